@@ -0,0 +1,61 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk int primary key, c1 int)"
+    dolt add test
+    dolt commit -m "created table test"
+    dolt checkout -b other
+    dolt sql -q "insert into test values (1, 1)"
+    dolt add test
+    dolt commit -m "added row 1"
+    dolt sql -q "insert into test values (2, 2)"
+    dolt add test
+    dolt commit -m "added row 2"
+    dolt checkout master
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "dolt cherry-pick applies only the changes of the given commit" {
+    run dolt cherry-pick other
+    [ "$status" -eq 0 ]
+    run dolt sql -q "select * from test"
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "| 1  | 1  |" ]] || false
+    [[ "$output" =~ "2" ]] || false
+    run dolt log
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "added row 2" ]] || false
+    [[ ! "$output" =~ "added row 1" ]] || false
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "dolt cherry-pick with a dirty working set fails" {
+    dolt sql -q "insert into test values (3, 3)"
+    run dolt cherry-pick other
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "local changes would be overwritten" ]] || false
+}
+
+@test "dolt cherry-pick with conflicting changes reports conflicts" {
+    dolt sql -q "insert into test values (2, 20)"
+    dolt add test
+    dolt commit -m "added conflicting row 2"
+    run dolt cherry-pick other
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+    run dolt conflicts cat test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "20" ]] || false
+}
+
+@test "dolt cherry-pick of an unknown commit fails" {
+    run dolt cherry-pick doesnotexist
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "not found" ]] || false
+}
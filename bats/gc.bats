@@ -0,0 +1,85 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk int primary key, c1 int)"
+    dolt add test
+    dolt commit -m "created table test"
+    dolt sql -q "insert into test values (1, 1), (2, 2)"
+    dolt add test
+    dolt commit -m "added rows"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "dolt gc keeps committed data" {
+    dolt branch other
+    dolt tag v1
+    run dolt gc
+    [ "$status" -eq 0 ]
+    run dolt log
+    [[ "$output" =~ "added rows" ]] || false
+    [[ "$output" =~ "created table test" ]] || false
+    run dolt sql -q "select * from test"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "| 2  | 2  |" ]] || false
+    run dolt checkout other
+    [ "$status" -eq 0 ]
+    run dolt log v1
+    [ "$status" -eq 0 ]
+}
+
+@test "dolt gc keeps the working set and staged tables" {
+    dolt sql -q "insert into test values (3, 3)"
+    dolt add test
+    dolt sql -q "insert into test values (4, 4)"
+    run dolt gc
+    [ "$status" -eq 0 ]
+    run dolt sql -q "select * from test"
+    [[ "$output" =~ "| 3  | 3  |" ]] || false
+    [[ "$output" =~ "| 4  | 4  |" ]] || false
+    dolt checkout test
+    run dolt sql -q "select * from test"
+    [[ "$output" =~ "| 3  | 3  |" ]] || false
+    [[ ! "$output" =~ "| 4  | 4  |" ]] || false
+}
+
+@test "dolt gc keeps stashes and commits in the reflog" {
+    dolt checkout -b other
+    dolt sql -q "insert into test values (5, 5)"
+    dolt add test
+    dolt commit -m "added row 5"
+    dolt checkout master
+    dolt branch -d -f other
+    dolt sql -q "insert into test values (3, 3)"
+    dolt stash
+    run dolt gc
+    [ "$status" -eq 0 ]
+    run dolt stash pop
+    [ "$status" -eq 0 ]
+    run dolt sql -q "select * from test"
+    [[ "$output" =~ "| 3  | 3  |" ]] || false
+    run dolt log refs/heads/other@{1}
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "added row 5" ]] || false
+}
+
+@test "dolt gc keeps the working set reflogs" {
+    dolt sql -q "insert into test values (3, 3)"
+    dolt sql -q "insert into test values (4, 4)"
+    run dolt reflog WORKING
+    [ "${#lines[@]}" -gt 1 ]
+    before="$output"
+    dolt gc
+    run dolt reflog WORKING
+    [ "$status" -eq 0 ]
+    [ "$output" = "$before" ]
+}
+
+@test "dolt gc does not take arguments" {
+    run dolt gc foo
+    [ "$status" -ne 0 ]
+}
@@ -0,0 +1,109 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk bigint primary key, cnt bigint, hi bigint, note varchar(20), updated datetime)"
+    dolt sql -q "insert into test values (1, 10, 1, 'base', '2019-01-01 00:00:00')"
+    dolt add test
+    dolt commit -m "created table test"
+}
+
+teardown() {
+    teardown_common
+}
+
+make_conflicting_changes() {
+    dolt checkout -b other
+    dolt sql -q "update test set cnt = 15, hi = 3, note = 'theirs', updated = '2019-03-01 00:00:00' where pk = 1"
+    dolt add test
+    dolt commit -m "changes on other"
+    dolt checkout master
+    dolt sql -q "update test set cnt = 12, hi = 7, note = 'ours', updated = '2019-02-01 00:00:00' where pk = 1"
+    dolt add test
+    dolt commit -m "changes on master"
+}
+
+@test "dolt schema merge-policy sets and shows column policies" {
+    dolt schema merge-policy test cnt sum-of-deltas
+    dolt schema merge-policy test note latest-by:updated
+    run dolt schema merge-policy test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "cnt      sum-of-deltas" ]] || false
+    [[ "$output" =~ "hi       none" ]] || false
+    [[ "$output" =~ "note     latest-by:updated" ]] || false
+    dolt schema rename-column test updated modified
+    run dolt schema merge-policy test
+    [[ "$output" =~ "note      latest-by:modified" ]] || false
+    run dolt schema drop-column test modified
+    [ "$status" -ne 0 ]
+}
+
+@test "dolt schema merge-policy sets a policy for the whole table" {
+    dolt schema merge-policy test theirs
+    dolt schema merge-policy test hi max
+    run dolt schema merge-policy test
+    [[ "$output" =~ "table default: theirs" ]] || false
+    [[ "$output" =~ "cnt      theirs (table default)" ]] || false
+    [[ "$output" =~ "hi       max" ]] || false
+    [[ "$output" =~ "updated  theirs (table default)" ]] || false
+    [[ ! "$output" =~ "pk" ]] || false
+    dolt schema add-column test added int
+    run dolt schema merge-policy test
+    [[ "$output" =~ "added    theirs (table default)" ]] || false
+}
+
+@test "dolt merge uses the table's merge policy for columns added after it was set" {
+    dolt schema merge-policy test theirs
+    dolt sql -q "alter table test add column extra bigint"
+    dolt sql -q "update test set extra = 1 where pk = 1"
+    dolt add test
+    dolt commit -m "added merge policy and column"
+    dolt checkout -b other
+    dolt sql -q "update test set extra = 3 where pk = 1"
+    dolt add test
+    dolt commit -m "changes on other"
+    dolt checkout master
+    dolt sql -q "update test set extra = 2 where pk = 1"
+    dolt add test
+    dolt commit -m "changes on master"
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "CONFLICT" ]] || false
+    run dolt sql -q "select extra from test where pk = 1"
+    [[ "$output" =~ "| 3     |" ]] || false
+}
+
+@test "dolt schema merge-policy rejects invalid policies" {
+    run dolt schema merge-policy test cnt average
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "unknown merge policy" ]] || false
+    run dolt schema merge-policy test note sum-of-deltas
+    [ "$status" -ne 0 ]
+    run dolt schema merge-policy test pk ours
+    [ "$status" -ne 0 ]
+    run dolt schema merge-policy test note latest-by:missing
+    [ "$status" -ne 0 ]
+}
+
+@test "dolt merge resolves conflicting cell changes using merge policies" {
+    dolt schema merge-policy test cnt sum-of-deltas
+    dolt schema merge-policy test hi max
+    dolt schema merge-policy test note latest-by:updated
+    dolt schema merge-policy test updated max
+    dolt add test
+    dolt commit -m "added merge policies"
+    make_conflicting_changes
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "CONFLICT" ]] || false
+    [[ "$output" =~ "4 conflicting cell changes resolved by merge policies" ]] || false
+    run dolt sql -q "select cnt, hi, note from test where pk = 1"
+    [[ "$output" =~ "| 17  | 7  | theirs |" ]] || false
+}
+
+@test "dolt merge without merge policies reports conflicts" {
+    make_conflicting_changes
+    run dolt merge other
+    [[ "$output" =~ "CONFLICT (content)" ]] || false
+}
@@ -0,0 +1,98 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk int primary key, c1 int)"
+    dolt add test
+    dolt commit -m "created table test"
+    dolt checkout -b feature
+    dolt sql -q "insert into test values (1, 1)"
+    dolt add test
+    dolt commit -m "added row 1"
+    dolt sql -q "insert into test values (2, 2)"
+    dolt add test
+    dolt commit -m "added row 2"
+    dolt checkout master
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "dolt merge --no-ff records a merge commit instead of fast-forwarding" {
+    run dolt merge --no-ff feature
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "Fast-forward" ]] || false
+    [[ "$output" =~ "2 rows added" ]] || false
+    run dolt status
+    [[ "$output" =~ "still merging" ]] || false
+    dolt add test
+    dolt commit -m "merged feature"
+    run dolt log -n 1
+    [[ "$output" =~ "Merge:" ]] || false
+    [[ "$output" =~ "merged feature" ]] || false
+    run dolt sql -q "select * from test"
+    [[ "$output" =~ "| 2  | 2  |" ]] || false
+}
+
+@test "dolt merge --squash stages the changes without recording a merge" {
+    run dolt merge --squash feature
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Squash commit -- not updating HEAD" ]] || false
+    run dolt log -n 1
+    [[ "$output" =~ "created table test" ]] || false
+    run dolt status
+    [[ "$output" =~ "Changes to be committed" ]] || false
+    [[ ! "$output" =~ "merging" ]] || false
+    dolt commit -m "squashed feature"
+    run dolt log
+    [[ ! "$output" =~ "Merge:" ]] || false
+    [[ ! "$output" =~ "added row 1" ]] || false
+    [[ "$output" =~ "squashed feature" ]] || false
+    run dolt sql -q "select * from test"
+    [[ "$output" =~ "| 1  | 1  |" ]] || false
+    [[ "$output" =~ "| 2  | 2  |" ]] || false
+}
+
+@test "dolt merge --squash with diverged branches" {
+    dolt sql -q "insert into test values (3, 3)"
+    dolt add test
+    dolt commit -m "added row 3"
+    run dolt merge --squash feature
+    [ "$status" -eq 0 ]
+    dolt commit -m "squashed feature"
+    run dolt log -n 1
+    [[ ! "$output" =~ "Merge:" ]] || false
+    run dolt sql -q "select * from test"
+    [[ "$output" =~ "| 1  | 1  |" ]] || false
+    [[ "$output" =~ "| 3  | 3  |" ]] || false
+}
+
+@test "dolt merge --ff-only fast-forwards when possible" {
+    run dolt merge --ff-only feature
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Fast-forward" ]] || false
+    run dolt log -n 1
+    [[ "$output" =~ "added row 2" ]] || false
+}
+
+@test "dolt merge --ff-only fails when a merge is required" {
+    dolt sql -q "insert into test values (3, 3)"
+    dolt add test
+    dolt commit -m "added row 3"
+    run dolt merge --ff-only feature
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "Not possible to fast-forward" ]] || false
+    run dolt status
+    [[ "$output" =~ "nothing to commit" ]] || false
+}
+
+@test "dolt merge rejects conflicting options" {
+    run dolt merge --squash --no-ff feature
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "cannot combine" ]] || false
+    run dolt merge --ff-only --no-ff feature
+    [ "$status" -ne 0 ]
+    [[ "$output" =~ "cannot combine" ]] || false
+}
@@ -0,0 +1,136 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk int primary key, c1 int)"
+    dolt add test
+    dolt commit -m "created table test"
+    dolt checkout -b feature
+    dolt sql -q "insert into test values (1, 1)"
+    dolt add test
+    dolt commit -m "feature row 1"
+    dolt sql -q "insert into test values (2, 2)"
+    dolt add test
+    dolt commit -m "feature row 2"
+    dolt checkout master
+    dolt sql -q "insert into test values (10, 10)"
+    dolt add test
+    dolt commit -m "master row 10"
+    dolt checkout feature
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "dolt rebase replays commits onto upstream" {
+    run dolt rebase master
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Successfully rebased and updated refs/heads/feature" ]] || false
+    run dolt log
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "feature row 2" ]] || false
+    [[ "$output" =~ "master row 10" ]] || false
+    run dolt sql -q "select count(*) from test"
+    [[ "$output" =~ "3" ]] || false
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+    run dolt rebase master
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "is up to date" ]] || false
+}
+
+@test "dolt rebase with a dirty working set fails" {
+    dolt sql -q "insert into test values (3, 3)"
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "local changes would be overwritten" ]] || false
+}
+
+@test "dolt rebase stops on conflicts and can be aborted" {
+    dolt checkout master
+    dolt sql -q "insert into test values (1, 100)"
+    dolt add test
+    dolt commit -m "master row 1"
+    dolt checkout feature
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+    run dolt status
+    [[ "$output" =~ "You are currently rebasing" ]] || false
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "already in progress" ]] || false
+    run dolt rebase --abort
+    [ "$status" -eq 0 ]
+    run dolt log -n 1
+    [[ "$output" =~ "feature row 2" ]] || false
+    run dolt status
+    [[ ! "$output" =~ "rebasing" ]] || false
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "dolt rebase --continue after resolving conflicts" {
+    dolt checkout master
+    dolt sql -q "insert into test values (1, 100)"
+    dolt add test
+    dolt commit -m "master row 1"
+    dolt checkout feature
+    run dolt rebase master
+    [ "$status" -eq 1 ]
+    run dolt rebase --continue
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "unresolved conflicts" ]] || false
+    dolt conflicts resolve --theirs test
+    dolt add test
+    run dolt rebase --continue
+    [ "$status" -eq 0 ]
+    run dolt sql -q "select * from test where pk = 1"
+    [[ "$output" =~ "| 1  | 1  |" ]] || false
+    run dolt log
+    [[ "$output" =~ "master row 1" ]] || false
+    [[ "$output" =~ "feature row 1" ]] || false
+}
+
+@test "dolt rebase --continue and --abort without a rebase fail" {
+    run dolt rebase --continue
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "No rebase in progress" ]] || false
+    run dolt rebase --abort
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "No rebase in progress" ]] || false
+}
+
+@test "dolt rebase -i can drop and squash commits" {
+    EDITOR="sed -i -e 1s/^pick/drop/" run dolt rebase -i master
+    [ "$status" -eq 0 ]
+    run dolt sql -q "select count(*) from test"
+    [[ "$output" =~ "2" ]] || false
+    dolt sql -q "insert into test values (3, 3)"
+    dolt add test
+    dolt commit -m "feature row 3"
+    EDITOR="sed -i -e 2s/^pick/squash/" run dolt rebase -i master
+    [ "$status" -eq 0 ]
+    run dolt log -n 1
+    [[ "$output" =~ "feature row 2" ]] || false
+    [[ "$output" =~ "feature row 3" ]] || false
+    run dolt log -n 2
+    [[ "$output" =~ "master row 10" ]] || false
+}
+
+@test "dolt rebase -i cannot squash into a commit which isn't being rebased" {
+    dolt sql -q "insert into test values (10, 10)"
+    dolt add test
+    dolt commit -m "feature row 10"
+    dolt sql -q "insert into test values (3, 3)"
+    dolt add test
+    dolt commit -m "feature row 3"
+    EDITOR="sed -i -e 1,2s/^pick/drop/ -e 4s/^pick/squash/" run dolt rebase -i master
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "cannot 'squash' without a previous commit" ]] || false
+    run dolt rebase --abort
+    [ "$status" -eq 0 ]
+    run dolt log -n 1
+    [[ "$output" =~ "feature row 3" ]] || false
+}
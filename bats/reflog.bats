@@ -0,0 +1,75 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk int primary key, c1 int)"
+    dolt add test
+    dolt commit -m "created table test"
+    dolt sql -q "insert into test values (1, 1)"
+    dolt add test
+    dolt commit -m "added row 1"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "dolt reflog shows the updates to HEAD" {
+    run dolt reflog
+    [ "$status" -eq 0 ]
+    [[ "${lines[0]}" =~ 'HEAD@{0}: dolt commit -m added row 1' ]] || false
+    [[ "${lines[1]}" =~ 'HEAD@{1}: dolt commit -m created table test' ]] || false
+    [[ "${lines[2]}" =~ 'HEAD@{2}: dolt init' ]] || false
+}
+
+@test "dolt reflog records checkouts in HEAD but not in the branch log" {
+    dolt checkout -b other
+    dolt sql -q "insert into test values (2, 2)"
+    dolt add test
+    dolt commit -m "added row 2"
+    dolt checkout master
+    run dolt reflog
+    [ "$status" -eq 0 ]
+    [[ "${lines[0]}" =~ 'HEAD@{0}: dolt checkout master' ]] || false
+    [[ "${lines[1]}" =~ 'HEAD@{1}: dolt commit -m added row 2' ]] || false
+    run dolt reflog master
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 3 ]
+    run dolt reflog refs/heads/other
+    [ "$status" -eq 0 ]
+    [[ "${lines[0]}" =~ 'refs/heads/other@{0}: dolt commit -m added row 2' ]] || false
+    [[ "${lines[1]}" =~ 'refs/heads/other@{1}: dolt checkout -b other' ]] || false
+}
+
+@test "reflog entries can be used as commits" {
+    run dolt log HEAD@{1}
+    [ "$status" -eq 0 ]
+    [[ "${lines[0]}" =~ "commit" ]] || false
+    [[ ! "$output" =~ "added row 1" ]] || false
+    [[ "$output" =~ "created table test" ]] || false
+    run dolt checkout -b recovered master@{1}
+    [ "$status" -eq 0 ]
+    run dolt sql -q "select * from test"
+    [[ ! "$output" =~ "| 1  | 1  |" ]] || false
+    run dolt log HEAD@{50}
+    [ "$status" -ne 0 ]
+}
+
+@test "dolt reflog records deleted branches" {
+    dolt branch other
+    dolt branch -d other
+    run dolt reflog refs/heads/other
+    [ "$status" -eq 0 ]
+    [[ "${lines[0]}" =~ '00000000000000000000000000000000 refs/heads/other@{0}: dolt branch -d other' ]] || false
+}
+
+@test "dolt reflog shows the history of the working set" {
+    dolt sql -q "insert into test values (3, 3)"
+    run dolt reflog WORKING
+    [ "$status" -eq 0 ]
+    [[ "${lines[0]}" =~ 'WORKING@{0}: dolt sql -q insert into test values (3, 3)' ]] || false
+    run dolt reflog STAGED
+    [ "$status" -eq 0 ]
+    [[ "${lines[0]}" =~ 'STAGED@{0}: dolt add test' ]] || false
+}
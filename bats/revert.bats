@@ -0,0 +1,78 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk int primary key, c1 int)"
+    dolt add test
+    dolt commit -m "created table test"
+    dolt sql -q "insert into test values (1, 1)"
+    dolt add test
+    dolt commit -m "added row 1"
+    dolt sql -q "insert into test values (2, 2)"
+    dolt add test
+    dolt commit -m "added row 2"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "dolt revert undoes the changes of a commit" {
+    run dolt revert HEAD~1
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ 'Revert "added row 1"' ]] || false
+    run dolt sql -q "select * from test"
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "2" ]] || false
+    [[ ! "$output" =~ "| 1  | 1  |" ]] || false
+    run dolt log
+    [[ "$output" =~ "added row 1" ]] || false
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+}
+
+@test "dolt revert with multiple commits creates a commit for each" {
+    run dolt revert HEAD HEAD~1
+    [ "$status" -eq 0 ]
+    run dolt log -n 2
+    [[ "$output" =~ 'Revert "added row 2"' ]] || false
+    [[ "$output" =~ 'Revert "added row 1"' ]] || false
+    run dolt sql -q "select count(*) from test"
+    [[ "$output" =~ "0" ]] || false
+}
+
+@test "dolt revert of a table creation drops the table" {
+    dolt sql -q "create table test2 (pk int primary key)"
+    dolt add test2
+    dolt commit -m "created table test2"
+    run dolt revert HEAD
+    [ "$status" -eq 0 ]
+    run dolt ls
+    [[ ! "$output" =~ "test2" ]] || false
+}
+
+@test "dolt revert of a table creation fails if the table was modified since" {
+    run dolt revert HEAD~2
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "could not apply" ]] || false
+}
+
+@test "dolt revert with conflicting changes reports conflicts" {
+    dolt sql -q "update test set c1 = 10 where pk = 1"
+    dolt add test
+    dolt commit -m "updated row 1"
+    run dolt revert HEAD~2
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+    run dolt conflicts cat test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "10" ]] || false
+}
+
+@test "dolt revert with a dirty working set fails" {
+    dolt sql -q "insert into test values (3, 3)"
+    run dolt revert HEAD
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "local changes would be overwritten" ]] || false
+}
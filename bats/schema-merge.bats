@@ -0,0 +1,86 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk int primary key, c1 int, c2 int, c3 int)"
+    dolt sql -q "insert into test values (1, 1, 1, 1), (2, 2, 2, 2)"
+    dolt add test
+    dolt commit -m "created table test"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "merge a column renamed on one branch and dropped on the other" {
+    dolt checkout -b other
+    dolt sql -q "alter table test rename column c1 to renamed"
+    dolt sql -q "insert into test values (3, 3, 3, 3)"
+    dolt add test
+    dolt commit -m "renamed c1"
+    dolt checkout master
+    dolt sql -q "alter table test drop column c3"
+    dolt sql -q "update test set c2 = 20 where pk = 2"
+    dolt add test
+    dolt commit -m "dropped c3"
+    run dolt merge other
+    [ "$status" -eq 0 ]
+    [[ ! "$output" =~ "CONFLICT" ]] || false
+    run dolt schema show test
+    [[ "$output" =~ "\`renamed\`" ]] || false
+    [[ ! "$output" =~ "\`c1\`" ]] || false
+    [[ ! "$output" =~ "\`c3\`" ]] || false
+    run dolt sql -q "select * from test where pk = 3"
+    [[ "$output" =~ "| 3  | 3       | 3  |" ]] || false
+    run dolt sql -q "select c2 from test where pk = 2"
+    [[ "$output" =~ "20" ]] || false
+}
+
+@test "merge a column renamed differently on both branches is a schema conflict" {
+    dolt checkout -b other
+    dolt sql -q "alter table test rename column c1 to theirs"
+    dolt add test
+    dolt commit -m "renamed c1 to theirs"
+    dolt checkout master
+    dolt sql -q "alter table test rename column c1 to ours"
+    dolt add test
+    dolt commit -m "renamed c1 to ours"
+    run dolt merge other
+    [[ "$output" =~ "CONFLICT (schema)" ]] || false
+    run dolt conflicts cat test
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "column name modified differently in both branches" ]] || false
+    [[ "$output" =~ "base:   \`c1\`" ]] || false
+    [[ "$output" =~ "ours:   \`ours\`" ]] || false
+    [[ "$output" =~ "theirs: \`theirs\`" ]] || false
+    run dolt add test
+    [ "$status" -ne 0 ]
+    dolt conflicts resolve --theirs test
+    run dolt schema show test
+    [[ "$output" =~ "\`theirs\`" ]] || false
+    [[ ! "$output" =~ "\`ours\`" ]] || false
+    dolt add test
+    dolt commit -m "merged"
+}
+
+@test "resolve a schema conflict using our schema" {
+    dolt checkout -b other
+    dolt sql -q "alter table test drop column c2"
+    dolt add test
+    dolt commit -m "dropped c2"
+    dolt checkout master
+    dolt sql -q "alter table test rename column c2 to renamed"
+    dolt add test
+    dolt commit -m "renamed c2"
+    run dolt merge other
+    [[ "$output" =~ "CONFLICT (schema)" ]] || false
+    run dolt conflicts cat test
+    [[ "$output" =~ "column modified in ours and deleted in theirs" ]] || false
+    [[ "$output" =~ "theirs: <deleted>" ]] || false
+    dolt conflicts resolve --ours test
+    run dolt schema show test
+    [[ "$output" =~ "\`renamed\`" ]] || false
+    run dolt status
+    [[ ! "$output" =~ "unmerged" ]] || false
+}
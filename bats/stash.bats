@@ -0,0 +1,97 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk int primary key, c1 int)"
+    dolt add test
+    dolt commit -m "created table test"
+}
+
+teardown() {
+    teardown_common
+}
+
+@test "dolt stash with no changes does nothing" {
+    run dolt stash
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "No local changes to save" ]] || false
+    run dolt stash list
+    [ "$status" -eq 0 ]
+    [ "$output" = "" ]
+}
+
+@test "dolt stash saves changes and resets the working set" {
+    dolt sql -q "insert into test values (1, 1)"
+    run dolt stash
+    [ "$status" -eq 0 ]
+    run dolt status
+    [[ "$output" =~ "nothing to commit, working tree clean" ]] || false
+    run dolt stash list
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "stash@{0}: WIP on master" ]] || false
+    run dolt branch -a
+    [[ ! "$output" =~ "stashes" ]] || false
+}
+
+@test "dolt stash pop restores working and staged tables" {
+    dolt sql -q "insert into test values (1, 1)"
+    dolt add test
+    dolt sql -q "create table test2 (pk int primary key)"
+    dolt stash -m "my changes"
+    run dolt stash list
+    [[ "$output" =~ "stash@{0}: On master: my changes" ]] || false
+    run dolt stash pop
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "Dropped stash@{0}" ]] || false
+    run dolt status
+    [[ "$output" =~ "Changes to be committed" ]] || false
+    [[ "$output" =~ "test2" ]] || false
+    run dolt stash list
+    [ "$output" = "" ]
+}
+
+@test "dolt stash apply keeps the stash and merges if head moved" {
+    dolt sql -q "insert into test values (1, 1)"
+    dolt stash
+    dolt sql -q "insert into test values (2, 2)"
+    dolt add test
+    dolt commit -m "added row 2"
+    run dolt stash apply
+    [ "$status" -eq 0 ]
+    run dolt sql -q "select count(*) from test"
+    [[ "$output" =~ "2" ]] || false
+    run dolt stash list
+    [[ "$output" =~ "stash@{0}" ]] || false
+}
+
+@test "dolt stash pop with conflicts keeps the stash" {
+    dolt sql -q "insert into test values (1, 1)"
+    dolt stash
+    dolt sql -q "insert into test values (1, 10)"
+    dolt add test
+    dolt commit -m "added conflicting row 1"
+    run dolt stash pop
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "CONFLICT" ]] || false
+    run dolt stash list
+    [[ "$output" =~ "stash@{0}" ]] || false
+}
+
+@test "dolt stash list orders stashes newest first and drop removes them" {
+    dolt sql -q "insert into test values (1, 1)"
+    dolt stash -m "first"
+    dolt sql -q "insert into test values (2, 2)"
+    dolt stash -m "second"
+    run dolt stash list
+    [ "${lines[0]}" = "stash@{0}: On master: second" ]
+    [ "${lines[1]}" = "stash@{1}: On master: first" ]
+    run dolt stash drop stash@{1}
+    [ "$status" -eq 0 ]
+    run dolt stash list
+    [ "${#lines[@]}" -eq 1 ]
+    [[ "$output" =~ "second" ]] || false
+    run dolt stash drop stash@{5}
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "does not exist" ]] || false
+}
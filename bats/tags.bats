@@ -0,0 +1,100 @@
+#!/usr/bin/env bats
+load $BATS_TEST_DIRNAME/helper/common.bash
+
+setup() {
+    setup_common
+    dolt sql -q "create table test (pk int primary key, c1 int)"
+    dolt add test
+    dolt commit -m "created table test"
+}
+
+teardown() {
+    teardown_common
+    rm -rf "$BATS_TMPDIR/tags-clone-$$"
+}
+
+@test "dolt tag with no tags lists nothing" {
+    run dolt tag
+    [ "$status" -eq 0 ]
+    [ "$output" = "" ]
+}
+
+@test "dolt tag creates lightweight and annotated tags" {
+    run dolt tag v1
+    [ "$status" -eq 0 ]
+    run dolt tag -m "first release" v1_annotated
+    [ "$status" -eq 0 ]
+    run dolt tag
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "v1" ]] || false
+    [[ "$output" =~ "v1_annotated" ]] || false
+    run dolt tag -v
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "first release" ]] || false
+    [[ "$output" =~ "Tagger:" ]] || false
+}
+
+@test "dolt tag refuses to overwrite an existing tag without -f" {
+    dolt tag v1
+    run dolt tag v1
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "already exists" ]] || false
+    run dolt tag -f v1
+    [ "$status" -eq 0 ]
+}
+
+@test "dolt tag rejects invalid tag names" {
+    run dolt tag v1.0
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "not a valid tag name" ]] || false
+}
+
+@test "tags can be used as commit specs" {
+    dolt tag -m "before insert" v1
+    dolt sql -q "insert into test values (0, 0)"
+    dolt add test
+    dolt commit -m "inserted a row"
+    run dolt log v1
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "created table test" ]] || false
+    [[ ! "$output" =~ "inserted a row" ]] || false
+    run dolt checkout -b from_tag refs/tags/v1
+    [ "$status" -eq 0 ]
+    run dolt sql -q "select * from test"
+    [ "$status" -eq 0 ]
+    [ "${#lines[@]}" -eq 4 ]
+}
+
+@test "dolt tag -d deletes tags" {
+    dolt tag v1
+    dolt tag v2
+    run dolt tag -d v1 v2
+    [ "$status" -eq 0 ]
+    run dolt tag
+    [ "$output" = "" ]
+    run dolt tag -d v1
+    [ "$status" -eq 1 ]
+    [[ "$output" =~ "not found" ]] || false
+}
+
+@test "tags are pushed, cloned and fetched" {
+    mkdir remote
+    dolt remote add origin file://$BATS_TMPDIR/dolt-repo-$$/remote
+    dolt push origin master
+    dolt tag -m "first release" v1
+    run dolt push origin refs/tags/v1
+    [ "$status" -eq 0 ]
+    cd $BATS_TMPDIR
+    dolt clone file://$BATS_TMPDIR/dolt-repo-$$/remote tags-clone-$$
+    cd tags-clone-$$
+    run dolt tag -v
+    [ "$status" -eq 0 ]
+    [[ "$output" =~ "first release" ]] || false
+    cd $BATS_TMPDIR/dolt-repo-$$
+    dolt tag v2
+    dolt push origin 'refs/tags/*:refs/tags/*'
+    cd $BATS_TMPDIR/tags-clone-$$
+    dolt fetch
+    run dolt tag
+    [[ "$output" =~ "v2" ]] || false
+}
@@ -69,17 +69,7 @@ func GenSubCommandHandler(commands []*Command) CommandFunc {
 		subCommandStr := strings.ToLower(strings.TrimSpace(args[0]))
 		if command, ok := commandMap[subCommandStr]; ok {
 			if command.ReqRepo && !hasHelpFlag(args) {
-				if !dEnv.HasDoltDir() {
-					PrintErrln(color.RedString("The current directory is not a valid dolt repository."))
-					PrintErrln("run: dolt init before trying to run this command")
-					return 2
-				} else if dEnv.RSLoadErr != nil {
-					PrintErrln(color.RedString("The current directories repository state is invalid"))
-					PrintErrln(dEnv.RSLoadErr.Error())
-					return 2
-				} else if dEnv.DBLoadError != nil {
-					PrintErrln(color.RedString("Failed to load database."))
-					PrintErrln(dEnv.DBLoadError.Error())
+				if !CheckEnvIsValid(dEnv) {
 					return 2
 				}
 			}
@@ -136,3 +126,23 @@ func printUsage(commandStr string, commands []*Command) {
 		}
 	}
 }
+
+// CheckEnvIsValid returns whether the DoltEnv was loaded from a valid dolt repository, printing an error describing the
+// problem if it wasn't.  It's used by commands which only require a repository in some cases, and so can't set ReqRepo.
+func CheckEnvIsValid(dEnv *env.DoltEnv) bool {
+	if !dEnv.HasDoltDir() {
+		PrintErrln(color.RedString("The current directory is not a valid dolt repository."))
+		PrintErrln("run: dolt init before trying to run this command")
+		return false
+	} else if dEnv.RSLoadErr != nil {
+		PrintErrln(color.RedString("The current directories repository state is invalid"))
+		PrintErrln(dEnv.RSLoadErr.Error())
+		return false
+	} else if dEnv.DBLoadError != nil {
+		PrintErrln(color.RedString("Failed to load database."))
+		PrintErrln(dEnv.DBLoadError.Error())
+		return false
+	}
+
+	return true
+}
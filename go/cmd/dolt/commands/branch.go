@@ -125,7 +125,7 @@ func printBranches(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgPar
 
 		cs, _ := doltdb.NewCommitSpec("HEAD", branch.String())
 
-		if branch.GetType() != ref.BranchRefType && !printAll {
+		if branch.GetType() == ref.TagRefType || branch.GetType() == ref.StashRefType || (branch.GetType() != ref.BranchRefType && !printAll) {
 			continue
 		}
 
@@ -0,0 +1,167 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/cli"
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/errhand"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/merge"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
+)
+
+var cherryPickShortDesc = "Apply the changes introduced by an existing commit"
+var cherryPickLongDesc = "Given an existing commit, apply the change it introduces to the current branch, and record a " +
+	"new commit which reuses the description of the original commit.\n" +
+	"\n" +
+	"The change is applied using a three-way merge in which the parent of the commit being cherry-picked is used as " +
+	"the merge base.  If a row changed by the commit was also changed on the current branch the merge results in a " +
+	"conflict.  In that case the working set is updated with the conflicts and no commit is made.  Resolve the " +
+	"conflicts with <b>dolt conflicts</b>, then use <b>dolt add</b> and <b>dolt commit</b> to record the result.\n" +
+	"\n" +
+	"The working set must not have any uncommitted changes when cherry-pick is run."
+var cherryPickSynopsis = []string{
+	"<commit>",
+}
+
+func CherryPick(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp["commit"] = "The commit whose changes should be applied to the current branch."
+	help, usage := cli.HelpAndUsagePrinters(commandStr, cherryPickShortDesc, cherryPickLongDesc, cherryPickSynopsis, ap)
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.NArg() != 1 {
+		usage()
+		return 1
+	}
+
+	verr := checkWorkingSetCleanForOp(ctx, dEnv, "cherry-pick")
+
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	cm, verr := ResolveCommitWithVErr(dEnv, apr.Arg(0), dEnv.RepoState.Head.Ref.String())
+
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	meta, err := cm.GetCommitMeta()
+
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to read commit metadata").AddCause(err).Build(), usage)
+	}
+
+	headRoot, err := dEnv.HeadRoot(ctx)
+
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to get the root value of HEAD").AddCause(err).Build(), usage)
+	}
+
+	mergedRoot, tblToStats, err := actions.CherryPick(ctx, dEnv.DoltDB, headRoot, cm)
+
+	if err != nil {
+		return HandleVErrAndExitCode(buildCommitOpErr("cherry-pick", apr.Arg(0), err), usage)
+	}
+
+	return commitMergedRoot(ctx, dEnv, mergedRoot, tblToStats, meta.Description, usage)
+}
+
+// checkWorkingSetCleanForOp verifies that the working set has no uncommitted changes, no unresolved conflicts, and that
+// there is no merge in progress before running an operation which creates commits from the result of a merge.
+func checkWorkingSetCleanForOp(ctx context.Context, dEnv *env.DoltEnv, opName string) errhand.VerboseError {
+	if dEnv.IsMergeActive() {
+		return errhand.BuildDError("error: %s is not possible because you have not committed an active merge.", opName).
+			AddDetails("hint: add affected tables using 'dolt add <table>' and commit using 'dolt commit -m <msg>'").Build()
+	}
+
+	if dEnv.IsRebaseActive() {
+		return errhand.BuildDError("error: %s is not possible because a rebase is in progress.", opName).
+			AddDetails("hint: use 'dolt rebase --continue' or 'dolt rebase --abort' to finish the rebase").Build()
+	}
+
+	root, verr := GetWorkingWithVErr(dEnv)
+
+	if verr != nil {
+		return verr
+	}
+
+	if has, err := root.HasConflicts(ctx); err != nil {
+		return errhand.BuildDError("error: failed to get conflicts").AddCause(err).Build()
+	} else if has {
+		return errhand.BuildDError("error: %s is not possible because you have unmerged tables.", opName).
+			AddDetails("hint: Fix them up in the work tree, and then use 'dolt add <table>'").
+			AddDetails("hint: as appropriate to mark resolution and make a commit.").Build()
+	}
+
+	if isUnchanged, err := dEnv.IsUnchangedFromHead(ctx); err != nil {
+		return errhand.BuildDError("error: failed to read the working set").AddCause(err).Build()
+	} else if !isUnchanged {
+		return errhand.BuildDError("error: your local changes would be overwritten by %s.", opName).
+			AddDetails("hint: commit your changes or reset them to proceed.").Build()
+	}
+
+	return nil
+}
+
+func buildCommitOpErr(opName, cSpecStr string, err error) errhand.VerboseError {
+	switch err {
+	case actions.ErrMergeCommitNotSupported:
+		return errhand.BuildDError("error: commit %s is a merge commit. %s of merge commits is not supported.", cSpecStr, opName).Build()
+	case actions.ErrNoParentCommit:
+		return errhand.BuildDError("error: commit %s has no parent.", cSpecStr).Build()
+	case merge.ErrSameTblAddedTwice, merge.ErrTblDeletedAndModified:
+		return errhand.BuildDError("error: could not apply %s", cSpecStr).AddCause(err).Build()
+	default:
+		return errhand.BuildDError("error: %s failed", opName).AddCause(err).Build()
+	}
+}
+
+// commitMergedRoot updates the working set with the result of a merge.  If the merge resulted in conflicts they are
+// reported and no commit is made, otherwise the result is staged and committed with the message given.
+func commitMergedRoot(ctx context.Context, dEnv *env.DoltEnv, mergedRoot *doltdb.RootValue, tblToStats map[string]*merge.MergeStats, msg string, usage cli.UsagePrinter) int {
+	verr := UpdateWorkingWithVErr(dEnv, mergedRoot)
+
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	if hasConflicts := printSuccessStats(tblToStats); hasConflicts {
+		cli.Println("Automatic merge failed; fix conflicts and then commit the result.")
+		return 1
+	}
+
+	verr = UpdateStagedWithVErr(dEnv, mergedRoot)
+
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	err := actions.CommitStaged(ctx, dEnv, msg, false)
+
+	if err == nil {
+		return Log(ctx, "log", []string{"-n=1"}, dEnv)
+	} else if actions.IsNothingStaged(err) {
+		cli.PrintErrln("The changes have already been applied, and the result is empty.  Nothing to commit.")
+		return 1
+	}
+
+	return handleCommitErr(err, usage)
+}
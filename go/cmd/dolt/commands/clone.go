@@ -41,39 +41,61 @@ import (
 )
 
 const (
-	remoteParam = "remote"
-	branchParam = "branch"
+	remoteParam       = "remote"
+	branchParam       = "branch"
+	depthParam        = "depth"
+	singleBranchParam = "single-branch"
 )
 
 var cloneShortDesc = "Clone a data repository into a new directory"
 var cloneLongDesc = "Clones a repository into a newly created directory, creates remote-tracking branches for each " +
 	"branch in the cloned repository (visible using dolt branch -a), and creates and checks out an initial branch that " +
-	"is forked from the cloned repository's currently active branch.\n" +
+	"is forked from the cloned repository's currently active branch. Tags in the cloned repository are copied as well.\n" +
 	"\n" +
 	"After the clone, a plain <b>dolt fetch</b> without arguments will update all the remote-tracking branches, and a <b>dolt " +
 	"pull</b> without arguments will in addition merge the remote branch into the current branch\n" +
 	"\n" +
 	"This default configuration is achieved by creating references to the remote branch heads under refs/remotes/origin " +
-	"and by creating a remote named 'origin'."
+	"and by creating a remote named 'origin'.\n" +
+	"\n" +
+	"With <b>--single-branch</b> only the history of the branch given by <b>--branch</b>, or of master, is cloned, and " +
+	"later fetches only update that branch.  With <b>--depth</b> the history is also truncated to the given number of " +
+	"commits, creating a shallow clone whose missing history can be fetched later using <b>dolt fetch --depth</b> or " +
+	"<b>dolt fetch --unshallow</b>."
 var cloneSynopsis = []string{
-	"[-remote <remote>] [-branch <branch>]  [--aws-region <region>] [--aws-creds-type <creds-type>] [--aws-creds-file <file>] [--aws-creds-profile <profile>] <remote-url> <new-dir>",
+	"[-remote <remote>] [-branch <branch>] [--single-branch] [--depth <depth>] [--aws-region <region>] [--aws-creds-type <creds-type>] [--aws-creds-file <file>] [--aws-creds-profile <profile>] [--s3-endpoint <url>] [--s3-path-style] <remote-url> <new-dir>",
 }
 
 func Clone(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
 	ap := argparser.NewArgParser()
 	ap.SupportsString(remoteParam, "", "name", "Name of the remote to be added. Default will be 'origin'.")
 	ap.SupportsString(branchParam, "b", "branch", "The branch to be cloned.  If not specified all branches will be cloned.")
+	ap.SupportsFlag(singleBranchParam, "", "Clone only the history of the branch given by --branch, or of master.")
+	ap.SupportsInt(depthParam, "", "depth", "Create a shallow clone with the history truncated to the given number of commits.  Implies --single-branch.")
 	ap.SupportsString(dbfactory.AWSRegionParam, "", "region", "")
 	ap.SupportsValidatedString(dbfactory.AWSCredsTypeParam, "", "creds-type", "", argparser.ValidatorFromStrList(dbfactory.AWSCredsTypeParam, credTypes))
 	ap.SupportsString(dbfactory.AWSCredsFileParam, "", "file", "AWS credentials file.")
 	ap.SupportsString(dbfactory.AWSCredsProfile, "", "profile", "AWS profile to use.")
+	ap.SupportsString(dbfactory.S3EndpointParam, "", "url", "Endpoint of an S3 compatible object store.")
+	ap.SupportsFlag(dbfactory.S3PathStyleParam, "", "Address s3 buckets using the path of the url rather than the hostname.")
 	help, usage := cli.HelpAndUsagePrinters(commandStr, cloneShortDesc, cloneLongDesc, cloneSynopsis, ap)
 	apr := cli.ParseArgs(ap, args, help)
 
 	remoteName := apr.GetValueOrDefault(remoteParam, "origin")
 	branch := apr.GetValueOrDefault(branchParam, "")
+	singleBranch := apr.Contains(singleBranchParam)
 	dir, urlStr, verr := parseArgs(apr)
 
+	depth, depthVErr := parseDepth(apr)
+
+	if verr == nil {
+		verr = depthVErr
+	}
+
+	if depth > 0 {
+		singleBranch = true
+	}
+
 	scheme, remoteUrl, err := getAbsRemoteUrl(dEnv.FS, dEnv.Config, urlStr)
 
 	if err != nil {
@@ -93,7 +115,7 @@ func Clone(ctx context.Context, commandStr string, args []string, dEnv *env.Dolt
 				dEnv, verr = envForClone(ctx, srcDB.ValueReadWriter().Format(), r, dir, dEnv.FS)
 
 				if verr == nil {
-					verr = cloneRemote(ctx, srcDB, remoteName, branch, dEnv)
+					verr = cloneRemote(ctx, srcDB, remoteName, branch, singleBranch, depth, dEnv)
 
 					if verr == nil {
 						evt := events.GetEventFromContext(ctx)
@@ -238,22 +260,31 @@ func cloneProg(eventCh <-chan datas.TableFileEvent) {
 	cli.Println()
 }
 
-func cloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch string, dEnv *env.DoltEnv) errhand.VerboseError {
-	wg := &sync.WaitGroup{}
-	eventCh := make(chan datas.TableFileEvent, 128)
+func cloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch string, singleBranch bool, depth int, dEnv *env.DoltEnv) errhand.VerboseError {
+	if singleBranch {
+		var verr errhand.VerboseError
+		branch, verr = cloneSingleBranch(ctx, srcDB, remoteName, branch, depth, dEnv)
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		cloneProg(eventCh)
-	}()
+		if verr != nil {
+			return verr
+		}
+	} else {
+		wg := &sync.WaitGroup{}
+		eventCh := make(chan datas.TableFileEvent, 128)
 
-	err := actions.Clone(ctx, srcDB, dEnv.DoltDB, eventCh)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cloneProg(eventCh)
+		}()
 
-	wg.Wait()
+		err := actions.Clone(ctx, srcDB, dEnv.DoltDB, eventCh)
 
-	if err != nil {
-		return errhand.BuildDError("error: clone failed").AddCause(err).Build()
+		wg.Wait()
+
+		if err != nil {
+			return errhand.BuildDError("error: clone failed").AddCause(err).Build()
+		}
 	}
 
 	if branch == "" {
@@ -280,7 +311,7 @@ func cloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch s
 	}
 
 	remoteRef := ref.NewRemoteRef(remoteName, branch)
-	err = dEnv.DoltDB.FastForward(ctx, remoteRef, cm)
+	err = dEnv.DoltDB.SetHeadToCommit(ctx, remoteRef, cm)
 
 	if err != nil {
 		return errhand.BuildDError("error: could not create remote ref at " + remoteRef.String()).AddCause(err).Build()
@@ -315,3 +346,45 @@ func cloneRemote(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch s
 
 	return nil
 }
+
+// cloneSingleBranch pulls the history of a single branch of the source database, limited to depth commits if depth is
+// greater than 0, and creates the branch in the new repository.  The remote's fetch specs are limited to the branch as
+// well.  Returns the name of the branch cloned, which is master if no branch was given.
+func cloneSingleBranch(ctx context.Context, srcDB *doltdb.DoltDB, remoteName, branch string, depth int, dEnv *env.DoltEnv) (string, errhand.VerboseError) {
+	if branch == "" {
+		branch = "master"
+	}
+
+	branchRef := ref.NewBranchRef(branch)
+	cs, _ := doltdb.NewCommitSpec("HEAD", branchRef.String())
+	cm, err := srcDB.Resolve(ctx, cs)
+
+	if err != nil {
+		return "", errhand.BuildDError("error: could not get %s from the remote", branch).AddCause(err).Build()
+	}
+
+	// table files are downloaded to the temp table file directory, which isn't created when a repo is initialized
+	err = dEnv.FS.MkDirs(dEnv.TempTableFilesDir())
+
+	if err != nil {
+		return "", errhand.BuildDError("error: unable to create directory %s", dEnv.TempTableFilesDir()).AddCause(err).Build()
+	}
+
+	wg, progChan, pullerEventCh := runProgFuncs()
+	if depth > 0 {
+		err = actions.FetchToDepth(ctx, dEnv, branchRef, srcDB, dEnv.DoltDB, cm, depth, progChan, pullerEventCh)
+	} else {
+		err = actions.Fetch(ctx, dEnv, branchRef, srcDB, dEnv.DoltDB, cm, progChan, pullerEventCh)
+	}
+	stopProgFuncs(wg, progChan, pullerEventCh)
+
+	if err != nil {
+		return "", errhand.BuildDError("error: clone failed").AddCause(err).Build()
+	}
+
+	r := dEnv.RepoState.Remotes[remoteName]
+	r.FetchSpecs = []string{fmt.Sprintf("refs/heads/%s:refs/remotes/%s/%s", branch, remoteName, branch)}
+	dEnv.RepoState.Remotes[remoteName] = r
+
+	return branch, nil
+}
@@ -26,12 +26,14 @@ import (
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/merge"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/sql"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/table/pipeline"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/table/untyped"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/table/untyped/fwt"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/table/untyped/nullprinter"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/iohelp"
+	"github.com/liquidata-inc/dolt/go/store/types"
 )
 
 var catShortDesc = "print conflicts"
@@ -113,6 +115,13 @@ func printConflicts(ctx context.Context, root *doltdb.RootValue, tblNames []stri
 
 			}
 
+			if has, err := tbl.HasSchemaConflicts(); err != nil {
+				return errhand.BuildDError("failed to read conflicts").AddCause(err).Build()
+			} else if has {
+				// rows are not merged until the schema conflicts are resolved, so there are no row conflicts to print
+				return printSchemaConflicts(ctx, tblName, tbl)
+			}
+
 			cnfRd, err := merge.NewConflictReader(ctx, tbl)
 
 			if err == doltdb.ErrNoConflicts {
@@ -166,3 +175,55 @@ func printConflicts(ctx context.Context, root *doltdb.RootValue, tblNames []stri
 
 	return nil
 }
+
+func printSchemaConflicts(ctx context.Context, tblName string, tbl *doltdb.Table) errhand.VerboseError {
+	base, sch, mergeSch, err := tbl.GetConflictSchemas(ctx)
+
+	if err != nil {
+		return errhand.BuildDError("failed to read conflict schemas").AddCause(err).Build()
+	}
+
+	schConflicts, err := tbl.GetSchemaConflicts(ctx)
+
+	if err != nil {
+		return errhand.BuildDError("failed to read schema conflicts").AddCause(err).Build()
+	}
+
+	cli.Printf("Schema conflicts in table %s:\n", tblName)
+	err = schConflicts.IterAll(ctx, func(key, value types.Value) error {
+		tag := uint64(key.(types.Uint))
+
+		if tag == schema.InvalidTag {
+			cli.Printf("  %s\n", string(value.(types.String)))
+			return nil
+		}
+
+		cli.Printf("  tag %d: %s\n", tag, string(value.(types.String)))
+		cli.Println("    base:  ", fmtConflictCol(base, tag))
+		cli.Println("    ours:  ", fmtConflictCol(sch, tag))
+		cli.Println("    theirs:", fmtConflictCol(mergeSch, tag))
+		return nil
+	})
+
+	if err != nil {
+		return errhand.BuildDError("failed to read schema conflicts").AddCause(err).Build()
+	}
+
+	return nil
+}
+
+func fmtConflictCol(sch schema.Schema, tag uint64) string {
+	col, ok := sch.GetAllCols().GetByTag(tag)
+
+	if !ok {
+		return "<deleted>"
+	}
+
+	colStr := sql.FmtCol(0, 0, 0, col)
+
+	if col.IsPartOfPK {
+		colStr += " PRIMARY KEY"
+	}
+
+	return colStr
+}
@@ -36,7 +36,8 @@ var resLongDesc = "When a merge operation finds conflicting changes, the rows wi
 	"the conflicts whose keys are provided.\n" +
 	"\n" +
 	"In it's second form <b>dolt conflicts resolve --ours|--theirs <table>...</b>, resolve runs in auto resolve mode. " +
-	"where conflicts are resolved using a rule to determine which version of a row should be used."
+	"where conflicts are resolved using a rule to determine which version of a row should be used.  Tables with schema " +
+	"conflicts can only be resolved in auto resolve mode, which replaces the table with the chosen branch's version."
 var resSynopsis = []string{
 	"<table> [<key_definition>] <key>...",
 	"--ours|--theirs <table>...",
@@ -108,8 +108,14 @@ func handleCommitErr(err error, usage cli.UsagePrinter) int {
 }
 
 func getCommitMessageFromEditor(ctx context.Context, dEnv *env.DoltEnv) string {
-	var finalMsg string
 	initialMsg := buildInitalCommitMsg(ctx, dEnv)
+	return parseCommitMessage(editWithEditor(dEnv, initialMsg))
+}
+
+// editWithEditor opens the configured editor on a temporary file containing initialContents, and returns the contents
+// of the file once the editor exits.
+func editWithEditor(dEnv *env.DoltEnv, initialContents string) string {
+	var contents string
 	backupEd := "vim"
 	if ed, edSet := os.LookupEnv("EDITOR"); edSet {
 		backupEd = ed
@@ -117,10 +123,9 @@ func getCommitMessageFromEditor(ctx context.Context, dEnv *env.DoltEnv) string {
 	editorStr := dEnv.Config.GetStringOrDefault(env.DoltEditor, backupEd)
 
 	cli.ExecuteWithStdioRestored(func() {
-		commitMsg, _ := editor.OpenCommitEditor(*editorStr, initialMsg)
-		finalMsg = parseCommitMessage(commitMsg)
+		contents, _ = editor.OpenCommitEditor(*editorStr, initialContents)
 	})
-	return finalMsg
+	return contents
 }
 
 func buildInitalCommitMsg(ctx context.Context, dEnv *env.DoltEnv) string {
@@ -37,26 +37,84 @@ var fetchLongDesc = "Fetch refs, along with the objects necessary to complete th
 	"\n By default dolt will attempt to fetch from a remote named 'origin'.  The <remote> parameter allows you to " +
 	"specify the name of a different remote you wish to pull from by the remote's name." +
 	"\n" +
-	"\nWhen no refspec(s) are specified on the command line, the fetch_specs for the default remote are used."
+	"\nWhen no refspec(s) are specified on the command line, the fetch_specs for the default remote are used." +
+	"\n" +
+	"\nWith <b>--depth</b> only the given number of commits from the tip of each remote branch are fetched, and the " +
+	"commits at the boundary of the fetched history are recorded as shallow commits.  Fetching with a larger depth " +
+	"deepens the history of a shallow repository, and <b>--unshallow</b> fetches all of the history it is missing."
 var fetchSynopsis = []string{
-	"[<remote>] [<refspec> ...]",
+	"[--depth <depth>] [<remote>] [<refspec> ...]",
+	"--unshallow [<remote>] [<refspec> ...]",
 }
 
+const unshallowParam = "unshallow"
+
 func Fetch(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
 	ap := argparser.NewArgParser()
+	ap.SupportsInt(depthParam, "", "depth", "Limit fetching to the given number of commits from the tip of each remote branch.")
+	ap.SupportsFlag(unshallowParam, "", "Fetch all of the history missing from a shallow repository.")
 	help, usage := cli.HelpAndUsagePrinters(commandStr, fetchShortDesc, fetchLongDesc, fetchSynopsis, ap)
 	apr := cli.ParseArgs(ap, args, help)
 
+	depth, verr := parseDepth(apr)
+
+	if verr == nil && apr.Contains(unshallowParam) {
+		if depth != 0 {
+			verr = errhand.BuildDError("error: --%s and --%s cannot be used together", depthParam, unshallowParam).SetPrintUsage().Build()
+		} else if !dEnv.DoltDB.IsShallow() {
+			verr = errhand.BuildDError("error: --%s on a complete repository does not make sense", unshallowParam).Build()
+		}
+	}
+
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
 	remotes, _ := dEnv.GetRemotes()
 	r, refSpecs, verr := getRefSpecs(apr.Args(), dEnv, remotes)
 
+	if verr == nil && apr.Contains(unshallowParam) {
+		verr = unshallow(ctx, dEnv, r)
+	}
+
 	if verr == nil {
-		verr = fetchRefSpecs(ctx, dEnv, r, refSpecs)
+		verr = fetchRefSpecs(ctx, dEnv, r, refSpecs, depth)
 	}
 
 	return HandleVErrAndExitCode(verr, usage)
 }
 
+// parseDepth returns the value of the depth param, or 0 if it wasn't provided.
+func parseDepth(apr *argparser.ArgParseResults) (int, errhand.VerboseError) {
+	depth, ok := apr.GetInt(depthParam)
+
+	if !ok {
+		return 0, nil
+	} else if depth < 1 {
+		return 0, errhand.BuildDError("error: depth %d is not a positive number", depth).Build()
+	}
+
+	return depth, nil
+}
+
+func unshallow(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote) errhand.VerboseError {
+	srcDB, err := rem.GetRemoteDB(ctx, dEnv.DoltDB.ValueReadWriter().Format())
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to get remote db").AddCause(err).Build()
+	}
+
+	wg, progChan, pullerEventCh := runProgFuncs()
+	err = actions.Unshallow(ctx, dEnv, srcDB, progChan, pullerEventCh)
+	stopProgFuncs(wg, progChan, pullerEventCh)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to fetch the history missing from the shallow repository").AddCause(err).Build()
+	}
+
+	return nil
+}
+
 func getRefSpecs(args []string, dEnv *env.DoltEnv, remotes map[string]env.Remote) (env.Remote, []ref.RemoteRefSpec, errhand.VerboseError) {
 	if len(remotes) == 0 {
 		return env.NoRemote, nil, errhand.BuildDError("error: no remotes set").AddDetails("to add a remote run: dolt remote add <remote> <url>").Build()
@@ -130,7 +188,9 @@ func mapRefspecsToRemotes(refSpecs []ref.RemoteRefSpec, dEnv *env.DoltEnv) (map[
 	return rsToRem, nil
 }
 
-func fetchRefSpecs(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote, refSpecs []ref.RemoteRefSpec) errhand.VerboseError {
+// fetchRefSpecs fetches the refs matching the refspecs given.  If depth is greater than 0 only the given number of
+// commits are fetched from each branch, and tags are only fetched if they reference a commit in the history fetched.
+func fetchRefSpecs(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote, refSpecs []ref.RemoteRefSpec, depth int) errhand.VerboseError {
 	for _, rs := range refSpecs {
 		srcDB, err := rem.GetRemoteDB(ctx, dEnv.DoltDB.ValueReadWriter().Format())
 
@@ -144,14 +204,26 @@ func fetchRefSpecs(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote, refSp
 			return errhand.BuildDError("error: failed to read from ").AddCause(err).Build()
 		}
 
-		for _, branchRef := range branchRefs {
-			remoteTrackRef := rs.DestRef(branchRef)
+		// branches are fetched before tags so that a shallow fetch knows which tags reference fetched commits
+		for _, fetchTags := range []bool{false, true} {
+			for _, branchRef := range branchRefs {
+				if (branchRef.GetType() == ref.TagRefType) != fetchTags {
+					continue
+				}
+
+				remoteTrackRef := rs.DestRef(branchRef)
 
-			if remoteTrackRef != nil {
-				verr := fetchRemoteBranch(ctx, dEnv, rem, srcDB, dEnv.DoltDB, branchRef, remoteTrackRef)
+				if remoteTrackRef != nil {
+					var verr errhand.VerboseError
+					if fetchTags {
+						verr = fetchRemoteTag(ctx, dEnv, rem, srcDB, dEnv.DoltDB, branchRef, remoteTrackRef, depth)
+					} else {
+						verr = fetchRemoteBranch(ctx, dEnv, rem, srcDB, dEnv.DoltDB, branchRef, remoteTrackRef, depth)
+					}
 
-				if verr != nil {
-					return verr
+					if verr != nil {
+						return verr
+					}
 				}
 			}
 		}
@@ -160,7 +232,7 @@ func fetchRefSpecs(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote, refSp
 	return nil
 }
 
-func fetchRemoteBranch(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote, srcDB, destDB *doltdb.DoltDB, srcRef, destRef ref.DoltRef) errhand.VerboseError {
+func fetchRemoteBranch(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote, srcDB, destDB *doltdb.DoltDB, srcRef, destRef ref.DoltRef, depth int) errhand.VerboseError {
 	evt := events.GetEventFromContext(ctx)
 
 	u, err := earl.Parse(rem.Url)
@@ -178,7 +250,11 @@ func fetchRemoteBranch(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote, s
 		return errhand.BuildDError("error: unable to find '%s' on '%s'", srcRef.GetPath(), rem.Name).Build()
 	} else {
 		wg, progChan, pullerEventCh := runProgFuncs()
-		err = actions.Fetch(ctx, dEnv, destRef, srcDB, destDB, cm, progChan, pullerEventCh)
+		if depth > 0 {
+			err = actions.FetchToDepth(ctx, dEnv, destRef, srcDB, destDB, cm, depth, progChan, pullerEventCh)
+		} else {
+			err = actions.Fetch(ctx, dEnv, destRef, srcDB, destDB, cm, progChan, pullerEventCh)
+		}
 		stopProgFuncs(wg, progChan, pullerEventCh)
 
 		if err != nil {
@@ -188,3 +264,39 @@ func fetchRemoteBranch(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote, s
 
 	return nil
 }
+
+func fetchRemoteTag(ctx context.Context, dEnv *env.DoltEnv, rem env.Remote, srcDB, destDB *doltdb.DoltDB, srcRef, destRef ref.DoltRef, depth int) errhand.VerboseError {
+	tag, err := srcDB.ResolveTag(ctx, srcRef)
+
+	if err != nil {
+		return errhand.BuildDError("error: unable to find tag '%s' on '%s'", srcRef.GetPath(), rem.Name).Build()
+	}
+
+	if depth > 0 {
+		h, err := tag.Commit.HashOf()
+
+		if err != nil {
+			return errhand.BuildDError("error: failed to get hash of commit").AddCause(err).Build()
+		}
+
+		cs, _ := doltdb.NewCommitSpec(h.String(), "")
+		_, err = destDB.Resolve(ctx, cs)
+
+		if err == doltdb.ErrHashNotFound {
+			// the tagged commit is outside of the history fetched
+			return nil
+		} else if err != nil {
+			return errhand.BuildDError("error: failed to read from local database").AddCause(err).Build()
+		}
+	}
+
+	wg, progChan, pullerEventCh := runProgFuncs()
+	err = actions.FetchTag(ctx, dEnv, destRef, srcDB, destDB, tag, progChan, pullerEventCh)
+	stopProgFuncs(wg, progChan, pullerEventCh)
+
+	if err != nil {
+		return errhand.BuildDError("error: fetch failed").AddCause(err).Build()
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/cli"
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/errhand"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
+	"github.com/liquidata-inc/dolt/go/store/datas"
+	"github.com/liquidata-inc/dolt/go/store/nbs"
+)
+
+var gcShortDesc = "Cleans up unreferenced data from the repository"
+var gcLongDesc = "Searches the repository for data that is no longer referenced and no longer needed, and removes it.\n" +
+	"\n" +
+	"Data is kept if it can be reached from a branch, remote, tag or stash, from the working set or staged tables, " +
+	"from a merge or rebase that is in progress, or from a commit or root value recorded in the reflog, including " +
+	"the earlier working sets and staged tables recorded in the WORKING and STAGED reflogs.  Everything else, such as " +
+	"the intermediate results of imports and sql statements, is deleted.\n" +
+	"\n" +
+	"The reachable data is copied into new table files before the old ones are deleted, so the repository requires " +
+	"additional disk space while gc is running."
+var gcSynopsis = []string{
+	"",
+}
+
+func GC(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := argparser.NewArgParser()
+	help, usage := cli.HelpAndUsagePrinters(commandStr, gcShortDesc, gcLongDesc, gcSynopsis, ap)
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.NArg() != 0 {
+		usage()
+		return 1
+	}
+
+	var verr errhand.VerboseError
+	err := actions.CollectGarbage(ctx, dEnv)
+
+	if err == datas.ErrGCUnsupported {
+		verr = errhand.BuildDError("error: this repository's storage does not support garbage collection").Build()
+	} else if err == nbs.ErrGCRootMoved {
+		verr = errhand.BuildDError("error: the repository was updated while gc was running. Please try again.").Build()
+	} else if err != nil {
+		verr = errhand.BuildDError("error: failed to collect garbage").AddCause(err).Build()
+	}
+
+	return HandleVErrAndExitCode(verr, usage)
+}
@@ -121,7 +121,10 @@ func parseCommitSpec(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (*doltdb
 func logCommits(ctx context.Context, dEnv *env.DoltEnv, cs *doltdb.CommitSpec, loggerFunc commitLoggerFunc, numLines int) int {
 	commit, err := dEnv.DoltDB.Resolve(ctx, cs)
 
-	if err != nil {
+	if err == doltdb.ErrBeyondShallowBoundary {
+		cli.PrintErrln(color.HiRedString("Fatal error: the commit is beyond the history of this shallow repository."))
+		return 1
+	} else if err != nil {
 		cli.PrintErrln(color.HiRedString("Fatal error: cannot get HEAD commit for current branch."))
 		return 1
 	}
@@ -33,7 +33,10 @@ import (
 )
 
 const (
-	abortParam = "abort"
+	abortParam  = "abort"
+	squashParam = "squash"
+	noFFParam   = "no-ff"
+	ffOnlyParam = "ff-only"
 )
 
 var mergeShortDest = "Join two or more development histories together"
@@ -47,9 +50,14 @@ var mergeLongDesc = "Incorporates changes from the named commits (since the time
 	"Therefore: \n" +
 	"\n" +
 	"<b>Warning</b>: Running dolt merge with non-trivial uncommitted changes is discouraged: while possible, it may " +
-	"leave you in a state that is hard to back out of in the case of a conflict."
+	"leave you in a state that is hard to back out of in the case of a conflict.\n" +
+	"\n" +
+	"By default a merge which only needs to fast-forward the current branch does so without creating a merge commit. " +
+	"<b>--no-ff</b> always creates a merge commit, and <b>--ff-only</b> refuses to merge unless a fast-forward is " +
+	"possible.  <b>--squash</b> stages the result of the merge without making the merged commit a parent of the next " +
+	"commit, so that the changes can be committed as a single commit on top of the current branch."
 var mergeSynopsis = []string{
-	"<branch>",
+	"[--squash | --no-ff | --ff-only] <branch>",
 	"--abort",
 }
 
@@ -62,6 +70,9 @@ var abortDetails = "Abort the current conflict resolution process, and try to re
 func Merge(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
 	ap := argparser.NewArgParser()
 	ap.SupportsFlag(abortParam, "", abortDetails)
+	ap.SupportsFlag(squashParam, "", "Stage the merged tables without recording the merged commit as a parent of the next commit. The current branch is not updated, even when a fast-forward is possible.")
+	ap.SupportsFlag(noFFParam, "", "Create a merge commit even when the merge resolves as a fast-forward.")
+	ap.SupportsFlag(ffOnlyParam, "", "Refuse to merge and exit with a non-zero status unless the current branch can be fast-forwarded.")
 	help, usage := cli.HelpAndUsagePrinters(commandStr, mergeShortDest, mergeLongDesc, mergeSynopsis, ap)
 	apr := cli.ParseArgs(ap, args, help)
 
@@ -79,12 +90,20 @@ func Merge(ctx context.Context, commandStr string, args []string, dEnv *env.Dolt
 			return 1
 		}
 
+		var opts actions.MergeOpts
+		opts, verr = getMergeOpts(apr)
+
+		if verr != nil {
+			return HandleVErrAndExitCode(verr, usage)
+		}
+
 		branchName := apr.Arg(0)
 		dref, err := dEnv.FindRef(ctx, branchName)
 
 		if err != nil {
 			cli.PrintErrln(color.RedString("unknown branch: %s", branchName))
 			usage()
+			return 1
 		}
 
 		isUnchanged, _ := dEnv.IsUnchangedFromHead(ctx)
@@ -116,7 +135,7 @@ func Merge(ctx context.Context, commandStr string, args []string, dEnv *env.Dolt
 			}
 
 			if verr == nil {
-				verr = mergeBranch(ctx, dEnv, dref)
+				verr = mergeBranch(ctx, dEnv, dref, opts)
 			}
 		}
 	}
@@ -124,6 +143,20 @@ func Merge(ctx context.Context, commandStr string, args []string, dEnv *env.Dolt
 	return handleCommitErr(verr, usage)
 }
 
+func getMergeOpts(apr *argparser.ArgParseResults) (actions.MergeOpts, errhand.VerboseError) {
+	opts := actions.MergeOpts{
+		Squash: apr.Contains(squashParam),
+		NoFF:   apr.Contains(noFFParam),
+		FFOnly: apr.Contains(ffOnlyParam),
+	}
+
+	if err := opts.Validate(); err != nil {
+		return actions.MergeOpts{}, errhand.BuildDError("fatal: %s.", err.Error()).Build()
+	}
+
+	return opts, nil
+}
+
 func abortMerge(ctx context.Context, doltEnv *env.DoltEnv) errhand.VerboseError {
 	err := actions.CheckoutAllTables(ctx, doltEnv)
 
@@ -138,7 +171,7 @@ func abortMerge(ctx context.Context, doltEnv *env.DoltEnv) errhand.VerboseError
 	return errhand.BuildDError("fatal: failed to revert changes").AddCause(err).Build()
 }
 
-func mergeBranch(ctx context.Context, dEnv *env.DoltEnv, dref ref.DoltRef) errhand.VerboseError {
+func mergeBranch(ctx context.Context, dEnv *env.DoltEnv, dref ref.DoltRef, opts actions.MergeOpts) errhand.VerboseError {
 	cm1, verr := ResolveCommitWithVErr(dEnv, "HEAD", dEnv.RepoState.Head.Ref.String())
 
 	if verr != nil {
@@ -170,14 +203,71 @@ func mergeBranch(ctx context.Context, dEnv *env.DoltEnv, dref ref.DoltRef) errha
 
 	cli.Println("Updating", h1.String()+".."+h2.String())
 
-	if ok, err := cm1.CanFastForwardTo(ctx, cm2); ok {
-		return executeFFMerge(ctx, dEnv, cm2)
-	} else if err == doltdb.ErrUpToDate || err == doltdb.ErrIsAhead {
+	canFF, err := cm1.CanFastForwardTo(ctx, cm2)
+
+	if err == doltdb.ErrUpToDate || err == doltdb.ErrIsAhead {
 		cli.Println("Already up to date.")
 		return nil
+	} else if err == doltdb.ErrNoCommonAncestor && dEnv.DoltDB.IsShallow() {
+		return errhand.BuildDError("error: no common ancestor was found within the history of this shallow repository.").
+			AddDetails("Fetch more history using 'dolt fetch --depth <depth>' or 'dolt fetch --unshallow' and try again.").
+			Build()
+	} else if err != nil && err != doltdb.ErrNoCommonAncestor {
+		return errhand.BuildDError("error: failed to find the common ancestor of the commits being merged").AddCause(err).Build()
+	} else if canFF && !opts.Squash && !opts.NoFF {
+		return executeFFMerge(ctx, dEnv, cm2)
+	} else if !canFF && opts.FFOnly {
+		return errhand.BuildDError("fatal: Not possible to fast-forward, aborting.").Build()
+	}
+
+	mergedRoot, tblToStats, verr := getMergedRoot(ctx, dEnv, cm1, cm2, canFF)
+
+	if verr != nil {
+		return verr
+	}
+
+	if opts.Squash {
+		return executeSquashMerge(ctx, dEnv, mergedRoot, tblToStats)
+	}
+
+	return executeMerge(ctx, dEnv, cm2, dref, mergedRoot, tblToStats)
+}
+
+// getMergedRoot merges cm2 into cm1.  When cm2 is a descendant of cm1 the merge is done using cm1 as the merge base so
+// that the changes made by the commits being merged are still reported.
+func getMergedRoot(ctx context.Context, dEnv *env.DoltEnv, cm1, cm2 *doltdb.Commit, canFF bool) (*doltdb.RootValue, map[string]*merge.MergeStats, errhand.VerboseError) {
+	var mergedRoot *doltdb.RootValue
+	var tblToStats map[string]*merge.MergeStats
+	var err error
+	if canFF {
+		var root, mergeRoot *doltdb.RootValue
+		root, err = cm1.GetRootValue()
+
+		if err == nil {
+			mergeRoot, err = cm2.GetRootValue()
+		}
+
+		if err != nil {
+			return nil, nil, errhand.BuildDError("error: failed to get root value").AddCause(err).Build()
+		}
+
+		mergedRoot, tblToStats, err = actions.MergeRoots(ctx, dEnv.DoltDB, root, mergeRoot, root)
 	} else {
-		return executeMerge(ctx, dEnv, cm1, cm2, dref)
+		mergedRoot, tblToStats, err = actions.MergeCommits(ctx, dEnv.DoltDB, cm1, cm2)
+	}
+
+	if err != nil {
+		switch err {
+		case doltdb.ErrUpToDate:
+			return nil, nil, errhand.BuildDError("Already up to date.").AddCause(err).Build()
+		case merge.ErrFastForward:
+			return nil, nil, errhand.BuildDError("error: the merge is a fast-forward and cannot be merged as a merge commit").AddCause(err).Build()
+		default:
+			return nil, nil, errhand.BuildDError("Bad merge").AddCause(err).Build()
+		}
 	}
+
+	return mergedRoot, tblToStats, nil
 }
 
 func executeFFMerge(ctx context.Context, dEnv *env.DoltEnv, cm2 *doltdb.Commit) errhand.VerboseError {
@@ -219,20 +309,7 @@ and take the hash for your current branch and use it for the value for "staged"
 	return nil
 }
 
-func executeMerge(ctx context.Context, dEnv *env.DoltEnv, cm1, cm2 *doltdb.Commit, dref ref.DoltRef) errhand.VerboseError {
-	mergedRoot, tblToStats, err := actions.MergeCommits(ctx, dEnv.DoltDB, cm1, cm2)
-
-	if err != nil {
-		switch err {
-		case doltdb.ErrUpToDate:
-			return errhand.BuildDError("Already up to date.").AddCause(err).Build()
-		case merge.ErrFastForward:
-			panic("fast forward merge")
-		default:
-			return errhand.BuildDError("Bad merge").AddCause(err).Build()
-		}
-	}
-
+func executeMerge(ctx context.Context, dEnv *env.DoltEnv, cm2 *doltdb.Commit, dref ref.DoltRef, mergedRoot *doltdb.RootValue, tblToStats map[string]*merge.MergeStats) errhand.VerboseError {
 	h2, err := cm2.HashOf()
 
 	if err != nil {
@@ -258,6 +335,32 @@ func executeMerge(ctx context.Context, dEnv *env.DoltEnv, cm1, cm2 *doltdb.Commi
 	return verr
 }
 
+// executeSquashMerge updates the working set with the merged root without starting a merge, so the next commit has
+// only the current head as its parent.  If the merge is free of conflicts the merged root is staged as well.
+func executeSquashMerge(ctx context.Context, dEnv *env.DoltEnv, mergedRoot *doltdb.RootValue, tblToStats map[string]*merge.MergeStats) errhand.VerboseError {
+	verr := UpdateWorkingWithVErr(dEnv, mergedRoot)
+
+	if verr != nil {
+		return verr
+	}
+
+	hasConflicts := printSuccessStats(tblToStats)
+
+	if hasConflicts {
+		cli.Println("Squash commit -- not updating HEAD")
+		cli.Println("Automatic merge failed; fix conflicts and then commit the result.")
+		return nil
+	}
+
+	verr = UpdateStagedWithVErr(dEnv, mergedRoot)
+
+	if verr == nil {
+		cli.Println("Squash commit -- not updating HEAD")
+	}
+
+	return verr
+}
+
 func printSuccessStats(tblToStats map[string]*merge.MergeStats) bool {
 	printModifications(tblToStats)
 	printAdditions(tblToStats)
@@ -267,7 +370,7 @@ func printSuccessStats(tblToStats map[string]*merge.MergeStats) bool {
 
 func printAdditions(tblToStats map[string]*merge.MergeStats) {
 	for tblName, stats := range tblToStats {
-		if stats.Operation == merge.TableRemoved {
+		if stats.Operation == merge.TableAdded {
 			cli.Println(tblName, "added")
 		}
 	}
@@ -286,7 +389,14 @@ func printConflicts(tblToStats map[string]*merge.MergeStats) bool {
 	for tblName, stats := range tblToStats {
 		if stats.Operation == merge.TableModified && stats.Conflicts > 0 {
 			cli.Println("Auto-merging", tblName)
-			cli.Println("CONFLICT (content): Merge conflict in", tblName)
+
+			if stats.SchemaConflicts > 0 {
+				cli.Println("CONFLICT (schema): Merge conflict in", tblName)
+			} else if stats.ForeignKeyConflicts > 0 {
+				cli.Println("CONFLICT (foreign key): Merge conflict in", tblName)
+			} else {
+				cli.Println("CONFLICT (content): Merge conflict in", tblName)
+			}
 
 			hasConflicts = true
 		}
@@ -301,6 +411,7 @@ func printModifications(tblToStats map[string]*merge.MergeStats) {
 	rowsAdded := 0
 	rowsDeleted := 0
 	rowsChanged := 0
+	policyResolutions := 0
 	var tbls []string
 	for tblName, stats := range tblToStats {
 		if stats.Operation == merge.TableModified && stats.Conflicts == 0 {
@@ -319,6 +430,7 @@ func printModifications(tblToStats map[string]*merge.MergeStats) {
 			rowsAdded += stats.Adds
 			rowsChanged += stats.Modifications + stats.Conflicts
 			rowsDeleted += stats.Deletes
+			policyResolutions += stats.PolicyResolutions
 		}
 	}
 
@@ -343,6 +455,10 @@ func printModifications(tblToStats map[string]*merge.MergeStats) {
 
 	details := fmt.Sprintf("%d tables changed, %d rows added(+), %d rows modified(*), %d rows deleted(-)", len(tbls), rowsAdded, rowsChanged, rowsDeleted)
 	cli.Println(details)
+
+	if policyResolutions > 0 {
+		cli.Println(fmt.Sprintf("%d conflicting cell changes resolved by merge policies", policyResolutions))
+	}
 }
 
 func visualizeChangeTypes(stats *merge.MergeStats, maxMods int) string {
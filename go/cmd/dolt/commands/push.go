@@ -142,6 +142,16 @@ func Push(ctx context.Context, commandStr string, args []string, dEnv *env.DoltE
 		return 1
 	}
 
+	if _, isTagSpec := refSpec.(ref.TagToTagRefSpec); verr == nil && isTagSpec {
+		if apr.Contains(SetUpstreamFlag) {
+			verr = errhand.BuildDError("error: --set-upstream cannot be used when pushing tags.").Build()
+		} else {
+			verr = pushTags(ctx, dEnv, refSpec, remote)
+		}
+
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
 	if verr == nil {
 		hasRef, err := dEnv.DoltDB.HasRef(ctx, currentBranch)
 
@@ -272,6 +282,57 @@ func pushToRemoteBranch(ctx context.Context, dEnv *env.DoltEnv, srcRef, destRef,
 	return nil
 }
 
+// pushTags pushes each local tag matched by the tag ref spec given to the remote.
+func pushTags(ctx context.Context, dEnv *env.DoltEnv, refSpec ref.RefSpec, remote env.Remote) errhand.VerboseError {
+	tagRefs, err := dEnv.DoltDB.GetTags(ctx)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to read tags from db").AddCause(err).Build()
+	}
+
+	destDB, err := remote.GetRemoteDB(ctx, dEnv.DoltDB.ValueReadWriter().Format())
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to get remote db").AddCause(err).Build()
+	}
+
+	pushed := 0
+	for _, tagRef := range tagRefs {
+		destRef := refSpec.DestRef(tagRef)
+
+		if destRef == nil {
+			continue
+		}
+
+		pushed++
+		tag, err := dEnv.DoltDB.ResolveTag(ctx, tagRef)
+
+		if err != nil {
+			return errhand.BuildDError("error: unable to resolve tag '%s'", tagRef.GetPath()).AddCause(err).Build()
+		}
+
+		wg, progChan, pullerEventCh := runProgFuncs()
+		err = actions.PushTag(ctx, dEnv, destRef.(ref.TagRef), dEnv.DoltDB, destDB, tag, progChan, pullerEventCh)
+		stopProgFuncs(wg, progChan, pullerEventCh)
+
+		if err == doltdb.ErrUpToDate {
+			cli.Println("Everything up-to-date")
+		} else if err == actions.ErrTagExists {
+			cli.Printf("To %s\n", remote.Url)
+			cli.Printf("! [rejected]          %s -> %s (already exists)\n", tagRef.String(), destRef.String())
+			return errhand.BuildDError("error: failed to push some refs to '%s'", remote.Url).Build()
+		} else if err != nil {
+			return errhand.BuildDError("error: push failed").AddCause(err).Build()
+		}
+	}
+
+	if pushed == 0 {
+		return errhand.BuildDError("error: src refspec does not match any tags.").Build()
+	}
+
+	return nil
+}
+
 func pullerProgFunc(pullerEventCh chan datas.PullerEvent) {
 	var pos int
 	for evt := range pullerEventCh {
@@ -0,0 +1,265 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/cli"
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/errhand"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
+)
+
+const (
+	interactiveParam = "interactive"
+	continueParam    = "continue"
+)
+
+var rebaseShortDesc = "Reapply commits on top of another base commit"
+var rebaseLongDesc = "All commits made on the current branch which are not reachable from <upstream> are replayed, one " +
+	"by one and oldest first, on top of <upstream>.  The current branch is then left pointing at the last replayed " +
+	"commit.  Merge commits are not replayed.\n" +
+	"\n" +
+	"Each commit is replayed using a three-way merge in which the parent of the commit is used as the merge base.  If " +
+	"a commit results in conflicts the rebase stops, and the working set is left with the conflicts.  Resolve them " +
+	"with <b>dolt conflicts</b>, stage the result with <b>dolt add</b>, and run <b>dolt rebase --continue</b>.  " +
+	"Alternatively, <b>dolt rebase --abort</b> restores the branch to the commit it pointed at before the rebase " +
+	"started.\n" +
+	"\n" +
+	"With <b>-i</b> the list of commits which will be replayed is opened in an editor before the rebase starts.  " +
+	"Each line of the list names an action and a commit.  The lines may be reordered, and the action may be changed " +
+	"to one of:\n" +
+	"\n" +
+	"\tpick: replay the commit.\n" +
+	"\treword: replay the commit, but edit its commit message.\n" +
+	"\tsquash: replay the commit, and meld it into the previous commit.\n" +
+	"\tdrop: do not replay the commit.  Removing the line has the same effect.\n" +
+	"\n" +
+	"The working set must not have any uncommitted changes when a rebase is started."
+var rebaseSynopsis = []string{
+	"[-i] <upstream>",
+	"--continue",
+	"--abort",
+}
+
+func Rebase(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp["upstream"] = "The commit the current branch is replayed onto."
+	ap.SupportsFlag(interactiveParam, "i", "Edit the list of commits to replay before the rebase starts.")
+	ap.SupportsFlag(continueParam, "", "Commit the staged tables for the step which stopped because of conflicts, and continue the rebase.")
+	ap.SupportsFlag(abortParam, "", "Abort the rebase and restore the branch to the commit it pointed at before the rebase started.")
+	help, usage := cli.HelpAndUsagePrinters(commandStr, rebaseShortDesc, rebaseLongDesc, rebaseSynopsis, ap)
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.Contains(abortParam) || apr.Contains(continueParam) {
+		if apr.NArg() != 0 || apr.Contains(interactiveParam) || (apr.Contains(abortParam) && apr.Contains(continueParam)) {
+			usage()
+			return 1
+		}
+
+		if !dEnv.IsRebaseActive() {
+			cli.PrintErrln("fatal: No rebase in progress?")
+			return 1
+		}
+
+		if apr.Contains(abortParam) {
+			err := actions.AbortRebase(ctx, dEnv)
+
+			if err != nil {
+				return HandleVErrAndExitCode(errhand.BuildDError("fatal: failed to abort the rebase").AddCause(err).Build(), usage)
+			}
+
+			return 0
+		}
+
+		return runRebase(ctx, dEnv, usage)
+	}
+
+	if apr.NArg() != 1 {
+		usage()
+		return 1
+	}
+
+	if dEnv.IsRebaseActive() {
+		verr := errhand.BuildDError("fatal: a rebase is already in progress.").
+			AddDetails("hint: use 'dolt rebase --continue' or 'dolt rebase --abort'").Build()
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	verr := checkWorkingSetCleanForOp(ctx, dEnv, "rebase")
+
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	ontoCm, verr := ResolveCommitWithVErr(dEnv, apr.Arg(0), dEnv.RepoState.Head.Ref.String())
+
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	headCm, verr := ResolveCommitWithVErr(dEnv, "HEAD", dEnv.RepoState.Head.Ref.String())
+
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	todos, err := actions.GetRebaseTodos(ctx, dEnv.DoltDB, headCm, ontoCm)
+
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to find the commits to rebase").AddCause(err).Build(), usage)
+	}
+
+	if apr.Contains(interactiveParam) {
+		todos, verr = editRebaseTodos(ctx, dEnv, todos)
+
+		if verr != nil {
+			return HandleVErrAndExitCode(verr, usage)
+		}
+
+		if len(todos) == 0 {
+			cli.Println("Nothing to do")
+			return 0
+		}
+	} else if isUpToDate, verr := isUpToDateWith(ctx, headCm, ontoCm); verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	} else if isUpToDate {
+		cli.Printf("Current branch %s is up to date.\n", dEnv.RepoState.Head.Ref.GetPath())
+		return 0
+	}
+
+	err = actions.StartRebase(ctx, dEnv, ontoCm, todos)
+
+	if err != nil {
+		return HandleVErrAndExitCode(errhand.BuildDError("error: failed to start the rebase").AddCause(err).Build(), usage)
+	}
+
+	return runRebase(ctx, dEnv, usage)
+}
+
+// isUpToDateWith returns true if ontoCm is the merge base of headCm and ontoCm, in which case there is nothing to
+// replay.
+func isUpToDateWith(ctx context.Context, headCm, ontoCm *doltdb.Commit) (bool, errhand.VerboseError) {
+	ancCm, err := doltdb.GetCommitAncestor(ctx, headCm, ontoCm)
+
+	if err != nil {
+		return false, errhand.BuildDError("error: failed to find the merge base").AddCause(err).Build()
+	}
+
+	ancHash, err := ancCm.HashOf()
+
+	if err != nil {
+		return false, errhand.BuildDError("error: failed to find the merge base").AddCause(err).Build()
+	}
+
+	ontoHash, err := ontoCm.HashOf()
+
+	if err != nil {
+		return false, errhand.BuildDError("error: failed to read commit").AddCause(err).Build()
+	}
+
+	return ancHash == ontoHash, nil
+}
+
+func runRebase(ctx context.Context, dEnv *env.DoltEnv, usage cli.UsagePrinter) int {
+	branch := dEnv.RepoState.Rebase.Branch.Ref
+	conflict, err := actions.ContinueRebase(ctx, dEnv, func(msg string) (string, error) {
+		return getRewordMessageFromEditor(dEnv, msg), nil
+	})
+
+	if err != nil {
+		var verr errhand.VerboseError
+		switch err {
+		case actions.ErrRebaseUnresolvedConflicts:
+			verr = errhand.BuildDError("error: you have unresolved conflicts.").
+				AddDetails("hint: fix them up in the work tree, and then use 'dolt add <table>' and 'dolt rebase --continue'").Build()
+		case actions.ErrRebaseUnstagedChanges:
+			verr = errhand.BuildDError("error: you have changes which have not been staged.").
+				AddDetails("hint: stage them using 'dolt add <table>' and then run 'dolt rebase --continue'").Build()
+		case actions.ErrEmptyCommitMessage:
+			verr = errhand.BuildDError("error: aborting commit due to empty commit message.").
+				AddDetails("hint: run 'dolt rebase --continue' to try again or 'dolt rebase --abort' to give up").Build()
+		default:
+			verr = errhand.BuildDError("error: rebase failed").AddCause(err).Build()
+		}
+
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	if conflict != nil {
+		printSuccessStats(conflict.TblToStats)
+		cli.Printf("error: could not apply %s\n", conflict.Todo.Commit)
+		cli.Println("hint: Resolve all conflicts using 'dolt conflicts', then run 'dolt add <table>' and")
+		cli.Println("hint: 'dolt rebase --continue'.  To abort and get back to the state before the rebase,")
+		cli.Println("hint: run 'dolt rebase --abort'.")
+		return 1
+	}
+
+	cli.Printf("Successfully rebased and updated %s.\n", branch.String())
+	return 0
+}
+
+func editRebaseTodos(ctx context.Context, dEnv *env.DoltEnv, todos []env.RebaseTodo) ([]env.RebaseTodo, errhand.VerboseError) {
+	var sb strings.Builder
+	for _, todo := range todos {
+		sb.WriteString(todo.Action + " " + todo.Commit)
+
+		cm, err := actions.MaybeGetCommit(ctx, dEnv, todo.Commit)
+
+		if err == nil && cm != nil {
+			if meta, err := cm.GetCommitMeta(); err == nil {
+				sb.WriteString(" " + strings.SplitN(meta.Description, "\n", 2)[0])
+			}
+		}
+
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n" +
+		"# Rebase " + dEnv.RepoState.Head.Ref.GetPath() + "\n" +
+		"#\n" +
+		"# Commands:\n" +
+		"# p, pick <commit> = use commit\n" +
+		"# r, reword <commit> = use commit, but edit the commit message\n" +
+		"# s, squash <commit> = use commit, but meld into previous commit\n" +
+		"# d, drop <commit> = remove commit\n" +
+		"#\n" +
+		"# These lines can be re-ordered; they are executed from top to bottom.\n" +
+		"#\n" +
+		"# If you remove a line here THAT COMMIT WILL BE LOST.\n" +
+		"#\n" +
+		"# However, if you remove everything, the rebase will be aborted.\n")
+
+	edited := editWithEditor(dEnv, sb.String())
+	todos, err := actions.ParseRebaseTodoList(edited, todos)
+
+	if err != nil {
+		return nil, errhand.BuildDError("error: invalid todo list").AddCause(err).Build()
+	}
+
+	return todos, nil
+}
+
+func getRewordMessageFromEditor(dEnv *env.DoltEnv, msg string) string {
+	initialMsg := msg + "\n\n" +
+		"# Please enter the commit message for your changes. Lines starting\n" +
+		"# with '#' will be ignored, and an empty message aborts the commit.\n"
+
+	return strings.TrimSpace(parseCommitMessage(editWithEditor(dEnv, initialMsg)))
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/cli"
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/errhand"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
+)
+
+var refLogShortDesc = `Show the history of updates to refs`
+var refLogLongDesc = "Reference logs, or \"reflogs\", record when the tips of branches and other references were updated " +
+	"in the local repository, along with the command that updated them.\n" +
+	"\n" +
+	"With no arguments the reflog of HEAD is shown, which records every commit that HEAD has pointed at, including " +
+	"those made while checking out other branches.  The <ref> parameter may be the name of a branch, a fully qualified " +
+	"ref such as refs/tags/v1, or one of WORKING or STAGED to show the history of the working set and staged tables.\n" +
+	"\n" +
+	"Entries are shown newest first as <name>@{<n>}, and may be used anywhere a commit is expected, e.g. " +
+	"\"dolt checkout -b recovered HEAD@{2}\"."
+
+var refLogSynopsis = []string{
+	"[<ref>]",
+}
+
+func RefLog(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp["ref"] = "The ref whose log should be shown. Defaults to HEAD."
+	help, usage := cli.HelpAndUsagePrinters(commandStr, refLogShortDesc, refLogLongDesc, refLogSynopsis, ap)
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.NArg() > 1 {
+		usage()
+		return 1
+	}
+
+	name := doltdb.HeadRefLogName
+	if apr.NArg() == 1 {
+		name = apr.Arg(0)
+	}
+
+	verr := printRefLog(dEnv, name)
+
+	return HandleVErrAndExitCode(verr, usage)
+}
+
+func printRefLog(dEnv *env.DoltEnv, name string) errhand.VerboseError {
+	if dEnv.RefLog == nil {
+		return errhand.BuildDError("fatal: unable to read the reflog").Build()
+	}
+
+	logName := getRefLogName(name)
+	entries, err := dEnv.RefLog.GetEntries(logName)
+
+	if err != nil {
+		return errhand.BuildDError("fatal: unable to read the reflog for '%s'", name).AddCause(err).Build()
+	}
+
+	for i, entry := range entries {
+		cli.Printf("%s %s@{%d}: %s\n", color.YellowString(entry.NewHash.String()), name, i, entry.Message)
+	}
+
+	return nil
+}
+
+func getRefLogName(name string) string {
+	switch strings.ToUpper(name) {
+	case doltdb.HeadRefLogName, env.WorkingRefLogName, env.StagedRefLogName:
+		return strings.ToUpper(name)
+	}
+
+	if dref, err := ref.Parse(name); err == nil {
+		return dref.String()
+	}
+
+	return ref.NewBranchRef(name).String()
+}
@@ -45,7 +45,7 @@ var remoteLongDesc = "With no arguments, shows a list of existing remotes. Sever
 	"Adds a remote named <name> for the repository at <url>. The command dolt fetch <name> can " +
 	"then be used to create and update remote-tracking branches <name>/<branch>." +
 	"\n" +
-	"\nThe <url> parameter supports url schemes of http, https, aws, gs, and file.  If a url scheme does not prefix the " +
+	"\nThe <url> parameter supports url schemes of http, https, aws, s3, gs, file, and blobfs.  If a url scheme does not prefix the " +
 	"url then https is assumed.  If the <url> paramenter is in the format <organization>/<repository> then dolt will use " +
 	"the remotes.default_host from your configuration file (Which will be dolthub.com unless changed).\n" +
 	"\n" +
@@ -59,19 +59,29 @@ var remoteLongDesc = "With no arguments, shows a list of existing remotes. Sever
 	"\tenv: Looks for environment variables AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY\n" +
 	"\tfile: Uses the credentials file specified by the parameter aws-creds-file\n" +
 	"\n" +
+	"S3 compatible object store remote urls should be of the form s3://bucket/database.  They store the manifest in " +
+	"the bucket alongside the data, so no dynamo table is needed, and can be used with object stores such as MinIO and " +
+	"Ceph by setting the optional parameter s3-endpoint to the url of the object store.  Most of these stores also " +
+	"require the s3-path-style flag.  The aws-region, aws-creds-type, aws-creds-file, and aws-creds-profile parameters " +
+	"configure s3 remotes in the same way as aws remotes.\n" +
+	"\n" +
 	"GCP remote urls should be of the form gs://gcs-bucket/database and will use the credentials setup using the gcloud " +
 	"command line available from Google" +
 	"\n" +
 	"The local filesystem can be used as a remote by providing a repository url in the format file://absolute path. See" +
 	"https://en.wikipedia.org/wiki/File_URI_scheme for details." +
 	"\n" +
+	"A directory can also be used as a remote by providing a url in the format blobfs://absolute path.  Unlike file " +
+	"remotes, blobfs remotes can be pushed to safely by several users at once, and so can be shared over a network " +
+	"filesystem." +
+	"\n" +
 	"\n<b>remove, rm</b>\n" +
 	"Remove the remote named <name>. All remote-tracking branches and configuration settings" +
 	"for the remote are removed."
 
 var remoteSynopsis = []string{
 	"[-v | --verbose]",
-	"add [--aws-region <region>] [--aws-creds-type <creds-type>] [--aws-creds-file <file>] [--aws-creds-profile <profile>] <name> <url>",
+	"add [--aws-region <region>] [--aws-creds-type <creds-type>] [--aws-creds-file <file>] [--aws-creds-profile <profile>] [--s3-endpoint <url>] [--s3-path-style] <name> <url>",
 	"remove <name>",
 }
 
@@ -81,6 +91,7 @@ const (
 )
 
 var awsParams = []string{dbfactory.AWSRegionParam, dbfactory.AWSCredsTypeParam, dbfactory.AWSCredsFileParam, dbfactory.AWSCredsProfile}
+var s3Params = []string{dbfactory.S3EndpointParam, dbfactory.S3PathStyleParam}
 var credTypes = []string{dbfactory.RoleCS.String(), dbfactory.EnvCS.String(), dbfactory.FileCS.String()}
 
 func Remote(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
@@ -93,6 +104,8 @@ func Remote(ctx context.Context, commandStr string, args []string, dEnv *env.Dol
 	ap.SupportsValidatedString(dbfactory.AWSCredsTypeParam, "", "creds-type", "", argparser.ValidatorFromStrList(dbfactory.AWSCredsTypeParam, credTypes))
 	ap.SupportsString(dbfactory.AWSCredsFileParam, "", "file", "AWS credentials file")
 	ap.SupportsString(dbfactory.AWSCredsProfile, "", "profile", "AWS profile to use")
+	ap.SupportsString(dbfactory.S3EndpointParam, "", "url", "Endpoint of an S3 compatible object store")
+	ap.SupportsFlag(dbfactory.S3PathStyleParam, "", "Address s3 buckets using the path of the url rather than the hostname")
 	help, usage := cli.HelpAndUsagePrinters(commandStr, remoteShortDesc, remoteLongDesc, remoteSynopsis, ap)
 	apr := cli.ParseArgs(ap, args, help)
 
@@ -165,14 +178,14 @@ func getAbsRemoteUrl(fs filesys.Filesys, cfg config.ReadableConfig, urlArg strin
 	}
 
 	if u.Scheme != "" {
-		if u.Scheme == dbfactory.FileScheme {
-			absUrl, err := getAbsFileRemoteUrl(u.Host+u.Path, fs)
+		if u.Scheme == dbfactory.FileScheme || u.Scheme == dbfactory.BlobFSScheme {
+			absUrl, err := getAbsFileRemoteUrl(u.Scheme, u.Host+u.Path, fs)
 
 			if err != nil {
 				return "", "", err
 			}
 
-			return dbfactory.FileScheme, absUrl, err
+			return u.Scheme, absUrl, err
 		}
 
 		return u.Scheme, urlArg, nil
@@ -195,7 +208,7 @@ func getAbsRemoteUrl(fs filesys.Filesys, cfg config.ReadableConfig, urlArg strin
 	return dbfactory.HTTPSScheme, "https://" + path.Join(hostName, u.Path), nil
 }
 
-func getAbsFileRemoteUrl(urlStr string, fs filesys.Filesys) (string, error) {
+func getAbsFileRemoteUrl(scheme, urlStr string, fs filesys.Filesys) (string, error) {
 	var err error
 	urlStr = filepath.Clean(urlStr)
 	urlStr, err = fs.Abs(urlStr)
@@ -216,7 +229,7 @@ func getAbsFileRemoteUrl(urlStr string, fs filesys.Filesys) (string, error) {
 	if !strings.HasPrefix(urlStr, "/") {
 		urlStr = "/" + urlStr
 	}
-	return dbfactory.FileScheme + "://" + urlStr, nil
+	return scheme + "://" + urlStr, nil
 }
 
 func addRemote(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
@@ -262,15 +275,43 @@ func parseRemoteArgs(apr *argparser.ArgParseResults, scheme, remoteUrl string) (
 	params := map[string]string{}
 
 	var verr errhand.VerboseError
-	if scheme == dbfactory.AWSScheme {
+	switch scheme {
+	case dbfactory.AWSScheme:
 		verr = addAWSParams(remoteUrl, apr, params)
-	} else {
+
+		if verr == nil {
+			verr = verifyNoS3Params(apr)
+		}
+	case dbfactory.S3Scheme:
+		addS3Params(apr, params)
+	default:
 		verr = verifyNoAwsParams(apr)
+
+		if verr == nil {
+			verr = verifyNoS3Params(apr)
+		}
 	}
 
 	return params, verr
 }
 
+// addS3Params adds the aws credential params along with the endpoint and addressing params of s3 remotes.
+func addS3Params(apr *argparser.ArgParseResults, params map[string]string) {
+	for _, p := range awsParams {
+		if val, ok := apr.GetValue(p); ok {
+			params[p] = val
+		}
+	}
+
+	if val, ok := apr.GetValue(dbfactory.S3EndpointParam); ok {
+		params[dbfactory.S3EndpointParam] = val
+	}
+
+	if apr.Contains(dbfactory.S3PathStyleParam) {
+		params[dbfactory.S3PathStyleParam] = "true"
+	}
+}
+
 func addAWSParams(remoteUrl string, apr *argparser.ArgParseResults, params map[string]string) errhand.VerboseError {
 	isAWS := strings.HasPrefix(remoteUrl, "aws")
 
@@ -305,6 +346,16 @@ func verifyNoAwsParams(apr *argparser.ArgParseResults) errhand.VerboseError {
 	return nil
 }
 
+func verifyNoS3Params(apr *argparser.ArgParseResults) errhand.VerboseError {
+	for _, p := range s3Params {
+		if apr.Contains(p) {
+			return errhand.BuildDError("The parameter %s, is only valid for s3 remotes", p).SetPrintUsage().Build()
+		}
+	}
+
+	return nil
+}
+
 func printRemotes(dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
 	remotes, err := dEnv.GetRemotes()
 
@@ -20,8 +20,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dbfactory"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/config"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/osutil"
@@ -111,6 +114,21 @@ func TestGetAbsRemoteUrl(t *testing.T) {
 			"file",
 			false,
 		},
+		{
+			"blobfs://./test-repo",
+			config.NewMapConfig(map[string]string{}),
+			fmt.Sprintf("blobfs://%s/test-repo", cwd),
+			"blobfs",
+			false,
+		},
+		{
+			// directory doesnt exist
+			"blobfs://./doesnt_exist",
+			config.NewMapConfig(map[string]string{}),
+			"",
+			"",
+			true,
+		},
 		{
 			// directory doesnt exist
 			"file://./doesnt_exist",
@@ -143,3 +161,47 @@ func TestGetAbsRemoteUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRemoteArgs(t *testing.T) {
+	ap := argparser.NewArgParser()
+	ap.SupportsString(dbfactory.AWSRegionParam, "", "region", "")
+	ap.SupportsString(dbfactory.S3EndpointParam, "", "url", "")
+	ap.SupportsFlag(dbfactory.S3PathStyleParam, "", "")
+
+	tests := []struct {
+		args           []string
+		scheme         string
+		url            string
+		expectedParams map[string]string
+		expectErr      bool
+	}{
+		{
+			[]string{"--aws-region", "us-west-2", "--s3-endpoint", "http://localhost:9000", "--s3-path-style"},
+			dbfactory.S3Scheme,
+			"s3://bucket/db",
+			map[string]string{dbfactory.AWSRegionParam: "us-west-2", dbfactory.S3EndpointParam: "http://localhost:9000", dbfactory.S3PathStyleParam: "true"},
+			false,
+		},
+		{[]string{}, dbfactory.S3Scheme, "s3://bucket/db", map[string]string{}, false},
+		{[]string{"--aws-region", "us-west-2"}, dbfactory.AWSScheme, "aws://table:bucket/db", map[string]string{dbfactory.AWSRegionParam: "us-west-2"}, false},
+		{[]string{"--s3-endpoint", "http://localhost:9000"}, dbfactory.AWSScheme, "aws://table:bucket/db", nil, true},
+		{[]string{"--s3-path-style"}, dbfactory.HTTPSScheme, "https://dolthub.com/org/repo", nil, true},
+		{[]string{"--aws-region", "us-west-2"}, dbfactory.GSScheme, "gs://bucket/db", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprint(test.args, test.url), func(t *testing.T) {
+			apr, err := ap.Parse(test.args)
+			require.NoError(t, err)
+
+			params, verr := parseRemoteArgs(apr, test.scheme, test.url)
+
+			if test.expectErr {
+				assert.NotNil(t, verr)
+			} else {
+				assert.Nil(t, verr)
+				assert.Equal(t, test.expectedParams, params)
+			}
+		})
+	}
+}
@@ -59,101 +59,49 @@ func Reset(ctx context.Context, commandStr string, args []string, dEnv *env.Dolt
 	help, usage := cli.HelpAndUsagePrinters(commandStr, resetShortDesc, resetLongDesc, resetSynopsis, ap)
 	apr := cli.ParseArgs(ap, args, help)
 
-	workingRoot, stagedRoot, headRoot, verr := getAllRoots(ctx, dEnv)
-
-	if verr == nil {
-		if apr.ContainsAll(HardResetParam, SoftResetParam) {
-			verr = errhand.BuildDError("error: --%s and --%s are mutually exclusive options.", HardResetParam, SoftResetParam).Build()
-		} else if apr.Contains(HardResetParam) {
-			verr = resetHard(ctx, dEnv, apr, workingRoot, headRoot)
-		} else {
-			verr = resetSoft(ctx, dEnv, apr, stagedRoot, headRoot)
-		}
+	var verr errhand.VerboseError
+	if apr.ContainsAll(HardResetParam, SoftResetParam) {
+		verr = errhand.BuildDError("error: --%s and --%s are mutually exclusive options.", HardResetParam, SoftResetParam).Build()
+	} else if apr.Contains(HardResetParam) {
+		verr = resetHard(ctx, dEnv, apr)
+	} else {
+		verr = resetSoft(ctx, dEnv, apr)
 	}
 
 	return HandleVErrAndExitCode(verr, usage)
 }
 
-func resetHard(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults, workingRoot, headRoot *doltdb.RootValue) errhand.VerboseError {
+func resetHard(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
 	if apr.NArg() != 0 {
 		return errhand.BuildDError("--%s does not support additional params", HardResetParam).SetPrintUsage().Build()
 	}
 
-	// need to save the state of files that aren't tracked
-	untrackedTables := make(map[string]*doltdb.Table)
-	wTblNames, err := workingRoot.GetTableNames(ctx)
+	err := actions.ResetHard(ctx, dEnv)
 
 	if err != nil {
-		return errhand.BuildDError("error: failed to read tables from the working set").AddCause(err).Build()
+		return errhand.BuildDError("error: failed to reset the working and staged tables.").AddCause(err).Build()
 	}
 
-	for _, tblName := range wTblNames {
-		untrackedTables[tblName], _, err = workingRoot.GetTable(ctx, tblName)
-
-		if err != nil {
-			return errhand.BuildDError("error: failed to read '%s' from the working set", tblName).AddCause(err).Build()
-		}
-	}
+	return nil
+}
 
-	headTblNames, err := headRoot.GetTableNames(ctx)
+func resetSoft(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	stagedRoot, err := actions.ResetSoft(ctx, dEnv, apr.Args())
 
 	if err != nil {
-		return errhand.BuildDError("error: failed to read tables from head").AddCause(err).Build()
-	}
+		if actions.IsTblNotExist(err) {
+			bdr := errhand.BuildDError("Invalid Table(s):")
 
-	for _, tblName := range headTblNames {
-		delete(untrackedTables, tblName)
-	}
-
-	newWkRoot := headRoot
-	for tblName, tbl := range untrackedTables {
-		newWkRoot, err = newWkRoot.PutTable(ctx, tblName, tbl)
+			for _, tbl := range actions.GetTablesForError(err) {
+				bdr.AddDetails("\t" + tbl)
+			}
 
-		if err != nil {
-			return errhand.BuildDError("error: failed to write table back to database").Build()
+			return bdr.Build()
 		}
-	}
-
-	// TODO: update working and staged in one repo_state write.
-	err = dEnv.UpdateWorkingRoot(ctx, newWkRoot)
-
-	if err != nil {
-		return errhand.BuildDError("error: failed to update the working tables.").AddCause(err).Build()
-	}
 
-	_, err = dEnv.UpdateStagedRoot(ctx, headRoot)
-
-	if err != nil {
 		return errhand.BuildDError("error: failed to update the staged tables.").AddCause(err).Build()
 	}
 
-	return nil
-}
-
-func resetSoft(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults, stagedRoot, headRoot *doltdb.RootValue) errhand.VerboseError {
-	tbls := apr.Args()
-
-	if len(tbls) == 0 || (len(tbls) == 1 && tbls[0] == ".") {
-		var err error
-		tbls, err = actions.AllTables(ctx, stagedRoot, headRoot)
-
-		if err != nil {
-			return errhand.BuildDError("error: failed to get all tables").AddCause(err).Build()
-		}
-	}
-
-	verr := ValidateTablesWithVErr(tbls, stagedRoot, headRoot)
-
-	if verr != nil {
-		return verr
-	}
-
-	stagedRoot, verr = resetStaged(ctx, dEnv, tbls, stagedRoot, headRoot)
-
-	if verr != nil {
-		return verr
-	}
-
 	printNotStaged(ctx, dEnv, stagedRoot)
 	return nil
 }
@@ -187,35 +135,3 @@ func printNotStaged(ctx context.Context, dEnv *env.DoltEnv, staged *doltdb.RootV
 		cli.Println(strings.Join(lines, "\n"))
 	}
 }
-
-func resetStaged(ctx context.Context, dEnv *env.DoltEnv, tbls []string, staged, head *doltdb.RootValue) (*doltdb.RootValue, errhand.VerboseError) {
-	updatedRoot, err := staged.UpdateTablesFromOther(ctx, tbls, head)
-
-	if err != nil {
-		return nil, errhand.BuildDError("error: failed to update tables").AddCause(err).Build()
-	}
-
-	return updatedRoot, UpdateStagedWithVErr(dEnv, updatedRoot)
-}
-
-func getAllRoots(ctx context.Context, dEnv *env.DoltEnv) (*doltdb.RootValue, *doltdb.RootValue, *doltdb.RootValue, errhand.VerboseError) {
-	workingRoot, err := dEnv.WorkingRoot(ctx)
-
-	if err != nil {
-		return nil, nil, nil, errhand.BuildDError("Unable to get staged.").AddCause(err).Build()
-	}
-
-	stagedRoot, err := dEnv.StagedRoot(ctx)
-
-	if err != nil {
-		return nil, nil, nil, errhand.BuildDError("Unable to get staged.").AddCause(err).Build()
-	}
-
-	headRoot, err := dEnv.HeadRoot(ctx)
-
-	if err != nil {
-		return nil, nil, nil, errhand.BuildDError("Unable to get at HEAD.").AddCause(err).Build()
-	}
-
-	return workingRoot, stagedRoot, headRoot, nil
-}
@@ -0,0 +1,115 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/cli"
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/errhand"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
+)
+
+var revertShortDesc = "Revert some existing commits"
+var revertLongDesc = "Given one or more existing commits, revert the changes that the related commits introduced, and " +
+	"record a new commit for each of them.  Unlike <b>dolt reset --hard</b>, revert does not rewrite history, so it " +
+	"is safe to use on branches which have already been pushed.\n" +
+	"\n" +
+	"The inverse of each commit is computed using a three-way merge in which the commit being reverted is used as " +
+	"the merge base and its parent is merged into the current branch.  If a row changed by the commit has since " +
+	"been changed again the merge results in a conflict.  In that case the working set is updated with the " +
+	"conflicts, no commit is made, and any remaining commits are not reverted.  Resolve the conflicts with " +
+	"<b>dolt conflicts</b>, then use <b>dolt add</b> and <b>dolt commit</b> to record the result.\n" +
+	"\n" +
+	"The working set must not have any uncommitted changes when revert is run."
+var revertSynopsis = []string{
+	"<commit>...",
+}
+
+func Revert(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp["commit"] = "Commits to revert.  Each commit is reverted in the order given."
+	help, usage := cli.HelpAndUsagePrinters(commandStr, revertShortDesc, revertLongDesc, revertSynopsis, ap)
+	apr := cli.ParseArgs(ap, args, help)
+
+	if apr.NArg() == 0 {
+		usage()
+		return 1
+	}
+
+	verr := checkWorkingSetCleanForOp(ctx, dEnv, "revert")
+
+	if verr != nil {
+		return HandleVErrAndExitCode(verr, usage)
+	}
+
+	// resolve all the commits up front so that a bad argument doesn't leave some of them reverted.
+	var commits []*doltdb.Commit
+	for _, cSpecStr := range apr.Args() {
+		cm, verr := ResolveCommitWithVErr(dEnv, cSpecStr, dEnv.RepoState.Head.Ref.String())
+
+		if verr != nil {
+			return HandleVErrAndExitCode(verr, usage)
+		}
+
+		commits = append(commits, cm)
+	}
+
+	for i, cm := range commits {
+		msg, verr := getRevertMessage(cm)
+
+		if verr != nil {
+			return HandleVErrAndExitCode(verr, usage)
+		}
+
+		headRoot, err := dEnv.HeadRoot(ctx)
+
+		if err != nil {
+			return HandleVErrAndExitCode(errhand.BuildDError("error: failed to get the root value of HEAD").AddCause(err).Build(), usage)
+		}
+
+		revertedRoot, tblToStats, err := actions.Revert(ctx, dEnv.DoltDB, headRoot, cm)
+
+		if err != nil {
+			return HandleVErrAndExitCode(buildCommitOpErr("revert", apr.Arg(i), err), usage)
+		}
+
+		if res := commitMergedRoot(ctx, dEnv, revertedRoot, tblToStats, msg, usage); res != 0 {
+			return res
+		}
+	}
+
+	return 0
+}
+
+func getRevertMessage(cm *doltdb.Commit) (string, errhand.VerboseError) {
+	meta, err := cm.GetCommitMeta()
+
+	if err != nil {
+		return "", errhand.BuildDError("error: failed to read commit metadata").AddCause(err).Build()
+	}
+
+	h, err := cm.HashOf()
+
+	if err != nil {
+		return "", errhand.BuildDError("error: failed to get commit hash").AddCause(err).Build()
+	}
+
+	return fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s.", meta.Description, h.String()), nil
+}
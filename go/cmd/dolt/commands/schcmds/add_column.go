@@ -45,7 +45,7 @@ var schAddColSynopsis = []string{
 func AddColumn(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
 	ap := argparser.NewArgParser()
 	ap.ArgListHelp["table"] = "table where the new column should be added."
-	ap.SupportsString(defaultParam, "", "default-value", "If provided all existing rows will be given this value as their default.")
+	ap.SupportsString(defaultParam, "", "default-value", "If provided all existing rows will be given this value, and it will be the default value of the column for rows added later.")
 	ap.SupportsUint(tagParam, "", "tag-number", "The numeric tag for the new column.")
 	ap.SupportsFlag(notNullFlag, "", "If provided rows without a value in this column will be considered invalid.  If rows already exist and not-null is specified then a default value must be provided.")
 
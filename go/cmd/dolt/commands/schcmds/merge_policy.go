@@ -0,0 +1,153 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schcmds
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/cli"
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/commands"
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/errhand"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/alterschema"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
+)
+
+var schMergePolicyShortDesc = "Shows or sets the merge policies of a table's columns."
+var schMergePolicyLongDesc = "A merge policy determines how <b>dolt merge</b> handles a cell which was changed to " +
+	"different values on both branches.  Without a policy such a change is a conflict.  The supported policies are:\n" +
+	"\n" +
+	"<b>none</b> - report a conflict.  This is the default.\n" +
+	"<b>ours</b> - take the value from the branch being merged into.\n" +
+	"<b>theirs</b> - take the value from the branch being merged.\n" +
+	"<b>max</b> - take the larger value.\n" +
+	"<b>min</b> - take the smaller value.\n" +
+	"<b>sum-of-deltas</b> - add the changes made on both branches to the common ancestor's value.  Useful for counters.\n" +
+	"<b>latest-by:<column></b> - take the value from the version of the row with the larger value in <column>, which " +
+	"must be a timestamp or integer column.\n" +
+	"\n" +
+	"In its first form <b>dolt schema merge-policy <table></b> lists the default merge policy of the table and the " +
+	"merge policy used for each of its columns.\n" +
+	"\n" +
+	"In its second form <b>dolt schema merge-policy <table> <policy></b> sets the default policy of the table.  The " +
+	"default policy is used for every column which isn't part of the primary key and doesn't have a policy of its own, " +
+	"including columns added later.  Policies which can't be used with the type of a column aren't applied to it.\n" +
+	"\n" +
+	"In its third form <b>dolt schema merge-policy <table> <column> <policy></b> sets the policy of a single column."
+var schMergePolicySynopsis = []string{
+	"<table>",
+	"<table> <policy>",
+	"<table> <column> <policy>",
+}
+
+func MergePolicy(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp["table"] = "table whose merge policies are being displayed or set."
+	ap.ArgListHelp["column"] = "column whose merge policy is being set."
+	ap.ArgListHelp["policy"] = "the merge policy."
+
+	help, usage := cli.HelpAndUsagePrinters(commandStr, schMergePolicyShortDesc, schMergePolicyLongDesc, schMergePolicySynopsis, ap)
+	apr := cli.ParseArgs(ap, args, help)
+
+	root, verr := commands.GetWorkingWithVErr(dEnv)
+
+	if verr == nil {
+		verr = mergePolicy(ctx, apr, root, dEnv)
+	}
+
+	return commands.HandleVErrAndExitCode(verr, usage)
+}
+
+func mergePolicy(ctx context.Context, apr *argparser.ArgParseResults, root *doltdb.RootValue, dEnv *env.DoltEnv) errhand.VerboseError {
+	if apr.NArg() == 0 || apr.NArg() > 3 {
+		return errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	tblName := apr.Arg(0)
+	if has, err := root.HasTable(ctx, tblName); err != nil {
+		return errhand.BuildDError("error: failed to read tables from database").AddCause(err).Build()
+	} else if !has {
+		return errhand.BuildDError(tblName + " not found").Build()
+	}
+
+	tbl, _, err := root.GetTable(ctx, tblName)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to get table '%s'", tblName).AddCause(err).Build()
+	}
+
+	if apr.NArg() == 1 {
+		return printMergePolicies(ctx, tbl)
+	}
+
+	var colName, policyStr string
+	if apr.NArg() == 2 {
+		policyStr = apr.Arg(1)
+	} else {
+		colName, policyStr = apr.Arg(1), apr.Arg(2)
+	}
+
+	newTbl, err := alterschema.SetMergePolicy(ctx, dEnv.DoltDB, tbl, colName, policyStr)
+
+	if err != nil {
+		return errToVerboseErr(colName, "", err)
+	}
+
+	root, err = root.PutTable(ctx, tblName, newTbl)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to write table back to database").Build()
+	}
+
+	return commands.UpdateWorkingWithVErr(dEnv, root)
+}
+
+func printMergePolicies(ctx context.Context, tbl *doltdb.Table) errhand.VerboseError {
+	sch, err := tbl.GetSchema(ctx)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to get schema").AddCause(err).Build()
+	}
+
+	nonPKCols := sch.GetNonPKCols()
+
+	maxNameLen := 0
+	for _, name := range nonPKCols.GetColumnNames() {
+		if len(name) > maxNameLen {
+			maxNameLen = len(name)
+		}
+	}
+
+	cli.Println("table default:", sch.MergePolicy().Format(sch))
+	cli.Println()
+
+	format := fmt.Sprintf("%%-%ds  %%s", maxNameLen)
+	_ = nonPKCols.Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		mp := schema.MergePolicyOf(sch, col)
+		policyStr := mp.Format(sch)
+
+		if col.MergePolicy.Type == schema.NoMergePolicy && mp.Type != schema.NoMergePolicy {
+			policyStr += " (table default)"
+		}
+
+		cli.Println(fmt.Sprintf(format, col.Name, policyStr))
+		return false, nil
+	})
+
+	return nil
+}
@@ -113,7 +113,7 @@ func printSchemas(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env
 			if !ok {
 				notFound = append(notFound, tblName)
 			} else {
-				verr = printTblSchema(ctx, cmStr, tblName, tbl)
+				verr = printTblSchema(ctx, root, cmStr, tblName, tbl)
 				cli.Println()
 			}
 		}
@@ -126,7 +126,7 @@ func printSchemas(ctx context.Context, apr *argparser.ArgParseResults, dEnv *env
 	return verr
 }
 
-func printTblSchema(ctx context.Context, cmStr string, tblName string, tbl *doltdb.Table) errhand.VerboseError {
+func printTblSchema(ctx context.Context, root *doltdb.RootValue, cmStr string, tblName string, tbl *doltdb.Table) errhand.VerboseError {
 	cli.Println(bold.Sprint(tblName), "@", cmStr)
 	sch, err := tbl.GetSchema(ctx)
 
@@ -134,7 +134,13 @@ func printTblSchema(ctx context.Context, cmStr string, tblName string, tbl *dolt
 		return errhand.BuildDError("unable to get schema").AddCause(err).Build()
 	}
 
-	cli.Println(sql.SchemaAsCreateStmt(tblName, sch))
+	createStmt, err := sql.SchemaAsCreateStmtWithForeignKeys(ctx, root, tblName, sch)
+
+	if err != nil {
+		return errhand.BuildDError("unable to get schema").AddCause(err).Build()
+	}
+
+	cli.Println(createStmt)
 	return nil
 }
 
@@ -71,12 +71,18 @@ Reasonably well supported functionality:
 * ORDER BY and LIMIT clauses
 * GROUP BY
 * Aggregate functions, e.g. SUM 
+* Querying a table as it was at a commit, branch or time with AS OF, e.g.
+  SELECT * FROM mytable AS OF 'HEAD~1', or SELECT * FROM mytable AS OF '2019-12-01 10:00:00'
+* Version control functions DOLT_ADD, DOLT_COMMIT, DOLT_CHECKOUT, DOLT_BRANCH, DOLT_MERGE and DOLT_RESET, which take
+  the same arguments as the equivalent dolt commands, e.g. SELECT DOLT_COMMIT('-m', 'my message')
+* Transactions with BEGIN or START TRANSACTION, COMMIT and ROLLBACK.  Changes are written to the working set as each
+  statement completes, or when the transaction is committed.  SET autocommit = 0 starts a transaction with the next
+  statement.
 
 Known limitations:
 * Some expressions in SELECT statements
 * Subqueries
-* Non-primary indexes
-* Foreign keys
+* Foreign key actions other than RESTRICT and NO ACTION
 * Column constraints besides NOT NULL
 * VARCHAR columns are unlimited length; FLOAT, INTEGER columns are 64 bit
 * Performance is very bad for many SELECT statements, especially JOINs
@@ -106,8 +112,6 @@ func Sql(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEn
 		return HandleVErrAndExitCode(verr, usage)
 	}
 
-	origRoot := root
-
 	// run a single command and exit
 	if query, ok := apr.GetValue(queryFlag); ok {
 		se, err := newSqlEngine(dEnv, dsqle.NewDatabase("dolt", root, dEnv))
@@ -116,11 +120,8 @@ func Sql(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEn
 		}
 		if err := processQuery(ctx, query, se); err != nil {
 			return HandleVErrAndExitCode(errhand.VerboseErrorFromError(err), usage)
-		} else if se.db.Root() != origRoot {
-			return HandleVErrAndExitCode(UpdateWorkingWithVErr(dEnv, se.db.Root()), usage)
-		} else {
-			return 0
 		}
+		return 0
 	}
 
 	// Run in either batch mode for piped input, or shell mode for interactive
@@ -149,9 +150,13 @@ func Sql(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEn
 		}
 	}
 
-	// If the SQL session wrote a new root value, update the working set with it
-	if se.db.Root() != origRoot {
-		return HandleVErrAndExitCode(UpdateWorkingWithVErr(dEnv, se.db.Root()), usage)
+	// as in MySQL, a transaction which is still open when the session ends is rolled back
+	if se.sess.InTransaction() {
+		if displayStrLen > 0 {
+			cli.PrintErrln()
+		}
+
+		cli.PrintErrln(color.YellowString("The open transaction was rolled back."))
 	}
 
 	return 0
@@ -211,7 +216,7 @@ func runBatchMode(ctx context.Context, se *sqlEngine) error {
 		return err
 	}
 
-	return nil
+	return se.publish(ctx)
 }
 
 // batchInsertEarlySemicolon loops through a string to check if Scan stopped too early on a semicolon
@@ -427,8 +432,57 @@ func prepend(s string, ss []string) []string {
 	return newSs
 }
 
-// Processes a single query. The Root of the sqlEngine will be updated if necessary.
+// Processes a single query in the session of the sqlEngine. The Root of the sqlEngine will be updated if necessary,
+// and any changes made outside of a transaction are written to the working set.
 func processQuery(ctx context.Context, query string, se *sqlEngine) error {
+	query = dsqle.RewriteAsOf(query)
+
+	// changes flushed in batch mode are written before the statement, which may begin a transaction
+	if err := se.publish(ctx); err != nil {
+		return err
+	}
+
+	// version control functions operate on the working set directly, so the open transaction is written to it first
+	if se.sess.InTransaction() && dsqle.ImplicitlyCommits(query) {
+		if err := se.sess.Commit(ctx); err != nil {
+			return err
+		}
+
+		if err := se.publish(ctx); err != nil {
+			return err
+		}
+	}
+
+	err := se.sess.Exec(ctx, []*dsqle.Database{se.db}, query, func() error {
+		return execQuery(ctx, query, se)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return se.publish(ctx)
+}
+
+// Executes a single query. The Root of the sqlEngine will be updated if necessary.
+func execQuery(ctx context.Context, query string, se *sqlEngine) error {
+	// the parser discards the details of index and foreign key statements, so they're handled before parsing
+	if indexDDL, ok, err := dsqle.ParseIndexDDL(query); ok {
+		if err != nil {
+			return fmt.Errorf("Error parsing DDL: %v.", err.Error())
+		}
+
+		return dsqle.ExecuteIndexDDL(ctx, se.db, indexDDL)
+	}
+
+	if fkDDL, ok, err := dsqle.ParseForeignKeyDDL(query); ok {
+		if err != nil {
+			return fmt.Errorf("Error parsing DDL: %v.", err.Error())
+		}
+
+		return dsqle.ExecuteForeignKeyDDL(ctx, se.db, fkDDL)
+	}
+
 	sqlStatement, err := sqlparser.Parse(query)
 	if err == sqlparser.ErrEmpty {
 		// silently skip empty statements
@@ -481,6 +535,7 @@ const updateInterval = 500
 
 // Processes a single query in batch mode. The Root of the sqlEngine may or may not be changed.
 func processBatchQuery(ctx context.Context, query string, se *sqlEngine) error {
+	query = dsqle.RewriteAsOf(query)
 	sqlStatement, err := sqlparser.Parse(query)
 	if err == sqlparser.ErrEmpty {
 		// silently skip empty statements
@@ -491,6 +546,7 @@ func processBatchQuery(ctx context.Context, query string, se *sqlEngine) error {
 
 	switch sqlStatement.(type) {
 	case *sqlparser.Insert:
+		se.sess.BeginImplicitly([]*dsqle.Database{se.db})
 		_, rowIter, err := se.query(ctx, query)
 		if err != nil {
 			return fmt.Errorf("Error inserting rows: %v", err.Error())
@@ -506,6 +562,11 @@ func processBatchQuery(ctx context.Context, query string, se *sqlEngine) error {
 			if err != nil {
 				return err
 			}
+
+			err = se.publish(ctx)
+			if err != nil {
+				return err
+			}
 		}
 
 		if batchEditStats.numRowsInserted%updateInterval == 0 {
@@ -554,12 +615,17 @@ type sqlEngine struct {
 	db     *dsqle.Database
 	dEnv   *env.DoltEnv
 	engine *sqle.Engine
+	sess   *dsqle.Session
+
+	// published is the root last read from or written to the working set
+	published *doltdb.RootValue
 }
 
 // sqlEngine packages up the context necessary to run sql queries against sqle.
 func newSqlEngine(dEnv *env.DoltEnv, db *dsqle.Database) (*sqlEngine, error) {
 	engine := sqle.NewDefault()
 	engine.AddDatabase(db)
+	engine.Catalog.MustRegister(dsqle.VersionControlFunctions(engine.Catalog)...)
 
 	// SQL engine still gives buggy results with indexes on
 	if _, ok := os.LookupEnv(UseIndexesEnv); ok {
@@ -570,12 +636,69 @@ func newSqlEngine(dEnv *env.DoltEnv, db *dsqle.Database) (*sqlEngine, error) {
 		}
 	}
 
-	return &sqlEngine{db, dEnv, engine}, nil
+	return &sqlEngine{db, dEnv, engine, dsqle.NewSession(false), db.Root()}, nil
+}
+
+// publish writes the changes made to the database outside of a transaction to the working set.  Other processes may
+// have changed the working set since it was last read, so the changes are merged into its current root.  If they
+// conflict, they're discarded and the database is reset to the current working root.  In batch mode, inserted rows are
+// only written once they've been flushed.
+func (se *sqlEngine) publish(ctx context.Context) error {
+	if se.sess.InTransaction() || se.db.Root() == se.published {
+		return nil
+	}
+
+	rs := se.dEnv.RepoState
+	lockCtx, err := rs.Lock(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	err = se.publishLocked(lockCtx)
+
+	if unlockErr := rs.Unlock(); err == nil {
+		err = unlockErr
+	}
+
+	return err
+}
+
+func (se *sqlEngine) publishLocked(ctx context.Context) error {
+	if err := se.dEnv.RepoState.Reload(); err != nil {
+		return err
+	}
+
+	if err := se.dEnv.DoltDB.Refresh(ctx); err != nil {
+		return err
+	}
+
+	working, err := se.dEnv.WorkingRoot(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	merged, err := dsqle.MergeTransaction(ctx, se.dEnv.DoltDB, working, se.published, se.db.Root())
+
+	if err != nil {
+		se.db.SetRoot(working)
+		se.published = working
+		return err
+	}
+
+	if err := se.dEnv.UpdateWorkingRoot(ctx, merged); err != nil {
+		return err
+	}
+
+	se.db.SetRoot(merged)
+	se.published = merged
+	return nil
 }
 
 // Execute a SQL statement and return values for printing.
 func (se *sqlEngine) query(ctx context.Context, query string) (sql.Schema, sql.RowIter, error) {
-	sqlCtx := sql.NewContext(ctx)
+	sqlCtx := sql.NewContext(ctx, sql.WithQuery(query))
 	return se.engine.Query(sqlCtx, query)
 }
 
@@ -769,11 +892,25 @@ func (se *sqlEngine) checkThenDeleteAllRows(ctx context.Context, s *sqlparser.De
 func (se *sqlEngine) ddl(ctx context.Context, ddl *sqlparser.DDL, query string) error {
 	switch ddl.Action {
 	case sqlparser.CreateStr, sqlparser.DropStr:
+		if err := dsqle.CheckDropTables(ctx, se.db, query); err != nil {
+			return err
+		}
+
+		root := se.db.Root()
 		_, ri, err := se.query(ctx, query)
-		if err == nil {
-			ri.Close()
+		if err != nil {
+			return err
 		}
-		return err
+		ri.Close()
+
+		// the engine creates tables without their foreign keys, which are added once the table exists
+		for _, fk := range dsqle.ParseCreateTableForeignKeys(query) {
+			if err := dsqle.ExecuteForeignKeyDDL(ctx, se.db, fk); err != nil {
+				se.db.SetRoot(root)
+				return err
+			}
+		}
+		return nil
 	case sqlparser.AlterStr, sqlparser.RenameStr:
 		newRoot, err := dsql.ExecuteAlter(ctx, se.dEnv.DoltDB, se.db.Root(), ddl, query)
 		if err != nil {
@@ -0,0 +1,405 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/src-d/go-mysql-server/sql"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+	dsqle "github.com/liquidata-inc/dolt/go/libraries/doltcore/sqle"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+)
+
+const (
+	// singleRepoDBName is the name of the database used when the server is serving only the repository it was started in.
+	singleRepoDBName = "dolt"
+
+	// branchDBSeparator separates the name of a repository from the name of a branch in the name of a database serving a
+	// branch, as in `mydb/feature-x`.
+	branchDBSeparator = "/"
+
+	// commitDBSeparator separates the name of a repository from a commit spec in the name of a database serving a
+	// commit, as in `mydb@<hash>`.
+	commitDBSeparator = "@"
+)
+
+// repoDatabase is a repository served by the server.  Its database serves the working root of the repository, and any
+// changes made to it are written back to the working root as statements complete.
+type repoDatabase struct {
+	name string
+	dEnv *env.DoltEnv
+	db   *dsqle.Database
+
+	// working is the hash of the working root the database was last synced with.
+	working string
+}
+
+// revisionDatabase is a read-only database serving a branch or commit of a repository.  A database serving a branch
+// follows the branch as new commits are made to it.
+type revisionDatabase struct {
+	repo   *repoDatabase
+	branch ref.DoltRef
+	db     *dsqle.Database
+
+	// commit is the hash of the commit the database is serving.
+	commit hash.Hash
+}
+
+// serverDatabases tracks the databases served by the server.  The working roots of repositories are served by databases
+// named after the repository, and databases serving branches and commits are added to the catalog the first time they're
+// referenced.
+type serverDatabases struct {
+	catalog *sql.Catalog
+	repos   []*repoDatabase
+
+	// mu guards revisions, which are added by read-only statements running at the same time as each other
+	mu        *sync.Mutex
+	revisions map[string]*revisionDatabase
+}
+
+func newServerDatabases(ctx context.Context, catalog *sql.Catalog, dEnvs map[string]*env.DoltEnv) (*serverDatabases, error) {
+	names := make([]string, 0, len(dEnvs))
+	for name := range dEnvs {
+		names = append(names, name)
+	}
+
+	// the repositories are sorted so that the default database doesn't depend on the order of the map
+	sort.Strings(names)
+
+	sd := &serverDatabases{catalog, nil, &sync.Mutex{}, make(map[string]*revisionDatabase)}
+	for _, name := range names {
+		dEnv := dEnvs[name]
+		root, err := dEnv.WorkingRoot(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		db := dsqle.NewDatabase(name, root, dEnv)
+		catalog.AddDatabase(db)
+		sd.repos = append(sd.repos, &repoDatabase{name, dEnv, db, dEnv.RepoState.Working})
+	}
+
+	return sd, nil
+}
+
+// loadMultiRepoEnvs loads every dolt repository in a subdirectory of dir.  Each is named after its subdirectory.
+func loadMultiRepoEnvs(ctx context.Context, dir string) (map[string]*env.DoltEnv, error) {
+	var subDirs []string
+	err := filesys.LocalFS.Iter(dir, false, func(path string, size int64, isDir bool) (stop bool) {
+		if isDir {
+			subDirs = append(subDirs, path)
+		}
+
+		return false
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	dEnvs := make(map[string]*env.DoltEnv)
+	for _, subDir := range subDirs {
+		dEnv, err := env.LoadFromDir(ctx, env.GetCurrentUserHomeDir, subDir)
+
+		if err != nil {
+			return nil, err
+		} else if !dEnv.HasDoltDir() {
+			continue
+		} else if dEnv.RSLoadErr != nil {
+			return nil, fmt.Errorf("failed to load the repository state of %s: %v", subDir, dEnv.RSLoadErr)
+		} else if dEnv.DBLoadError != nil {
+			return nil, fmt.Errorf("failed to load the database in %s: %v", subDir, dEnv.DBLoadError)
+		}
+
+		dEnvs[filepath.Base(subDir)] = dEnv
+	}
+
+	if len(dEnvs) == 0 {
+		return nil, fmt.Errorf("no dolt repositories found in %s", dir)
+	}
+
+	return dEnvs, nil
+}
+
+// defaultDBName returns the name of the database used by connections which haven't selected one.
+func (sd *serverDatabases) defaultDBName() string {
+	return sd.repos[0].name
+}
+
+// sync rereads the repo state of every repository, and refreshes every database whose working root or branch was
+// changed by another process since the last statement.  It must not be called while other statements are running.
+func (sd *serverDatabases) sync(ctx context.Context) error {
+	for _, repo := range sd.repos {
+		if err := repo.sync(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, rev := range sd.branchRevisions() {
+		cm, err := resolveBranch(ctx, rev.repo.dEnv.DoltDB, rev.branch)
+
+		if err != nil {
+			// the branch may have been deleted, in which case the database keeps serving the last commit it saw
+			continue
+		}
+
+		if err := rev.setCommit(cm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stale returns whether another process has changed the repo state of any repository, or the head of any branch being
+// served, since the databases were last synced.  It doesn't change the databases, so it can be called while other
+// read-only statements are running.
+func (sd *serverDatabases) stale(ctx context.Context) (bool, error) {
+	for _, repo := range sd.repos {
+		if changed, err := repo.dEnv.RepoState.ChangedOnDisk(); err != nil || changed {
+			return changed, err
+		}
+
+		if err := repo.dEnv.DoltDB.Refresh(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	for _, rev := range sd.branchRevisions() {
+		cm, err := resolveBranch(ctx, rev.repo.dEnv.DoltDB, rev.branch)
+
+		if err != nil {
+			// as in sync, a deleted branch leaves the database serving the last commit it saw
+			continue
+		}
+
+		h, err := cm.HashOf()
+
+		if err != nil {
+			return false, err
+		} else if h != rev.commit {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// branchRevisions returns the databases serving branches.
+func (sd *serverDatabases) branchRevisions() []*revisionDatabase {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	var revs []*revisionDatabase
+	for _, rev := range sd.revisions {
+		if rev.branch != nil {
+			revs = append(revs, rev)
+		}
+	}
+
+	return revs
+}
+
+func (repo *repoDatabase) sync(ctx context.Context) error {
+	if err := repo.dEnv.RepoState.Reload(); err != nil {
+		return err
+	}
+
+	if err := repo.dEnv.DoltDB.Refresh(ctx); err != nil {
+		return err
+	}
+
+	if repo.dEnv.RepoState.Working == repo.working {
+		return nil
+	}
+
+	root, err := repo.dEnv.WorkingRoot(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	repo.db.SetRoot(root)
+	repo.working = repo.dEnv.RepoState.Working
+	return nil
+}
+
+func resolveBranch(ctx context.Context, ddb *doltdb.DoltDB, branch ref.DoltRef) (*doltdb.Commit, error) {
+	cs, err := doltdb.NewCommitSpec("HEAD", branch.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ddb.Resolve(ctx, cs)
+}
+
+func (rev *revisionDatabase) setCommit(cm *doltdb.Commit) error {
+	h, err := cm.HashOf()
+
+	if err != nil {
+		return err
+	} else if h == rev.commit {
+		return nil
+	}
+
+	root, err := cm.GetRootValue()
+
+	if err != nil {
+		return err
+	}
+
+	rev.db.SetRoot(root)
+	rev.commit = h
+	return nil
+}
+
+// roots returns the current root of each repository's working database.
+func (sd *serverDatabases) roots() []*doltdb.RootValue {
+	roots := make([]*doltdb.RootValue, len(sd.repos))
+	for i, repo := range sd.repos {
+		roots[i] = repo.db.Root()
+	}
+
+	return roots
+}
+
+// workingDBs returns the working database of each repository.
+func (sd *serverDatabases) workingDBs() []*dsqle.Database {
+	dbs := make([]*dsqle.Database, len(sd.repos))
+	for i, repo := range sd.repos {
+		dbs[i] = repo.db
+	}
+
+	return dbs
+}
+
+// reset discards any changes made to the working databases since roots was called.
+func (sd *serverDatabases) reset(roots []*doltdb.RootValue) {
+	for i, repo := range sd.repos {
+		repo.db.SetRoot(roots[i])
+	}
+}
+
+// persist writes the root of every working database which changed since roots was called back to the working root of
+// its repository.  The repo state lock of a repository is only held while its working root is saved, and
+// env.ErrWorkingSetChanged is returned if another process changed the working root since the databases were synced.
+func (sd *serverDatabases) persist(ctx context.Context, roots []*doltdb.RootValue) error {
+	for i, repo := range sd.repos {
+		if repo.db.Root() == roots[i] {
+			continue
+		}
+
+		if err := repo.dEnv.UpdateWorkingRoot(ctx, repo.db.Root()); err != nil {
+			return err
+		}
+
+		repo.working = repo.dEnv.RepoState.Working
+	}
+
+	return nil
+}
+
+// resolve makes sure that the database with the given name is in the catalog, adding a database serving a branch or
+// commit if the name refers to one.  Returns false if no database with the name exists.
+func (sd *serverDatabases) resolve(ctx context.Context, name string) (bool, error) {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	if _, err := sd.catalog.Database(name); err == nil {
+		return true, nil
+	}
+
+	sepIdx := strings.IndexAny(name, branchDBSeparator+commitDBSeparator)
+
+	if sepIdx == -1 {
+		return false, nil
+	}
+
+	var repo *repoDatabase
+	for _, curr := range sd.repos {
+		if strings.EqualFold(curr.name, name[:sepIdx]) {
+			repo = curr
+		}
+	}
+
+	revStr := name[sepIdx+1:]
+	if repo == nil || revStr == "" {
+		return false, nil
+	}
+
+	rev := &revisionDatabase{repo: repo}
+	var cm *doltdb.Commit
+	if name[sepIdx:sepIdx+1] == branchDBSeparator {
+		if !ref.IsValidBranchName(revStr) {
+			return false, nil
+		}
+
+		rev.branch = ref.NewBranchRef(revStr)
+		if ok, err := repo.dEnv.DoltDB.HasRef(ctx, rev.branch); err != nil {
+			return false, err
+		} else if !ok {
+			return false, nil
+		}
+
+		var err error
+		cm, err = resolveBranch(ctx, repo.dEnv.DoltDB, rev.branch)
+
+		if err != nil {
+			return false, err
+		}
+	} else {
+		cs, err := doltdb.NewCommitSpec(revStr, repo.dEnv.RepoState.Head.Ref.String())
+
+		if err != nil {
+			return false, nil
+		}
+
+		cm, err = repo.dEnv.DoltDB.Resolve(ctx, cs)
+
+		if err != nil {
+			return false, nil
+		}
+	}
+
+	root, err := cm.GetRootValue()
+
+	if err != nil {
+		return false, err
+	}
+
+	rev.commit, err = cm.HashOf()
+
+	if err != nil {
+		return false, err
+	}
+
+	rev.db = dsqle.NewReadOnlyDatabase(name, root, repo.dEnv)
+	sd.catalog.AddDatabase(rev.db)
+	sd.revisions[strings.ToLower(name)] = rev
+
+	return true, nil
+}
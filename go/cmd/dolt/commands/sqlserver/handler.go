@@ -0,0 +1,328 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlserver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/src-d/go-mysql-server/server"
+	"github.com/src-d/go-mysql-server/sql"
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	dsqle "github.com/liquidata-inc/dolt/go/libraries/doltcore/sqle"
+)
+
+// workingSetHandler wraps the go-mysql-server handler so that every statement is run against the current working roots
+// of the repositories being served, and any changes made by a statement are written back to the working roots once it
+// completes.  Read-only statements run at the same time as each other, as long as they use the same current database
+// and the repositories haven't been changed by another process since they were last synced.  Any other statement runs
+// on its own.  The repo state lock of a repository is only held while a statement's changes to its working root are
+// saved, so dolt commands run from the command line aren't held up by running statements.
+//
+// The handler also tracks the current database of each connection.  The catalog only has a single current database, so
+// it's set from the connection before each statement, and the connection is updated if the statement changed it.
+//
+// Each connection has its own session, which tracks its transaction.  The changes made by a transaction are held by
+// the session until they're committed, when they're merged into the working roots.
+type workingSetHandler struct {
+	*server.Handler
+	dbs  *serverDatabases
+	gate *statementGate
+
+	mu       *sync.Mutex
+	sessions map[uint32]*dsqle.Session
+}
+
+var _ mysql.Handler = (*workingSetHandler)(nil)
+
+func newWorkingSetHandler(h *server.Handler, dbs *serverDatabases) *workingSetHandler {
+	return &workingSetHandler{h, dbs, &statementGate{}, &sync.Mutex{}, make(map[uint32]*dsqle.Session)}
+}
+
+// statementGate lets any number of read-only statements run at once, or a single statement of any other kind.  The
+// catalog only has a single current database, so read-only statements only run together if they use the same one.
+type statementGate struct {
+	rw sync.RWMutex
+
+	// mu guards readers, the number of read-only statements running, and readDB, the database they use
+	mu      sync.Mutex
+	readers int
+	readDB  string
+}
+
+// tryEnterRead waits for any statement which isn't read-only to finish, and enters the gate to run a read-only
+// statement using the database given.  Returns false without entering if read-only statements using another database
+// are running.
+func (g *statementGate) tryEnterRead(dbName string) bool {
+	g.rw.RLock()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.readers > 0 && g.readDB != dbName {
+		g.rw.RUnlock()
+		return false
+	}
+
+	g.readers++
+	g.readDB = dbName
+	return true
+}
+
+func (g *statementGate) exitRead() {
+	g.mu.Lock()
+	g.readers--
+	g.mu.Unlock()
+
+	g.rw.RUnlock()
+}
+
+// enterWrite waits for every running statement to finish, and enters the gate to run a statement on its own.
+func (g *statementGate) enterWrite() {
+	g.rw.Lock()
+}
+
+func (g *statementGate) exitWrite() {
+	g.rw.Unlock()
+}
+
+// ConnectionClosed discards the session of the connection, rolling back its transaction if it has one.
+func (h *workingSetHandler) ConnectionClosed(c *mysql.Conn) {
+	h.mu.Lock()
+	delete(h.sessions, c.ConnectionID)
+	h.mu.Unlock()
+
+	h.Handler.ConnectionClosed(c)
+}
+
+// session returns the session of the connection, creating it on the connection's first statement.
+func (h *workingSetHandler) session(c *mysql.Conn) *dsqle.Session {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sess, ok := h.sessions[c.ConnectionID]
+
+	if !ok {
+		sess = dsqle.NewSession(true)
+		h.sessions[c.ConnectionID] = sess
+	}
+
+	return sess
+}
+
+// ComQuery executes a statement in the connection's session, loading any changes made to the working roots outside of
+// the server before it runs, and saving the working roots afterwards if the statement or a committed transaction
+// changed them.  If the statement fails, any changes it made are discarded.
+func (h *workingSetHandler) ComQuery(c *mysql.Conn, query string, callback func(*sqltypes.Result) error) error {
+	ctx := context.Background()
+	query = dsqle.RewriteAsOf(query)
+
+	if c.SchemaName == "" {
+		c.SchemaName = h.dbs.defaultDBName()
+	}
+
+	if h.session(c).UsesSharedRoots() && dsqle.IsReadOnlyQuery(query) {
+		if ran, err := h.comQueryRead(ctx, c, query, callback); ran {
+			return err
+		}
+	}
+
+	h.gate.enterWrite()
+	defer h.gate.exitWrite()
+
+	if err := h.dbs.sync(ctx); err != nil {
+		return err
+	}
+
+	if err := h.selectDatabase(ctx, c, query); err != nil {
+		return err
+	}
+
+	startRoots := h.dbs.roots()
+	executed := false
+	err := h.session(c).Exec(ctx, h.dbs.workingDBs(), query, func() error {
+		executed = true
+		return h.comQuery(ctx, c, query, callback)
+	})
+	c.SchemaName = h.dbs.catalog.CurrentDatabase()
+
+	if err != nil {
+		h.dbs.reset(startRoots)
+		return err
+	}
+
+	if err := h.dbs.persist(ctx, startRoots); err != nil {
+		h.dbs.reset(startRoots)
+		return err
+	}
+
+	// transaction statements are run by the session, and have no results
+	if !executed {
+		return callback(&sqltypes.Result{})
+	}
+
+	return nil
+}
+
+// comQueryRead runs a read-only statement at the same time as any other read-only statements.  ran is false if the
+// statement wasn't run because read-only statements using another database are running, or the repositories were
+// changed by another process since they were last synced, in which case the statement must be run on its own.
+func (h *workingSetHandler) comQueryRead(ctx context.Context, c *mysql.Conn, query string, callback func(*sqltypes.Result) error) (ran bool, err error) {
+	if !h.gate.tryEnterRead(c.SchemaName) {
+		return false, nil
+	}
+
+	defer h.gate.exitRead()
+
+	if stale, err := h.dbs.stale(ctx); err != nil {
+		return true, err
+	} else if stale {
+		return false, nil
+	}
+
+	if err := h.selectDatabase(ctx, c, query); err != nil {
+		return true, err
+	}
+
+	return true, h.comQuery(ctx, c, query, callback)
+}
+
+// comQuery runs a statement with the engine.  Index and foreign key statements, which the engine can't parse, are run
+// against the connection's current database directly, and the foreign keys of a created table are added to it once the
+// engine has created it.
+func (h *workingSetHandler) comQuery(ctx context.Context, c *mysql.Conn, query string, callback func(*sqltypes.Result) error) error {
+	indexDDL, isIndexDDL, err := dsqle.ParseIndexDDL(query)
+
+	if isIndexDDL && err != nil {
+		return err
+	}
+
+	fkDDL, isFKDDL, err := dsqle.ParseForeignKeyDDL(query)
+
+	if isFKDDL && err != nil {
+		return err
+	}
+
+	createFKs := dsqle.ParseCreateTableForeignKeys(query)
+	db, err := h.dbs.catalog.Database(c.SchemaName)
+
+	if err != nil {
+		return err
+	}
+
+	doltDB, isDoltDB := db.(*dsqle.Database)
+
+	if !isIndexDDL && !isFKDDL && len(createFKs) == 0 {
+		if isDoltDB {
+			if err := dsqle.CheckDropTables(ctx, doltDB, query); err != nil {
+				return err
+			}
+		}
+
+		return h.Handler.ComQuery(c, query, callback)
+	}
+
+	if !isDoltDB {
+		return fmt.Errorf("database '%s' does not support indexes or foreign keys", c.SchemaName)
+	}
+
+	switch {
+	case isIndexDDL:
+		err = dsqle.ExecuteIndexDDL(ctx, doltDB, indexDDL)
+	case isFKDDL:
+		err = dsqle.ExecuteForeignKeyDDL(ctx, doltDB, fkDDL)
+	default:
+		// results are held back until the foreign keys have been added, so a failure is reported as the only result
+		var results []*sqltypes.Result
+		err = h.Handler.ComQuery(c, query, func(res *sqltypes.Result) error {
+			results = append(results, res)
+			return nil
+		})
+
+		for _, fk := range createFKs {
+			if err != nil {
+				break
+			}
+
+			err = dsqle.ExecuteForeignKeyDDL(ctx, doltDB, fk)
+		}
+
+		for _, res := range results {
+			if err != nil {
+				break
+			}
+
+			err = callback(res)
+		}
+
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return callback(&sqltypes.Result{})
+}
+
+// selectDatabase makes the connection's database the current database of the catalog, and adds any databases serving
+// branches or commits referenced by the query to the catalog.
+func (h *workingSetHandler) selectDatabase(ctx context.Context, c *mysql.Conn, query string) error {
+	if ok, err := h.dbs.resolve(ctx, c.SchemaName); err != nil {
+		return err
+	} else if !ok {
+		return sql.ErrDatabaseNotFound.New(c.SchemaName)
+	}
+
+	h.dbs.catalog.SetCurrentDatabase(c.SchemaName)
+
+	// databases that don't exist are left for the engine to report
+	for _, name := range referencedDatabases(query) {
+		if _, err := h.dbs.resolve(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// referencedDatabases returns the names of the databases named in the query, either by a USE statement or as the
+// qualifier of a table.  Queries which can't be parsed return no names.
+func referencedDatabases(query string) []string {
+	stmt, err := sqlparser.Parse(query)
+
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Use:
+			names = append(names, node.DBName.String())
+		case sqlparser.TableName:
+			if !node.Qualifier.IsEmpty() {
+				names = append(names, node.Qualifier.String())
+			}
+		}
+
+		return true, nil
+	}, stmt)
+
+	return names
+}
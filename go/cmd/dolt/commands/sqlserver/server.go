@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/opentracing/opentracing-go"
 	"github.com/sirupsen/logrus"
 	sqle "github.com/src-d/go-mysql-server"
 	"github.com/src-d/go-mysql-server/auth"
@@ -28,12 +29,15 @@ import (
 	"vitess.io/vitess/go/mysql"
 
 	"github.com/liquidata-inc/dolt/go/cmd/dolt/cli"
-	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
 	dsqle "github.com/liquidata-inc/dolt/go/libraries/doltcore/sqle"
 )
 
-// Serve starts a MySQL-compatible server. Returns any errors that were encountered.
-func Serve(ctx context.Context, serverConfig *ServerConfig, rootValue *doltdb.RootValue, serverController *ServerController) (startError error, closeError error) {
+// Serve starts a MySQL-compatible server serving the working root of the repository in dEnv as the database `dolt`, or
+// if the config has a MultiDBDir, the working root of every repository in that directory as a database named after the
+// repository. Changes made by statements are written back to the working roots as each statement completes. Returns any
+// errors that were encountered.
+func Serve(ctx context.Context, serverConfig *ServerConfig, dEnv *env.DoltEnv, serverController *ServerController) (startError error, closeError error) {
 	if serverConfig == nil {
 		cli.Println("No configuration given, using defaults")
 		serverConfig = DefaultServerConfig()
@@ -74,13 +78,29 @@ func Serve(ctx context.Context, serverConfig *ServerConfig, rootValue *doltdb.Ro
 		permissions = auth.ReadPerm
 	}
 
+	dEnvs := map[string]*env.DoltEnv{singleRepoDBName: dEnv}
+	if serverConfig.MultiDBDir != "" {
+		dEnvs, startError = loadMultiRepoEnvs(ctx, serverConfig.MultiDBDir)
+		if startError != nil {
+			cli.PrintErr(startError)
+			return
+		}
+	}
+
 	userAuth := auth.NewAudit(auth.NewNativeSingle(serverConfig.User, serverConfig.Password, permissions), auth.NewAuditLog(logrus.StandardLogger()))
 	sqlEngine := sqle.NewDefault()
-	sqlEngine.AddDatabase(dsqle.NewDatabase("dolt", rootValue, nil))
+	sqlEngine.Catalog.MustRegister(dsqle.VersionControlFunctions(sqlEngine.Catalog)...)
+
+	var dbs *serverDatabases
+	dbs, startError = newServerDatabases(ctx, sqlEngine.Catalog, dEnvs)
+	if startError != nil {
+		cli.PrintErr(startError)
+		return
+	}
 
 	hostPort := net.JoinHostPort(serverConfig.Host, strconv.Itoa(serverConfig.Port))
 	timeout := time.Second * time.Duration(serverConfig.Timeout)
-	mySQLServer, startError = server.NewServer(
+	mySQLServer, startError = newServer(
 		server.Config{
 			Protocol:         "tcp",
 			Address:          hostPort,
@@ -92,6 +112,7 @@ func Serve(ctx context.Context, serverConfig *ServerConfig, rootValue *doltdb.Ro
 		func(conn *mysql.Conn, host string) sql.Session {
 			return sql.NewSession(host, conn.RemoteAddr().String(), conn.User, conn.ConnectionID)
 		},
+		dbs,
 	)
 	if startError != nil {
 		cli.PrintErr(startError)
@@ -105,3 +126,24 @@ func Serve(ctx context.Context, serverConfig *ServerConfig, rootValue *doltdb.Ro
 	}
 	return
 }
+
+// newServer creates a server in the same way as server.NewServer, but with its handler wrapped so that the working roots
+// of the repositories are kept in sync with the databases as statements are run.
+func newServer(cfg server.Config, e *sqle.Engine, sb server.SessionBuilder, dbs *serverDatabases) (*server.Server, error) {
+	handler := server.NewHandler(e,
+		server.NewSessionManager(
+			sb, opentracing.NoopTracer{},
+			e.Catalog.MemoryManager,
+			cfg.Address),
+		cfg.ConnReadTimeout)
+	l, err := server.NewListener(cfg.Protocol, cfg.Address, handler)
+	if err != nil {
+		return nil, err
+	}
+	vtListnr, err := mysql.NewFromListener(l, cfg.Auth.Mysql(), newWorkingSetHandler(handler, dbs), cfg.ConnReadTimeout, cfg.ConnWriteTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server.Server{Listener: vtListnr}, nil
+}
@@ -15,8 +15,12 @@
 package sqlserver
 
 import (
+	"database/sql"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gocraft/dbr"
@@ -24,11 +28,14 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/context"
 
-	"github.com/liquidata-inc/dolt/go/cmd/dolt/commands"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/table"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/table/typed/noms"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/test"
+	"github.com/liquidata-inc/dolt/go/store/types"
 )
 
 type testPerson struct {
@@ -99,8 +106,6 @@ func TestServerBadArgs(t *testing.T) {
 
 func TestServerGoodParams(t *testing.T) {
 	env := createEnvWithSeedData(t)
-	root, verr := commands.GetWorkingWithVErr(env)
-	require.NoError(t, verr)
 
 	tests := []*ServerConfig{
 		DefaultServerConfig(),
@@ -121,7 +126,7 @@ func TestServerGoodParams(t *testing.T) {
 		t.Run(test.String(), func(t *testing.T) {
 			sc := CreateServerController()
 			go func(config *ServerConfig, sc *ServerController) {
-				_, _ = Serve(context.Background(), config, root, sc)
+				_, _ = Serve(context.Background(), config, env, sc)
 			}(test, sc)
 			err := sc.WaitForStart()
 			require.NoError(t, err)
@@ -138,14 +143,12 @@ func TestServerGoodParams(t *testing.T) {
 
 func TestServerSelect(t *testing.T) {
 	env := createEnvWithSeedData(t)
-	root, verr := commands.GetWorkingWithVErr(env)
-	require.NoError(t, verr)
 	serverConfig := DefaultServerConfig().WithLogLevel(LogLevel_Fatal).WithPort(15300)
 
 	sc := CreateServerController()
 	defer sc.StopServer()
 	go func() {
-		_, _ = Serve(context.Background(), serverConfig, root, sc)
+		_, _ = Serve(context.Background(), serverConfig, env, sc)
 	}()
 	err := sc.WaitForStart()
 	require.NoError(t, err)
@@ -188,8 +191,309 @@ func TestServerSelect(t *testing.T) {
 	}
 }
 
+func TestServerWorkingSet(t *testing.T) {
+	dEnv := createEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().WithLogLevel(LogLevel_Fatal).WithPort(15301)
+	origWorking := dEnv.RepoState.Working
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), serverConfig, dEnv, sc)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	conn, err := dbr.Open("mysql", serverConfig.ConnectionString(), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	sess := conn.NewSession(nil)
+
+	loadBill := func() []testPerson {
+		var peoples []testPerson
+		_, err := sess.Select("*").From("people").Where("name = 'Bill Billerson'").LoadContext(context.Background(), &peoples)
+		require.NoError(t, err)
+		return peoples
+	}
+
+	// writes made through the server are saved to the working set on disk
+	_, err = sess.Update("people").Set("age", 33).Where("name = 'Bill Billerson'").ExecContext(context.Background())
+	require.NoError(t, err)
+
+	rs, err := env.LoadRepoState(dEnv.FS)
+	require.NoError(t, err)
+	assert.NotEqual(t, origWorking, rs.Working)
+	assert.Equal(t, []testPerson{{bill.Name, 33, bill.Is_married, bill.Title}}, loadBill())
+
+	// a failed statement leaves the working set unchanged
+	working := rs.Working
+	_, err = sess.InsertInto("people").Columns("name").Values("Jim Jimson").ExecContext(context.Background())
+	require.Error(t, err)
+
+	rs, err = env.LoadRepoState(dEnv.FS)
+	require.NoError(t, err)
+	assert.Equal(t, working, rs.Working)
+
+	// changes made to the working set by other processes are picked up by the next statement
+	rs.Working = origWorking
+	err = rs.Save()
+	require.NoError(t, err)
+	assert.Equal(t, []testPerson{bill}, loadBill())
+}
+
+func TestServerTransactions(t *testing.T) {
+	dEnv := createEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().WithLogLevel(LogLevel_Fatal).WithPort(15303)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), serverConfig, dEnv, sc)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	conn, err := dbr.Open("mysql", serverConfig.ConnectionString(), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+	c1, err := conn.Conn(ctx)
+	require.NoError(t, err)
+	defer c1.Close()
+	c2, err := conn.Conn(ctx)
+	require.NoError(t, err)
+	defer c2.Close()
+
+	exec := func(c *sql.Conn, query string) {
+		_, err := c.ExecContext(ctx, query)
+		require.NoError(t, err, query)
+	}
+
+	age := func(c *sql.Conn, name string) int {
+		var age int
+		err := c.QueryRowContext(ctx, "select age from people where name = '"+name+"'").Scan(&age)
+		require.NoError(t, err)
+		return age
+	}
+
+	// changes made by a transaction are only seen by other sessions once it's committed
+	exec(c1, "begin")
+	exec(c1, "update people set age = 40 where name = 'Bill Billerson'")
+	assert.Equal(t, 40, age(c1, bill.Name))
+	assert.Equal(t, 32, age(c2, bill.Name))
+	exec(c1, "commit")
+	assert.Equal(t, 40, age(c2, bill.Name))
+
+	// changes committed by other sessions are merged with the changes made by the transaction
+	exec(c1, "start transaction")
+	exec(c1, "update people set age = 41 where name = 'Bill Billerson'")
+	exec(c2, "update people set age = 26 where name = 'John Johnson'")
+	assert.Equal(t, 25, age(c1, john.Name))
+	exec(c1, "commit")
+	assert.Equal(t, 41, age(c2, bill.Name))
+	assert.Equal(t, 26, age(c1, john.Name))
+
+	// a transaction which conflicts with changes committed by another session is rolled back
+	exec(c1, "begin")
+	exec(c1, "update people set age = 42 where name = 'Bill Billerson'")
+	exec(c2, "update people set age = 43 where name = 'Bill Billerson'")
+	_, err = c1.ExecContext(ctx, "commit")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "transaction conflicts")
+	assert.Equal(t, 43, age(c1, bill.Name))
+
+	// rolling back discards the changes made by the transaction
+	exec(c1, "begin")
+	exec(c1, "update people set age = 22 where name = 'Rob Robertson'")
+	exec(c1, "rollback")
+	assert.Equal(t, 21, age(c1, rob.Name))
+	assert.Equal(t, 21, age(c2, rob.Name))
+
+	// with autocommit off, statements are run in a transaction which lasts until it's committed
+	exec(c1, "set autocommit = 0")
+	exec(c1, "update people set age = 23 where name = 'Rob Robertson'")
+	assert.Equal(t, 21, age(c2, rob.Name))
+	exec(c1, "commit")
+	assert.Equal(t, 23, age(c2, rob.Name))
+	exec(c1, "update people set age = 24 where name = 'Rob Robertson'")
+	exec(c1, "set autocommit = 1")
+	assert.Equal(t, 24, age(c2, rob.Name))
+}
+
+func TestServerSessionCheckout(t *testing.T) {
+	dEnv := createEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().WithLogLevel(LogLevel_Fatal).WithPort(15304)
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), serverConfig, dEnv, sc)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	conn, err := dbr.Open("mysql", serverConfig.ConnectionString(), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+	c1, err := conn.Conn(ctx)
+	require.NoError(t, err)
+	defer c1.Close()
+	c2, err := conn.Conn(ctx)
+	require.NoError(t, err)
+	defer c2.Close()
+
+	exec := func(c *sql.Conn, query string) {
+		_, err := c.ExecContext(ctx, query)
+		require.NoError(t, err, query)
+	}
+
+	age := func(c *sql.Conn, name string) int {
+		var age int
+		err := c.QueryRowContext(ctx, "select age from people where name = '"+name+"'").Scan(&age)
+		require.NoError(t, err)
+		return age
+	}
+
+	exec(c1, "select dolt_add('.')")
+	exec(c1, "select dolt_commit('-m', 'added people')")
+
+	// checking out a branch only changes the head and working set of the session which checked it out
+	exec(c1, "select dolt_checkout('-b', 'feature')")
+	exec(c1, "update people set age = 40 where name = 'Bill Billerson'")
+	assert.Equal(t, 40, age(c1, bill.Name))
+	assert.Equal(t, 32, age(c2, bill.Name))
+
+	rs, err := env.LoadRepoState(dEnv.FS)
+	require.NoError(t, err)
+	assert.Equal(t, "refs/heads/master", rs.Head.Ref.String())
+
+	// commits are made to the session's branch, and reset and merge operate on the session's working set
+	exec(c1, "select dolt_add('people')")
+	exec(c1, "select dolt_commit('-m', 'changed bill')")
+	exec(c2, "update people set age = 26 where name = 'John Johnson'")
+	exec(c1, "update people set age = 22 where name = 'Rob Robertson'")
+	exec(c1, "select dolt_reset('--hard')")
+	assert.Equal(t, 21, age(c1, rob.Name))
+	assert.Equal(t, 25, age(c1, john.Name))
+	assert.Equal(t, 26, age(c2, john.Name))
+	assert.Equal(t, 32, age(c2, bill.Name))
+
+	exec(c2, "select dolt_reset('--hard')")
+	exec(c2, "select dolt_merge('feature')")
+	assert.Equal(t, 40, age(c2, bill.Name))
+
+	rs, err = env.LoadRepoState(dEnv.FS)
+	require.NoError(t, err)
+	assert.Equal(t, "refs/heads/master", rs.Head.Ref.String())
+
+	// checking out the repository's branch again goes back to the repository's working set
+	exec(c1, "select dolt_checkout('master')")
+	exec(c2, "update people set age = 27 where name = 'John Johnson'")
+	assert.Equal(t, 27, age(c1, john.Name))
+	assert.Equal(t, 40, age(c1, bill.Name))
+}
+
+func TestServerRepoStateLock(t *testing.T) {
+	dEnv := createEnvWithSeedData(t)
+	serverConfig := DefaultServerConfig().WithLogLevel(LogLevel_Fatal).WithPort(15305)
+	origWorking := dEnv.RepoState.Working
+
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(context.Background(), serverConfig, dEnv, sc)
+	}()
+	err := sc.WaitForStart()
+	require.NoError(t, err)
+
+	conn, err := dbr.Open("mysql", serverConfig.ConnectionString(), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// the repo state lock is held as it would be by a command saving the repo state
+	ctx := context.Background()
+	_, err = dEnv.RepoState.Lock(ctx)
+	require.NoError(t, err)
+
+	// statements which only read don't wait for it
+	var count int
+	err = conn.QueryRowContext(ctx, "select count(*) from people").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, len(dtestutils.TypedRows), count)
+
+	// statements which write only wait for it to save their changes
+	updated := make(chan error)
+	go func() {
+		_, err := conn.ExecContext(ctx, "update people set age = 33 where name = 'Bill Billerson'")
+		updated <- err
+	}()
+
+	select {
+	case err := <-updated:
+		t.Fatalf("saved the working set while the repo state was locked: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, dEnv.RepoState.Unlock())
+	require.NoError(t, <-updated)
+
+	rs, err := env.LoadRepoState(dEnv.FS)
+	require.NoError(t, err)
+	assert.NotEqual(t, origWorking, rs.Working)
+}
+
+func TestStatementGate(t *testing.T) {
+	g := &statementGate{}
+
+	// read-only statements run together if they use the same database
+	require.True(t, g.tryEnterRead("db1"))
+	require.True(t, g.tryEnterRead("db1"))
+	assert.False(t, g.tryEnterRead("db2"))
+
+	// any other statement waits for them to finish
+	entered := make(chan struct{})
+	go func() {
+		g.enterWrite()
+		close(entered)
+	}()
+
+	select {
+	case <-entered:
+		t.Fatal("entered to write while read-only statements were running")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	g.exitRead()
+	g.exitRead()
+	<-entered
+
+	// and read-only statements wait for it to finish
+	read := make(chan bool)
+	go func() {
+		read <- g.tryEnterRead("db2")
+	}()
+
+	select {
+	case <-read:
+		t.Fatal("entered to read while a statement was writing")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	g.exitWrite()
+	assert.True(t, <-read)
+	g.exitRead()
+}
+
 func createEnvWithSeedData(t *testing.T) *env.DoltEnv {
 	dEnv := dtestutils.CreateTestEnv()
+	seedPeople(t, dEnv)
+	return dEnv
+}
+
+func seedPeople(t *testing.T, dEnv *env.DoltEnv) {
 	imt, sch := dtestutils.CreateTestDataTable(true)
 
 	rd := table.NewInMemTableReader(imt)
@@ -208,6 +512,80 @@ func createEnvWithSeedData(t *testing.T) *env.DoltEnv {
 	if err != nil {
 		t.Error("Unable to put initial value of table in in mem noms db", err)
 	}
+}
 
-	return dEnv
+func TestServerMultiDB(t *testing.T) {
+	ctx := context.Background()
+	dir := test.TestDir("TestServerMultiDB")
+	for _, name := range []string{"db1", "db2", "notarepo"} {
+		err := os.MkdirAll(filepath.Join(dir, name), os.ModePerm)
+		require.NoError(t, err)
+	}
+
+	dEnvs := make(map[string]*env.DoltEnv)
+	for _, name := range []string{"db1", "db2"} {
+		dEnv, err := env.LoadFromDir(ctx, env.GetCurrentUserHomeDir, filepath.Join(dir, name))
+		require.NoError(t, err)
+		err = dEnv.InitRepo(ctx, types.Format_7_18, "Bill Billerson", "bigbillieb@fake.horse")
+		require.NoError(t, err)
+		dEnvs[name] = dEnv
+	}
+
+	// db1 has a branch at its initial commit, before the people table is added to its working set
+	db1 := dEnvs["db1"]
+	cs, err := doltdb.NewCommitSpec("HEAD", db1.RepoState.Head.Ref.String())
+	require.NoError(t, err)
+	cm, err := db1.DoltDB.Resolve(ctx, cs)
+	require.NoError(t, err)
+	err = db1.DoltDB.NewBranchAtCommit(ctx, ref.NewBranchRef("other"), cm)
+	require.NoError(t, err)
+	seedPeople(t, db1)
+
+	serverConfig := DefaultServerConfig().WithLogLevel(LogLevel_Fatal).WithPort(15302).WithMultiDBDir(dir)
+	sc := CreateServerController()
+	defer sc.StopServer()
+	go func() {
+		_, _ = Serve(ctx, serverConfig, nil, sc)
+	}()
+	err = sc.WaitForStart()
+	require.NoError(t, err)
+
+	conn, err := dbr.Open("mysql", serverConfig.ConnectionString(), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// the database selected with use is only remembered by the connection it was run on
+	conn.SetMaxOpenConns(1)
+
+	var dbNames []string
+	_, err = conn.NewSession(nil).SelectBySql("show databases").LoadContext(ctx, &dbNames)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db1", "db2"}, dbNames)
+
+	// the first repository is selected when no database is given
+	var count int
+	err = conn.QueryRowContext(ctx, "select count(*) from people").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, len(dtestutils.TypedRows), count)
+
+	// writes to a repository's database are saved to its working set
+	_, err = conn.ExecContext(ctx, "use db2")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "create table t (pk bigint primary key comment 'tag:0')")
+	require.NoError(t, err)
+	rs, err := env.LoadRepoState(dEnvs["db2"].FS)
+	require.NoError(t, err)
+	assert.NotEqual(t, dEnvs["db2"].RepoState.Working, rs.Working)
+
+	// branches are served as read-only databases
+	_, err = conn.ExecContext(ctx, "use `db1/other`")
+	require.NoError(t, err)
+	_, err = conn.ExecContext(ctx, "select * from people")
+	assert.Error(t, err)
+	_, err = conn.ExecContext(ctx, "create table people2 (pk bigint primary key comment 'tag:0')")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "read-only")
+
+	_, err = conn.ExecContext(ctx, "use `notarepo`")
+	assert.Error(t, err)
 }
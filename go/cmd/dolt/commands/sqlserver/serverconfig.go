@@ -39,6 +39,10 @@ type ServerConfig struct {
 	Timeout  int      // The read and write timeouts.
 	ReadOnly bool     // Whether the server will only accept read statements or all statements.
 	LogLevel LogLevel // Specifies the level of logging that the server will use.
+
+	// MultiDBDir is a directory whose subdirectories are dolt repositories that will each be served as a database. If
+	// empty, the repository the server is started in is served as the database `dolt`.
+	MultiDBDir string
 }
 
 // DefaultServerConfig creates a `*ServerConfig` that has all of the options set to their default values.
@@ -119,15 +123,28 @@ func (config *ServerConfig) WithLogLevel(loglevel LogLevel) *ServerConfig {
 	return config
 }
 
-// ConnectionString returns a Data Source Name (DSN) to be used by go clients for connecting to a running server.
+// WithMultiDBDir updates the multi-database directory and returns the called `*ServerConfig`, which is useful for
+// chaining calls.
+func (config *ServerConfig) WithMultiDBDir(dir string) *ServerConfig {
+	config.MultiDBDir = dir
+	return config
+}
+
+// ConnectionString returns a Data Source Name (DSN) to be used by go clients for connecting to a running server.  When
+// serving multiple repositories no database is given, and the server selects the first one.
 func (config *ServerConfig) ConnectionString() string {
-	return fmt.Sprintf("%v:%v@tcp(%v:%v)/dolt", config.User, config.Password, config.Host, config.Port)
+	dbName := singleRepoDBName
+	if config.MultiDBDir != "" {
+		dbName = ""
+	}
+
+	return fmt.Sprintf("%v:%v@tcp(%v:%v)/%v", config.User, config.Password, config.Host, config.Port, dbName)
 }
 
 // String implements `fmt.Stringer`.
 func (config *ServerConfig) String() string {
-	return fmt.Sprintf(`HP="%v:%v"|U="%v"|P="%v"|T="%v"|R="%v"|L="%v"`, config.Host, config.Port, config.User,
-		config.Password, config.Timeout, config.ReadOnly, config.LogLevel)
+	return fmt.Sprintf(`HP="%v:%v"|U="%v"|P="%v"|T="%v"|R="%v"|L="%v"|M="%v"`, config.Host, config.Port, config.User,
+		config.Password, config.Timeout, config.ReadOnly, config.LogLevel, config.MultiDBDir)
 }
 
 // String returns the string representation of the log level.
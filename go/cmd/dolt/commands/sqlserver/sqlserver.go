@@ -25,23 +25,35 @@ import (
 )
 
 const (
-	hostFlag     = "host"
-	portFlag     = "port"
-	userFlag     = "user"
-	passwordFlag = "password"
-	timeoutFlag  = "timeout"
-	readonlyFlag = "readonly"
-	logLevelFlag = "loglevel"
+	hostFlag       = "host"
+	portFlag       = "port"
+	userFlag       = "user"
+	passwordFlag   = "password"
+	timeoutFlag    = "timeout"
+	readonlyFlag   = "readonly"
+	logLevelFlag   = "loglevel"
+	multiDBDirFlag = "multi-db-dir"
 )
 
 var sqlServerShortDesc = "Start a MySQL-compatible server."
 var sqlServerLongDesc = `Start a MySQL-compatible server which can be connected to by MySQL clients.
 
-Currently, only SELECT statements are operational, as support for other statements is
-still being developed.
+Changes made by statements run against the server are written to the working set of the
+repository as each statement completes, and changes made to the working set by other dolt
+commands while the server is running are visible to subsequent statements.
+
+By default the repository in the current directory is served as the database 'dolt'. If
+--multi-db-dir is given, every dolt repository in a subdirectory of that directory is
+served instead, each as a database named after its subdirectory.
+
+Branches and commits of a served repository can be queried as read-only databases named
+'<database>/<branch>' and '<database>@<commit>', for example:
+
+    USE ` + "`mydb/feature-x`" + `;
+    SELECT * FROM ` + "`mydb@head~1`" + `.mytable;
 `
 var sqlServerSynopsis = []string{
-	"[-H <host>] [-P <port>] [-u <user>] [-p <password>] [-t <timeout>] [-l <loglevel>] [-r]",
+	"[-H <host>] [-P <port>] [-u <user>] [-p <password>] [-t <timeout>] [-l <loglevel>] [-r] [--multi-db-dir <directory>]",
 }
 
 func SqlServer(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
@@ -59,13 +71,17 @@ func SqlServerImpl(ctx context.Context, commandStr string, args []string, dEnv *
 	ap.SupportsInt(timeoutFlag, "t", "Connection timeout", fmt.Sprintf("Defines the timeout, in seconds, used for connections\nA value of `0` represents an infinite timeout (default `%v`)", serverConfig.Timeout))
 	ap.SupportsFlag(readonlyFlag, "r", "Disables modification of the database")
 	ap.SupportsString(logLevelFlag, "l", "Log level", fmt.Sprintf("Defines the level of logging provided\nOptions are: `debug`, `info`, `warning`, `error`, `fatal` (default `%v`)", serverConfig.LogLevel))
+	ap.SupportsString(multiDBDirFlag, "", "directory", "Defines a directory whose subdirectories are dolt repositories which should each be served as a database. The current directory doesn't need to be a repository when this is given.")
 	help, usage := cli.HelpAndUsagePrinters(commandStr, sqlServerShortDesc, sqlServerLongDesc, sqlServerSynopsis, ap)
 
 	apr := cli.ParseArgs(ap, args, help)
 	args = apr.Args()
 
-	root, verr := commands.GetWorkingWithVErr(dEnv)
-	if verr != nil {
+	if multiDBDir, ok := apr.GetValue(multiDBDirFlag); ok {
+		serverConfig.MultiDBDir = multiDBDir
+	} else if !cli.CheckEnvIsValid(dEnv) {
+		return 2
+	} else if _, verr := commands.GetWorkingWithVErr(dEnv); verr != nil {
 		return commands.HandleVErrAndExitCode(verr, usage)
 	}
 
@@ -90,7 +106,7 @@ func SqlServerImpl(ctx context.Context, commandStr string, args []string, dEnv *
 	if logLevel, ok := apr.GetValue(logLevelFlag); ok {
 		serverConfig.LogLevel = LogLevel(logLevel)
 	}
-	if startError, closeError := Serve(ctx, serverConfig, root, serverController); startError != nil || closeError != nil {
+	if startError, closeError := Serve(ctx, serverConfig, dEnv, serverController); startError != nil || closeError != nil {
 		if startError != nil {
 			cli.PrintErrln(startError)
 		}
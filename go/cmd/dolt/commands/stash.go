@@ -0,0 +1,247 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/cli"
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/errhand"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
+)
+
+var stashShortDesc = "Stash the changes in a dirty working set away"
+var stashLongDesc = "Use dolt stash when you want to record the current state of the working set and the staged tables, " +
+	"but want to go back to a clean working set.  The command saves your local modifications away and reverts the " +
+	"working set to match the HEAD commit.  New tables which have not been committed are stashed as well.\n" +
+	"\n" +
+	"The modifications stashed away by this command can be listed with <b>dolt stash list</b>, and restored " +
+	"(potentially on top of a different commit) with <b>dolt stash apply</b>.  Calling dolt stash without any " +
+	"arguments is equivalent to <b>dolt stash push</b>.  The latest stash you created is stored as stash@{0}, the one " +
+	"before it is stash@{1}, and so on.\n" +
+	"\n" +
+	"<b>push</b>\n" +
+	"Save your local modifications to a new stash entry and reset the working and staged tables to HEAD.  If " +
+	"<b>-m</b> is given the message is used to describe the stash.\n" +
+	"\n" +
+	"<b>list</b>\n" +
+	"List the stash entries that you currently have.\n" +
+	"\n" +
+	"<b>apply</b>\n" +
+	"Restore the working and staged tables saved in a stash.  If HEAD has moved since the stash was created the " +
+	"stashed changes are merged into the working set using the commit the stash was created on as the merge base, " +
+	"and any conflicts are left in the working set.  If <stash> is not given, stash@{0} is applied.\n" +
+	"\n" +
+	"<b>pop</b>\n" +
+	"Apply a stash, and then remove it from the stash list.  If applying the stash results in conflicts the stash is " +
+	"not removed.\n" +
+	"\n" +
+	"<b>drop</b>\n" +
+	"Remove a single stash entry from the list of stash entries.  If <stash> is not given, stash@{0} is removed."
+
+var stashSynopsis = []string{
+	"[push [-m <message>]]",
+	"list",
+	"apply [<stash>]",
+	"pop [<stash>]",
+	"drop [<stash>]",
+}
+
+const (
+	stashPushId  = "push"
+	stashListId  = "list"
+	stashApplyId = "apply"
+	stashPopId   = "pop"
+	stashDropId  = "drop"
+)
+
+func Stash(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	const stashMessageArg = "message"
+
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp["stash"] = "A stash in the format stash@{n}.  Defaults to stash@{0}."
+	ap.SupportsString(stashMessageArg, "m", "msg", "Use the given <msg> to describe the stash.")
+	help, usage := cli.HelpAndUsagePrinters(commandStr, stashShortDesc, stashLongDesc, stashSynopsis, ap)
+	apr := cli.ParseArgs(ap, args, help)
+
+	var verr errhand.VerboseError
+	switch {
+	case apr.NArg() == 0 || apr.Arg(0) == stashPushId:
+		verr = stashChanges(ctx, dEnv, apr, apr.GetValueOrDefault(stashMessageArg, ""))
+	case apr.Arg(0) == stashListId:
+		verr = listStashes(ctx, dEnv, apr)
+	case apr.Arg(0) == stashApplyId:
+		verr = applyStash(ctx, dEnv, apr, false)
+	case apr.Arg(0) == stashPopId:
+		verr = applyStash(ctx, dEnv, apr, true)
+	case apr.Arg(0) == stashDropId:
+		verr = dropStash(ctx, dEnv, apr)
+	default:
+		verr = errhand.BuildDError("error: unknown subcommand '%s'", apr.Arg(0)).SetPrintUsage().Build()
+	}
+
+	return HandleVErrAndExitCode(verr, usage)
+}
+
+func stashChanges(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults, msg string) errhand.VerboseError {
+	if apr.NArg() > 1 {
+		return errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	if dEnv.IsMergeActive() {
+		return errhand.BuildDError("error: cannot stash while a merge is in progress.").
+			AddDetails("hint: commit or abort the merge before stashing.").Build()
+	}
+
+	working, verr := GetWorkingWithVErr(dEnv)
+
+	if verr != nil {
+		return verr
+	}
+
+	if has, err := working.HasConflicts(ctx); err != nil {
+		return errhand.BuildDError("error: failed to get conflicts").AddCause(err).Build()
+	} else if has {
+		return errhand.BuildDError("error: cannot stash because you have unmerged tables.").Build()
+	}
+
+	err := actions.StashChanges(ctx, dEnv, msg)
+
+	if err == actions.ErrNoLocalChanges {
+		cli.Println("No local changes to save")
+		return nil
+	} else if err != nil {
+		return errhand.BuildDError("error: failed to stash changes").AddCause(err).Build()
+	}
+
+	stash, err := actions.GetStash(ctx, dEnv.DoltDB, "0")
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to read stashes").AddCause(err).Build()
+	}
+
+	cli.Println("Saved working set and staged tables", stash.Meta.Description)
+	return nil
+}
+
+func listStashes(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	if apr.NArg() > 1 {
+		return errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	stashes, err := actions.GetStashes(ctx, dEnv.DoltDB)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to read stashes").AddCause(err).Build()
+	}
+
+	for _, stash := range stashes {
+		cli.Printf("%s: %s\n", stash.Name, stash.Meta.Description)
+	}
+
+	return nil
+}
+
+func getStashFromArgs(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (*actions.StashEntry, errhand.VerboseError) {
+	if apr.NArg() > 2 {
+		return nil, errhand.BuildDError("error: too many arguments").SetPrintUsage().Build()
+	}
+
+	name := "stash@{0}"
+	if apr.NArg() == 2 {
+		name = apr.Arg(1)
+	}
+
+	stash, err := actions.GetStash(ctx, dEnv.DoltDB, name)
+
+	if err == actions.ErrInvalidStashName {
+		return nil, errhand.BuildDError("error: '%s' is not a valid stash reference", name).Build()
+	} else if err == doltdb.ErrStashNotFound {
+		if apr.NArg() == 2 {
+			return nil, errhand.BuildDError("error: %s does not exist", name).Build()
+		}
+
+		return nil, errhand.BuildDError("error: no stash entries found.").Build()
+	} else if err != nil {
+		return nil, errhand.BuildDError("error: failed to read stashes").AddCause(err).Build()
+	}
+
+	return stash, nil
+}
+
+func applyStash(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults, drop bool) errhand.VerboseError {
+	stash, verr := getStashFromArgs(ctx, dEnv, apr)
+
+	if verr != nil {
+		return verr
+	}
+
+	verr = checkWorkingSetCleanForOp(ctx, dEnv, "stash "+apr.Arg(0))
+
+	if verr != nil {
+		return verr
+	}
+
+	tblToStats, err := actions.ApplyStash(ctx, dEnv, stash)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to apply %s", stash.Name).AddCause(err).Build()
+	}
+
+	if hasConflicts := printSuccessStats(tblToStats); hasConflicts {
+		if drop {
+			cli.Println("The stash entry is kept in case you need it again.")
+		}
+
+		return errhand.BuildDError("error: conflicts applying %s", stash.Name).
+			AddDetails("hint: fix conflicts with 'dolt conflicts' and then add the affected tables using 'dolt add <table>'").Build()
+	}
+
+	if drop {
+		return dropStashEntry(ctx, dEnv, stash)
+	}
+
+	return nil
+}
+
+func dropStash(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	stash, verr := getStashFromArgs(ctx, dEnv, apr)
+
+	if verr != nil {
+		return verr
+	}
+
+	return dropStashEntry(ctx, dEnv, stash)
+}
+
+func dropStashEntry(ctx context.Context, dEnv *env.DoltEnv, stash *actions.StashEntry) errhand.VerboseError {
+	h, err := stash.Commit.HashOf()
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to read %s", stash.Name).AddCause(err).Build()
+	}
+
+	err = actions.DropStash(ctx, dEnv.DoltDB, stash)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to drop %s", stash.Name).AddCause(err).Build()
+	}
+
+	cli.Printf("Dropped %s (%s)\n", stash.Name, h.String())
+	return nil
+}
@@ -85,6 +85,11 @@ const (
   (use "dolt commit" to conclude merge)
 `
 
+	rebaseHeader = `You are currently rebasing branch '%s' on '%s'.
+  (fix conflicts, add the affected tables and run "dolt rebase --continue")
+  (use "dolt rebase --abort" to check out the original branch)
+`
+
 	mergedTableHeader = `Unmerged paths:`
 	mergedTableHelp   = `  (use "dolt add <file>..." to mark resolution)`
 
@@ -205,6 +210,10 @@ func printStatus(dEnv *env.DoltEnv, staged, notStaged *actions.TableDiffs, worki
 		}
 	}
 
+	if dEnv.RepoState.Rebase != nil {
+		cli.Println(fmt.Sprintf(rebaseHeader, dEnv.RepoState.Rebase.Branch.Ref.GetPath(), dEnv.RepoState.Rebase.Onto))
+	}
+
 	n := printStagedDiffs(cli.CliOut, staged, true)
 	n = printDiffsNotStaged(cli.CliOut, notStaged, true, n, workingInConflict)
 
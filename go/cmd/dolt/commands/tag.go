@@ -0,0 +1,152 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/cli"
+	"github.com/liquidata-inc/dolt/go/cmd/dolt/errhand"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
+)
+
+var tagShortDesc = `Create, list, or delete tags`
+var tagLongDesc = `If there are no non-option arguments, existing tags are listed.
+
+The command's second form creates a new tag named <tagname> which points to the current <b>HEAD</b>, or <ref> if given. If <b>-m</b> is given an annotated tag is created which records the tagger, the date and the message alongside the tagged commit, otherwise a lightweight tag which only points at the commit is created.
+
+With a <b>-d</b>, <tagname> will be deleted. You may specify more than one tag for deletion.
+
+Tags can be shared with a remote using "dolt push <remote> refs/tags/<tagname>", and are fetched from remotes along with branches.`
+
+var tagSynopsis = []string{
+	`[-v]`,
+	`[-m <message>] [-f] <tagname> [<ref>]`,
+	`-d <tagname>...`,
+}
+
+func Tag(ctx context.Context, commandStr string, args []string, dEnv *env.DoltEnv) int {
+	const tagMessageArg = "message"
+
+	ap := argparser.NewArgParser()
+	ap.ArgListHelp["ref"] = "A commit that a new tag should point at."
+	ap.SupportsString(tagMessageArg, "m", "msg", "Use the given <msg> as the tag message and create an annotated tag.")
+	ap.SupportsFlag(forceFlag, "f", "Replace an existing tag with the given name instead of failing.")
+	ap.SupportsFlag(deleteFlag, "d", "Delete a tag.")
+	ap.SupportsFlag(verboseFlag, "v", "When in list mode, show the hash of the tagged commit, and the tagger, date and message of annotated tags.")
+	help, usage := cli.HelpAndUsagePrinters(commandStr, tagShortDesc, tagLongDesc, tagSynopsis, ap)
+	apr := cli.ParseArgs(ap, args, help)
+
+	var verr errhand.VerboseError
+	switch {
+	case apr.Contains(deleteFlag):
+		verr = deleteTags(ctx, dEnv, apr)
+	case apr.NArg() > 0:
+		verr = createTag(ctx, dEnv, apr, apr.GetValueOrDefault(tagMessageArg, ""))
+	default:
+		verr = listTags(ctx, dEnv, apr)
+	}
+
+	return HandleVErrAndExitCode(verr, usage)
+}
+
+func createTag(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults, msg string) errhand.VerboseError {
+	if apr.NArg() > 2 {
+		return errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	tagName := apr.Arg(0)
+	startPt := "head"
+
+	if apr.NArg() == 2 {
+		startPt = apr.Arg(1)
+	}
+
+	err := actions.CreateTag(ctx, dEnv, tagName, startPt, msg, apr.Contains(forceFlag))
+
+	if err != nil {
+		switch {
+		case err == actions.ErrAlreadyExists:
+			return errhand.BuildDError("fatal: tag '%s' already exists", tagName).Build()
+		case err == doltdb.ErrInvTagName:
+			return errhand.BuildDError("fatal: '%s' is not a valid tag name.", tagName).Build()
+		case err == actions.ErrNameNotConfigured || err == actions.ErrEmailNotConfigured:
+			bdr := errhand.BuildDError("Could not determine the tagger for an annotated tag.")
+			bdr.AddDetails("dolt config [-global|local] -add %s:\"FIRST LAST\" -add %s:\"EMAIL_ADDRESS\"", env.UserNameKey, env.UserEmailKey)
+			return bdr.Build()
+		case err == doltdb.ErrInvHash || doltdb.IsNotACommit(err):
+			return errhand.BuildDError("fatal: '%s' is not a commit and a tag '%s' cannot be created from it", startPt, tagName).Build()
+		default:
+			return errhand.BuildDError("fatal: Unexpected error creating tag '%s'", tagName).AddCause(err).Build()
+		}
+	}
+
+	return nil
+}
+
+func deleteTags(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	if apr.NArg() == 0 {
+		return errhand.BuildDError("").SetPrintUsage().Build()
+	}
+
+	for _, tagName := range apr.Args() {
+		err := actions.DeleteTags(ctx, dEnv, tagName)
+
+		if err == doltdb.ErrTagNotFound {
+			return errhand.BuildDError("error: tag '%s' not found.", tagName).Build()
+		} else if err != nil {
+			return errhand.BuildDError("fatal: Unexpected error deleting tag '%s'", tagName).AddCause(err).Build()
+		}
+	}
+
+	return nil
+}
+
+func listTags(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) errhand.VerboseError {
+	tags, err := actions.GetTags(ctx, dEnv.DoltDB)
+
+	if err != nil {
+		return errhand.BuildDError("error: failed to read tags from db").AddCause(err).Build()
+	}
+
+	for _, tag := range tags {
+		if !apr.Contains(verboseFlag) {
+			cli.Println(tag.Name)
+			continue
+		}
+
+		h, err := tag.Commit.HashOf()
+
+		if err != nil {
+			return errhand.BuildDError("error: failed to hash commit").AddCause(err).Build()
+		}
+
+		cli.Println(fmt.Sprintf("%s\t%s", color.YellowString(tag.Name), h.String()))
+
+		if tag.IsAnnotated() {
+			cli.Printf("\tTagger: %s <%s>\n", tag.Meta.Name, tag.Meta.Email)
+			cli.Printf("\tDate:   %s\n", tag.Meta.FormatTS())
+			cli.Printf("\t%s\n", tag.Meta.Description)
+		}
+	}
+
+	return nil
+}
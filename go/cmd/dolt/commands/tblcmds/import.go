@@ -355,7 +355,10 @@ func executeMove(ctx context.Context, dEnv *env.DoltEnv, force bool, mvOpts *mvd
 		tableDest := mvOpts.Dest.(mvdata.TableDataLocation)
 		err = dEnv.PutTableToWorking(ctx, *nomsWr.GetMap(), nomsWr.GetSchema(), tableDest.Name)
 
-		if err != nil {
+		if doltdb.IsForeignKeyViolation(err) {
+			cli.PrintErrln(color.RedString("Failed to import data: %s", err.Error()))
+			return 1
+		} else if err != nil {
 			cli.PrintErrln(color.RedString("Failed to update the working value."))
 			return 1
 		}
@@ -20,6 +20,7 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/pkg/profile"
@@ -50,12 +51,18 @@ var doltCommand = cli.GenSubCommandHandler([]*cli.Command{
 	{Name: "reset", Desc: "Remove table changes from the list of staged table changes.", Func: commands.Reset, ReqRepo: true, EventType: eventsapi.ClientEventType_RESET},
 	{Name: "commit", Desc: "Record changes to the repository.", Func: commands.Commit, ReqRepo: true, EventType: eventsapi.ClientEventType_COMMIT},
 	{Name: "sql", Desc: "Run a SQL query against tables in repository.", Func: commands.Sql, ReqRepo: true, EventType: eventsapi.ClientEventType_SQL},
-	{Name: "sql-server", Desc: "Starts a MySQL-compatible server.", Func: sqlserver.SqlServer, ReqRepo: true, EventType: eventsapi.ClientEventType_SQL_SERVER},
+	{Name: "sql-server", Desc: "Starts a MySQL-compatible server.", Func: sqlserver.SqlServer, ReqRepo: false, EventType: eventsapi.ClientEventType_SQL_SERVER},
 	{Name: "log", Desc: "Show commit logs.", Func: commands.Log, ReqRepo: true, EventType: eventsapi.ClientEventType_LOG},
+	{Name: "reflog", Desc: "Show the history of updates to refs.", Func: commands.RefLog, ReqRepo: true},
 	{Name: "diff", Desc: "Diff a table.", Func: commands.Diff, ReqRepo: true, EventType: eventsapi.ClientEventType_DIFF},
 	{Name: "blame", Desc: "Show what revision and author last modified each row of a table.", Func: commands.Blame, ReqRepo: true, EventType: eventsapi.ClientEventType_BLAME},
 	{Name: "merge", Desc: "Merge a branch.", Func: commands.Merge, ReqRepo: true, EventType: eventsapi.ClientEventType_MERGE},
+	{Name: "cherry-pick", Desc: "Apply the changes introduced by an existing commit.", Func: commands.CherryPick, ReqRepo: true},
+	{Name: "revert", Desc: "Undo the changes introduced by existing commits.", Func: commands.Revert, ReqRepo: true},
+	{Name: "rebase", Desc: "Reapply commits on top of another base commit.", Func: commands.Rebase, ReqRepo: true},
 	{Name: "branch", Desc: "Create, list, edit, delete branches.", Func: commands.Branch, ReqRepo: true, EventType: eventsapi.ClientEventType_BRANCH},
+	{Name: "tag", Desc: "Create, list, delete tags.", Func: commands.Tag, ReqRepo: true},
+	{Name: "stash", Desc: "Stash the changes in a dirty working set away.", Func: commands.Stash, ReqRepo: true},
 	{Name: "checkout", Desc: "Checkout a branch or overwrite a table from HEAD.", Func: commands.Checkout, ReqRepo: true, EventType: eventsapi.ClientEventType_CHECKOUT},
 	{Name: "remote", Desc: "Manage set of tracked repositories.", Func: commands.Remote, ReqRepo: true, EventType: eventsapi.ClientEventType_REMOTE},
 	{Name: "push", Desc: "Push to a dolt remote.", Func: commands.Push, ReqRepo: true, EventType: eventsapi.ClientEventType_PUSH},
@@ -66,6 +73,7 @@ var doltCommand = cli.GenSubCommandHandler([]*cli.Command{
 	{Name: "login", Desc: "Login to a dolt remote host.", Func: commands.Login, ReqRepo: false, EventType: eventsapi.ClientEventType_LOGIN},
 	{Name: "version", Desc: "Displays the current Dolt cli version.", Func: commands.Version(Version), ReqRepo: false, EventType: eventsapi.ClientEventType_VERSION},
 	{Name: "config", Desc: "Dolt configuration.", Func: commands.Config, ReqRepo: false},
+	{Name: "gc", Desc: "Cleans up unreferenced data from the repository.", Func: commands.GC, ReqRepo: true},
 	{Name: "ls", Desc: "List tables in the working set.", Func: commands.Ls, ReqRepo: true, EventType: eventsapi.ClientEventType_LS},
 	{Name: "schema", Desc: "Commands for showing, and modifying table schemas.", Func: schcmds.Commands, ReqRepo: true, EventType: eventsapi.ClientEventType_SCHEMA},
 	{Name: "table", Desc: "Commands for creating, reading, updating, and deleting tables.", Func: tblcmds.Commands, ReqRepo: false},
@@ -173,6 +181,10 @@ func runMain() int {
 		return 1
 	}
 
+	if dEnv.RefLog != nil {
+		dEnv.RefLog.SetMessage("dolt " + strings.Join(args, " "))
+	}
+
 	return doltCommand(context.Background(), "dolt", args, dEnv)
 }
 
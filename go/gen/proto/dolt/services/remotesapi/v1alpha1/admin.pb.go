@@ -0,0 +1,450 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: dolt/services/remotesapi/v1alpha1/admin.proto
+
+package remotesapi
+
+import (
+	context "context"
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type CreateRepoRequest struct {
+	RepoId               *RepoId           `protobuf:"bytes,1,opt,name=repo_id,json=repoId,proto3" json:"repo_id,omitempty"`
+	RepoFormat           *ClientRepoFormat `protobuf:"bytes,2,opt,name=repo_format,json=repoFormat,proto3" json:"repo_format,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *CreateRepoRequest) Reset()         { *m = CreateRepoRequest{} }
+func (m *CreateRepoRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateRepoRequest) ProtoMessage()    {}
+func (*CreateRepoRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f6556bc81c9af65a, []int{0}
+}
+
+func (m *CreateRepoRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateRepoRequest.Unmarshal(m, b)
+}
+func (m *CreateRepoRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateRepoRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateRepoRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateRepoRequest.Merge(m, src)
+}
+func (m *CreateRepoRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateRepoRequest.Size(m)
+}
+func (m *CreateRepoRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateRepoRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateRepoRequest proto.InternalMessageInfo
+
+func (m *CreateRepoRequest) GetRepoId() *RepoId {
+	if m != nil {
+		return m.RepoId
+	}
+	return nil
+}
+
+func (m *CreateRepoRequest) GetRepoFormat() *ClientRepoFormat {
+	if m != nil {
+		return m.RepoFormat
+	}
+	return nil
+}
+
+type CreateRepoResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CreateRepoResponse) Reset()         { *m = CreateRepoResponse{} }
+func (m *CreateRepoResponse) String() string { return proto.CompactTextString(m) }
+func (*CreateRepoResponse) ProtoMessage()    {}
+func (*CreateRepoResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f6556bc81c9af65a, []int{1}
+}
+
+func (m *CreateRepoResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateRepoResponse.Unmarshal(m, b)
+}
+func (m *CreateRepoResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateRepoResponse.Marshal(b, m, deterministic)
+}
+func (m *CreateRepoResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateRepoResponse.Merge(m, src)
+}
+func (m *CreateRepoResponse) XXX_Size() int {
+	return xxx_messageInfo_CreateRepoResponse.Size(m)
+}
+func (m *CreateRepoResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateRepoResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateRepoResponse proto.InternalMessageInfo
+
+type DeleteRepoRequest struct {
+	RepoId               *RepoId  `protobuf:"bytes,1,opt,name=repo_id,json=repoId,proto3" json:"repo_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRepoRequest) Reset()         { *m = DeleteRepoRequest{} }
+func (m *DeleteRepoRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRepoRequest) ProtoMessage()    {}
+func (*DeleteRepoRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f6556bc81c9af65a, []int{2}
+}
+
+func (m *DeleteRepoRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRepoRequest.Unmarshal(m, b)
+}
+func (m *DeleteRepoRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRepoRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteRepoRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRepoRequest.Merge(m, src)
+}
+func (m *DeleteRepoRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRepoRequest.Size(m)
+}
+func (m *DeleteRepoRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRepoRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRepoRequest proto.InternalMessageInfo
+
+func (m *DeleteRepoRequest) GetRepoId() *RepoId {
+	if m != nil {
+		return m.RepoId
+	}
+	return nil
+}
+
+type DeleteRepoResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRepoResponse) Reset()         { *m = DeleteRepoResponse{} }
+func (m *DeleteRepoResponse) String() string { return proto.CompactTextString(m) }
+func (*DeleteRepoResponse) ProtoMessage()    {}
+func (*DeleteRepoResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f6556bc81c9af65a, []int{3}
+}
+
+func (m *DeleteRepoResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRepoResponse.Unmarshal(m, b)
+}
+func (m *DeleteRepoResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRepoResponse.Marshal(b, m, deterministic)
+}
+func (m *DeleteRepoResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRepoResponse.Merge(m, src)
+}
+func (m *DeleteRepoResponse) XXX_Size() int {
+	return xxx_messageInfo_DeleteRepoResponse.Size(m)
+}
+func (m *DeleteRepoResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRepoResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRepoResponse proto.InternalMessageInfo
+
+type ListReposRequest struct {
+	// If set only the repositories of this org are listed.
+	Org                  string   `protobuf:"bytes,1,opt,name=org,proto3" json:"org,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListReposRequest) Reset()         { *m = ListReposRequest{} }
+func (m *ListReposRequest) String() string { return proto.CompactTextString(m) }
+func (*ListReposRequest) ProtoMessage()    {}
+func (*ListReposRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f6556bc81c9af65a, []int{4}
+}
+
+func (m *ListReposRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReposRequest.Unmarshal(m, b)
+}
+func (m *ListReposRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReposRequest.Marshal(b, m, deterministic)
+}
+func (m *ListReposRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReposRequest.Merge(m, src)
+}
+func (m *ListReposRequest) XXX_Size() int {
+	return xxx_messageInfo_ListReposRequest.Size(m)
+}
+func (m *ListReposRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReposRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReposRequest proto.InternalMessageInfo
+
+func (m *ListReposRequest) GetOrg() string {
+	if m != nil {
+		return m.Org
+	}
+	return ""
+}
+
+type ListReposResponse struct {
+	RepoIds              []*RepoId `protobuf:"bytes,1,rep,name=repo_ids,json=repoIds,proto3" json:"repo_ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ListReposResponse) Reset()         { *m = ListReposResponse{} }
+func (m *ListReposResponse) String() string { return proto.CompactTextString(m) }
+func (*ListReposResponse) ProtoMessage()    {}
+func (*ListReposResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f6556bc81c9af65a, []int{5}
+}
+
+func (m *ListReposResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListReposResponse.Unmarshal(m, b)
+}
+func (m *ListReposResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListReposResponse.Marshal(b, m, deterministic)
+}
+func (m *ListReposResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListReposResponse.Merge(m, src)
+}
+func (m *ListReposResponse) XXX_Size() int {
+	return xxx_messageInfo_ListReposResponse.Size(m)
+}
+func (m *ListReposResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListReposResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListReposResponse proto.InternalMessageInfo
+
+func (m *ListReposResponse) GetRepoIds() []*RepoId {
+	if m != nil {
+		return m.RepoIds
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*CreateRepoRequest)(nil), "dolt.services.remotesapi.v1alpha1.CreateRepoRequest")
+	proto.RegisterType((*CreateRepoResponse)(nil), "dolt.services.remotesapi.v1alpha1.CreateRepoResponse")
+	proto.RegisterType((*DeleteRepoRequest)(nil), "dolt.services.remotesapi.v1alpha1.DeleteRepoRequest")
+	proto.RegisterType((*DeleteRepoResponse)(nil), "dolt.services.remotesapi.v1alpha1.DeleteRepoResponse")
+	proto.RegisterType((*ListReposRequest)(nil), "dolt.services.remotesapi.v1alpha1.ListReposRequest")
+	proto.RegisterType((*ListReposResponse)(nil), "dolt.services.remotesapi.v1alpha1.ListReposResponse")
+}
+
+func init() {
+	proto.RegisterFile("dolt/services/remotesapi/v1alpha1/admin.proto", fileDescriptor_f6556bc81c9af65a)
+}
+
+var fileDescriptor_f6556bc81c9af65a = []byte{
+	// 369 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x53, 0xcb, 0x8b, 0xda, 0x40,
+	0x18, 0x27, 0x15, 0xb4, 0x7e, 0x5e, 0x74, 0xe8, 0x41, 0x72, 0xb2, 0xa1, 0x87, 0xf6, 0x60, 0x06,
+	0x5f, 0xa7, 0x9e, 0xaa, 0x52, 0x28, 0xf4, 0x94, 0x16, 0xc4, 0xbd, 0x2c, 0x63, 0xf2, 0x6d, 0x1c,
+	0x36, 0xc9, 0xc4, 0x99, 0x89, 0xe0, 0x6d, 0xff, 0xa1, 0xfd, 0x1f, 0x97, 0x4c, 0xa2, 0x11, 0xf7,
+	0x60, 0x02, 0x7b, 0x9a, 0x61, 0xf8, 0xbd, 0xe6, 0x7b, 0xc0, 0x38, 0x10, 0x91, 0xa6, 0x0a, 0xe5,
+	0x91, 0xfb, 0xa8, 0xa8, 0xc4, 0x58, 0x68, 0x54, 0x2c, 0xe5, 0xf4, 0x38, 0x61, 0x51, 0xba, 0x67,
+	0x13, 0xca, 0x82, 0x98, 0x27, 0x6e, 0x2a, 0x85, 0x16, 0xe4, 0x6b, 0x0e, 0x77, 0xcf, 0x70, 0xb7,
+	0x82, 0xbb, 0x67, 0xb8, 0x3d, 0xbd, 0xaf, 0xe8, 0xef, 0xb3, 0xe4, 0x59, 0x69, 0x21, 0xb1, 0x90,
+	0x75, 0x5e, 0x2d, 0x18, 0xac, 0x24, 0x32, 0x8d, 0x1e, 0xa6, 0xc2, 0xc3, 0x43, 0x86, 0x4a, 0x93,
+	0x25, 0x74, 0x24, 0xa6, 0xe2, 0x91, 0x07, 0x43, 0x6b, 0x64, 0x7d, 0xef, 0x4d, 0x7f, 0xb8, 0x77,
+	0xed, 0xdd, 0x5c, 0xe0, 0x4f, 0xe0, 0xb5, 0xa5, 0x39, 0xc9, 0x7f, 0xe8, 0x19, 0x8d, 0x27, 0x21,
+	0x63, 0xa6, 0x87, 0x9f, 0x8c, 0xce, 0xac, 0x86, 0xce, 0x2a, 0xe2, 0x98, 0xe8, 0x5c, 0xed, 0xb7,
+	0xa1, 0x7a, 0x20, 0x2f, 0x77, 0xe7, 0x0b, 0x90, 0xeb, 0xb8, 0x2a, 0x15, 0x89, 0x42, 0x67, 0x03,
+	0x83, 0x35, 0x46, 0xf8, 0xe1, 0x9f, 0xc8, 0xed, 0xae, 0x85, 0x4b, 0xbb, 0x6f, 0xd0, 0xff, 0xcb,
+	0x95, 0x89, 0xa8, 0xce, 0x6e, 0x7d, 0x68, 0x09, 0x19, 0x1a, 0xa7, 0xae, 0x97, 0x5f, 0x9d, 0x2d,
+	0x0c, 0xae, 0x50, 0x05, 0x95, 0xac, 0xe1, 0x73, 0x19, 0x4a, 0x0d, 0xad, 0x51, 0xab, 0x59, 0xaa,
+	0x4e, 0x91, 0x4a, 0x4d, 0x5f, 0x5a, 0xd0, 0xcf, 0xdf, 0x7e, 0xe5, 0x03, 0xf2, 0xaf, 0x60, 0x92,
+	0x13, 0x40, 0x55, 0x1a, 0x32, 0xaf, 0x53, 0xe9, 0xdb, 0xc6, 0xdb, 0x8b, 0x86, 0xac, 0xf2, 0x57,
+	0x27, 0x80, 0xaa, 0x4c, 0xb5, 0xac, 0xdf, 0xb5, 0xcb, 0x5e, 0x34, 0x64, 0x95, 0xd6, 0x47, 0xe8,
+	0x5e, 0xaa, 0x4c, 0xea, 0x8c, 0xd7, 0x6d, 0xe7, 0xec, 0x79, 0x33, 0x52, 0xe1, 0xbb, 0xdc, 0x3e,
+	0x6c, 0x42, 0xae, 0xf7, 0xd9, 0xce, 0xf5, 0x45, 0x4c, 0x23, 0x7e, 0xc8, 0x78, 0xc0, 0x34, 0x1b,
+	0xf3, 0xc4, 0xa7, 0x66, 0x0f, 0x43, 0x41, 0x43, 0x4c, 0xa8, 0x59, 0x33, 0x7a, 0x77, 0x33, 0x7f,
+	0x56, 0x6f, 0xbb, 0xb6, 0xe1, 0xcc, 0xde, 0x06, 0x00, 0x3d, 0xed, 0xd8, 0xd2, 0x22, 0x04, 0x00,
+	0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// RepoAdminServiceClient is the client API for RepoAdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type RepoAdminServiceClient interface {
+	// Create an empty repository.  Repositories are not created implicitly by the ChunkStoreService.
+	CreateRepo(ctx context.Context, in *CreateRepoRequest, opts ...grpc.CallOption) (*CreateRepoResponse, error)
+	// Delete a repository and all of its data.
+	DeleteRepo(ctx context.Context, in *DeleteRepoRequest, opts ...grpc.CallOption) (*DeleteRepoResponse, error)
+	ListRepos(ctx context.Context, in *ListReposRequest, opts ...grpc.CallOption) (*ListReposResponse, error)
+}
+
+type repoAdminServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewRepoAdminServiceClient(cc *grpc.ClientConn) RepoAdminServiceClient {
+	return &repoAdminServiceClient{cc}
+}
+
+func (c *repoAdminServiceClient) CreateRepo(ctx context.Context, in *CreateRepoRequest, opts ...grpc.CallOption) (*CreateRepoResponse, error) {
+	out := new(CreateRepoResponse)
+	err := c.cc.Invoke(ctx, "/dolt.services.remotesapi.v1alpha1.RepoAdminService/CreateRepo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repoAdminServiceClient) DeleteRepo(ctx context.Context, in *DeleteRepoRequest, opts ...grpc.CallOption) (*DeleteRepoResponse, error) {
+	out := new(DeleteRepoResponse)
+	err := c.cc.Invoke(ctx, "/dolt.services.remotesapi.v1alpha1.RepoAdminService/DeleteRepo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repoAdminServiceClient) ListRepos(ctx context.Context, in *ListReposRequest, opts ...grpc.CallOption) (*ListReposResponse, error) {
+	out := new(ListReposResponse)
+	err := c.cc.Invoke(ctx, "/dolt.services.remotesapi.v1alpha1.RepoAdminService/ListRepos", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RepoAdminServiceServer is the server API for RepoAdminService service.
+type RepoAdminServiceServer interface {
+	// Create an empty repository.  Repositories are not created implicitly by the ChunkStoreService.
+	CreateRepo(context.Context, *CreateRepoRequest) (*CreateRepoResponse, error)
+	// Delete a repository and all of its data.
+	DeleteRepo(context.Context, *DeleteRepoRequest) (*DeleteRepoResponse, error)
+	ListRepos(context.Context, *ListReposRequest) (*ListReposResponse, error)
+}
+
+// UnimplementedRepoAdminServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedRepoAdminServiceServer struct {
+}
+
+func (*UnimplementedRepoAdminServiceServer) CreateRepo(ctx context.Context, req *CreateRepoRequest) (*CreateRepoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRepo not implemented")
+}
+func (*UnimplementedRepoAdminServiceServer) DeleteRepo(ctx context.Context, req *DeleteRepoRequest) (*DeleteRepoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRepo not implemented")
+}
+func (*UnimplementedRepoAdminServiceServer) ListRepos(ctx context.Context, req *ListReposRequest) (*ListReposResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRepos not implemented")
+}
+
+func RegisterRepoAdminServiceServer(s *grpc.Server, srv RepoAdminServiceServer) {
+	s.RegisterService(&_RepoAdminService_serviceDesc, srv)
+}
+
+func _RepoAdminService_CreateRepo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRepoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepoAdminServiceServer).CreateRepo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dolt.services.remotesapi.v1alpha1.RepoAdminService/CreateRepo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepoAdminServiceServer).CreateRepo(ctx, req.(*CreateRepoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepoAdminService_DeleteRepo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRepoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepoAdminServiceServer).DeleteRepo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dolt.services.remotesapi.v1alpha1.RepoAdminService/DeleteRepo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepoAdminServiceServer).DeleteRepo(ctx, req.(*DeleteRepoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepoAdminService_ListRepos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReposRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepoAdminServiceServer).ListRepos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/dolt.services.remotesapi.v1alpha1.RepoAdminService/ListRepos",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepoAdminServiceServer).ListRepos(ctx, req.(*ListReposRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _RepoAdminService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "dolt.services.remotesapi.v1alpha1.RepoAdminService",
+	HandlerType: (*RepoAdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateRepo",
+			Handler:    _RepoAdminService_CreateRepo_Handler,
+		},
+		{
+			MethodName: "DeleteRepo",
+			Handler:    _RepoAdminService_DeleteRepo_Handler,
+		},
+		{
+			MethodName: "ListRepos",
+			Handler:    _RepoAdminService_ListRepos_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "dolt/services/remotesapi/v1alpha1/admin.proto",
+}
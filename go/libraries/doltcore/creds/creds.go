@@ -44,12 +44,23 @@ const (
 	JWTAlgHeader = "alg"
 )
 
+const (
+	// RemoteAPIAudience is the audience of the bearer tokens sent to remote servers
+	RemoteAPIAudience = "dolthub-remote-api.liquidata.co"
+
+	// ClientIssuer is the issuer of the bearer tokens sent to remote servers
+	ClientIssuer = "dolt-client.liquidata.co"
+
+	clientSubjectPrefix = "doltClientCredentials/"
+)
+
 var B32CredsByteSet = set.NewByteSet([]byte(B32CharEncoding))
 var B32CredsEncoding = base32.NewEncoding(B32CharEncoding).WithPadding(base32.NoPadding)
 var EmptyCreds = DoltCreds{}
 
 var ErrBadB32CredsEncoding = errors.New("bad base32 credentials encoding")
 var ErrCredsNotFound = errors.New("credentials not found")
+var ErrInvalidBearerToken = errors.New("invalid bearer token")
 
 type DoltCreds struct {
 	PubKey  []byte
@@ -133,9 +144,9 @@ func (dc DoltCreds) toBearerToken() (string, error) {
 	// Shouldn't be hard coded
 	jwtBuilder := jwt.Signed(signer)
 	jwtBuilder = jwtBuilder.Claims(jwt.Claims{
-		Audience: []string{"dolthub-remote-api.liquidata.co"},
-		Issuer:   "dolt-client.liquidata.co",
-		Subject:  "doltClientCredentials/" + b32KIDStr,
+		Audience: []string{RemoteAPIAudience},
+		Issuer:   ClientIssuer,
+		Subject:  clientSubjectPrefix + b32KIDStr,
 		Expiry:   jwt.NewNumericDate(datetime.Now().Add(30 * time.Second)),
 	})
 
@@ -157,3 +168,43 @@ func (dc DoltCreds) GetRequestMetadata(ctx context.Context, uri ...string) (map[
 func (dc DoltCreds) RequireTransportSecurity() bool {
 	return false
 }
+
+// VerifyBearerToken checks the signature and claims of a bearer token sent by a dolt client, and returns the base32
+// encoded key id of the credentials that signed it.  pubKeyForKID is used to look up the public key of the key id in
+// the token's header, and ErrCredsNotFound is returned if it isn't found.
+func VerifyBearerToken(token string, pubKeyForKID func(kid string) ([]byte, bool)) (string, error) {
+	tok, err := jwt.ParseSigned(token)
+
+	if err != nil || len(tok.Headers) != 1 {
+		return "", ErrInvalidBearerToken
+	}
+
+	kid := tok.Headers[0].KeyID
+	pubKey, ok := pubKeyForKID(kid)
+
+	if !ok {
+		return "", ErrCredsNotFound
+	} else if len(pubKey) != pubKeySize {
+		return "", ErrInvalidBearerToken
+	}
+
+	var claims jwt.Claims
+	err = tok.Claims(ed25519.PublicKey(pubKey), &claims)
+
+	if err != nil {
+		return "", ErrInvalidBearerToken
+	}
+
+	err = claims.Validate(jwt.Expected{
+		Audience: []string{RemoteAPIAudience},
+		Issuer:   ClientIssuer,
+		Subject:  clientSubjectPrefix + kid,
+		Time:     datetime.Now().Time,
+	})
+
+	if err != nil {
+		return "", ErrInvalidBearerToken
+	}
+
+	return kid, nil
+}
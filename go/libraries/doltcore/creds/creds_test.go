@@ -0,0 +1,61 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package creds
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyBearerToken(t *testing.T) {
+	dc, err := GenerateCredentials()
+	require.NoError(t, err)
+	other, err := GenerateCredentials()
+	require.NoError(t, err)
+
+	md, err := dc.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	token := strings.TrimPrefix(md["authorization"], "Bearer ")
+
+	keys := func(pubKey []byte) func(string) ([]byte, bool) {
+		return func(kid string) ([]byte, bool) {
+			if kid == dc.KeyIDBase32Str() {
+				return pubKey, true
+			}
+
+			return nil, false
+		}
+	}
+
+	kid, err := VerifyBearerToken(token, keys(dc.PubKey))
+	require.NoError(t, err)
+	assert.Equal(t, dc.KeyIDBase32Str(), kid)
+
+	_, err = VerifyBearerToken(token, keys(other.PubKey))
+	assert.Equal(t, ErrInvalidBearerToken, err)
+
+	_, err = VerifyBearerToken(token, func(string) ([]byte, bool) { return nil, false })
+	assert.Equal(t, ErrCredsNotFound, err)
+
+	_, err = VerifyBearerToken(token[:len(token)-2], keys(dc.PubKey))
+	assert.Equal(t, ErrInvalidBearerToken, err)
+
+	_, err = VerifyBearerToken("not a token", keys(dc.PubKey))
+	assert.Equal(t, ErrInvalidBearerToken, err)
+}
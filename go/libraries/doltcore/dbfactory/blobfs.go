@@ -0,0 +1,55 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"net/url"
+	"os"
+
+	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
+	"github.com/liquidata-inc/dolt/go/store/blobstore"
+	"github.com/liquidata-inc/dolt/go/store/datas"
+	"github.com/liquidata-inc/dolt/go/store/nbs"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// BlobFSFactory is a DBFactory implementation for creating databases stored in a directory through the Blobstore
+// interface.  Unlike FileFactory databases, they can be shared by writers on different hosts through a network
+// filesystem, as the manifest is only updated with CheckAndPut.
+type BlobFSFactory struct {
+}
+
+// CreateDB creates a local filesys blobstore backed database
+func (fact BlobFSFactory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]string) (datas.Database, error) {
+	path := urlObj.Host + urlObj.Path
+
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, filesys.ErrIsFile
+	}
+
+	bs := blobstore.NewLocalBlobstore(path)
+	st, err := nbs.NewBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return datas.NewDatabase(st), nil
+}
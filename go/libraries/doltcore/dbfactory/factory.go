@@ -35,6 +35,12 @@ const (
 	// FileScheme
 	FileScheme = "file"
 
+	// BlobFSScheme
+	BlobFSScheme = "blobfs"
+
+	// S3Scheme
+	S3Scheme = "s3"
+
 	// MemScheme
 	MemScheme = "mem"
 
@@ -56,10 +62,12 @@ type DBFactory interface {
 // DBFactories is a map from url scheme name to DBFactory.  Additional factories can be added to the DBFactories map
 // from external packages.
 var DBFactories = map[string]DBFactory{
-	AWSScheme:  AWSFactory{},
-	GSScheme:   GSFactory{},
-	FileScheme: FileFactory{},
-	MemScheme:  MemFactory{},
+	AWSScheme:    AWSFactory{},
+	GSScheme:     GSFactory{},
+	FileScheme:   FileFactory{},
+	BlobFSScheme: BlobFSFactory{},
+	S3Scheme:     S3Factory{},
+	MemScheme:    MemFactory{},
 }
 
 // InitializeFactories initializes any factories that rely on a GRPCConnectionProvider (Namely http and https)
@@ -16,11 +16,17 @@ package dbfactory
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/liquidata-inc/dolt/go/store/blobstore"
 	"github.com/liquidata-inc/dolt/go/store/types"
+	"github.com/liquidata-inc/dolt/go/store/util/s3test"
 )
 
 /*
@@ -59,3 +65,65 @@ func TestCreateMemDB(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, db)
 }
+
+func TestCreateBlobFSDB(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := CreateDB(ctx, types.Format_7_18, "blobfs://"+dir, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, db)
+
+	_, err = CreateDB(ctx, types.Format_7_18, "blobfs://"+filepath.Join(dir, "missing"), nil)
+	assert.Error(t, err)
+}
+
+func TestCreateS3DB(t *testing.T) {
+	ctx := context.Background()
+	srv := s3test.NewServer()
+	defer srv.Close()
+
+	params := map[string]string{
+		S3EndpointParam:   srv.URL,
+		S3PathStyleParam:  "true",
+		AWSCredsTypeParam: "env",
+	}
+
+	os.Setenv("AWS_ACCESS_KEY_ID", "id")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+	defer os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+
+	db, err := CreateDB(ctx, types.Format_7_18, "s3://bucket/db", params)
+	require.NoError(t, err)
+	ds, err := db.GetDataset(ctx, "ds")
+	require.NoError(t, err)
+	_, err = db.CommitValue(ctx, ds, types.String("value"))
+	require.NoError(t, err)
+
+	db, err = CreateDB(ctx, types.Format_7_18, "s3://bucket/db", params)
+	require.NoError(t, err)
+	ds, err = db.GetDataset(ctx, "ds")
+	require.NoError(t, err)
+	val, ok, err := ds.MaybeHeadValue()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, types.String("value"), val)
+
+	for _, urlStr := range []string{"s3://bucket", "s3:///db", "s3://bucket/db/nested"} {
+		_, err = CreateDB(ctx, types.Format_7_18, urlStr, params)
+		assert.Error(t, err, urlStr)
+	}
+
+	_, err = CreateDB(ctx, types.Format_7_18, "s3://bucket/db", map[string]string{S3EndpointParam: srv.URL, S3PathStyleParam: "sometimes"})
+	assert.Error(t, err)
+	// object stores which ignore conditional writes are refused
+	noCondSrv := s3test.NewServer()
+	defer noCondSrv.Close()
+	noCondSrv.IgnoreConditions = true
+	params[S3EndpointParam] = noCondSrv.URL
+	_, err = CreateDB(ctx, types.Format_7_18, "s3://bucket/db", params)
+	assert.Equal(t, blobstore.ErrConditionalWritesNotSupported, err)
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dbfactory
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/liquidata-inc/dolt/go/store/blobstore"
+	"github.com/liquidata-inc/dolt/go/store/datas"
+	"github.com/liquidata-inc/dolt/go/store/nbs"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+const (
+	// S3EndpointParam is a creation parameter that can be used to set the endpoint of an S3 compatible object store
+	// such as MinIO or Ceph.  When it's not set the AWS endpoint for the region is used.
+	S3EndpointParam = "s3-endpoint"
+
+	// S3PathStyleParam is a creation parameter that can be used to address buckets with the path of the url rather
+	// than the hostname.  Most S3 compatible object stores require it.
+	S3PathStyleParam = "s3-path-style"
+
+	// defaultS3Region is the region used when an endpoint is given without one.  S3 compatible object stores generally
+	// ignore the region, but requests can't be signed without one.
+	defaultS3Region = "us-east-1"
+)
+
+// S3Factory is a DBFactory implementation for creating databases stored in an S3 compatible object store.  Unlike
+// AWSFactory databases, the manifest is stored in the bucket alongside the table files and updated with conditional
+// writes, so no DynamoDB table is needed.
+type S3Factory struct {
+}
+
+// CreateDB creates an S3 compatible object store backed database
+func (fact S3Factory) CreateDB(ctx context.Context, nbf *types.NomsBinFormat, urlObj *url.URL, params map[string]string) (datas.Database, error) {
+	if urlObj.Host == "" {
+		return nil, errors.New("s3 url has no bucket")
+	}
+
+	dbName, err := validatePath(urlObj.Path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := awsConfigFromParams(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if endpoint, ok := params[S3EndpointParam]; ok {
+		opts.Config.WithEndpoint(endpoint)
+
+		if opts.Config.Region == nil {
+			opts.Config.WithRegion(defaultS3Region)
+		}
+	}
+
+	if val, ok := params[S3PathStyleParam]; ok {
+		pathStyle, err := strconv.ParseBool(strings.TrimSpace(val))
+
+		if err != nil {
+			return nil, errors.New("invalid value for " + S3PathStyleParam)
+		}
+
+		opts.Config.WithS3ForcePathStyle(pathStyle)
+	}
+
+	sess, err := session.NewSessionWithOptions(opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bs := blobstore.NewS3Blobstore(s3.New(sess), urlObj.Host, dbName+"/")
+
+	if err := bs.CheckConditionalWrites(ctx); err != nil {
+		return nil, err
+	}
+
+	st, err := nbs.NewBSStore(ctx, nbf.VersionString(), bs, defaultMemTableSize)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return datas.NewDatabase(st), nil
+}
@@ -45,12 +45,15 @@ func IsValidBranchRef(dref ref.DoltRef) bool {
 type CommitSpecType string
 
 const (
-	RefCommitSpec  CommitSpecType = "ref"
-	HashCommitSpec CommitSpecType = "hash"
+	RefCommitSpec    CommitSpecType = "ref"
+	HashCommitSpec   CommitSpecType = "hash"
+	RefLogCommitSpec CommitSpecType = "reflog"
 )
 
 // CommitSpec handles three different types of string representations of commits.  Commits can either be represented
 // by the hash of the commit, a branch name, or using "head" to represent the latest commit of the current branch.
+// Any of the names can be suffixed with @{n} to reference the commit the name pointed at n updates ago, as recorded in
+// the reflog.
 // An Ancestor spec can be appended to the end of any of these in order to reach commits that are in the ancestor tree
 // of the referenced commit.
 type CommitSpec struct {
@@ -70,6 +73,10 @@ func NewCommitSpec(cSpecStr, cwb string) (*CommitSpec, error) {
 		return nil, err
 	}
 
+	if rls, ok := parseRefLogSpec(name); ok {
+		return &CommitSpec{rls, RefLogCommitSpec, as}, nil
+	}
+
 	if strings.ToLower(name) == head {
 		name = cwb
 	}
@@ -51,6 +51,10 @@ func TestNewCommitSpec(t *testing.T) {
 		{"head^~2", "master", "refs/heads/master", "^~2", false},
 		{"00000000000000000000000000000000", "", "00000000000000000000000000000000", "", false},
 		{"head", "", "", "", true},
+		{"head@{1}", "", "HEAD@{1}", "", false},
+		{"HEAD@{0}~2", "refs/heads/master", "HEAD@{0}", "~2", false},
+		{"master@{3}", "", "refs/heads/master@{3}", "", false},
+		{"refs/tags/v1@{1}", "", "refs/tags/v1@{1}", "", false},
 	}
 
 	for _, test := range tests {
@@ -57,14 +57,16 @@ var InMemDoltDB = "mem://"
 // Additionally the noms codebase uses panics in a way that is non idiomatic and I've opted to recover and return
 // errors in many cases.
 type DoltDB struct {
-	db datas.Database
+	db      datas.Database
+	refLog  RefLog
+	shallow hash.HashSet
 }
 
 // DoltDBFromCS creates a DoltDB from a noms chunks.ChunkStore
 func DoltDBFromCS(cs chunks.ChunkStore) *DoltDB {
 	db := datas.NewDatabase(cs)
 
-	return &DoltDB{db}
+	return &DoltDB{db: db}
 }
 
 // LoadDoltDB will acquire a reference to the underlying noms db.  If the Location is InMemDoltDB then a reference
@@ -91,7 +93,7 @@ func LoadDoltDBWithParams(ctx context.Context, nbf *types.NomsBinFormat, urlStr
 		return nil, err
 	}
 
-	return &DoltDB{db}, nil
+	return &DoltDB{db: db}, nil
 }
 
 // WriteEmptyRepo will create initialize the given db with a master branch which points to a commit which has valid
@@ -187,9 +189,19 @@ func getCommitStForRef(ctx context.Context, db datas.Database, dref ref.DoltRef)
 
 	dsHead, hasHead := ds.MaybeHead()
 	if hasHead {
+		if dref.GetType() == ref.TagRefType {
+			return peelTagSt(ctx, db, dsHead)
+		}
+
 		return dsHead, nil
 	}
 
+	if dref.GetType() == ref.TagRefType {
+		return types.EmptyStruct(db.Format()), ErrTagNotFound
+	} else if dref.GetType() == ref.StashRefType {
+		return types.EmptyStruct(db.Format()), ErrStashNotFound
+	}
+
 	return types.EmptyStruct(db.Format()), ErrBranchNotFound
 }
 
@@ -221,7 +233,9 @@ func getCommitStForHash(ctx context.Context, db datas.Database, c string) (types
 	return valSt, nil
 }
 
-func walkAncestorSpec(ctx context.Context, db datas.Database, commitSt types.Struct, aSpec *AncestorSpec) (types.Struct, error) {
+func (ddb *DoltDB) walkAncestorSpec(ctx context.Context, commitSt types.Struct, aSpec *AncestorSpec) (types.Struct, error) {
+	db := ddb.db
+
 	if aSpec == nil || len(aSpec.Instructions) == 0 {
 		return commitSt, nil
 	}
@@ -230,6 +244,12 @@ func walkAncestorSpec(ctx context.Context, db datas.Database, commitSt types.Str
 	for _, inst := range instructions {
 		cm := Commit{db, commitSt}
 
+		if isShallow, err := ddb.IsShallowCommit(&cm); err != nil {
+			return types.EmptyStruct(db.Format()), err
+		} else if isShallow {
+			return types.EmptyStruct(db.Format()), ErrBeyondShallowBoundary
+		}
+
 		numPars, err := cm.NumParents()
 
 		if err != nil {
@@ -265,15 +285,38 @@ func (ddb *DoltDB) Resolve(ctx context.Context, cs *CommitSpec) (*Commit, error)
 	var err error
 	if cs.CSType == HashCommitSpec {
 		commitSt, err = getCommitStForHash(ctx, ddb.db, cs.CommitStringer.String())
+	} else if cs.CSType == RefLogCommitSpec {
+		var h hash.Hash
+		h, err = ddb.resolveRefLogSpec(ctx, cs.CommitStringer.(refLogSpec))
+
+		if err == nil {
+			commitSt, err = getCommitStForHash(ctx, ddb.db, h.String())
+		}
+
+		// the reflog of an annotated tag records the tag rather than the tagged commit
+		if err == nil {
+			commitSt, err = peelTagSt(ctx, ddb.db, commitSt)
+		}
 	} else if cs.CSType == RefCommitSpec {
-		commitSt, err = getCommitStForRef(ctx, ddb.db, cs.CommitStringer.(ref.DoltRef))
+		dref := cs.CommitStringer.(ref.DoltRef)
+		commitSt, err = getCommitStForRef(ctx, ddb.db, dref)
+
+		// names which aren't fully qualified refs are parsed as branch names.  If no such branch exists fall back to a
+		// tag with the same name.
+		if err == ErrBranchNotFound && dref.GetType() == ref.BranchRefType {
+			commitSt, err = getCommitStForRef(ctx, ddb.db, ref.NewTagRef(dref.GetPath()))
+
+			if err == ErrTagNotFound {
+				err = ErrBranchNotFound
+			}
+		}
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	commitSt, err = walkAncestorSpec(ctx, ddb.db, commitSt, cs.ASpec)
+	commitSt, err = ddb.walkAncestorSpec(ctx, commitSt, cs.ASpec)
 
 	if err != nil {
 		return nil, err
@@ -320,6 +363,12 @@ func (ddb *DoltDB) ReadRootValue(ctx context.Context, h hash.Hash) (*RootValue,
 	return nil, errors.New("there is no dolt root value at that hash")
 }
 
+// Refresh updates the DoltDB's view of its underlying storage so that values written by other processes since it was
+// loaded can be read.
+func (ddb *DoltDB) Refresh(ctx context.Context) error {
+	return ddb.db.Rebase(ctx)
+}
+
 // Commit will update a branch's head value to be that of a previously committed root value hash
 func (ddb *DoltDB) Commit(ctx context.Context, valHash hash.Hash, dref ref.DoltRef, cm *CommitMeta) (*Commit, error) {
 	if dref.GetType() != ref.BranchRefType {
@@ -345,7 +394,11 @@ func (ddb *DoltDB) FastForward(ctx context.Context, branch ref.DoltRef, commit *
 
 	_, err = ddb.db.FastForward(ctx, ds, rf)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return ddb.logRefUpdate(ctx, branch, ds, rf.TargetHash())
 }
 
 // CanFastForward returns whether the given branch can be fast-forwarded to the commit given.
@@ -368,6 +421,7 @@ func (ddb *DoltDB) CanFastForward(ctx context.Context, branch ref.DoltRef, new *
 // error if the value or any parents can't be resolved, or if anything goes wrong accessing the underlying storage.
 func (ddb *DoltDB) CommitWithParents(ctx context.Context, valHash hash.Hash, dref ref.DoltRef, parentCmSpecs []*CommitSpec, cm *CommitMeta) (*Commit, error) {
 	var commitSt types.Struct
+	var oldDs datas.Dataset
 	err := pantoerr.PanicToError("error committing value "+valHash.String(), func() error {
 		val, err := ddb.db.ReadValue(ctx, valHash)
 
@@ -385,6 +439,7 @@ func (ddb *DoltDB) CommitWithParents(ctx context.Context, valHash hash.Hash, dre
 			return err
 		}
 
+		oldDs = ds
 		s, err := types.NewSet(ctx, ddb.db)
 
 		if err != nil {
@@ -465,7 +520,20 @@ func (ddb *DoltDB) CommitWithParents(ctx context.Context, valHash hash.Hash, dre
 		return nil, err
 	}
 
-	return &Commit{ddb.db, commitSt}, nil
+	commit := &Commit{ddb.db, commitSt}
+	h, err := commit.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = ddb.logRefUpdate(ctx, dref, oldDs, h)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return commit, nil
 }
 
 // ValueReadWriter returns the underlying noms database as a types.ValueReadWriter.
@@ -498,9 +566,15 @@ func writeValAndGetRef(ctx context.Context, vrw types.ValueReadWriter, val types
 }
 
 // ResolveParent returns the n-th ancestor of a given commit (direct parent is index 0). error return value will be
-// non-nil in the case that the commit cannot be resolved, there aren't as many ancestors as requested, or the
-// underlying storage cannot be accessed.
+// non-nil in the case that the commit cannot be resolved, there aren't as many ancestors as requested, the commit is a
+// shallow commit whose parents haven't been fetched, or the underlying storage cannot be accessed.
 func (ddb *DoltDB) ResolveParent(ctx context.Context, commit *Commit, parentIdx int) (*Commit, error) {
+	if isShallow, err := ddb.IsShallowCommit(commit); err != nil {
+		return nil, err
+	} else if isShallow {
+		return nil, ErrBeyondShallowBoundary
+	}
+
 	var parentCommitSt types.Struct
 	parentSet, err := commit.getParents()
 
@@ -589,6 +663,12 @@ func (ddb *DoltDB) NewBranchAtCommit(ctx context.Context, dref ref.DoltRef, comm
 		panic(fmt.Sprintf("invalid branch name %s, use IsValidUserBranchName check", dref.String()))
 	}
 
+	return ddb.SetHeadToCommit(ctx, dref, commit)
+}
+
+// SetHeadToCommit moves the ref given to the commit given, creating it if it doesn't exist.  Unlike FastForward the
+// commit doesn't need to be a descendant of the one the ref points at.
+func (ddb *DoltDB) SetHeadToCommit(ctx context.Context, dref ref.DoltRef, commit *Commit) error {
 	ds, err := ddb.db.GetDataset(ctx, dref.String())
 
 	if err != nil {
@@ -603,7 +683,11 @@ func (ddb *DoltDB) NewBranchAtCommit(ctx context.Context, dref ref.DoltRef, comm
 
 	_, err = ddb.db.SetHead(ctx, ds, rf)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return ddb.logRefUpdate(ctx, dref, ds, rf.TargetHash())
 }
 
 // DeleteBranch deletes the branch given, returning an error if it doesn't exist.
@@ -619,13 +703,192 @@ func (ddb *DoltDB) DeleteBranch(ctx context.Context, dref ref.DoltRef) error {
 	}
 
 	_, err = ddb.db.Delete(ctx, ds)
-	return err
+
+	if err != nil {
+		return err
+	}
+
+	return ddb.logRefUpdate(ctx, dref, ds, hash.Hash{})
+}
+
+var tagRefFilter = map[ref.RefType]struct{}{ref.TagRefType: {}}
+
+// GetTags returns a list of all tags in the database.
+func (ddb *DoltDB) GetTags(ctx context.Context) ([]ref.DoltRef, error) {
+	return ddb.GetRefsOfType(ctx, tagRefFilter)
+}
+
+// NewTagAtCommit creates a new tag pointing at the commit given.  If meta is nil a lightweight tag is created,
+// otherwise an annotated tag storing the tagger, date and message is written.  Tag names must pass
+// ref.IsValidTagName.
+func (ddb *DoltDB) NewTagAtCommit(ctx context.Context, tagRef ref.DoltRef, commit *Commit, meta *TagMeta) error {
+	if tagRef.GetType() != ref.TagRefType || !ref.IsValidTagName(tagRef.GetPath()) {
+		panic(fmt.Sprintf("invalid tag name %s, use IsValidTagName check", tagRef.String()))
+	}
+
+	ds, err := ddb.db.GetDataset(ctx, tagRef.String())
+
+	if err != nil {
+		return err
+	}
+
+	rf, err := types.NewRef(commit.commitSt, ddb.db.Format())
+
+	if err != nil {
+		return err
+	}
+
+	if meta != nil {
+		metaSt, err := meta.toNomsStruct(ddb.db.Format())
+
+		if err != nil {
+			return err
+		}
+
+		parents, err := types.NewSet(ctx, ddb.db)
+
+		if err != nil {
+			return err
+		}
+
+		tagSt, err := datas.NewCommit(rf, parents, metaSt)
+
+		if err != nil {
+			return err
+		}
+
+		rf, err = ddb.db.WriteValue(ctx, tagSt)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = ddb.db.SetHead(ctx, ds, rf)
+
+	if err != nil {
+		return err
+	}
+
+	return ddb.logRefUpdate(ctx, tagRef, ds, rf.TargetHash())
+}
+
+// ResolveTag takes a reference to a tag and returns the Tag, or ErrTagNotFound if it does not exist.
+func (ddb *DoltDB) ResolveTag(ctx context.Context, tagRef ref.DoltRef) (*Tag, error) {
+	ds, err := ddb.db.GetDataset(ctx, tagRef.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	dsHead, hasHead := ds.MaybeHead()
+
+	if !hasHead {
+		return nil, ErrTagNotFound
+	}
+
+	return newTag(ctx, ddb.db, tagRef.GetPath(), dsHead)
+}
+
+// SetTag points the tag given at a Tag which may have been resolved from another database.  The chunks for the tag
+// must already exist in this database (see PullTagChunks).
+func (ddb *DoltDB) SetTag(ctx context.Context, tagRef ref.DoltRef, tag *Tag) error {
+	ds, err := ddb.db.GetDataset(ctx, tagRef.String())
+
+	if err != nil {
+		return err
+	}
+
+	rf, err := types.NewRef(tag.tagSt, ddb.db.Format())
+
+	if err != nil {
+		return err
+	}
+
+	_, err = ddb.db.SetHead(ctx, ds, rf)
+
+	if err != nil {
+		return err
+	}
+
+	return ddb.logRefUpdate(ctx, tagRef, ds, rf.TargetHash())
+}
+
+// DeleteTag deletes the tag given, returning ErrTagNotFound if it doesn't exist.
+func (ddb *DoltDB) DeleteTag(ctx context.Context, tagRef ref.DoltRef) error {
+	ds, err := ddb.db.GetDataset(ctx, tagRef.String())
+
+	if err != nil {
+		return err
+	}
+
+	if !ds.HasHead() {
+		return ErrTagNotFound
+	}
+
+	_, err = ddb.db.Delete(ctx, ds)
+
+	if err != nil {
+		return err
+	}
+
+	return ddb.logRefUpdate(ctx, tagRef, ds, hash.Hash{})
+}
+
+var stashRefFilter = map[ref.RefType]struct{}{ref.StashRefType: {}}
+
+// GetStashes returns a list of all stash refs in the database.  The list is in no particular order.
+func (ddb *DoltDB) GetStashes(ctx context.Context) ([]ref.DoltRef, error) {
+	return ddb.GetRefsOfType(ctx, stashRefFilter)
+}
+
+// DeleteStash deletes the stash given, returning ErrStashNotFound if it doesn't exist.
+func (ddb *DoltDB) DeleteStash(ctx context.Context, stashRef ref.DoltRef) error {
+	ds, err := ddb.db.GetDataset(ctx, stashRef.String())
+
+	if err != nil {
+		return err
+	}
+
+	if !ds.HasHead() {
+		return ErrStashNotFound
+	}
+
+	_, err = ddb.db.Delete(ctx, ds)
+
+	if err != nil {
+		return err
+	}
+
+	return ddb.logRefUpdate(ctx, stashRef, ds, hash.Hash{})
 }
 
 // PushChunks initiates a push into a database from the source database given, at the commit given. Pull progress is
 // communicated over the provided channel.
 func (ddb *DoltDB) PushChunks(ctx context.Context, tempDir string, srcDB *DoltDB, cm *Commit, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
-	rf, err := types.NewRef(cm.commitSt, ddb.db.Format())
+	err := ddb.checkHasShallowParents(ctx, srcDB)
+
+	if err != nil {
+		return err
+	}
+
+	return ddb.pushChunksForSt(ctx, tempDir, srcDB, cm.commitSt, progChan, pullerEventCh)
+}
+
+// PushTagChunks initiates a push into a database from the source database given of all the chunks needed by the tag
+// given. Pull progress is communicated over the provided channel.
+func (ddb *DoltDB) PushTagChunks(ctx context.Context, tempDir string, srcDB *DoltDB, tag *Tag, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	err := ddb.checkHasShallowParents(ctx, srcDB)
+
+	if err != nil {
+		return err
+	}
+
+	return ddb.pushChunksForSt(ctx, tempDir, srcDB, tag.tagSt, progChan, pullerEventCh)
+}
+
+func (ddb *DoltDB) pushChunksForSt(ctx context.Context, tempDir string, srcDB *DoltDB, st types.Struct, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	rf, err := types.NewRef(st, ddb.db.Format())
 
 	if err != nil {
 		return err
@@ -649,14 +912,30 @@ func (ddb *DoltDB) PushChunks(ctx context.Context, tempDir string, srcDB *DoltDB
 // PullChunks initiates a pull into a database from the source database given, at the commit given. Progress is
 // communicated over the provided channel.
 func (ddb *DoltDB) PullChunks(ctx context.Context, tempDir string, srcDB *DoltDB, cm *Commit, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
-	rf, err := types.NewRef(cm.commitSt, ddb.db.Format())
+	return ddb.pullChunksForSt(ctx, tempDir, srcDB, cm.commitSt, progChan, pullerEventCh)
+}
+
+// PullTagChunks initiates a pull into a database from the source database given of all the chunks needed by the tag
+// given. Progress is communicated over the provided channel.
+func (ddb *DoltDB) PullTagChunks(ctx context.Context, tempDir string, srcDB *DoltDB, tag *Tag, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	return ddb.pullChunksForSt(ctx, tempDir, srcDB, tag.tagSt, progChan, pullerEventCh)
+}
+
+func (ddb *DoltDB) pullChunksForSt(ctx context.Context, tempDir string, srcDB *DoltDB, st types.Struct, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	return ddb.pullShallowChunksForSt(ctx, tempDir, srcDB, st, nil, progChan, pullerEventCh)
+}
+
+// pullShallowChunksForSt pulls the chunks needed by the struct given, other than the chunks in skip and the chunks
+// which are only reachable through them.
+func (ddb *DoltDB) pullShallowChunksForSt(ctx context.Context, tempDir string, srcDB *DoltDB, st types.Struct, skip hash.HashSet, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	rf, err := types.NewRef(st, ddb.db.Format())
 
 	if err != nil {
 		return err
 	}
 
 	if datas.CanUsePuller(srcDB.db) && datas.CanUsePuller(ddb.db) {
-		puller, err := datas.NewPuller(ctx, tempDir, 256*1024, srcDB.db, ddb.db, rf.TargetHash(), pullerEventCh)
+		puller, err := datas.NewShallowPuller(ctx, tempDir, 256*1024, srcDB.db, ddb.db, rf.TargetHash(), skip, pullerEventCh)
 
 		if err == datas.ErrDBUpToDate {
 			return nil
@@ -666,10 +945,21 @@ func (ddb *DoltDB) PullChunks(ctx context.Context, tempDir string, srcDB *DoltDB
 
 		return puller.Pull(ctx)
 	} else {
-		return datas.PullWithoutBatching(ctx, srcDB.db, ddb.db, rf, progChan)
+		return datas.PullShallow(ctx, srcDB.db, ddb.db, rf, skip, progChan)
 	}
 }
 
 func (ddb *DoltDB) Clone(ctx context.Context, destDB *DoltDB, eventCh chan<- datas.TableFileEvent) error {
 	return datas.Clone(ctx, ddb.db, destDB.db, eventCh)
 }
+
+// GC removes all data which is not reachable from a ref in the database, or from one of the extra roots given.  The
+// extra roots are the hashes of values which are referenced from outside of the database, such as the working and
+// staged root values of a repository.
+func (ddb *DoltDB) GC(ctx context.Context, extraRoots ...hash.Hash) error {
+	if ddb.IsShallow() {
+		return ErrShallowGC
+	}
+
+	return datas.CollectGarbage(ctx, ddb.db, hash.NewHashSet(extraRoots...))
+}
@@ -17,6 +17,7 @@ package doltdb
 import "errors"
 
 var ErrInvBranchName = errors.New("not a valid user branch name")
+var ErrInvTagName = errors.New("not a valid tag name")
 var ErrInvTableName = errors.New("not a valid table name")
 var ErrInvHash = errors.New("not a valid hash")
 var ErrInvalidAncestorSpec = errors.New("invalid ancestor spec")
@@ -26,6 +27,9 @@ var ErrFoundHashNotACommit = errors.New("the value retrieved for this hash is no
 
 var ErrHashNotFound = errors.New("could not find a value for this hash")
 var ErrBranchNotFound = errors.New("branch not found")
+var ErrTagNotFound = errors.New("tag not found")
+var ErrStashNotFound = errors.New("stash not found")
+var ErrRefLogEntryNotFound = errors.New("reflog entry not found")
 var ErrTableNotFound = errors.New("table not found")
 var ErrTableExists = errors.New("table already exists")
 var ErrAlreadyOnBranch = errors.New("Already on branch")
@@ -37,9 +41,14 @@ var ErrUpToDate = errors.New("up to date")
 var ErrIsAhead = errors.New("current fast forward from a to b. a is ahead of b already")
 var ErrIsBehind = errors.New("cannot reverse from b to a. b is a is behind a already")
 
+var ErrBeyondShallowBoundary = errors.New("the parents of this commit are beyond the shallow boundary of the repository")
+var ErrShallowPush = errors.New("the destination is missing history beyond the shallow boundary of the repository")
+var ErrShallowGC = errors.New("garbage collection is not supported in shallow repositories")
+var ErrInvalidDepth = errors.New("depth must be a positive number of commits")
+
 func IsInvalidFormatErr(err error) bool {
 	switch err {
-	case ErrInvBranchName, ErrInvTableName, ErrInvHash, ErrInvalidAncestorSpec, ErrInvalidBranchOrHash:
+	case ErrInvBranchName, ErrInvTagName, ErrInvTableName, ErrInvHash, ErrInvalidAncestorSpec, ErrInvalidBranchOrHash:
 		return true
 	default:
 		return false
@@ -48,7 +57,7 @@ func IsInvalidFormatErr(err error) bool {
 
 func IsNotFoundErr(err error) bool {
 	switch err {
-	case ErrHashNotFound, ErrBranchNotFound, ErrTableNotFound:
+	case ErrHashNotFound, ErrBranchNotFound, ErrTagNotFound, ErrStashNotFound, ErrRefLogEntryNotFound, ErrTableNotFound:
 		return true
 	default:
 		return false
@@ -57,7 +66,7 @@ func IsNotFoundErr(err error) bool {
 
 func IsNotACommit(err error) bool {
 	switch err {
-	case ErrHashNotFound, ErrBranchNotFound, ErrFoundHashNotACommit:
+	case ErrHashNotFound, ErrBranchNotFound, ErrTagNotFound, ErrStashNotFound, ErrRefLogEntryNotFound, ErrFoundHashNotACommit:
 		return true
 	default:
 		return false
@@ -0,0 +1,671 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/atomicerr"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// ForeignKeyViolation describes a row of a child table whose foreign key values don't match the referenced values of
+// any row of the parent table.
+type ForeignKeyViolation struct {
+	// Table is the name of the child table
+	Table string
+	// ForeignKey is the violated foreign key of the child table
+	ForeignKey schema.ForeignKey
+	// Key is the primary key of the violating row of the child table
+	Key types.Tuple
+}
+
+// ForeignKeyViolationError is returned when a change would leave a table with a row that violates a foreign key.
+type ForeignKeyViolationError struct {
+	Violation ForeignKeyViolation
+}
+
+func (e ForeignKeyViolationError) Error() string {
+	fk := e.Violation.ForeignKey
+	return fmt.Sprintf("foreign key constraint '%s' fails: a row of table '%s' references a row of table '%s' that does not exist", fk.Name, e.Violation.Table, fk.ReferencedTable)
+}
+
+// IsForeignKeyViolation returns true if the error is a ForeignKeyViolationError
+func IsForeignKeyViolation(err error) bool {
+	_, ok := err.(ForeignKeyViolationError)
+	return ok
+}
+
+// TableForeignKey is a foreign key along with the name of the child table it is defined on.
+type TableForeignKey struct {
+	Table      string
+	ForeignKey schema.ForeignKey
+}
+
+// ForeignKeysReferencing returns the foreign keys of all tables in the root, including the named table itself, which
+// reference the named table.
+func (root *RootValue) ForeignKeysReferencing(ctx context.Context, tableName string) ([]TableForeignKey, error) {
+	names, err := root.GetTableNames(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var referencing []TableForeignKey
+	for _, name := range names {
+		tbl, _, err := root.GetTable(ctx, name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sch, err := tbl.GetSchema(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fk := range sch.ForeignKeys().GetForeignKeys() {
+			if fk.ReferencedTable == tableName {
+				referencing = append(referencing, TableForeignKey{name, fk})
+			}
+		}
+	}
+
+	return referencing, nil
+}
+
+// CheckNotReferenced returns an error if a foreign key of another table references the named table, which therefore
+// can't be dropped.  If tags are given, only foreign keys which reference one of the columns with those tags are
+// considered, including those of the table itself unless they would be dropped along with the columns.
+func (root *RootValue) CheckNotReferenced(ctx context.Context, tableName string, tags ...uint64) error {
+	referencing, err := root.ForeignKeysReferencing(ctx, tableName)
+
+	if err != nil {
+		return err
+	}
+
+	for _, tblFK := range referencing {
+		fk := tblFK.ForeignKey
+
+		if len(tags) == 0 {
+			if tblFK.Table != tableName {
+				return fmt.Errorf("table '%s' is referenced by foreign key '%s' of table '%s'", tableName, fk.Name, tblFK.Table)
+			}
+
+			continue
+		}
+
+		for _, tag := range tags {
+			if tblFK.Table == tableName && fk.HasTag(tag) {
+				break
+			}
+
+			for _, refTag := range fk.ReferencedTags {
+				if refTag == tag {
+					return fmt.Errorf("column of table '%s' is referenced by foreign key '%s' of table '%s'", tableName, fk.Name, tblFK.Table)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// CheckForeignKeys returns a ForeignKeyViolationError for the first foreign key violation found by
+// ForeignKeyViolations, or nil if the change to the table violates no foreign keys.
+func (root *RootValue) CheckForeignKeys(ctx context.Context, tableName string, oldTbl *Table) error {
+	violations, err := root.ForeignKeyViolations(ctx, tableName, oldTbl)
+
+	if err != nil {
+		return err
+	}
+
+	if len(violations) > 0 {
+		return ForeignKeyViolationError{violations[0]}
+	}
+
+	return nil
+}
+
+// ForeignKeyViolations returns the foreign key violations caused by changing the named table from oldTbl to the
+// version of the table in this root.  Rows added or modified in the table are checked against the parent tables of
+// its foreign keys, and the rows it no longer holds are checked for rows of any table in the root that still reference
+// them.  If oldTbl is nil, or a foreign key was not defined on oldTbl, every row of the table is checked against that
+// foreign key.  If the table is not in this root, it is treated as having been emptied.
+func (root *RootValue) ForeignKeyViolations(ctx context.Context, tableName string, oldTbl *Table) ([]ForeignKeyViolation, error) {
+	newRows, err := types.NewMap(ctx, root.VRW())
+
+	if err != nil {
+		return nil, err
+	}
+
+	oldRows := newRows
+	var newSch, oldSch schema.Schema = schema.EmptySchema, schema.EmptySchema
+	tbl, ok, err := root.GetTable(ctx, tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		newSch, newRows, err = schemaAndRows(ctx, tbl)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if oldTbl != nil {
+		oldSch, oldRows, err = schemaAndRows(ctx, oldTbl)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	v := &fkValidator{tableName: tableName, seen: make(map[hash.Hash]bool)}
+	var checkAll []*childCheck
+	var checkChanged []*childCheck
+	for _, fk := range newSch.ForeignKeys().GetForeignKeys() {
+		check, err := newChildCheck(ctx, root, fk)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if oldFK, ok := oldSch.ForeignKeys().GetByName(fk.Name); ok && oldFK.Equals(fk) {
+			checkChanged = append(checkChanged, check)
+		} else {
+			checkAll = append(checkAll, check)
+		}
+	}
+
+	parentChecks, err := newParentChecks(ctx, root, tableName, tbl, ok)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(checkAll) > 0 {
+		err = newRows.Iter(ctx, func(key, val types.Value) (stop bool, err error) {
+			r, err := row.FromNoms(newSch, key.(types.Tuple), val.(types.Tuple))
+
+			if err != nil {
+				return true, err
+			}
+
+			for _, check := range checkAll {
+				err = v.checkChild(ctx, check, key.(types.Tuple), nil, r)
+
+				if err != nil {
+					return true, err
+				}
+			}
+
+			return false, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(checkChanged) > 0 || len(parentChecks) > 0 {
+		err = v.checkChanges(ctx, oldSch, oldRows, newSch, newRows, checkChanged, parentChecks)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return v.violations, nil
+}
+
+func schemaAndRows(ctx context.Context, tbl *Table) (schema.Schema, types.Map, error) {
+	sch, err := tbl.GetSchema(ctx)
+
+	if err != nil {
+		return nil, types.EmptyMap, err
+	}
+
+	rows, err := tbl.GetRowData(ctx)
+
+	if err != nil {
+		return nil, types.EmptyMap, err
+	}
+
+	return sch, rows, nil
+}
+
+// childCheck checks the rows of a child table against the parent table of one of its foreign keys.  parent is nil if
+// the parent table does not exist, in which case every constrained row violates the foreign key.
+type childCheck struct {
+	fk     schema.ForeignKey
+	parent *rowFinder
+}
+
+func newChildCheck(ctx context.Context, root *RootValue, fk schema.ForeignKey) (*childCheck, error) {
+	parentTbl, ok, err := root.GetTable(ctx, fk.ReferencedTable)
+
+	if err != nil || !ok {
+		return &childCheck{fk, nil}, err
+	}
+
+	parent, err := newRowFinder(ctx, parentTbl, fk.ReferencedTags)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &childCheck{fk, parent}, nil
+}
+
+// parentCheck finds the rows of a child table which referenced the values of rows removed from the parent table.
+// remaining finds the rows of the parent table which still provide the referenced values, and is nil if the parent
+// table no longer exists.
+type parentCheck struct {
+	childName string
+	fk        schema.ForeignKey
+	children  *rowFinder
+	remaining *rowFinder
+}
+
+func newParentChecks(ctx context.Context, root *RootValue, tableName string, tbl *Table, tblExists bool) ([]*parentCheck, error) {
+	referencing, err := root.ForeignKeysReferencing(ctx, tableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []*parentCheck
+	for _, tblFK := range referencing {
+		childTbl, _, err := root.GetTable(ctx, tblFK.Table)
+
+		if err != nil {
+			return nil, err
+		}
+
+		children, err := newRowFinder(ctx, childTbl, tblFK.ForeignKey.Tags)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var remaining *rowFinder
+		if tblExists {
+			remaining, err = newRowFinder(ctx, tbl, tblFK.ForeignKey.ReferencedTags)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		checks = append(checks, &parentCheck{tblFK.Table, tblFK.ForeignKey, children, remaining})
+	}
+
+	return checks, nil
+}
+
+// fkValidator accumulates the foreign key violations found while validating a change to a table, reporting each
+// violating row once per foreign key.
+type fkValidator struct {
+	tableName  string
+	violations []ForeignKeyViolation
+	seen       map[hash.Hash]bool
+}
+
+func (v *fkValidator) addViolation(childName string, fk schema.ForeignKey, key types.Tuple) error {
+	id, err := types.NewTuple(key.Format(), types.String(childName), types.String(fk.Name), key)
+
+	if err != nil {
+		return err
+	}
+
+	h, err := id.Hash(key.Format())
+
+	if err != nil {
+		return err
+	}
+
+	if !v.seen[h] {
+		v.seen[h] = true
+		v.violations = append(v.violations, ForeignKeyViolation{childName, fk, key})
+	}
+
+	return nil
+}
+
+func (v *fkValidator) checkChanges(ctx context.Context, oldSch schema.Schema, oldRows types.Map, newSch schema.Schema, newRows types.Map, childChecks []*childCheck, parentChecks []*parentCheck) error {
+	ae := atomicerr.New()
+	changeChan := make(chan types.ValueChanged, 32)
+	stopChan := make(chan struct{}, 1)
+
+	go func() {
+		newRows.Diff(ctx, oldRows, ae, changeChan, stopChan)
+		close(changeChan)
+	}()
+
+	defer func() {
+		close(stopChan)
+		for range changeChan {
+		}
+	}()
+
+	for change := range changeChan {
+		var oldRow, newRow row.Row
+		var err error
+		if change.OldValue != nil {
+			oldRow, err = row.FromNoms(oldSch, change.Key.(types.Tuple), change.OldValue.(types.Tuple))
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if change.NewValue != nil {
+			newRow, err = row.FromNoms(newSch, change.Key.(types.Tuple), change.NewValue.(types.Tuple))
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if newRow != nil {
+			for _, check := range childChecks {
+				err = v.checkChild(ctx, check, change.Key.(types.Tuple), oldRow, newRow)
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if oldRow != nil {
+			for _, check := range parentChecks {
+				err = v.checkParent(ctx, check, oldRow, newRow)
+
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return ae.Get()
+}
+
+// checkChild records a violation if the new version of a child row references values that don't exist in the parent
+// table.  Rows whose constrained values are unchanged from their old version are not checked again.
+func (v *fkValidator) checkChild(ctx context.Context, check *childCheck, key types.Tuple, oldRow, newRow row.Row) error {
+	vals, hasNull := rowValues(newRow, check.fk.Tags)
+
+	if hasNull {
+		return nil
+	}
+
+	if oldRow != nil {
+		oldVals, _ := rowValues(oldRow, check.fk.Tags)
+
+		if valuesEqual(vals, oldVals) {
+			return nil
+		}
+	}
+
+	if check.parent != nil {
+		found, err := check.parent.hasRow(ctx, vals)
+
+		if err != nil || found {
+			return err
+		}
+	}
+
+	return v.addViolation(v.tableName, check.fk, key)
+}
+
+// checkParent records a violation for every child row which referenced the values of the old version of a parent row,
+// unless the values are still provided by the new version of the row or by another row of the parent table.
+func (v *fkValidator) checkParent(ctx context.Context, check *parentCheck, oldRow, newRow row.Row) error {
+	vals, hasNull := rowValues(oldRow, check.fk.ReferencedTags)
+
+	if hasNull {
+		return nil
+	}
+
+	if newRow != nil {
+		newVals, _ := rowValues(newRow, check.fk.ReferencedTags)
+
+		if valuesEqual(vals, newVals) {
+			return nil
+		}
+	}
+
+	if check.remaining != nil {
+		found, err := check.remaining.hasRow(ctx, vals)
+
+		if err != nil || found {
+			return err
+		}
+	}
+
+	return check.children.find(ctx, vals, func(key types.Tuple) (stop bool, err error) {
+		return false, v.addViolation(check.childName, check.fk, key)
+	})
+}
+
+// rowValues returns the values of the columns with the given tags, and whether any of them is null.
+func rowValues(r row.Row, tags []uint64) ([]types.Value, bool) {
+	vals := make([]types.Value, len(tags))
+	hasNull := false
+	for i, tag := range tags {
+		val, ok := r.GetColVal(tag)
+
+		if !ok || types.IsNull(val) {
+			val = types.NullValue
+			hasNull = true
+		}
+
+		vals[i] = val
+	}
+
+	return vals, hasNull
+}
+
+// rowFinder finds the rows of a table which have the given values for a set of columns.  If the columns are the
+// primary key columns the row is looked up by key, if they are the leading columns of an index the index data is
+// scanned from the first matching entry, and otherwise every row of the table is scanned.
+type rowFinder struct {
+	sch  schema.Schema
+	rows types.Map
+	tags []uint64
+
+	// byPK is true if the primary key is used, in which case order maps primary key columns to positions in tags
+	byPK bool
+	// idx is the index used, if any, in which case order maps its leading columns to positions in tags
+	idx       *schema.Index
+	indexData types.Map
+	order     []int
+}
+
+func newRowFinder(ctx context.Context, tbl *Table, tags []uint64) (*rowFinder, error) {
+	sch, rows, err := schemaAndRows(ctx, tbl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rf := &rowFinder{sch: sch, rows: rows, tags: tags}
+
+	if order, ok := tagOrder(sch.GetPKCols().Tags, tags); ok {
+		rf.byPK = true
+		rf.order = order
+		return rf, nil
+	}
+
+	for _, idx := range sch.Indexes().GetIndexes() {
+		if len(idx.Tags) < len(tags) {
+			continue
+		}
+
+		if order, ok := tagOrder(idx.Tags[:len(tags)], tags); ok {
+			indexData, err := tbl.GetIndexRowData(ctx, idx.Name)
+
+			if err != nil {
+				return nil, err
+			}
+
+			idx := idx
+			rf.idx = &idx
+			rf.indexData = indexData
+			rf.order = order
+			return rf, nil
+		}
+	}
+
+	return rf, nil
+}
+
+// tagOrder returns the position in tags of each of the target tags, if the target tags hold exactly the same tags.
+func tagOrder(target, tags []uint64) ([]int, bool) {
+	if len(target) != len(tags) {
+		return nil, false
+	}
+
+	order := make([]int, len(target))
+	for i, targetTag := range target {
+		found := false
+		for j, tag := range tags {
+			if tag == targetTag {
+				order[i] = j
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, false
+		}
+	}
+
+	return order, true
+}
+
+func (rf *rowFinder) hasRow(ctx context.Context, vals []types.Value) (bool, error) {
+	found := false
+	err := rf.find(ctx, vals, func(key types.Tuple) (stop bool, err error) {
+		found = true
+		return true, nil
+	})
+
+	return found, err
+}
+
+// find calls cb with the primary key of every row whose values for the finder's columns equal vals, until cb returns
+// true or an error.
+func (rf *rowFinder) find(ctx context.Context, vals []types.Value, cb func(key types.Tuple) (stop bool, err error)) error {
+	nbf := rf.rows.Format()
+
+	switch {
+	case rf.byPK:
+		pkTags := rf.sch.GetPKCols().Tags
+		tplVals := make([]types.Value, 0, 2*len(pkTags))
+		for i, tag := range pkTags {
+			tplVals = append(tplVals, types.Uint(tag), vals[rf.order[i]])
+		}
+
+		key, err := types.NewTuple(nbf, tplVals...)
+
+		if err != nil {
+			return err
+		}
+
+		_, ok, err := rf.rows.MaybeGet(ctx, key)
+
+		if err != nil || !ok {
+			return err
+		}
+
+		_, err = cb(key)
+		return err
+
+	case rf.idx != nil:
+		n := len(rf.order)
+		idxVals := make([]types.Value, n)
+		for i := range idxVals {
+			idxVals[i] = vals[rf.order[i]]
+		}
+
+		prefix, err := IndexKeyPrefix(nbf, rf.idx.Tags[:n], idxVals)
+
+		if err != nil {
+			return err
+		}
+
+		itr, err := rf.indexData.IteratorFrom(ctx, prefix)
+
+		if err != nil {
+			return err
+		}
+
+		for {
+			k, _, err := itr.Next(ctx)
+
+			if err != nil || k == nil {
+				return err
+			}
+
+			keyVals, err := IndexKeyValues(*rf.idx, k.(types.Tuple))
+
+			if err != nil {
+				return err
+			}
+
+			if !valuesEqual(idxVals, keyVals[:n]) {
+				return nil
+			}
+
+			pk, err := IndexKeyToPK(nbf, *rf.idx, k.(types.Tuple))
+
+			if err != nil {
+				return err
+			}
+
+			if stop, err := cb(pk); err != nil || stop {
+				return err
+			}
+		}
+
+	default:
+		return rf.rows.Iter(ctx, func(key, val types.Value) (stop bool, err error) {
+			r, err := row.FromNoms(rf.sch, key.(types.Tuple), val.(types.Tuple))
+
+			if err != nil {
+				return true, err
+			}
+
+			rowVals, _ := rowValues(r, rf.tags)
+
+			if !valuesEqual(vals, rowVals) {
+				return false, nil
+			}
+
+			return cb(key.(types.Tuple))
+		})
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+const (
+	familyIdTag     = 100
+	familyNameTag   = 101
+	memberIdTag     = 200
+	memberFamilyTag = 201
+	memberNameTag   = 202
+)
+
+var familyFK = schema.ForeignKey{Name: "fk_family", Tags: []uint64{memberFamilyTag}, ReferencedTable: "families", ReferencedTags: []uint64{familyIdTag}}
+var familyNameFK = schema.ForeignKey{Name: "fk_family_name", Tags: []uint64{memberNameTag}, ReferencedTable: "families", ReferencedTags: []uint64{familyNameTag}}
+
+func familiesSchema(indexes ...schema.Index) schema.Schema {
+	colColl, _ := schema.NewColCollection(
+		schema.NewColumn("id", familyIdTag, types.IntKind, true, schema.NotNullConstraint{}),
+		schema.NewColumn("name", familyNameTag, types.StringKind, false),
+	)
+
+	idxColl, _ := schema.NewIndexCollection(indexes...)
+	sch, _ := schema.SchemaWithIndexes(schema.SchemaFromCols(colColl), idxColl)
+	return sch
+}
+
+func membersSchema(foreignKeys ...schema.ForeignKey) schema.Schema {
+	colColl, _ := schema.NewColCollection(
+		schema.NewColumn("id", memberIdTag, types.IntKind, true, schema.NotNullConstraint{}),
+		schema.NewColumn("family_id", memberFamilyTag, types.IntKind, false),
+		schema.NewColumn("name", memberNameTag, types.StringKind, false),
+	)
+
+	fkColl, _ := schema.NewForeignKeyCollection(foreignKeys...)
+	sch, _ := schema.SchemaWithForeignKeys(schema.SchemaFromCols(colColl), fkColl)
+	return sch
+}
+
+func family(id int64, name string) row.TaggedValues {
+	return row.TaggedValues{familyIdTag: types.Int(id), familyNameTag: types.String(name)}
+}
+
+func member(id int64, familyId types.Value, name string) row.TaggedValues {
+	return row.TaggedValues{memberIdTag: types.Int(id), memberFamilyTag: familyId, memberNameTag: types.String(name)}
+}
+
+func createTableWithRows(t *testing.T, vrw types.ValueReadWriter, sch schema.Schema, rows ...row.TaggedValues) *Table {
+	m, err := types.NewMap(context.Background(), vrw)
+	require.NoError(t, err)
+	tbl, err := createTestTable(vrw, sch, m)
+	require.NoError(t, err)
+
+	return setTableRows(t, tbl, sch, rows...)
+}
+
+// setTableRows replaces all rows of the table with the given rows
+func setTableRows(t *testing.T, tbl *Table, sch schema.Schema, rows ...row.TaggedValues) *Table {
+	empty, err := types.NewMap(context.Background(), tbl.vrw)
+	require.NoError(t, err)
+	me := empty.Edit()
+
+	for _, vals := range rows {
+		r, err := row.New(types.Format_7_18, sch, vals)
+		require.NoError(t, err)
+		me = me.Set(r.NomsMapKey(sch), r.NomsMapValue(sch))
+	}
+
+	m, err := me.Map(context.Background())
+	require.NoError(t, err)
+	tbl, err = tbl.UpdateRows(context.Background(), m)
+	require.NoError(t, err)
+
+	return tbl
+}
+
+func memberKeys(t *testing.T, violations []ForeignKeyViolation) []int64 {
+	var ids []int64
+	for _, v := range violations {
+		assert.Equal(t, "members", v.Table)
+		id, err := v.Key.Get(1)
+		require.NoError(t, err)
+		ids = append(ids, int64(id.(types.Int)))
+	}
+
+	return ids
+}
+
+func TestForeignKeyViolations(t *testing.T) {
+	ctx := context.Background()
+	ddb, _ := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	vrw := ddb.ValueReadWriter()
+	root, err := emptyRootValue(ctx, vrw)
+	require.NoError(t, err)
+
+	famSch := familiesSchema()
+	memSch := membersSchema(familyFK)
+	families := createTableWithRows(t, vrw, famSch, family(1, "simpson"), family(2, "flanders"))
+	members := createTableWithRows(t, vrw, memSch, member(10, types.Int(1), "homer"), member(11, types.Int(2), "ned"), member(12, types.NullValue, "moe"))
+
+	root, err = root.PutTable(ctx, "families", families)
+	require.NoError(t, err)
+	root, err = root.PutTable(ctx, "members", members)
+	require.NoError(t, err)
+
+	violations, err := root.ForeignKeyViolations(ctx, "members", nil)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	t.Run("child row references missing parent", func(t *testing.T) {
+		updated := setTableRows(t, members, memSch, member(10, types.Int(1), "homer"), member(13, types.Int(3), "apu"), member(14, types.Int(2), "maude"))
+		newRoot, err := root.PutTable(ctx, "members", updated)
+		require.NoError(t, err)
+
+		violations, err := newRoot.ForeignKeyViolations(ctx, "members", members)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{13}, memberKeys(t, violations))
+
+		err = newRoot.CheckForeignKeys(ctx, "members", members)
+		assert.True(t, IsForeignKeyViolation(err))
+	})
+
+	t.Run("parent row removed", func(t *testing.T) {
+		updated := setTableRows(t, families, famSch, family(2, "van houten"), family(3, "wiggum"))
+		newRoot, err := root.PutTable(ctx, "families", updated)
+		require.NoError(t, err)
+
+		violations, err := newRoot.ForeignKeyViolations(ctx, "families", families)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{10}, memberKeys(t, violations))
+	})
+
+	t.Run("parent table removed", func(t *testing.T) {
+		newRoot, err := root.RemoveTables(ctx, "families")
+		require.NoError(t, err)
+
+		violations, err := newRoot.ForeignKeyViolations(ctx, "families", families)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{10, 11}, memberKeys(t, violations))
+
+		violations, err = newRoot.ForeignKeyViolations(ctx, "members", nil)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int64{10, 11}, memberKeys(t, violations))
+	})
+
+	t.Run("foreign key added", func(t *testing.T) {
+		// existing rows are checked against a foreign key that wasn't defined on the old table, using a scan of the
+		// parent without an index and an index lookup with one
+		for _, idxs := range [][]schema.Index{nil, {{Name: "idx_name", Tags: []uint64{familyNameTag}}}} {
+			withIdx := createTableWithRows(t, vrw, familiesSchema(idxs...), family(1, "simpson"), family(2, "flanders"))
+			newRoot, err := root.PutTable(ctx, "families", withIdx)
+			require.NoError(t, err)
+
+			newSch := membersSchema(familyFK, familyNameFK)
+			updated := setTableRows(t, members, newSch, member(10, types.Int(1), "simpson"), member(11, types.Int(2), "ned"))
+			updated, err = updated.UpdateSchema(ctx, newSch)
+			require.NoError(t, err)
+			newRoot, err = newRoot.PutTable(ctx, "members", updated)
+			require.NoError(t, err)
+
+			violations, err := newRoot.ForeignKeyViolations(ctx, "members", members)
+			require.NoError(t, err)
+			require.Equal(t, []int64{11}, memberKeys(t, violations))
+			assert.Equal(t, familyNameFK, violations[0].ForeignKey)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+	"github.com/liquidata-inc/dolt/go/store/datas"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+)
+
+// HeadRefLogName is the name of the reflog which records every update to the commit that HEAD points at, including
+// changes of the checked out branch.
+const HeadRefLogName = "HEAD"
+
+// RefLog records the updates made to refs so that they can be undone.  A DoltDB with a RefLog set calls
+// RecordRefUpdate every time it creates, moves or deletes a ref, and uses LookupRefLogEntry to resolve commit specs in
+// the format <ref>@{n}.
+type RefLog interface {
+	// RecordRefUpdate is called after the ref given is moved from oldHash to newHash.  oldHash is empty if the ref was
+	// created, and newHash is empty if the ref was deleted.
+	RecordRefUpdate(ctx context.Context, dref ref.DoltRef, oldHash, newHash hash.Hash) error
+
+	// LookupRefLogEntry returns the hash that the reflog with the name given pointed at n updates ago, where 0 is the
+	// most recent update.  The name is either HeadRefLogName or a fully qualified ref string.
+	LookupRefLogEntry(ctx context.Context, name string, n int) (hash.Hash, error)
+}
+
+var refLogSpecRegex = regexp.MustCompile(`^(.+)@\{(\d+)\}$`)
+
+// refLogSpec is the CommitStringer of a RefLogCommitSpec
+type refLogSpec struct {
+	name string
+	n    int
+}
+
+func (rls refLogSpec) String() string {
+	return fmt.Sprintf("%s@{%d}", rls.name, rls.n)
+}
+
+// parseRefLogSpec parses strings in the format <name>@{n}, returning false if the string given is not in that format.
+// HEAD is returned as HeadRefLogName, and branch names are converted to fully qualified refs.
+func parseRefLogSpec(str string) (refLogSpec, bool) {
+	matches := refLogSpecRegex.FindStringSubmatch(str)
+
+	if matches == nil {
+		return refLogSpec{}, false
+	}
+
+	n, err := strconv.Atoi(matches[2])
+
+	if err != nil {
+		return refLogSpec{}, false
+	}
+
+	name := matches[1]
+	if strings.ToLower(name) == head {
+		name = HeadRefLogName
+	} else if !ref.IsRef(name) {
+		if !IsValidUserBranchName(name) {
+			return refLogSpec{}, false
+		}
+
+		name = ref.NewBranchRef(name).String()
+	}
+
+	return refLogSpec{name, n}, true
+}
+
+// SetRefLog sets the RefLog which updates to refs are recorded in.  By default updates are not recorded.
+func (ddb *DoltDB) SetRefLog(refLog RefLog) {
+	ddb.refLog = refLog
+}
+
+func (ddb *DoltDB) resolveRefLogSpec(ctx context.Context, rls refLogSpec) (hash.Hash, error) {
+	if ddb.refLog == nil {
+		return hash.Hash{}, ErrRefLogEntryNotFound
+	}
+
+	h, err := ddb.refLog.LookupRefLogEntry(ctx, rls.name, rls.n)
+
+	if err != nil {
+		return hash.Hash{}, err
+	}
+
+	if h.IsEmpty() {
+		// the ref was deleted by this update
+		return hash.Hash{}, ErrRefLogEntryNotFound
+	}
+
+	return h, nil
+}
+
+// logRefUpdate records the update of a ref in the reflog, if one is set.  oldDs is the dataset for the ref before the
+// update, and newHash is the hash of the value the ref now points at, or the empty hash if it was deleted.
+func (ddb *DoltDB) logRefUpdate(ctx context.Context, dref ref.DoltRef, oldDs datas.Dataset, newHash hash.Hash) error {
+	if ddb.refLog == nil {
+		return nil
+	}
+
+	var oldHash hash.Hash
+	headRef, hasHead, err := oldDs.MaybeHeadRef()
+
+	if err != nil {
+		return err
+	} else if hasHead {
+		oldHash = headRef.TargetHash()
+	}
+
+	if oldHash == newHash {
+		return nil
+	}
+
+	return ddb.refLog.RecordRefUpdate(ctx, dref, oldHash, newHash)
+}
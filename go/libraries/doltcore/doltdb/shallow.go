@@ -0,0 +1,261 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/store/datas"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+)
+
+// SetShallowCommits sets the shallow commits of the database.  These are the commits at the boundary of the history
+// pulled by a shallow clone or fetch, whose parents are not in the database, so history is never walked past them.  By
+// default a database has no shallow commits.
+func (ddb *DoltDB) SetShallowCommits(hashes hash.HashSet) {
+	ddb.shallow = hashes
+	ddb.db.SetShallowCommits(hashes)
+}
+
+// ShallowCommits returns the hashes of the shallow commits of the database.  The set returned should not be modified.
+func (ddb *DoltDB) ShallowCommits() hash.HashSet {
+	return ddb.shallow
+}
+
+// IsShallow returns whether the database is missing part of its history because it was pulled with a limited depth.
+func (ddb *DoltDB) IsShallow() bool {
+	return len(ddb.shallow) > 0
+}
+
+// IsShallowCommit returns whether the commit given is a shallow commit, whose parents are not in the database.
+func (ddb *DoltDB) IsShallowCommit(cm *Commit) (bool, error) {
+	if len(ddb.shallow) == 0 {
+		return false, nil
+	}
+
+	h, err := cm.HashOf()
+
+	if err != nil {
+		return false, err
+	}
+
+	return ddb.shallow.Has(h), nil
+}
+
+// PullChunksToDepth pulls the chunks needed by the commits in the history of cm which are within depth commits of it
+// from srcDB, where a depth of 1 pulls cm alone.  The parents of the commits at the boundary of that history are not
+// pulled, and unless the database already has them the boundary commits become shallow commits.  Shallow commits within
+// the depth have their missing parents pulled, and are no longer shallow.
+func (ddb *DoltDB) PullChunksToDepth(ctx context.Context, tempDir string, srcDB *DoltDB, cm *Commit, depth int, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	if depth < 1 {
+		return ErrInvalidDepth
+	}
+
+	commits, err := commitsToDepth(ctx, srcDB, cm, depth)
+
+	if err != nil {
+		return err
+	}
+
+	skip := hash.NewHashSet()
+	parentHashes := make(map[hash.Hash][]hash.Hash, len(commits))
+	for h, commit := range commits {
+		parents, err := commit.ParentHashes(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		parentHashes[h] = parents
+		for _, parent := range parents {
+			if _, ok := commits[parent]; !ok {
+				skip.Insert(parent)
+			}
+		}
+	}
+
+	missing := hash.NewHashSet()
+	for h := range skip {
+		has, err := ddb.hasValue(ctx, h)
+
+		if err != nil {
+			return err
+		} else if !has {
+			missing.Insert(h)
+		}
+	}
+
+	cmHash, err := cm.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	roots := []hash.Hash{cmHash}
+	shallow := hash.NewHashSet()
+	for h := range ddb.shallow {
+		if _, ok := commits[h]; !ok {
+			shallow.Insert(h)
+		}
+	}
+
+	for h, parents := range parentHashes {
+		for _, parent := range parents {
+			if missing.Has(parent) {
+				shallow.Insert(h)
+			} else if ddb.shallow.Has(h) && !skip.Has(parent) {
+				// the parent was missing and is now within the depth
+				roots = append(roots, parent)
+			}
+		}
+	}
+
+	for _, h := range roots {
+		err = ddb.pullShallowChunksForSt(ctx, tempDir, srcDB, commits[h].commitSt, skip, progChan, pullerEventCh)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// the shallow commits only change once all of the history they bound has been pulled
+	ddb.SetShallowCommits(shallow)
+
+	return nil
+}
+
+// Unshallow pulls all of the history which is missing from a shallow database from srcDB, which is the full history of
+// the parents of each shallow commit.  Afterwards the database has no shallow commits.
+func (ddb *DoltDB) Unshallow(ctx context.Context, tempDir string, srcDB *DoltDB, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	for h := range ddb.shallow {
+		commitSt, err := getCommitStForHash(ctx, ddb.db, h.String())
+
+		if err != nil {
+			return err
+		}
+
+		parents, err := (&Commit{ddb.db, commitSt}).ParentHashes(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, parent := range parents {
+			parentSt, err := getCommitStForHash(ctx, srcDB.db, parent.String())
+
+			if err != nil {
+				return err
+			}
+
+			err = ddb.pullChunksForSt(ctx, tempDir, srcDB, parentSt, progChan, pullerEventCh)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	ddb.SetShallowCommits(nil)
+
+	return nil
+}
+
+// commitsToDepth returns the commits in the history of cm in srcDB which are within depth commits of it, keyed by hash.
+func commitsToDepth(ctx context.Context, srcDB *DoltDB, cm *Commit, depth int) (map[hash.Hash]*Commit, error) {
+	h, err := cm.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	commits := map[hash.Hash]*Commit{h: cm}
+	level := []*Commit{cm}
+	for i := 1; i < depth && len(level) > 0; i++ {
+		var nextLevel []*Commit
+		for _, commit := range level {
+			if isShallow, err := srcDB.IsShallowCommit(commit); err != nil {
+				return nil, err
+			} else if isShallow {
+				continue
+			}
+
+			parents, err := commit.ParentHashes(ctx)
+
+			if err != nil {
+				return nil, err
+			}
+
+			for _, parent := range parents {
+				if _, ok := commits[parent]; ok {
+					continue
+				}
+
+				parentSt, err := getCommitStForHash(ctx, srcDB.db, parent.String())
+
+				if err != nil {
+					return nil, err
+				}
+
+				parentCm := &Commit{srcDB.db, parentSt}
+				commits[parent] = parentCm
+				nextLevel = append(nextLevel, parentCm)
+			}
+		}
+
+		level = nextLevel
+	}
+
+	return commits, nil
+}
+
+// checkHasShallowParents returns ErrShallowPush if srcDB is shallow and this database is missing the parents of any
+// of its shallow commits, as pushing to it would leave it with commits whose history is missing.
+func (ddb *DoltDB) checkHasShallowParents(ctx context.Context, srcDB *DoltDB) error {
+	for h := range srcDB.shallow {
+		commitSt, err := getCommitStForHash(ctx, srcDB.db, h.String())
+
+		if err != nil {
+			return err
+		}
+
+		parents, err := (&Commit{srcDB.db, commitSt}).ParentHashes(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, parent := range parents {
+			has, err := ddb.hasValue(ctx, parent)
+
+			if err != nil {
+				return err
+			} else if !has {
+				return ErrShallowPush
+			}
+		}
+	}
+
+	return nil
+}
+
+func (ddb *DoltDB) hasValue(ctx context.Context, h hash.Hash) (bool, error) {
+	val, err := ddb.db.ReadValue(ctx, h)
+
+	if err != nil {
+		return false, err
+	}
+
+	return val != nil, nil
+}
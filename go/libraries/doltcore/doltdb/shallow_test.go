@@ -0,0 +1,239 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// createShallowTestSrc creates an in memory database whose master branch has numCommits commits after the initial one,
+// each of which adds a table.  The commits are returned from newest to oldest.
+func createShallowTestSrc(t *testing.T, numCommits int) (*DoltDB, []*Commit) {
+	ctx := context.Background()
+	ddb, err := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+	err = ddb.WriteEmptyRepo(ctx, "Bill Billerson", "bigbillieb@fake.horse")
+	require.NoError(t, err)
+
+	cs, _ := NewCommitSpec("HEAD", "master")
+	cm, err := ddb.Resolve(ctx, cs)
+	require.NoError(t, err)
+
+	commits := []*Commit{cm}
+	for i := 1; i <= numCommits; i++ {
+		root, err := cm.GetRootValue()
+		require.NoError(t, err)
+
+		sch := createTestSchema()
+		rowData, _ := createTestRowData(t, ddb.db, sch)
+		tbl, err := createTestTable(ddb.db, sch, rowData)
+		require.NoError(t, err)
+
+		root, err = root.PutTable(ctx, "test"+strconv.Itoa(i), tbl)
+		require.NoError(t, err)
+		valHash, err := ddb.WriteRootValue(ctx, root)
+		require.NoError(t, err)
+
+		meta, err := NewCommitMeta("Bill Billerson", "bigbillieb@fake.horse", "commit "+strconv.Itoa(i))
+		require.NoError(t, err)
+		cm, err = ddb.Commit(ctx, valHash, ref.NewBranchRef("master"), meta)
+		require.NoError(t, err)
+
+		commits = append([]*Commit{cm}, commits...)
+	}
+
+	return ddb, commits
+}
+
+func mustHashOf(t *testing.T, cm *Commit) hash.Hash {
+	h, err := cm.HashOf()
+	require.NoError(t, err)
+	return h
+}
+
+func assertHasCommits(t *testing.T, ddb *DoltDB, commits []*Commit, expected bool) {
+	for _, cm := range commits {
+		has, err := ddb.hasValue(context.Background(), mustHashOf(t, cm))
+		require.NoError(t, err)
+		assert.Equal(t, expected, has)
+	}
+}
+
+func TestShallowPull(t *testing.T) {
+	ctx := context.Background()
+	srcDB, commits := createShallowTestSrc(t, 5)
+	masterRef := ref.NewBranchRef("master")
+
+	ddb, err := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+
+	err = ddb.PullChunksToDepth(ctx, "", srcDB, commits[0], 0, nil, nil)
+	assert.Equal(t, ErrInvalidDepth, err)
+
+	// pull the two newest commits
+	err = ddb.PullChunksToDepth(ctx, "", srcDB, commits[0], 2, nil, nil)
+	require.NoError(t, err)
+	err = ddb.SetHeadToCommit(ctx, masterRef, commits[0])
+	require.NoError(t, err)
+
+	assertHasCommits(t, ddb, commits[:2], true)
+	assertHasCommits(t, ddb, commits[2:], false)
+	assert.True(t, ddb.IsShallow())
+	assert.Equal(t, hash.NewHashSet(mustHashOf(t, commits[1])), ddb.ShallowCommits())
+
+	cs, _ := NewCommitSpec("HEAD~1", "master")
+	cm, err := ddb.Resolve(ctx, cs)
+	require.NoError(t, err)
+	assert.Equal(t, mustHashOf(t, commits[1]), mustHashOf(t, cm))
+
+	cs, _ = NewCommitSpec("HEAD~2", "master")
+	_, err = ddb.Resolve(ctx, cs)
+	assert.Equal(t, ErrBeyondShallowBoundary, err)
+
+	_, err = ddb.ResolveParent(ctx, cm, 0)
+	assert.Equal(t, ErrBeyondShallowBoundary, err)
+
+	err = ddb.GC(ctx)
+	assert.Equal(t, ErrShallowGC, err)
+
+	// pushing to a database without the history beyond the boundary fails
+	emptyDB, err := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+	err = emptyDB.PushChunks(ctx, "", ddb, commits[0], nil, nil)
+	assert.Equal(t, ErrShallowPush, err)
+
+	// pushing to a database which has it succeeds
+	err = srcDB.PushChunks(ctx, "", ddb, commits[0], nil, nil)
+	assert.NoError(t, err)
+
+	// deepen the history to four commits
+	err = ddb.PullChunksToDepth(ctx, "", srcDB, commits[0], 4, nil, nil)
+	require.NoError(t, err)
+
+	assertHasCommits(t, ddb, commits[:4], true)
+	assertHasCommits(t, ddb, commits[4:], false)
+	assert.Equal(t, hash.NewHashSet(mustHashOf(t, commits[3])), ddb.ShallowCommits())
+
+	cs, _ = NewCommitSpec("HEAD~3", "master")
+	cm, err = ddb.Resolve(ctx, cs)
+	require.NoError(t, err)
+	meta, err := cm.GetCommitMeta()
+	require.NoError(t, err)
+	assert.Equal(t, "commit 2", meta.Description)
+
+	// fetch everything else
+	err = ddb.Unshallow(ctx, "", srcDB, nil, nil)
+	require.NoError(t, err)
+
+	assertHasCommits(t, ddb, commits, true)
+	assert.False(t, ddb.IsShallow())
+
+	cs, _ = NewCommitSpec("HEAD~5", "master")
+	cm, err = ddb.Resolve(ctx, cs)
+	require.NoError(t, err)
+	assert.Equal(t, mustHashOf(t, commits[5]), mustHashOf(t, cm))
+}
+
+func TestShallowPullFromShallowSource(t *testing.T) {
+	ctx := context.Background()
+	srcDB, commits := createShallowTestSrc(t, 5)
+
+	shallowDB, err := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+	err = shallowDB.PullChunksToDepth(ctx, "", srcDB, commits[0], 3, nil, nil)
+	require.NoError(t, err)
+
+	// the history of a shallow database is never walked past its shallow commits, whatever the depth
+	ddb, err := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+	err = ddb.PullChunksToDepth(ctx, "", shallowDB, commits[0], 10, nil, nil)
+	require.NoError(t, err)
+
+	assertHasCommits(t, ddb, commits[:3], true)
+	assertHasCommits(t, ddb, commits[3:], false)
+	assert.Equal(t, hash.NewHashSet(mustHashOf(t, commits[2])), ddb.ShallowCommits())
+}
+
+func TestShallowSetHead(t *testing.T) {
+	ctx := context.Background()
+	srcDB, commits := createShallowTestSrc(t, 2)
+	masterRef := ref.NewBranchRef("master")
+
+	// a shallow commit can be made a head even though its parents are missing
+	ddb, err := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+	err = ddb.PullChunksToDepth(ctx, "", srcDB, commits[0], 1, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, hash.NewHashSet(mustHashOf(t, commits[0])), ddb.ShallowCommits())
+	err = ddb.SetHeadToCommit(ctx, masterRef, commits[0])
+	require.NoError(t, err)
+
+	// other commits are still required to have all of their parents
+	ddb, err = LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+	err = ddb.PullChunksToDepth(ctx, "", srcDB, commits[0], 1, nil, nil)
+	require.NoError(t, err)
+	ddb.SetShallowCommits(nil)
+	assert.Panics(t, func() {
+		_ = ddb.SetHeadToCommit(ctx, masterRef, commits[0])
+	})
+}
+
+func TestShallowPullFailure(t *testing.T) {
+	ctx := context.Background()
+	srcDB, commits := createShallowTestSrc(t, 2)
+
+	// add a commit which removes the tables, so the previous commit's tables are only reachable through it
+	root, err := commits[0].GetRootValue()
+	require.NoError(t, err)
+	tblHash, ok, err := root.GetTableHash(ctx, "test1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	root, err = root.RemoveTables(ctx, "test1", "test2")
+	require.NoError(t, err)
+	valHash, err := srcDB.WriteRootValue(ctx, root)
+	require.NoError(t, err)
+	meta, err := NewCommitMeta("Bill Billerson", "bigbillieb@fake.horse", "removed tables")
+	require.NoError(t, err)
+	cm, err := srcDB.Commit(ctx, valHash, ref.NewBranchRef("master"), meta)
+	require.NoError(t, err)
+	commits = append([]*Commit{cm}, commits...)
+
+	ddb, err := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+	err = ddb.PullChunksToDepth(ctx, "", srcDB, commits[0], 1, nil, nil)
+	require.NoError(t, err)
+
+	// a source which has the second commit, but not its tables
+	brokenDB, err := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+	skip := hash.NewHashSet(tblHash, mustHashOf(t, commits[2]))
+	err = brokenDB.pullShallowChunksForSt(ctx, "", srcDB, commits[0].commitSt, skip, nil, nil)
+	require.NoError(t, err)
+
+	// the shallow commits are unchanged when deepening the history fails
+	err = ddb.PullChunksToDepth(ctx, "", brokenDB, commits[0], 2, nil, nil)
+	require.Error(t, err)
+	assert.Equal(t, hash.NewHashSet(mustHashOf(t, commits[0])), ddb.ShallowCommits())
+}
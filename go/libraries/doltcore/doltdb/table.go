@@ -29,10 +29,13 @@ import (
 const (
 	tableStructName = "table"
 
-	schemaRefKey       = "schema_ref"
-	tableRowsKey       = "rows"
-	conflictsKey       = "conflicts"
-	conflictSchemasKey = "conflict_schemas"
+	schemaRefKey         = "schema_ref"
+	tableRowsKey         = "rows"
+	conflictsKey         = "conflicts"
+	conflictSchemasKey   = "conflict_schemas"
+	schemaConflictsKey   = "schema_conflicts"
+	conflictMergeRowsKey = "conflict_merge_rows"
+	indexesKey           = "indexes"
 
 	// TableNameRegexStr is the regular expression that valid tables must match.
 	TableNameRegexStr = `^[a-zA-Z]{1}$|^[a-zA-Z]+[-_0-9a-zA-Z]*[0-9a-zA-Z]+$`
@@ -191,19 +194,103 @@ func (t *Table) NumRowsInConflict(ctx context.Context) (uint64, error) {
 }
 
 func (t *Table) ClearConflicts() (*Table, error) {
-	tSt, err := t.tableStruct.Delete(conflictSchemasKey)
+	tSt := t.tableStruct
+	for _, key := range []string{conflictSchemasKey, conflictsKey, schemaConflictsKey, conflictMergeRowsKey} {
+		var err error
+		tSt, err = tSt.Delete(key)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Table{t.vrw, tSt}, nil
+}
+
+// SetSchemaConflicts marks the table as having schema conflicts.  schemaConflicts is a map from column tag to a
+// description of the conflict, and mergeRowData is the row data of the other side of the merge, which is needed in
+// order to resolve the conflicts in favor of that side.  The table is left without any row level conflicts, as rows
+// can't be merged until the schema conflicts are resolved.
+func (t *Table) SetSchemaConflicts(ctx context.Context, schemas Conflict, schemaConflicts, mergeRowData types.Map) (*Table, error) {
+	emptyMap, err := types.NewMap(ctx, t.vrw)
 
 	if err != nil {
 		return nil, err
 	}
 
-	tSt, err = tSt.Delete(conflictsKey)
+	tbl, err := t.SetConflicts(ctx, schemas, emptyMap)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &Table{t.vrw, tSt}, nil
+	schConflictsRef, err := writeValAndGetRef(ctx, t.vrw, schemaConflicts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mergeRowsRef, err := writeValAndGetRef(ctx, t.vrw, mergeRowData)
+
+	if err != nil {
+		return nil, err
+	}
+
+	updatedSt, err := tbl.tableStruct.Set(schemaConflictsKey, schConflictsRef)
+
+	if err != nil {
+		return nil, err
+	}
+
+	updatedSt, err = updatedSt.Set(conflictMergeRowsKey, mergeRowsRef)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{t.vrw, updatedSt}, nil
+}
+
+// HasSchemaConflicts returns true if a merge was unable to reconcile the schemas of this table.
+func (t *Table) HasSchemaConflicts() (bool, error) {
+	if t == nil {
+		return false, nil
+	}
+
+	_, ok, err := t.tableStruct.MaybeGet(schemaConflictsKey)
+
+	return ok, err
+}
+
+// GetSchemaConflicts returns a map from column tag to a description of the schema conflict for that column.  If the
+// table has no schema conflicts an empty map is returned.
+func (t *Table) GetSchemaConflicts(ctx context.Context) (types.Map, error) {
+	return t.getMapRef(ctx, schemaConflictsKey)
+}
+
+// GetSchemaConflictMergeRows returns the row data from the other side of a merge that resulted in schema conflicts.
+func (t *Table) GetSchemaConflictMergeRows(ctx context.Context) (types.Map, error) {
+	return t.getMapRef(ctx, conflictMergeRowsKey)
+}
+
+func (t *Table) getMapRef(ctx context.Context, key string) (types.Map, error) {
+	val, ok, err := t.tableStruct.MaybeGet(key)
+
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	if !ok {
+		return types.NewMap(ctx, t.vrw)
+	}
+
+	v, err := val.(types.Ref).TargetValue(ctx, t.vrw)
+
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	return v.(types.Map), nil
 }
 
 func (t *Table) GetConflictSchemas(ctx context.Context) (base, sch, mergeSch schema.Schema, err error) {
@@ -264,6 +351,30 @@ func (t *Table) GetSchema(ctx context.Context) (schema.Schema, error) {
 	return refToSchema(ctx, t.vrw, schemaRef)
 }
 
+// UpdateSchema replaces the schema of the table and returns the updated Table.  The rows of the table are not modified,
+// so the new schema must be able to read them.
+func (t *Table) UpdateSchema(ctx context.Context, sch schema.Schema) (*Table, error) {
+	schemaVal, err := encoding.MarshalAsNomsValue(ctx, t.vrw, sch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	schemaRef, err := writeValAndGetRef(ctx, t.vrw, schemaVal)
+
+	if err != nil {
+		return nil, err
+	}
+
+	updatedSt, err := t.tableStruct.Set(schemaRefKey, schemaRef)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{t.vrw, updatedSt}, nil
+}
+
 func (t *Table) GetSchemaRef() (types.Ref, error) {
 	v, _, err := t.tableStruct.MaybeGet(schemaRefKey)
 
@@ -389,9 +500,17 @@ func (t *Table) GetRows(ctx context.Context, pkItr PKItr, numPKs int, sch schema
 	return rows, missing, nil
 }
 
-// UpdateRows replaces the current row data and returns and updated Table.  Calls to UpdateRows will not be written to the
-// database.  The root must be updated with the updated table, and the root must be committed or written.
+// UpdateRows replaces the current row data and returns and updated Table.  The data of the table's indexes is updated
+// to match the new rows, and a UniqueIndexViolationError is returned if the new rows violate a unique index.  Calls to
+// UpdateRows will not be written to the database.  The root must be updated with the updated table, and the root must
+// be committed or written.
 func (t *Table) UpdateRows(ctx context.Context, updatedRows types.Map) (*Table, error) {
+	oldRows, err := t.GetRowData(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
 	rowDataRef, err := writeValAndGetRef(ctx, t.vrw, updatedRows)
 
 	if err != nil {
@@ -404,7 +523,7 @@ func (t *Table) UpdateRows(ctx context.Context, updatedRows types.Map) (*Table,
 		return nil, err
 	}
 
-	return &Table{t.vrw, updatedSt}, nil
+	return (&Table{t.vrw, updatedSt}).updateIndexes(ctx, oldRows, updatedRows)
 }
 
 // GetRowData retrieves the underlying map which is a map from a primary key to a list of field values.
@@ -0,0 +1,134 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+const autoIncrementKey = "auto_increment"
+
+// GetAutoIncrementValue returns the table's auto increment counter, which is the lowest value that may be generated
+// for the table's auto increment column.  Returns 0 if the counter has never been set.
+func (t *Table) GetAutoIncrementValue() (uint64, error) {
+	val, ok, err := t.tableStruct.MaybeGet(autoIncrementKey)
+
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	return uint64(val.(types.Uint)), nil
+}
+
+// SetAutoIncrementValue sets the table's auto increment counter.
+func (t *Table) SetAutoIncrementValue(val uint64) (*Table, error) {
+	updatedSt, err := t.tableStruct.Set(autoIncrementKey, types.Uint(val))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Table{t.vrw, updatedSt}, nil
+}
+
+// CopyAutoIncrementValue sets the table's auto increment counter to the larger of its own counter and the counter of
+// another table.  It's used when a table is recreated from another, and when the two sides of a merge are combined, so
+// that values generated on either side are never generated again.
+func (t *Table) CopyAutoIncrementValue(from *Table) (*Table, error) {
+	val, err := t.GetAutoIncrementValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	fromVal, err := from.GetAutoIncrementValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if fromVal <= val {
+		return t, nil
+	}
+
+	return t.SetAutoIncrementValue(fromVal)
+}
+
+// NextAutoIncrementValue returns the value to generate for the next row inserted into the table without a value for
+// its auto increment column.  It's the larger of the table's counter and one more than the largest value in the
+// column, so that rows written with explicit values, or by operations that don't maintain the counter, are never
+// collided with.  Returns 0 if the schema has no auto increment column.
+func (t *Table) NextAutoIncrementValue(ctx context.Context, sch schema.Schema) (uint64, error) {
+	col, ok := schema.AutoIncrementCol(sch)
+
+	if !ok {
+		return 0, nil
+	}
+
+	next, err := t.GetAutoIncrementValue()
+
+	if err != nil {
+		return 0, err
+	}
+
+	if next == 0 {
+		next = 1
+	}
+
+	rowData, err := t.GetRowData(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if rowData.Len() == 0 {
+		return next, nil
+	}
+
+	// the auto increment column is the only primary key column, so the last row holds its largest value
+	key, val, err := rowData.Last(ctx)
+
+	if err != nil {
+		return 0, err
+	}
+
+	r, err := row.FromNoms(sch, key.(types.Tuple), val.(types.Tuple))
+
+	if err != nil {
+		return 0, err
+	}
+
+	colVal, _ := r.GetColVal(col.Tag)
+
+	var max uint64
+	switch colVal := colVal.(type) {
+	case types.Int:
+		if colVal > 0 {
+			max = uint64(colVal)
+		}
+	case types.Uint:
+		max = uint64(colVal)
+	}
+
+	if max >= next {
+		next = max + 1
+	}
+
+	return next, nil
+}
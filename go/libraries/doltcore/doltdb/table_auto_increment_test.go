@@ -0,0 +1,99 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+func TestNextAutoIncrementValue(t *testing.T) {
+	ctx := context.Background()
+	db, err := dbfactory.MemFactory{}.CreateDB(ctx, types.Format_7_18, nil, nil)
+	require.NoError(t, err)
+
+	idCol := schema.NewColumn("id", 0, types.IntKind, true, schema.NotNullConstraint{})
+	idCol.AutoIncrement = true
+	colColl, err := schema.NewColCollection(idCol, schema.NewColumn("name", 1, types.StringKind, false))
+	require.NoError(t, err)
+	sch := schema.SchemaFromCols(colColl)
+
+	m, err := types.NewMap(ctx, db)
+	require.NoError(t, err)
+	tbl, err := createTestTable(db, sch, m)
+	require.NoError(t, err)
+
+	next, err := tbl.NextAutoIncrementValue(ctx, sch)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), next)
+
+	ed := m.Edit()
+	for _, id := range []int64{-5, 3, 7} {
+		r, err := row.New(types.Format_7_18, sch, row.TaggedValues{0: types.Int(id)})
+		require.NoError(t, err)
+		ed = ed.Set(r.NomsMapKey(sch), r.NomsMapValue(sch))
+	}
+	m, err = ed.Map(ctx)
+	require.NoError(t, err)
+	tbl, err = tbl.UpdateRows(ctx, m)
+	require.NoError(t, err)
+
+	next, err = tbl.NextAutoIncrementValue(ctx, sch)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8), next)
+
+	// the counter isn't lowered by deleting the rows with the largest values
+	tbl, err = tbl.SetAutoIncrementValue(8)
+	require.NoError(t, err)
+	empty, err := types.NewMap(ctx, db)
+	require.NoError(t, err)
+	tbl, err = tbl.UpdateRows(ctx, empty)
+	require.NoError(t, err)
+
+	next, err = tbl.NextAutoIncrementValue(ctx, sch)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8), next)
+
+	// the larger counter is kept when copying
+	other, err := createTestTable(db, sch, empty)
+	require.NoError(t, err)
+	other, err = other.SetAutoIncrementValue(20)
+	require.NoError(t, err)
+
+	copied, err := tbl.CopyAutoIncrementValue(other)
+	require.NoError(t, err)
+	val, err := copied.GetAutoIncrementValue()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), val)
+
+	copied, err = other.CopyAutoIncrementValue(tbl)
+	require.NoError(t, err)
+	val, err = copied.GetAutoIncrementValue()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), val)
+
+	// schemas without an auto increment column have no next value
+	next, err = tbl.NextAutoIncrementValue(ctx, createTestSchema())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), next)
+}
@@ -0,0 +1,646 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/atomicerr"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// UniqueIndexViolationError is returned when a change to a table would leave two different rows with the same values
+// for the columns of a unique index.
+type UniqueIndexViolationError struct {
+	IndexName string
+}
+
+func (e UniqueIndexViolationError) Error() string {
+	return fmt.Sprintf("duplicate entry for unique index '%s'", e.IndexName)
+}
+
+// IsUniqueIndexViolation returns true if the error is a UniqueIndexViolationError
+func IsUniqueIndexViolation(err error) bool {
+	_, ok := err.(UniqueIndexViolationError)
+	return ok
+}
+
+// IndexKeyForRow returns the key of the entry for a row in the data of an index.  Index keys are tuples containing the
+// tagged values of the indexed columns followed by the tagged values of the primary key columns, so index data is
+// ordered by the indexed values and every entry refers to exactly one row.  Null values are encoded as
+// types.NullValue.
+func IndexKeyForRow(nbf *types.NomsBinFormat, sch schema.Schema, idx schema.Index, r row.Row) (types.Tuple, error) {
+	pkTags := sch.GetPKCols().Tags
+	vals := make([]types.Value, 0, 2*(len(idx.Tags)+len(pkTags)))
+	for _, tags := range [][]uint64{idx.Tags, pkTags} {
+		for _, tag := range tags {
+			val, ok := r.GetColVal(tag)
+
+			if !ok || val == nil {
+				val = types.NullValue
+			}
+
+			vals = append(vals, types.Uint(tag), val)
+		}
+	}
+
+	return types.NewTuple(nbf, vals...)
+}
+
+// IndexKeyPrefix returns a tuple of the tagged values given, which sorts before every index key that starts with the
+// same tagged values.  It is used to position an iterator over index data.
+func IndexKeyPrefix(nbf *types.NomsBinFormat, tags []uint64, vals []types.Value) (types.Tuple, error) {
+	tplVals := make([]types.Value, 0, 2*len(tags))
+	for i, tag := range tags {
+		tplVals = append(tplVals, types.Uint(tag), vals[i])
+	}
+
+	return types.NewTuple(nbf, tplVals...)
+}
+
+// IndexKeyToPK returns the primary key tuple of the row referenced by an index key of the given index.
+func IndexKeyToPK(nbf *types.NomsBinFormat, idx schema.Index, key types.Tuple) (types.Tuple, error) {
+	return tupleFields(nbf, key, uint64(2*len(idx.Tags)), key.Len())
+}
+
+// IndexKeyValues returns the values of the indexed columns of an index key of the given index, in index order.
+func IndexKeyValues(idx schema.Index, key types.Tuple) ([]types.Value, error) {
+	vals := make([]types.Value, 0, len(idx.Tags))
+	err := key.IterFields(func(i uint64, v types.Value) (stop bool, err error) {
+		if i >= uint64(2*len(idx.Tags)) {
+			return true, nil
+		}
+
+		if i%2 == 1 {
+			vals = append(vals, v)
+		}
+
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return vals, nil
+}
+
+func tupleFields(nbf *types.NomsBinFormat, tpl types.Tuple, start, end uint64) (types.Tuple, error) {
+	vals := make([]types.Value, 0, end-start)
+	err := tpl.IterFields(func(i uint64, v types.Value) (stop bool, err error) {
+		if i >= end {
+			return true, nil
+		}
+
+		if i >= start {
+			vals = append(vals, v)
+		}
+
+		return false, nil
+	})
+
+	if err != nil {
+		return types.EmptyTuple(nbf), err
+	}
+
+	return types.NewTuple(nbf, vals...)
+}
+
+// getIndexRefs returns the map from index name to a ref of the index data, if the table holds data for any index.
+func (t *Table) getIndexRefs(ctx context.Context) (types.Map, bool, error) {
+	val, ok, err := t.tableStruct.MaybeGet(indexesKey)
+
+	if err != nil || !ok {
+		return types.EmptyMap, false, err
+	}
+
+	val, err = val.(types.Ref).TargetValue(ctx, t.vrw)
+
+	if err != nil {
+		return types.EmptyMap, false, err
+	}
+
+	return val.(types.Map), true, nil
+}
+
+func (t *Table) setIndexRefs(ctx context.Context, refs types.Map) (*Table, error) {
+	var updatedSt types.Struct
+	if refs.Len() == 0 {
+		var err error
+		updatedSt, err = t.tableStruct.Delete(indexesKey)
+
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		refsRef, err := writeValAndGetRef(ctx, t.vrw, refs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		updatedSt, err = t.tableStruct.Set(indexesKey, refsRef)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Table{t.vrw, updatedSt}, nil
+}
+
+// HasIndexRowData returns true if the table holds data for the index with the given name.
+func (t *Table) HasIndexRowData(ctx context.Context, indexName string) (bool, error) {
+	refs, ok, err := t.getIndexRefs(ctx)
+
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return refs.Has(ctx, types.String(indexName))
+}
+
+// GetIndexRowData retrieves the data of the index with the given name.  Index data is a map from index keys, as
+// returned by IndexKeyForRow, to empty tuples.  schema.ErrIndexNotFound is returned if the table holds no data for
+// the index.
+func (t *Table) GetIndexRowData(ctx context.Context, indexName string) (types.Map, error) {
+	refs, ok, err := t.getIndexRefs(ctx)
+
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	if !ok {
+		return types.EmptyMap, schema.ErrIndexNotFound
+	}
+
+	val, ok, err := refs.MaybeGet(ctx, types.String(indexName))
+
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	if !ok {
+		return types.EmptyMap, schema.ErrIndexNotFound
+	}
+
+	val, err = val.(types.Ref).TargetValue(ctx, t.vrw)
+
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	return val.(types.Map), nil
+}
+
+// SetIndexRowData replaces the data of the index with the given name and returns the updated Table.
+func (t *Table) SetIndexRowData(ctx context.Context, indexName string, indexData types.Map) (*Table, error) {
+	dataRef, err := writeValAndGetRef(ctx, t.vrw, indexData)
+
+	if err != nil {
+		return nil, err
+	}
+
+	refs, ok, err := t.getIndexRefs(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		refs, err = types.NewMap(ctx, t.vrw)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	refs, err = refs.Edit().Set(types.String(indexName), dataRef).Map(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t.setIndexRefs(ctx, refs)
+}
+
+// DeleteIndexRowData removes the data of the index with the given name and returns the updated Table.  It is not an
+// error to delete the data of an index the table has no data for.
+func (t *Table) DeleteIndexRowData(ctx context.Context, indexName string) (*Table, error) {
+	refs, ok, err := t.getIndexRefs(ctx)
+
+	if err != nil || !ok {
+		return t, err
+	}
+
+	refs, err = refs.Edit().Remove(types.String(indexName)).Map(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t.setIndexRefs(ctx, refs)
+}
+
+// RebuildIndexRowData builds the data of the given index from the rows of the table and returns the updated Table.
+// A UniqueIndexViolationError is returned if the index is unique and the existing rows violate it.
+func (t *Table) RebuildIndexRowData(ctx context.Context, idx schema.Index) (*Table, error) {
+	sch, err := t.GetSchema(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rowData, err := t.GetRowData(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	indexData, err := types.NewMap(ctx, t.vrw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ed := indexData.Edit()
+	emptyTpl := types.EmptyTuple(t.Format())
+	err = rowData.Iter(ctx, func(k, v types.Value) (stop bool, err error) {
+		r, err := row.FromNoms(sch, k.(types.Tuple), v.(types.Tuple))
+
+		if err != nil {
+			return true, err
+		}
+
+		idxKey, err := IndexKeyForRow(t.Format(), sch, idx, r)
+
+		if err != nil {
+			return true, err
+		}
+
+		ed.Set(idxKey, emptyTpl)
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	indexData, err = ed.Map(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if idx.Unique {
+		err = checkUniqueIndexData(ctx, idx, indexData)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t.SetIndexRowData(ctx, idx.Name, indexData)
+}
+
+// RebuildIndexes builds the data of every index defined by the table's schema from the rows of the table, and removes
+// the data of any index that is no longer defined.
+func (t *Table) RebuildIndexes(ctx context.Context) (*Table, error) {
+	sch, err := t.GetSchema(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tbl, err := t.removeUndefinedIndexRowData(ctx, sch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, idx := range sch.Indexes().GetIndexes() {
+		tbl, err = tbl.RebuildIndexRowData(ctx, idx)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tbl, nil
+}
+
+// CopyIndexes copies the data of the indexes defined by the table's schema from another table that has the same rows,
+// building the data of any index the other table holds no data for.  It is used when a table is recreated with a
+// modified schema that leaves the indexed values untouched.
+func (t *Table) CopyIndexes(ctx context.Context, from *Table) (*Table, error) {
+	sch, err := t.GetSchema(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tbl, err := t.removeUndefinedIndexRowData(ctx, sch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, idx := range sch.Indexes().GetIndexes() {
+		indexData, err := from.GetIndexRowData(ctx, idx.Name)
+
+		if err == schema.ErrIndexNotFound {
+			tbl, err = tbl.RebuildIndexRowData(ctx, idx)
+		} else if err == nil {
+			tbl, err = tbl.SetIndexRowData(ctx, idx.Name, indexData)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tbl, nil
+}
+
+func (t *Table) removeUndefinedIndexRowData(ctx context.Context, sch schema.Schema) (*Table, error) {
+	refs, ok, err := t.getIndexRefs(ctx)
+
+	if err != nil || !ok {
+		return t, err
+	}
+
+	var undefined []string
+	err = refs.IterAll(ctx, func(k, _ types.Value) error {
+		if _, ok := sch.Indexes().GetByName(string(k.(types.String))); !ok {
+			undefined = append(undefined, string(k.(types.String)))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	tbl := t
+	for _, name := range undefined {
+		tbl, err = tbl.DeleteIndexRowData(ctx, name)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tbl, nil
+}
+
+// updateIndexes applies the changes between oldRowData and newRowData to the data of every index defined by the
+// table's schema.  Indexes the table holds no data for are built from newRowData.
+func (t *Table) updateIndexes(ctx context.Context, oldRowData, newRowData types.Map) (*Table, error) {
+	sch, err := t.GetSchema(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if sch.Indexes().Size() == 0 {
+		return t.removeUndefinedIndexRowData(ctx, sch)
+	}
+
+	var editors []*types.MapEditor
+	var indexes []schema.Index
+	var missing []schema.Index
+	for _, idx := range sch.Indexes().GetIndexes() {
+		indexData, err := t.GetIndexRowData(ctx, idx.Name)
+
+		if err == schema.ErrIndexNotFound {
+			missing = append(missing, idx)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		indexes = append(indexes, idx)
+		editors = append(editors, indexData.Edit())
+	}
+
+	addedKeys := make([][]types.Tuple, len(indexes))
+	if len(indexes) > 0 {
+		ae := atomicerr.New()
+		changeChan := make(chan types.ValueChanged, 32)
+		stopChan := make(chan struct{}, 1)
+
+		go func() {
+			newRowData.Diff(ctx, oldRowData, ae, changeChan, stopChan)
+			close(changeChan)
+		}()
+
+		defer func() {
+			close(stopChan)
+			for range changeChan {
+			}
+		}()
+
+		emptyTpl := types.EmptyTuple(t.Format())
+		for change := range changeChan {
+			var oldRow, newRow row.Row
+			if change.OldValue != nil {
+				oldRow, err = row.FromNoms(sch, change.Key.(types.Tuple), change.OldValue.(types.Tuple))
+
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if change.NewValue != nil {
+				newRow, err = row.FromNoms(sch, change.Key.(types.Tuple), change.NewValue.(types.Tuple))
+
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			for i, idx := range indexes {
+				var oldKey, newKey types.Tuple
+				if oldRow != nil {
+					oldKey, err = IndexKeyForRow(t.Format(), sch, idx, oldRow)
+
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				if newRow != nil {
+					newKey, err = IndexKeyForRow(t.Format(), sch, idx, newRow)
+
+					if err != nil {
+						return nil, err
+					}
+				}
+
+				if oldRow != nil && newRow != nil && oldKey.Equals(newKey) {
+					continue
+				}
+
+				if oldRow != nil {
+					editors[i].Remove(oldKey)
+				}
+
+				if newRow != nil {
+					editors[i].Set(newKey, emptyTpl)
+
+					if idx.Unique {
+						addedKeys[i] = append(addedKeys[i], newKey)
+					}
+				}
+			}
+		}
+
+		if err := ae.Get(); err != nil {
+			return nil, err
+		}
+	}
+
+	tbl := t
+	for i, idx := range indexes {
+		indexData, err := editors[i].Map(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range addedKeys[i] {
+			err = checkUniqueIndexKey(ctx, idx, indexData, key)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		tbl, err = tbl.SetIndexRowData(ctx, idx.Name, indexData)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, idx := range missing {
+		tbl, err = tbl.RebuildIndexRowData(ctx, idx)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return tbl.removeUndefinedIndexRowData(ctx, sch)
+}
+
+// checkUniqueIndexKey returns a UniqueIndexViolationError if the index data holds an entry for a different row with
+// the same indexed values as key.  Keys with null indexed values never violate a unique index.
+func checkUniqueIndexKey(ctx context.Context, idx schema.Index, indexData types.Map, key types.Tuple) error {
+	vals, err := IndexKeyValues(idx, key)
+
+	if err != nil {
+		return err
+	}
+
+	for _, val := range vals {
+		if types.IsNull(val) {
+			return nil
+		}
+	}
+
+	prefix, err := IndexKeyPrefix(key.Format(), idx.Tags, vals)
+
+	if err != nil {
+		return err
+	}
+
+	itr, err := indexData.IteratorFrom(ctx, prefix)
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		k, _, err := itr.Next(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		if k == nil {
+			return nil
+		}
+
+		otherVals, err := IndexKeyValues(idx, k.(types.Tuple))
+
+		if err != nil {
+			return err
+		}
+
+		if !valuesEqual(vals, otherVals) {
+			return nil
+		}
+
+		if !k.Equals(key) {
+			return UniqueIndexViolationError{idx.Name}
+		}
+	}
+}
+
+// checkUniqueIndexData returns a UniqueIndexViolationError if any two entries of the index data have the same non-null
+// indexed values.
+func checkUniqueIndexData(ctx context.Context, idx schema.Index, indexData types.Map) error {
+	var prev []types.Value
+	return indexData.IterAll(ctx, func(k, _ types.Value) error {
+		vals, err := IndexKeyValues(idx, k.(types.Tuple))
+
+		if err != nil {
+			return err
+		}
+
+		if prev != nil && valuesEqual(prev, vals) {
+			return UniqueIndexViolationError{idx.Name}
+		}
+
+		prev = vals
+		for _, val := range vals {
+			if types.IsNull(val) {
+				prev = nil
+				break
+			}
+		}
+
+		return nil
+	})
+}
+
+func valuesEqual(vals, otherVals []types.Value) bool {
+	if len(vals) != len(otherVals) {
+		return false
+	}
+
+	for i := range vals {
+		if !vals[i].Equals(otherVals[i]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+var ageIdx = schema.Index{Name: "idx_age", Tags: []uint64{ageTag}}
+var lastIdx = schema.Index{Name: "idx_last", Tags: []uint64{lastTag}, Unique: true}
+
+func createIndexedTestTable(t *testing.T) (*Table, schema.Schema, []row.Row) {
+	db, err := dbfactory.MemFactory{}.CreateDB(context.Background(), types.Format_7_18, nil, nil)
+	require.NoError(t, err)
+
+	idxColl, err := schema.NewIndexCollection(ageIdx, lastIdx)
+	require.NoError(t, err)
+	sch, err := schema.SchemaWithIndexes(createTestSchema(), idxColl)
+	require.NoError(t, err)
+
+	rowData, rows := createTestRowData(t, db, sch)
+	tbl, err := createTestTable(db, sch, rowData)
+	require.NoError(t, err)
+
+	tbl, err = tbl.RebuildIndexes(context.Background())
+	require.NoError(t, err)
+
+	return tbl, sch, rows
+}
+
+func pkOf(t *testing.T, r row.Row, sch schema.Schema) types.Tuple {
+	pk, err := r.NomsMapKey(sch).Value(context.Background())
+	require.NoError(t, err)
+
+	return pk.(types.Tuple)
+}
+
+// indexedRows returns the primary keys of the rows referenced by the index data, in index order
+func indexedRows(t *testing.T, tbl *Table, idx schema.Index) []types.Value {
+	indexData, err := tbl.GetIndexRowData(context.Background(), idx.Name)
+	require.NoError(t, err)
+
+	var pks []types.Value
+	err = indexData.IterAll(context.Background(), func(k, _ types.Value) error {
+		pk, err := IndexKeyToPK(types.Format_7_18, idx, k.(types.Tuple))
+
+		if err != nil {
+			return err
+		}
+
+		pks = append(pks, pk)
+		return nil
+	})
+	require.NoError(t, err)
+
+	return pks
+}
+
+func TestRebuildIndexes(t *testing.T) {
+	tbl, sch, rows := createIndexedTestTable(t)
+
+	// ordered by age, then by primary key
+	expected := []types.Value{pkOf(t, rows[1], sch), pkOf(t, rows[3], sch)}
+	if less, err := pkOf(t, rows[0], sch).Less(types.Format_7_18, pkOf(t, rows[2], sch)); err == nil && less {
+		expected = append(expected, pkOf(t, rows[0], sch), pkOf(t, rows[2], sch))
+	} else {
+		expected = append(expected, pkOf(t, rows[2], sch), pkOf(t, rows[0], sch))
+	}
+
+	assert.Equal(t, expected, indexedRows(t, tbl, ageIdx))
+
+	assert.Len(t, indexedRows(t, tbl, lastIdx), 4)
+
+	tbl, err := tbl.DeleteIndexRowData(context.Background(), ageIdx.Name)
+	require.NoError(t, err)
+	_, err = tbl.GetIndexRowData(context.Background(), ageIdx.Name)
+	assert.Equal(t, schema.ErrIndexNotFound, err)
+}
+
+func TestUpdateRowsMaintainsIndexes(t *testing.T) {
+	ctx := context.Background()
+	tbl, sch, rows := createIndexedTestTable(t)
+	rowData, err := tbl.GetRowData(ctx)
+	require.NoError(t, err)
+
+	updated, err := rows[0].SetColVal(ageTag, types.Uint(18), sch)
+	require.NoError(t, err)
+	newRowData, err := rowData.Edit().
+		Set(updated.NomsMapKey(sch), updated.NomsMapValue(sch)).
+		Remove(rows[3].NomsMapKey(sch)).
+		Map(ctx)
+	require.NoError(t, err)
+
+	tbl, err = tbl.UpdateRows(ctx, newRowData)
+	require.NoError(t, err)
+
+	rebuilt, err := tbl.RebuildIndexes(ctx)
+	require.NoError(t, err)
+
+	for _, idx := range []schema.Index{ageIdx, lastIdx} {
+		incremental, err := tbl.GetIndexRowData(ctx, idx.Name)
+		require.NoError(t, err)
+		expected, err := rebuilt.GetIndexRowData(ctx, idx.Name)
+		require.NoError(t, err)
+		assert.True(t, expected.Equals(incremental), "index %s differs from a rebuilt index", idx.Name)
+	}
+
+	assert.Equal(t, pkOf(t, updated, sch), indexedRows(t, tbl, ageIdx)[0])
+	assert.Len(t, indexedRows(t, tbl, lastIdx), 3)
+}
+
+func TestUniqueIndexViolation(t *testing.T) {
+	ctx := context.Background()
+	tbl, sch, rows := createIndexedTestTable(t)
+	rowData, err := tbl.GetRowData(ctx)
+	require.NoError(t, err)
+
+	dupe, err := rows[0].SetColVal(lastTag, types.String("ericson"), sch)
+	require.NoError(t, err)
+	newRowData, err := rowData.Edit().Set(dupe.NomsMapKey(sch), dupe.NomsMapValue(sch)).Map(ctx)
+	require.NoError(t, err)
+
+	_, err = tbl.UpdateRows(ctx, newRowData)
+	assert.True(t, IsUniqueIndexViolation(err))
+
+	// swapping the values of a unique column between two rows is not a violation
+	swapped, err := rows[1].SetColVal(lastTag, types.String("billerson"), sch)
+	require.NoError(t, err)
+	newRowData, err = newRowData.Edit().Set(swapped.NomsMapKey(sch), swapped.NomsMapValue(sch)).Map(ctx)
+	require.NoError(t, err)
+
+	_, err = tbl.UpdateRows(ctx, newRowData)
+	assert.NoError(t, err)
+
+	// two of the test rows have the same age
+	_, err = tbl.RebuildIndexRowData(ctx, schema.Index{Name: "idx_age_unique", Tags: []uint64{ageTag}, Unique: true})
+	assert.True(t, IsUniqueIndexViolation(err))
+}
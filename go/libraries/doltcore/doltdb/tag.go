@@ -0,0 +1,122 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/liquidata-inc/dolt/go/store/hash"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+var errTagHasNoCommit = errors.New("tag does not reference a commit")
+
+// Tag is a named reference to a commit.  A lightweight tag's dataset head is the tagged commit itself.  An annotated
+// tag's dataset head is a noms commit, without parents, whose value is a ref to the tagged commit and whose meta is the
+// TagMeta describing the tagger, date and message.
+type Tag struct {
+	Name   string
+	vrw    types.ValueReadWriter
+	tagSt  types.Struct
+	Commit *Commit
+	Meta   *TagMeta
+}
+
+// IsAnnotated returns whether the tag was created with a tagger, date and message.
+func (t *Tag) IsAnnotated() bool {
+	return t.Meta != nil
+}
+
+// HashOf returns the hash of the tag.  For lightweight tags this is the hash of the tagged commit.
+func (t *Tag) HashOf() (hash.Hash, error) {
+	return t.tagSt.Hash(t.vrw.Format())
+}
+
+// isAnnotatedTagSt returns whether the dataset head given is an annotated tag rather than a commit
+func isAnnotatedTagSt(headSt types.Struct) (bool, error) {
+	val, ok, err := headSt.MaybeGet(rootValueField)
+
+	if err != nil {
+		return false, err
+	} else if !ok {
+		return false, nil
+	}
+
+	_, isRef := val.(types.Ref)
+	return isRef, nil
+}
+
+// peelTagSt takes the head of a tag dataset and returns the commit struct that it refers to
+func peelTagSt(ctx context.Context, vrw types.ValueReadWriter, headSt types.Struct) (types.Struct, error) {
+	isAnnotated, err := isAnnotatedTagSt(headSt)
+
+	if err != nil {
+		return types.EmptyStruct(vrw.Format()), err
+	} else if !isAnnotated {
+		return headSt, nil
+	}
+
+	val, _, err := headSt.MaybeGet(rootValueField)
+
+	if err != nil {
+		return types.EmptyStruct(vrw.Format()), err
+	}
+
+	targetVal, err := val.(types.Ref).TargetValue(ctx, vrw)
+
+	if err != nil {
+		return types.EmptyStruct(vrw.Format()), err
+	}
+
+	commitSt, ok := targetVal.(types.Struct)
+
+	if !ok || commitSt.Name() != CommitStructName {
+		return types.EmptyStruct(vrw.Format()), errTagHasNoCommit
+	}
+
+	return commitSt, nil
+}
+
+func newTag(ctx context.Context, vrw types.ValueReadWriter, name string, headSt types.Struct) (*Tag, error) {
+	commitSt, err := peelTagSt(ctx, vrw, headSt)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var meta *TagMeta
+	if !commitSt.Equals(headSt) {
+		metaVal, found, err := headSt.MaybeGet(metaField)
+
+		if err != nil {
+			return nil, err
+		}
+
+		metaSt, ok := metaVal.(types.Struct)
+
+		if !found || !ok {
+			return nil, errCommitHasNoMeta
+		}
+
+		meta, err = tagMetaFromNomsSt(metaSt)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Tag{name, vrw, headSt, &Commit{vrw, commitSt}, meta}, nil
+}
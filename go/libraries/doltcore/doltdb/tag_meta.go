@@ -0,0 +1,121 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+const (
+	tagMetaStructName = "tagmeta"
+	tagMetaNameKey    = "tagger_name"
+	tagMetaEmailKey   = "tagger_email"
+)
+
+// TagMeta contains the metadata that is associated with an annotated tag.
+type TagMeta struct {
+	Name        string
+	Email       string
+	Timestamp   uint64
+	Description string
+}
+
+// NewTagMeta creates a TagMeta instance from a tagger name, email, and description and uses the current time for the
+// timestamp
+func NewTagMeta(name, email, desc string) (*TagMeta, error) {
+	n := strings.TrimSpace(name)
+	e := strings.TrimSpace(email)
+	d := strings.TrimSpace(desc)
+
+	if n == "" || e == "" || d == "" {
+		return nil, errors.New("Aborting tag due to empty tag message.")
+	}
+
+	ns := uint64(CommitNowFunc().UnixNano())
+	ms := ns / milliToNano
+
+	return &TagMeta{n, e, ms, d}, nil
+}
+
+func tagMetaFromNomsSt(st types.Struct) (*TagMeta, error) {
+	if st.Name() != tagMetaStructName {
+		return nil, errors.New("not a tag metadata struct")
+	}
+
+	e, err := getRequiredFromSt(st, tagMetaEmailKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	n, err := getRequiredFromSt(st, tagMetaNameKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := getRequiredFromSt(st, commitMetaDescKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := getRequiredFromSt(st, commitMetaTimestampKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &TagMeta{
+		string(n.(types.String)),
+		string(e.(types.String)),
+		uint64(ts.(types.Uint)),
+		string(d.(types.String)),
+	}, nil
+}
+
+func (tm *TagMeta) toNomsStruct(nbf *types.NomsBinFormat) (types.Struct, error) {
+	metadata := types.StructData{
+		tagMetaNameKey:         types.String(tm.Name),
+		tagMetaEmailKey:        types.String(tm.Email),
+		commitMetaDescKey:      types.String(tm.Description),
+		commitMetaTimestampKey: types.Uint(tm.Timestamp),
+		commitMetaVersionKey:   types.String(metaVersion),
+	}
+
+	return types.NewStruct(nbf, tagMetaStructName, metadata)
+}
+
+// Time returns the time at which the tag was created
+func (tm *TagMeta) Time() time.Time {
+	seconds := tm.Timestamp / secToMilli
+	nanos := (tm.Timestamp % secToMilli) * milliToNano
+	return time.Unix(int64(seconds), int64(nanos))
+}
+
+// FormatTS takes the internal timestamp and turns it into a human readable string in the time.RubyDate format
+func (tm *TagMeta) FormatTS() string {
+	return tm.Time().In(CommitLoc).Format(time.RubyDate)
+}
+
+// String returns the human readable string representation of the tag data
+func (tm *TagMeta) String() string {
+	return fmt.Sprintf("tagger: %s, email: %s, timestamp: %s, description: %s", tm.Name, tm.Email, tm.FormatTS(), tm.Description)
+}
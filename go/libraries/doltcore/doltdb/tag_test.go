@@ -0,0 +1,104 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doltdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+func TestTags(t *testing.T) {
+	ctx := context.Background()
+	ddb, err := LoadDoltDB(ctx, types.Format_7_18, InMemDoltDB)
+	require.NoError(t, err)
+
+	err = ddb.WriteEmptyRepo(ctx, "Bill Billerson", "bigbillieb@fake.horse")
+	require.NoError(t, err)
+
+	cs, _ := NewCommitSpec("HEAD", "master")
+	commit, err := ddb.Resolve(ctx, cs)
+	require.NoError(t, err)
+
+	commitHash, err := commit.HashOf()
+	require.NoError(t, err)
+
+	meta, err := NewTagMeta("Bill Billerson", "bigbillieb@fake.horse", "first release")
+	require.NoError(t, err)
+
+	lightweight := ref.NewTagRef("light")
+	annotated := ref.NewTagRef("v1")
+	require.NoError(t, ddb.NewTagAtCommit(ctx, lightweight, commit, nil))
+	require.NoError(t, ddb.NewTagAtCommit(ctx, annotated, commit, meta))
+
+	tags, err := ddb.GetTags(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tags, 2)
+
+	branches, err := ddb.GetBranches(ctx)
+	require.NoError(t, err)
+	assert.Len(t, branches, 1)
+
+	for _, specStr := range []string{"light", "v1", "refs/tags/light", "refs/tags/v1"} {
+		cs, err := NewCommitSpec(specStr, "master")
+		require.NoError(t, err)
+
+		cm, err := ddb.Resolve(ctx, cs)
+		require.NoError(t, err, specStr)
+
+		h, err := cm.HashOf()
+		require.NoError(t, err)
+		assert.Equal(t, commitHash, h, specStr)
+	}
+
+	tag, err := ddb.ResolveTag(ctx, lightweight)
+	require.NoError(t, err)
+	assert.False(t, tag.IsAnnotated())
+
+	tag, err = ddb.ResolveTag(ctx, annotated)
+	require.NoError(t, err)
+	assert.True(t, tag.IsAnnotated())
+	assert.Equal(t, "first release", tag.Meta.Description)
+	assert.Equal(t, "Bill Billerson", tag.Meta.Name)
+
+	h, err := tag.Commit.HashOf()
+	require.NoError(t, err)
+	assert.Equal(t, commitHash, h)
+
+	// an existing tag can be replaced without deleting it first
+	require.NoError(t, ddb.NewTagAtCommit(ctx, lightweight, commit, meta))
+	tag, err = ddb.ResolveTag(ctx, lightweight)
+	require.NoError(t, err)
+	assert.True(t, tag.IsAnnotated())
+
+	tags, err = ddb.GetTags(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tags, 2)
+
+	require.NoError(t, ddb.DeleteTag(ctx, annotated))
+	assert.Equal(t, ErrTagNotFound, ddb.DeleteTag(ctx, annotated))
+
+	_, err = ddb.ResolveTag(ctx, annotated)
+	assert.Equal(t, ErrTagNotFound, err)
+
+	cs, _ = NewCommitSpec("v1", "master")
+	_, err = ddb.Resolve(ctx, cs)
+	assert.Equal(t, ErrBranchNotFound, err)
+}
@@ -239,13 +239,43 @@ func CheckoutBranch(ctx context.Context, dEnv *env.DoltEnv, brName string) error
 		return err
 	}
 
+	oldHeadCm, err := dEnv.DoltDB.Resolve(ctx, dEnv.RepoState.CWBHeadSpec())
+
+	if err != nil {
+		return RootValueUnreadable{HeadRoot, err}
+	}
+
 	dEnv.RepoState.Head = ref.MarshalableRef{Ref: dref}
 	dEnv.RepoState.Working = wrkHash.String()
 	dEnv.RepoState.Staged = stgHash.String()
 
 	err = dEnv.RepoState.Save()
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	if dEnv.RefLog != nil {
+		return recordHeadMove(dEnv, oldHeadCm, cm)
+	}
+
+	return nil
+}
+
+func recordHeadMove(dEnv *env.DoltEnv, oldHeadCm, newHeadCm *doltdb.Commit) error {
+	oldHash, err := oldHeadCm.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	newHash, err := newHeadCm.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	return dEnv.RefLog.RecordHeadUpdate(oldHash, newHash)
 }
 
 var emptyHash = hash.Hash{}
@@ -0,0 +1,73 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"errors"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/merge"
+)
+
+var ErrMergeCommitNotSupported = errors.New("commit is a merge commit which is not supported")
+var ErrNoParentCommit = errors.New("commit has no parent")
+
+// CherryPick applies the changes introduced by the commit given on top of root.  The commit's parent is used as the
+// base of a three-way merge between root and the commit's root value, so rows changed by the commit which were also
+// changed in root are recorded as conflicts on the resulting tables.
+func CherryPick(ctx context.Context, ddb *doltdb.DoltDB, root *doltdb.RootValue, cm *doltdb.Commit) (*doltdb.RootValue, map[string]*merge.MergeStats, error) {
+	cmRoot, parentRoot, err := getCommitAndParentRoots(ctx, ddb, cm)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return MergeRoots(ctx, ddb, root, cmRoot, parentRoot)
+}
+
+func getCommitAndParentRoots(ctx context.Context, ddb *doltdb.DoltDB, cm *doltdb.Commit) (*doltdb.RootValue, *doltdb.RootValue, error) {
+	numParents, err := cm.NumParents()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if numParents == 0 {
+		return nil, nil, ErrNoParentCommit
+	} else if numParents > 1 {
+		return nil, nil, ErrMergeCommitNotSupported
+	}
+
+	parent, err := ddb.ResolveParent(ctx, cm, 0)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cmRoot, err := cm.GetRootValue()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parentRoot, err := parent.GetRootValue()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cmRoot, parentRoot, nil
+}
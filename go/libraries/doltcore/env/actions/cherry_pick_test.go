@@ -0,0 +1,101 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+)
+
+const peopleTable = "people"
+
+// commitPeople replaces the people table in the working root with the rows given and commits it to the current branch.
+func commitPeople(t *testing.T, dEnv *env.DoltEnv, msg string, rows ...row.Row) *doltdb.Commit {
+	ctx := context.Background()
+	dtestutils.CreateTestTable(t, dEnv, peopleTable, dtestutils.TypedSchema, rows...)
+	require.NoError(t, StageAllTables(ctx, dEnv, false))
+	require.NoError(t, CommitStaged(ctx, dEnv, msg, false))
+
+	cs, err := doltdb.NewCommitSpec("HEAD", dEnv.RepoState.Head.Ref.String())
+	require.NoError(t, err)
+	cm, err := dEnv.DoltDB.Resolve(ctx, cs)
+	require.NoError(t, err)
+
+	return cm
+}
+
+func person(idx int, name string) row.Row {
+	title := dtestutils.Titles[idx]
+	return dtestutils.NewTypedRow(dtestutils.UUIDS[idx], name, uint(dtestutils.Ages[idx]), dtestutils.MaritalStatus[idx], &title)
+}
+
+func TestCherryPickConflict(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+
+	commitPeople(t, dEnv, "initial", dtestutils.TypedRows...)
+	require.NoError(t, CreateBranch(ctx, dEnv, "other", "master", false))
+	commitPeople(t, dEnv, "rename on master", person(0, "Master Billerson"), dtestutils.TypedRows[1], dtestutils.TypedRows[2])
+
+	require.NoError(t, CheckoutBranch(ctx, dEnv, "other"))
+	cm := commitPeople(t, dEnv, "rename on other", person(0, "Other Billerson"), person(1, "Other Johnson"), dtestutils.TypedRows[2])
+	require.NoError(t, CheckoutBranch(ctx, dEnv, "master"))
+
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	mergedRoot, tblToStats, err := CherryPick(ctx, dEnv.DoltDB, root, cm)
+	require.NoError(t, err)
+
+	require.Contains(t, tblToStats, peopleTable)
+	assert.Equal(t, 1, tblToStats[peopleTable].Conflicts)
+	assert.Equal(t, 1, tblToStats[peopleTable].Modifications)
+
+	hasConflicts, err := mergedRoot.HasConflicts(ctx)
+	require.NoError(t, err)
+	assert.True(t, hasConflicts)
+
+	tbl, ok, err := mergedRoot.GetTable(ctx, peopleTable)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, conflicts, err := tbl.GetConflicts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), conflicts.Len())
+}
+
+func TestCherryPickRootCommit(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+
+	cs, err := doltdb.NewCommitSpec("HEAD", "master")
+	require.NoError(t, err)
+	cm, err := dEnv.DoltDB.Resolve(ctx, cs)
+	require.NoError(t, err)
+
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	_, _, err = CherryPick(ctx, dEnv.DoltDB, root, cm)
+	assert.Equal(t, ErrNoParentCommit, err)
+}
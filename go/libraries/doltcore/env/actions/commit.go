@@ -163,6 +163,12 @@ func AddCommits(ctx context.Context, ddb *doltdb.DoltDB, commit *doltdb.Commit,
 
 	hashToCommit[hash] = commit
 
+	if isShallow, err := ddb.IsShallowCommit(commit); err != nil {
+		return err
+	} else if isShallow {
+		return nil
+	}
+
 	numParents, err := commit.NumParents()
 
 	if err != nil {
@@ -16,6 +16,7 @@ package commitwalk
 
 import (
 	"context"
+	"time"
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
 	"github.com/liquidata-inc/dolt/go/store/hash"
@@ -132,6 +133,18 @@ func (q *q) Get(ctx context.Context, id hash.Hash) (*c, error) {
 	return c, nil
 }
 
+// ParentHashes returns the hashes of the parents of a commit.  Shallow commits are treated as having no parents, as their
+// parents are not in the database.
+func (q *q) ParentHashes(ctx context.Context, c *c) ([]hash.Hash, error) {
+	if isShallow, err := q.ddb.IsShallowCommit(c.commit); err != nil {
+		return nil, err
+	} else if isShallow {
+		return nil, nil
+	}
+
+	return c.commit.ParentHashes(ctx)
+}
+
 func newQueue(ddb *doltdb.DoltDB) *q {
 	return &q{ddb: ddb, loaded: make(map[hash.Hash]*c)}
 }
@@ -144,9 +157,16 @@ func newQueue(ddb *doltdb.DoltDB) *q {
 // concurrent commits --- higher commits appear first. Remaining
 // ties are broken by timestamp; newer commits appear first.
 //
+// Passing a negative value for `num` will result in all such commits being returned.
+//
+// In a shallow repository the history of shallow commits ends at the shallow commit.
+//
 // Roughly mimics `git log master..feature`.
 func GetDotDotRevisions(ctx context.Context, ddb *doltdb.DoltDB, includedHead hash.Hash, excludedHead hash.Hash, num int) ([]*doltdb.Commit, error) {
-	commitList := make([]*doltdb.Commit, 0, num)
+	var commitList []*doltdb.Commit
+	if num > 0 {
+		commitList = make([]*doltdb.Commit, 0, num)
+	}
 	q := newQueue(ddb)
 	if err := q.SetInvisible(ctx, excludedHead); err != nil {
 		return nil, err
@@ -159,7 +179,7 @@ func GetDotDotRevisions(ctx context.Context, ddb *doltdb.DoltDB, includedHead ha
 	}
 	for q.NumVisiblePending() > 0 {
 		nextC := q.PopPending()
-		parents, err := nextC.commit.ParentHashes(ctx)
+		parents, err := q.ParentHashes(ctx, nextC)
 		if err != nil {
 			return nil, err
 		}
@@ -188,22 +208,60 @@ func GetDotDotRevisions(ctx context.Context, ddb *doltdb.DoltDB, includedHead ha
 // appear first. Remaining ties are broken by timestamp; newer commits appear first.
 func GetTopologicalOrderCommits(ctx context.Context, ddb *doltdb.DoltDB, startCommitHash hash.Hash) ([]*doltdb.Commit, error) {
 	var commitList []*doltdb.Commit
+	err := walkTopologicalOrder(ctx, ddb, startCommitHash, func(commit *doltdb.Commit) (bool, error) {
+		commitList = append(commitList, commit)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commitList, nil
+}
+
+// GetFirstCommitAtOrBefore walks back from the commit at hash `startCommitHash` in the same order as
+// GetTopologicalOrderCommits, and returns the first commit made at or before time `t`. The history
+// beyond that commit isn't loaded. Returns nil if no commit was made at or before `t`.
+func GetFirstCommitAtOrBefore(ctx context.Context, ddb *doltdb.DoltDB, startCommitHash hash.Hash, t time.Time) (*doltdb.Commit, error) {
+	var found *doltdb.Commit
+	err := walkTopologicalOrder(ctx, ddb, startCommitHash, func(commit *doltdb.Commit) (bool, error) {
+		meta, err := commit.GetCommitMeta()
+		if err != nil {
+			return false, err
+		}
+		if meta.Time().After(t) {
+			return false, nil
+		}
+		found = commit
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// walkTopologicalOrder calls `cb` with each commit reachable from the commit at hash `startCommitHash`,
+// in the order they're returned by GetTopologicalOrderCommits, until `cb` returns true. The parents
+// of a commit are only loaded once `cb` has been called with it.
+func walkTopologicalOrder(ctx context.Context, ddb *doltdb.DoltDB, startCommitHash hash.Hash, cb func(*doltdb.Commit) (stop bool, err error)) error {
 	q := newQueue(ddb)
 	if err := q.AddPendingIfUnseen(ctx, startCommitHash); err != nil {
-		return nil, err
+		return err
 	}
 	for q.NumVisiblePending() > 0 {
 		nextC := q.PopPending()
-		parents, err := nextC.commit.ParentHashes(ctx)
+		if stop, err := cb(nextC.commit); err != nil || stop {
+			return err
+		}
+		parents, err := q.ParentHashes(ctx, nextC)
 		if err != nil {
-			return nil, err
+			return err
 		}
 		for _, parentID := range parents {
 			if err := q.AddPendingIfUnseen(ctx, parentID); err != nil {
-				return nil, err
+				return err
 			}
 		}
-		commitList = append(commitList, nextC.commit)
 	}
-	return commitList, nil
+	return nil
 }
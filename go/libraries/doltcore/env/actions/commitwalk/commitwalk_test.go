@@ -17,6 +17,7 @@ package commitwalk
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -135,6 +136,92 @@ func TestGetDotDotRevisions(t *testing.T) {
 	assert.Equal(t, featureCommits[1], res[2])
 }
 
+func TestShallowWalks(t *testing.T) {
+	env := createUninitializedEnv()
+	err := env.InitRepo(context.Background(), types.Format_LD_1, "Bill Billerson", "bill@billerson.com")
+	require.NoError(t, err)
+
+	cs, err := doltdb.NewCommitSpec("HEAD", "master")
+	require.NoError(t, err)
+	commit, err := env.DoltDB.Resolve(context.Background(), cs)
+	require.NoError(t, err)
+
+	rv, err := commit.GetRootValue()
+	require.NoError(t, err)
+	rvh, err := env.DoltDB.WriteRootValue(context.Background(), rv)
+	require.NoError(t, err)
+
+	// Create 5 commits on master.
+	masterCommits := make([]*doltdb.Commit, 6)
+	masterCommits[0] = commit
+	for i := 1; i < 6; i++ {
+		masterCommits[i] = mustCreateCommit(t, env.DoltDB, "master", rvh, masterCommits[i-1])
+	}
+
+	// The history is not walked past a shallow commit, even though its parents are in the database.
+	env.DoltDB.SetShallowCommits(hash.NewHashSet(mustGetHash(t, masterCommits[3])))
+
+	headHash := mustGetHash(t, masterCommits[5])
+	res, err := GetTopologicalOrderCommits(context.Background(), env.DoltDB, headHash)
+	require.NoError(t, err)
+	assert.Equal(t, []*doltdb.Commit{masterCommits[5], masterCommits[4], masterCommits[3]}, res)
+
+	res, err = GetDotDotRevisions(context.Background(), env.DoltDB, headHash, mustGetHash(t, masterCommits[1]), 100)
+	require.NoError(t, err)
+	assert.Equal(t, []*doltdb.Commit{masterCommits[5], masterCommits[4], masterCommits[3]}, res)
+
+	res, err = GetDotDotRevisions(context.Background(), env.DoltDB, headHash, mustGetHash(t, masterCommits[4]), 100)
+	require.NoError(t, err)
+	assert.Equal(t, []*doltdb.Commit{masterCommits[5]}, res)
+}
+
+func TestGetFirstCommitAtOrBefore(t *testing.T) {
+	env := createUninitializedEnv()
+	err := env.InitRepo(context.Background(), types.Format_LD_1, "Bill Billerson", "bill@billerson.com")
+	require.NoError(t, err)
+
+	cs, err := doltdb.NewCommitSpec("HEAD", "master")
+	require.NoError(t, err)
+	commit, err := env.DoltDB.Resolve(context.Background(), cs)
+	require.NoError(t, err)
+
+	rv, err := commit.GetRootValue()
+	require.NoError(t, err)
+	rvh, err := env.DoltDB.WriteRootValue(context.Background(), rv)
+	require.NoError(t, err)
+
+	// Create 3 commits on master, a day apart.
+	start := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	masterCommits := make([]*doltdb.Commit, 4)
+	masterCommits[0] = commit
+	for i := 1; i < 4; i++ {
+		ts := start.AddDate(0, 0, i)
+		meta := &doltdb.CommitMeta{Name: "Bill Billerson", Email: "bill@billerson.com", Timestamp: uint64(ts.UnixNano() / int64(time.Millisecond)), Description: "A New Commit."}
+		pcs, err := doltdb.NewCommitSpec(mustGetHash(t, masterCommits[i-1]).String(), "master")
+		require.NoError(t, err)
+		masterCommits[i], err = env.DoltDB.CommitWithParents(context.Background(), rvh, ref.NewBranchRef("master"), []*doltdb.CommitSpec{pcs}, meta)
+		require.NoError(t, err)
+	}
+
+	headHash := mustGetHash(t, masterCommits[3])
+	res, err := GetFirstCommitAtOrBefore(context.Background(), env.DoltDB, headHash, start.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.Equal(t, masterCommits[2], res)
+
+	res, err = GetFirstCommitAtOrBefore(context.Background(), env.DoltDB, headHash, start.AddDate(0, 0, 2).Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, masterCommits[2], res)
+
+	res, err = GetFirstCommitAtOrBefore(context.Background(), env.DoltDB, headHash, start.AddDate(1, 0, 0))
+	require.NoError(t, err)
+	assert.Equal(t, masterCommits[3], res)
+
+	// The initial commit is made at the current time, so none was made at or before the start.
+	res, err = GetFirstCommitAtOrBefore(context.Background(), env.DoltDB, headHash, start)
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
+
 func mustCreateCommit(t *testing.T, ddb *doltdb.DoltDB, bn string, rvh hash.Hash, parents ...*doltdb.Commit) *doltdb.Commit {
 	cm, err := doltdb.NewCommitMeta("Bill Billerson", "bill@billerson.com", "A New Commit.")
 	require.NoError(t, err)
@@ -0,0 +1,72 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+)
+
+// CollectGarbage removes all data from the repository's database that can no longer be reached.  Everything reachable
+// from a ref is kept, along with the values referenced by the repo state (the working and staged roots, and any
+// in progress merge or rebase) and the commits and root values recorded in the reflogs.
+func CollectGarbage(ctx context.Context, dEnv *env.DoltEnv) error {
+	roots := repoStateRoots(dEnv.RepoState)
+
+	if dEnv.RefLog != nil {
+		refLogValues, err := dEnv.RefLog.ReferencedValues()
+
+		if err != nil {
+			return err
+		}
+
+		roots = append(roots, refLogValues...)
+	}
+
+	return dEnv.DoltDB.GC(ctx, roots...)
+}
+
+// repoStateRoots returns the hashes of the values referenced by the repo state which are not necessarily reachable
+// from any ref.
+func repoStateRoots(rs *env.RepoState) []hash.Hash {
+	hashStrs := []string{rs.Working, rs.Staged}
+
+	if rs.Merge != nil {
+		hashStrs = append(hashStrs, rs.Merge.Commit, rs.Merge.PreMergeWorking)
+	}
+
+	if rs.Rebase != nil {
+		hashStrs = append(hashStrs, rs.Rebase.OrigHead, rs.Rebase.Onto)
+
+		for _, todo := range rs.Rebase.Todo {
+			hashStrs = append(hashStrs, todo.Commit)
+		}
+
+		if rs.Rebase.Current != nil {
+			hashStrs = append(hashStrs, rs.Rebase.Current.Commit)
+		}
+	}
+
+	var roots []hash.Hash
+	for _, hashStr := range hashStrs {
+		if h, ok := hash.MaybeParse(hashStr); ok {
+			roots = append(roots, h)
+		}
+	}
+
+	return roots
+}
@@ -16,12 +16,35 @@ package actions
 
 import (
 	"context"
+	"errors"
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/merge"
 )
 
+var ErrSquashWithNoFF = errors.New("cannot combine --squash with --no-ff")
+var ErrFFOnlyWithNoFF = errors.New("cannot combine --ff-only with --no-ff")
+
+// MergeOpts controls how a merge which can be resolved as a fast-forward is handled, and whether the merged commit is
+// recorded as a parent of the next commit.
+type MergeOpts struct {
+	Squash bool
+	NoFF   bool
+	FFOnly bool
+}
+
+// Validate returns an error if options which cannot be used together have been set.
+func (opts MergeOpts) Validate() error {
+	if opts.NoFF && opts.Squash {
+		return ErrSquashWithNoFF
+	} else if opts.NoFF && opts.FFOnly {
+		return ErrFFOnlyWithNoFF
+	}
+
+	return nil
+}
+
 func MergeCommits(ctx context.Context, ddb *doltdb.DoltDB, cm1, cm2 *doltdb.Commit) (*doltdb.RootValue, map[string]*merge.MergeStats, error) {
 	merger, err := merge.NewMerger(ctx, cm1, cm2, ddb.ValueReadWriter())
 
@@ -41,7 +64,18 @@ func MergeCommits(ctx context.Context, ddb *doltdb.DoltDB, cm1, cm2 *doltdb.Comm
 		return nil, nil, err
 	}
 
-	tblNames, err := AllTables(ctx, root, rv)
+	return mergeRoots(ctx, merger, root, rv)
+}
+
+// MergeRoots merges the changes made between ancRoot and mergeRoot into root, returning the merged root and the
+// statistics for each table.  Conflicts are recorded on the tables of the merged root.
+func MergeRoots(ctx context.Context, ddb *doltdb.DoltDB, root, mergeRoot, ancRoot *doltdb.RootValue) (*doltdb.RootValue, map[string]*merge.MergeStats, error) {
+	merger := merge.NewMergerForRoots(root, mergeRoot, ancRoot, ddb.ValueReadWriter())
+	return mergeRoots(ctx, merger, root, mergeRoot)
+}
+
+func mergeRoots(ctx context.Context, merger *merge.Merger, root, mergeRoot *doltdb.RootValue) (*doltdb.RootValue, map[string]*merge.MergeStats, error) {
+	tblNames, err := AllTables(ctx, root, mergeRoot)
 
 	if err != nil {
 		return nil, nil, err
@@ -75,11 +109,15 @@ func MergeCommits(ctx context.Context, ddb *doltdb.DoltDB, cm1, cm2 *doltdb.Comm
 			if err != nil {
 				return nil, nil, err
 			}
-		} else {
-			panic("?")
 		}
 	}
 
+	root, err = merger.AddForeignKeyConflicts(ctx, root, tblToStats)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return root, tblToStats, nil
 }
 
@@ -124,3 +162,28 @@ func GetTablesInConflict(ctx context.Context, dEnv *env.DoltEnv) (workingInConfl
 
 	return workingInConflict, stagedInConflict, headInConflict, err
 }
+
+// FastForward moves the current branch to the commit given, and sets the working and staged roots to its root value.
+func FastForward(ctx context.Context, dEnv *env.DoltEnv, cm *doltdb.Commit) error {
+	rv, err := cm.GetRootValue()
+
+	if err != nil {
+		return err
+	}
+
+	h, err := dEnv.DoltDB.WriteRootValue(ctx, rv)
+
+	if err != nil {
+		return err
+	}
+
+	err = dEnv.DoltDB.FastForward(ctx, dEnv.RepoState.Head.Ref, cm)
+
+	if err != nil {
+		return err
+	}
+
+	dEnv.RepoState.Working = h.String()
+	dEnv.RepoState.Staged = h.String()
+	return dEnv.RepoState.Save()
+}
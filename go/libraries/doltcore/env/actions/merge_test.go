@@ -0,0 +1,44 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeOptsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   MergeOpts
+		expErr error
+	}{
+		{"default", MergeOpts{}, nil},
+		{"squash", MergeOpts{Squash: true}, nil},
+		{"no-ff", MergeOpts{NoFF: true}, nil},
+		{"ff-only", MergeOpts{FFOnly: true}, nil},
+		{"squash and ff-only", MergeOpts{Squash: true, FFOnly: true}, nil},
+		{"no-ff and squash", MergeOpts{NoFF: true, Squash: true}, ErrSquashWithNoFF},
+		{"no-ff and ff-only", MergeOpts{NoFF: true, FFOnly: true}, ErrFFOnlyWithNoFF},
+		{"all", MergeOpts{Squash: true, NoFF: true, FFOnly: true}, ErrSquashWithNoFF},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expErr, test.opts.Validate())
+		})
+	}
+}
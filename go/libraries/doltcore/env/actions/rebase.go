@@ -0,0 +1,497 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions/commitwalk"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/merge"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+)
+
+const (
+	RebasePick   = "pick"
+	RebaseReword = "reword"
+	RebaseSquash = "squash"
+	RebaseDrop   = "drop"
+)
+
+var rebaseActions = map[string]string{
+	RebasePick: RebasePick, "p": RebasePick,
+	RebaseReword: RebaseReword, "r": RebaseReword,
+	RebaseSquash: RebaseSquash, "s": RebaseSquash,
+	RebaseDrop: RebaseDrop, "d": RebaseDrop,
+}
+
+var ErrRebaseSquashWithoutPrevious = errors.New("cannot 'squash' without a previous commit")
+var ErrRebaseUnstagedChanges = errors.New("the working set has changes which have not been staged")
+var ErrRebaseUnresolvedConflicts = errors.New("the working set has unresolved conflicts")
+
+// RebaseMessageFunc is called with the current message of a commit being reworded, and returns the new message.
+type RebaseMessageFunc func(msg string) (string, error)
+
+// RebaseConflict describes the step of a rebase which could not be applied because of conflicts.
+type RebaseConflict struct {
+	Todo       env.RebaseTodo
+	TblToStats map[string]*merge.MergeStats
+}
+
+// GetRebaseTodos returns the list of steps needed to replay the commits which are reachable from headCm but not from
+// ontoCm on top of ontoCm, oldest first.  Each commit is picked.  Merge commits are not replayed.
+func GetRebaseTodos(ctx context.Context, ddb *doltdb.DoltDB, headCm, ontoCm *doltdb.Commit) ([]env.RebaseTodo, error) {
+	headHash, err := headCm.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ontoHash, err := ontoCm.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := commitwalk.GetDotDotRevisions(ctx, ddb, headHash, ontoHash, -1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var todos []env.RebaseTodo
+	for i := len(commits) - 1; i >= 0; i-- {
+		numParents, err := commits[i].NumParents()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if numParents > 1 {
+			continue
+		}
+
+		h, err := commits[i].HashOf()
+
+		if err != nil {
+			return nil, err
+		}
+
+		todos = append(todos, env.RebaseTodo{Action: RebasePick, Commit: h.String()})
+	}
+
+	return todos, nil
+}
+
+// ParseRebaseTodoList parses an edited todo list.  Each line is in the format "<action> <commit> [<description>]",
+// where action is one of pick, reword, squash and drop or their first letters.  Empty lines and lines starting with #
+// are ignored.  Only commits which were in the original list of steps may be used.
+func ParseRebaseTodoList(text string, original []env.RebaseTodo) ([]env.RebaseTodo, error) {
+	validCommits := make(map[string]struct{}, len(original))
+	for _, todo := range original {
+		validCommits[todo.Commit] = struct{}{}
+	}
+
+	var todos []env.RebaseTodo
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		tokens := strings.Fields(line)
+
+		if len(tokens) < 2 {
+			return nil, fmt.Errorf("line %d: expected '<action> <commit>' but got '%s'", i+1, line)
+		}
+
+		action, ok := rebaseActions[strings.ToLower(tokens[0])]
+
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown action '%s'", i+1, tokens[0])
+		}
+
+		if _, ok := validCommits[tokens[1]]; !ok {
+			return nil, fmt.Errorf("line %d: '%s' is not one of the commits being rebased", i+1, tokens[1])
+		}
+
+		todos = append(todos, env.RebaseTodo{Action: action, Commit: tokens[1]})
+	}
+
+	for _, todo := range todos {
+		if todo.Action == RebaseSquash {
+			return nil, ErrRebaseSquashWithoutPrevious
+		} else if todo.Action != RebaseDrop {
+			break
+		}
+	}
+
+	return todos, nil
+}
+
+// StartRebase records the state of a new rebase, and moves the current branch, along with the working and staged
+// roots, to ontoCm.  The steps given are run by calling ContinueRebase.
+func StartRebase(ctx context.Context, dEnv *env.DoltEnv, ontoCm *doltdb.Commit, todos []env.RebaseTodo) error {
+	branch := dEnv.RepoState.Head.Ref
+	headCm, err := dEnv.DoltDB.Resolve(ctx, dEnv.RepoState.CWBHeadSpec())
+
+	if err != nil {
+		return err
+	}
+
+	origHead, err := headCm.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	onto, err := ontoCm.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	err = dEnv.RepoState.StartRebase(branch, origHead.String(), onto.String(), todos)
+
+	if err != nil {
+		return err
+	}
+
+	return resetBranchToCommit(ctx, dEnv, branch, ontoCm)
+}
+
+// ContinueRebase runs the remaining steps of the rebase in progress.  If the rebase previously stopped because of
+// conflicts, the staged root is committed for the step that stopped before continuing.  If a step results in
+// conflicts, the working root is updated with the conflicts and a RebaseConflict is returned.  Once all steps have
+// been run the rebase state is cleared and nil is returned.
+func ContinueRebase(ctx context.Context, dEnv *env.DoltEnv, msgFn RebaseMessageFunc) (*RebaseConflict, error) {
+	state := dEnv.RepoState.Rebase
+	branch := state.Branch.Ref
+
+	if state.Current != nil {
+		working, err := dEnv.WorkingRoot(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if has, err := working.HasConflicts(ctx); err != nil {
+			return nil, err
+		} else if has {
+			return nil, ErrRebaseUnresolvedConflicts
+		}
+
+		if dEnv.RepoState.Working != dEnv.RepoState.Staged {
+			return nil, ErrRebaseUnstagedChanges
+		}
+
+		err = commitRebaseStep(ctx, dEnv, branch, *state.Current, working, msgFn)
+
+		if err != nil {
+			return nil, err
+		}
+
+		state.Current = nil
+		err = dEnv.RepoState.Save()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for len(state.Todo) > 0 {
+		todo := state.Todo[0]
+		conflict, err := runRebaseStep(ctx, dEnv, branch, todo, msgFn)
+
+		if err != nil {
+			return nil, err
+		}
+
+		state.Todo = state.Todo[1:]
+
+		if conflict != nil {
+			state.Current = &todo
+		}
+
+		err = dEnv.RepoState.Save()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if conflict != nil {
+			return conflict, nil
+		}
+	}
+
+	return nil, dEnv.RepoState.ClearRebase()
+}
+
+// AbortRebase moves the branch being rebased back to the commit it pointed at before the rebase started, resets the
+// working and staged roots to match, and clears the rebase state.
+func AbortRebase(ctx context.Context, dEnv *env.DoltEnv) error {
+	state := dEnv.RepoState.Rebase
+	origHeadCm, err := resolveCommitHash(ctx, dEnv.DoltDB, state.OrigHead)
+
+	if err != nil {
+		return err
+	}
+
+	err = resetBranchToCommit(ctx, dEnv, state.Branch.Ref, origHeadCm)
+
+	if err != nil {
+		return err
+	}
+
+	return dEnv.RepoState.ClearRebase()
+}
+
+func runRebaseStep(ctx context.Context, dEnv *env.DoltEnv, branch ref.DoltRef, todo env.RebaseTodo, msgFn RebaseMessageFunc) (*RebaseConflict, error) {
+	if todo.Action == RebaseDrop {
+		return nil, nil
+	}
+
+	cm, err := resolveCommitHash(ctx, dEnv.DoltDB, todo.Commit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	headCm, err := resolveBranchHead(ctx, dEnv.DoltDB, branch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if todo.Action == RebasePick {
+		// if the commit is already based on the current head it can be reused as is
+		headHash, err := headCm.HashOf()
+
+		if err != nil {
+			return nil, err
+		}
+
+		parentHashes, err := cm.ParentHashes(ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(parentHashes) == 1 && parentHashes[0] == headHash {
+			return nil, resetBranchToRebasedCommit(ctx, dEnv, branch, cm)
+		}
+	}
+
+	headRoot, err := headCm.GetRootValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	mergedRoot, tblToStats, err := CherryPick(ctx, dEnv.DoltDB, headRoot, cm)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if hasConflicts(tblToStats) {
+		err = dEnv.UpdateWorkingRoot(ctx, mergedRoot)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &RebaseConflict{todo, tblToStats}, nil
+	}
+
+	return nil, commitRebaseStep(ctx, dEnv, branch, todo, mergedRoot, msgFn)
+}
+
+// commitRebaseStep commits root to the branch being rebased using the author and message of the commit being
+// replayed.  Squashed commits replace the commit at the head of the branch, and combine the messages of both commits.
+// Steps which result in no changes are not committed unless they are being squashed.
+func commitRebaseStep(ctx context.Context, dEnv *env.DoltEnv, branch ref.DoltRef, todo env.RebaseTodo, root *doltdb.RootValue, msgFn RebaseMessageFunc) error {
+	ddb := dEnv.DoltDB
+	cm, err := resolveCommitHash(ctx, ddb, todo.Commit)
+
+	if err != nil {
+		return err
+	}
+
+	meta, err := cm.GetCommitMeta()
+
+	if err != nil {
+		return err
+	}
+
+	headCm, err := resolveBranchHead(ctx, ddb, branch)
+
+	if err != nil {
+		return err
+	}
+
+	msg := meta.Description
+	if todo.Action == RebaseSquash {
+		headHash, err := headCm.HashOf()
+
+		if err != nil {
+			return err
+		}
+
+		// only a commit made by this rebase can be squashed into.  If the steps before this one were all skipped
+		// because they made no changes, the head is a commit which isn't being rebased.
+		if dEnv.RepoState.Rebase.LastCommit != headHash.String() {
+			return ErrRebaseSquashWithoutPrevious
+		}
+
+		headMeta, err := headCm.GetCommitMeta()
+
+		if err != nil {
+			return err
+		}
+
+		msg = headMeta.Description + "\n\n" + msg
+		headCm, err = ddb.ResolveParent(ctx, headCm, 0)
+
+		if err != nil {
+			return err
+		}
+
+		err = ddb.NewBranchAtCommit(ctx, branch, headCm)
+
+		if err != nil {
+			return err
+		}
+	} else {
+		headRoot, err := headCm.GetRootValue()
+
+		if err != nil {
+			return err
+		}
+
+		headHash, err := headRoot.HashOf()
+
+		if err != nil {
+			return err
+		}
+
+		rootHash, err := root.HashOf()
+
+		if err != nil {
+			return err
+		}
+
+		if headHash == rootHash {
+			return resetBranchToCommit(ctx, dEnv, branch, headCm)
+		}
+	}
+
+	if todo.Action == RebaseReword {
+		msg, err = msgFn(msg)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if strings.TrimSpace(msg) == "" {
+		return ErrEmptyCommitMessage
+	}
+
+	h, err := ddb.WriteRootValue(ctx, root)
+
+	if err != nil {
+		return err
+	}
+
+	newMeta, err := doltdb.NewCommitMeta(meta.Name, meta.Email, msg)
+
+	if err != nil {
+		return err
+	}
+
+	newCm, err := ddb.CommitWithParents(ctx, h, branch, nil, newMeta)
+
+	if err != nil {
+		return err
+	}
+
+	return resetBranchToRebasedCommit(ctx, dEnv, branch, newCm)
+}
+
+// resetBranchToRebasedCommit resets the branch to a commit made or reused by a step of the rebase, and records it as
+// the last commit of the rebase, which later squash steps are folded into.
+func resetBranchToRebasedCommit(ctx context.Context, dEnv *env.DoltEnv, branch ref.DoltRef, cm *doltdb.Commit) error {
+	h, err := cm.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	dEnv.RepoState.Rebase.LastCommit = h.String()
+
+	return resetBranchToCommit(ctx, dEnv, branch, cm)
+}
+
+// resetBranchToCommit points the branch given at the commit given, and sets the working and staged roots to the root
+// value of the commit.
+func resetBranchToCommit(ctx context.Context, dEnv *env.DoltEnv, branch ref.DoltRef, cm *doltdb.Commit) error {
+	err := dEnv.DoltDB.NewBranchAtCommit(ctx, branch, cm)
+
+	if err != nil {
+		return err
+	}
+
+	root, err := cm.GetRootValue()
+
+	if err != nil {
+		return err
+	}
+
+	err = dEnv.UpdateWorkingRoot(ctx, root)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = dEnv.UpdateStagedRoot(ctx, root)
+
+	return err
+}
+
+func resolveCommitHash(ctx context.Context, ddb *doltdb.DoltDB, h string) (*doltdb.Commit, error) {
+	cs, err := doltdb.NewCommitSpec(h, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ddb.Resolve(ctx, cs)
+}
+
+func resolveBranchHead(ctx context.Context, ddb *doltdb.DoltDB, branch ref.DoltRef) (*doltdb.Commit, error) {
+	cs, err := doltdb.NewCommitSpec("HEAD", branch.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ddb.Resolve(ctx, cs)
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+)
+
+func TestParseRebaseTodoList(t *testing.T) {
+	original := []env.RebaseTodo{
+		{Action: RebasePick, Commit: "aaaa"},
+		{Action: RebasePick, Commit: "bbbb"},
+		{Action: RebasePick, Commit: "cccc"},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected []env.RebaseTodo
+		expErr   bool
+	}{
+		{
+			"unchanged",
+			"pick aaaa first\npick bbbb second\npick cccc third\n\n# comment\n",
+			original,
+			false,
+		},
+		{
+			"reordered with abbreviations",
+			"p cccc third\nr aaaa first\ns bbbb second\n",
+			[]env.RebaseTodo{
+				{Action: RebasePick, Commit: "cccc"},
+				{Action: RebaseReword, Commit: "aaaa"},
+				{Action: RebaseSquash, Commit: "bbbb"},
+			},
+			false,
+		},
+		{
+			"removed lines and drop",
+			"drop aaaa first\n  pick cccc third  \n",
+			[]env.RebaseTodo{
+				{Action: RebaseDrop, Commit: "aaaa"},
+				{Action: RebasePick, Commit: "cccc"},
+			},
+			false,
+		},
+		{
+			"everything removed",
+			"# pick aaaa first\n",
+			nil,
+			false,
+		},
+		{
+			"unknown action",
+			"edit aaaa first\n",
+			nil,
+			true,
+		},
+		{
+			"unknown commit",
+			"pick dddd fourth\n",
+			nil,
+			true,
+		},
+		{
+			"missing commit",
+			"pick\n",
+			nil,
+			true,
+		},
+		{
+			"squash without previous commit",
+			"drop aaaa first\nsquash bbbb second\n",
+			nil,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := ParseRebaseTodoList(test.text, original)
+
+			if test.expErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, actual)
+			}
+		})
+	}
+}
@@ -25,6 +25,7 @@ import (
 )
 
 var ErrCantFF = errors.New("can't fast forward merge")
+var ErrTagExists = errors.New("tag already exists")
 
 // Push will update a destination branch, in a given destination database if it can be done as a fast forward merge.
 // This is accomplished first by verifying that the remote tracking reference for the source database can be updated to
@@ -83,6 +84,60 @@ func DeleteRemoteBranch(ctx context.Context, targetRef ref.BranchRef, remoteRef
 	return nil
 }
 
+// PushTag pushes the chunks needed by a tag to the destination database and then creates the tag in the destination
+// database.  If the destination already has a different tag with the same name ErrTagExists is returned.
+func PushTag(ctx context.Context, dEnv *env.DoltEnv, destRef ref.TagRef, srcDB, destDB *doltdb.DoltDB, tag *doltdb.Tag, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	destTag, err := destDB.ResolveTag(ctx, destRef)
+
+	if err == nil {
+		if same, err := tagsEqual(tag, destTag); err != nil {
+			return err
+		} else if same {
+			return doltdb.ErrUpToDate
+		}
+
+		return ErrTagExists
+	} else if err != doltdb.ErrTagNotFound {
+		return err
+	}
+
+	err = destDB.PushTagChunks(ctx, dEnv.TempTableFilesDir(), srcDB, tag, progChan, pullerEventCh)
+
+	if err != nil {
+		return err
+	}
+
+	return destDB.SetTag(ctx, destRef, tag)
+}
+
+// FetchTag pulls the chunks needed by a tag from the source database, and then sets the tag in the destination
+// database, overwriting any existing tag with the same name.
+func FetchTag(ctx context.Context, dEnv *env.DoltEnv, destRef ref.DoltRef, srcDB, destDB *doltdb.DoltDB, tag *doltdb.Tag, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	err := destDB.PullTagChunks(ctx, dEnv.TempTableFilesDir(), srcDB, tag, progChan, pullerEventCh)
+
+	if err != nil {
+		return err
+	}
+
+	return destDB.SetTag(ctx, destRef, tag)
+}
+
+func tagsEqual(t1, t2 *doltdb.Tag) (bool, error) {
+	h1, err := t1.HashOf()
+
+	if err != nil {
+		return false, err
+	}
+
+	h2, err := t2.HashOf()
+
+	if err != nil {
+		return false, err
+	}
+
+	return h1 == h2, nil
+}
+
 func Fetch(ctx context.Context, dEnv *env.DoltEnv, destRef ref.DoltRef, srcDB, destDB *doltdb.DoltDB, commit *doltdb.Commit, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
 	err := destDB.PullChunks(ctx, dEnv.TempTableFilesDir(), srcDB, commit, progChan, pullerEventCh)
 
@@ -93,6 +148,39 @@ func Fetch(ctx context.Context, dEnv *env.DoltEnv, destRef ref.DoltRef, srcDB, d
 	return destDB.FastForward(ctx, destRef, commit)
 }
 
+// FetchToDepth pulls the chunks needed by the history of a commit from the source database, limited to the commits
+// within depth commits of it, and then sets the destination ref to the commit.  The shallow commits of the repository,
+// at the boundary of the history pulled, are saved in the repo state.
+func FetchToDepth(ctx context.Context, dEnv *env.DoltEnv, destRef ref.DoltRef, srcDB, destDB *doltdb.DoltDB, commit *doltdb.Commit, depth int, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	err := destDB.PullChunksToDepth(ctx, dEnv.TempTableFilesDir(), srcDB, commit, depth, progChan, pullerEventCh)
+
+	if err != nil {
+		return err
+	}
+
+	dEnv.RepoState.SetShallowCommits(destDB.ShallowCommits())
+	err = dEnv.RepoState.Save()
+
+	if err != nil {
+		return err
+	}
+
+	return destDB.SetHeadToCommit(ctx, destRef, commit)
+}
+
+// Unshallow pulls all of the history missing from a shallow repository from the source database, after which the
+// repository is no longer shallow.
+func Unshallow(ctx context.Context, dEnv *env.DoltEnv, srcDB *doltdb.DoltDB, progChan chan datas.PullProgress, pullerEventCh chan datas.PullerEvent) error {
+	err := dEnv.DoltDB.Unshallow(ctx, dEnv.TempTableFilesDir(), srcDB, progChan, pullerEventCh)
+
+	if err != nil {
+		return err
+	}
+
+	dEnv.RepoState.SetShallowCommits(nil)
+	return dEnv.RepoState.Save()
+}
+
 func Clone(ctx context.Context, srcDB, destDB *doltdb.DoltDB, eventCh chan<- datas.TableFileEvent) error {
 	return srcDB.Clone(ctx, destDB, eventCh)
 }
@@ -0,0 +1,118 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+)
+
+// ResetHard sets the working and staged roots to the root of HEAD.  Tables in the working set which don't exist at HEAD
+// are untracked, and are kept.
+func ResetHard(ctx context.Context, dEnv *env.DoltEnv) error {
+	workingRoot, headRoot, err := getWorkingAndHead(ctx, dEnv)
+
+	if err != nil {
+		return err
+	}
+
+	// need to save the state of files that aren't tracked
+	untrackedTables := make(map[string]*doltdb.Table)
+	wTblNames, err := workingRoot.GetTableNames(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, tblName := range wTblNames {
+		untrackedTables[tblName], _, err = workingRoot.GetTable(ctx, tblName)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	headTblNames, err := headRoot.GetTableNames(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, tblName := range headTblNames {
+		delete(untrackedTables, tblName)
+	}
+
+	newWkRoot := headRoot
+	for tblName, tbl := range untrackedTables {
+		newWkRoot, err = newWkRoot.PutTable(ctx, tblName, tbl)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// TODO: update working and staged in one repo_state write.
+	err = dEnv.UpdateWorkingRoot(ctx, newWkRoot)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = dEnv.UpdateStagedRoot(ctx, headRoot)
+
+	return err
+}
+
+// ResetSoft sets the staged value of the given tables to their value at HEAD, leaving the working set untouched.  If no
+// tables are given, or the only table given is ".", all tables are reset.  Returns the updated staged root.
+func ResetSoft(ctx context.Context, dEnv *env.DoltEnv, tbls []string) (*doltdb.RootValue, error) {
+	roots, err := getRoots(ctx, dEnv, StagedRoot, HeadRoot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stagedRoot, headRoot := roots[StagedRoot], roots[HeadRoot]
+
+	if len(tbls) == 0 || (len(tbls) == 1 && tbls[0] == ".") {
+		tbls, err = AllTables(ctx, stagedRoot, headRoot)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = ValidateTables(ctx, tbls, stagedRoot, headRoot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stagedRoot, err = stagedRoot.UpdateTablesFromOther(ctx, tbls, headRoot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = dEnv.UpdateStagedRoot(ctx, stagedRoot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stagedRoot, nil
+}
@@ -0,0 +1,35 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/merge"
+)
+
+// Revert computes the inverse of the changes introduced by the commit given and applies them on top of root.  The
+// commit's root value is used as the base of a three-way merge between root and the commit's parent, so rows changed
+// by the commit which have since been changed again in root are recorded as conflicts on the resulting tables.
+func Revert(ctx context.Context, ddb *doltdb.DoltDB, root *doltdb.RootValue, cm *doltdb.Commit) (*doltdb.RootValue, map[string]*merge.MergeStats, error) {
+	cmRoot, parentRoot, err := getCommitAndParentRoots(ctx, ddb, cm)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return MergeRoots(ctx, ddb, root, parentRoot, cmRoot)
+}
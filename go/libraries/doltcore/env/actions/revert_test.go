@@ -0,0 +1,86 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+)
+
+func TestRevertMergeCommit(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+
+	commitPeople(t, dEnv, "initial", dtestutils.TypedRows...)
+	require.NoError(t, CreateBranch(ctx, dEnv, "other", "master", false))
+	commitPeople(t, dEnv, "rename on master", person(0, "Master Billerson"), dtestutils.TypedRows[1], dtestutils.TypedRows[2])
+
+	require.NoError(t, CheckoutBranch(ctx, dEnv, "other"))
+	commitPeople(t, dEnv, "rename on other", dtestutils.TypedRows[0], person(1, "Other Johnson"), dtestutils.TypedRows[2])
+	require.NoError(t, CheckoutBranch(ctx, dEnv, "master"))
+
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+	h, err := dEnv.DoltDB.WriteRootValue(ctx, root)
+	require.NoError(t, err)
+
+	otherSpec, err := doltdb.NewCommitSpec("other", "master")
+	require.NoError(t, err)
+	meta, err := doltdb.NewCommitMeta("Bill Billerson", "bigbillieb@fake.horse", "merge other")
+	require.NoError(t, err)
+	mergeCm, err := dEnv.DoltDB.CommitWithParents(ctx, h, dEnv.RepoState.Head.Ref, []*doltdb.CommitSpec{otherSpec}, meta)
+	require.NoError(t, err)
+
+	numParents, err := mergeCm.NumParents()
+	require.NoError(t, err)
+	require.Equal(t, 2, numParents)
+
+	_, _, err = Revert(ctx, dEnv.DoltDB, root, mergeCm)
+	assert.Equal(t, ErrMergeCommitNotSupported, err)
+}
+
+func TestRevert(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+
+	commitPeople(t, dEnv, "initial", dtestutils.TypedRows...)
+	cm := commitPeople(t, dEnv, "rename", person(0, "Renamed Billerson"), dtestutils.TypedRows[1], dtestutils.TypedRows[2])
+
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	revertedRoot, tblToStats, err := Revert(ctx, dEnv.DoltDB, root, cm)
+	require.NoError(t, err)
+	require.Contains(t, tblToStats, peopleTable)
+	assert.Equal(t, 1, tblToStats[peopleTable].Modifications)
+	assert.Equal(t, 0, tblToStats[peopleTable].Conflicts)
+
+	parent, err := dEnv.DoltDB.ResolveParent(ctx, cm, 0)
+	require.NoError(t, err)
+	parentRoot, err := parent.GetRootValue()
+	require.NoError(t, err)
+
+	expected, _, err := parentRoot.GetTableHash(ctx, peopleTable)
+	require.NoError(t, err)
+	actual, _, err := revertedRoot.GetTableHash(ctx, peopleTable)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
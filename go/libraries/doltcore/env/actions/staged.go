@@ -74,6 +74,10 @@ func stageTables(ctx context.Context, dEnv *env.DoltEnv, tbls []string, staged *
 				if !allowConflicts {
 					inConflict = append(inConflict, tblName)
 				}
+			} else if has, err := tbl.HasSchemaConflicts(); err != nil {
+				return err
+			} else if has {
+				inConflict = append(inConflict, tblName)
 			}
 		}
 
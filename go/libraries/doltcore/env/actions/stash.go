@@ -0,0 +1,352 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/merge"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+)
+
+var ErrNoLocalChanges = errors.New("no local changes to save")
+var ErrInvalidStashName = errors.New("not a valid stash reference")
+
+// StashEntry is a single entry in the stash stack.  Each stash is stored as a pair of commits under refs/stashes/.
+// The commit the stash ref points at holds the stashed working root.  Its only parent holds the stashed staged root,
+// and the parent of that commit is the commit that HEAD pointed at when the stash was created.
+type StashEntry struct {
+	// Name is the name used to refer to the stash on the command line e.g. stash@{0}
+	Name   string
+	Ref    ref.DoltRef
+	Commit *doltdb.Commit
+	Meta   *doltdb.CommitMeta
+
+	id int
+}
+
+// StashChanges saves the working and staged roots to a new stash entry, and then resets the working and staged roots
+// to HEAD.  If msg is empty a description is generated from the commit at HEAD.
+func StashChanges(ctx context.Context, dEnv *env.DoltEnv, msg string) error {
+	isUnchanged, err := dEnv.IsUnchangedFromHead(ctx)
+
+	if err != nil {
+		return err
+	} else if isUnchanged {
+		return ErrNoLocalChanges
+	}
+
+	name, email, err := getNameAndEmail(dEnv.Config)
+
+	if err != nil {
+		return err
+	}
+
+	headCm, err := dEnv.DoltDB.Resolve(ctx, dEnv.RepoState.CWBHeadSpec())
+
+	if err != nil {
+		return err
+	}
+
+	headRoot, err := headCm.GetRootValue()
+
+	if err != nil {
+		return err
+	}
+
+	staged, err := dEnv.StagedRoot(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	working, err := dEnv.WorkingRoot(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	stashes, err := GetStashes(ctx, dEnv.DoltDB)
+
+	if err != nil {
+		return err
+	}
+
+	nextId := 0
+	if len(stashes) > 0 {
+		nextId = stashes[0].id + 1
+	}
+
+	stashRef := ref.NewStashRef(strconv.Itoa(nextId))
+	desc, err := stashDescription(dEnv.RepoState.Head.Ref, headCm, msg)
+
+	if err != nil {
+		return err
+	}
+
+	stagedHash, err := dEnv.DoltDB.WriteRootValue(ctx, staged)
+
+	if err != nil {
+		return err
+	}
+
+	meta, err := doltdb.NewCommitMeta(name, email, "index on "+desc)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = dEnv.DoltDB.CommitWithParents(ctx, stagedHash, stashRef, []*doltdb.CommitSpec{dEnv.RepoState.CWBHeadSpec()}, meta)
+
+	if err != nil {
+		return err
+	}
+
+	workingHash, err := dEnv.DoltDB.WriteRootValue(ctx, working)
+
+	if err != nil {
+		return err
+	}
+
+	meta, err = doltdb.NewCommitMeta(name, email, desc)
+
+	if err != nil {
+		return err
+	}
+
+	// the stash ref already points at the staged commit, so it becomes the parent of the working commit.
+	_, err = dEnv.DoltDB.CommitWithParents(ctx, workingHash, stashRef, nil, meta)
+
+	if err != nil {
+		return err
+	}
+
+	err = dEnv.UpdateWorkingRoot(ctx, headRoot)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = dEnv.UpdateStagedRoot(ctx, headRoot)
+
+	return err
+}
+
+func stashDescription(headRef ref.DoltRef, headCm *doltdb.Commit, msg string) (string, error) {
+	if msg != "" {
+		return fmt.Sprintf("On %s: %s", headRef.GetPath(), msg), nil
+	}
+
+	h, err := headCm.HashOf()
+
+	if err != nil {
+		return "", err
+	}
+
+	meta, err := headCm.GetCommitMeta()
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("WIP on %s: %s %s", headRef.GetPath(), h.String(), meta.Description), nil
+}
+
+// GetStashes returns all the entries in the stash stack, with the most recently created stash first.
+func GetStashes(ctx context.Context, ddb *doltdb.DoltDB) ([]*StashEntry, error) {
+	stashRefs, err := ddb.GetStashes(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stashes := make([]*StashEntry, 0, len(stashRefs))
+	for _, stashRef := range stashRefs {
+		id, err := strconv.Atoi(stashRef.GetPath())
+
+		if err != nil {
+			// not created by dolt stash
+			continue
+		}
+
+		cs, err := doltdb.NewCommitSpec("HEAD", stashRef.String())
+
+		if err != nil {
+			return nil, err
+		}
+
+		cm, err := ddb.Resolve(ctx, cs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		meta, err := cm.GetCommitMeta()
+
+		if err != nil {
+			return nil, err
+		}
+
+		stashes = append(stashes, &StashEntry{Ref: stashRef, Commit: cm, Meta: meta, id: id})
+	}
+
+	sort.Slice(stashes, func(i, j int) bool {
+		return stashes[i].id > stashes[j].id
+	})
+
+	for i, stash := range stashes {
+		stash.Name = fmt.Sprintf("stash@{%d}", i)
+	}
+
+	return stashes, nil
+}
+
+// GetStash returns the stash with the name given.  Stashes can be referred to as stash@{n} or just n, where n is the
+// position of the stash in the stack, and 0 is the most recently created stash.
+func GetStash(ctx context.Context, ddb *doltdb.DoltDB, name string) (*StashEntry, error) {
+	idxStr := name
+	if strings.HasPrefix(name, "stash@{") && strings.HasSuffix(name, "}") {
+		idxStr = name[len("stash@{") : len(name)-1]
+	}
+
+	idx, err := strconv.Atoi(idxStr)
+
+	if err != nil || idx < 0 {
+		return nil, ErrInvalidStashName
+	}
+
+	stashes, err := GetStashes(ctx, ddb)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if idx >= len(stashes) {
+		return nil, doltdb.ErrStashNotFound
+	}
+
+	return stashes[idx], nil
+}
+
+// ApplyStash restores the working and staged roots saved in the stash given.  If HEAD has moved since the stash was
+// created, the stashed changes are applied using a three-way merge with the commit the stash was created on as the
+// merge base.  Rows which conflict are recorded as conflicts in the working root, and in that case the staged root is
+// left at HEAD.  The stash is not removed.
+func ApplyStash(ctx context.Context, dEnv *env.DoltEnv, stash *StashEntry) (map[string]*merge.MergeStats, error) {
+	stagedCm, err := dEnv.DoltDB.ResolveParent(ctx, stash.Commit, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	baseCm, err := dEnv.DoltDB.ResolveParent(ctx, stagedCm, 0)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stashWorking, err := stash.Commit.GetRootValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	stashStaged, err := stagedCm.GetRootValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	baseRoot, err := baseCm.GetRootValue()
+
+	if err != nil {
+		return nil, err
+	}
+
+	headRoot, err := dEnv.HeadRoot(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	baseHash, err := baseRoot.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	headHash, err := headRoot.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	working, staged := stashWorking, stashStaged
+	var tblToStats map[string]*merge.MergeStats
+	if baseHash != headHash {
+		working, tblToStats, err = MergeRoots(ctx, dEnv.DoltDB, headRoot, stashWorking, baseRoot)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var stagedStats map[string]*merge.MergeStats
+		staged, stagedStats, err = MergeRoots(ctx, dEnv.DoltDB, headRoot, stashStaged, baseRoot)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if hasConflicts(stagedStats) {
+			staged = headRoot
+		}
+	}
+
+	err = dEnv.UpdateWorkingRoot(ctx, working)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = dEnv.UpdateStagedRoot(ctx, staged)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return tblToStats, nil
+}
+
+func hasConflicts(tblToStats map[string]*merge.MergeStats) bool {
+	for _, stats := range tblToStats {
+		if stats.Conflicts > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DropStash removes the stash given from the stash stack.
+func DropStash(ctx context.Context, ddb *doltdb.DoltDB, stash *StashEntry) error {
+	return ddb.DeleteStash(ctx, stash.Ref)
+}
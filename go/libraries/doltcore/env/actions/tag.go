@@ -0,0 +1,113 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"sort"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+)
+
+// CreateTag creates a tag named tagName at the commit resolved from startPoint.  If msg is non-empty an annotated tag
+// is created using the configured user name and email as the tagger, otherwise a lightweight tag is created.
+func CreateTag(ctx context.Context, dEnv *env.DoltEnv, tagName, startPoint, msg string, force bool) error {
+	tagRef := ref.NewTagRef(tagName)
+
+	if !ref.IsValidTagName(tagName) {
+		return doltdb.ErrInvTagName
+	}
+
+	hasRef, err := dEnv.DoltDB.HasRef(ctx, tagRef)
+
+	if err != nil {
+		return err
+	}
+
+	if !force && hasRef {
+		return ErrAlreadyExists
+	}
+
+	cs, err := doltdb.NewCommitSpec(startPoint, dEnv.RepoState.Head.Ref.String())
+
+	if err != nil {
+		return err
+	}
+
+	cm, err := dEnv.DoltDB.Resolve(ctx, cs)
+
+	if err != nil {
+		return err
+	}
+
+	var meta *doltdb.TagMeta
+	if msg != "" {
+		name, email, err := getNameAndEmail(dEnv.Config)
+
+		if err != nil {
+			return err
+		}
+
+		meta, err = doltdb.NewTagMeta(name, email, msg)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// NewTagAtCommit moves an existing tag in a single ref update, so a forced tag is never missing
+	return dEnv.DoltDB.NewTagAtCommit(ctx, tagRef, cm, meta)
+}
+
+// DeleteTags deletes each of the tags given, stopping at the first tag which does not exist.
+func DeleteTags(ctx context.Context, dEnv *env.DoltEnv, tagNames ...string) error {
+	for _, tagName := range tagNames {
+		err := dEnv.DoltDB.DeleteTag(ctx, ref.NewTagRef(tagName))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetTags returns all tags in the database sorted by name.
+func GetTags(ctx context.Context, ddb *doltdb.DoltDB) ([]*doltdb.Tag, error) {
+	tagRefs, err := ddb.GetTags(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]*doltdb.Tag, 0, len(tagRefs))
+	for _, tagRef := range tagRefs {
+		tag, err := ddb.ResolveTag(ctx, tagRef)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+
+	return tags, nil
+}
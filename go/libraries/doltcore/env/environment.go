@@ -18,7 +18,6 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -64,6 +63,8 @@ type DoltEnv struct {
 	DoltDB      *doltdb.DoltDB
 	DBLoadError error
 
+	RefLog *RefLog
+
 	FS     filesys.Filesys
 	urlStr string
 	hdp    HomeDirProvider
@@ -82,14 +83,21 @@ func Load(ctx context.Context, hdp HomeDirProvider, fs filesys.Filesys, urlStr s
 		rsErr,
 		ddb,
 		dbLoadErr,
+		nil,
 		fs,
 		urlStr,
 		hdp,
 	}
 
-	if dbLoadErr == nil && dEnv.HasDoltDir() {
+	if rsErr == nil && dbLoadErr == nil {
+		dEnv.RefLog = NewRefLog(fs, repoState)
+		ddb.SetRefLog(dEnv.RefLog)
+		ddb.SetShallowCommits(repoState.ShallowCommits())
+	}
+
+	if dbLoadErr == nil && dEnv.HasDoltDataDir() {
 		if !dEnv.HasDoltTempTableDir() {
-			err := os.Mkdir(dEnv.TempTableFilesDir(), os.ModePerm)
+			err := fs.MkDirs(dEnv.TempTableFilesDir())
 			dEnv.DBLoadError = err
 		} else {
 			// fire and forget cleanup routine.  Will delete as many old temp files as it can during the main commands execution.
@@ -115,6 +123,25 @@ func Load(ctx context.Context, hdp HomeDirProvider, fs filesys.Filesys, urlStr s
 	return dEnv
 }
 
+// LoadFromDir loads the DoltEnv for the repository in the directory dir, rather than the current directory.
+func LoadFromDir(ctx context.Context, hdp HomeDirProvider, dir string) (*DoltEnv, error) {
+	fs, err := filesys.LocalFilesysWithWorkingDir(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, err := fs.Abs("")
+
+	if err != nil {
+		return nil, err
+	}
+
+	urlStr := "file://" + filepath.ToSlash(filepath.Join(absDir, dbfactory.DoltDataDir))
+
+	return Load(ctx, hdp, fs, urlStr), nil
+}
+
 // HasDoltDir returns true if the .dolt directory exists and is a valid directory
 func (dEnv *DoltEnv) HasDoltDir() bool {
 	return dEnv.hasDoltDir("./")
@@ -246,6 +273,7 @@ func (dEnv *DoltEnv) initDBAndState(ctx context.Context, nbf *types.NomsBinForma
 		return err
 	}
 
+	dEnv.DBLoadError = nil
 	err = dEnv.DoltDB.WriteEmptyRepo(ctx, name, email)
 
 	if err != nil {
@@ -273,7 +301,35 @@ func (dEnv *DoltEnv) initDBAndState(ctx context.Context, nbf *types.NomsBinForma
 		return ErrStateUpdate
 	}
 
-	return nil
+	dEnv.RSLoadErr = nil
+
+	return dEnv.initRefLog(ctx, commit)
+}
+
+// initRefLog creates the reflog for a newly initialized repository and records the initial commit on master in it.
+func (dEnv *DoltEnv) initRefLog(ctx context.Context, initialCommit *doltdb.Commit) error {
+	h, err := initialCommit.HashOf()
+
+	if err != nil {
+		return err
+	}
+
+	dEnv.RefLog = NewRefLog(dEnv.FS, dEnv.RepoState)
+	dEnv.RefLog.SetMessage("dolt init")
+	dEnv.DoltDB.SetRefLog(dEnv.RefLog)
+
+	return dEnv.RefLog.RecordRefUpdate(ctx, dEnv.RepoState.Head.Ref, hash.Hash{}, h)
+}
+
+// Detached returns a copy of the environment with a detached copy of its repo state.  Its head and working set can be
+// changed without affecting other users of the repository, while branches and other refs are still shared with them
+// through the database.  Moves of its head aren't recorded in the reflog.
+func (dEnv *DoltEnv) Detached() *DoltEnv {
+	detached := *dEnv
+	detached.RepoState = dEnv.RepoState.Detached()
+	detached.RefLog = nil
+
+	return &detached
 }
 
 func (dEnv *DoltEnv) WorkingRoot(ctx context.Context) (*doltdb.RootValue, error) {
@@ -291,9 +347,11 @@ func (dEnv *DoltEnv) UpdateWorkingRoot(ctx context.Context, newRoot *doltdb.Root
 	}
 
 	dEnv.RepoState.Working = h.String()
-	err = dEnv.RepoState.Save()
+	err = dEnv.RepoState.SaveContext(ctx)
 
-	if err != nil {
+	if err == ErrWorkingSetChanged {
+		return err
+	} else if err != nil {
 		return ErrStateUpdate
 	}
 
@@ -326,15 +384,20 @@ func (dEnv *DoltEnv) UpdateStagedRoot(ctx context.Context, newRoot *doltdb.RootV
 	}
 
 	dEnv.RepoState.Staged = h.String()
-	err = dEnv.RepoState.Save()
+	err = dEnv.RepoState.SaveContext(ctx)
 
-	if err != nil {
+	if err == ErrWorkingSetChanged {
+		return hash.Hash{}, err
+	} else if err != nil {
 		return hash.Hash{}, ErrStateUpdate
 	}
 
 	return h, nil
 }
 
+// PutTableToWorking writes a table with the given rows and schema to the working root.  A
+// doltdb.ForeignKeyViolationError is returned if the new rows violate a foreign key of the table, or leave a row of
+// another table referencing a row which no longer exists.
 func (dEnv *DoltEnv) PutTableToWorking(ctx context.Context, rows types.Map, sch schema.Schema, tableName string) error {
 	root, err := dEnv.WorkingRoot(ctx)
 
@@ -355,12 +418,38 @@ func (dEnv *DoltEnv) PutTableToWorking(ctx context.Context, rows types.Map, sch
 		return err
 	}
 
+	tbl, err = tbl.RebuildIndexes(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	oldTbl, oldTblExists, err := root.GetTable(ctx, tableName)
+
+	if err != nil {
+		return err
+	}
+
+	if oldTblExists {
+		tbl, err = tbl.CopyAutoIncrementValue(oldTbl)
+
+		if err != nil {
+			return err
+		}
+	}
+
 	newRoot, err := root.PutTable(ctx, tableName, tbl)
 
 	if err != nil {
 		return err
 	}
 
+	err = newRoot.CheckForeignKeys(ctx, tableName, oldTbl)
+
+	if err != nil {
+		return err
+	}
+
 	rootHash, err := root.HashOf()
 
 	if err != nil {
@@ -380,6 +469,10 @@ func (dEnv *DoltEnv) IsMergeActive() bool {
 	return dEnv.RepoState.Merge != nil
 }
 
+func (dEnv *DoltEnv) IsRebaseActive() bool {
+	return dEnv.RepoState.Rebase != nil
+}
+
 func (dEnv *DoltEnv) GetTablesWithConflicts(ctx context.Context) ([]string, error) {
 	root, err := dEnv.WorkingRoot(ctx)
 
@@ -29,7 +29,8 @@ const (
 	configFile   = "config.json"
 	globalConfig = "config_global.json"
 
-	repoStateFile = "repo_state.json"
+	repoStateFile     = "repo_state.json"
+	repoStateLockFile = "repo_state.lock"
 )
 
 // HomeDirProvider is a function that returns the users home directory.  This is where global dolt state is stored for
@@ -77,6 +78,10 @@ func getRepoStateFile() string {
 	return filepath.Join(dbfactory.DoltDir, repoStateFile)
 }
 
+func getRepoStateLockFile() string {
+	return filepath.Join(dbfactory.DoltDir, repoStateLockFile)
+}
+
 func getHomeDir(hdp HomeDirProvider) (string, error) {
 	homeDir, err := hdp()
 	if err != nil {
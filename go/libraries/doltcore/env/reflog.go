@@ -0,0 +1,290 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+)
+
+const (
+	refLogDir = "logs"
+
+	// WorkingRefLogName is the name of the reflog which records the root value hashes of the working set
+	WorkingRefLogName = "WORKING"
+
+	// StagedRefLogName is the name of the reflog which records the root value hashes of the staged tables
+	StagedRefLogName = "STAGED"
+)
+
+// RefLogEntry is a single update recorded in a reflog
+type RefLogEntry struct {
+	OldHash   hash.Hash
+	NewHash   hash.Hash
+	Timestamp time.Time
+	Message   string
+}
+
+// RefLog is a doltdb.RefLog which stores a log file for each ref in the .dolt/logs directory, using the same layout as
+// the ref names e.g. .dolt/logs/refs/heads/master.  Updates to the branch that is checked out are also recorded in the
+// HEAD log, and changes to the working and staged root values are recorded in the WORKING and STAGED logs.
+type RefLog struct {
+	fs      filesys.Filesys
+	rs      *RepoState
+	message string
+
+	lastWorking string
+	lastStaged  string
+}
+
+// NewRefLog creates a RefLog which records updates to the refs of the repository with the RepoState given.
+func NewRefLog(fs filesys.Filesys, rs *RepoState) *RefLog {
+	rl := &RefLog{fs: fs, rs: rs, lastWorking: rs.Working, lastStaged: rs.Staged}
+	rs.refLog = rl
+
+	return rl
+}
+
+// SetMessage sets the message recorded along with each update, which is normally the command being run.
+func (rl *RefLog) SetMessage(msg string) {
+	rl.message = strings.Join(strings.Fields(msg), " ")
+}
+
+// RecordRefUpdate records an update to the ref given.  If the ref is the branch that is checked out, the update is
+// also recorded in the HEAD log.
+func (rl *RefLog) RecordRefUpdate(ctx context.Context, dref ref.DoltRef, oldHash, newHash hash.Hash) error {
+	err := rl.appendEntry(dref.String(), oldHash, newHash)
+
+	if err != nil {
+		return err
+	}
+
+	if ref.Equals(dref, rl.rs.Head.Ref) {
+		return rl.appendEntry(doltdb.HeadRefLogName, oldHash, newHash)
+	}
+
+	return nil
+}
+
+// RecordHeadUpdate records HEAD moving between commits without any ref being updated, such as when a different branch
+// is checked out.
+func (rl *RefLog) RecordHeadUpdate(oldHash, newHash hash.Hash) error {
+	if oldHash == newHash {
+		return nil
+	}
+
+	return rl.appendEntry(doltdb.HeadRefLogName, oldHash, newHash)
+}
+
+// recordWorkingSetUpdate is called whenever the repo state is saved, and records any changes to the working and staged
+// root values.
+func (rl *RefLog) recordWorkingSetUpdate(working, staged string) error {
+	if working != rl.lastWorking {
+		err := rl.appendEntry(WorkingRefLogName, hash.Parse(rl.lastWorking), hash.Parse(working))
+
+		if err != nil {
+			return err
+		}
+
+		rl.lastWorking = working
+	}
+
+	if staged != rl.lastStaged {
+		err := rl.appendEntry(StagedRefLogName, hash.Parse(rl.lastStaged), hash.Parse(staged))
+
+		if err != nil {
+			return err
+		}
+
+		rl.lastStaged = staged
+	}
+
+	return nil
+}
+
+// LookupRefLogEntry returns the hash the log with the name given pointed at n updates ago, where 0 is the most recent
+// update.
+func (rl *RefLog) LookupRefLogEntry(ctx context.Context, name string, n int) (hash.Hash, error) {
+	entries, err := rl.GetEntries(name)
+
+	if err != nil {
+		return hash.Hash{}, err
+	}
+
+	if n >= len(entries) {
+		return hash.Hash{}, doltdb.ErrRefLogEntryNotFound
+	}
+
+	return entries[n].NewHash, nil
+}
+
+// GetEntries returns the entries of the log with the name given, with the most recent update first.  The name is
+// either HEAD, WORKING, STAGED or a fully qualified ref string.
+func (rl *RefLog) GetEntries(name string) ([]RefLogEntry, error) {
+	path := getRefLogPath(name)
+
+	if exists, _ := rl.fs.Exists(path); !exists {
+		return nil, nil
+	}
+
+	return rl.readEntries(path)
+}
+
+// ReferencedValues returns the hashes of every value recorded in the reflogs.  These are commits for the logs of HEAD
+// and of the refs, and root values for the WORKING and STAGED logs.
+func (rl *RefLog) ReferencedValues() ([]hash.Hash, error) {
+	logsDir := filepath.Join(dbfactory.DoltDir, refLogDir)
+
+	if exists, _ := rl.fs.Exists(logsDir); !exists {
+		return nil, nil
+	}
+
+	var paths []string
+	err := rl.fs.Iter(logsDir, true, func(path string, size int64, isDir bool) (stop bool) {
+		if !isDir {
+			paths = append(paths, path)
+		}
+
+		return false
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []hash.Hash
+	for _, path := range paths {
+		entries, err := rl.readEntries(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			for _, h := range []hash.Hash{entry.OldHash, entry.NewHash} {
+				if !h.IsEmpty() {
+					hashes = append(hashes, h)
+				}
+			}
+		}
+	}
+
+	return hashes, nil
+}
+
+func (rl *RefLog) readEntries(path string) ([]RefLogEntry, error) {
+	data, err := rl.fs.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	entries := make([]RefLogEntry, 0, len(lines))
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+
+		entry, err := parseRefLogLine(lines[i])
+
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", path, i+1, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// appendEntry adds an entry to the end of the log with the name given.  The entry is added with a single write to the
+// file opened for appending, so entries written at the same time by other processes, such as a running sql-server,
+// are never lost.
+func (rl *RefLog) appendEntry(name string, oldHash, newHash hash.Hash) (err error) {
+	path := getRefLogPath(name)
+	err = rl.fs.MkDirs(filepath.Dir(path))
+
+	if err != nil {
+		return err
+	}
+
+	wr, err := rl.fs.OpenForAppend(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		closeErr := wr.Close()
+
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	line := fmt.Sprintf("%s %s %d\t%s\n", oldHash.String(), newHash.String(), time.Now().Unix(), rl.message)
+	_, err = wr.Write([]byte(line))
+
+	return err
+}
+
+func parseRefLogLine(line string) (RefLogEntry, error) {
+	tokens := strings.SplitN(line, "\t", 2)
+	fields := strings.Fields(tokens[0])
+
+	if len(fields) != 3 {
+		return RefLogEntry{}, fmt.Errorf("invalid reflog entry '%s'", line)
+	}
+
+	oldHash, ok := hash.MaybeParse(fields[0])
+
+	if !ok {
+		return RefLogEntry{}, fmt.Errorf("invalid hash '%s'", fields[0])
+	}
+
+	newHash, ok := hash.MaybeParse(fields[1])
+
+	if !ok {
+		return RefLogEntry{}, fmt.Errorf("invalid hash '%s'", fields[1])
+	}
+
+	secs, err := strconv.ParseInt(fields[2], 10, 64)
+
+	if err != nil {
+		return RefLogEntry{}, fmt.Errorf("invalid timestamp '%s'", fields[2])
+	}
+
+	var msg string
+	if len(tokens) == 2 {
+		msg = tokens[1]
+	}
+
+	return RefLogEntry{oldHash, newHash, time.Unix(secs, 0), msg}, nil
+}
+
+func getRefLogPath(name string) string {
+	return filepath.Join(dbfactory.DoltDir, refLogDir, filepath.FromSlash(name))
+}
@@ -0,0 +1,203 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+func TestRefLog(t *testing.T) {
+	ctx := context.Background()
+	h1 := hash.Of([]byte("one"))
+	h2 := hash.Of([]byte("two"))
+	h3 := hash.Of([]byte("three"))
+
+	fs := filesys.NewInMemFS([]string{workingDir}, nil, workingDir)
+	masterRef := ref.NewBranchRef("master")
+	otherRef := ref.NewBranchRef("other")
+	rs := &RepoState{Head: ref.MarshalableRef{Ref: masterRef}, Working: h1.String(), Staged: h1.String(), fs: fs}
+	rs.fsLock = filesys.CreateFilesysLock(fs, getRepoStateLockFile())
+	rl := NewRefLog(fs, rs)
+
+	rl.SetMessage("dolt  commit\n-m one")
+	require.NoError(t, rl.RecordRefUpdate(ctx, masterRef, hash.Hash{}, h1))
+	rl.SetMessage("dolt branch other")
+	require.NoError(t, rl.RecordRefUpdate(ctx, otherRef, hash.Hash{}, h2))
+	rl.SetMessage("dolt commit -m two")
+	require.NoError(t, rl.RecordRefUpdate(ctx, masterRef, h1, h3))
+
+	entries, err := rl.GetEntries(masterRef.String())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, h1, entries[0].OldHash)
+	assert.Equal(t, h3, entries[0].NewHash)
+	assert.Equal(t, "dolt commit -m two", entries[0].Message)
+	assert.Equal(t, "dolt commit -m one", entries[1].Message)
+
+	headEntries, err := rl.GetEntries(doltdb.HeadRefLogName)
+	require.NoError(t, err)
+	assert.Len(t, headEntries, 2)
+
+	h, err := rl.LookupRefLogEntry(ctx, otherRef.String(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, h2, h)
+
+	h, err = rl.LookupRefLogEntry(ctx, doltdb.HeadRefLogName, 1)
+	require.NoError(t, err)
+	assert.Equal(t, h1, h)
+
+	_, err = rl.LookupRefLogEntry(ctx, doltdb.HeadRefLogName, 2)
+	assert.Equal(t, doltdb.ErrRefLogEntryNotFound, err)
+
+	rs.Working = h2.String()
+	require.NoError(t, rs.Save())
+	require.NoError(t, rs.Save())
+
+	workingEntries, err := rl.GetEntries(WorkingRefLogName)
+	require.NoError(t, err)
+	require.Len(t, workingEntries, 1)
+	assert.Equal(t, h1, workingEntries[0].OldHash)
+	assert.Equal(t, h2, workingEntries[0].NewHash)
+
+	stagedEntries, err := rl.GetEntries(StagedRefLogName)
+	require.NoError(t, err)
+	assert.Len(t, stagedEntries, 0)
+}
+
+func TestRefLogBlankLines(t *testing.T) {
+	ctx := context.Background()
+	h1 := hash.Of([]byte("one"))
+	h2 := hash.Of([]byte("two"))
+
+	fs := filesys.NewInMemFS([]string{workingDir}, nil, workingDir)
+	masterRef := ref.NewBranchRef("master")
+	rl := NewRefLog(fs, &RepoState{Head: ref.MarshalableRef{Ref: masterRef}, fs: fs})
+	path := getRefLogPath(masterRef.String())
+	require.NoError(t, fs.MkDirs(filepath.Dir(path)))
+
+	for _, contents := range []string{"", "\n", "  \n\t\n"} {
+		require.NoError(t, fs.WriteFile(path, []byte(contents)))
+
+		entries, err := rl.GetEntries(masterRef.String())
+		require.NoError(t, err)
+		assert.Len(t, entries, 0)
+
+		_, err = rl.LookupRefLogEntry(ctx, masterRef.String(), 0)
+		assert.Equal(t, doltdb.ErrRefLogEntryNotFound, err)
+	}
+
+	require.NoError(t, rl.RecordRefUpdate(ctx, masterRef, hash.Hash{}, h1))
+	data, err := fs.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, fs.WriteFile(path, append(data, []byte("\n\n")...)))
+	require.NoError(t, rl.RecordRefUpdate(ctx, masterRef, h1, h2))
+
+	entries, err := rl.GetEntries(masterRef.String())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, h2, entries[0].NewHash)
+	assert.Equal(t, h1, entries[1].NewHash)
+}
+
+func TestRefLogTagSpec(t *testing.T) {
+	ctx := context.Background()
+	fs := filesys.NewInMemFS([]string{workingDir}, nil, workingDir)
+	masterRef := ref.NewBranchRef("master")
+	rl := NewRefLog(fs, &RepoState{Head: ref.MarshalableRef{Ref: masterRef}, fs: fs})
+
+	ddb, err := doltdb.LoadDoltDB(ctx, types.Format_7_18, doltdb.InMemDoltDB)
+	require.NoError(t, err)
+	require.NoError(t, ddb.WriteEmptyRepo(ctx, "Bill Billerson", "bigbillieb@fake.horse"))
+	ddb.SetRefLog(rl)
+
+	cs, err := doltdb.NewCommitSpec("HEAD", masterRef.String())
+	require.NoError(t, err)
+	cm, err := ddb.Resolve(ctx, cs)
+	require.NoError(t, err)
+	cmHash, err := cm.HashOf()
+	require.NoError(t, err)
+
+	meta, err := doltdb.NewTagMeta("Bill Billerson", "bigbillieb@fake.horse", "first release")
+	require.NoError(t, err)
+	require.NoError(t, ddb.NewTagAtCommit(ctx, ref.NewTagRef("light"), cm, nil))
+	require.NoError(t, ddb.NewTagAtCommit(ctx, ref.NewTagRef("v1"), cm, meta))
+
+	for _, specStr := range []string{"refs/tags/light@{0}", "refs/tags/v1@{0}"} {
+		cs, err := doltdb.NewCommitSpec(specStr, masterRef.String())
+		require.NoError(t, err)
+
+		tagged, err := ddb.Resolve(ctx, cs)
+		require.NoError(t, err, specStr)
+
+		h, err := tagged.HashOf()
+		require.NoError(t, err)
+		assert.Equal(t, cmHash, h, specStr)
+	}
+}
+
+func TestRefLogConcurrentAppends(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "reflog_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs, err := filesys.LocalFilesysWithWorkingDir(dir)
+	require.NoError(t, err)
+
+	masterRef := ref.NewBranchRef("master")
+	otherRef := ref.NewBranchRef("other")
+
+	// each RefLog stands in for a different process, such as the sql-server and a command, updating the same log
+	const numLogs, numUpdates = 4, 50
+	wg := &sync.WaitGroup{}
+	errs := make([]error, numLogs)
+	for i := 0; i < numLogs; i++ {
+		rs := &RepoState{Head: ref.MarshalableRef{Ref: otherRef}, fs: fs}
+		rl := NewRefLog(fs, rs)
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < numUpdates && errs[i] == nil; j++ {
+				errs[i] = rl.RecordRefUpdate(ctx, masterRef, hash.Hash{}, hash.Of([]byte{byte(i), byte(j)}))
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	rl := NewRefLog(fs, &RepoState{fs: fs})
+	entries, err := rl.GetEntries(masterRef.String())
+	require.NoError(t, err)
+	assert.Len(t, entries, numLogs*numUpdates)
+}
@@ -31,7 +31,7 @@ type Remote struct {
 }
 
 func NewRemote(name, url string, params map[string]string) Remote {
-	return Remote{name, url, []string{"refs/heads/*:refs/remotes/" + name + "/*"}, params}
+	return Remote{name, url, []string{"refs/heads/*:refs/remotes/" + name + "/*", "refs/tags/*:refs/tags/*"}, params}
 }
 
 func (r *Remote) GetParam(pName string) (string, bool) {
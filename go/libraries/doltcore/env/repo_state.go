@@ -15,7 +15,15 @@
 package env
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/juju/fslock"
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
@@ -34,6 +42,25 @@ type MergeState struct {
 	PreMergeWorking string             `json:"working_pre_merge"`
 }
 
+// RebaseTodo is a single step of a rebase.  Action is one of the RebaseAction constants, and Commit is the hash of the
+// commit the action applies to.
+type RebaseTodo struct {
+	Action string `json:"action"`
+	Commit string `json:"commit"`
+}
+
+// RebaseState is the state of an in progress rebase.  Todo holds the steps which have not yet been run, and Current
+// holds the step which stopped because of conflicts, if any.  LastCommit is the hash of the last commit made by the
+// rebase, which is empty until a step has been committed.
+type RebaseState struct {
+	Branch     ref.MarshalableRef `json:"branch"`
+	OrigHead   string             `json:"orig_head"`
+	Onto       string             `json:"onto"`
+	Todo       []RebaseTodo       `json:"todo"`
+	Current    *RebaseTodo        `json:"current"`
+	LastCommit string             `json:"last_commit,omitempty"`
+}
+
 type RepoState struct {
 	Head     ref.MarshalableRef      `json:"head"`
 	Staged   string                  `json:"staged"`
@@ -41,11 +68,58 @@ type RepoState struct {
 	Merge    *MergeState             `json:"merge"`
 	Remotes  map[string]Remote       `json:"remotes"`
 	Branches map[string]BranchConfig `json:"branches"`
+	Rebase   *RebaseState            `json:"rebase"`
+
+	// Shallow holds the hashes of the shallow commits of a repository cloned or fetched with a limited depth, whose
+	// parents have not been fetched.
+	Shallow []string `json:"shallow,omitempty"`
+
+	fs     filesys.ReadWriteFS
+	refLog *RefLog
+
+	// loaded is the state as of the last time it was read from or written to disk.  Save uses it to tell which fields
+	// were changed by this process, so that the changes other processes made to the rest are kept.
+	loaded *RepoState
+
+	// detached is true for a copy of the repo state whose changes are kept in memory rather than saved.
+	detached bool
+
+	// lockMu serializes the goroutines of this process which take the repo state lock, and fsLock is the lock file which
+	// is shared with other processes.  lockGen identifies the current holder of the lock, and is 0 when it isn't held.
+	// It's guarded by lockGenMu.
+	lockMu    sync.Mutex
+	fsLock    filesys.FilesysLock
+	lockGenMu sync.Mutex
+	lockGen   uint64
+	nextGen   uint64
+}
+
+// ErrWorkingSetChanged is returned when saving a repo state whose working or staged root was changed by another process
+// since it was loaded, after being changed by this process as well.
+var ErrWorkingSetChanged = errors.New("the working set was changed by another process")
 
-	fs filesys.ReadWriteFS
+// repoStateLockKey is the key of the context value identifying the holder of a RepoState's lock.
+type repoStateLockKey struct {
+	rs *RepoState
 }
 
+// repoStateLockPollInterval is how long Lock waits between attempts to acquire the repo state lock file.
+const repoStateLockPollInterval = 10 * time.Millisecond
+
 func LoadRepoState(fs filesys.ReadWriteFS) (*RepoState, error) {
+	repoState, err := readRepoState(fs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	repoState.fsLock = filesys.CreateFilesysLock(fs, getRepoStateLockFile())
+
+	return repoState, nil
+}
+
+// readRepoState reads the repo state from disk without creating its lock.
+func readRepoState(fs filesys.ReadWriteFS) (*RepoState, error) {
 	path := getRepoStateFile()
 	data, err := fs.ReadFile(path)
 
@@ -60,7 +134,15 @@ func LoadRepoState(fs filesys.ReadWriteFS) (*RepoState, error) {
 		return nil, err
 	}
 
+	var loaded RepoState
+	err = json.Unmarshal(data, &loaded)
+
+	if err != nil {
+		return nil, err
+	}
+
 	repoState.fs = fs
+	repoState.loaded = &loaded
 
 	return &repoState, nil
 }
@@ -68,7 +150,14 @@ func LoadRepoState(fs filesys.ReadWriteFS) (*RepoState, error) {
 func CloneRepoState(fs filesys.ReadWriteFS, r Remote) (*RepoState, error) {
 	h := hash.Hash{}
 	hashStr := h.String()
-	rs := &RepoState{ref.MarshalableRef{Ref: ref.NewBranchRef("master")}, hashStr, hashStr, nil, map[string]Remote{r.Name: r}, nil, fs}
+	rs := &RepoState{
+		Head:    ref.MarshalableRef{Ref: ref.NewBranchRef("master")},
+		Staged:  hashStr,
+		Working: hashStr,
+		Remotes: map[string]Remote{r.Name: r},
+		fs:      fs,
+		fsLock:  filesys.CreateFilesysLock(fs, getRepoStateLockFile()),
+	}
 
 	err := rs.Save()
 
@@ -87,7 +176,13 @@ func CreateRepoState(fs filesys.ReadWriteFS, br string, rootHash hash.Hash) (*Re
 		return nil, err
 	}
 
-	rs := &RepoState{ref.MarshalableRef{Ref: headRef}, hashStr, hashStr, nil, nil, nil, fs}
+	rs := &RepoState{
+		Head:    ref.MarshalableRef{Ref: headRef},
+		Staged:  hashStr,
+		Working: hashStr,
+		fs:      fs,
+		fsLock:  filesys.CreateFilesysLock(fs, getRepoStateLockFile()),
+	}
 
 	err = rs.Save()
 
@@ -98,16 +193,309 @@ func CreateRepoState(fs filesys.ReadWriteFS, br string, rootHash hash.Hash) (*Re
 	return rs, nil
 }
 
+// ShallowCommits returns the hashes of the shallow commits of the repository.
+func (rs *RepoState) ShallowCommits() hash.HashSet {
+	hashes := hash.NewHashSet()
+	for _, hashStr := range rs.Shallow {
+		hashes.Insert(hash.Parse(hashStr))
+	}
+
+	return hashes
+}
+
+// SetShallowCommits sets the hashes of the shallow commits of the repository.  The repo state must be saved for the
+// change to be persisted.
+func (rs *RepoState) SetShallowCommits(hashes hash.HashSet) {
+	rs.Shallow = nil
+	for h := range hashes {
+		rs.Shallow = append(rs.Shallow, h.String())
+	}
+
+	sort.Strings(rs.Shallow)
+}
+
+// Save writes the repo state to disk.  It's the same as SaveContext called with a context that doesn't hold the repo
+// state lock.
 func (rs *RepoState) Save() error {
+	return rs.SaveContext(context.Background())
+}
+
+// SaveContext writes the repo state to disk.  If ctx is a context returned by Lock and the lock is still held, the state
+// is written under that lock.  Otherwise the lock is acquired for the duration of the write, waiting for any other
+// holder to release it.  The state on disk is reread with the lock held, and only the fields which were changed by this
+// process since it was loaded are written, so the changes made to other fields by other processes, such as a running
+// sql-server, are kept.  ErrWorkingSetChanged is returned if both changed the working or staged root.
+func (rs *RepoState) SaveContext(ctx context.Context) error {
+	if rs.detached {
+		return nil
+	}
+
+	if !rs.holdsLock(ctx) {
+		if _, err := rs.Lock(ctx); err != nil {
+			return err
+		}
+
+		defer rs.Unlock()
+	}
+
+	if onDisk, err := readRepoState(rs.fs); err == nil {
+		if err := rs.applyChangesTo(onDisk); err != nil {
+			return err
+		}
+	}
+
 	data, err := json.MarshalIndent(rs, "", "  ")
 
 	if err != nil {
 		return err
 	}
 
+	// the state is written to a temporary file which replaces the old one, so that processes reading it without holding
+	// the lock never see a partially written file
 	path := getRepoStateFile()
+	tmpPath := path + ".tmp"
+	err = rs.fs.WriteFile(tmpPath, data)
+
+	if err != nil {
+		return err
+	}
 
-	return rs.fs.WriteFile(path, data)
+	err = rs.fs.MoveFile(tmpPath, path)
+
+	if err != nil {
+		return err
+	}
+
+	var loaded RepoState
+	err = json.Unmarshal(data, &loaded)
+
+	if err != nil {
+		return err
+	}
+
+	rs.loaded = &loaded
+
+	if rs.refLog != nil {
+		return rs.refLog.recordWorkingSetUpdate(rs.Working, rs.Staged)
+	}
+
+	return nil
+}
+
+// applyChangesTo replaces each field of the repo state which has not been changed since it was loaded with its value in
+// the state on disk.  Remotes and branch configs are merged by name.
+func (rs *RepoState) applyChangesTo(onDisk *RepoState) error {
+	loaded := rs.loaded
+
+	if loaded == nil {
+		return nil
+	}
+
+	for _, roots := range [][3]string{{rs.Working, loaded.Working, onDisk.Working}, {rs.Staged, loaded.Staged, onDisk.Staged}} {
+		mine, base, theirs := roots[0], roots[1], roots[2]
+
+		if mine != base && theirs != base && mine != theirs {
+			return ErrWorkingSetChanged
+		}
+	}
+
+	if rs.Working == loaded.Working {
+		rs.Working = onDisk.Working
+	}
+
+	if rs.Staged == loaded.Staged {
+		rs.Staged = onDisk.Staged
+	}
+
+	if jsonEqual(rs.Head, loaded.Head) {
+		rs.Head = onDisk.Head
+	}
+
+	if jsonEqual(rs.Merge, loaded.Merge) {
+		rs.Merge = onDisk.Merge
+	}
+
+	if jsonEqual(rs.Rebase, loaded.Rebase) {
+		rs.Rebase = onDisk.Rebase
+	}
+
+	if jsonEqual(rs.Shallow, loaded.Shallow) {
+		rs.Shallow = onDisk.Shallow
+	}
+
+	remotes := onDisk.Remotes
+	if remotes == nil {
+		remotes = make(map[string]Remote)
+	}
+
+	for name, r := range rs.Remotes {
+		if base, ok := loaded.Remotes[name]; !ok || !jsonEqual(r, base) {
+			remotes[name] = r
+		}
+	}
+
+	for name := range loaded.Remotes {
+		if _, ok := rs.Remotes[name]; !ok {
+			delete(remotes, name)
+		}
+	}
+
+	branches := onDisk.Branches
+	if branches == nil {
+		branches = make(map[string]BranchConfig)
+	}
+
+	for name, br := range rs.Branches {
+		if base, ok := loaded.Branches[name]; !ok || !jsonEqual(br, base) {
+			branches[name] = br
+		}
+	}
+
+	for name := range loaded.Branches {
+		if _, ok := rs.Branches[name]; !ok {
+			delete(branches, name)
+		}
+	}
+
+	rs.Remotes = remotes
+	rs.Branches = branches
+
+	return nil
+}
+
+// jsonEqual returns whether the two values given are encoded as the same json.
+func jsonEqual(a, b interface{}) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+// Detached returns a copy of the repo state whose changes are kept in memory.  Saving it does nothing, so changes to its
+// head and working set are never seen by other users of the repository.
+func (rs *RepoState) Detached() *RepoState {
+	detached := &RepoState{
+		Head:     rs.Head,
+		Staged:   rs.Staged,
+		Working:  rs.Working,
+		Remotes:  make(map[string]Remote, len(rs.Remotes)),
+		Branches: make(map[string]BranchConfig, len(rs.Branches)),
+		Shallow:  rs.Shallow,
+		fs:       rs.fs,
+		detached: true,
+	}
+
+	if rs.Merge != nil {
+		merge := *rs.Merge
+		detached.Merge = &merge
+	}
+
+	for name, r := range rs.Remotes {
+		detached.Remotes[name] = r
+	}
+
+	for name, br := range rs.Branches {
+		detached.Branches[name] = br
+	}
+
+	return detached
+}
+
+// Reload rereads the repo state from disk, picking up any changes made by other processes and discarding any changes
+// which haven't been saved.
+func (rs *RepoState) Reload() error {
+	onDisk, err := readRepoState(rs.fs)
+
+	if err != nil {
+		return err
+	}
+
+	rs.Head = onDisk.Head
+	rs.Staged = onDisk.Staged
+	rs.Working = onDisk.Working
+	rs.Merge = onDisk.Merge
+	rs.Remotes = onDisk.Remotes
+	rs.Branches = onDisk.Branches
+	rs.Rebase = onDisk.Rebase
+	rs.Shallow = onDisk.Shallow
+	rs.loaded = onDisk.loaded
+
+	return nil
+}
+
+// ChangedOnDisk returns whether the repo state on disk was changed by another process since it was last loaded or saved.
+func (rs *RepoState) ChangedOnDisk() (bool, error) {
+	onDisk, err := readRepoState(rs.fs)
+
+	if err != nil {
+		return false, err
+	}
+
+	return !jsonEqual(onDisk.loaded, rs.loaded), nil
+}
+
+// Lock blocks until the repo state lock is acquired or the context is canceled.  The lock is shared by every process
+// using the repository, so a process which holds it can read, modify and save the repo state without another process
+// clobbering its changes.  The returned context identifies the caller as the holder of the lock, and must be passed to
+// SaveContext, directly or through the DoltEnv methods which update the working set, for saves made while the lock is
+// held.  Saves made with any other context wait until the lock is released.
+func (rs *RepoState) Lock(ctx context.Context) (context.Context, error) {
+	rs.lockMu.Lock()
+
+	for {
+		ok, err := rs.fsLock.TryLock()
+
+		if err != nil && err != fslock.ErrLocked {
+			rs.lockMu.Unlock()
+			return nil, err
+		} else if ok {
+			return context.WithValue(ctx, repoStateLockKey{rs}, rs.setHolder()), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			rs.lockMu.Unlock()
+			return nil, ctx.Err()
+		case <-time.After(repoStateLockPollInterval):
+		}
+	}
+}
+
+// Unlock releases the repo state lock acquired by Lock.
+func (rs *RepoState) Unlock() error {
+	rs.lockGenMu.Lock()
+	rs.lockGen = 0
+	rs.lockGenMu.Unlock()
+
+	err := rs.fsLock.Unlock()
+	rs.lockMu.Unlock()
+
+	return err
+}
+
+// setHolder records that the lock has been acquired by a new holder, and returns the generation identifying it.
+func (rs *RepoState) setHolder() uint64 {
+	rs.lockGenMu.Lock()
+	defer rs.lockGenMu.Unlock()
+
+	rs.nextGen++
+	rs.lockGen = rs.nextGen
+
+	return rs.lockGen
+}
+
+// holdsLock returns whether ctx was returned by the call to Lock which acquired the lock that is currently held.
+func (rs *RepoState) holdsLock(ctx context.Context) bool {
+	gen, ok := ctx.Value(repoStateLockKey{rs}).(uint64)
+
+	if !ok {
+		return false
+	}
+
+	rs.lockGenMu.Lock()
+	defer rs.lockGenMu.Unlock()
+
+	return gen == rs.lockGen
 }
 
 func (rs *RepoState) CWBHeadSpec() *doltdb.CommitSpec {
@@ -131,6 +519,16 @@ func (rs *RepoState) ClearMerge() error {
 	return rs.Save()
 }
 
+func (rs *RepoState) StartRebase(branch ref.DoltRef, origHead, onto string, todo []RebaseTodo) error {
+	rs.Rebase = &RebaseState{ref.MarshalableRef{Ref: branch}, origHead, onto, todo, nil, ""}
+	return rs.Save()
+}
+
+func (rs *RepoState) ClearRebase() error {
+	rs.Rebase = nil
+	return rs.Save()
+}
+
 func (rs *RepoState) AddRemote(r Remote) {
 	if rs.Remotes == nil {
 		rs.Remotes = make(map[string]Remote)
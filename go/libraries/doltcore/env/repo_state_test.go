@@ -0,0 +1,186 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dbfactory"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+)
+
+func TestRepoStateLocking(t *testing.T) {
+	ctx := context.Background()
+	fs, cleanup := newRepoStateTestFS(t)
+	defer cleanup()
+
+	// each RepoState stands in for a different process, and each is shared by several goroutines, as it is by the
+	// connections of a sql-server
+	const numStates, numGoroutines, numUpdates = 2, 4, 10
+	wg := &sync.WaitGroup{}
+	errs := make([]error, numStates*numGoroutines)
+	for i := 0; i < numStates; i++ {
+		rs, err := LoadRepoState(fs)
+		require.NoError(t, err)
+
+		for j := 0; j < numGoroutines; j++ {
+			wg.Add(1)
+			go func(rs *RepoState, id int) {
+				defer wg.Done()
+				for k := 0; k < numUpdates && errs[id] == nil; k++ {
+					errs[id] = addRemoteLocked(ctx, rs, fmt.Sprintf("remote_%d_%d", id, k))
+				}
+			}(rs, i*numGoroutines+j)
+		}
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	rs, err := LoadRepoState(fs)
+	require.NoError(t, err)
+	assert.Len(t, rs.Remotes, numStates*numGoroutines*numUpdates)
+}
+
+func TestRepoStateSaveKeepsOtherChanges(t *testing.T) {
+	fs, cleanup := newRepoStateTestFS(t)
+	defer cleanup()
+	h1, h2 := hash.Of([]byte("one")), hash.Of([]byte("two"))
+
+	// neither process holds the lock while it modifies the repo state, only while saving it
+	rs1, err := LoadRepoState(fs)
+	require.NoError(t, err)
+	rs2, err := LoadRepoState(fs)
+	require.NoError(t, err)
+
+	rs1.AddRemote(NewRemote("one", "file:///one", nil))
+	rs1.Working = h1.String()
+	require.NoError(t, rs1.Save())
+
+	rs2.AddRemote(NewRemote("two", "file:///two", nil))
+	rs2.Staged = h2.String()
+	require.NoError(t, rs2.Save())
+
+	rs, err := LoadRepoState(fs)
+	require.NoError(t, err)
+	assert.Len(t, rs.Remotes, 2)
+	assert.Equal(t, h1.String(), rs.Working)
+	assert.Equal(t, h2.String(), rs.Staged)
+
+	// both processes changing the working root is a conflict
+	rs2.Working = h2.String()
+	rs.Working = hash.Hash{}.String()
+	require.NoError(t, rs.Save())
+	assert.Equal(t, ErrWorkingSetChanged, rs2.Save())
+}
+
+func TestRepoStateLockHolder(t *testing.T) {
+	ctx := context.Background()
+	fs, cleanup := newRepoStateTestFS(t)
+	defer cleanup()
+	rs, err := LoadRepoState(fs)
+	require.NoError(t, err)
+
+	lockCtx, err := rs.Lock(ctx)
+	require.NoError(t, err)
+
+	// the holder saves under the lock it holds
+	rs.AddRemote(NewRemote("one", "file:///one", nil))
+	require.NoError(t, rs.SaveContext(lockCtx))
+
+	// anyone else waits for the lock to be released
+	saved := make(chan error)
+	go func() {
+		saved <- rs.Save()
+	}()
+
+	select {
+	case err := <-saved:
+		t.Fatalf("saved while the lock was held by another caller: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, rs.Unlock())
+	require.NoError(t, <-saved)
+
+	// a context stops identifying the holder once the lock is released, even when the lock is acquired again
+	otherCtx, err := rs.Lock(ctx)
+	require.NoError(t, err)
+
+	go func() {
+		saved <- rs.SaveContext(lockCtx)
+	}()
+
+	select {
+	case err := <-saved:
+		t.Fatalf("saved with the context of a released lock: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, rs.SaveContext(otherCtx))
+	require.NoError(t, rs.Unlock())
+	require.NoError(t, <-saved)
+}
+
+// newRepoStateTestFS returns a filesystem in a new temporary directory holding a repo state, and a function which
+// removes the directory.
+func newRepoStateTestFS(t *testing.T) (filesys.Filesys, func()) {
+	dir, err := ioutil.TempDir("", "repo_state_test")
+	require.NoError(t, err)
+
+	fs, err := filesys.LocalFilesysWithWorkingDir(dir)
+	require.NoError(t, err)
+	require.NoError(t, fs.MkDirs(dbfactory.DoltDir))
+
+	_, err = CreateRepoState(fs, "refs/heads/master", hash.Hash{})
+	require.NoError(t, err)
+
+	return fs, func() { os.RemoveAll(dir) }
+}
+
+// addRemoteLocked performs a read-modify-write of the repo state with the lock held, as a command does.
+func addRemoteLocked(ctx context.Context, rs *RepoState, name string) error {
+	lockCtx, err := rs.Lock(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	err = rs.Reload()
+
+	if err == nil {
+		rs.AddRemote(NewRemote(name, "file:///"+name, nil))
+		err = rs.SaveContext(lockCtx)
+	}
+
+	if unlockErr := rs.Unlock(); err == nil {
+		err = unlockErr
+	}
+
+	return err
+}
@@ -0,0 +1,257 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/rowconv"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/set"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// AddForeignKeyConflicts validates the foreign keys of the tables in mergedRoot, the result of merging the tables of
+// the merger, and marks every row of a child table which references a parent row that no longer exists as a conflict.
+// Each side of the merge may be valid on its own, e.g. when one branch deletes a parent row and the other adds a
+// child row which references it, so rows are checked against the changes made to our root by the merge.  The stats
+// of the child tables are updated with the number of foreign key conflicts found.
+func (merger *Merger) AddForeignKeyConflicts(ctx context.Context, mergedRoot *doltdb.RootValue, tblToStats map[string]*MergeStats) (*doltdb.RootValue, error) {
+	ourNames, err := merger.root.GetTableNames(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mergedNames, err := mergedRoot.GetTableNames(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// tables removed by the merge must be checked for rows that reference them
+	tblNames := mergedNames
+	mergedNameSet := set.NewStrSet(mergedNames)
+	for _, tblName := range ourNames {
+		if !mergedNameSet.Contains(tblName) {
+			tblNames = append(tblNames, tblName)
+		}
+	}
+
+	var childNames []string
+	violatingKeys := make(map[string][]types.Tuple)
+	for _, tblName := range tblNames {
+		ourTbl, _, err := merger.root.GetTable(ctx, tblName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		violations, err := mergedRoot.ForeignKeyViolations(ctx, tblName, ourTbl)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, violation := range violations {
+			if _, ok := violatingKeys[violation.Table]; !ok {
+				childNames = append(childNames, violation.Table)
+			}
+
+			violatingKeys[violation.Table] = append(violatingKeys[violation.Table], violation.Key)
+		}
+	}
+
+	for _, childName := range childNames {
+		tbl, _, err := mergedRoot.GetTable(ctx, childName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		// rows of a table with schema conflicts weren't merged, so they're already in conflict
+		if has, err := tbl.HasSchemaConflicts(); err != nil {
+			return nil, err
+		} else if has {
+			continue
+		}
+
+		tbl, added, err := merger.addForeignKeyConflicts(ctx, childName, tbl, violatingKeys[childName])
+
+		if err != nil {
+			return nil, err
+		}
+
+		if added == 0 {
+			continue
+		}
+
+		mergedRoot, err = mergedRoot.PutTable(ctx, childName, tbl)
+
+		if err != nil {
+			return nil, err
+		}
+
+		stats, ok := tblToStats[childName]
+
+		if !ok {
+			stats = &MergeStats{}
+			tblToStats[childName] = stats
+		}
+
+		stats.Operation = TableModified
+		stats.Conflicts += added
+		stats.ForeignKeyConflicts += added
+	}
+
+	return mergedRoot, nil
+}
+
+// addForeignKeyConflicts adds a conflict to the merged table for each of the keys which isn't already in conflict,
+// returning the updated table and the number of conflicts added.
+func (merger *Merger) addForeignKeyConflicts(ctx context.Context, tblName string, tbl *doltdb.Table, keys []types.Tuple) (*doltdb.Table, int, error) {
+	sch, err := tbl.GetSchema(ctx)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	schemas, conflicts, err := tbl.GetConflicts(ctx)
+
+	if err == doltdb.ErrNoConflicts {
+		sr, err := tbl.GetSchemaRef()
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		schemas = doltdb.NewConflict(sr, sr, sr)
+		conflicts, err = types.NewMap(ctx, merger.vrw)
+
+		if err != nil {
+			return nil, 0, err
+		}
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	added := 0
+	me := conflicts.Edit()
+	for _, key := range keys {
+		if has, err := conflicts.Has(ctx, key); err != nil {
+			return nil, 0, err
+		} else if has {
+			continue
+		}
+
+		var vals [3]types.Value
+		for i, root := range []*doltdb.RootValue{merger.ancRoot, merger.root, merger.mergeRoot} {
+			vals[i], err = getRowInSchema(ctx, root, tblName, key, sch)
+
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+
+		conflictTuple, err := doltdb.NewConflict(vals[0], vals[1], vals[2]).ToNomsList(merger.vrw)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		me.Set(key, conflictTuple)
+		added++
+	}
+
+	if added == 0 {
+		return tbl, 0, nil
+	}
+
+	conflicts, err = me.Map(ctx)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tbl, err = tbl.SetConflicts(ctx, schemas, conflicts)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return tbl, added, nil
+}
+
+// getRowInSchema returns the value of the row with the given key in the named table of the root, converted to
+// destSch.  nil is returned if the table or the row doesn't exist.
+func getRowInSchema(ctx context.Context, root *doltdb.RootValue, tblName string, key types.Tuple, destSch schema.Schema) (types.Value, error) {
+	tbl, ok, err := root.GetTable(ctx, tblName)
+
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	rowData, err := tbl.GetRowData(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	val, ok, err := rowData.MaybeGet(ctx, key)
+
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	srcSch, err := tbl.GetSchema(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if eq, err := schema.SchemasAreEqual(srcSch, destSch); err != nil {
+		return nil, err
+	} else if eq {
+		return val, nil
+	}
+
+	mapping, err := rowconv.TagMapping(srcSch, destSch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rConv, err := rowconv.NewRowConverter(mapping)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := row.FromNoms(srcSch, key, val.(types.Tuple))
+
+	if err != nil {
+		return nil, err
+	}
+
+	r, err = rConv.Convert(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r.NomsMapValue(destSch).Value(ctx)
+}
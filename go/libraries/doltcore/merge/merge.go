@@ -23,23 +23,25 @@ import (
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/rowconv"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/encoding"
-	"github.com/liquidata-inc/dolt/go/libraries/doltcore/table/typed"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/valutil"
 	"github.com/liquidata-inc/dolt/go/store/types"
 )
 
 var ErrFastForward = errors.New("fast forward")
 var ErrSameTblAddedTwice = errors.New("table with same name added in 2 commits can't be merged")
+var ErrTblDeletedAndModified = errors.New("table was deleted in one commit and modified in the other")
 
 type Merger struct {
-	commit      *doltdb.Commit
-	mergeCommit *doltdb.Commit
-	ancestor    *doltdb.Commit
-	vrw         types.ValueReadWriter
+	root      *doltdb.RootValue
+	mergeRoot *doltdb.RootValue
+	ancRoot   *doltdb.RootValue
+	vrw       types.ValueReadWriter
 }
 
+// NewMerger creates a new merger that merges mergeCommit into commit using their common ancestor as the merge base.
 func NewMerger(ctx context.Context, commit, mergeCommit *doltdb.Commit, vrw types.ValueReadWriter) (*Merger, error) {
 	ancestor, err := doltdb.GetCommitAncestor(ctx, commit, mergeCommit)
 
@@ -53,28 +55,40 @@ func NewMerger(ctx context.Context, commit, mergeCommit *doltdb.Commit, vrw type
 	} else if ff {
 		return nil, ErrFastForward
 	}
-	return &Merger{commit, mergeCommit, ancestor, vrw}, nil
-}
 
-func (merger *Merger) MergeTable(ctx context.Context, tblName string) (*doltdb.Table, *MergeStats, error) {
-	root, err := merger.commit.GetRootValue()
+	root, err := commit.GetRootValue()
 
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	mergeRoot, err := merger.mergeCommit.GetRootValue()
+	mergeRoot, err := mergeCommit.GetRootValue()
 
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	ancRoot, err := merger.ancestor.GetRootValue()
+	ancRoot, err := ancestor.GetRootValue()
 
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
+	return &Merger{root, mergeRoot, ancRoot, vrw}, nil
+}
+
+// NewMergerForRoots creates a new merger which merges the changes made between ancRoot and mergeRoot into root.  Unlike
+// NewMerger, the merge base is supplied by the caller rather than computed from the commit graph, which allows for
+// operations such as cherry-pick, where the base is the parent of the commit being applied.
+func NewMergerForRoots(root, mergeRoot, ancRoot *doltdb.RootValue, vrw types.ValueReadWriter) *Merger {
+	return &Merger{root, mergeRoot, ancRoot, vrw}
+}
+
+func (merger *Merger) MergeTable(ctx context.Context, tblName string) (*doltdb.Table, *MergeStats, error) {
+	root := merger.root
+	mergeRoot := merger.mergeRoot
+	ancRoot := merger.ancRoot
+
 	tbl, ok, err := root.GetTable(ctx, tblName)
 
 	if err != nil {
@@ -135,9 +149,21 @@ func (merger *Merger) MergeTable(ctx context.Context, tblName string) (*doltdb.T
 	}
 
 	if h == anch {
-		return mergeTbl, &MergeStats{Operation: TableModified}, nil
+		stats := &MergeStats{Operation: TableModified}
+
+		if ok && mergeOk {
+			stats, err = calcTableDiffStats(ctx, tbl, mergeTbl)
+
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		return mergeTbl, stats, nil
 	} else if mh == anch {
 		return tbl, &MergeStats{Operation: TableUnmodified}, nil
+	} else if !ok || !mergeOk {
+		return nil, nil, ErrTblDeletedAndModified
 	}
 
 	tblSchema, err := tbl.GetSchema(ctx)
@@ -152,85 +178,257 @@ func (merger *Merger) MergeTable(ctx context.Context, tblName string) (*doltdb.T
 		return nil, nil, err
 	}
 
-	schemaUnion, err := typed.TypedSchemaUnion(tblSchema, mergeTblSchema)
+	ancTblSchema, err := ancTbl.GetSchema(ctx)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	rows, err := tbl.GetRowData(ctx)
+	mergedSch, schConflicts, err := MergeSchemas(ancTblSchema, tblSchema, mergeTblSchema)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	mergeRows, err := mergeTbl.GetRowData(ctx)
+	if len(schConflicts) > 0 {
+		return merger.setSchemaConflicts(ctx, tbl, mergeTbl, ancTbl, schConflicts)
+	}
+
+	tbls := []*doltdb.Table{tbl, mergeTbl, ancTbl}
+	schemas := []schema.Schema{tblSchema, mergeTblSchema, ancTblSchema}
+	rowData, err := getRowDataInSchema(ctx, merger.vrw, mergedSch, tbls, schemas)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	ancRows, err := ancTbl.GetRowData(ctx)
+	rows, mergeRows, ancRows := rowData[0], rowData[1], rowData[2]
+
+	mergedRowData, conflicts, stats, err := mergeTableData(ctx, mergedSch, rows, mergeRows, ancRows, merger.vrw)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	mergedRowData, conflicts, stats, err := mergeTableData(ctx, schemaUnion, rows, mergeRows, ancRows, merger.vrw)
+	mergedSchVal, err := encoding.MarshalAsNomsValue(ctx, merger.vrw, mergedSch)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	schUnionVal, err := encoding.MarshalAsNomsValue(ctx, merger.vrw, schemaUnion)
+	mergedTable, err := doltdb.NewTable(ctx, merger.vrw, mergedSchVal, mergedRowData)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	mergedTable, err := doltdb.NewTable(ctx, merger.vrw, schUnionVal, mergedRowData)
+	// the merged table's auto increment counter must be past every value generated on either side
+	for _, t := range []*doltdb.Table{tbl, mergeTbl} {
+		mergedTable, err = mergedTable.CopyAutoIncrementValue(t)
+
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	mergedTable, err = mergedTable.RebuildIndexes(ctx)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
 	if conflicts.Len() > 0 {
+		// all versions of the conflicting rows have been converted to the merged schema
+		msr, err := mergedTable.GetSchemaRef()
 
 		if err != nil {
 			return nil, nil, err
 		}
 
+		schemas := doltdb.NewConflict(msr, msr, msr)
+		mergedTable, err = mergedTable.SetConflicts(ctx, schemas, conflicts)
+
 		if err != nil {
 			return nil, nil, err
 		}
+	}
+
+	return mergedTable, stats, nil
+}
+
+// setSchemaConflicts returns our version of the table marked with the schema conflicts found while merging it.  Rows
+// are not merged, as there is no schema to merge them into until the schema conflicts are resolved.
+func (merger *Merger) setSchemaConflicts(ctx context.Context, tbl, mergeTbl, ancTbl *doltdb.Table, schConflicts []SchemaConflict) (*doltdb.Table, *MergeStats, error) {
+	asr, err := ancTbl.GetSchemaRef()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sr, err := tbl.GetSchemaRef()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msr, err := mergeTbl.GetSchemaRef()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	kvps := make([]types.Value, 0, 2*len(schConflicts))
+	for _, cnf := range schConflicts {
+		kvps = append(kvps, types.Uint(cnf.Tag), types.String(cnf.Description))
+	}
+
+	schConflictMap, err := types.NewMap(ctx, merger.vrw, kvps...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mergeRows, err := mergeTbl.GetRowData(ctx)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	schemas := doltdb.NewConflict(asr, sr, msr)
+	conflictedTbl, err := tbl.SetSchemaConflicts(ctx, schemas, schConflictMap, mergeRows)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stats := &MergeStats{Operation: TableModified, Conflicts: len(schConflicts), SchemaConflicts: len(schConflicts)}
+	return conflictedTbl, stats, nil
+}
+
+// getRowDataInSchema returns the row data of each of the tables converted from the table's schema to |destSch|.  Dropping
+// a column doesn't remove its values from existing rows, so unless all the schemas are equal to |destSch| every row is
+// rewritten so that the rows of the different tables can be compared.
+func getRowDataInSchema(ctx context.Context, vrw types.ValueReadWriter, destSch schema.Schema, tbls []*doltdb.Table, schemas []schema.Schema) ([]types.Map, error) {
+	rowData := make([]types.Map, len(tbls))
+	convert := false
+	for i, tbl := range tbls {
+		var err error
+		rowData[i], err = tbl.GetRowData(ctx)
 
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
-		asr, err := ancTbl.GetSchemaRef()
+		if eq, err := schema.SchemasAreEqual(schemas[i], destSch); err != nil {
+			return nil, err
+		} else if !eq {
+			convert = true
+		}
+	}
+
+	if !convert {
+		return rowData, nil
+	}
+
+	for i := range rowData {
+		var err error
+		rowData[i], err = convertRowData(ctx, vrw, schemas[i], destSch, rowData[i])
 
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
+	}
+
+	return rowData, nil
+}
+
+func convertRowData(ctx context.Context, vrw types.ValueReadWriter, srcSch, destSch schema.Schema, rowData types.Map) (types.Map, error) {
+	mapping, err := rowconv.TagMapping(srcSch, destSch)
+
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	rConv, err := rowconv.NewRowConverter(mapping)
 
-		sr, err := tbl.GetSchemaRef()
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	converted, err := types.NewMap(ctx, vrw)
+
+	if err != nil {
+		return types.EmptyMap, err
+	}
+
+	me := converted.Edit()
+	err = rowData.Iter(ctx, func(key, value types.Value) (stop bool, err error) {
+		r, err := row.FromNoms(srcSch, key.(types.Tuple), value.(types.Tuple))
 
 		if err != nil {
-			return nil, nil, err
+			return true, err
 		}
 
-		msr, err := mergeTbl.GetSchemaRef()
+		r, err = rConv.Convert(r)
 
 		if err != nil {
-			return nil, nil, err
+			return true, err
 		}
 
-		schemas := doltdb.NewConflict(asr, sr, msr)
-		mergedTable, err = mergedTable.SetConflicts(ctx, schemas, conflicts)
+		me.Set(r.NomsMapKey(destSch), r.NomsMapValue(destSch))
+		return false, nil
+	})
+
+	if err != nil {
+		return types.EmptyMap, err
 	}
 
-	return mergedTable, stats, nil
+	return me.Map(ctx)
+}
+
+// calcTableDiffStats returns the stats for a merge in which the rows of |tbl| are replaced by the rows of |mergeTbl|
+// because |tbl| is unchanged from the merge base.
+func calcTableDiffStats(ctx context.Context, tbl, mergeTbl *doltdb.Table) (*MergeStats, error) {
+	rows, err := tbl.GetRowData(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mergeRows, err := mergeTbl.GetRowData(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ae := atomicerr.New()
+	changeChan := make(chan types.ValueChanged, 32)
+	stopChan := make(chan struct{}, 1)
+
+	go func() {
+		mergeRows.Diff(ctx, rows, ae, changeChan, stopChan)
+		close(changeChan)
+	}()
+
+	defer stopAndDrain(stopChan, changeChan)
+
+	stats := &MergeStats{Operation: TableModified}
+	for change := range changeChan {
+		switch change.ChangeType {
+		case types.DiffChangeAdded:
+			stats.Adds++
+		case types.DiffChangeRemoved:
+			stats.Deletes++
+		case types.DiffChangeModified:
+			stats.Modifications++
+		}
+	}
+
+	if err := ae.Get(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
 }
 
 func stopAndDrain(stop chan<- struct{}, drain <-chan types.ValueChanged) {
@@ -327,7 +525,7 @@ func mergeTableData(ctx context.Context, sch schema.Schema, rows, mergeRows, anc
 
 			if !processed {
 				r, mergeRow, ancRow := change.NewValue, mergeChange.NewValue, change.OldValue
-				mergedRow, isConflict, err := rowMerge(ctx, vrw.Format(), sch, r, mergeRow, ancRow)
+				mergedRow, isConflict, err := rowMerge(ctx, vrw.Format(), sch, r, mergeRow, ancRow, stats)
 
 				if err != nil {
 					return err
@@ -393,7 +591,10 @@ func applyChange(me *types.MapEditor, stats *MergeStats, change types.ValueChang
 	}
 }
 
-func rowMerge(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema, r, mergeRow, baseRow types.Value) (types.Value, bool, error) {
+// rowMerge merges the changes made to a row on both sides of a merge.  Cells which were changed differently on both sides
+// are resolved using the column's merge policy, and if any of them can't be resolved the row is in conflict.  The number of
+// cells resolved by merge policies is added to stats when the row merges successfully.
+func rowMerge(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema, r, mergeRow, baseRow types.Value, stats *MergeStats) (types.Value, bool, error) {
 	var baseVals row.TaggedValues
 	if baseRow == nil {
 		if r.Equals(mergeRow) {
@@ -427,23 +628,31 @@ func rowMerge(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema,
 		return nil, false, err
 	}
 
-	processTagFunc := func(tag uint64) (resultVal types.Value, isConflict bool) {
-		baseVal, _ := baseVals.Get(tag)
-		val, _ := rowVals.Get(tag)
-		mergeVal, _ := mergeVals.Get(tag)
+	policyResolved := 0
+	processTagFunc := func(col schema.Column) (resultVal types.Value, isConflict bool, err error) {
+		baseVal, _ := baseVals.Get(col.Tag)
+		val, _ := rowVals.Get(col.Tag)
+		mergeVal, _ := mergeVals.Get(col.Tag)
 
 		if valutil.NilSafeEqCheck(val, mergeVal) {
-			return val, false
+			return val, false, nil
 		} else {
 			modified := !valutil.NilSafeEqCheck(val, baseVal)
 			mergeModified := !valutil.NilSafeEqCheck(mergeVal, baseVal)
 			switch {
 			case modified && mergeModified:
-				return nil, true
+				resolved, ok, err := applyMergePolicy(nbf, sch, col, baseVal, val, mergeVal, rowVals, mergeVals)
+
+				if err != nil || !ok {
+					return nil, !ok, err
+				}
+
+				policyResolved++
+				return resolved, false, nil
 			case modified:
-				return val, false
+				return val, false, nil
 			default:
-				return mergeVal, false
+				return mergeVal, false, nil
 			}
 		}
 
@@ -452,12 +661,12 @@ func rowMerge(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema,
 	resultVals := make(row.TaggedValues)
 
 	var isConflict bool
-	err = sch.GetNonPKCols().Iter(func(tag uint64, _ schema.Column) (stop bool, err error) {
+	err = sch.GetNonPKCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
 		var val types.Value
-		val, isConflict = processTagFunc(tag)
+		val, isConflict, err = processTagFunc(col)
 		resultVals[tag] = val
 
-		return isConflict, nil
+		return isConflict, err
 	})
 
 	if err != nil {
@@ -468,6 +677,8 @@ func rowMerge(ctx context.Context, nbf *types.NomsBinFormat, sch schema.Schema,
 		return nil, true, nil
 	}
 
+	stats.PolicyResolutions += policyResolved
+
 	tpl := resultVals.NomsTupleForTags(nbf, sch.GetNonPKCols().SortedTags, false)
 	v, err := tpl.Value(ctx)
 
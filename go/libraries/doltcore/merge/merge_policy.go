@@ -0,0 +1,116 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"math"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// applyMergePolicy attempts to merge a cell of the column col which was changed to val on our side and mergeVal on their
+// side using the merge policy of the column, or the default merge policy of the schema sch if the column doesn't have
+// one.  rowVals and mergeVals are the values of our and their versions of the row. The returned bool is false if the
+// policy can't resolve the change, in which case the cell is in conflict.
+func applyMergePolicy(nbf *types.NomsBinFormat, sch schema.Schema, col schema.Column, baseVal, val, mergeVal types.Value, rowVals, mergeVals row.TaggedValues) (types.Value, bool, error) {
+	mp := schema.MergePolicyOf(sch, col)
+	switch mp.Type {
+	case schema.OursMergePolicy:
+		return val, true, nil
+	case schema.TheirsMergePolicy:
+		return mergeVal, true, nil
+	case schema.MaxMergePolicy, schema.MinMergePolicy:
+		if types.IsNull(val) || types.IsNull(mergeVal) {
+			return nil, false, nil
+		}
+
+		less, err := val.Less(nbf, mergeVal)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		if less == (mp.Type == schema.MaxMergePolicy) {
+			return mergeVal, true, nil
+		}
+
+		return val, true, nil
+	case schema.SumOfDeltasMergePolicy:
+		sum, ok := sumOfDeltas(baseVal, val, mergeVal)
+		return sum, ok, nil
+	case schema.LatestByMergePolicy:
+		byVal, _ := rowVals.Get(mp.ByTag)
+		mergeByVal, _ := mergeVals.Get(mp.ByTag)
+
+		if types.IsNull(byVal) || types.IsNull(mergeByVal) || byVal.Equals(mergeByVal) {
+			return nil, false, nil
+		}
+
+		less, err := byVal.Less(nbf, mergeByVal)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		if less {
+			return mergeVal, true, nil
+		}
+
+		return val, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// sumOfDeltas applies the changes made to baseVal on both sides of a merge, treating a missing baseVal as 0.
+func sumOfDeltas(baseVal, val, mergeVal types.Value) (types.Value, bool) {
+	switch v := val.(type) {
+	case types.Int:
+		mv, ok := mergeVal.(types.Int)
+		bv, bOk := baseVal.(types.Int)
+
+		if !ok || (!bOk && !types.IsNull(baseVal)) {
+			return nil, false
+		}
+
+		return v + mv - bv, true
+	case types.Uint:
+		mv, ok := mergeVal.(types.Uint)
+		bv, bOk := baseVal.(types.Uint)
+
+		if !ok || (!bOk && !types.IsNull(baseVal)) {
+			return nil, false
+		}
+
+		if uint64(mv) > math.MaxUint64-uint64(v) || uint64(v)+uint64(mv) < uint64(bv) {
+			return nil, false
+		}
+
+		return v + mv - bv, true
+	case types.Float:
+		mv, ok := mergeVal.(types.Float)
+		bv, bOk := baseVal.(types.Float)
+
+		if !ok || (!bOk && !types.IsNull(baseVal)) {
+			return nil, false
+		}
+
+		return v + mv - bv, true
+	}
+
+	return nil, false
+}
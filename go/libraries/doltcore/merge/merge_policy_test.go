@@ -0,0 +1,197 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+type policyMergeTest struct {
+	name                  string
+	policies              []schema.MergePolicy
+	defaultPolicy         schema.MergePolicy
+	vals, mergeVals, anc  []types.Value
+	expected              []types.Value
+	expectConflict        bool
+	expectedPolicyResolve int
+}
+
+func ts(secs int64) types.Timestamp {
+	return types.Timestamp(time.Unix(secs, 0).UTC())
+}
+
+func TestRowMergeWithPolicies(t *testing.T) {
+	ours := schema.MergePolicy{Type: schema.OursMergePolicy}
+	theirs := schema.MergePolicy{Type: schema.TheirsMergePolicy}
+	max := schema.MergePolicy{Type: schema.MaxMergePolicy}
+	min := schema.MergePolicy{Type: schema.MinMergePolicy}
+	sumOfDeltas := schema.MergePolicy{Type: schema.SumOfDeltasMergePolicy}
+	latestByCol2 := schema.MergePolicy{Type: schema.LatestByMergePolicy, ByTag: 2}
+
+	tests := []policyMergeTest{
+		{
+			"ours",
+			[]schema.MergePolicy{ours},
+			schema.MergePolicy{},
+			[]types.Value{types.String("b")},
+			[]types.Value{types.String("c")},
+			[]types.Value{types.String("a")},
+			[]types.Value{types.String("b")},
+			false,
+			1,
+		},
+		{
+			"theirs",
+			[]schema.MergePolicy{theirs},
+			schema.MergePolicy{},
+			[]types.Value{types.String("b")},
+			[]types.Value{types.String("c")},
+			[]types.Value{types.String("a")},
+			[]types.Value{types.String("c")},
+			false,
+			1,
+		},
+		{
+			"max and min",
+			[]schema.MergePolicy{max, min},
+			schema.MergePolicy{},
+			[]types.Value{types.Int(5), types.Int(5)},
+			[]types.Value{types.Int(7), types.Int(7)},
+			[]types.Value{types.Int(1), types.Int(1)},
+			[]types.Value{types.Int(7), types.Int(5)},
+			false,
+			2,
+		},
+		{
+			"sum of deltas",
+			[]schema.MergePolicy{sumOfDeltas, sumOfDeltas},
+			schema.MergePolicy{},
+			[]types.Value{types.Int(12), types.Float(1.5)},
+			[]types.Value{types.Int(5), types.Float(3)},
+			[]types.Value{types.Int(10), types.Float(1)},
+			[]types.Value{types.Int(7), types.Float(3.5)},
+			false,
+			2,
+		},
+		{
+			"sum of deltas with unsigned underflow",
+			[]schema.MergePolicy{sumOfDeltas},
+			schema.MergePolicy{},
+			[]types.Value{types.Uint(2)},
+			[]types.Value{types.Uint(3)},
+			[]types.Value{types.Uint(10)},
+			nil,
+			true,
+			0,
+		},
+		{
+			"latest by",
+			[]schema.MergePolicy{latestByCol2, max},
+			schema.MergePolicy{},
+			[]types.Value{types.String("b"), ts(200)},
+			[]types.Value{types.String("c"), ts(300)},
+			[]types.Value{types.String("a"), ts(100)},
+			[]types.Value{types.String("c"), ts(300)},
+			false,
+			2,
+		},
+		{
+			"latest by with equal values",
+			[]schema.MergePolicy{latestByCol2, max},
+			schema.MergePolicy{},
+			[]types.Value{types.String("b"), ts(200)},
+			[]types.Value{types.String("c"), ts(200)},
+			[]types.Value{types.String("a"), ts(100)},
+			nil,
+			true,
+			0,
+		},
+		{
+			"a cell without a policy is still a conflict",
+			[]schema.MergePolicy{max, {}},
+			schema.MergePolicy{},
+			[]types.Value{types.Int(5), types.String("b")},
+			[]types.Value{types.Int(7), types.String("c")},
+			[]types.Value{types.Int(1), types.String("a")},
+			nil,
+			true,
+			0,
+		},
+		{
+			"columns without a policy use the default policy",
+			[]schema.MergePolicy{ours, {}, {}},
+			sumOfDeltas,
+			[]types.Value{types.Int(12), types.Int(12), types.String("b")},
+			[]types.Value{types.Int(5), types.Int(5), types.String("c")},
+			[]types.Value{types.Int(10), types.Int(10), types.String("a")},
+			nil,
+			true,
+			0,
+		},
+		{
+			"the default policy is only applied to the columns it can be used with",
+			[]schema.MergePolicy{ours, {}, theirs},
+			sumOfDeltas,
+			[]types.Value{types.Int(12), types.Int(12), types.String("b")},
+			[]types.Value{types.Int(5), types.Int(5), types.String("c")},
+			[]types.Value{types.Int(10), types.Int(10), types.String("a")},
+			[]types.Value{types.Int(12), types.Int(7), types.String("c")},
+			false,
+			3,
+		},
+		{
+			"default latest by policy",
+			[]schema.MergePolicy{{}, {}},
+			latestByCol2,
+			[]types.Value{types.String("b"), ts(200)},
+			[]types.Value{types.String("c"), ts(300)},
+			[]types.Value{types.String("a"), ts(100)},
+			[]types.Value{types.String("c"), ts(300)},
+			false,
+			2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cols := make([]schema.Column, len(test.policies)+1)
+			cols[0] = schema.NewColumn("primaryKey", 0, types.IntKind, true)
+			for i, mp := range test.policies {
+				tag := i + 1
+				cols[tag] = schema.NewColumn(strconv.FormatInt(int64(tag), 10), uint64(tag), test.anc[i].Kind(), false)
+				cols[tag].MergePolicy = mp
+			}
+
+			colColl, _ := schema.NewColCollection(cols...)
+			sch := schema.SchemaWithMergePolicy(schema.SchemaFromCols(colColl), test.defaultPolicy)
+
+			stats := &MergeStats{}
+			tpl, mergeTpl, ancTpl := valsToTestTupleWithPks(test.vals), valsToTestTupleWithPks(test.mergeVals), valsToTestTupleWithPks(test.anc)
+			actualResult, isConflict, err := rowMerge(context.Background(), types.Format_7_18, sch, tpl, mergeTpl, ancTpl, stats)
+			assert.NoError(t, err)
+			assert.Equal(t, valsToTestTupleWithPks(test.expected), actualResult)
+			assert.Equal(t, test.expectConflict, isConflict)
+			assert.Equal(t, test.expectedPolicyResolve, stats.PolicyResolutions)
+		})
+	}
+}
@@ -29,4 +29,12 @@ type MergeStats struct {
 	Deletes       int
 	Modifications int
 	Conflicts     int
+	// SchemaConflicts is the number of schema conflicts included in Conflicts
+	SchemaConflicts int
+	// PolicyResolutions is the number of cells changed on both sides of the merge which were merged using the merge
+	// policy of their column instead of being reported as conflicts
+	PolicyResolutions int
+	// ForeignKeyConflicts is the number of rows included in Conflicts which reference a parent row that no longer
+	// exists after the merge
+	ForeignKeyConflicts int
 }
@@ -16,6 +16,7 @@ package merge
 
 import (
 	"context"
+	"errors"
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
@@ -24,6 +25,8 @@ import (
 	"github.com/liquidata-inc/dolt/go/store/types"
 )
 
+var ErrUnresolvableSchemaConflict = errors.New("schema conflicts must be resolved using either our or their schema")
+
 type AutoResolver func(key types.Value, conflict doltdb.Conflict) (types.Value, error)
 
 func Ours(key types.Value, cnf doltdb.Conflict) (types.Value, error) {
@@ -41,6 +44,12 @@ func ResolveTable(ctx context.Context, vrw types.ValueReadWriter, tbl *doltdb.Ta
 		return nil, doltdb.ErrNoConflicts
 	}
 
+	if has, err := tbl.HasSchemaConflicts(); err != nil {
+		return nil, err
+	} else if has {
+		return resolveSchemaConflicts(ctx, vrw, tbl, autoResFunc)
+	}
+
 	tblSchRef, err := tbl.GetSchemaRef()
 
 	if err != nil {
@@ -122,6 +131,12 @@ func ResolveTable(ctx context.Context, vrw types.ValueReadWriter, tbl *doltdb.Ta
 		return nil, err
 	}
 
+	newTbl, err = newTbl.RebuildIndexes(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
 	m, err = types.NewMap(ctx, vrw)
 
 	if err != nil {
@@ -136,3 +151,45 @@ func ResolveTable(ctx context.Context, vrw types.ValueReadWriter, tbl *doltdb.Ta
 
 	return newTbl, nil
 }
+
+// resolveSchemaConflicts resolves a table with schema conflicts by asking the AutoResolver to choose between our schema
+// and their schema.  The table is then replaced by the chosen side's version of the table.
+func resolveSchemaConflicts(ctx context.Context, vrw types.ValueReadWriter, tbl *doltdb.Table, autoResFunc AutoResolver) (*doltdb.Table, error) {
+	schemas, _, err := tbl.GetConflicts(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := autoResFunc(nil, schemas)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if resolved.Equals(schemas.Value) {
+		return tbl.ClearConflicts()
+	} else if !resolved.Equals(schemas.MergeValue) {
+		return nil, ErrUnresolvableSchemaConflict
+	}
+
+	mergeSchVal, err := resolved.(types.Ref).TargetValue(ctx, vrw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mergeRows, err := tbl.GetSchemaConflictMergeRows(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newTbl, err := doltdb.NewTable(ctx, vrw, mergeSchVal, mergeRows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTbl.RebuildIndexes(ctx)
+}
@@ -0,0 +1,383 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+)
+
+// SchemaConflict describes a column whose definitions could not be reconciled by a three way schema merge.  Conflicts
+// which do not apply to a single column, such as a merged schema without a primary key, use schema.InvalidTag.
+type SchemaConflict struct {
+	Tag         uint64
+	Description string
+}
+
+// MergeSchemas performs a three way merge of the schemas sch and mergeSch using ancSch as the merge base.  Columns are
+// matched by tag, so a column which is renamed, retyped or has its constraints changed is still recognized as the same
+// column.  Adds, drops and modifications made on one side are carried into the merged schema.  Changes that cannot be
+// reconciled are returned as SchemaConflicts, in which case the returned schema is nil.  Index and foreign key
+// definitions are merged by name in the same way, and those over columns which are not part of the merged schema are
+// dropped.  The default merge policy of the schemas is merged like the properties of a column.
+func MergeSchemas(ancSch, sch, mergeSch schema.Schema) (schema.Schema, []SchemaConflict, error) {
+	ancCols := ancSch.GetAllCols()
+	cols := sch.GetAllCols()
+	mergeCols := mergeSch.GetAllCols()
+
+	// columns are ordered as they are in our schema, followed by any columns added in theirs
+	tags := append([]uint64{}, cols.Tags...)
+	for _, tag := range mergeCols.Tags {
+		if _, ok := cols.GetByTag(tag); !ok {
+			tags = append(tags, tag)
+		}
+	}
+
+	var conflicts []SchemaConflict
+	var mergedCols []schema.Column
+	for _, tag := range tags {
+		ancCol, ancOk := ancCols.GetByTag(tag)
+		col, ok := cols.GetByTag(tag)
+		mergeCol, mergeOk := mergeCols.GetByTag(tag)
+
+		var desc string
+		switch {
+		case !ancOk && ok && mergeOk:
+			if col.Equals(mergeCol) {
+				mergedCols = append(mergedCols, col)
+			} else {
+				desc = "column added in both branches with different definitions"
+			}
+		case !ancOk && ok:
+			mergedCols = append(mergedCols, col)
+		case !ancOk:
+			mergedCols = append(mergedCols, mergeCol)
+		case !ok:
+			if !mergeCol.Equals(ancCol) {
+				desc = "column deleted in ours and modified in theirs"
+			}
+		case !mergeOk:
+			if !col.Equals(ancCol) {
+				desc = "column modified in ours and deleted in theirs"
+			}
+		default:
+			var mergedCol schema.Column
+			mergedCol, desc = mergeColumns(ancCol, col, mergeCol)
+
+			if desc == "" {
+				mergedCols = append(mergedCols, mergedCol)
+			}
+		}
+
+		if desc != "" {
+			conflicts = append(conflicts, SchemaConflict{tag, desc})
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, conflicts, nil
+	}
+
+	conflicts = checkMergedCols(mergedCols)
+
+	if len(conflicts) > 0 {
+		return nil, conflicts, nil
+	}
+
+	mergedIndexes, conflict := mergeIndexes(ancSch.Indexes(), sch.Indexes(), mergeSch.Indexes())
+
+	if conflict != nil {
+		return nil, []SchemaConflict{*conflict}, nil
+	}
+
+	mergedForeignKeys, conflict := mergeForeignKeys(ancSch.ForeignKeys(), sch.ForeignKeys(), mergeSch.ForeignKeys())
+
+	if conflict != nil {
+		return nil, []SchemaConflict{*conflict}, nil
+	}
+
+	var mergedPolicy schema.MergePolicy
+	switch {
+	case sch.MergePolicy() == mergeSch.MergePolicy() || mergeSch.MergePolicy() == ancSch.MergePolicy():
+		mergedPolicy = sch.MergePolicy()
+	case sch.MergePolicy() == ancSch.MergePolicy():
+		mergedPolicy = mergeSch.MergePolicy()
+	default:
+		return nil, []SchemaConflict{{schema.InvalidTag, "default merge policy modified differently in both branches"}}, nil
+	}
+
+	colColl, err := schema.NewColCollection(mergedCols...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hasAllTags := func(tags []uint64) bool {
+		for _, tag := range tags {
+			if _, ok := colColl.GetByTag(tag); !ok {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	mergedIndexes = mergedIndexes.Filter(func(idx schema.Index) bool {
+		return hasAllTags(idx.Tags)
+	})
+	mergedForeignKeys = mergedForeignKeys.Filter(func(fk schema.ForeignKey) bool {
+		return hasAllTags(fk.Tags)
+	})
+
+	mergedSch, err := schema.SchemaWithIndexes(schema.SchemaFromCols(colColl), mergedIndexes)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mergedSch, err = schema.SchemaWithForeignKeys(mergedSch, mergedForeignKeys)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return schema.SchemaWithMergePolicy(mergedSch, mergedPolicy), nil, nil
+}
+
+// mergeIndexes performs a three way merge of the index definitions of two schemas, matching indexes by name.  All
+// indexes that could not be reconciled are described by a single conflict, as index conflicts don't apply to a single
+// column.
+func mergeIndexes(ancIndexes, indexes, mergeIndexes *schema.IndexCollection) (*schema.IndexCollection, *SchemaConflict) {
+	// indexes are ordered as they are in our schema, followed by any indexes added in theirs
+	names := make([]string, 0, indexes.Size()+mergeIndexes.Size())
+	for _, idx := range indexes.GetIndexes() {
+		names = append(names, idx.Name)
+	}
+
+	for _, idx := range mergeIndexes.GetIndexes() {
+		if _, ok := indexes.GetByName(idx.Name); !ok {
+			names = append(names, idx.Name)
+		}
+	}
+
+	var merged []schema.Index
+	var conflicting []string
+	for _, name := range names {
+		ancIdx, ancOk := ancIndexes.GetByName(name)
+		idx, ok := indexes.GetByName(name)
+		mergeIdx, mergeOk := mergeIndexes.GetByName(name)
+
+		switch {
+		case ok && mergeOk && idx.Equals(mergeIdx):
+			merged = append(merged, idx)
+		case !ancOk && ok && mergeOk:
+			conflicting = append(conflicting, name)
+		case !ancOk && ok:
+			merged = append(merged, idx)
+		case !ancOk:
+			merged = append(merged, mergeIdx)
+		case !ok:
+			if !mergeIdx.Equals(ancIdx) {
+				conflicting = append(conflicting, name)
+			}
+		case !mergeOk:
+			if !idx.Equals(ancIdx) {
+				conflicting = append(conflicting, name)
+			}
+		case mergeIdx.Equals(ancIdx):
+			merged = append(merged, idx)
+		case idx.Equals(ancIdx):
+			merged = append(merged, mergeIdx)
+		default:
+			conflicting = append(conflicting, name)
+		}
+	}
+
+	if len(conflicting) > 0 {
+		desc := fmt.Sprintf("indexes modified differently in both branches: %s", strings.Join(conflicting, ", "))
+		return nil, &SchemaConflict{schema.InvalidTag, desc}
+	}
+
+	// names are unique as they were taken from valid index collections
+	mergedColl, _ := schema.NewIndexCollection(merged...)
+	return mergedColl, nil
+}
+
+// mergeForeignKeys performs a three way merge of the foreign key definitions of two schemas, matching foreign keys by
+// name.  Like index conflicts, all foreign keys that could not be reconciled are described by a single conflict.
+func mergeForeignKeys(ancFKs, fks, mergeFKs *schema.ForeignKeyCollection) (*schema.ForeignKeyCollection, *SchemaConflict) {
+	// foreign keys are ordered as they are in our schema, followed by any foreign keys added in theirs
+	names := make([]string, 0, fks.Size()+mergeFKs.Size())
+	for _, fk := range fks.GetForeignKeys() {
+		names = append(names, fk.Name)
+	}
+
+	for _, fk := range mergeFKs.GetForeignKeys() {
+		if _, ok := fks.GetByName(fk.Name); !ok {
+			names = append(names, fk.Name)
+		}
+	}
+
+	var merged []schema.ForeignKey
+	var conflicting []string
+	for _, name := range names {
+		ancFK, ancOk := ancFKs.GetByName(name)
+		fk, ok := fks.GetByName(name)
+		mergeFK, mergeOk := mergeFKs.GetByName(name)
+
+		switch {
+		case ok && mergeOk && fk.Equals(mergeFK):
+			merged = append(merged, fk)
+		case !ancOk && ok && mergeOk:
+			conflicting = append(conflicting, name)
+		case !ancOk && ok:
+			merged = append(merged, fk)
+		case !ancOk:
+			merged = append(merged, mergeFK)
+		case !ok:
+			if !mergeFK.Equals(ancFK) {
+				conflicting = append(conflicting, name)
+			}
+		case !mergeOk:
+			if !fk.Equals(ancFK) {
+				conflicting = append(conflicting, name)
+			}
+		case mergeFK.Equals(ancFK):
+			merged = append(merged, fk)
+		case fk.Equals(ancFK):
+			merged = append(merged, mergeFK)
+		default:
+			conflicting = append(conflicting, name)
+		}
+	}
+
+	if len(conflicting) > 0 {
+		desc := fmt.Sprintf("foreign keys modified differently in both branches: %s", strings.Join(conflicting, ", "))
+		return nil, &SchemaConflict{schema.InvalidTag, desc}
+	}
+
+	// names are unique as they were taken from valid foreign key collections
+	mergedColl, _ := schema.NewForeignKeyCollection(merged...)
+	return mergedColl, nil
+}
+
+// mergeColumns merges the definitions of a column which exists in the ancestor and on both sides of the merge.  If both
+// sides made different changes to the same property of the column, a description of the conflict is returned.
+func mergeColumns(ancCol, col, mergeCol schema.Column) (schema.Column, string) {
+	if col.Equals(mergeCol) || mergeCol.Equals(ancCol) {
+		return col, ""
+	} else if col.Equals(ancCol) {
+		return mergeCol, ""
+	}
+
+	merged := ancCol
+	var conflicting []string
+
+	switch {
+	case col.Name == mergeCol.Name || mergeCol.Name == ancCol.Name:
+		merged.Name = col.Name
+	case col.Name == ancCol.Name:
+		merged.Name = mergeCol.Name
+	default:
+		conflicting = append(conflicting, "name")
+	}
+
+	switch {
+	case col.Kind == mergeCol.Kind || mergeCol.Kind == ancCol.Kind:
+		merged.Kind = col.Kind
+	case col.Kind == ancCol.Kind:
+		merged.Kind = mergeCol.Kind
+	default:
+		conflicting = append(conflicting, "type")
+	}
+
+	switch {
+	case col.IsPartOfPK == mergeCol.IsPartOfPK || mergeCol.IsPartOfPK == ancCol.IsPartOfPK:
+		merged.IsPartOfPK = col.IsPartOfPK
+	case col.IsPartOfPK == ancCol.IsPartOfPK:
+		merged.IsPartOfPK = mergeCol.IsPartOfPK
+	default:
+		conflicting = append(conflicting, "primary key membership")
+	}
+
+	switch {
+	case schema.ColConstraintsAreEqual(col.Constraints, mergeCol.Constraints) || schema.ColConstraintsAreEqual(mergeCol.Constraints, ancCol.Constraints):
+		merged.Constraints = col.Constraints
+	case schema.ColConstraintsAreEqual(col.Constraints, ancCol.Constraints):
+		merged.Constraints = mergeCol.Constraints
+	default:
+		conflicting = append(conflicting, "constraints")
+	}
+
+	switch {
+	case col.MergePolicy == mergeCol.MergePolicy || mergeCol.MergePolicy == ancCol.MergePolicy:
+		merged.MergePolicy = col.MergePolicy
+	case col.MergePolicy == ancCol.MergePolicy:
+		merged.MergePolicy = mergeCol.MergePolicy
+	default:
+		conflicting = append(conflicting, "merge policy")
+	}
+
+	switch {
+	case col.Default == mergeCol.Default || mergeCol.Default == ancCol.Default:
+		merged.Default = col.Default
+	case col.Default == ancCol.Default:
+		merged.Default = mergeCol.Default
+	default:
+		conflicting = append(conflicting, "default value")
+	}
+
+	// auto increment is a flag, so both sides can't have changed it differently
+	if col.AutoIncrement != ancCol.AutoIncrement {
+		merged.AutoIncrement = col.AutoIncrement
+	} else {
+		merged.AutoIncrement = mergeCol.AutoIncrement
+	}
+
+	if len(conflicting) > 0 {
+		return schema.InvalidCol, fmt.Sprintf("column %s modified differently in both branches", strings.Join(conflicting, " and "))
+	}
+
+	return merged, ""
+}
+
+// checkMergedCols looks for problems which only become apparent once the columns from both sides have been merged, such
+// as two different columns ending up with the same name.
+func checkMergedCols(mergedCols []schema.Column) []SchemaConflict {
+	var conflicts []SchemaConflict
+	nameToTag := make(map[string]uint64, len(mergedCols))
+	hasPK := false
+	for _, col := range mergedCols {
+		lwrName := strings.ToLower(col.Name)
+		if tag, ok := nameToTag[lwrName]; ok {
+			desc := fmt.Sprintf("column name '%s' is used by the columns with tags %d and %d", col.Name, tag, col.Tag)
+			conflicts = append(conflicts, SchemaConflict{col.Tag, desc})
+		} else {
+			nameToTag[lwrName] = col.Tag
+		}
+
+		hasPK = hasPK || col.IsPartOfPK
+	}
+
+	if !hasPK {
+		conflicts = append(conflicts, SchemaConflict{schema.InvalidTag, "merged schema has no primary key columns"})
+	} else if colColl, err := schema.NewColCollection(mergedCols...); err == nil && schema.ValidateAutoIncrement(colColl) != nil {
+		conflicts = append(conflicts, SchemaConflict{schema.InvalidTag, "merged schema has an auto increment column which isn't its only primary key column"})
+	}
+
+	return conflicts
+}
@@ -0,0 +1,451 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+const ageTag = 2
+
+var notNull = schema.NotNullConstraint{}
+
+var pkCol = schema.NewColumn("id", idTag, types.UUIDKind, true, notNull)
+var nameCol = schema.NewColumn("name", nameTag, types.StringKind, false, notNull)
+var ageCol = schema.NewColumn("age", ageTag, types.UintKind, false)
+
+func schemaFromCols(cols ...schema.Column) schema.Schema {
+	colColl, err := schema.NewColCollection(cols...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return schema.SchemaFromCols(colColl)
+}
+
+func renamed(col schema.Column, name string) schema.Column {
+	col.Name = name
+	return col
+}
+
+func retyped(col schema.Column, kind types.NomsKind) schema.Column {
+	col.Kind = kind
+	return col
+}
+
+func withConstraints(col schema.Column, constraints ...schema.ColConstraint) schema.Column {
+	col.Constraints = constraints
+	return col
+}
+
+func withDefault(col schema.Column, lit string) schema.Column {
+	col.Default = lit
+	return col
+}
+
+func TestMergeSchemas(t *testing.T) {
+	ancSch := schemaFromCols(pkCol, nameCol, ageCol)
+
+	tests := []struct {
+		name              string
+		sch               schema.Schema
+		mergeSch          schema.Schema
+		expected          schema.Schema
+		expectedConflicts []uint64
+	}{
+		{
+			"unchanged",
+			ancSch,
+			ancSch,
+			ancSch,
+			nil,
+		},
+		{
+			"column added in ours",
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.StringKind, false)),
+			ancSch,
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.StringKind, false)),
+			nil,
+		},
+		{
+			"column added in theirs",
+			ancSch,
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.StringKind, false)),
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.StringKind, false)),
+			nil,
+		},
+		{
+			"same column added in both",
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.StringKind, false)),
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.StringKind, false)),
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.StringKind, false)),
+			nil,
+		},
+		{
+			"different columns with the same tag added in both",
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.StringKind, false)),
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.IntKind, false)),
+			nil,
+			[]uint64{titleTag},
+		},
+		{
+			"column dropped in theirs",
+			ancSch,
+			schemaFromCols(pkCol, nameCol),
+			schemaFromCols(pkCol, nameCol),
+			nil,
+		},
+		{
+			"column dropped in both",
+			schemaFromCols(pkCol, nameCol),
+			schemaFromCols(pkCol, nameCol),
+			schemaFromCols(pkCol, nameCol),
+			nil,
+		},
+		{
+			"column dropped in ours and renamed in theirs",
+			schemaFromCols(pkCol, nameCol),
+			schemaFromCols(pkCol, nameCol, renamed(ageCol, "years")),
+			nil,
+			[]uint64{ageTag},
+		},
+		{
+			"column renamed in ours and retyped in theirs",
+			schemaFromCols(pkCol, nameCol, renamed(ageCol, "years")),
+			schemaFromCols(pkCol, nameCol, retyped(ageCol, types.IntKind)),
+			schemaFromCols(pkCol, nameCol, retyped(renamed(ageCol, "years"), types.IntKind)),
+			nil,
+		},
+		{
+			"constraint added in theirs and column renamed in ours",
+			schemaFromCols(pkCol, renamed(nameCol, "full_name"), ageCol),
+			schemaFromCols(pkCol, nameCol, withConstraints(ageCol, notNull)),
+			schemaFromCols(pkCol, renamed(nameCol, "full_name"), withConstraints(ageCol, notNull)),
+			nil,
+		},
+		{
+			"default added in theirs and column renamed in ours",
+			schemaFromCols(pkCol, nameCol, renamed(ageCol, "years")),
+			schemaFromCols(pkCol, nameCol, withDefault(ageCol, "0")),
+			schemaFromCols(pkCol, nameCol, withDefault(renamed(ageCol, "years"), "0")),
+			nil,
+		},
+		{
+			"default changed differently in both",
+			schemaFromCols(pkCol, nameCol, withDefault(ageCol, "1")),
+			schemaFromCols(pkCol, nameCol, withDefault(ageCol, "2")),
+			nil,
+			[]uint64{ageTag},
+		},
+		{
+			"column renamed differently in both",
+			schemaFromCols(pkCol, nameCol, renamed(ageCol, "years")),
+			schemaFromCols(pkCol, nameCol, renamed(ageCol, "age_in_years")),
+			nil,
+			[]uint64{ageTag},
+		},
+		{
+			"different columns renamed to the same name",
+			schemaFromCols(pkCol, nameCol, renamed(ageCol, "info")),
+			schemaFromCols(pkCol, renamed(nameCol, "info"), ageCol),
+			nil,
+			[]uint64{ageTag},
+		},
+		{
+			"column added in ours with the name of a column renamed in theirs",
+			schemaFromCols(pkCol, nameCol, ageCol, schema.NewColumn("title", titleTag, types.StringKind, false)),
+			schemaFromCols(pkCol, renamed(nameCol, "title"), ageCol),
+			nil,
+			[]uint64{titleTag},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mergedSch, conflicts, err := MergeSchemas(ancSch, test.sch, test.mergeSch)
+			require.NoError(t, err)
+
+			var conflictedTags []uint64
+			for _, cnf := range conflicts {
+				conflictedTags = append(conflictedTags, cnf.Tag)
+			}
+
+			assert.Equal(t, test.expectedConflicts, conflictedTags)
+
+			if test.expected == nil {
+				assert.Nil(t, mergedSch)
+			} else {
+				eq, err := schema.SchemasAreEqual(test.expected, mergedSch)
+				require.NoError(t, err)
+				assert.True(t, eq)
+			}
+		})
+	}
+}
+
+func withIndexes(sch schema.Schema, indexes ...schema.Index) schema.Schema {
+	idxColl, err := schema.NewIndexCollection(indexes...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	sch, err = schema.SchemaWithIndexes(sch, idxColl)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return sch
+}
+
+func withForeignKeys(sch schema.Schema, foreignKeys ...schema.ForeignKey) schema.Schema {
+	fkColl, err := schema.NewForeignKeyCollection(foreignKeys...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	sch, err = schema.SchemaWithForeignKeys(sch, fkColl)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return sch
+}
+
+func TestMergeSchemaIndexes(t *testing.T) {
+	nameIdx := schema.Index{Name: "idx_name", Tags: []uint64{nameTag}}
+	uniqueNameIdx := schema.Index{Name: "idx_name", Tags: []uint64{nameTag}, Unique: true}
+	ageIdx := schema.Index{Name: "idx_age", Tags: []uint64{ageTag}}
+	nameAgeIdx := schema.Index{Name: "idx_name", Tags: []uint64{nameTag, ageTag}}
+	ancSch := withIndexes(schemaFromCols(pkCol, nameCol, ageCol), nameIdx)
+
+	tests := []struct {
+		name            string
+		sch             schema.Schema
+		mergeSch        schema.Schema
+		expected        []schema.Index
+		expectConflicts bool
+	}{
+		{
+			"unchanged",
+			ancSch,
+			ancSch,
+			[]schema.Index{nameIdx},
+			false,
+		},
+		{
+			"index added in theirs",
+			ancSch,
+			withIndexes(schemaFromCols(pkCol, nameCol, ageCol), nameIdx, ageIdx),
+			[]schema.Index{nameIdx, ageIdx},
+			false,
+		},
+		{
+			"index dropped in ours",
+			schemaFromCols(pkCol, nameCol, ageCol),
+			ancSch,
+			[]schema.Index{},
+			false,
+		},
+		{
+			"index modified in theirs",
+			ancSch,
+			withIndexes(schemaFromCols(pkCol, nameCol, ageCol), uniqueNameIdx),
+			[]schema.Index{uniqueNameIdx},
+			false,
+		},
+		{
+			"index modified differently in both",
+			withIndexes(schemaFromCols(pkCol, nameCol, ageCol), uniqueNameIdx),
+			withIndexes(schemaFromCols(pkCol, nameCol, ageCol), nameAgeIdx),
+			nil,
+			true,
+		},
+		{
+			"index dropped in ours and modified in theirs",
+			schemaFromCols(pkCol, nameCol, ageCol),
+			withIndexes(schemaFromCols(pkCol, nameCol, ageCol), uniqueNameIdx),
+			nil,
+			true,
+		},
+		{
+			"indexed column dropped in theirs",
+			withIndexes(schemaFromCols(pkCol, nameCol, ageCol), nameIdx, ageIdx),
+			withIndexes(schemaFromCols(pkCol, nameCol), nameIdx),
+			[]schema.Index{nameIdx},
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mergedSch, conflicts, err := MergeSchemas(ancSch, test.sch, test.mergeSch)
+			require.NoError(t, err)
+
+			if test.expectConflicts {
+				assert.Nil(t, mergedSch)
+				require.Len(t, conflicts, 1)
+				assert.Equal(t, schema.InvalidTag, conflicts[0].Tag)
+			} else {
+				assert.Empty(t, conflicts)
+				assert.Equal(t, test.expected, mergedSch.Indexes().GetIndexes())
+			}
+		})
+	}
+}
+
+func TestMergeSchemaForeignKeys(t *testing.T) {
+	nameFK := schema.ForeignKey{Name: "fk_name", Tags: []uint64{nameTag}, ReferencedTable: "names", ReferencedTags: []uint64{0}}
+	renamedParentFK := schema.ForeignKey{Name: "fk_name", Tags: []uint64{nameTag}, ReferencedTable: "all_names", ReferencedTags: []uint64{0}}
+	nameAgeFK := schema.ForeignKey{Name: "fk_name", Tags: []uint64{nameTag, ageTag}, ReferencedTable: "names", ReferencedTags: []uint64{0, 1}}
+	ageFK := schema.ForeignKey{Name: "fk_age", Tags: []uint64{ageTag}, ReferencedTable: "ages", ReferencedTags: []uint64{0}}
+	ancSch := withForeignKeys(schemaFromCols(pkCol, nameCol, ageCol), nameFK)
+
+	tests := []struct {
+		name            string
+		sch             schema.Schema
+		mergeSch        schema.Schema
+		expected        []schema.ForeignKey
+		expectConflicts bool
+	}{
+		{
+			"unchanged",
+			ancSch,
+			ancSch,
+			[]schema.ForeignKey{nameFK},
+			false,
+		},
+		{
+			"foreign key added in theirs",
+			ancSch,
+			withForeignKeys(schemaFromCols(pkCol, nameCol, ageCol), nameFK, ageFK),
+			[]schema.ForeignKey{nameFK, ageFK},
+			false,
+		},
+		{
+			"foreign key dropped in ours",
+			schemaFromCols(pkCol, nameCol, ageCol),
+			ancSch,
+			[]schema.ForeignKey{},
+			false,
+		},
+		{
+			"foreign key modified in theirs",
+			ancSch,
+			withForeignKeys(schemaFromCols(pkCol, nameCol, ageCol), renamedParentFK),
+			[]schema.ForeignKey{renamedParentFK},
+			false,
+		},
+		{
+			"foreign key modified differently in both",
+			withForeignKeys(schemaFromCols(pkCol, nameCol, ageCol), renamedParentFK),
+			withForeignKeys(schemaFromCols(pkCol, nameCol, ageCol), nameAgeFK),
+			nil,
+			true,
+		},
+		{
+			"constrained column dropped in theirs",
+			withForeignKeys(schemaFromCols(pkCol, nameCol, ageCol), nameFK, ageFK),
+			withForeignKeys(schemaFromCols(pkCol, nameCol), nameFK),
+			[]schema.ForeignKey{nameFK},
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mergedSch, conflicts, err := MergeSchemas(ancSch, test.sch, test.mergeSch)
+			require.NoError(t, err)
+
+			if test.expectConflicts {
+				assert.Nil(t, mergedSch)
+				require.Len(t, conflicts, 1)
+				assert.Equal(t, schema.InvalidTag, conflicts[0].Tag)
+			} else {
+				assert.Empty(t, conflicts)
+				assert.Equal(t, test.expected, mergedSch.ForeignKeys().GetForeignKeys())
+			}
+		})
+	}
+}
+
+func TestMergeSchemaDefaultMergePolicy(t *testing.T) {
+	ours := schema.MergePolicy{Type: schema.OursMergePolicy}
+	theirs := schema.MergePolicy{Type: schema.TheirsMergePolicy}
+	ancSch := schema.SchemaWithMergePolicy(schemaFromCols(pkCol, nameCol, ageCol), ours)
+
+	tests := []struct {
+		name            string
+		sch             schema.Schema
+		mergeSch        schema.Schema
+		expected        schema.MergePolicy
+		expectConflicts bool
+	}{
+		{
+			"unchanged",
+			ancSch,
+			ancSch,
+			ours,
+			false,
+		},
+		{
+			"policy modified in theirs",
+			ancSch,
+			schema.SchemaWithMergePolicy(ancSch, theirs),
+			theirs,
+			false,
+		},
+		{
+			"policy removed in ours",
+			schemaFromCols(pkCol, nameCol, ageCol),
+			ancSch,
+			schema.MergePolicy{},
+			false,
+		},
+		{
+			"policy modified differently in both",
+			schema.SchemaWithMergePolicy(ancSch, theirs),
+			schemaFromCols(pkCol, nameCol, ageCol),
+			schema.MergePolicy{},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mergedSch, conflicts, err := MergeSchemas(ancSch, test.sch, test.mergeSch)
+			require.NoError(t, err)
+
+			if test.expectConflicts {
+				assert.Nil(t, mergedSch)
+				require.Len(t, conflicts, 1)
+				assert.Equal(t, schema.InvalidTag, conflicts[0].Tag)
+			} else {
+				assert.Empty(t, conflicts)
+				assert.Equal(t, test.expected, mergedSch.MergePolicy())
+			}
+		})
+	}
+}
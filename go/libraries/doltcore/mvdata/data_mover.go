@@ -23,6 +23,7 @@ import (
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/table/typed/noms"
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/rowconv"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/encoding"
@@ -31,6 +32,7 @@ import (
 	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/funcitr"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/set"
+	"github.com/liquidata-inc/dolt/go/store/types"
 )
 
 type MoveOperation string
@@ -145,7 +147,16 @@ func NewDataMover(ctx context.Context, root *doltdb.RootValue, fs filesys.Filesy
 		return nil, &DataMoverCreationError{MappingErr, err}
 	}
 
-	err = maybeMapFields(transforms, mapping)
+	var filler *columnFiller
+	if _, isTableDest := mvOpts.Dest.(TableDataLocation); isTableDest {
+		filler, err = newColumnFiller(ctx, root, mvOpts, mapping)
+
+		if err != nil {
+			return nil, &DataMoverCreationError{CreateMapperErr, err}
+		}
+	}
+
+	err = maybeMapFields(transforms, mapping, filler)
 
 	if err != nil {
 		return nil, &DataMoverCreationError{CreateMapperErr, err}
@@ -208,14 +219,17 @@ func (imp *DataMover) Move(ctx context.Context) (badRowCount int64, err error) {
 	return badCount, nil
 }
 
-func maybeMapFields(transforms *pipeline.TransformCollection, mapping *rowconv.FieldMapping) error {
+func maybeMapFields(transforms *pipeline.TransformCollection, mapping *rowconv.FieldMapping, filler *columnFiller) error {
 	rconv, err := rowconv.NewRowConverter(mapping)
 
 	if err != nil {
 		return err
 	}
 
-	if !rconv.IdentityConverter {
+	if filler != nil {
+		nt := pipeline.NewNamedTransform("Mapping transform", rowconv.GetRowConvTransformFuncWithFiller(rconv, filler.fill))
+		transforms.AppendTransforms(nt)
+	} else if !rconv.IdentityConverter {
 		nt := pipeline.NewNamedTransform("Mapping transform", rowconv.GetRowConvTransformFunc(rconv))
 		transforms.AppendTransforms(nt)
 	}
@@ -223,6 +237,114 @@ func maybeMapFields(transforms *pipeline.TransformCollection, mapping *rowconv.F
 	return nil
 }
 
+// columnFiller gives the columns of rows imported into a table the values the source data doesn't have.  Columns
+// which aren't mapped from the source get their default values, and rows without a value for the auto increment
+// column are given the next one.
+type columnFiller struct {
+	sch         schema.Schema
+	defaults    map[uint64]types.Value
+	autoIncCol  schema.Column
+	hasAutoInc  bool
+	nextAutoInc uint64
+}
+
+// newColumnFiller returns the columnFiller for an import into the destination table of the move options given, or
+// nil if the destination schema has no default values or auto increment column.
+func newColumnFiller(ctx context.Context, root *doltdb.RootValue, mvOpts *MoveOptions, mapping *rowconv.FieldMapping) (*columnFiller, error) {
+	sch := mapping.DestSch
+	mappedTags := make(map[uint64]bool)
+	for _, destTag := range mapping.SrcToDest {
+		mappedTags[destTag] = true
+	}
+
+	defaults := make(map[uint64]types.Value)
+	err := sch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		if mappedTags[tag] {
+			return false, nil
+		}
+
+		val, err := col.DefaultValue()
+
+		if err != nil {
+			return true, err
+		}
+
+		if !types.IsNull(val) {
+			defaults[tag] = val
+		}
+
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	autoIncCol, hasAutoInc := schema.AutoIncrementCol(sch)
+
+	if len(defaults) == 0 && !hasAutoInc {
+		return nil, nil
+	}
+
+	// rows written to an existing table mustn't be given values its column has already had
+	nextAutoInc := uint64(1)
+	if hasAutoInc && mvOpts.Operation != OverwriteOp {
+		tbl, ok, err := root.GetTable(ctx, mvOpts.Dest.(TableDataLocation).Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			nextAutoInc, err = tbl.NextAutoIncrementValue(ctx, sch)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &columnFiller{sch, defaults, autoIncCol, hasAutoInc, nextAutoInc}, nil
+}
+
+func (cf *columnFiller) fill(r row.Row) (row.Row, error) {
+	var err error
+	for tag, val := range cf.defaults {
+		if _, ok := r.GetColVal(tag); !ok {
+			r, err = r.SetColVal(tag, val, cf.sch)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !cf.hasAutoInc {
+		return r, nil
+	}
+
+	switch val, _ := r.GetColVal(cf.autoIncCol.Tag); val := val.(type) {
+	case types.Int:
+		if val > 0 && uint64(val) >= cf.nextAutoInc {
+			cf.nextAutoInc = uint64(val) + 1
+		}
+		return r, nil
+	case types.Uint:
+		if uint64(val) >= cf.nextAutoInc {
+			cf.nextAutoInc = uint64(val) + 1
+		}
+		return r, nil
+	}
+
+	var generated types.Value = types.Uint(cf.nextAutoInc)
+	if cf.autoIncCol.Kind == types.IntKind {
+		generated = types.Int(cf.nextAutoInc)
+	}
+	cf.nextAutoInc++
+
+	return r.SetColVal(cf.autoIncCol.Tag, generated, cf.sch)
+}
+
 func getOutSchema(ctx context.Context, inSch schema.Schema, root *doltdb.RootValue, fs filesys.ReadableFS, mvOpts *MoveOptions) (schema.Schema, error) {
 	if mvOpts.Operation == UpdateOp || mvOpts.Operation == ReplaceOp {
 		// Get schema from target
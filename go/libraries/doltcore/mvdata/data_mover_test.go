@@ -19,9 +19,14 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/rowconv"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/encoding"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/table"
+	"github.com/liquidata-inc/dolt/go/store/types"
 )
 
 const (
@@ -161,3 +166,57 @@ func TestDataMover(t *testing.T) {
 		}
 	}
 }
+
+func TestColumnFiller(t *testing.T) {
+	ctx := context.Background()
+	_, root, _ := createRootAndFS()
+
+	idCol := schema.NewColumn("id", 0, types.UintKind, true, schema.NotNullConstraint{})
+	idCol.AutoIncrement = true
+	nameCol := schema.NewColumn("name", 1, types.StringKind, false)
+	qtyCol := schema.NewColumn("qty", 2, types.IntKind, false)
+	qtyCol.Default = "7"
+	destSch := schema.SchemaFromCols(mustColColl(t, idCol, nameCol, qtyCol))
+	srcSch := schema.UnkeyedSchemaFromCols(mustColColl(t, schema.NewColumn("id", 0, types.UintKind, false), nameCol))
+
+	mapping, err := rowconv.NewFieldMapping(srcSch, destSch, map[uint64]uint64{0: 0, 1: 1})
+	require.NoError(t, err)
+
+	mvOpts := &MoveOptions{Operation: OverwriteOp, Dest: TableDataLocation{"items"}}
+	filler, err := newColumnFiller(ctx, root, mvOpts, mapping)
+	require.NoError(t, err)
+	require.NotNil(t, filler)
+
+	tests := []struct {
+		in       row.TaggedValues
+		expected row.TaggedValues
+	}{
+		{row.TaggedValues{1: types.String("a")}, row.TaggedValues{0: types.Uint(1), 1: types.String("a"), 2: types.Int(7)}},
+		{row.TaggedValues{0: types.Uint(10)}, row.TaggedValues{0: types.Uint(10), 2: types.Int(7)}},
+		{row.TaggedValues{1: types.String("b")}, row.TaggedValues{0: types.Uint(11), 1: types.String("b"), 2: types.Int(7)}},
+	}
+
+	for _, test := range tests {
+		r, err := row.New(types.Format_7_18, destSch, test.in)
+		require.NoError(t, err)
+		r, err = filler.fill(r)
+		require.NoError(t, err)
+
+		expected, err := row.New(types.Format_7_18, destSch, test.expected)
+		require.NoError(t, err)
+		assert.True(t, row.AreEqual(expected, r, destSch))
+	}
+
+	// columns without defaults or an auto increment column need no filler
+	mapping, err = rowconv.NewFieldMapping(srcSch, srcSch, map[uint64]uint64{0: 0, 1: 1})
+	require.NoError(t, err)
+	filler, err = newColumnFiller(ctx, root, mvOpts, mapping)
+	require.NoError(t, err)
+	assert.Nil(t, filler)
+}
+
+func mustColColl(t *testing.T, cols ...schema.Column) *schema.ColCollection {
+	colColl, err := schema.NewColCollection(cols...)
+	require.NoError(t, err)
+	return colColl
+}
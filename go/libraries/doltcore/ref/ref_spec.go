@@ -101,6 +101,8 @@ func ParseRefSpecForRemote(remote, refSpecStr string) (RefSpec, error) {
 		return newLocalToRemoteTrackingRef(remote, fromRef.(BranchRef), toRef.(RemoteRef))
 	} else if fromRef.GetType() == BranchRefType && toRef.GetType() == BranchRefType {
 		return NewBranchToBranchRefSpec(fromRef.(BranchRef), toRef.(BranchRef))
+	} else if fromRef.GetType() == TagRefType && toRef.GetType() == TagRefType {
+		return newTagToTagRefSpec(remote, fromRef.(TagRef), toRef.(TagRef))
 	}
 
 	return nil, ErrUnsupportedMapping
@@ -248,3 +250,70 @@ func (rs BranchToTrackingBranchRefSpec) DestRef(branchRef DoltRef) DoltRef {
 func (rs BranchToTrackingBranchRefSpec) GetRemote() string {
 	return rs.remote
 }
+
+// TagToTagRefSpec maps tags in one database to tags in another.  Unlike branches, tags are not mapped to remote
+// tracking refs, so a tag named v1 on the remote is written to refs/tags/v1 locally and vice versa.
+type TagToTagRefSpec struct {
+	srcRef          DoltRef
+	srcPattern      pattern
+	remote          string
+	srcToDestMapper branchMapper
+}
+
+func newTagToTagRefSpec(remote string, srcRef, destRef TagRef) (RefSpec, error) {
+	srcWCs := strings.Count(srcRef.GetPath(), "*")
+	destWCs := strings.Count(destRef.GetPath(), "*")
+
+	if srcWCs != destWCs || srcWCs > 1 {
+		return nil, ErrInvalidRefSpec
+	}
+
+	if srcWCs == 0 {
+		return TagToTagRefSpec{
+			srcRef:          srcRef,
+			srcPattern:      strPattern(srcRef.GetPath()),
+			remote:          remote,
+			srcToDestMapper: identityBranchMapper(destRef.GetPath()),
+		}, nil
+	}
+
+	return TagToTagRefSpec{
+		srcPattern:      newWildcardPattern(srcRef.GetPath()),
+		remote:          remote,
+		srcToDestMapper: newWildcardBranchMapper(destRef.GetPath()),
+	}, nil
+}
+
+// SrcRef returns the tag specified as the source of the ref spec.  For wildcard ref specs the ref passed in is returned
+// if it is a tag which matches the source pattern, and nil is returned otherwise.
+func (rs TagToTagRefSpec) SrcRef(cwbRef DoltRef) DoltRef {
+	if rs.srcRef != nil {
+		return rs.srcRef
+	}
+
+	if cwbRef.GetType() == TagRefType {
+		if _, matches := rs.srcPattern.matches(cwbRef.GetPath()); matches {
+			return cwbRef
+		}
+	}
+
+	return nil
+}
+
+// DestRef verifies the tagRef matches the refspec's source pattern, and then maps it to the destination tag, or to
+// nil if it does not match the pattern.
+func (rs TagToTagRefSpec) DestRef(tagRef DoltRef) DoltRef {
+	if tagRef.GetType() == TagRefType {
+		captured, matches := rs.srcPattern.matches(tagRef.GetPath())
+		if matches {
+			return NewTagRef(rs.srcToDestMapper.mapBranch(captured))
+		}
+	}
+
+	return nil
+}
+
+// GetRemote returns the name of the remote being operated on.
+func (rs TagToTagRefSpec) GetRemote() string {
+	return rs.remote
+}
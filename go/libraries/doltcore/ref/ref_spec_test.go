@@ -65,6 +65,29 @@ func TestRefSpec(t *testing.T) {
 				"refs/heads/master":  "refs/heads/master",
 				"refs/heads/feature": "refs/nil/",
 			},
+		}, {
+			"origin",
+			"refs/tags/*:refs/tags/*",
+			true,
+			map[string]string{
+				"refs/tags/v1":      "refs/tags/v1",
+				"refs/tags/release": "refs/tags/release",
+				"refs/heads/master": "refs/nil/",
+			},
+		}, {
+			"",
+			"refs/tags/v1:refs/tags/v1_copy",
+			true,
+			map[string]string{
+				"refs/tags/v1":      "refs/tags/v1_copy",
+				"refs/tags/v2":      "refs/nil/",
+				"refs/heads/master": "refs/nil/",
+			},
+		}, {
+			"origin",
+			"refs/tags/*:refs/tags/v1",
+			false,
+			nil,
 		}, {
 			"origin",
 			"refs/heads/master:refs/remotes/not_borigin/mymaster",
@@ -40,6 +40,14 @@ func TestJsonMarshalAndUnmarshal(t *testing.T) {
 			NewInternalRef("create"),
 			`{"test":"refs/internal/create"}`,
 		},
+		{
+			NewTagRef("v1"),
+			`{"test":"refs/tags/v1"}`,
+		},
+		{
+			NewStashRef("0"),
+			`{"test":"refs/stashes/0"}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -134,6 +142,31 @@ func TestEqualsStr(t *testing.T) {
 			"refs/internal/create",
 			true,
 		},
+		{
+			NewTagRef("v1"),
+			"refs/tags/v1",
+			true,
+		},
+		{
+			NewTagRef("refs/tags/v1"),
+			"refs/tags/v1",
+			true,
+		},
+		{
+			NewTagRef("v1"),
+			"refs/heads/v1",
+			false,
+		},
+		{
+			NewStashRef("refs/stashes/0"),
+			"refs/stashes/0",
+			true,
+		},
+		{
+			NewStashRef("0"),
+			"refs/tags/0",
+			false,
+		},
 	}
 
 	for _, test := range tests {
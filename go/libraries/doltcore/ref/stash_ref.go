@@ -0,0 +1,53 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ref
+
+import "strings"
+
+// StashRef is a reference to a stashed working set in the format refs/stashes/...  Stash refs are internal to a
+// repository and are never pushed or fetched.
+type StashRef struct {
+	stash string
+}
+
+func (sr StashRef) GetType() RefType {
+	return StashRefType
+}
+
+func (sr StashRef) GetPath() string {
+	return sr.stash
+}
+
+func (sr StashRef) String() string {
+	return String(sr)
+}
+
+func (sr StashRef) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(sr)
+}
+
+// NewStashRef creates a StashRef from a stash id or from a reference string in the format refs/stashes/...
+func NewStashRef(stashId string) StashRef {
+	if IsRef(stashId) {
+		prefix := PrefixForType(StashRefType)
+		if strings.HasPrefix(stashId, prefix) {
+			stashId = stashId[len(prefix):]
+		} else {
+			panic(stashId + " is a ref that is not of type " + prefix)
+		}
+	}
+
+	return StashRef{stashId}
+}
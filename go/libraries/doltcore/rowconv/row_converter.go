@@ -162,26 +162,46 @@ func GetRowConvTransformFunc(rc *RowConverter) func(row.Row, pipeline.ReadableMa
 			return []*pipeline.TransformedRowResult{{RowData: inRow, PropertyUpdates: nil}}, ""
 		}
 	} else {
-		return func(inRow row.Row, props pipeline.ReadableMap) (outRows []*pipeline.TransformedRowResult, badRowDetails string) {
-			outRow, err := rc.Convert(inRow)
+		return GetRowConvTransformFuncWithFiller(rc, nil)
+	}
+}
+
+// GetRowConvTransformFuncWithFiller returns a transform func like GetRowConvTransformFunc's, which also passes each
+// converted row to fill before it is validated.  fill gives values to the columns the conversion didn't, and may be nil.
+// Unlike GetRowConvTransformFunc's, the rows of identity converters are validated.
+func GetRowConvTransformFuncWithFiller(rc *RowConverter, fill func(row.Row) (row.Row, error)) func(row.Row, pipeline.ReadableMap) ([]*pipeline.TransformedRowResult, string) {
+	return func(inRow row.Row, props pipeline.ReadableMap) (outRows []*pipeline.TransformedRowResult, badRowDetails string) {
+		outRow := inRow
+		if !rc.IdentityConverter {
+			var err error
+			outRow, err = rc.Convert(inRow)
 
 			if err != nil {
 				return nil, err.Error()
 			}
+		}
+
+		if fill != nil {
+			var err error
+			outRow, err = fill(outRow)
 
-			if isv, err := row.IsValid(outRow, rc.DestSch); err != nil {
+			if err != nil {
 				return nil, err.Error()
-			} else if !isv {
-				col, err := row.GetInvalidCol(outRow, rc.DestSch)
-
-				if err != nil {
-					return nil, "invalid column"
-				} else {
-					return nil, "invalid column: " + col.Name
-				}
 			}
+		}
+
+		if isv, err := row.IsValid(outRow, rc.DestSch); err != nil {
+			return nil, err.Error()
+		} else if !isv {
+			col, err := row.GetInvalidCol(outRow, rc.DestSch)
 
-			return []*pipeline.TransformedRowResult{{RowData: outRow, PropertyUpdates: nil}}, ""
+			if err != nil {
+				return nil, "invalid column"
+			} else {
+				return nil, "invalid column: " + col.Name
+			}
 		}
+
+		return []*pipeline.TransformedRowResult{{RowData: outRow, PropertyUpdates: nil}}, ""
 	}
 }
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/liquidata-inc/dolt/go/libraries/utils/set"
 
@@ -176,15 +177,23 @@ func (ssg *SuperSchemaGen) nameCols() map[TagKindPair]string {
 func (ssg *SuperSchemaGen) GenerateSuperSchema(additionalCols ...NameKindPair) (SuperSchema, error) {
 	namedCols := ssg.nameCols()
 
-	colColl, _ := schema.NewColCollection()
-	for tagKind, colName := range namedCols {
-		destTag, ok := ssg.tagKindToDestTag[tagKind]
-
-		if !ok {
+	// columns are ordered by tag so that the generated schema is the same every time
+	tagKinds := make([]TagKindPair, 0, len(namedCols))
+	for tagKind := range namedCols {
+		if _, ok := ssg.tagKindToDestTag[tagKind]; !ok {
 			panic("mismatch between namedCols and tagKindToDestTag")
 		}
 
-		col := schema.NewColumn(colName, destTag, tagKind.Kind, false)
+		tagKinds = append(tagKinds, tagKind)
+	}
+
+	sort.Slice(tagKinds, func(i, j int) bool {
+		return ssg.tagKindToDestTag[tagKinds[i]] < ssg.tagKindToDestTag[tagKinds[j]]
+	})
+
+	colColl, _ := schema.NewColCollection()
+	for _, tagKind := range tagKinds {
+		col := schema.NewColumn(namedCols[tagKind], ssg.tagKindToDestTag[tagKind], tagKind.Kind, false)
 
 		var err error
 		colColl, err = colColl.Append(col)
@@ -35,6 +35,7 @@ const (
 
 // Adds a new column to the schema given and returns the new table value. Non-null column additions rewrite the entire
 // table, since we must write a value for each row. If the column is not nullable, a default value must be provided.
+// The default value is also stored as the column's default, for rows inserted later without a value for the column.
 //
 // Returns an error if the column added conflicts with the existing schema in tag or name.
 func AddColumnToTable(ctx context.Context, db *doltdb.DoltDB, tbl *doltdb.Table, tag uint64, newColName string, colKind types.NomsKind, nullable Nullable, defaultVal types.Value) (*doltdb.Table, error) {
@@ -48,7 +49,7 @@ func AddColumnToTable(ctx context.Context, db *doltdb.DoltDB, tbl *doltdb.Table,
 		return nil, err
 	}
 
-	newSchema, err := createNewSchema(sch, tag, newColName, colKind, nullable)
+	newSchema, err := createNewSchema(sch, tag, newColName, colKind, nullable, defaultVal)
 	if err != nil {
 		return nil, err
 	}
@@ -72,7 +73,19 @@ func updateTableWithNewSchema(ctx context.Context, db *doltdb.DoltDB, tbl *doltd
 	}
 
 	if defaultVal == nil {
-		return doltdb.NewTable(ctx, vrw, newSchemaVal, rowData)
+		newTable, err := doltdb.NewTable(ctx, vrw, newSchemaVal, rowData)
+
+		if err != nil {
+			return nil, err
+		}
+
+		newTable, err = newTable.CopyAutoIncrementValue(tbl)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return newTable.CopyIndexes(ctx, tbl)
 	}
 
 	me := rowData.Edit()
@@ -102,11 +115,23 @@ func updateTableWithNewSchema(ctx context.Context, db *doltdb.DoltDB, tbl *doltd
 		return nil, err
 	}
 
-	return doltdb.NewTable(ctx, vrw, newSchemaVal, m)
+	newTable, err := doltdb.NewTable(ctx, vrw, newSchemaVal, m)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newTable, err = newTable.CopyAutoIncrementValue(tbl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTable.CopyIndexes(ctx, tbl)
 }
 
 // createNewSchema Creates a new schema with a column as specified by the params.
-func createNewSchema(sch schema.Schema, tag uint64, newColName string, colKind types.NomsKind, nullable Nullable) (schema.Schema, error) {
+func createNewSchema(sch schema.Schema, tag uint64, newColName string, colKind types.NomsKind, nullable Nullable, defaultVal types.Value) (schema.Schema, error) {
 	var col schema.Column
 	if nullable {
 		col = schema.NewColumn(newColName, tag, colKind, false)
@@ -114,12 +139,21 @@ func createNewSchema(sch schema.Schema, tag uint64, newColName string, colKind t
 		col = schema.NewColumn(newColName, tag, colKind, false, schema.NotNullConstraint{})
 	}
 
+	if defaultVal != nil {
+		var err error
+		col.Default, err = schema.DefaultLiteral(defaultVal)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	updatedCols, err := sch.GetAllCols().Append(col)
 	if err != nil {
 		return nil, err
 	}
 
-	return schema.SchemaFromCols(updatedCols), nil
+	return schema.SchemaWithIndexesAndForeignKeysOf(schema.SchemaFromCols(updatedCols), sch), nil
 }
 
 // validateNewColumn returns an error if the column as specified cannot be added to the schema given.
@@ -113,7 +113,7 @@ func TestAddColumnToTable(t *testing.T) {
 			nullable:   NotNull,
 			defaultVal: types.String("default"),
 			expectedSchema: dtestutils.AddColumnToSchema(dtestutils.TypedSchema,
-				schema.NewColumn("newCol", dtestutils.NextTag, types.StringKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("newCol", dtestutils.NextTag, types.StringKind, false, schema.NotNullConstraint{}), "'default'")),
 			expectedRows: dtestutils.AddColToRows(t, dtestutils.TypedRows, dtestutils.NextTag, types.String("default")),
 		},
 		{
@@ -124,7 +124,7 @@ func TestAddColumnToTable(t *testing.T) {
 			nullable:   NotNull,
 			defaultVal: types.Int(42),
 			expectedSchema: dtestutils.AddColumnToSchema(dtestutils.TypedSchema,
-				schema.NewColumn("newCol", dtestutils.NextTag, types.IntKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("newCol", dtestutils.NextTag, types.IntKind, false, schema.NotNullConstraint{}), "42")),
 			expectedRows: dtestutils.AddColToRows(t, dtestutils.TypedRows, dtestutils.NextTag, types.Int(42)),
 		},
 		{
@@ -135,7 +135,7 @@ func TestAddColumnToTable(t *testing.T) {
 			nullable:   NotNull,
 			defaultVal: types.Uint(64),
 			expectedSchema: dtestutils.AddColumnToSchema(dtestutils.TypedSchema,
-				schema.NewColumn("newCol", dtestutils.NextTag, types.UintKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("newCol", dtestutils.NextTag, types.UintKind, false, schema.NotNullConstraint{}), "64")),
 			expectedRows: dtestutils.AddColToRows(t, dtestutils.TypedRows, dtestutils.NextTag, types.Uint(64)),
 		},
 		{
@@ -146,7 +146,7 @@ func TestAddColumnToTable(t *testing.T) {
 			nullable:   NotNull,
 			defaultVal: types.Float(33.33),
 			expectedSchema: dtestutils.AddColumnToSchema(dtestutils.TypedSchema,
-				schema.NewColumn("newCol", dtestutils.NextTag, types.FloatKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("newCol", dtestutils.NextTag, types.FloatKind, false, schema.NotNullConstraint{}), "33.33")),
 			expectedRows: dtestutils.AddColToRows(t, dtestutils.TypedRows, dtestutils.NextTag, types.Float(33.33)),
 		},
 		{
@@ -157,7 +157,7 @@ func TestAddColumnToTable(t *testing.T) {
 			nullable:   NotNull,
 			defaultVal: types.Bool(true),
 			expectedSchema: dtestutils.AddColumnToSchema(dtestutils.TypedSchema,
-				schema.NewColumn("newCol", dtestutils.NextTag, types.BoolKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("newCol", dtestutils.NextTag, types.BoolKind, false, schema.NotNullConstraint{}), "TRUE")),
 			expectedRows: dtestutils.AddColToRows(t, dtestutils.TypedRows, dtestutils.NextTag, types.Bool(true)),
 		},
 		{
@@ -168,7 +168,7 @@ func TestAddColumnToTable(t *testing.T) {
 			nullable:   NotNull,
 			defaultVal: types.UUID(uuid.MustParse("00000000-0000-0000-0000-000000000000")),
 			expectedSchema: dtestutils.AddColumnToSchema(dtestutils.TypedSchema,
-				schema.NewColumn("newCol", dtestutils.NextTag, types.UUIDKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("newCol", dtestutils.NextTag, types.UUIDKind, false, schema.NotNullConstraint{}), "'00000000-0000-0000-0000-000000000000'")),
 			expectedRows: dtestutils.AddColToRows(t,
 				dtestutils.TypedRows, dtestutils.NextTag, types.UUID(uuid.MustParse("00000000-0000-0000-0000-000000000000"))),
 		},
@@ -191,7 +191,7 @@ func TestAddColumnToTable(t *testing.T) {
 			nullable:   Null,
 			defaultVal: types.Int(42),
 			expectedSchema: dtestutils.AddColumnToSchema(dtestutils.TypedSchema,
-				schema.NewColumn("newCol", dtestutils.NextTag, types.IntKind, false)),
+				withDefault(schema.NewColumn("newCol", dtestutils.NextTag, types.IntKind, false), "42")),
 			expectedRows: dtestutils.AddColToRows(t, dtestutils.TypedRows, dtestutils.NextTag, types.Int(42)),
 		},
 		{
@@ -276,6 +276,11 @@ func TestAddColumnToTable(t *testing.T) {
 	}
 }
 
+func withDefault(col schema.Column, lit string) schema.Column {
+	col.Default = lit
+	return col
+}
+
 func createEnvWithSeedData(t *testing.T) *env.DoltEnv {
 	dEnv := dtestutils.CreateTestEnv()
 	imt, sch := dtestutils.CreateTestDataTable(true)
@@ -17,13 +17,15 @@ package alterschema
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/encoding"
 )
 
-// DropColumn drops a column from a table. No existing rows are modified, but a new schema entry is written.
+// DropColumn drops a column from a table. No existing rows are modified, but a new schema entry is written. Any index
+// that includes the column is dropped along with it.
 func DropColumn(ctx context.Context, doltDB *doltdb.DoltDB, tbl *doltdb.Table, colName string) (*doltdb.Table, error) {
 	if tbl == nil || doltDB == nil {
 		panic("invalid parameters")
@@ -41,6 +43,20 @@ func DropColumn(ctx context.Context, doltDB *doltdb.DoltDB, tbl *doltdb.Table, c
 		return nil, schema.ErrColNotFound
 	} else if col.IsPartOfPK {
 		return nil, errors.New("Cannot drop column in primary key")
+	} else if mp := tblSch.MergePolicy(); mp.Type == schema.LatestByMergePolicy && mp.ByTag == col.Tag {
+		return nil, errors.New("Cannot drop column used by the default merge policy of the table")
+	} else {
+		err = allCols.Iter(func(tag uint64, other schema.Column) (stop bool, err error) {
+			if other.MergePolicy.Type == schema.LatestByMergePolicy && other.MergePolicy.ByTag == col.Tag {
+				return true, fmt.Errorf("Cannot drop column used by the merge policy of column '%s'", other.Name)
+			}
+
+			return false, nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	cols := make([]schema.Column, 0)
@@ -60,7 +76,7 @@ func DropColumn(ctx context.Context, doltDB *doltdb.DoltDB, tbl *doltdb.Table, c
 		return nil, err
 	}
 
-	newSch := schema.SchemaFromCols(colColl)
+	newSch := schema.SchemaWithIndexesAndForeignKeysOf(schema.SchemaFromCols(colColl), tblSch)
 
 	vrw := doltDB.ValueReadWriter()
 	schemaVal, err := encoding.MarshalAsNomsValue(ctx, vrw, newSch)
@@ -81,5 +97,11 @@ func DropColumn(ctx context.Context, doltDB *doltdb.DoltDB, tbl *doltdb.Table, c
 		return nil, err
 	}
 
-	return newTable, nil
+	newTable, err = newTable.CopyAutoIncrementValue(tbl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTable.CopyIndexes(ctx, tbl)
 }
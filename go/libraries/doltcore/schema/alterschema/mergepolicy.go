@@ -0,0 +1,116 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alterschema
+
+import (
+	"context"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/encoding"
+)
+
+// SetMergePolicy parses policyStr and sets it as the merge policy of the column named colName.  If colName is empty the
+// policy is set as the default merge policy of the table, which is used by every column which isn't part of the primary
+// key and doesn't have a policy of its own, including columns added later.  No rows are modified.
+func SetMergePolicy(ctx context.Context, doltDB *doltdb.DoltDB, tbl *doltdb.Table, colName, policyStr string) (*doltdb.Table, error) {
+	if tbl == nil || doltDB == nil {
+		panic("invalid parameters")
+	}
+
+	tblSch, err := tbl.GetSchema(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var newSch schema.Schema
+	if colName == "" {
+		mp, err := schema.ParseDefaultMergePolicy(policyStr, tblSch)
+
+		if err != nil {
+			return nil, err
+		}
+
+		newSch = schema.SchemaWithMergePolicy(tblSch, mp)
+	} else {
+		newSch, err = setColumnMergePolicy(tblSch, colName, policyStr)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	vrw := doltDB.ValueReadWriter()
+	schemaVal, err := encoding.MarshalAsNomsValue(ctx, vrw, newSch)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rd, err := tbl.GetRowData(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newTable, err := doltdb.NewTable(ctx, vrw, schemaVal, rd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newTable, err = newTable.CopyAutoIncrementValue(tbl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTable.CopyIndexes(ctx, tbl)
+}
+
+func setColumnMergePolicy(tblSch schema.Schema, colName, policyStr string) (schema.Schema, error) {
+	allCols := tblSch.GetAllCols()
+
+	if _, ok := allCols.GetByName(colName); !ok {
+		return nil, schema.ErrColNotFound
+	}
+
+	cols := make([]schema.Column, 0)
+	err := allCols.Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		if col.Name == colName {
+			col.MergePolicy, err = schema.ParseMergePolicy(policyStr, tblSch, col)
+
+			if err != nil {
+				return true, err
+			}
+		}
+
+		cols = append(cols, col)
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	colColl, err := schema.NewColCollection(cols...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return schema.SchemaWithIndexesAndForeignKeysOf(schema.SchemaFromCols(colColl), tblSch), nil
+}
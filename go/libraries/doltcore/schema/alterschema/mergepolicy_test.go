@@ -0,0 +1,129 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alterschema
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+)
+
+func TestSetMergePolicy(t *testing.T) {
+	tests := []struct {
+		name             string
+		colName          string
+		policy           string
+		expectedPolicies map[uint64]schema.MergePolicy
+		expectedDefault  schema.MergePolicy
+		expectedErr      string
+	}{
+		{
+			name:    "set column policy",
+			colName: "age",
+			policy:  "max",
+			expectedPolicies: map[uint64]schema.MergePolicy{
+				dtestutils.AgeTag: {Type: schema.MaxMergePolicy},
+			},
+		},
+		{
+			name:    "set latest-by policy",
+			colName: "title",
+			policy:  "latest-by:age",
+			expectedPolicies: map[uint64]schema.MergePolicy{
+				dtestutils.TitleTag: {Type: schema.LatestByMergePolicy, ByTag: dtestutils.AgeTag},
+			},
+		},
+		{
+			name:            "set table policy",
+			policy:          "theirs",
+			expectedDefault: schema.MergePolicy{Type: schema.TheirsMergePolicy},
+		},
+		{
+			name:            "set table policy not valid for every column type",
+			policy:          "sum-of-deltas",
+			expectedDefault: schema.MergePolicy{Type: schema.SumOfDeltasMergePolicy},
+		},
+		{
+			name:            "set table latest-by policy",
+			policy:          "latest-by:age",
+			expectedDefault: schema.MergePolicy{Type: schema.LatestByMergePolicy, ByTag: dtestutils.AgeTag},
+		},
+		{
+			name:        "table latest-by policy referring to a primary key column",
+			policy:      "latest-by:id",
+			expectedErr: "primary key column 'id'",
+		},
+		{
+			name:        "column not found",
+			colName:     "not found",
+			policy:      "ours",
+			expectedErr: "column not found",
+		},
+		{
+			name:        "unknown policy",
+			colName:     "age",
+			policy:      "average",
+			expectedErr: "unknown merge policy",
+		},
+		{
+			name:        "policy not valid for column type",
+			colName:     "title",
+			policy:      "sum-of-deltas",
+			expectedErr: "can't be used with the string column 'title'",
+		},
+		{
+			name:        "primary key column",
+			colName:     "id",
+			policy:      "ours",
+			expectedErr: "primary key column 'id'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dEnv := createEnvWithSeedData(t)
+			ctx := context.Background()
+
+			root, err := dEnv.WorkingRoot(ctx)
+			assert.NoError(t, err)
+			tbl, _, err := root.GetTable(ctx, tableName)
+			require.NoError(t, err)
+
+			updatedTable, err := SetMergePolicy(ctx, dEnv.DoltDB, tbl, tt.colName, tt.policy)
+			if len(tt.expectedErr) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			} else {
+				require.NoError(t, err)
+			}
+
+			sch, err := updatedTable.GetSchema(ctx)
+			require.NoError(t, err)
+
+			err = sch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+				assert.Equal(t, tt.expectedPolicies[tag], col.MergePolicy, col.Name)
+				return false, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDefault, sch.MergePolicy())
+		})
+	}
+}
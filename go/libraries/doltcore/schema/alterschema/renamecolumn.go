@@ -64,7 +64,7 @@ func RenameColumn(ctx context.Context, doltDB *doltdb.DoltDB, tbl *doltdb.Table,
 		return nil, err
 	}
 
-	newSch := schema.SchemaFromCols(colColl)
+	newSch := schema.SchemaWithIndexesAndForeignKeysOf(schema.SchemaFromCols(colColl), tblSch)
 
 	vrw := doltDB.ValueReadWriter()
 	schemaVal, err := encoding.MarshalAsNomsValue(ctx, vrw, newSch)
@@ -85,5 +85,11 @@ func RenameColumn(ctx context.Context, doltDB *doltdb.DoltDB, tbl *doltdb.Table,
 		return nil, err
 	}
 
-	return newTable, nil
+	newTable, err = newTable.CopyAutoIncrementValue(tbl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newTable.CopyIndexes(ctx, tbl)
 }
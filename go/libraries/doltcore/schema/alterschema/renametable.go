@@ -18,9 +18,11 @@ import (
 	"context"
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
 )
 
-// RenameTable renames a table with in a RootValue and returns the updated root.
+// RenameTable renames a table with in a RootValue and returns the updated root.  Foreign keys which reference the table
+// are updated to reference its new name.
 func RenameTable(ctx context.Context, doltDb *doltdb.DoltDB, root *doltdb.RootValue, oldName, newName string) (*doltdb.RootValue, error) {
 	if newName == oldName {
 		return root, nil
@@ -44,9 +46,77 @@ func RenameTable(ctx context.Context, doltDb *doltdb.DoltDB, root *doltdb.RootVa
 		return nil, doltdb.ErrTableExists
 	}
 
+	referencing, err := root.ForeignKeysReferencing(ctx, oldName)
+
+	if err != nil {
+		return nil, err
+	}
+
 	if root, err = root.RemoveTables(ctx, oldName); err != nil {
 		return nil, err
 	}
 
-	return root.PutTable(ctx, newName, tbl)
+	if root, err = root.PutTable(ctx, newName, tbl); err != nil {
+		return nil, err
+	}
+
+	updated := make(map[string]bool)
+	for _, tblFK := range referencing {
+		childName := tblFK.Table
+		if childName == oldName {
+			childName = newName
+		}
+
+		if updated[childName] {
+			continue
+		}
+
+		updated[childName] = true
+		if root, err = renameForeignKeyReferences(ctx, root, childName, oldName, newName); err != nil {
+			return nil, err
+		}
+	}
+
+	return root, nil
+}
+
+// renameForeignKeyReferences updates the foreign keys of the named child table which reference oldName to reference
+// newName instead.
+func renameForeignKeyReferences(ctx context.Context, root *doltdb.RootValue, childName, oldName, newName string) (*doltdb.RootValue, error) {
+	tbl, _, err := root.GetTable(ctx, childName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sch, err := tbl.GetSchema(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	foreignKeys := sch.ForeignKeys().GetForeignKeys()
+	for i := range foreignKeys {
+		if foreignKeys[i].ReferencedTable == oldName {
+			foreignKeys[i].ReferencedTable = newName
+		}
+	}
+
+	fkColl, err := schema.NewForeignKeyCollection(foreignKeys...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newSch, err := schema.SchemaWithForeignKeys(sch, fkColl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tbl, err = tbl.UpdateSchema(ctx, newSch); err != nil {
+		return nil, err
+	}
+
+	return root.PutTable(ctx, childName, tbl)
 }
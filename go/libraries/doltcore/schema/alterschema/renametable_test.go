@@ -104,3 +104,33 @@ func TestRenameTable(t *testing.T) {
 		})
 	}
 }
+
+func TestRenameTableUpdatesForeignKeys(t *testing.T) {
+	dEnv := createEnvWithSeedData(t)
+	ctx := context.Background()
+
+	pkTag := dtestutils.UntypedSchema.GetPKCols().Tags[0]
+	fkColl, err := schema.NewForeignKeyCollection(
+		schema.ForeignKey{Name: "fk_people", Tags: []uint64{pkTag}, ReferencedTable: "people", ReferencedTags: []uint64{dtestutils.IdTag}},
+	)
+	require.NoError(t, err)
+	childSch, err := schema.SchemaWithForeignKeys(dtestutils.UntypedSchema, fkColl)
+	require.NoError(t, err)
+	dtestutils.CreateTestTable(t, dEnv, "other", childSch)
+
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	updatedRoot, err := RenameTable(ctx, dEnv.DoltDB, root, "people", "newPeople")
+	require.NoError(t, err)
+
+	child, ok, err := updatedRoot.GetTable(ctx, "other")
+	require.NoError(t, err)
+	require.True(t, ok)
+	sch, err := child.GetSchema(ctx)
+	require.NoError(t, err)
+
+	fk, ok := sch.ForeignKeys().GetByName("fk_people")
+	require.True(t, ok)
+	assert.Equal(t, "newPeople", fk.ReferencedTable)
+}
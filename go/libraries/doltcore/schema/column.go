@@ -59,6 +59,16 @@ type Column struct {
 
 	// Constraints are rules that can be checked on each column to say if the columns value is valid
 	Constraints []ColConstraint
+
+	// MergePolicy is the rule used to merge cells of this column which were changed differently on both sides of a merge
+	MergePolicy MergePolicy
+
+	// Default is the value given to the column by rows inserted without one, written as a SQL literal such as 'abc', 10
+	// or NULL.  Empty if the column has no default.
+	Default string
+
+	// AutoIncrement says whether values are generated for the column for rows inserted without one
+	AutoIncrement bool
 }
 
 // NewColumn creates a Column instance
@@ -75,6 +85,9 @@ func NewColumn(name string, tag uint64, kind types.NomsKind, partOfPK bool, cons
 		kind,
 		partOfPK,
 		constraints,
+		MergePolicy{},
+		"",
+		false,
 	}
 }
 
@@ -94,7 +107,10 @@ func (c Column) Equals(other Column) bool {
 		c.Tag == other.Tag &&
 		c.Kind == other.Kind &&
 		c.IsPartOfPK == other.IsPartOfPK &&
-		ColConstraintsAreEqual(c.Constraints, other.Constraints)
+		ColConstraintsAreEqual(c.Constraints, other.Constraints) &&
+		c.MergePolicy == other.MergePolicy &&
+		c.Default == other.Default &&
+		c.AutoIncrement == other.AutoIncrement
 }
 
 // KindString returns the string representation of the NomsKind stored in the column.
@@ -0,0 +1,179 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// ErrInvalidAutoIncrementCol is returned when a schema has an auto increment column which isn't its only primary key
+// column, or which doesn't hold integers.
+var ErrInvalidAutoIncrementCol = errors.New("an auto increment column must be an integer column and the only primary key column")
+
+// DefaultValue returns the default value of the column, or nil if the column has no default.  An error is returned if
+// the default can't be converted to the column's kind, or if it's NULL and the column isn't nullable.
+func (c Column) DefaultValue() (types.Value, error) {
+	if c.Default == "" {
+		return nil, nil
+	}
+
+	val, err := parseDefault(c.Default, c.Kind)
+
+	if err != nil || (types.IsNull(val) && !c.IsNullable()) {
+		return nil, fmt.Errorf("invalid default value for column '%s': %s", c.Name, c.Default)
+	}
+
+	return val, nil
+}
+
+// DefaultLiteral returns the SQL literal used to store val as the default value of a column.
+func DefaultLiteral(val types.Value) (string, error) {
+	if types.IsNull(val) {
+		return "NULL", nil
+	}
+
+	switch val.Kind() {
+	case types.BoolKind:
+		if val.(types.Bool) {
+			return "TRUE", nil
+		}
+
+		return "FALSE", nil
+	case types.IntKind, types.UintKind, types.FloatKind, types.StringKind, types.UUIDKind:
+		convFn, err := doltcore.GetConvFunc(val.Kind(), types.StringKind)
+
+		if err != nil {
+			return "", err
+		}
+
+		str, err := convFn(val)
+
+		if err != nil {
+			return "", err
+		}
+
+		if val.Kind() == types.StringKind || val.Kind() == types.UUIDKind {
+			return quoteDefault(string(str.(types.String))), nil
+		}
+
+		return string(str.(types.String)), nil
+	default:
+		return "", fmt.Errorf("default values of type %s are not supported", KindToLwrStr[val.Kind()])
+	}
+}
+
+// ValidateAutoIncrement returns ErrInvalidAutoIncrementCol if the schema has an auto increment column which can't hold
+// generated values.
+func ValidateAutoIncrement(allCols *ColCollection) error {
+	var numPKCols int
+	var autoIncCols []Column
+	for _, col := range allCols.cols {
+		if col.IsPartOfPK {
+			numPKCols++
+		}
+
+		if col.AutoIncrement {
+			autoIncCols = append(autoIncCols, col)
+		}
+	}
+
+	for _, col := range autoIncCols {
+		if !col.IsPartOfPK || numPKCols != 1 || (col.Kind != types.IntKind && col.Kind != types.UintKind) {
+			return ErrInvalidAutoIncrementCol
+		}
+	}
+
+	return nil
+}
+
+// AutoIncrementCol returns the auto increment column of the schema.  Returns false if the schema doesn't have one.
+func AutoIncrementCol(sch Schema) (Column, bool) {
+	for _, col := range sch.GetPKCols().cols {
+		if col.AutoIncrement {
+			return col, true
+		}
+	}
+
+	return Column{}, false
+}
+
+func parseDefault(lit string, kind types.NomsKind) (types.Value, error) {
+	lit = strings.TrimSpace(lit)
+
+	if strings.EqualFold(lit, "null") {
+		return types.NullValue, nil
+	}
+
+	str := lit
+	if len(lit) >= 2 && (lit[0] == '\'' || lit[0] == '"') && lit[len(lit)-1] == lit[0] {
+		str = unquoteDefault(lit)
+	}
+
+	if kind == types.StringKind {
+		return types.String(str), nil
+	}
+
+	return doltcore.StringToValue(str, kind)
+}
+
+func quoteDefault(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+// unquoteDefault removes the quotes from a quoted SQL string literal, and replaces its escape sequences, including
+// doubled quote characters, with the characters they represent.
+func unquoteDefault(lit string) string {
+	quote := lit[0]
+	lit = lit[1 : len(lit)-1]
+
+	var sb strings.Builder
+	for i := 0; i < len(lit); i++ {
+		ch := lit[i]
+
+		if ch == '\\' && i+1 < len(lit) {
+			i++
+			switch lit[i] {
+			case '0':
+				sb.WriteByte(0)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'Z':
+				sb.WriteByte(26)
+			default:
+				sb.WriteByte(lit[i])
+			}
+		} else if ch == quote && i+1 < len(lit) && lit[i+1] == quote {
+			sb.WriteByte(quote)
+			i++
+		} else {
+			sb.WriteByte(ch)
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		kind     types.NomsKind
+		notNull  bool
+		lit      string
+		expected types.Value
+		expErr   bool
+	}{
+		{types.StringKind, false, "", nil, false},
+		{types.StringKind, false, "'abc'", types.String("abc"), false},
+		{types.StringKind, false, `"say ""hi"""`, types.String(`say "hi"`), false},
+		{types.StringKind, false, `'it\'s\n'`, types.String("it's\n"), false},
+		{types.StringKind, false, "''", types.String(""), false},
+		{types.StringKind, false, "NULL", types.NullValue, false},
+		{types.StringKind, true, "null", nil, true},
+		{types.IntKind, true, "-12", types.Int(-12), false},
+		{types.IntKind, true, "'7'", types.Int(7), false},
+		{types.IntKind, true, "abc", nil, true},
+		{types.UintKind, true, "3", types.Uint(3), false},
+		{types.FloatKind, true, "1.5", types.Float(1.5), false},
+		{types.BoolKind, true, "TRUE", types.Bool(true), false},
+		{types.BoolKind, true, "0", types.Bool(false), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.lit, func(t *testing.T) {
+			col := NewColumn("col", 0, test.kind, false)
+			if test.notNull {
+				col.Constraints = []ColConstraint{NotNullConstraint{}}
+			}
+			col.Default = test.lit
+
+			val, err := col.DefaultValue()
+
+			if test.expErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, val)
+			}
+		})
+	}
+}
+
+func TestDefaultLiteral(t *testing.T) {
+	tests := []struct {
+		val      types.Value
+		kind     types.NomsKind
+		expected string
+	}{
+		{types.String("it's"), types.StringKind, `'it\'s'`},
+		{types.String(`back\slash`), types.StringKind, `'back\\slash'`},
+		{types.Int(-3), types.IntKind, "-3"},
+		{types.Uint(3), types.UintKind, "3"},
+		{types.Float(2.5), types.FloatKind, "2.5"},
+		{types.Bool(false), types.BoolKind, "FALSE"},
+		{types.NullValue, types.StringKind, "NULL"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			lit, err := DefaultLiteral(test.val)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, lit)
+
+			// the literal must be parsed back into the same value
+			col := NewColumn("col", 0, test.kind, false)
+			col.Default = lit
+			val, err := col.DefaultValue()
+			require.NoError(t, err)
+			assert.Equal(t, test.val, val)
+		})
+	}
+
+	_, err := DefaultLiteral(types.Blob{})
+	assert.Error(t, err)
+}
+
+func TestValidateAutoIncrement(t *testing.T) {
+	autoIncCol := func(name string, tag uint64, kind types.NomsKind, partOfPK bool) Column {
+		col := NewColumn(name, tag, kind, partOfPK)
+		col.AutoIncrement = true
+		return col
+	}
+
+	tests := []struct {
+		name   string
+		cols   []Column
+		expErr bool
+	}{
+		{"int pk", []Column{autoIncCol("id", 0, types.IntKind, true), NewColumn("name", 1, types.StringKind, false)}, false},
+		{"uint pk", []Column{autoIncCol("id", 0, types.UintKind, true)}, false},
+		{"string pk", []Column{autoIncCol("id", 0, types.StringKind, true)}, true},
+		{"non-pk", []Column{NewColumn("id", 0, types.IntKind, true), autoIncCol("seq", 1, types.IntKind, false)}, true},
+		{"compound pk", []Column{autoIncCol("id", 0, types.IntKind, true), NewColumn("id2", 1, types.IntKind, true)}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			colColl, err := NewColCollection(test.cols...)
+			require.NoError(t, err)
+
+			err = ValidateForInsert(colColl)
+
+			if test.expErr {
+				assert.Equal(t, ErrInvalidAutoIncrementCol, err)
+			} else {
+				require.NoError(t, err)
+				col, ok := AutoIncrementCol(SchemaFromCols(colColl))
+				assert.True(t, ok)
+				assert.Equal(t, "id", col.Name)
+			}
+		})
+	}
+}
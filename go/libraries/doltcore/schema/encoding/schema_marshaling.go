@@ -36,6 +36,16 @@ type encodedColumn struct {
 	IsPartOfPK bool `noms:"is_part_of_pk" json:"is_part_of_pk"`
 
 	Constraints []encodedConstraint `noms:"col_constraints" json:"col_constraints"`
+
+	// MergePolicy is the type of the column's merge policy, and MergePolicyByTag is the tag of the column referenced by
+	// the policy.  Both are omitted for columns without a merge policy.
+	MergePolicy      string `noms:"merge_policy,omitempty" json:"merge_policy,omitempty"`
+	MergePolicyByTag uint64 `noms:"merge_policy_by_tag,omitempty" json:"merge_policy_by_tag,omitempty"`
+
+	// Default is the SQL literal of the column's default value, and AutoIncrement says whether the column's values are
+	// generated.  Both are omitted for columns without them.
+	Default       string `noms:"default,omitempty" json:"default,omitempty"`
+	AutoIncrement bool   `noms:"auto_increment,omitempty" json:"auto_increment,omitempty"`
 }
 
 func encodeAllColConstraints(constraints []schema.ColConstraint) []encodedConstraint {
@@ -69,12 +79,20 @@ func encodeColumn(col schema.Column) encodedColumn {
 		col.Name,
 		col.KindString(),
 		col.IsPartOfPK,
-		encodeAllColConstraints(col.Constraints)}
+		encodeAllColConstraints(col.Constraints),
+		string(col.MergePolicy.Type),
+		col.MergePolicy.ByTag,
+		col.Default,
+		col.AutoIncrement}
 }
 
 func (nfd encodedColumn) decodeColumn() schema.Column {
 	colConstraints := decodeAllColConstraint(nfd.Constraints)
-	return schema.NewColumn(nfd.Name, nfd.Tag, schema.LwrStrToKind[nfd.Kind], nfd.IsPartOfPK, colConstraints...)
+	col := schema.NewColumn(nfd.Name, nfd.Tag, schema.LwrStrToKind[nfd.Kind], nfd.IsPartOfPK, colConstraints...)
+	col.MergePolicy = schema.MergePolicy{Type: schema.MergePolicyType(nfd.MergePolicy), ByTag: nfd.MergePolicyByTag}
+	col.Default = nfd.Default
+	col.AutoIncrement = nfd.AutoIncrement
+	return col
 }
 
 type encodedConstraint struct {
@@ -90,8 +108,48 @@ func (encCnst encodedConstraint) decodeColConstraint() schema.ColConstraint {
 	return schema.ColConstraintFromTypeAndParams(encCnst.Type, encCnst.Params)
 }
 
+type encodedIndex struct {
+	Name   string   `noms:"name" json:"name"`
+	Tags   []uint64 `noms:"tags" json:"tags"`
+	Unique bool     `noms:"unique" json:"unique"`
+}
+
+func encodeIndex(idx schema.Index) encodedIndex {
+	return encodedIndex{idx.Name, idx.Tags, idx.Unique}
+}
+
+func (ei encodedIndex) decodeIndex() schema.Index {
+	return schema.Index{Name: ei.Name, Tags: ei.Tags, Unique: ei.Unique}
+}
+
+type encodedForeignKey struct {
+	Name            string   `noms:"name" json:"name"`
+	Tags            []uint64 `noms:"tags" json:"tags"`
+	ReferencedTable string   `noms:"ref_table" json:"ref_table"`
+	ReferencedTags  []uint64 `noms:"ref_tags" json:"ref_tags"`
+}
+
+func encodeForeignKey(fk schema.ForeignKey) encodedForeignKey {
+	return encodedForeignKey{fk.Name, fk.Tags, fk.ReferencedTable, fk.ReferencedTags}
+}
+
+func (efk encodedForeignKey) decodeForeignKey() schema.ForeignKey {
+	return schema.ForeignKey{Name: efk.Name, Tags: efk.Tags, ReferencedTable: efk.ReferencedTable, ReferencedTags: efk.ReferencedTags}
+}
+
 type schemaData struct {
 	Columns []encodedColumn `noms:"columns" json:"columns"`
+
+	// Indexes are the secondary indexes of the schema.  Omitted for schemas without indexes.
+	Indexes []encodedIndex `noms:"indexes,omitempty" json:"indexes,omitempty"`
+
+	// ForeignKeys are the foreign keys of the schema.  Omitted for schemas without foreign keys.
+	ForeignKeys []encodedForeignKey `noms:"foreign_keys,omitempty" json:"foreign_keys,omitempty"`
+
+	// MergePolicy and MergePolicyByTag are the default merge policy of the schema, encoded as they are for columns.
+	// Both are omitted for schemas without a default merge policy.
+	MergePolicy      string `noms:"merge_policy,omitempty" json:"merge_policy,omitempty"`
+	MergePolicyByTag uint64 `noms:"merge_policy_by_tag,omitempty" json:"merge_policy_by_tag,omitempty"`
 }
 
 func toSchemaData(sch schema.Schema) (schemaData, error) {
@@ -110,7 +168,18 @@ func toSchemaData(sch schema.Schema) (schemaData, error) {
 		return schemaData{}, err
 	}
 
-	return schemaData{encCols}, nil
+	var encIndexes []encodedIndex
+	for _, idx := range sch.Indexes().GetIndexes() {
+		encIndexes = append(encIndexes, encodeIndex(idx))
+	}
+
+	var encForeignKeys []encodedForeignKey
+	for _, fk := range sch.ForeignKeys().GetForeignKeys() {
+		encForeignKeys = append(encForeignKeys, encodeForeignKey(fk))
+	}
+
+	mp := sch.MergePolicy()
+	return schemaData{encCols, encIndexes, encForeignKeys, string(mp.Type), mp.ByTag}, nil
 }
 
 func (sd schemaData) decodeSchema() (schema.Schema, error) {
@@ -127,7 +196,52 @@ func (sd schemaData) decodeSchema() (schema.Schema, error) {
 		return nil, err
 	}
 
-	return schema.SchemaFromCols(colColl), nil
+	sch := schema.SchemaFromCols(colColl)
+
+	if len(sd.Indexes) > 0 {
+		indexes := make([]schema.Index, len(sd.Indexes))
+		for i, encIdx := range sd.Indexes {
+			indexes[i] = encIdx.decodeIndex()
+		}
+
+		idxColl, err := schema.NewIndexCollection(indexes...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sch, err = schema.SchemaWithIndexes(sch, idxColl)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(sd.ForeignKeys) > 0 {
+		foreignKeys := make([]schema.ForeignKey, len(sd.ForeignKeys))
+		for i, encFK := range sd.ForeignKeys {
+			foreignKeys[i] = encFK.decodeForeignKey()
+		}
+
+		fkColl, err := schema.NewForeignKeyCollection(foreignKeys...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sch, err = schema.SchemaWithForeignKeys(sch, fkColl)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if sd.MergePolicy != "" {
+		mp := schema.MergePolicy{Type: schema.MergePolicyType(sd.MergePolicy), ByTag: sd.MergePolicyByTag}
+		sch = schema.SchemaWithMergePolicy(sch, mp)
+	}
+
+	return sch, nil
 }
 
 // MarshalAsNomsValue takes a Schema and converts it to a types.Value
@@ -89,3 +89,290 @@ func TestJSONMarshalling(t *testing.T) {
 		t.Error("Value different after marshalling and unmarshalling.")
 	}
 }
+
+func TestMergePolicyMarshalling(t *testing.T) {
+	countCol := schema.NewColumn("count", 5, types.IntKind, false)
+	countCol.MergePolicy = schema.MergePolicy{Type: schema.SumOfDeltasMergePolicy}
+	firstCol := schema.NewColumn("first", 1, types.StringKind, false)
+	firstCol.MergePolicy = schema.MergePolicy{Type: schema.LatestByMergePolicy, ByTag: 6}
+	columns := []schema.Column{
+		schema.NewColumn("id", 4, types.UUIDKind, true, schema.NotNullConstraint{}),
+		firstCol,
+		countCol,
+		schema.NewColumn("updated", 6, types.TimestampKind, false),
+	}
+
+	colColl, _ := schema.NewColCollection(columns...)
+	tSchema := schema.SchemaFromCols(colColl)
+	tSchema = schema.SchemaWithMergePolicy(tSchema, schema.MergePolicy{Type: schema.LatestByMergePolicy, ByTag: 6})
+	db, err := dbfactory.MemFactory{}.CreateDB(context.Background(), types.Format_7_18, nil, nil)
+
+	if err != nil {
+		t.Fatal("Could not create in mem noms db.")
+	}
+
+	val, err := MarshalAsNomsValue(context.Background(), db, tSchema)
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as a types.Value.")
+	}
+
+	unMarshalled, err := UnmarshalNomsValue(context.Background(), types.Format_7_18, val)
+
+	if err != nil {
+		t.Fatal("Failed to unmarshal types.Value as Schema")
+	}
+
+	if !reflect.DeepEqual(tSchema, unMarshalled) {
+		t.Error("Value different after marshalling and unmarshalling.")
+	}
+
+	// schemas and columns without a merge policy must be encoded exactly as they were before merge policies existed
+	val, err = MarshalAsNomsValue(context.Background(), db, createTestSchema())
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as a types.Value.")
+	}
+
+	if _, ok, _ := val.(types.Struct).MaybeGet("merge_policy"); ok {
+		t.Error("merge_policy encoded for a schema without a default merge policy")
+	}
+
+	cols, _, err := val.(types.Struct).MaybeGet("columns")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	col, err := cols.(types.List).Get(context.Background(), 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, _ := col.(types.Struct).MaybeGet("merge_policy"); ok {
+		t.Error("merge_policy encoded for a column without a merge policy")
+	}
+}
+
+func TestDefaultAndAutoIncrementMarshalling(t *testing.T) {
+	idCol := schema.NewColumn("id", 4, types.IntKind, true, schema.NotNullConstraint{})
+	idCol.AutoIncrement = true
+	firstCol := schema.NewColumn("first", 1, types.StringKind, false)
+	firstCol.Default = `'it\'s'`
+	ageCol := schema.NewColumn("age", 3, types.UintKind, false, schema.NotNullConstraint{})
+	ageCol.Default = "0"
+
+	colColl, _ := schema.NewColCollection(idCol, firstCol, ageCol)
+	tSchema := schema.SchemaFromCols(colColl)
+	db, err := dbfactory.MemFactory{}.CreateDB(context.Background(), types.Format_7_18, nil, nil)
+
+	if err != nil {
+		t.Fatal("Could not create in mem noms db.")
+	}
+
+	val, err := MarshalAsNomsValue(context.Background(), db, tSchema)
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as a types.Value.")
+	}
+
+	unMarshalled, err := UnmarshalNomsValue(context.Background(), types.Format_7_18, val)
+
+	if err != nil {
+		t.Fatal("Failed to unmarshal types.Value as Schema")
+	}
+
+	if !reflect.DeepEqual(tSchema, unMarshalled) {
+		t.Error("Value different after marshalling and unmarshalling.")
+	}
+
+	jsonStr, err := MarshalAsJson(tSchema)
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as json.")
+	}
+
+	jsonUnmarshalled, err := UnmarshalJson(jsonStr)
+
+	if err != nil {
+		t.Fatal("Failed to unmarshal json as Schema")
+	}
+
+	if !reflect.DeepEqual(tSchema, jsonUnmarshalled) {
+		t.Error("Value different after marshalling and unmarshalling.")
+	}
+
+	// columns without defaults must be encoded exactly as they were before defaults existed
+	val, err = MarshalAsNomsValue(context.Background(), db, createTestSchema())
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as a types.Value.")
+	}
+
+	if _, ok, _ := val.(types.Struct).MaybeGet("merge_policy"); ok {
+		t.Error("merge_policy encoded for a schema without a default merge policy")
+	}
+
+	cols, _, err := val.(types.Struct).MaybeGet("columns")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	col, err := cols.(types.List).Get(context.Background(), 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, field := range []string{"default", "auto_increment"} {
+		if _, ok, _ := col.(types.Struct).MaybeGet(field); ok {
+			t.Errorf("%s encoded for a column without it", field)
+		}
+	}
+}
+
+func TestIndexMarshalling(t *testing.T) {
+	indexes, err := schema.NewIndexCollection(
+		schema.Index{Name: "idx_last", Tags: []uint64{2}},
+		schema.Index{Name: "uniq_first_age", Tags: []uint64{1, 3}, Unique: true},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tSchema, err := schema.SchemaWithIndexes(createTestSchema(), indexes)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := dbfactory.MemFactory{}.CreateDB(context.Background(), types.Format_7_18, nil, nil)
+
+	if err != nil {
+		t.Fatal("Could not create in mem noms db.")
+	}
+
+	val, err := MarshalAsNomsValue(context.Background(), db, tSchema)
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as a types.Value.")
+	}
+
+	unMarshalled, err := UnmarshalNomsValue(context.Background(), types.Format_7_18, val)
+
+	if err != nil {
+		t.Fatal("Failed to unmarshal types.Value as Schema")
+	}
+
+	if !reflect.DeepEqual(tSchema, unMarshalled) {
+		t.Error("Value different after marshalling and unmarshalling.")
+	}
+
+	jsonStr, err := MarshalAsJson(tSchema)
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as json.")
+	}
+
+	jsonUnmarshalled, err := UnmarshalJson(jsonStr)
+
+	if err != nil {
+		t.Fatal("Failed to unmarshal json as Schema")
+	}
+
+	if !reflect.DeepEqual(tSchema, jsonUnmarshalled) {
+		t.Error("Value different after marshalling and unmarshalling.")
+	}
+
+	// schemas without indexes must be encoded exactly as they were before indexes existed
+	val, err = MarshalAsNomsValue(context.Background(), db, createTestSchema())
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as a types.Value.")
+	}
+
+	if _, ok, _ := val.(types.Struct).MaybeGet("indexes"); ok {
+		t.Error("indexes encoded for a schema without indexes")
+	}
+}
+
+func TestForeignKeyMarshalling(t *testing.T) {
+	foreignKeys, err := schema.NewForeignKeyCollection(
+		schema.ForeignKey{Name: "fk_last", Tags: []uint64{2}, ReferencedTable: "families", ReferencedTags: []uint64{0}},
+		schema.ForeignKey{Name: "fk_first_age", Tags: []uint64{1, 3}, ReferencedTable: "people", ReferencedTags: []uint64{7, 8}},
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	indexes, err := schema.NewIndexCollection(schema.Index{Name: "idx_last", Tags: []uint64{2}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tSchema, err := schema.SchemaWithIndexes(createTestSchema(), indexes)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tSchema, err = schema.SchemaWithForeignKeys(tSchema, foreignKeys)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := dbfactory.MemFactory{}.CreateDB(context.Background(), types.Format_7_18, nil, nil)
+
+	if err != nil {
+		t.Fatal("Could not create in mem noms db.")
+	}
+
+	val, err := MarshalAsNomsValue(context.Background(), db, tSchema)
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as a types.Value.")
+	}
+
+	unMarshalled, err := UnmarshalNomsValue(context.Background(), types.Format_7_18, val)
+
+	if err != nil {
+		t.Fatal("Failed to unmarshal types.Value as Schema")
+	}
+
+	if !reflect.DeepEqual(tSchema, unMarshalled) {
+		t.Error("Value different after marshalling and unmarshalling.")
+	}
+
+	jsonStr, err := MarshalAsJson(tSchema)
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as json.")
+	}
+
+	jsonUnmarshalled, err := UnmarshalJson(jsonStr)
+
+	if err != nil {
+		t.Fatal("Failed to unmarshal json as Schema")
+	}
+
+	if !reflect.DeepEqual(tSchema, jsonUnmarshalled) {
+		t.Error("Value different after marshalling and unmarshalling.")
+	}
+
+	// schemas without foreign keys must be encoded exactly as they were before foreign keys existed
+	val, err = MarshalAsNomsValue(context.Background(), db, createTestSchema())
+
+	if err != nil {
+		t.Fatal("Failed to marshal Schema as a types.Value.")
+	}
+
+	if _, ok, _ := val.(types.Struct).MaybeGet("foreign_keys"); ok {
+		t.Error("foreign keys encoded for a schema without foreign keys")
+	}
+}
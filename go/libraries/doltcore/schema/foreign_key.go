@@ -0,0 +1,184 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrForeignKeyNameCollision is an error that is returned when two foreign keys within a ForeignKeyCollection have
+// the same case-insensitive name
+var ErrForeignKeyNameCollision = errors.New("two different foreign keys with the same name exist")
+
+// ErrForeignKeyNotFound is an error that is returned when attempting an operation on a foreign key that does not exist
+var ErrForeignKeyNotFound = errors.New("foreign key not found")
+
+// ErrForeignKeyColumnMismatch is an error that is returned when a foreign key has no columns, or references a
+// different number of columns than it contains
+var ErrForeignKeyColumnMismatch = errors.New("foreign key must reference the same number of columns it contains")
+
+// EmptyForeignKeyColl is a ForeignKeyCollection with no foreign keys
+var EmptyForeignKeyColl = &ForeignKeyCollection{[]ForeignKey{}, map[string]int{}}
+
+// ForeignKey is a constraint requiring that the values of one or more columns of a table, the child, match the values
+// of the referenced columns of some row in another table, the parent.  Rows with a null value in any of the foreign
+// key columns are not constrained.  The child columns are referenced by tag and the parent table by name, with the
+// parent's columns referenced by tag in the same order as the child columns they correspond to.
+type ForeignKey struct {
+	// Name is the name of the foreign key
+	Name string
+	// Tags are the tags of the constrained columns of the child table
+	Tags []uint64
+	// ReferencedTable is the name of the parent table
+	ReferencedTable string
+	// ReferencedTags are the tags of the referenced columns of the parent table
+	ReferencedTags []uint64
+}
+
+// HasTag returns true if the child column with the given tag is constrained by this foreign key
+func (fk ForeignKey) HasTag(tag uint64) bool {
+	for _, t := range fk.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// References returns true if this foreign key references the given parent table, compared case-insensitively
+func (fk ForeignKey) References(tableName string) bool {
+	return strings.ToLower(fk.ReferencedTable) == strings.ToLower(tableName)
+}
+
+// Equals returns true if the two foreign keys have the same name, columns and referenced columns
+func (fk ForeignKey) Equals(other ForeignKey) bool {
+	if fk.Name != other.Name || fk.ReferencedTable != other.ReferencedTable {
+		return false
+	}
+
+	return tagsEqual(fk.Tags, other.Tags) && tagsEqual(fk.ReferencedTags, other.ReferencedTags)
+}
+
+func tagsEqual(tags, other []uint64) bool {
+	if len(tags) != len(other) {
+		return false
+	}
+
+	for i := range tags {
+		if tags[i] != other[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ForeignKeyCollection is an ordered collection of foreign keys.  All foreign keys in the collection must have
+// unique case-insensitive names.
+type ForeignKeyCollection struct {
+	foreignKeys []ForeignKey
+	// lowerNameToIdx is a map from lower-cased foreign key name to the position of the foreign key in foreignKeys
+	lowerNameToIdx map[string]int
+}
+
+// NewForeignKeyCollection creates a new collection from a list of foreign keys.  If any two foreign keys have the same
+// case-insensitive name, or a foreign key's columns don't match the columns it references, an error is returned.
+func NewForeignKeyCollection(foreignKeys ...ForeignKey) (*ForeignKeyCollection, error) {
+	lowerNameToIdx := make(map[string]int, len(foreignKeys))
+	for i, fk := range foreignKeys {
+		if len(fk.Tags) == 0 || len(fk.Tags) != len(fk.ReferencedTags) {
+			return nil, ErrForeignKeyColumnMismatch
+		}
+
+		lwr := strings.ToLower(fk.Name)
+		if _, ok := lowerNameToIdx[lwr]; ok {
+			return nil, ErrForeignKeyNameCollision
+		}
+
+		lowerNameToIdx[lwr] = i
+	}
+
+	return &ForeignKeyCollection{append([]ForeignKey{}, foreignKeys...), lowerNameToIdx}, nil
+}
+
+// GetForeignKeys returns the foreign keys in the collection in their original order
+func (fkc *ForeignKeyCollection) GetForeignKeys() []ForeignKey {
+	return append([]ForeignKey{}, fkc.foreignKeys...)
+}
+
+// GetByName does a case-insensitive lookup of a foreign key by name
+func (fkc *ForeignKeyCollection) GetByName(name string) (ForeignKey, bool) {
+	i, ok := fkc.lowerNameToIdx[strings.ToLower(name)]
+
+	if !ok {
+		return ForeignKey{}, false
+	}
+
+	return fkc.foreignKeys[i], true
+}
+
+// Append returns a new collection containing the foreign keys of this collection followed by the given foreign keys.
+func (fkc *ForeignKeyCollection) Append(foreignKeys ...ForeignKey) (*ForeignKeyCollection, error) {
+	return NewForeignKeyCollection(append(fkc.GetForeignKeys(), foreignKeys...)...)
+}
+
+// Remove returns a new collection without the foreign key with the given case-insensitive name.
+// ErrForeignKeyNotFound is returned if there is no such foreign key.
+func (fkc *ForeignKeyCollection) Remove(name string) (*ForeignKeyCollection, error) {
+	i, ok := fkc.lowerNameToIdx[strings.ToLower(name)]
+
+	if !ok {
+		return nil, ErrForeignKeyNotFound
+	}
+
+	remaining := append(append([]ForeignKey{}, fkc.foreignKeys[:i]...), fkc.foreignKeys[i+1:]...)
+	return NewForeignKeyCollection(remaining...)
+}
+
+// Filter returns a new collection containing only the foreign keys for which the callback returns true
+func (fkc *ForeignKeyCollection) Filter(cb func(fk ForeignKey) bool) *ForeignKeyCollection {
+	var filtered []ForeignKey
+	for _, fk := range fkc.foreignKeys {
+		if cb(fk) {
+			filtered = append(filtered, fk)
+		}
+	}
+
+	// names were unique in the source collection so this cannot fail
+	coll, _ := NewForeignKeyCollection(filtered...)
+	return coll
+}
+
+// Equals returns true if both collections contain equal foreign keys in the same order
+func (fkc *ForeignKeyCollection) Equals(other *ForeignKeyCollection) bool {
+	if fkc.Size() != other.Size() {
+		return false
+	}
+
+	for i := range fkc.foreignKeys {
+		if !fkc.foreignKeys[i].Equals(other.foreignKeys[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Size returns the number of foreign keys in the collection.
+func (fkc *ForeignKeyCollection) Size() int {
+	return len(fkc.foreignKeys)
+}
@@ -0,0 +1,106 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForeignKeyCollection(t *testing.T) {
+	fkFirst := ForeignKey{Name: "fk_first", Tags: []uint64{0}, ReferencedTable: "names", ReferencedTags: []uint64{5}}
+	fkBoth := ForeignKey{Name: "fk_both", Tags: []uint64{0, 1}, ReferencedTable: "People", ReferencedTags: []uint64{5, 6}}
+
+	fkColl, err := NewForeignKeyCollection(fkFirst, fkBoth)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fkColl.Size())
+
+	fk, ok := fkColl.GetByName("FK_BOTH")
+	assert.True(t, ok)
+	assert.True(t, fk.Equals(fkBoth))
+	assert.True(t, fk.References("people"))
+	assert.False(t, fk.References("names"))
+
+	_, ok = fkColl.GetByName("missing")
+	assert.False(t, ok)
+
+	_, err = fkColl.Append(ForeignKey{Name: "FK_FIRST", Tags: []uint64{1}, ReferencedTable: "names", ReferencedTags: []uint64{5}})
+	assert.Equal(t, ErrForeignKeyNameCollision, err)
+
+	_, err = NewForeignKeyCollection(ForeignKey{Name: "empty", ReferencedTable: "names"})
+	assert.Equal(t, ErrForeignKeyColumnMismatch, err)
+
+	_, err = NewForeignKeyCollection(ForeignKey{Name: "mismatched", Tags: []uint64{0, 1}, ReferencedTable: "names", ReferencedTags: []uint64{5}})
+	assert.Equal(t, ErrForeignKeyColumnMismatch, err)
+
+	removed, err := fkColl.Remove("fk_first")
+	require.NoError(t, err)
+	assert.Equal(t, []ForeignKey{fkBoth}, removed.GetForeignKeys())
+
+	_, err = removed.Remove("fk_first")
+	assert.Equal(t, ErrForeignKeyNotFound, err)
+
+	filtered := fkColl.Filter(func(fk ForeignKey) bool { return !fk.HasTag(1) })
+	assert.Equal(t, []ForeignKey{fkFirst}, filtered.GetForeignKeys())
+	assert.False(t, filtered.Equals(fkColl))
+}
+
+func TestSchemaWithForeignKeys(t *testing.T) {
+	pkCol := Column{"id", 4, firstNameCol.Kind, true, nil, MergePolicy{}, "", false}
+	colColl, err := NewColCollection(pkCol, firstNameCol, lastNameCol)
+	require.NoError(t, err)
+	sch := SchemaFromCols(colColl)
+	assert.Equal(t, 0, sch.ForeignKeys().Size())
+
+	idxColl, err := NewIndexCollection(Index{Name: "idx_first", Tags: []uint64{0}})
+	require.NoError(t, err)
+	sch, err = SchemaWithIndexes(sch, idxColl)
+	require.NoError(t, err)
+
+	fkColl, err := NewForeignKeyCollection(
+		ForeignKey{Name: "fk_first", Tags: []uint64{0}, ReferencedTable: "names", ReferencedTags: []uint64{99}},
+		ForeignKey{Name: "fk_last", Tags: []uint64{1}, ReferencedTable: "names", ReferencedTags: []uint64{98}},
+	)
+	require.NoError(t, err)
+
+	// indexes are kept when foreign keys are set, and the referenced tags belong to another table
+	withFKs, err := SchemaWithForeignKeys(sch, fkColl)
+	require.NoError(t, err)
+	assert.True(t, withFKs.ForeignKeys().Equals(fkColl))
+	assert.True(t, withFKs.Indexes().Equals(idxColl))
+
+	badColl, err := NewForeignKeyCollection(ForeignKey{Name: "fk_missing", Tags: []uint64{99}, ReferencedTable: "names", ReferencedTags: []uint64{0}})
+	require.NoError(t, err)
+	_, err = SchemaWithForeignKeys(sch, badColl)
+	assert.Equal(t, ErrColNotFound, err)
+
+	// foreign keys over columns that no longer exist are dropped
+	withoutLast, err := NewColCollection(pkCol, firstNameCol)
+	require.NoError(t, err)
+	carried := SchemaWithIndexesAndForeignKeysOf(SchemaFromCols(withoutLast), withFKs)
+	assert.Equal(t, []string{"fk_first"}, fkNames(carried.ForeignKeys()))
+	assert.True(t, carried.Indexes().Equals(idxColl))
+}
+
+func fkNames(fkColl *ForeignKeyCollection) []string {
+	var names []string
+	for _, fk := range fkColl.GetForeignKeys() {
+		names = append(names, fk.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,166 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrIndexNameCollision is an error that is returned when two indexes within an IndexCollection have the same
+// case-insensitive name
+var ErrIndexNameCollision = errors.New("two different indexes with the same name exist")
+
+// ErrIndexNotFound is an error that is returned when attempting an operation on an index that does not exist
+var ErrIndexNotFound = errors.New("index not found")
+
+// ErrIndexHasNoColumns is an error that is returned when an index is defined without any columns
+var ErrIndexHasNoColumns = errors.New("index has no columns")
+
+// EmptyIndexColl is an IndexCollection with no indexes
+var EmptyIndexColl = &IndexCollection{[]Index{}, map[string]int{}}
+
+// Index is a secondary index over one or more columns of a table. The index is identified by its name, and the
+// columns it covers are referenced by tag in the order in which they are indexed.
+type Index struct {
+	// Name is the name of the index
+	Name string
+	// Tags are the tags of the indexed columns in index order
+	Tags []uint64
+	// Unique is true if no two rows may have the same values for the indexed columns
+	Unique bool
+}
+
+// HasTag returns true if the column with the given tag is covered by this index
+func (idx Index) HasTag(tag uint64) bool {
+	for _, t := range idx.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Equals returns true if the two indexes have the same name, tags and uniqueness
+func (idx Index) Equals(other Index) bool {
+	if idx.Name != other.Name || idx.Unique != other.Unique || len(idx.Tags) != len(other.Tags) {
+		return false
+	}
+
+	for i := range idx.Tags {
+		if idx.Tags[i] != other.Tags[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IndexCollection is an ordered collection of indexes.  All indexes in the collection must have unique
+// case-insensitive names.
+type IndexCollection struct {
+	indexes []Index
+	// lowerNameToIdx is a map from lower-cased index name to the position of the index in indexes
+	lowerNameToIdx map[string]int
+}
+
+// NewIndexCollection creates a new collection from a list of indexes.  If any two indexes have the same
+// case-insensitive name, or an index has no columns, an error is returned.
+func NewIndexCollection(indexes ...Index) (*IndexCollection, error) {
+	lowerNameToIdx := make(map[string]int, len(indexes))
+	for i, idx := range indexes {
+		if len(idx.Tags) == 0 {
+			return nil, ErrIndexHasNoColumns
+		}
+
+		lwr := strings.ToLower(idx.Name)
+		if _, ok := lowerNameToIdx[lwr]; ok {
+			return nil, ErrIndexNameCollision
+		}
+
+		lowerNameToIdx[lwr] = i
+	}
+
+	return &IndexCollection{append([]Index{}, indexes...), lowerNameToIdx}, nil
+}
+
+// GetIndexes returns the indexes in the collection in their original order
+func (ic *IndexCollection) GetIndexes() []Index {
+	return append([]Index{}, ic.indexes...)
+}
+
+// GetByName does a case-insensitive lookup of an index by name
+func (ic *IndexCollection) GetByName(name string) (Index, bool) {
+	i, ok := ic.lowerNameToIdx[strings.ToLower(name)]
+
+	if !ok {
+		return Index{}, false
+	}
+
+	return ic.indexes[i], true
+}
+
+// Append returns a new collection containing the indexes of this collection followed by the given indexes.
+func (ic *IndexCollection) Append(indexes ...Index) (*IndexCollection, error) {
+	return NewIndexCollection(append(ic.GetIndexes(), indexes...)...)
+}
+
+// Remove returns a new collection without the index with the given case-insensitive name.  ErrIndexNotFound is
+// returned if there is no such index.
+func (ic *IndexCollection) Remove(name string) (*IndexCollection, error) {
+	i, ok := ic.lowerNameToIdx[strings.ToLower(name)]
+
+	if !ok {
+		return nil, ErrIndexNotFound
+	}
+
+	remaining := append(append([]Index{}, ic.indexes[:i]...), ic.indexes[i+1:]...)
+	return NewIndexCollection(remaining...)
+}
+
+// Filter returns a new collection containing only the indexes for which the callback returns true
+func (ic *IndexCollection) Filter(cb func(idx Index) bool) *IndexCollection {
+	var filtered []Index
+	for _, idx := range ic.indexes {
+		if cb(idx) {
+			filtered = append(filtered, idx)
+		}
+	}
+
+	// names were unique in the source collection so this cannot fail
+	coll, _ := NewIndexCollection(filtered...)
+	return coll
+}
+
+// Equals returns true if both collections contain equal indexes in the same order
+func (ic *IndexCollection) Equals(other *IndexCollection) bool {
+	if ic.Size() != other.Size() {
+		return false
+	}
+
+	for i := range ic.indexes {
+		if !ic.indexes[i].Equals(other.indexes[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Size returns the number of indexes in the collection.
+func (ic *IndexCollection) Size() int {
+	return len(ic.indexes)
+}
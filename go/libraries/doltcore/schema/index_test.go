@@ -0,0 +1,81 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexCollection(t *testing.T) {
+	idxFirst := Index{Name: "idx_first", Tags: []uint64{0}}
+	idxBoth := Index{Name: "idx_both", Tags: []uint64{0, 1}, Unique: true}
+
+	idxColl, err := NewIndexCollection(idxFirst, idxBoth)
+	require.NoError(t, err)
+	assert.Equal(t, 2, idxColl.Size())
+
+	idx, ok := idxColl.GetByName("IDX_BOTH")
+	assert.True(t, ok)
+	assert.True(t, idx.Equals(idxBoth))
+
+	_, ok = idxColl.GetByName("missing")
+	assert.False(t, ok)
+
+	_, err = idxColl.Append(Index{Name: "IDX_FIRST", Tags: []uint64{1}})
+	assert.Equal(t, ErrIndexNameCollision, err)
+
+	_, err = NewIndexCollection(Index{Name: "empty"})
+	assert.Equal(t, ErrIndexHasNoColumns, err)
+
+	removed, err := idxColl.Remove("idx_first")
+	require.NoError(t, err)
+	assert.Equal(t, []Index{idxBoth}, removed.GetIndexes())
+
+	_, err = removed.Remove("idx_first")
+	assert.Equal(t, ErrIndexNotFound, err)
+
+	filtered := idxColl.Filter(func(idx Index) bool { return !idx.HasTag(1) })
+	assert.Equal(t, []Index{idxFirst}, filtered.GetIndexes())
+	assert.False(t, filtered.Equals(idxColl))
+}
+
+func TestSchemaWithIndexes(t *testing.T) {
+	pkCol := Column{"id", 4, firstNameCol.Kind, true, nil, MergePolicy{}, "", false}
+	colColl, err := NewColCollection(pkCol, firstNameCol, lastNameCol)
+	require.NoError(t, err)
+	sch := SchemaFromCols(colColl)
+	assert.Equal(t, 0, sch.Indexes().Size())
+
+	idxColl, err := NewIndexCollection(Index{Name: "idx_first", Tags: []uint64{0}}, Index{Name: "idx_last", Tags: []uint64{1}})
+	require.NoError(t, err)
+
+	withIndexes, err := SchemaWithIndexes(sch, idxColl)
+	require.NoError(t, err)
+	assert.True(t, withIndexes.Indexes().Equals(idxColl))
+
+	badColl, err := NewIndexCollection(Index{Name: "idx_missing", Tags: []uint64{99}})
+	require.NoError(t, err)
+	_, err = SchemaWithIndexes(sch, badColl)
+	assert.Equal(t, ErrColNotFound, err)
+
+	// indexes over columns that no longer exist are dropped
+	withoutLast, err := NewColCollection(pkCol, firstNameCol)
+	require.NoError(t, err)
+	carried := SchemaWithIndexesAndForeignKeysOf(SchemaFromCols(withoutLast), withIndexes)
+	assert.Equal(t, []Index{{Name: "idx_first", Tags: []uint64{0}}}, carried.Indexes().GetIndexes())
+}
@@ -0,0 +1,230 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// MergePolicyType identifies the rule used to merge a cell which was changed differently on both sides of a merge.
+type MergePolicyType string
+
+const (
+	// NoMergePolicy results in a conflict whenever a cell is changed differently on both sides of a merge.
+	NoMergePolicy MergePolicyType = ""
+	// OursMergePolicy takes the value from our side of the merge.
+	OursMergePolicy MergePolicyType = "ours"
+	// TheirsMergePolicy takes the value from their side of the merge.
+	TheirsMergePolicy MergePolicyType = "theirs"
+	// MaxMergePolicy takes the larger of the two values.
+	MaxMergePolicy MergePolicyType = "max"
+	// MinMergePolicy takes the smaller of the two values.
+	MinMergePolicy MergePolicyType = "min"
+	// SumOfDeltasMergePolicy applies the changes made on both sides to the ancestor's value, as is needed for counters.
+	SumOfDeltasMergePolicy MergePolicyType = "sum-of-deltas"
+	// LatestByMergePolicy takes the value from the version of the row with the larger value in another column.
+	LatestByMergePolicy MergePolicyType = "latest-by"
+)
+
+// noMergePolicyStr is the string used to refer to NoMergePolicy on the command line.
+const noMergePolicyStr = "none"
+
+// MergePolicy is a rule stored with a column which is used to resolve a merge in which both sides changed the same cell
+// of the column to different values.
+type MergePolicy struct {
+	Type MergePolicyType
+
+	// ByTag is the tag of the column whose values are compared to find the latest version of a row when Type is
+	// LatestByMergePolicy.  Columns are referenced by tag so that renaming the column doesn't invalidate the policy.
+	ByTag uint64
+}
+
+// ParseMergePolicy parses a merge policy for the column col of the schema sch.  Valid policies are "none", "ours",
+// "theirs", "max", "min", "sum-of-deltas" and "latest-by:<column>".
+func ParseMergePolicy(str string, sch Schema, col Column) (MergePolicy, error) {
+	mp, err := parseMergePolicy(str, sch)
+
+	if err != nil {
+		return MergePolicy{}, err
+	}
+
+	if err := mp.Validate(sch, col); err != nil {
+		return MergePolicy{}, err
+	}
+
+	return mp, nil
+}
+
+// ParseDefaultMergePolicy parses a merge policy to be used as the default merge policy of the schema sch.  It accepts
+// the same policies as ParseMergePolicy.
+func ParseDefaultMergePolicy(str string, sch Schema) (MergePolicy, error) {
+	mp, err := parseMergePolicy(str, sch)
+
+	if err != nil {
+		return MergePolicy{}, err
+	}
+
+	if err := mp.ValidateDefault(sch); err != nil {
+		return MergePolicy{}, err
+	}
+
+	return mp, nil
+}
+
+func parseMergePolicy(str string, sch Schema) (MergePolicy, error) {
+	str = strings.TrimSpace(str)
+	policyStr, byColName := str, ""
+	if idx := strings.Index(str, ":"); idx != -1 {
+		policyStr, byColName = str[:idx], str[idx+1:]
+	}
+
+	var mp MergePolicy
+	switch MergePolicyType(strings.ToLower(policyStr)) {
+	case noMergePolicyStr:
+		mp.Type = NoMergePolicy
+	case OursMergePolicy:
+		mp.Type = OursMergePolicy
+	case TheirsMergePolicy:
+		mp.Type = TheirsMergePolicy
+	case MaxMergePolicy:
+		mp.Type = MaxMergePolicy
+	case MinMergePolicy:
+		mp.Type = MinMergePolicy
+	case SumOfDeltasMergePolicy:
+		mp.Type = SumOfDeltasMergePolicy
+	case LatestByMergePolicy:
+		byCol, ok := sch.GetAllCols().GetByName(byColName)
+
+		if !ok {
+			return MergePolicy{}, fmt.Errorf("invalid merge policy '%s': unknown column '%s'", str, byColName)
+		}
+
+		mp = MergePolicy{LatestByMergePolicy, byCol.Tag}
+	default:
+		return MergePolicy{}, fmt.Errorf("unknown merge policy '%s'", str)
+	}
+
+	if mp.Type != LatestByMergePolicy && byColName != "" {
+		return MergePolicy{}, fmt.Errorf("invalid merge policy '%s': only %s takes a column", str, LatestByMergePolicy)
+	}
+
+	return mp, nil
+}
+
+// Validate returns an error if the merge policy can't be used with the column col of the schema sch.
+func (mp MergePolicy) Validate(sch Schema, col Column) error {
+	if mp.Type == NoMergePolicy {
+		return nil
+	}
+
+	if col.IsPartOfPK {
+		return fmt.Errorf("merge policies can't be set on primary key column '%s'", col.Name)
+	}
+
+	switch mp.Type {
+	case MaxMergePolicy, MinMergePolicy:
+		switch col.Kind {
+		case types.IntKind, types.UintKind, types.FloatKind, types.StringKind, types.TimestampKind:
+		default:
+			return fmt.Errorf("merge policy '%s' can't be used with the %s column '%s'", mp.Type, col.KindString(), col.Name)
+		}
+	case SumOfDeltasMergePolicy:
+		switch col.Kind {
+		case types.IntKind, types.UintKind, types.FloatKind:
+		default:
+			return fmt.Errorf("merge policy '%s' can't be used with the %s column '%s'", mp.Type, col.KindString(), col.Name)
+		}
+	case LatestByMergePolicy:
+		if mp.ByTag == col.Tag {
+			return fmt.Errorf("merge policy '%s' of column '%s' can't refer to itself", mp.Type, col.Name)
+		}
+
+		return mp.validateByCol(sch)
+	}
+
+	return nil
+}
+
+// ValidateDefault returns an error if the merge policy can't be used as the default merge policy of the schema sch.
+// Unlike Validate it doesn't check the types of the columns, as the default policy is only applied to the columns it
+// can be used with.
+func (mp MergePolicy) ValidateDefault(sch Schema) error {
+	if mp.Type == LatestByMergePolicy {
+		return mp.validateByCol(sch)
+	}
+
+	return nil
+}
+
+// validateByCol returns an error if the column referenced by a LatestByMergePolicy can't be used to order the versions
+// of a row.
+func (mp MergePolicy) validateByCol(sch Schema) error {
+	byCol, ok := sch.GetAllCols().GetByTag(mp.ByTag)
+
+	if !ok {
+		return fmt.Errorf("merge policy '%s' refers to a column that doesn't exist", mp.Type)
+	} else if byCol.IsPartOfPK {
+		return fmt.Errorf("merge policy '%s' can't refer to the primary key column '%s'", mp.Type, byCol.Name)
+	}
+
+	switch byCol.Kind {
+	case types.IntKind, types.UintKind, types.TimestampKind:
+	default:
+		return fmt.Errorf("merge policy '%s' can't refer to the %s column '%s'", mp.Type, byCol.KindString(), byCol.Name)
+	}
+
+	return nil
+}
+
+// MergePolicyOf returns the merge policy used to merge the column col of the schema sch.  This is the column's own
+// policy if it has one, and otherwise the default merge policy of the schema if it can be used with the column.
+func MergePolicyOf(sch Schema, col Column) MergePolicy {
+	if col.MergePolicy.Type != NoMergePolicy || col.IsPartOfPK {
+		return col.MergePolicy
+	}
+
+	mp := sch.MergePolicy()
+
+	// the column the versions of a row are ordered by takes its value from the latest version, which is its larger value
+	if mp.Type == LatestByMergePolicy && mp.ByTag == col.Tag {
+		return mp
+	}
+
+	if mp.Validate(sch, col) != nil {
+		return MergePolicy{}
+	}
+
+	return mp
+}
+
+// Format returns the merge policy as it would be written on the command line, with any columns referenced by the policy
+// looked up in the schema sch.
+func (mp MergePolicy) Format(sch Schema) string {
+	switch mp.Type {
+	case NoMergePolicy:
+		return noMergePolicyStr
+	case LatestByMergePolicy:
+		if byCol, ok := sch.GetAllCols().GetByTag(mp.ByTag); ok {
+			return fmt.Sprintf("%s:%s", mp.Type, byCol.Name)
+		}
+
+		return fmt.Sprintf("%s:<tag %d>", mp.Type, mp.ByTag)
+	}
+
+	return string(mp.Type)
+}
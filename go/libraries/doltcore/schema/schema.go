@@ -29,6 +29,16 @@ type Schema interface {
 
 	// GetAllCols gets the collection of all columns (pk and non-pk)
 	GetAllCols() *ColCollection
+
+	// Indexes gets the collection of secondary indexes defined on the schema.
+	Indexes() *IndexCollection
+
+	// ForeignKeys gets the collection of foreign keys defined on the schema, for which this schema's table is the child.
+	ForeignKeys() *ForeignKeyCollection
+
+	// MergePolicy gets the default merge policy of the schema, which is used for the columns that don't have a merge
+	// policy of their own.
+	MergePolicy() MergePolicy
 }
 
 // ColFromTag returns a schema.Column from a schema and a tag
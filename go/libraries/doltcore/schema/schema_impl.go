@@ -24,10 +24,16 @@ var EmptySchema = &schemaImpl{
 	EmptyColColl,
 	EmptyColColl,
 	EmptyColColl,
+	EmptyIndexColl,
+	EmptyForeignKeyColl,
+	MergePolicy{},
 }
 
 type schemaImpl struct {
 	pkCols, nonPKCols, allCols *ColCollection
+	indexes                    *IndexCollection
+	foreignKeys                *ForeignKeyCollection
+	mergePolicy                MergePolicy
 }
 
 // SchemaFromCols creates a Schema from a collection of columns
@@ -51,7 +57,7 @@ func SchemaFromCols(allCols *ColCollection) Schema {
 	nonPKColColl, _ := NewColCollection(nonPKCols...)
 
 	return &schemaImpl{
-		pkColColl, nonPKColColl, allCols,
+		pkColColl, nonPKColColl, allCols, EmptyIndexColl, EmptyForeignKeyColl, MergePolicy{},
 	}
 }
 
@@ -86,7 +92,11 @@ func ValidateForInsert(allCols *ColCollection) error {
 		return false, nil
 	})
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return ValidateAutoIncrement(allCols)
 }
 
 // UnkeyedSchemaFromCols creates a schema without any primary keys to be used for displaying to users, tests, etc. Such
@@ -104,7 +114,7 @@ func UnkeyedSchemaFromCols(allCols *ColCollection) Schema {
 	nonPKColColl, _ := NewColCollection(nonPKCols...)
 
 	return &schemaImpl{
-		pkColColl, nonPKColColl, nonPKColColl,
+		pkColColl, nonPKColColl, nonPKColColl, EmptyIndexColl, EmptyForeignKeyColl, MergePolicy{},
 	}
 }
 
@@ -138,10 +148,80 @@ func SchemaFromPKAndNonPKCols(pkCols, nonPKCols *ColCollection) (Schema, error)
 	}
 
 	return &schemaImpl{
-		pkCols, nonPKCols, allColColl,
+		pkCols, nonPKCols, allColColl, EmptyIndexColl, EmptyForeignKeyColl, MergePolicy{},
+	}, nil
+}
+
+// SchemaWithIndexes returns a copy of the given schema with its indexes replaced by the given collection. Every
+// column referenced by an index must exist in the schema, otherwise ErrColNotFound is returned.
+func SchemaWithIndexes(sch Schema, indexes *IndexCollection) (Schema, error) {
+	allCols := sch.GetAllCols()
+	for _, idx := range indexes.indexes {
+		for _, tag := range idx.Tags {
+			if _, ok := allCols.GetByTag(tag); !ok {
+				return nil, ErrColNotFound
+			}
+		}
+	}
+
+	return &schemaImpl{
+		sch.GetPKCols(), sch.GetNonPKCols(), allCols, indexes, sch.ForeignKeys(), sch.MergePolicy(),
 	}, nil
 }
 
+// SchemaWithForeignKeys returns a copy of the given schema with its foreign keys replaced by the given collection.
+// Every child column of a foreign key must exist in the schema, otherwise ErrColNotFound is returned.  The referenced
+// columns belong to other tables and are not validated.
+func SchemaWithForeignKeys(sch Schema, foreignKeys *ForeignKeyCollection) (Schema, error) {
+	allCols := sch.GetAllCols()
+	for _, fk := range foreignKeys.foreignKeys {
+		for _, tag := range fk.Tags {
+			if _, ok := allCols.GetByTag(tag); !ok {
+				return nil, ErrColNotFound
+			}
+		}
+	}
+
+	return &schemaImpl{
+		sch.GetPKCols(), sch.GetNonPKCols(), allCols, sch.Indexes(), foreignKeys, sch.MergePolicy(),
+	}, nil
+}
+
+// SchemaWithMergePolicy returns a copy of the given schema with its default merge policy replaced by mp.  The policy is
+// used for columns which don't have a merge policy of their own, including columns added to the schema later.
+func SchemaWithMergePolicy(sch Schema, mp MergePolicy) Schema {
+	return &schemaImpl{
+		sch.GetPKCols(), sch.GetNonPKCols(), sch.GetAllCols(), sch.Indexes(), sch.ForeignKeys(), mp,
+	}
+}
+
+// SchemaWithIndexesAndForeignKeysOf returns a copy of sch with the indexes and foreign keys of from that only
+// reference columns that still exist in sch, and with the default merge policy of from. It is used to carry these
+// definitions through operations that rebuild a schema from its columns.
+func SchemaWithIndexesAndForeignKeysOf(sch, from Schema) Schema {
+	allCols := sch.GetAllCols()
+	hasAllTags := func(tags []uint64) bool {
+		for _, tag := range tags {
+			if _, ok := allCols.GetByTag(tag); !ok {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	indexes := from.Indexes().Filter(func(idx Index) bool {
+		return hasAllTags(idx.Tags)
+	})
+	foreignKeys := from.ForeignKeys().Filter(func(fk ForeignKey) bool {
+		return hasAllTags(fk.Tags)
+	})
+
+	newSch, _ := SchemaWithIndexes(sch, indexes)
+	newSch, _ = SchemaWithForeignKeys(newSch, foreignKeys)
+	return SchemaWithMergePolicy(newSch, from.MergePolicy())
+}
+
 // GetAllCols gets the collection of all columns (pk and non-pk)
 func (si *schemaImpl) GetAllCols() *ColCollection {
 	return si.allCols
@@ -157,6 +237,21 @@ func (si *schemaImpl) GetPKCols() *ColCollection {
 	return si.pkCols
 }
 
+// Indexes gets the collection of secondary indexes defined on the schema.
+func (si *schemaImpl) Indexes() *IndexCollection {
+	return si.indexes
+}
+
+// ForeignKeys gets the collection of foreign keys defined on the schema.
+func (si *schemaImpl) ForeignKeys() *ForeignKeyCollection {
+	return si.foreignKeys
+}
+
+// MergePolicy gets the default merge policy of the schema.
+func (si *schemaImpl) MergePolicy() MergePolicy {
+	return si.mergePolicy
+}
+
 func (si *schemaImpl) String() string {
 	var b strings.Builder
 	writeColFn := func(tag uint64, col Column) (stop bool, err error) {
@@ -48,6 +48,14 @@ func FmtColWithNameAndType(indent, nameWidth, typeWidth int, colName, typeStr st
 		}
 	}
 
+	if col.Default != "" {
+		colStr += " DEFAULT " + col.Default
+	}
+
+	if col.AutoIncrement {
+		colStr += " AUTO_INCREMENT"
+	}
+
 	return colStr + fmt.Sprintf(" COMMENT 'tag:%d'", col.Tag)
 }
 
@@ -59,6 +59,20 @@ func TestFmtCol(t *testing.T) {
 			15,
 			"   `aoeui` BIGINT UNSIGNED COMMENT 'tag:52'",
 		},
+		{
+			withDefault(schema.NewColumn("last", 123, types.StringKind, false, schema.NotNullConstraint{}), "'none'"),
+			2,
+			0,
+			0,
+			"  `last` LONGTEXT NOT NULL DEFAULT 'none' COMMENT 'tag:123'",
+		},
+		{
+			withAutoIncrement(schema.NewColumn("id", 1, types.UintKind, true, schema.NotNullConstraint{})),
+			0,
+			0,
+			0,
+			"`id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'tag:1'",
+		},
 	}
 
 	for _, test := range tests {
@@ -23,6 +23,7 @@ import (
 
 	"vitess.io/vitess/go/vt/sqlparser"
 
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema/alterschema"
@@ -80,6 +81,12 @@ func ExecuteDrop(ctx context.Context, db *doltdb.DoltDB, root *doltdb.RootValue,
 		}
 	}
 
+	for _, tableName := range filtered {
+		if err := root.CheckNotReferenced(ctx, tableName); err != nil {
+			return nil, err
+		}
+	}
+
 	var err error
 	if root, err = root.RemoveTables(ctx, filtered...); err != nil {
 		return nil, err
@@ -264,6 +271,18 @@ func dropColumn(ctx context.Context, db *doltdb.DoltDB, root *doltdb.RootValue,
 		return nil, err
 	}
 
+	sch, err := table.GetSchema(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if column, ok := sch.GetAllCols().GetByName(col.String()); ok {
+		if err := root.CheckNotReferenced(ctx, tableName, column.Tag); err != nil {
+			return nil, err
+		}
+	}
+
 	updatedTable, err := alterschema.DropColumn(ctx, db, table, col.String())
 	if err != nil {
 		if err == schema.ErrColNotFound {
@@ -299,6 +318,9 @@ func addColumn(ctx context.Context, db *doltdb.DoltDB, root *doltdb.RootValue, t
 	if col.IsPartOfPK {
 		return nil, errFmt("Adding primary keys is not supported")
 	}
+	if col.AutoIncrement {
+		return nil, schema.ErrInvalidAutoIncrementCol
+	}
 
 	nullable := alterschema.NotNull
 	if col.IsNullable() {
@@ -320,7 +342,6 @@ func getSchema(spec *sqlparser.TableSpec) (schema.Schema, error) {
 	var tag uint64
 	var seenPk bool
 	for i, colDef := range spec.Columns {
-		// TODO: support default value
 		col, _, err := getColumn(colDef, spec.Indexes, tag)
 		if err != nil {
 			return nil, err
@@ -340,6 +361,10 @@ func getSchema(spec *sqlparser.TableSpec) (schema.Schema, error) {
 		return nil, err
 	}
 
+	if err := schema.ValidateAutoIncrement(colColl); err != nil {
+		return nil, err
+	}
+
 	return schema.SchemaFromCols(colColl), nil
 }
 
@@ -438,11 +463,22 @@ func getColumn(colDef *sqlparser.ColumnDefinition, indexes []*sqlparser.IndexDef
 	}
 
 	column := schema.NewColumn(colDef.Name.String(), tag, colKind, isPkey, constraints...)
+	column.AutoIncrement = bool(colDef.Type.Autoincrement)
 
 	if colDef.Type.Default == nil {
 		return column, nil, nil
 	}
 
+	// A NULL default is stored, but there's nothing to give existing rows
+	if _, ok := colDef.Type.Default.(*sqlparser.NullVal); ok {
+		if !column.IsNullable() {
+			return errColumn("Invalid default value for column %v: '%v'", column.Name, nodeToString(colDef.Type.Default))
+		}
+
+		column.Default = "NULL"
+		return column, nil, nil
+	}
+
 	// Get the default value. This can be any expression (usually a literal value). We aren't using the simpler semantics
 	// of extractNomsValueFromSQLVal here, that doesn't cover the full range of expressions permitted by SQL (like -1.0,
 	// 2+2, CONCAT("a", "b")).
@@ -451,12 +487,6 @@ func getColumn(colDef *sqlparser.ColumnDefinition, indexes []*sqlparser.IndexDef
 		return schema.InvalidCol, nil, err
 	}
 
-	// TODO: type conversion. This doesn't work at all for uint columns (parser always thinks integer literals are int,
-	//  not uint)
-	if getter.NomsKind != colKind {
-		return errColumn("Type mismatch for default value of column %v: '%v'", column.Name, nodeToString(colDef.Type.Default))
-	}
-
 	if err = getter.Init(fakeResolver{}); err != nil {
 		return errColumn("Unsupported default expression for column %v: '%v'", column.Name, nodeToString(colDef.Type.Default))
 	}
@@ -479,6 +509,24 @@ func getColumn(colDef *sqlparser.ColumnDefinition, indexes []*sqlparser.IndexDef
 		return schema.InvalidCol, nil, err
 	}
 
+	// The parser gives literals the kind that fits them best, such as int for unsigned integers and string for quoted
+	// numbers, so the value is converted to the column's kind
+	if defaultVal.Kind() != colKind {
+		convFn, err := doltcore.GetConvFunc(defaultVal.Kind(), colKind)
+
+		if err == nil {
+			defaultVal, err = convFn(defaultVal)
+		}
+
+		if err != nil || types.IsNull(defaultVal) {
+			return errColumn("Type mismatch for default value of column %v: '%v'", column.Name, nodeToString(colDef.Type.Default))
+		}
+	}
+
+	if column.Default, err = schema.DefaultLiteral(defaultVal); err != nil {
+		return schema.InvalidCol, nil, err
+	}
+
 	return column, defaultVal, nil
 }
 
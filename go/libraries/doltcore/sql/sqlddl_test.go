@@ -192,18 +192,34 @@ func TestExecuteCreate(t *testing.T) {
 				schema.NewColumn("age", 1, types.IntKind, false)),
 		},
 		// Real world examples for regression testing
-		// TODO: need type conversion for defaults to work here (uint to int)
-		// 		{
-		// 			name:  "Test ip2nation",
-		// 			query: `CREATE TABLE ip2nation (
-		//   ip int(11) unsigned NOT NULL default 0,
-		//   country char(2) NOT NULL default '',
-		//   PRIMARY KEY (ip)
-		// );`,
-		// 			expectedSchema: dtestutils.CreateSchema(
-		// 				schema.NewColumn("ip", 0, types.UintKind, true, schema.NotNullConstraint{}),
-		// 				schema.NewColumn("country", 1, types.StringKind, false, schema.NotNullConstraint{})),
-		// 		},
+		{
+			name: "Test ip2nation",
+			query: `CREATE TABLE ip2nation (
+  ip int(11) unsigned NOT NULL default 0,
+  country char(2) NOT NULL default '',
+  PRIMARY KEY (ip)
+);`,
+			expectedSchema: dtestutils.CreateSchema(
+				withDefault(schema.NewColumn("ip", 0, types.UintKind, true, schema.NotNullConstraint{}), "0"),
+				withDefault(schema.NewColumn("country", 1, types.StringKind, false, schema.NotNullConstraint{}), "''")),
+		},
+		{
+			name:  "Test auto increment",
+			query: "create table testTable (id int unsigned auto_increment primary key, name varchar(80) default 'none')",
+			expectedSchema: dtestutils.CreateSchema(
+				withAutoIncrement(schema.NewColumn("id", 0, types.UintKind, true, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("name", 1, types.StringKind, false), "'none'")),
+		},
+		{
+			name:        "Test auto increment on non primary key",
+			query:       "create table testTable (id int primary key, num int auto_increment)",
+			expectedErr: "auto increment",
+		},
+		{
+			name:        "Test null default on not null column",
+			query:       "create table testTable (id int primary key, num int not null default null)",
+			expectedErr: "Invalid default value",
+		},
 		{
 			name: "Test ip2nationCountries",
 			query: `CREATE TABLE ip2nationCountries (
@@ -217,13 +233,13 @@ func TestExecuteCreate(t *testing.T) {
   PRIMARY KEY (code)
 );`,
 			expectedSchema: dtestutils.CreateSchema(
-				schema.NewColumn("code", 0, types.StringKind, true, schema.NotNullConstraint{}),
-				schema.NewColumn("iso_code_2", 1, types.StringKind, false, schema.NotNullConstraint{}),
-				schema.NewColumn("iso_code_3", 2, types.StringKind, false),
-				schema.NewColumn("iso_country", 3, types.StringKind, false, schema.NotNullConstraint{}),
-				schema.NewColumn("country", 4, types.StringKind, false, schema.NotNullConstraint{}),
-				schema.NewColumn("lat", 5, types.FloatKind, false, schema.NotNullConstraint{}),
-				schema.NewColumn("lon", 6, types.FloatKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("code", 0, types.StringKind, true, schema.NotNullConstraint{}), "''"),
+				withDefault(schema.NewColumn("iso_code_2", 1, types.StringKind, false, schema.NotNullConstraint{}), "''"),
+				withDefault(schema.NewColumn("iso_code_3", 2, types.StringKind, false), "''"),
+				withDefault(schema.NewColumn("iso_country", 3, types.StringKind, false, schema.NotNullConstraint{}), "''"),
+				withDefault(schema.NewColumn("country", 4, types.StringKind, false, schema.NotNullConstraint{}), "''"),
+				withDefault(schema.NewColumn("lat", 5, types.FloatKind, false, schema.NotNullConstraint{}), "0"),
+				withDefault(schema.NewColumn("lon", 6, types.FloatKind, false, schema.NotNullConstraint{}), "0")),
 		},
 	}
 
@@ -346,21 +362,21 @@ func TestAddColumn(t *testing.T) {
 			name:  "alter add column not null",
 			query: "alter table people add (newColumn varchar(80) not null default 'default' comment 'tag:100')",
 			expectedSchema: dtestutils.AddColumnToSchema(PeopleTestSchema,
-				schema.NewColumn("newColumn", 100, types.StringKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("newColumn", 100, types.StringKind, false, schema.NotNullConstraint{}), "'default'")),
 			expectedRows: dtestutils.AddColToRows(t, AllPeopleRows, 100, types.String("default")),
 		},
 		{
 			name:  "alter add column not null with expression default",
 			query: "alter table people add (newColumn int not null default 2+2/2 comment 'tag:100')",
 			expectedSchema: dtestutils.AddColumnToSchema(PeopleTestSchema,
-				schema.NewColumn("newColumn", 100, types.IntKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("newColumn", 100, types.IntKind, false, schema.NotNullConstraint{}), "3")),
 			expectedRows: dtestutils.AddColToRows(t, AllPeopleRows, 100, types.Int(3)),
 		},
 		{
 			name:  "alter add column not null with negative expression",
 			query: "alter table people add (newColumn float not null default -1.1 comment 'tag:100')",
 			expectedSchema: dtestutils.AddColumnToSchema(PeopleTestSchema,
-				schema.NewColumn("newColumn", 100, types.FloatKind, false, schema.NotNullConstraint{})),
+				withDefault(schema.NewColumn("newColumn", 100, types.FloatKind, false, schema.NotNullConstraint{}), "-1.1")),
 			expectedRows: dtestutils.AddColToRows(t, AllPeopleRows, 100, types.Float(-1.1)),
 		},
 		{
@@ -814,3 +830,13 @@ func TestRenameTable(t *testing.T) {
 		})
 	}
 }
+
+func withDefault(col schema.Column, defaultLiteral string) schema.Column {
+	col.Default = defaultLiteral
+	return col
+}
+
+func withAutoIncrement(col schema.Column) schema.Column {
+	col.AutoIncrement = true
+	return col
+}
@@ -117,7 +117,11 @@ func BuildShowPipeline(ctx context.Context, root *doltdb.RootValue, show *sqlpar
 			return nil, nil, err
 		}
 
-		schemaStr := SchemaAsCreateStmt(tableName, sch)
+		schemaStr, err := SchemaAsCreateStmtWithForeignKeys(ctx, root, tableName, sch)
+
+		if err != nil {
+			return nil, nil, err
+		}
 
 		resultSch := showCreateTableSchema()
 		rows, err := toRows(root.VRW().Format(), ([][]string{{tableName, schemaStr}}), resultSch)
@@ -15,10 +15,12 @@
 package sql
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
 	"github.com/liquidata-inc/dolt/go/store/types"
 
@@ -33,6 +35,10 @@ func QuoteIdentifier(s string) string {
 // SchemaAsCreateStmt takes a Schema and returns a string representing a SQL create table command that could be used to
 // create this table
 func SchemaAsCreateStmt(tableName string, sch schema.Schema) string {
+	return schemaAsCreateStmt(tableName, sch, nil)
+}
+
+func schemaAsCreateStmt(tableName string, sch schema.Schema, fkStrs []string) string {
 	sb := &strings.Builder{}
 	fmt.Fprintf(sb, "CREATE TABLE %s (\n", QuoteIdentifier(tableName))
 
@@ -67,10 +73,94 @@ func SchemaAsCreateStmt(tableName string, sch schema.Schema) string {
 		panic(err)
 	}
 
-	sb.WriteString(")\n);")
+	sb.WriteString(")")
+
+	for _, idx := range sch.Indexes().GetIndexes() {
+		if idx.Unique {
+			sb.WriteString(",\n  UNIQUE KEY ")
+		} else {
+			sb.WriteString(",\n  KEY ")
+		}
+
+		sb.WriteString(QuoteIdentifier(idx.Name))
+		sb.WriteString(" (")
+		for i, tag := range idx.Tags {
+			if i > 0 {
+				sb.WriteRune(',')
+			}
+
+			col, _ := sch.GetAllCols().GetByTag(tag)
+			sb.WriteString(QuoteIdentifier(col.Name))
+		}
+		sb.WriteRune(')')
+	}
+
+	for _, fkStr := range fkStrs {
+		sb.WriteString(",\n  ")
+		sb.WriteString(fkStr)
+	}
+
+	sb.WriteString("\n);")
 	return sb.String()
 }
 
+// SchemaAsCreateStmtWithForeignKeys returns the same statement as SchemaAsCreateStmt, along with a CONSTRAINT clause
+// for each of the foreign keys of the schema.  The referenced columns of the foreign keys are looked up in the given
+// root.
+func SchemaAsCreateStmtWithForeignKeys(ctx context.Context, root *doltdb.RootValue, tableName string, sch schema.Schema) (string, error) {
+	var fkStrs []string
+	for _, fk := range sch.ForeignKeys().GetForeignKeys() {
+		refSch := sch
+		if fk.ReferencedTable != tableName {
+			refTbl, ok, err := root.GetTable(ctx, fk.ReferencedTable)
+
+			if err != nil {
+				return "", err
+			} else if !ok {
+				return "", fmt.Errorf("table '%s' referenced by foreign key '%s' not found", fk.ReferencedTable, fk.Name)
+			}
+
+			refSch, err = refTbl.GetSchema(ctx)
+
+			if err != nil {
+				return "", err
+			}
+		}
+
+		cols, err := tagsToQuotedNames(sch, fk.Tags)
+
+		if err != nil {
+			return "", err
+		}
+
+		refCols, err := tagsToQuotedNames(refSch, fk.ReferencedTags)
+
+		if err != nil {
+			return "", err
+		}
+
+		fkStr := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)", QuoteIdentifier(fk.Name), cols, QuoteIdentifier(fk.ReferencedTable), refCols)
+		fkStrs = append(fkStrs, fkStr)
+	}
+
+	return schemaAsCreateStmt(tableName, sch, fkStrs), nil
+}
+
+func tagsToQuotedNames(sch schema.Schema, tags []uint64) (string, error) {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		col, ok := sch.GetAllCols().GetByTag(tag)
+
+		if !ok {
+			return "", schema.ErrColNotFound
+		}
+
+		names[i] = QuoteIdentifier(col.Name)
+	}
+
+	return strings.Join(names, ","), nil
+}
+
 func DropTableStmt(tableName string) string {
 	var b strings.Builder
 	b.WriteString("DROP TABLE ")
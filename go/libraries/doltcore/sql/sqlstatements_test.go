@@ -15,6 +15,7 @@
 package sql
 
 import (
+	"context"
 	"testing"
 
 	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const expectedCreateSQL = "CREATE TABLE `table_name` (\n" +
@@ -59,6 +61,59 @@ func TestSchemaAsCreateStmt(t *testing.T) {
 	assert.Equal(t, expectedCreateSQL, stmt)
 }
 
+func TestSchemaAsCreateStmtWithIndexes(t *testing.T) {
+	indexes, err := schema.NewIndexCollection(
+		schema.Index{Name: "idx_age", Tags: []uint64{4}},
+		schema.Index{Name: "uniq_name", Tags: []uint64{1, 2}, Unique: true},
+	)
+	assert.NoError(t, err)
+	tSchema, err := schema.SchemaWithIndexes(sqltestutil.PeopleTestSchema, indexes)
+	assert.NoError(t, err)
+
+	stmt := SchemaAsCreateStmt("table_name", tSchema)
+
+	expected := expectedCreateSQL[:len(expectedCreateSQL)-len("\n);")] + ",\n" +
+		"  KEY `idx_age` (`age`),\n" +
+		"  UNIQUE KEY `uniq_name` (`first`,`last`)\n" +
+		");"
+	assert.Equal(t, expected, stmt)
+}
+
+func TestSchemaAsCreateStmtWithForeignKeys(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	sqltestutil.CreateTestDatabase(dEnv, t)
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	fks, err := schema.NewForeignKeyCollection(
+		schema.ForeignKey{Name: "fk_people", Tags: []uint64{4}, ReferencedTable: sqltestutil.PeopleTableName, ReferencedTags: []uint64{0}},
+		schema.ForeignKey{Name: "fk_self", Tags: []uint64{8}, ReferencedTable: "table_name", ReferencedTags: []uint64{0}},
+	)
+	require.NoError(t, err)
+	tSchema, err := schema.SchemaWithForeignKeys(sqltestutil.PeopleTestSchema, fks)
+	require.NoError(t, err)
+
+	stmt, err := SchemaAsCreateStmtWithForeignKeys(ctx, root, "table_name", tSchema)
+	require.NoError(t, err)
+
+	expected := expectedCreateSQL[:len(expectedCreateSQL)-len("\n);")] + ",\n" +
+		"  CONSTRAINT `fk_people` FOREIGN KEY (`age`) REFERENCES `people` (`id`),\n" +
+		"  CONSTRAINT `fk_self` FOREIGN KEY (`num_episodes`) REFERENCES `table_name` (`id`)\n" +
+		");"
+	assert.Equal(t, expected, stmt)
+
+	fks, err = schema.NewForeignKeyCollection(
+		schema.ForeignKey{Name: "fk_missing", Tags: []uint64{4}, ReferencedTable: "missing", ReferencedTags: []uint64{0}},
+	)
+	require.NoError(t, err)
+	tSchema, err = schema.SchemaWithForeignKeys(sqltestutil.PeopleTestSchema, fks)
+	require.NoError(t, err)
+
+	_, err = SchemaAsCreateStmtWithForeignKeys(ctx, root, "table_name", tSchema)
+	assert.Error(t, err)
+}
+
 func TestTableDropStmt(t *testing.T) {
 	stmt := DropTableStmt("table_name")
 
@@ -0,0 +1,276 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions/commitwalk"
+)
+
+const (
+	// asOfTableSep separates the name of a table from the revision it's read at in the names produced by RewriteAsOf.
+	// It can't appear in a valid table name.
+	asOfTableSep = " as of "
+
+	// asOfUpperEscape precedes the lower case form of each upper case character of the revision in the names produced
+	// by RewriteAsOf, since the engine lower cases table names before looking them up.
+	asOfUpperEscape = '\\'
+)
+
+// asOfTimeFormats are the formats of the timestamps accepted by AS OF clauses.  Timestamps without a time zone are in
+// the local time zone.
+var asOfTimeFormats = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+}
+
+// RewriteAsOf rewrites every `table AS OF 'revision'` clause in the query into a reference to a table whose name
+// encodes both the table and the revision, which Database resolves by reading the table from that revision.  The
+// revision may be a commit spec, such as a branch name, a commit hash or `HEAD~2`, or a timestamp, in which case the
+// latest commit on the current branch made at or before that time is used.  If no alias is given for the table, it's
+// aliased with its own name so that the rest of the query can refer to it as usual.
+//
+// The SQL parser doesn't support AS OF, so this must be done before the query is parsed.  The query is split up by the
+// parser's tokenizer, so AS OF in string literals, comments and quoted identifiers is left alone.  Queries without AS
+// OF clauses are returned unchanged.
+func RewriteAsOf(query string) string {
+	type token struct {
+		typ int
+		val string
+		end int
+	}
+
+	var tokens []token
+	tkn := sqlparser.NewStringTokenizer(query)
+	for {
+		typ, val := tkn.Scan()
+
+		if typ == 0 || typ == sqlparser.LEX_ERROR {
+			break
+		}
+
+		tokens = append(tokens, token{typ, string(val), tkn.Position - 1})
+	}
+
+	var sb strings.Builder
+	pos := 0
+	for i := 0; i+3 < len(tokens); i++ {
+		tblTok, asTok, ofTok, revTok := tokens[i], tokens[i+1], tokens[i+2], tokens[i+3]
+
+		if tblTok.typ != sqlparser.ID || asTok.typ != sqlparser.AS || ofTok.typ != sqlparser.ID ||
+			!strings.EqualFold(ofTok.val, "of") || revTok.typ != sqlparser.STRING {
+			continue
+		}
+
+		tblStart, ok := identifierStart(query, tblTok.val, tblTok.end)
+
+		if !ok || tblStart < pos {
+			continue
+		}
+
+		sb.WriteString(query[pos:tblStart])
+		sb.WriteString(quoteIdentifier(tblTok.val + asOfTableSep + encodeAsOfRevision(revTok.val)))
+
+		hasAlias := i+4 < len(tokens) && (tokens[i+4].typ == sqlparser.AS || tokens[i+4].typ == sqlparser.ID)
+		if !hasAlias {
+			sb.WriteString(" AS ")
+			sb.WriteString(quoteIdentifier(tblTok.val))
+		}
+
+		pos = revTok.end
+		i += 3
+	}
+
+	if pos == 0 {
+		return query
+	}
+
+	sb.WriteString(query[pos:])
+	return sb.String()
+}
+
+// identifierStart returns the offset in the query of the identifier with the name given that ends at the offset end,
+// whether or not it's quoted.  Returns false if the identifier isn't found there.
+func identifierStart(query, name string, end int) (int, bool) {
+	text := name
+	if end > 0 && query[end-1] == '`' {
+		text = quoteIdentifier(name)
+	}
+
+	start := end - len(text)
+	if start < 0 || query[start:end] != text {
+		return 0, false
+	}
+
+	return start, true
+}
+
+func quoteIdentifier(name string) string {
+	return "`" + strings.Replace(name, "`", "``", -1) + "`"
+}
+
+func encodeAsOfRevision(rev string) string {
+	var sb strings.Builder
+	for _, r := range rev {
+		if unicode.IsUpper(r) || r == asOfUpperEscape {
+			sb.WriteRune(asOfUpperEscape)
+		}
+
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+func decodeAsOfRevision(encoded string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range encoded {
+		if escaped {
+			sb.WriteRune(unicode.ToUpper(r))
+			escaped = false
+		} else if r == asOfUpperEscape {
+			escaped = true
+		} else {
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+// splitAsOfTableName splits a table name produced by RewriteAsOf into the name of the table and the revision it should
+// be read at.  Returns false if the name doesn't have a revision.
+func splitAsOfTableName(name string) (string, string, bool) {
+	idx := strings.Index(strings.ToLower(name), asOfTableSep)
+
+	if idx == -1 {
+		return "", "", false
+	}
+
+	return name[:idx], decodeAsOfRevision(name[idx+len(asOfTableSep):]), true
+}
+
+// getTableAsOf returns the table named by a table name produced by RewriteAsOf, read from the revision given in the
+// name.  Tables read from a revision can't be modified.
+func (db *Database) getTableAsOf(ctx context.Context, asOfName, tblName, asOf string) (sql.Table, bool, error) {
+	root, err := db.rootAsOf(ctx, asOf)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	tbl, ok, err := NewReadOnlyDatabase(db.name, root, db.dEnv).GetTableInsensitive(ctx, tblName)
+
+	if err != nil {
+		return nil, false, err
+	} else if !ok {
+		// the engine would report the encoded name, so the error is returned here instead
+		return nil, false, sql.ErrTableNotFound.New(fmt.Sprintf("%s AS OF '%s'", tblName, asOf))
+	}
+
+	// the table keeps the name it was requested with, so that it's distinct from the same table at other revisions
+	if dt, ok := tbl.(*DoltTable); ok {
+		dt.name = asOfName
+	}
+
+	return tbl, true, nil
+}
+
+// rootAsOf returns the root value of the commit referenced by an AS OF clause, which is either a commit spec or a
+// timestamp.
+func (db *Database) rootAsOf(ctx context.Context, asOf string) (*doltdb.RootValue, error) {
+	if db.dEnv == nil {
+		return nil, fmt.Errorf("AS OF is not supported by database '%s'", db.name)
+	}
+
+	ddb := db.dEnv.DoltDB
+	cs, err := doltdb.NewCommitSpec(asOf, db.dEnv.RepoState.Head.Ref.String())
+
+	var cm *doltdb.Commit
+	if err == nil {
+		cm, err = ddb.Resolve(ctx, cs)
+	}
+
+	if err != nil {
+		t, ok := parseAsOfTime(asOf)
+
+		if !ok {
+			return nil, fmt.Errorf("invalid AS OF revision '%s': %v", asOf, err)
+		}
+
+		cm, err = db.commitAsOfTime(ctx, t)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cm.GetRootValue()
+}
+
+// commitAsOfTime returns the latest commit on the current branch made at or before the given time, walking back from
+// the head of the branch and stopping at the first such commit.
+func (db *Database) commitAsOfTime(ctx context.Context, t time.Time) (*doltdb.Commit, error) {
+	ddb := db.dEnv.DoltDB
+	cs, err := doltdb.NewCommitSpec("HEAD", db.dEnv.RepoState.Head.Ref.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := ddb.Resolve(ctx, cs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := head.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	cm, err := commitwalk.GetFirstCommitAtOrBefore(ctx, ddb, h, t)
+
+	if err != nil {
+		return nil, err
+	} else if cm == nil {
+		return nil, fmt.Errorf("no commits were made at or before %s", t.Format(time.RFC3339))
+	}
+
+	return cm, nil
+}
+
+func parseAsOfTime(s string) (time.Time, bool) {
+	for _, format := range asOfTimeFormats {
+		if t, err := time.ParseInLocation(format, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,245 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	sqle "github.com/src-d/go-mysql-server"
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	. "github.com/liquidata-inc/dolt/go/libraries/doltcore/sql/sqltestutil"
+)
+
+func TestRewriteAsOf(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			"select * from people",
+			"select * from people",
+		},
+		{
+			"select * from people as of 'HEAD~1'",
+			"select * from `people as of \\H\\E\\A\\D~1` AS `people`",
+		},
+		{
+			"select * from people AS OF 'master' where id = 0",
+			"select * from `people as of master` AS `people` where id = 0",
+		},
+		{
+			"select p.age, old.age from people p join people as of '2019-12-01' as old on p.id = old.id",
+			"select p.age, old.age from people p join `people as of 2019-12-01` as old on p.id = old.id",
+		},
+		{
+			"select * from db.`people` as of 'b' old",
+			"select * from db.`people as of b` old",
+		},
+		{
+			"select 'people as of' from people",
+			"select 'people as of' from people",
+		},
+		{
+			"select 'people as of ''master''', \"people as of 'master'\" from people",
+			"select 'people as of ''master''', \"people as of 'master'\" from people",
+		},
+		{
+			"select * from `people as of 'master'`",
+			"select * from `people as of 'master'`",
+		},
+		{
+			"select * from `peo``ple` as of 'master'",
+			"select * from `peo``ple as of master` AS `peo``ple`",
+		},
+		{
+			"select * from people -- people as of 'master'",
+			"select * from people -- people as of 'master'",
+		},
+		{
+			"select * from people # people as of 'master'\nwhere id = 0",
+			"select * from people # people as of 'master'\nwhere id = 0",
+		},
+		{
+			"select * from /* people as of 'master' */ people as of 'HEAD'",
+			"select * from /* people as of 'master' */ `people as of \\H\\E\\A\\D` AS `people`",
+		},
+		{
+			"select * from/*c*/people as of 'master'",
+			"select * from/*c*/`people as of master` AS `people`",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			assert.Equal(t, test.expected, RewriteAsOf(test.query))
+		})
+	}
+}
+
+func TestAsOfQueries(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	commitAll(t, dEnv, "add tables")
+
+	_, err := executeQuery(ctx, dEnv, "update people set age = 41 where id = 0")
+	require.NoError(t, err)
+	commitAll(t, dEnv, "update homer")
+
+	_, err = executeQuery(ctx, dEnv, "update people set age = 42 where id = 0")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedRows []sql.Row
+		expectedErr  string
+	}{
+		{
+			name:         "working set",
+			query:        "select age from people where id = 0",
+			expectedRows: []sql.Row{{int64(42)}},
+		},
+		{
+			name:         "head",
+			query:        "select age from people as of 'HEAD' where id = 0",
+			expectedRows: []sql.Row{{int64(41)}},
+		},
+		{
+			name:         "ancestor of head",
+			query:        "select age from people as of 'HEAD~1' where people.id = 0",
+			expectedRows: []sql.Row{{int64(40)}},
+		},
+		{
+			name:         "branch",
+			query:        "select age from people as of 'master' where id = 0",
+			expectedRows: []sql.Row{{int64(41)}},
+		},
+		{
+			name:         "join against a revision",
+			query:        "select p.age, old.age from people p join people as of 'HEAD~1' old on p.id = old.id where p.id = 0",
+			expectedRows: []sql.Row{{int64(42), int64(40)}},
+		},
+		{
+			name:         "timestamp after the last commit",
+			query:        "select age from people as of '2200-01-01' where id = 0",
+			expectedRows: []sql.Row{{int64(41)}},
+		},
+		{
+			name:        "timestamp before the first commit",
+			query:       "select age from people as of '2000-01-01T00:00:00Z' where id = 0",
+			expectedErr: "no commits were made at or before",
+		},
+		{
+			name:        "table missing at revision",
+			query:       "select * from people as of 'HEAD~2'",
+			expectedErr: "table not found: people AS OF 'HEAD~2'",
+		},
+		{
+			name:        "invalid revision",
+			query:       "select * from people as of 'nope'",
+			expectedErr: "invalid AS OF revision 'nope'",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := executeQuery(ctx, dEnv, test.query)
+
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedRows, rows)
+			}
+		})
+	}
+}
+
+func commitAll(t *testing.T, dEnv *env.DoltEnv, msg string) {
+	err := actions.StageAllTables(context.Background(), dEnv, false)
+	require.NoError(t, err)
+	err = actions.CommitStaged(context.Background(), dEnv, msg, false)
+	require.NoError(t, err)
+}
+
+// executeQuery runs a query against the working root of the environment, and writes the root back if it changed.
+func executeQuery(ctx context.Context, dEnv *env.DoltEnv, query string) ([]sql.Row, error) {
+	root, err := dEnv.WorkingRoot(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db := NewDatabase("dolt", root, dEnv)
+	engine := sqle.NewDefault()
+	engine.AddDatabase(db)
+	engine.Catalog.MustRegister(VersionControlFunctions(engine.Catalog)...)
+
+	var rows []sql.Row
+	if indexDDL, ok, err := ParseIndexDDL(query); ok {
+		if err != nil {
+			return nil, err
+		}
+
+		if err = ExecuteIndexDDL(ctx, db, indexDDL); err != nil {
+			return nil, err
+		}
+	} else if fkDDL, ok, err := ParseForeignKeyDDL(query); ok {
+		if err != nil {
+			return nil, err
+		}
+
+		if err = ExecuteForeignKeyDDL(ctx, db, fkDDL); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := CheckDropTables(ctx, db, query); err != nil {
+			return nil, err
+		}
+
+		_, iter, err := engine.Query(sql.NewContext(ctx, sql.WithQuery(query)), RewriteAsOf(query))
+
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err = sql.RowIterToRows(iter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fkDDL := range ParseCreateTableForeignKeys(query) {
+			if err = ExecuteForeignKeyDDL(ctx, db, fkDDL); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if db.Root() != root {
+		err = dEnv.UpdateWorkingRoot(ctx, db.Root())
+	}
+
+	return rows, err
+}
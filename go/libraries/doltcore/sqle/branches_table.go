@@ -0,0 +1,111 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"github.com/src-d/go-mysql-server/sql"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+)
+
+const (
+	// BranchesTableName is the system table name
+	BranchesTableName = "dolt_branches"
+)
+
+var _ sql.Table = (*BranchesTable)(nil)
+
+// BranchesTable is a sql.Table implementation that implements a system table which shows the branches of the
+// repository and the latest commit on each
+type BranchesTable struct {
+	dEnv *env.DoltEnv
+}
+
+// NewBranchesTable creates a BranchesTable
+func NewBranchesTable(dEnv *env.DoltEnv) *BranchesTable {
+	return &BranchesTable{dEnv: dEnv}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// BranchesTableName
+func (bt *BranchesTable) Name() string {
+	return BranchesTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// BranchesTableName
+func (bt *BranchesTable) String() string {
+	return BranchesTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the branches system table.
+func (bt *BranchesTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "name", Type: sql.Text, Source: BranchesTableName, PrimaryKey: true},
+		{Name: "hash", Type: sql.Text, Source: BranchesTableName, PrimaryKey: false},
+		{Name: "latest_committer", Type: sql.Text, Source: BranchesTableName, PrimaryKey: false},
+		{Name: "latest_committer_email", Type: sql.Text, Source: BranchesTableName, PrimaryKey: false},
+		{Name: "latest_commit_date", Type: sql.Timestamp, Source: BranchesTableName, PrimaryKey: false},
+		{Name: "latest_commit_message", Type: sql.Text, Source: BranchesTableName, PrimaryKey: false},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (bt *BranchesTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &doltTablePartitionIter{}, nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (bt *BranchesTable) PartitionRows(sqlCtx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	ddb := bt.dEnv.DoltDB
+	branches, err := ddb.GetRefsOfType(sqlCtx, map[ref.RefType]struct{}{ref.BranchRefType: {}})
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]sql.Row, 0, len(branches))
+	for _, branch := range branches {
+		cs, err := doltdb.NewCommitSpec("HEAD", branch.String())
+
+		if err != nil {
+			return nil, err
+		}
+
+		cm, err := ddb.Resolve(sqlCtx, cs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		h, err := cm.HashOf()
+
+		if err != nil {
+			return nil, err
+		}
+
+		meta, err := cm.GetCommitMeta()
+
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, sql.NewRow(branch.GetPath(), h.String(), meta.Name, meta.Email, meta.Time(), meta.Description))
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// applyCreateTableColumnOptions sets the default values and auto increment flags of the columns of a schema from the
+// CREATE TABLE statement it was created by.  The engine drops both from column definitions before the table is created,
+// so they are read from the statement itself.  The schema is returned unchanged for other statements.
+func applyCreateTableColumnOptions(sch schema.Schema, query string) (schema.Schema, error) {
+	stmt, err := sqlparser.Parse(query)
+
+	if err != nil {
+		return sch, nil
+	}
+
+	ddl, ok := stmt.(*sqlparser.DDL)
+
+	if !ok || ddl.Action != sqlparser.CreateStr || ddl.TableSpec == nil {
+		return sch, nil
+	}
+
+	colDefs := make(map[string]*sqlparser.ColumnDefinition)
+	for _, colDef := range ddl.TableSpec.Columns {
+		colDefs[colDef.Name.Lowered()] = colDef
+	}
+
+	var cols []schema.Column
+	err = sch.GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		if colDef, ok := colDefs[strings.ToLower(col.Name)]; ok {
+			col.AutoIncrement = bool(colDef.Type.Autoincrement)
+
+			if colDef.Type.Default != nil {
+				col.Default, err = defaultLiteralForCol(col, colDef.Type.Default)
+
+				if err != nil {
+					return true, err
+				}
+			}
+		}
+
+		cols = append(cols, col)
+		return false, nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	colColl, err := schema.NewColCollection(cols...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = schema.ValidateForInsert(colColl); err != nil {
+		return nil, err
+	}
+
+	return schema.SchemaFromCols(colColl), nil
+}
+
+// defaultLiteralForCol returns the default value literal stored in a column for the DEFAULT expression given.  Only
+// literals are supported, and they are converted to the kind of the column.
+func defaultLiteralForCol(col schema.Column, expr sqlparser.Expr) (string, error) {
+	var val types.Value
+	var err error
+	switch expr := expr.(type) {
+	case *sqlparser.NullVal:
+		if !col.IsNullable() {
+			return "", fmt.Errorf("invalid default value for column '%s': '%s'", col.Name, sqlparser.String(expr))
+		}
+
+		return "NULL", nil
+	case sqlparser.BoolVal:
+		val = types.Bool(expr)
+	case *sqlparser.SQLVal:
+		val, err = sqlValToNomsVal(expr, false)
+	case *sqlparser.UnaryExpr:
+		if sqlVal, ok := expr.Expr.(*sqlparser.SQLVal); ok && expr.Operator == sqlparser.UMinusStr {
+			val, err = sqlValToNomsVal(sqlVal, true)
+		} else {
+			err = fmt.Errorf("unsupported default value for column '%s': '%s'", col.Name, sqlparser.String(expr))
+		}
+	default:
+		err = fmt.Errorf("unsupported default value for column '%s': '%s'", col.Name, sqlparser.String(expr))
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	if val.Kind() != col.Kind {
+		convFn, err := doltcore.GetConvFunc(val.Kind(), col.Kind)
+
+		if err == nil {
+			val, err = convFn(val)
+		}
+
+		if err != nil || types.IsNull(val) {
+			return "", fmt.Errorf("invalid default value for column '%s': '%s'", col.Name, sqlparser.String(expr))
+		}
+	}
+
+	return schema.DefaultLiteral(val)
+}
+
+func sqlValToNomsVal(sqlVal *sqlparser.SQLVal, negate bool) (types.Value, error) {
+	str := string(sqlVal.Val)
+	if negate {
+		str = "-" + str
+	}
+
+	switch sqlVal.Type {
+	case sqlparser.StrVal:
+		if !negate {
+			return types.String(str), nil
+		}
+	case sqlparser.IntVal:
+		if n, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return types.Int(n), nil
+		} else if n, err := strconv.ParseUint(str, 10, 64); err == nil {
+			return types.Uint(n), nil
+		}
+	case sqlparser.FloatVal:
+		if f, err := strconv.ParseFloat(str, 64); err == nil {
+			return types.Float(f), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported default value '%s'", sqlparser.String(sqlVal))
+}
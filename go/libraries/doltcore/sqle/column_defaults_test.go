@@ -0,0 +1,92 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+)
+
+func TestCreateTableColumnOptions(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	_, err := executeQuery(ctx, dEnv, "create table items (id int unsigned auto_increment primary key, name varchar(80) not null default 'none', qty int default -1, price float default '1.5', note varchar(80) default null)")
+	require.NoError(t, err)
+
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+	tbl, _, err := root.GetTable(ctx, "items")
+	require.NoError(t, err)
+	sch, err := tbl.GetSchema(ctx)
+	require.NoError(t, err)
+
+	col, ok := schema.AutoIncrementCol(sch)
+	require.True(t, ok)
+	assert.Equal(t, "id", col.Name)
+
+	expectedDefaults := map[string]string{"id": "", "name": "'none'", "qty": "-1", "price": "1.5", "note": "NULL"}
+	for name, expected := range expectedDefaults {
+		col, ok := sch.GetAllCols().GetByName(name)
+		require.True(t, ok)
+		assert.Equal(t, expected, col.Default, name)
+	}
+
+	for _, query := range []string{
+		"create table bad1 (id int primary key, num int auto_increment)",
+		"create table bad2 (id varchar(20) auto_increment primary key)",
+		"create table bad3 (id int primary key, num int not null default null)",
+		"create table bad4 (id int primary key, num float default 'abc')",
+		"create table bad5 (id int primary key, num int default 1+1)",
+	} {
+		_, err = executeQuery(ctx, dEnv, query)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestInsertAutoIncrementAndDefaults(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+
+	for _, query := range []string{
+		"create table items (id bigint auto_increment primary key, name varchar(80) not null default 'none', qty int default 7)",
+		"insert into items (name) values ('a'), ('b')",
+		"insert into items (id, name) values (10, 'c')",
+		"insert into items (id) values (null)",
+		"delete from items where id = 11",
+		"insert into items (name, qty) values ('d', 1)",
+	} {
+		_, err := executeQuery(ctx, dEnv, query)
+		require.NoError(t, err, query)
+	}
+
+	rows, err := executeQuery(ctx, dEnv, "select id, name, qty from items")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{
+		{int64(1), "a", int64(7)},
+		{int64(2), "b", int64(7)},
+		{int64(10), "c", int64(7)},
+		{int64(12), "d", int64(1)},
+	}, rows)
+
+	_, err = executeQuery(ctx, dEnv, "update items set id = null where id = 1")
+	assert.Error(t, err)
+}
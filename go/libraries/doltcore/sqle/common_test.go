@@ -73,7 +73,7 @@ func executeModify(ctx context.Context, root *doltdb.RootValue, query string) (*
 	engine := sqle.NewDefault()
 	engine.AddDatabase(db)
 	engine.Init()
-	sqlCtx := sql.NewContext(ctx)
+	sqlCtx := sql.NewContext(ctx, sql.WithQuery(query))
 	_, _, err := engine.Query(sqlCtx, query)
 	return db.Root(), err
 }
@@ -0,0 +1,374 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"io"
+
+	"github.com/src-d/go-mysql-server/sql"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	sqlTypes "github.com/liquidata-inc/dolt/go/libraries/doltcore/sqle/types"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+const (
+	// ConflictsTableName is the name of the system table which summarizes the conflicts in each table
+	ConflictsTableName = "dolt_conflicts"
+
+	// DoltConflictsTablePrefix is the prefix of the system tables which contain the conflicts of a single table
+	DoltConflictsTablePrefix = "dolt_conflicts_"
+
+	conflictBasePrefix  = "base_"
+	conflictOursPrefix  = "our_"
+	conflictTheirPrefix = "their_"
+)
+
+var _ sql.Table = (*ConflictsTable)(nil)
+
+// ConflictsTable is a sql.Table implementation that implements a system table which shows the number of conflicting
+// rows in each table of the working set which has conflicts
+type ConflictsTable struct {
+	db *Database
+}
+
+// NewConflictsTable creates a ConflictsTable for the working root of the database given
+func NewConflictsTable(db *Database) *ConflictsTable {
+	return &ConflictsTable{db: db}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// ConflictsTableName
+func (ct *ConflictsTable) Name() string {
+	return ConflictsTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// ConflictsTableName
+func (ct *ConflictsTable) String() string {
+	return ConflictsTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the conflicts system table.
+func (ct *ConflictsTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "table", Type: sql.Text, Source: ConflictsTableName, PrimaryKey: true},
+		{Name: "num_conflicts", Type: sql.Uint64, Source: ConflictsTableName, PrimaryKey: false},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (ct *ConflictsTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &doltTablePartitionIter{}, nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (ct *ConflictsTable) PartitionRows(sqlCtx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	root := ct.db.root
+	tblNames, err := root.TablesInConflict(sqlCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]sql.Row, 0, len(tblNames))
+	for _, tblName := range tblNames {
+		tbl, _, err := root.GetTable(sqlCtx, tblName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := tbl.NumRowsInConflict(sqlCtx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, sql.NewRow(tblName, n))
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
+
+var _ sql.DeletableTable = (*TableConflictsTable)(nil)
+
+// TableConflictsTable is a sql.Table implementation that implements a system table which shows the conflicting rows of
+// a single table.  Each row has the base, our and their version of a row in conflict, with the columns of each
+// version prefixed by base_, our_ and their_ respectively.  Versions which don't exist, such as the base version of a
+// row added on both sides of a merge, are NULL.  Deleting a row marks its conflict as resolved, keeping our version of
+// the row in the table.
+type TableConflictsTable struct {
+	tblName string
+	db      *Database
+	tbl     *doltdb.Table
+	schs    [3]schema.Schema
+	sqlSch  sql.Schema
+}
+
+// NewTableConflictsTable creates a TableConflictsTable for the table with the name given in the working root of the
+// database given.
+func NewTableConflictsTable(ctx context.Context, db *Database, tblName string) (*TableConflictsTable, error) {
+	tbl, ok, err := db.root.GetTable(ctx, tblName)
+
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, sql.ErrTableNotFound.New(DoltConflictsTablePrefix + tblName)
+	}
+
+	base, ours, theirs, err := tbl.GetConflictSchemas(ctx)
+
+	if err == doltdb.ErrNoConflicts {
+		// the table has no conflicts, but still has the same columns it would if it did
+		ours, err = tbl.GetSchema(ctx)
+		base, theirs = ours, ours
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	ct := &TableConflictsTable{tblName: tblName, db: db, tbl: tbl, schs: [3]schema.Schema{base, ours, theirs}}
+	for i, prefix := range []string{conflictBasePrefix, conflictOursPrefix, conflictTheirPrefix} {
+		sqlSch, err := doltSchemaToSqlSchema(ct.Name(), ct.schs[i])
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, col := range sqlSch {
+			col.Name = prefix + col.Name
+			col.Nullable = true
+		}
+
+		ct.sqlSch = append(ct.sqlSch, sqlSch...)
+	}
+
+	return ct, nil
+}
+
+// Name is a sql.Table interface function which returns the name of the table
+func (ct *TableConflictsTable) Name() string {
+	return DoltConflictsTablePrefix + ct.tblName
+}
+
+// String is a sql.Table interface function which returns the name of the table
+func (ct *TableConflictsTable) String() string {
+	return DoltConflictsTablePrefix + ct.tblName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the conflicts table.
+func (ct *TableConflictsTable) Schema() sql.Schema {
+	return ct.sqlSch
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (ct *TableConflictsTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &doltTablePartitionIter{}, nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (ct *TableConflictsTable) PartitionRows(sqlCtx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	if has, err := ct.tbl.HasConflicts(); err != nil {
+		return nil, err
+	} else if !has {
+		return sql.RowsToRowIter(), nil
+	}
+
+	_, confData, err := ct.tbl.GetConflicts(sqlCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	itr, err := confData.Iterator(sqlCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &conflictRowIter{ctx: sqlCtx, ct: ct, itr: itr}, nil
+}
+
+// Deleter is a sql.DeletableTable interface function that returns a sql.RowDeleter which resolves the conflicts of
+// the rows deleted.
+func (ct *TableConflictsTable) Deleter(*sql.Context) sql.RowDeleter {
+	return &conflictDeleter{ct: ct}
+}
+
+// conflictRowIter is a sql.RowIter implementation which iterates over the conflicts of a table.
+type conflictRowIter struct {
+	ctx context.Context
+	ct  *TableConflictsTable
+	itr types.MapIterator
+}
+
+// Next retrieves the next row. It will return io.EOF if it's the last row.
+func (itr *conflictRowIter) Next() (sql.Row, error) {
+	key, val, err := itr.itr.Next(itr.ctx)
+
+	if err != nil {
+		return nil, err
+	} else if key == nil {
+		return nil, io.EOF
+	}
+
+	cnf, err := doltdb.ConflictFromTuple(val.(types.Tuple))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var sqlRow sql.Row
+	for i, nonKey := range []types.Value{cnf.Base, cnf.Value, cnf.MergeValue} {
+		sch := itr.ct.schs[i]
+
+		if types.IsNull(nonKey) {
+			sqlRow = append(sqlRow, make(sql.Row, sch.GetAllCols().Size())...)
+			continue
+		}
+
+		r, err := row.FromNoms(sch, key.(types.Tuple), nonKey.(types.Tuple))
+
+		if err != nil {
+			return nil, err
+		}
+
+		vals, err := doltRowToSqlRow(r, sch)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sqlRow = append(sqlRow, vals...)
+	}
+
+	return sqlRow, nil
+}
+
+// Close closes the iterator.
+func (itr *conflictRowIter) Close() error {
+	return nil
+}
+
+// conflictDeleter is a sql.RowDeleter implementation which resolves the conflicts of the rows it's given, writing the
+// updated table to the database when it's closed.
+type conflictDeleter struct {
+	ct   *TableConflictsTable
+	keys []types.Value
+}
+
+// Delete is a sql.RowDeleter interface function which marks the conflict of the row given as resolved.
+func (cd *conflictDeleter) Delete(ctx *sql.Context, sqlRow sql.Row) error {
+	if err := cd.ct.db.checkWritable(); err != nil {
+		return err
+	}
+
+	key, err := cd.ct.conflictKey(ctx, sqlRow)
+
+	if err != nil {
+		return err
+	}
+
+	cd.keys = append(cd.keys, key)
+	return nil
+}
+
+// Close is a sql.RowDeleter interface function which writes the table with the deleted conflicts resolved to the
+// database.  Once every conflict is resolved the table is no longer in conflict.
+func (cd *conflictDeleter) Close(ctx *sql.Context) error {
+	if len(cd.keys) == 0 {
+		return nil
+	}
+
+	_, _, tbl, err := cd.ct.tbl.ResolveConflicts(ctx, cd.keys)
+
+	if err != nil {
+		return err
+	} else if tbl == nil {
+		// none of the keys were in conflict
+		return nil
+	}
+
+	n, err := tbl.NumRowsInConflict(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	hasSchemaConflicts, err := tbl.HasSchemaConflicts()
+
+	if err != nil {
+		return err
+	}
+
+	if n == 0 && !hasSchemaConflicts {
+		tbl, err = tbl.ClearConflicts()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	root, err := cd.ct.db.root.PutTable(ctx, cd.ct.tblName, tbl)
+
+	if err != nil {
+		return err
+	}
+
+	cd.ct.tbl = tbl
+	cd.ct.db.SetRoot(root)
+	return nil
+}
+
+// conflictKey returns the key of the conflict for a row of the table.  The primary key values are taken from our
+// version of the row, or if it doesn't exist, from their version or the base version.
+func (ct *TableConflictsTable) conflictKey(ctx context.Context, sqlRow sql.Row) (types.Value, error) {
+	ours := ct.schs[1]
+	pkCols := ours.GetPKCols()
+	taggedVals := make(row.TaggedValues)
+
+	for _, i := range []int{1, 2, 0} {
+		offset := 0
+		for j := 0; j < i; j++ {
+			offset += ct.schs[j].GetAllCols().Size()
+		}
+
+		idx := 0
+		err := ct.schs[i].GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+			val := sqlRow[offset+idx]
+			idx++
+
+			if _, isPK := pkCols.GetByTag(tag); !isPK || val == nil {
+				return false, nil
+			} else if _, ok := taggedVals[tag]; ok {
+				return false, nil
+			}
+
+			taggedVals[tag], err = sqlTypes.SqlValToNomsVal(val, col.Kind)
+			return err != nil, err
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return taggedVals.NomsTupleForTags(ct.tbl.Format(), pkCols.Tags, true).Value(ctx)
+}
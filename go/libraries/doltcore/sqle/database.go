@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/src-d/go-mysql-server/sql"
 
@@ -36,13 +37,25 @@ const (
 	single  batchMode = false
 )
 
+// ErrReadOnlyDatabaseFmt is the format of the error returned when a statement tries to modify a read-only database.
+var ErrReadOnlyDatabaseFmt = "database '%s' is read-only"
+
 // Database implements sql.Database for a dolt DB.
 type Database struct {
 	name      string
 	root      *doltdb.RootValue
 	dEnv      *env.DoltEnv
 	batchMode batchMode
-	tables    map[string]*DoltTable
+	readOnly  bool
+
+	// tablesMu guards the tables cached by GetTableInsensitive, which read-only statements call at the same time as
+	// each other.  Statements which change the database don't run alongside other statements.
+	tablesMu *sync.Mutex
+	tables   map[string]*DoltTable
+
+	// sessWS is the working set of the session running a statement against the database, if the database is shared
+	// with other sessions.
+	sessWS *sessionWorkingSet
 }
 
 // NewDatabase returns a new dolt database to use in queries.
@@ -52,10 +65,19 @@ func NewDatabase(name string, root *doltdb.RootValue, dEnv *env.DoltEnv) *Databa
 		root:      root,
 		dEnv:      dEnv,
 		batchMode: single,
+		tablesMu:  &sync.Mutex{},
 		tables:    make(map[string]*DoltTable),
 	}
 }
 
+// NewReadOnlyDatabase returns a new dolt database to use in queries which rejects any statement that would modify it.
+// It's used to query historical revisions of a repository, which can't be written to.
+func NewReadOnlyDatabase(name string, root *doltdb.RootValue, dEnv *env.DoltEnv) *Database {
+	db := NewDatabase(name, root, dEnv)
+	db.readOnly = true
+	return db
+}
+
 // NewBatchedDatabase returns a new dolt database executing in batch insert mode. Integrators must call Flush() to
 // commit any outstanding edits.
 func NewBatchedDatabase(name string, root *doltdb.RootValue, dEnv *env.DoltEnv) *Database {
@@ -64,6 +86,7 @@ func NewBatchedDatabase(name string, root *doltdb.RootValue, dEnv *env.DoltEnv)
 		root:      root,
 		dEnv:      dEnv,
 		batchMode: batched,
+		tablesMu:  &sync.Mutex{},
 		tables:    make(map[string]*DoltTable),
 	}
 }
@@ -74,6 +97,10 @@ func (db *Database) Name() string {
 }
 
 func (db *Database) GetTableInsensitive(ctx context.Context, tblName string) (sql.Table, bool, error) {
+	if name, asOf, ok := splitAsOfTableName(tblName); ok {
+		return db.getTableAsOf(ctx, tblName, name, asOf)
+	}
+
 	lwrName := strings.ToLower(tblName)
 	if strings.HasPrefix(lwrName, DoltDiffTablePrefix) {
 		tblName = tblName[len(DoltDiffTablePrefix):]
@@ -86,8 +113,54 @@ func (db *Database) GetTableInsensitive(ctx context.Context, tblName string) (sq
 		return dt, true, nil
 	}
 
-	if lwrName == LogTableName {
+	if strings.HasPrefix(lwrName, DoltHistoryTablePrefix) {
+		tblName = tblName[len(DoltHistoryTablePrefix):]
+
+		// the engine lower cases table names, so the name is matched against the tables in the working root
+		if tableNames, err := db.root.GetTableNames(ctx); err != nil {
+			return nil, false, err
+		} else if exactName, ok := sql.GetTableNameInsensitive(tblName, tableNames); ok {
+			tblName = exactName
+		}
+
+		ht, err := NewHistoryTable(ctx, tblName, db.dEnv)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		return ht, true, nil
+	}
+
+	if strings.HasPrefix(lwrName, DoltConflictsTablePrefix) {
+		tblName = tblName[len(DoltConflictsTablePrefix):]
+
+		if tableNames, err := db.root.GetTableNames(ctx); err != nil {
+			return nil, false, err
+		} else if exactName, ok := sql.GetTableNameInsensitive(tblName, tableNames); ok {
+			tblName = exactName
+		}
+
+		ct, err := NewTableConflictsTable(ctx, db, tblName)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		return ct, true, nil
+	}
+
+	switch lwrName {
+	case LogTableName:
 		return NewLogTable(db.dEnv), true, nil
+	case BranchesTableName:
+		return NewBranchesTable(db.dEnv), true, nil
+	case RemotesTableName:
+		return NewRemotesTable(db.dEnv), true, nil
+	case StatusTableName:
+		return NewStatusTable(db), true, nil
+	case ConflictsTableName:
+		return NewConflictsTable(db), true, nil
 	}
 
 	tableNames, err := db.root.GetTableNames(ctx)
@@ -102,6 +175,9 @@ func (db *Database) GetTableInsensitive(ctx context.Context, tblName string) (sq
 		return nil, false, nil
 	}
 
+	db.tablesMu.Lock()
+	defer db.tablesMu.Unlock()
+
 	if table, ok := db.tables[exactName]; ok {
 		return table, true, nil
 	}
@@ -129,20 +205,45 @@ func (db *Database) GetTableNames(ctx context.Context) ([]string, error) {
 	return db.root.GetTableNames(ctx)
 }
 
+// IsReadOnly returns whether the database rejects statements that would modify it.
+func (db *Database) IsReadOnly() bool {
+	return db.readOnly
+}
+
+// checkWritable returns an error if the database is read-only.
+func (db *Database) checkWritable() error {
+	if db.readOnly {
+		return fmt.Errorf(ErrReadOnlyDatabaseFmt, db.name)
+	}
+
+	return nil
+}
+
 // Root returns the root value for the database.
 func (db *Database) Root() *doltdb.RootValue {
 	return db.root
 }
 
 // Set a new root value for the database. Can be used if the dolt working
-// set value changes outside of the basic SQL execution engine.
+// set value changes outside of the basic SQL execution engine. Cached tables
+// hold values read from the old root, so any without outstanding batched edits
+// are discarded.
 func (db *Database) SetRoot(newRoot *doltdb.RootValue) {
-	// TODO: races
 	db.root = newRoot
+
+	for name, table := range db.tables {
+		if table.ed == nil {
+			delete(db.tables, name)
+		}
+	}
 }
 
 // DropTable drops the table with the name given
 func (db *Database) DropTable(ctx *sql.Context, tableName string) error {
+	if err := db.checkWritable(); err != nil {
+		return err
+	}
+
 	tableExists, err := db.root.HasTable(ctx, tableName)
 	if err != nil {
 		return err
@@ -152,6 +253,10 @@ func (db *Database) DropTable(ctx *sql.Context, tableName string) error {
 		return sql.ErrTableNotFound.New(tableName)
 	}
 
+	if err := db.root.CheckNotReferenced(ctx, tableName); err != nil {
+		return err
+	}
+
 	newRoot, err := db.root.RemoveTables(ctx, tableName)
 	if err != nil {
 		return err
@@ -166,6 +271,9 @@ func (db *Database) DropTable(ctx *sql.Context, tableName string) error {
 
 // CreateTable creates a table with the name and schema given.
 func (db *Database) CreateTable(ctx *sql.Context, tableName string, schema sql.Schema) error {
+	if err := db.checkWritable(); err != nil {
+		return err
+	}
 
 	if !doltdb.IsValidTableName(tableName) {
 		return fmt.Errorf("Invalid table name: '%v'", tableName)
@@ -182,6 +290,11 @@ func (db *Database) CreateTable(ctx *sql.Context, tableName string, schema sql.S
 		return err
 	}
 
+	doltSch, err = applyCreateTableColumnOptions(doltSch, ctx.Query())
+	if err != nil {
+		return err
+	}
+
 	schVal, err := encoding.MarshalAsNomsValue(ctx, db.root.VRW(), doltSch)
 	if err != nil {
 		return err
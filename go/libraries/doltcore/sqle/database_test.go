@@ -0,0 +1,71 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	sqle "github.com/src-d/go-mysql-server"
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	. "github.com/liquidata-inc/dolt/go/libraries/doltcore/sql/sqltestutil"
+)
+
+func TestReadOnlyDatabase(t *testing.T) {
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	root, err := dEnv.WorkingRoot(context.Background())
+	require.NoError(t, err)
+
+	tests := []struct {
+		query    string
+		readOnly bool
+	}{
+		{"select * from people", false},
+		{"insert into people (id, first, last, is_married, age, rating) values (10, 'Maggie', 'Simpson', false, 1, 5.0)", true},
+		{"update people set age = 41 where id = 0", true},
+		{"delete from people where id = 0", true},
+		{"replace into people (id, first, last, is_married, age, rating) values (0, 'Homer', 'Simpson', true, 40, 8.5)", true},
+		{"create table test (pk int primary key)", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			db := NewReadOnlyDatabase("dolt", root, dEnv)
+			engine := sqle.NewDefault()
+			engine.AddDatabase(db)
+			require.NoError(t, engine.Init())
+
+			sqlCtx := sql.NewContext(context.Background())
+			_, iter, err := engine.Query(sqlCtx, test.query)
+			if err == nil {
+				_, err = sql.RowIterToRows(iter)
+			}
+
+			if test.readOnly {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "read-only")
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, root, db.Root())
+		})
+	}
+}
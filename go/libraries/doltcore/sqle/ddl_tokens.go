@@ -0,0 +1,104 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import "vitess.io/vitess/go/vt/sqlparser"
+
+type ddlToken struct {
+	typ int
+	val string
+}
+
+// ddlTokens is a cursor over the tokens of a statement which the SQL parser accepts but doesn't fully parse, such as
+// index and foreign key statements.
+type ddlTokens struct {
+	tokens []ddlToken
+	pos    int
+}
+
+// tokenizeDDL returns a cursor over the tokens of the query given, not including any trailing semicolons.
+func tokenizeDDL(query string) *ddlTokens {
+	var tokens []ddlToken
+	tkn := sqlparser.NewStringTokenizer(query)
+	for {
+		typ, val := tkn.Scan()
+
+		if typ == 0 || typ == sqlparser.LEX_ERROR {
+			break
+		}
+
+		tokens = append(tokens, ddlToken{typ, string(val)})
+	}
+
+	for len(tokens) > 0 && tokens[len(tokens)-1].typ == ';' {
+		tokens = tokens[:len(tokens)-1]
+	}
+
+	return &ddlTokens{tokens: tokens}
+}
+
+// accept advances past the next token and returns true if it is of the type given.
+func (t *ddlTokens) accept(typ int) bool {
+	if t.pos < len(t.tokens) && t.tokens[t.pos].typ == typ {
+		t.pos++
+		return true
+	}
+
+	return false
+}
+
+// ident advances past the next token and returns its value if it is an identifier.
+func (t *ddlTokens) ident() (string, bool) {
+	if t.pos < len(t.tokens) && t.tokens[t.pos].typ == sqlparser.ID {
+		t.pos++
+		return t.tokens[t.pos-1].val, true
+	}
+
+	return "", false
+}
+
+// identList parses a parenthesized, comma separated list of identifiers.  skip is called after each identifier to
+// allow for modifiers such as sort orders.
+func (t *ddlTokens) identList(skip func()) ([]string, bool) {
+	if !t.accept('(') {
+		return nil, false
+	}
+
+	var idents []string
+	for {
+		id, ok := t.ident()
+
+		if !ok {
+			return nil, false
+		}
+
+		idents = append(idents, id)
+
+		if skip != nil {
+			skip()
+		}
+
+		if t.accept(')') {
+			return idents, true
+		} else if !t.accept(',') {
+			return nil, false
+		}
+	}
+}
+
+// done returns true if every token has been consumed.
+func (t *ddlTokens) done() bool {
+	return t.pos == len(t.tokens)
+}
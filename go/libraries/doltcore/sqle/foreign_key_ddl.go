@@ -0,0 +1,414 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+)
+
+// ForeignKeyDDL is a foreign key added or dropped by an ALTER TABLE statement parsed by ParseForeignKeyDDL, or defined
+// in a CREATE TABLE statement.
+type ForeignKeyDDL struct {
+	// Drop is true for DROP FOREIGN KEY statements, and false when adding a foreign key
+	Drop bool
+	// Name is the name of the foreign key.  If empty when adding a foreign key, a name is generated.
+	Name string
+	// Table is the name of the child table
+	Table string
+	// Columns are the names of the constrained columns of the child table.  Empty when dropping a foreign key.
+	Columns []string
+	// ReferencedTable is the name of the parent table.  Empty when dropping a foreign key.
+	ReferencedTable string
+	// ReferencedColumns are the names of the referenced columns of the parent table.  Empty when dropping a foreign key.
+	ReferencedColumns []string
+	// OnDelete and OnUpdate are the referential actions of the foreign key
+	OnDelete, OnUpdate sqlparser.ReferenceAction
+}
+
+// ParseForeignKeyDDL parses statements of the forms
+//
+//	ALTER TABLE table ADD [CONSTRAINT [name]] FOREIGN KEY (column, ...) REFERENCES parent (column, ...)
+//		[ON DELETE action] [ON UPDATE action]
+//	ALTER TABLE table DROP FOREIGN KEY name
+//
+// The SQL parser accepts both statements but ignores them, so they must be recognized before the query is parsed.  ok
+// is false if the query isn't a foreign key statement, and an error is returned if it is one but can't be parsed.
+func ParseForeignKeyDDL(query string) (ddl *ForeignKeyDDL, ok bool, err error) {
+	tokens := tokenizeDDL(query)
+
+	if !tokens.accept(sqlparser.ALTER) {
+		return nil, false, nil
+	}
+
+	_ = tokens.accept(sqlparser.IGNORE)
+
+	if !tokens.accept(sqlparser.TABLE) {
+		return nil, false, nil
+	}
+
+	ddl = &ForeignKeyDDL{}
+	table, tableOk := tokens.ident()
+
+	switch {
+	case tokens.accept(sqlparser.ADD):
+		if tokens.accept(sqlparser.CONSTRAINT) {
+			ddl.Name, _ = tokens.ident()
+		}
+
+		if !tokens.accept(sqlparser.FOREIGN) {
+			return nil, false, nil
+		}
+	case tokens.accept(sqlparser.DROP):
+		if !tokens.accept(sqlparser.FOREIGN) {
+			return nil, false, nil
+		}
+
+		ddl.Drop = true
+	default:
+		return nil, false, nil
+	}
+
+	syntaxErr := fmt.Errorf("invalid foreign key statement: '%s'", query)
+
+	if !tableOk || !tokens.accept(sqlparser.KEY) {
+		return nil, true, syntaxErr
+	}
+
+	ddl.Table = table
+
+	if ddl.Drop {
+		var nameOk bool
+		ddl.Name, nameOk = tokens.ident()
+
+		if !nameOk || !tokens.done() {
+			return nil, true, syntaxErr
+		}
+
+		return ddl, true, nil
+	}
+
+	// MySQL allows an index name here, which is ignored as the referenced columns must already be indexed
+	_, _ = tokens.ident()
+
+	var colsOk, refTableOk, refColsOk bool
+	ddl.Columns, colsOk = tokens.identList(nil)
+	refOk := tokens.accept(sqlparser.REFERENCES)
+	ddl.ReferencedTable, refTableOk = tokens.ident()
+	ddl.ReferencedColumns, refColsOk = tokens.identList(nil)
+
+	if !colsOk || !refOk || !refTableOk || !refColsOk {
+		return nil, true, syntaxErr
+	}
+
+	for tokens.accept(sqlparser.ON) {
+		var action *sqlparser.ReferenceAction
+		if tokens.accept(sqlparser.DELETE) {
+			action = &ddl.OnDelete
+		} else if tokens.accept(sqlparser.UPDATE) {
+			action = &ddl.OnUpdate
+		} else {
+			return nil, true, syntaxErr
+		}
+
+		switch {
+		case tokens.accept(sqlparser.RESTRICT):
+			*action = sqlparser.Restrict
+		case tokens.accept(sqlparser.CASCADE):
+			*action = sqlparser.Cascade
+		case tokens.accept(sqlparser.NO) && tokens.accept(sqlparser.ACTION):
+			*action = sqlparser.NoAction
+		case tokens.accept(sqlparser.SET) && tokens.accept(sqlparser.NULL):
+			*action = sqlparser.SetNull
+		case tokens.accept(sqlparser.DEFAULT):
+			*action = sqlparser.SetDefault
+		default:
+			return nil, true, syntaxErr
+		}
+	}
+
+	if !tokens.done() {
+		return nil, true, syntaxErr
+	}
+
+	return ddl, true, nil
+}
+
+// ParseCreateTableForeignKeys returns the foreign keys defined in a CREATE TABLE statement.  The SQL engine creates
+// tables without their foreign keys, so they are added with ExecuteForeignKeyDDL once the table has been created.  No
+// foreign keys are returned for other statements, including those which fail to parse.
+func ParseCreateTableForeignKeys(query string) []*ForeignKeyDDL {
+	stmt, err := sqlparser.Parse(query)
+
+	if err != nil {
+		return nil
+	}
+
+	ddl, ok := stmt.(*sqlparser.DDL)
+
+	if !ok || ddl.Action != sqlparser.CreateStr || ddl.TableSpec == nil {
+		return nil
+	}
+
+	var fks []*ForeignKeyDDL
+	for _, constraint := range ddl.TableSpec.Constraints {
+		fkDef, ok := constraint.Details.(*sqlparser.ForeignKeyDefinition)
+
+		if !ok {
+			continue
+		}
+
+		fk := &ForeignKeyDDL{
+			Name:            constraint.Name,
+			Table:           ddl.Table.Name.String(),
+			ReferencedTable: fkDef.ReferencedTable.Name.String(),
+			OnDelete:        fkDef.OnDelete,
+			OnUpdate:        fkDef.OnUpdate,
+		}
+
+		for _, col := range fkDef.Source {
+			fk.Columns = append(fk.Columns, col.String())
+		}
+
+		for _, col := range fkDef.ReferencedColumns {
+			fk.ReferencedColumns = append(fk.ReferencedColumns, col.String())
+		}
+
+		fks = append(fks, fk)
+	}
+
+	return fks
+}
+
+// CheckDropTables returns an error if the query is a DROP TABLE statement for a table referenced by a foreign key of
+// another table.  The engine doesn't report errors returned by Database.DropTable, so this check must be made before
+// the statement is run.
+func CheckDropTables(ctx context.Context, db *Database, query string) error {
+	stmt, err := sqlparser.Parse(query)
+
+	if err != nil {
+		return nil
+	}
+
+	ddl, ok := stmt.(*sqlparser.DDL)
+
+	if !ok || ddl.Action != sqlparser.DropStr {
+		return nil
+	}
+
+	tableNames, err := db.root.GetTableNames(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, tableName := range ddl.FromTables {
+		if exactName, ok := sql.GetTableNameInsensitive(tableName.Name.String(), tableNames); ok {
+			if err := db.root.CheckNotReferenced(ctx, exactName); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ExecuteForeignKeyDDL adds or drops a foreign key of the database given.
+func ExecuteForeignKeyDDL(ctx context.Context, db *Database, ddl *ForeignKeyDDL) error {
+	if ddl.Drop {
+		return db.DropForeignKey(ctx, ddl.Table, ddl.Name)
+	}
+
+	for _, action := range []sqlparser.ReferenceAction{ddl.OnDelete, ddl.OnUpdate} {
+		if action != sqlparser.DefaultAction && action != sqlparser.Restrict && action != sqlparser.NoAction {
+			return fmt.Errorf("unsupported foreign key action '%s', only RESTRICT and NO ACTION are supported", sqlparser.String(action))
+		}
+	}
+
+	return db.CreateForeignKey(ctx, ddl.Table, ddl.Name, ddl.Columns, ddl.ReferencedTable, ddl.ReferencedColumns)
+}
+
+// CreateForeignKey adds a foreign key to a table which requires the values of the columns given to match the values of
+// the referenced columns of a row in the parent table.  The referenced columns must be the primary key of the parent
+// table, or the leading columns of one of its indexes.  A doltdb.ForeignKeyViolationError is returned if any existing
+// rows violate the foreign key.
+func (db *Database) CreateForeignKey(ctx context.Context, tableName, fkName string, columns []string, refTableName string, refColumns []string) error {
+	tableName, tbl, sch, err := db.getTableForDDL(ctx, tableName)
+
+	if err != nil {
+		return err
+	}
+
+	refTableName, _, refSch, err := db.getTableForDDL(ctx, refTableName)
+
+	if err != nil {
+		return err
+	}
+
+	if refTableName == tableName {
+		refSch = sch
+	}
+
+	if fkName == "" {
+		for i := 1; ; i++ {
+			fkName = fmt.Sprintf("%s_ibfk_%d", tableName, i)
+
+			if _, ok := sch.ForeignKeys().GetByName(fkName); !ok {
+				break
+			}
+		}
+	} else if _, ok := sch.ForeignKeys().GetByName(fkName); ok {
+		return fmt.Errorf("foreign key '%s' already exists on table '%s'", fkName, tableName)
+	}
+
+	if len(columns) != len(refColumns) {
+		return fmt.Errorf("foreign key '%s' must reference the same number of columns it contains", fkName)
+	}
+
+	fk := schema.ForeignKey{Name: fkName, ReferencedTable: refTableName}
+	for i := range columns {
+		col, ok := sch.GetAllCols().GetByNameCaseInsensitive(columns[i])
+
+		if !ok {
+			return fmt.Errorf("table '%s' does not have column '%s'", tableName, columns[i])
+		} else if fk.HasTag(col.Tag) {
+			return fmt.Errorf("column '%s' is used more than once in foreign key '%s'", columns[i], fkName)
+		}
+
+		refCol, ok := refSch.GetAllCols().GetByNameCaseInsensitive(refColumns[i])
+
+		if !ok {
+			return fmt.Errorf("table '%s' does not have column '%s'", refTableName, refColumns[i])
+		} else if refCol.Kind != col.Kind {
+			return fmt.Errorf("column '%s' of foreign key '%s' does not have the same type as the column '%s' it references", columns[i], fkName, refColumns[i])
+		}
+
+		fk.Tags = append(fk.Tags, col.Tag)
+		fk.ReferencedTags = append(fk.ReferencedTags, refCol.Tag)
+	}
+
+	if !isKeyedBy(refSch, fk.ReferencedTags) {
+		return fmt.Errorf("the columns referenced by foreign key '%s' must be the primary key or the leading columns of an index of table '%s'", fkName, refTableName)
+	}
+
+	foreignKeys, err := sch.ForeignKeys().Append(fk)
+
+	if err != nil {
+		return err
+	}
+
+	newSch, err := schema.SchemaWithForeignKeys(sch, foreignKeys)
+
+	if err != nil {
+		return err
+	}
+
+	newTbl, err := tbl.UpdateSchema(ctx, newSch)
+
+	if err != nil {
+		return err
+	}
+
+	newRoot, err := db.root.PutTable(ctx, tableName, newTbl)
+
+	if err != nil {
+		return err
+	}
+
+	// only the new foreign key is checked, as it is the only one not defined on the old table
+	if err := newRoot.CheckForeignKeys(ctx, tableName, tbl); err != nil {
+		return err
+	}
+
+	delete(db.tables, tableName)
+	db.SetRoot(newRoot)
+
+	return nil
+}
+
+// DropForeignKey removes a foreign key from a table.
+func (db *Database) DropForeignKey(ctx context.Context, tableName, fkName string) error {
+	tableName, tbl, sch, err := db.getTableForDDL(ctx, tableName)
+
+	if err != nil {
+		return err
+	}
+
+	fk, ok := sch.ForeignKeys().GetByName(fkName)
+
+	if !ok {
+		return fmt.Errorf("foreign key '%s' does not exist on table '%s'", fkName, tableName)
+	}
+
+	foreignKeys, err := sch.ForeignKeys().Remove(fk.Name)
+
+	if err != nil {
+		return err
+	}
+
+	newSch, err := schema.SchemaWithForeignKeys(sch, foreignKeys)
+
+	if err != nil {
+		return err
+	}
+
+	tbl, err = tbl.UpdateSchema(ctx, newSch)
+
+	if err != nil {
+		return err
+	}
+
+	return db.putTableForDDL(ctx, tableName, tbl)
+}
+
+// isKeyedBy returns true if the columns with the tags given, in any order, are the primary key columns of the schema or
+// the leading columns of one of its indexes, so rows can be looked up by their values efficiently.
+func isKeyedBy(sch schema.Schema, tags []uint64) bool {
+	sameTags := func(keyTags []uint64) bool {
+		if len(keyTags) < len(tags) {
+			return false
+		}
+
+		for _, tag := range keyTags[:len(tags)] {
+			found := false
+			for _, other := range tags {
+				found = found || tag == other
+			}
+
+			if !found {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if pkTags := sch.GetPKCols().Tags; len(pkTags) == len(tags) && sameTags(pkTags) {
+		return true
+	}
+
+	for _, idx := range sch.Indexes().GetIndexes() {
+		if sameTags(idx.Tags) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,249 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+)
+
+func TestParseForeignKeyDDL(t *testing.T) {
+	tests := []struct {
+		query     string
+		expected  *ForeignKeyDDL
+		expectErr bool
+	}{
+		{"select * from members", nil, false},
+		{"alter table members add column age int", nil, false},
+		{"create index idx_family on members (family_id)", nil, false},
+		{
+			"alter table members add foreign key (family_id) references families (id)",
+			&ForeignKeyDDL{Table: "members", Columns: []string{"family_id"}, ReferencedTable: "families", ReferencedColumns: []string{"id"}},
+			false,
+		},
+		{
+			"ALTER TABLE `members` ADD CONSTRAINT `fk_family` FOREIGN KEY (a, b) REFERENCES `families` (x, y) ON DELETE RESTRICT ON UPDATE NO ACTION;",
+			&ForeignKeyDDL{
+				Name:              "fk_family",
+				Table:             "members",
+				Columns:           []string{"a", "b"},
+				ReferencedTable:   "families",
+				ReferencedColumns: []string{"x", "y"},
+				OnDelete:          sqlparser.Restrict,
+				OnUpdate:          sqlparser.NoAction,
+			},
+			false,
+		},
+		{
+			"alter table members add foreign key (family_id) references families (id) on delete cascade",
+			&ForeignKeyDDL{Table: "members", Columns: []string{"family_id"}, ReferencedTable: "families", ReferencedColumns: []string{"id"}, OnDelete: sqlparser.Cascade},
+			false,
+		},
+		{
+			"alter table members drop foreign key fk_family",
+			&ForeignKeyDDL{Drop: true, Name: "fk_family", Table: "members"},
+			false,
+		},
+		{"alter table members add foreign key (family_id)", nil, true},
+		{"alter table members add foreign key (family_id) references families", nil, true},
+		{"alter table members add foreign key (family_id) references families (id) on delete", nil, true},
+		{"alter table members drop foreign key", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			ddl, ok, err := ParseForeignKeyDDL(test.query)
+
+			if test.expectErr {
+				assert.True(t, ok)
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected != nil, ok)
+				assert.Equal(t, test.expected, ddl)
+			}
+		})
+	}
+}
+
+func TestParseCreateTableForeignKeys(t *testing.T) {
+	fks := ParseCreateTableForeignKeys("create table members (id int primary key, family_id int, constraint fk_family foreign key (family_id) references families (id))")
+	assert.Equal(t, []*ForeignKeyDDL{
+		{Name: "fk_family", Table: "members", Columns: []string{"family_id"}, ReferencedTable: "families", ReferencedColumns: []string{"id"}},
+	}, fks)
+
+	assert.Empty(t, ParseCreateTableForeignKeys("create table families (id int primary key)"))
+	assert.Empty(t, ParseCreateTableForeignKeys("select * from members"))
+}
+
+func createFamilyTables(t *testing.T, dEnv *env.DoltEnv) {
+	for _, query := range []string{
+		"create table families (id bigint primary key, name varchar(80))",
+		"create table members (id bigint primary key, family_id bigint, name varchar(80))",
+		"insert into families (id, name) values (1, 'Simpson'), (2, 'Flanders')",
+		"insert into members (id, family_id, name) values (1, 1, 'Homer'), (2, 2, 'Ned'), (3, null, 'Moe')",
+	} {
+		_, err := executeQuery(context.Background(), dEnv, query)
+		require.NoError(t, err, query)
+	}
+}
+
+func getForeignKeys(t *testing.T, dEnv *env.DoltEnv, tableName string) []schema.ForeignKey {
+	ctx := context.Background()
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+	tbl, ok, err := root.GetTable(ctx, tableName)
+	require.NoError(t, err)
+	require.True(t, ok)
+	sch, err := tbl.GetSchema(ctx)
+	require.NoError(t, err)
+
+	return sch.ForeignKeys().GetForeignKeys()
+}
+
+func TestCreateAndDropForeignKey(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	createFamilyTables(t, dEnv)
+
+	_, err := executeQuery(ctx, dEnv, "alter table members add foreign key (family_id) references families (id)")
+	require.NoError(t, err)
+
+	fks := getForeignKeys(t, dEnv, "members")
+	require.Len(t, fks, 1)
+	assert.Equal(t, "members_ibfk_1", fks[0].Name)
+	assert.Equal(t, "families", fks[0].ReferencedTable)
+
+	for _, query := range []string{
+		"alter table members add constraint members_ibfk_1 foreign key (family_id) references families (id)",
+		"alter table members add foreign key (missing) references families (id)",
+		"alter table members add foreign key (family_id) references families (name)",
+		"alter table members add foreign key (family_id) references families (id, name)",
+		"alter table members add foreign key (name) references families (id)",
+		"alter table members add foreign key (family_id) references missing (id)",
+		"alter table members add foreign key (family_id) references families (id) on delete cascade",
+		"alter table members drop foreign key fk_missing",
+		"drop table families",
+	} {
+		_, err = executeQuery(ctx, dEnv, query)
+		assert.Error(t, err, query)
+	}
+
+	_, err = executeQuery(ctx, dEnv, "alter table members drop foreign key members_ibfk_1")
+	require.NoError(t, err)
+	assert.Empty(t, getForeignKeys(t, dEnv, "members"))
+
+	// existing rows must satisfy a new foreign key
+	_, err = executeQuery(ctx, dEnv, "insert into members (id, family_id, name) values (4, 3, 'Apu')")
+	require.NoError(t, err)
+	_, err = executeQuery(ctx, dEnv, "alter table members add foreign key (family_id) references families (id)")
+	assert.True(t, doltdb.IsForeignKeyViolation(err))
+	assert.Empty(t, getForeignKeys(t, dEnv, "members"))
+
+	_, err = executeQuery(ctx, dEnv, "drop table families")
+	assert.NoError(t, err)
+}
+
+func TestForeignKeyEnforcement(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	_, err := executeQuery(ctx, dEnv, "create table families (id bigint primary key, name varchar(80))")
+	require.NoError(t, err)
+	_, err = executeQuery(ctx, dEnv, "create table members (id bigint primary key, family_id bigint, name varchar(80), constraint fk_family foreign key (family_id) references families (id))")
+	require.NoError(t, err)
+
+	fks := getForeignKeys(t, dEnv, "members")
+	require.Len(t, fks, 1)
+	assert.Equal(t, "fk_family", fks[0].Name)
+
+	for _, query := range []string{
+		"insert into families (id, name) values (1, 'Simpson'), (2, 'Flanders')",
+		"insert into members (id, family_id, name) values (1, 1, 'Homer'), (2, 2, 'Ned'), (3, null, 'Moe')",
+		"update members set family_id = 1 where id = 2",
+		"delete from families where id = 2",
+	} {
+		_, err = executeQuery(ctx, dEnv, query)
+		require.NoError(t, err, query)
+	}
+
+	for _, query := range []string{
+		"insert into members (id, family_id, name) values (4, 3, 'Apu')",
+		"update members set family_id = 3 where id = 1",
+		"delete from families where id = 1",
+		"update families set id = 3 where id = 1",
+		"drop table families",
+	} {
+		_, err = executeQuery(ctx, dEnv, query)
+		assert.Error(t, err, query)
+	}
+
+	rows, err := executeQuery(ctx, dEnv, "select id, family_id from members order by id")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(1), int64(1)}, {int64(2), int64(1)}, {int64(3), nil}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select id from families")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(1)}}, rows)
+}
+
+func TestForeignKeyConflictsOnMerge(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	createFamilyTables(t, dEnv)
+
+	for _, query := range []string{
+		"alter table members add constraint fk_family foreign key (family_id) references families (id)",
+		"select dolt_add('.')",
+		"select dolt_commit('-m', 'add tables')",
+		"select dolt_checkout('-b', 'other')",
+		"insert into members (id, family_id, name) values (4, 2, 'Maude')",
+		"select dolt_add('.')",
+		"select dolt_commit('-m', 'on other')",
+		"select dolt_checkout('master')",
+		"delete from members where id = 2",
+		"delete from families where id = 2",
+		"select dolt_add('.')",
+		"select dolt_commit('-m', 'on master')",
+	} {
+		_, err := executeQuery(ctx, dEnv, query)
+		require.NoError(t, err, query)
+	}
+
+	rows, err := executeQuery(ctx, dEnv, "select dolt_merge('other')")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(1)}}, rows)
+
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+	inConflict, err := root.TablesInConflict(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"members"}, inConflict)
+
+	tbl, _, err := root.GetTable(ctx, "members")
+	require.NoError(t, err)
+	n, err := tbl.NumRowsInConflict(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), n)
+}
@@ -0,0 +1,547 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"io"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/src-d/go-mysql-server/sql/expression"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/rowconv"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	sqlTypes "github.com/liquidata-inc/dolt/go/libraries/doltcore/sqle/types"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+const (
+	// DoltHistoryTablePrefix is the prefix of the names of the system tables containing the history of each table
+	DoltHistoryTablePrefix = "dolt_history_"
+
+	// CommitHashCol is the name of the history table column containing the hash of the commit a row was read from
+	CommitHashCol = "commit_hash"
+
+	// CommitterCol is the name of the history table column containing the name of the committer of the commit a row
+	// was read from
+	CommitterCol = "committer"
+
+	// CommitDateCol is the name of the history table column containing the date of the commit a row was read from
+	CommitDateCol = "commit_date"
+)
+
+var _ sql.FilteredTable = (*HistoryTable)(nil)
+
+// HistoryTable is a system table which contains every row of a table as it existed at each commit in the history of the
+// current branch.  Columns which were renamed or changed type over the table's history are mapped onto a schema which is
+// the superset of every schema the table has had.
+//
+// Filters on the commit columns are used to skip commits, and filters matching every primary key column to a value are
+// used to look up rows rather than scanning the table at each commit.
+type HistoryTable struct {
+	name          string
+	dEnv          *env.DoltEnv
+	ss            rowconv.SuperSchema
+	sqlSch        sql.Schema
+	commitFilters []sql.Expression
+	rowFilters    []sql.Expression
+}
+
+// NewHistoryTable creates a HistoryTable for the table with the given name.  Returns an error if the table doesn't
+// exist in any commit in the history of the current branch.
+func NewHistoryTable(ctx context.Context, name string, dEnv *env.DoltEnv) (*HistoryTable, error) {
+	head, err := headCommit(ctx, dEnv)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ssg := rowconv.NewSuperSchemaGen()
+	err = ssg.AddHistoryOfTableAtCommit(ctx, name, dEnv.DoltDB, head)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ss, err := ssg.GenerateSuperSchema(
+		rowconv.NameKindPair{Name: CommitHashCol, Kind: types.StringKind},
+		rowconv.NameKindPair{Name: CommitterCol, Kind: types.StringKind},
+		rowconv.NameKindPair{Name: CommitDateCol, Kind: types.TimestampKind})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if ss.GetSchema().GetAllCols().Size() == 3 {
+		return nil, sql.ErrTableNotFound.New(DoltHistoryTablePrefix + name)
+	}
+
+	sqlSch, err := doltSchemaToSqlSchema(DoltHistoryTablePrefix+name, ss.GetSchema())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &HistoryTable{name: name, dEnv: dEnv, ss: ss, sqlSch: sqlSch}, nil
+}
+
+func headCommit(ctx context.Context, dEnv *env.DoltEnv) (*doltdb.Commit, error) {
+	cs, err := doltdb.NewCommitSpec("HEAD", dEnv.RepoState.Head.Ref.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dEnv.DoltDB.Resolve(ctx, cs)
+}
+
+// Name returns the name of the history table
+func (ht *HistoryTable) Name() string {
+	return DoltHistoryTablePrefix + ht.name
+}
+
+// String returns the name of the history table
+func (ht *HistoryTable) String() string {
+	return DoltHistoryTablePrefix + ht.name
+}
+
+// Schema returns the schema of the history table, which is the superset of all the table's schemas followed by the
+// commit columns
+func (ht *HistoryTable) Schema() sql.Schema {
+	return ht.sqlSch
+}
+
+// Partitions returns a single partition containing all the history of the table
+func (ht *HistoryTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &doltTablePartitionIter{}, nil
+}
+
+// PartitionRows returns an iterator over the rows of the table at each commit, starting with the most recent commit
+func (ht *HistoryTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	head, err := headCommit(ctx, ht.dEnv)
+
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := actions.TimeSortedCommits(ctx, ht.dEnv.DoltDB, head, -1)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &historyRowItr{
+		ctx:     ctx,
+		ht:      ht,
+		commits: commits,
+		pkVals:  ht.pkFilterVals(),
+		convs:   make(map[hash.Hash]*rowconv.RowConverter),
+	}, nil
+}
+
+// HandledFilters returns the filters which compare columns of the history table to literal values.  Each of them is
+// evaluated by the table, and those on the commit columns and primary key are also used to avoid reading rows.
+func (ht *HistoryTable) HandledFilters(filters []sql.Expression) []sql.Expression {
+	var handled []sql.Expression
+	for _, f := range filters {
+		if ht.canHandleFilter(f) {
+			handled = append(handled, f)
+		}
+	}
+
+	return handled
+}
+
+func (ht *HistoryTable) canHandleFilter(f sql.Expression) bool {
+	canHandle := true
+	sql.Inspect(f, func(e sql.Expression) bool {
+		switch e := e.(type) {
+		case nil, *expression.Literal, expression.Tuple, *expression.Equals, *expression.GreaterThan,
+			*expression.GreaterThanOrEqual, *expression.LessThan, *expression.LessThanOrEqual, *expression.In,
+			*expression.And, *expression.Or:
+		case *expression.GetField:
+			canHandle = canHandle && e.Table() == ht.Name()
+		default:
+			canHandle = false
+		}
+
+		return canHandle
+	})
+
+	return canHandle
+}
+
+// WithFilters returns a new HistoryTable which applies the given filters
+func (ht *HistoryTable) WithFilters(filters []sql.Expression) sql.Table {
+	filtered := *ht
+	filtered.commitFilters = nil
+	filtered.rowFilters = nil
+
+	for _, f := range filters {
+		if isCommitFilter(f) {
+			filtered.commitFilters = append(filtered.commitFilters, f)
+		} else {
+			filtered.rowFilters = append(filtered.rowFilters, f)
+		}
+	}
+
+	return &filtered
+}
+
+// Filters returns the filters applied to this table
+func (ht *HistoryTable) Filters() []sql.Expression {
+	return append(append([]sql.Expression{}, ht.commitFilters...), ht.rowFilters...)
+}
+
+func isCommitFilter(f sql.Expression) bool {
+	isCommitFilter := true
+	sql.Inspect(f, func(e sql.Expression) bool {
+		if gf, ok := e.(*expression.GetField); ok {
+			switch gf.Name() {
+			case CommitHashCol, CommitterCol, CommitDateCol:
+			default:
+				isCommitFilter = false
+			}
+		}
+
+		return isCommitFilter
+	})
+
+	return isCommitFilter
+}
+
+// pkFilterVals returns the values of the columns which are compared to a literal with an equality filter.  Rows can be
+// looked up by primary key if all the primary key columns of the table at a commit are in the result.
+func (ht *HistoryTable) pkFilterVals() map[string]interface{} {
+	vals := make(map[string]interface{})
+	for _, f := range ht.rowFilters {
+		eq, ok := f.(*expression.Equals)
+
+		if !ok {
+			continue
+		}
+
+		gf, ok := eq.Left().(*expression.GetField)
+		lit, litOk := eq.Right().(*expression.Literal)
+
+		if !ok || !litOk {
+			gf, ok = eq.Right().(*expression.GetField)
+			lit, litOk = eq.Left().(*expression.Literal)
+		}
+
+		if ok && litOk {
+			vals[gf.Name()] = lit.Value()
+		}
+	}
+
+	return vals
+}
+
+// matches returns whether all the filters evaluate to true for the given row
+func matches(ctx *sql.Context, filters []sql.Expression, r sql.Row) (bool, error) {
+	for _, f := range filters {
+		res, err := f.Eval(ctx, r)
+
+		if err != nil {
+			return false, err
+		}
+
+		if res != true {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+var _ sql.RowIter = (*historyRowItr)(nil)
+
+// historyRowItr iterates over the rows of the table at each commit.
+type historyRowItr struct {
+	ctx     *sql.Context
+	ht      *HistoryTable
+	commits []*doltdb.Commit
+	pkVals  map[string]interface{}
+	convs   map[hash.Hash]*rowconv.RowConverter
+
+	// the state of the commit currently being read
+	commitVals row.TaggedValues
+	sch        schema.Schema
+	conv       *rowconv.RowConverter
+	mapItr     types.MapIterator
+}
+
+// Next returns the next row
+func (itr *historyRowItr) Next() (sql.Row, error) {
+	ssSch := itr.ht.ss.GetSchema()
+	for {
+		if itr.mapItr == nil {
+			if len(itr.commits) == 0 {
+				return nil, io.EOF
+			}
+
+			cm := itr.commits[0]
+			itr.commits = itr.commits[1:]
+
+			if err := itr.startCommit(cm); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		k, v, err := itr.mapItr.Next(itr.ctx)
+
+		if err != nil {
+			return nil, err
+		} else if k == nil {
+			itr.mapItr = nil
+			continue
+		}
+
+		r, err := row.FromNoms(itr.sch, k.(types.Tuple), v.(types.Tuple))
+
+		if err != nil {
+			return nil, err
+		}
+
+		r, err = itr.conv.Convert(r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for tag, val := range itr.commitVals {
+			r, err = r.SetColVal(tag, val, ssSch)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		sqlRow, err := doltRowToSqlRow(r, ssSch)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if ok, err := matches(itr.ctx, itr.ht.rowFilters, sqlRow); err != nil {
+			return nil, err
+		} else if ok {
+			return sqlRow, nil
+		}
+	}
+}
+
+// startCommit prepares to read the rows of the table at the given commit.  If the commit doesn't match the commit
+// filters, or the table doesn't exist at the commit, no rows will be read from it.
+func (itr *historyRowItr) startCommit(cm *doltdb.Commit) error {
+	commitVals, err := itr.commitValues(cm)
+
+	if err != nil {
+		return err
+	}
+
+	if ok, err := itr.commitMatches(commitVals); err != nil || !ok {
+		return err
+	}
+
+	root, err := cm.GetRootValue()
+
+	if err != nil {
+		return err
+	}
+
+	tbl, ok, err := root.GetTable(itr.ctx, itr.ht.name)
+
+	if err != nil || !ok {
+		return err
+	}
+
+	schRef, err := tbl.GetSchemaRef()
+
+	if err != nil {
+		return err
+	}
+
+	sch, err := tbl.GetSchema(itr.ctx)
+
+	if err != nil {
+		return err
+	}
+
+	conv, ok := itr.convs[schRef.TargetHash()]
+
+	if !ok {
+		conv, err = itr.ht.ss.RowConvForSchema(sch)
+
+		if err != nil {
+			return err
+		}
+
+		itr.convs[schRef.TargetHash()] = conv
+	}
+
+	rowData, err := tbl.GetRowData(itr.ctx)
+
+	if err != nil {
+		return err
+	}
+
+	itr.commitVals = commitVals
+	itr.sch = sch
+	itr.conv = conv
+
+	key, ok, err := itr.pkLookupKey(sch, conv)
+
+	if err != nil {
+		return err
+	} else if !ok {
+		itr.mapItr, err = rowData.Iterator(itr.ctx)
+		return err
+	}
+
+	val, ok, err := rowData.MaybeGet(itr.ctx, key)
+
+	if err != nil {
+		return err
+	}
+
+	itr.mapItr = &lookupItr{}
+	if ok {
+		itr.mapItr = &lookupItr{key, val}
+	}
+
+	return nil
+}
+
+// commitValues returns the values of the commit columns for the given commit
+func (itr *historyRowItr) commitValues(cm *doltdb.Commit) (row.TaggedValues, error) {
+	h, err := cm.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := cm.GetCommitMeta()
+
+	if err != nil {
+		return nil, err
+	}
+
+	cols := itr.ht.ss.GetSchema().GetAllCols()
+	hashCol, _ := cols.GetByName(CommitHashCol)
+	committerCol, _ := cols.GetByName(CommitterCol)
+	dateCol, _ := cols.GetByName(CommitDateCol)
+
+	return row.TaggedValues{
+		hashCol.Tag:      types.String(h.String()),
+		committerCol.Tag: types.String(meta.Name),
+		dateCol.Tag:      types.Timestamp(meta.Time()),
+	}, nil
+}
+
+// commitMatches returns whether the commit filters evaluate to true for a commit with the given commit column values
+func (itr *historyRowItr) commitMatches(commitVals row.TaggedValues) (bool, error) {
+	if len(itr.ht.commitFilters) == 0 {
+		return true, nil
+	}
+
+	sqlRow := make(sql.Row, len(itr.ht.sqlSch))
+	i := 0
+	err := itr.ht.ss.GetSchema().GetAllCols().Iter(func(tag uint64, col schema.Column) (stop bool, err error) {
+		if val, ok := commitVals[tag]; ok {
+			sqlRow[i], err = sqlTypes.NomsValToSqlVal(val)
+		}
+
+		i++
+		return err != nil, err
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return matches(itr.ctx, itr.ht.commitFilters, sqlRow)
+}
+
+// pkLookupKey returns the key of the row matching the primary key filters, if there is a filter for every primary key
+// column of the schema.
+func (itr *historyRowItr) pkLookupKey(sch schema.Schema, conv *rowconv.RowConverter) (types.Value, bool, error) {
+	if len(itr.pkVals) == 0 {
+		return nil, false, nil
+	}
+
+	ssCols := itr.ht.ss.GetSchema().GetAllCols()
+	taggedVals := make(row.TaggedValues)
+	for _, tag := range sch.GetPKCols().Tags {
+		ssCol, ok := ssCols.GetByTag(conv.SrcToDest[tag])
+
+		if !ok {
+			return nil, false, nil
+		}
+
+		sqlVal, ok := itr.pkVals[ssCol.Name]
+
+		if !ok {
+			return nil, false, nil
+		}
+
+		col, _ := sch.GetPKCols().GetByTag(tag)
+		val, err := sqlTypes.SqlValToNomsVal(sqlVal, col.Kind)
+
+		if err != nil || types.IsNull(val) {
+			// the value can't be converted to the key's type, so the rows are scanned and filtered instead
+			return nil, false, nil
+		}
+
+		taggedVals[tag] = val
+	}
+
+	key, err := taggedVals.NomsTupleForTags(itr.ht.dEnv.DoltDB.ValueReadWriter().Format(), sch.GetPKCols().Tags, true).Value(itr.ctx)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return key, true, nil
+}
+
+// Close closes the iterator
+func (itr *historyRowItr) Close() error {
+	return nil
+}
+
+var _ types.MapIterator = (*lookupItr)(nil)
+
+// lookupItr is a types.MapIterator over the result of looking up a single key.  It's empty if the key is nil.
+type lookupItr struct {
+	key, val types.Value
+}
+
+func (itr *lookupItr) Next(context.Context) (types.Value, types.Value, error) {
+	k, v := itr.key, itr.val
+	itr.key, itr.val = nil, nil
+	return k, v, nil
+}
+
+func (itr *lookupItr) Prev(context.Context) (types.Value, types.Value, error) {
+	return nil, nil, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/src-d/go-mysql-server/sql/expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	. "github.com/liquidata-inc/dolt/go/libraries/doltcore/sql/sqltestutil"
+)
+
+func TestHistoryTable(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	commitAll(t, dEnv, "add tables")
+
+	_, err := executeQuery(ctx, dEnv, "update people set age = 41 where id = 0")
+	require.NoError(t, err)
+	commitAll(t, dEnv, "update homer")
+
+	// changes to the working set aren't part of the history
+	_, err = executeQuery(ctx, dEnv, "update people set age = 42 where id = 0")
+	require.NoError(t, err)
+
+	head, err := headCommit(ctx, dEnv)
+	require.NoError(t, err)
+	headHash, err := head.HashOf()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedRows []sql.Row
+		expectedErr  string
+	}{
+		{
+			name:         "all versions of a row",
+			query:        "select age from dolt_history_people where id = 0",
+			expectedRows: []sql.Row{{int64(41)}, {int64(40)}},
+		},
+		{
+			name:         "rows at a commit",
+			query:        "select count(*) from dolt_history_people where commit_hash = '" + headHash.String() + "'",
+			expectedRows: []sql.Row{{int64(len(AllPeopleRows))}},
+		},
+		{
+			name:         "row at a commit",
+			query:        "select age, committer from dolt_history_people where id = 0 and commit_hash = '" + headHash.String() + "'",
+			expectedRows: []sql.Row{{int64(41), "billy bob"}},
+		},
+		{
+			name:         "commit date",
+			query:        "select count(*) from dolt_history_people where commit_date > '2000-01-01' and id = 1",
+			expectedRows: []sql.Row{{int64(2)}},
+		},
+		{
+			name:         "commit date before history",
+			query:        "select count(*) from dolt_history_people where commit_date < '2000-01-01'",
+			expectedRows: []sql.Row{{int64(0)}},
+		},
+		{
+			name:         "filter on a non primary key column",
+			query:        "select id from dolt_history_people where age = 41",
+			expectedRows: []sql.Row{{int64(0)}},
+		},
+		{
+			name:         "missing primary key",
+			query:        "select count(*) from dolt_history_people where id = 100",
+			expectedRows: []sql.Row{{int64(0)}},
+		},
+		{
+			name:        "unknown table",
+			query:       "select * from dolt_history_unknown",
+			expectedErr: "table not found: dolt_history_unknown",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := executeQuery(ctx, dEnv, test.query)
+
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedRows, rows)
+			}
+		})
+	}
+}
+
+func TestHistoryTableFilters(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	commitAll(t, dEnv, "add tables")
+
+	ht, err := NewHistoryTable(ctx, PeopleTableName, dEnv)
+	require.NoError(t, err)
+
+	field := func(name string) sql.Expression {
+		idx := ht.Schema().IndexOf(name, ht.Name())
+		require.NotEqual(t, -1, idx)
+		return expression.NewGetFieldWithTable(idx, ht.Schema()[idx].Type, ht.Name(), name, true)
+	}
+
+	idFilter := expression.NewEquals(field("id"), expression.NewLiteral(int64(0), sql.Int64))
+	commitFilter := expression.NewEquals(field(CommitHashCol), expression.NewLiteral("abc", sql.Text))
+	likeFilter := expression.NewLike(field("first"), expression.NewLiteral("H%", sql.Text))
+	otherTableFilter := expression.NewEquals(
+		expression.NewGetFieldWithTable(0, sql.Int64, "other", "id", false),
+		expression.NewLiteral(int64(0), sql.Int64))
+
+	handled := ht.HandledFilters([]sql.Expression{idFilter, commitFilter, likeFilter, otherTableFilter})
+	assert.Equal(t, []sql.Expression{idFilter, commitFilter}, handled)
+
+	filtered := ht.WithFilters(handled).(*HistoryTable)
+	assert.Equal(t, []sql.Expression{commitFilter}, filtered.commitFilters)
+	assert.Equal(t, []sql.Expression{idFilter}, filtered.rowFilters)
+	assert.Equal(t, map[string]interface{}{"id": int64(0)}, filtered.pkFilterVals())
+	assert.Empty(t, ht.Filters())
+}
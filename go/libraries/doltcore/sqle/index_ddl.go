@@ -0,0 +1,252 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+)
+
+// IndexDDL is a CREATE INDEX or DROP INDEX statement parsed by ParseIndexDDL.
+type IndexDDL struct {
+	// Drop is true for DROP INDEX statements, and false for CREATE INDEX statements.
+	Drop bool
+	// Name is the name of the index
+	Name string
+	// Table is the name of the indexed table
+	Table string
+	// Columns are the names of the indexed columns, in index order.  Empty for DROP INDEX statements.
+	Columns []string
+	// Unique is true for CREATE UNIQUE INDEX statements.
+	Unique bool
+}
+
+// ParseIndexDDL parses statements of the forms
+//
+//	CREATE [UNIQUE] INDEX name ON table (column [ASC|DESC], ...)
+//	DROP INDEX name ON table
+//
+// The SQL parser accepts both statements but discards everything but the table name, so they must be recognized before
+// the query is parsed.  ok is false if the query isn't an index statement, and an error is returned if it is one but
+// can't be parsed.
+func ParseIndexDDL(query string) (ddl *IndexDDL, ok bool, err error) {
+	tokens := tokenizeDDL(query)
+
+	ddl = &IndexDDL{}
+	switch {
+	case tokens.accept(sqlparser.CREATE):
+		ddl.Unique = tokens.accept(sqlparser.UNIQUE)
+
+		if !tokens.accept(sqlparser.INDEX) {
+			return nil, false, nil
+		}
+	case tokens.accept(sqlparser.DROP):
+		if !tokens.accept(sqlparser.INDEX) {
+			return nil, false, nil
+		}
+
+		ddl.Drop = true
+	default:
+		return nil, false, nil
+	}
+
+	syntaxErr := fmt.Errorf("invalid index statement: '%s'", query)
+
+	var nameOk, tableOk bool
+	ddl.Name, nameOk = tokens.ident()
+	onOk := tokens.accept(sqlparser.ON)
+	ddl.Table, tableOk = tokens.ident()
+
+	if !nameOk || !onOk || !tableOk {
+		return nil, true, syntaxErr
+	}
+
+	if !ddl.Drop {
+		var colsOk bool
+		ddl.Columns, colsOk = tokens.identList(func() {
+			_ = tokens.accept(sqlparser.ASC) || tokens.accept(sqlparser.DESC)
+		})
+
+		if !colsOk {
+			return nil, true, syntaxErr
+		}
+	}
+
+	if !tokens.done() {
+		return nil, true, syntaxErr
+	}
+
+	return ddl, true, nil
+}
+
+// ExecuteIndexDDL runs a parsed index statement against the database given.
+func ExecuteIndexDDL(ctx context.Context, db *Database, ddl *IndexDDL) error {
+	if ddl.Drop {
+		return db.DropIndex(ctx, ddl.Table, ddl.Name)
+	}
+
+	return db.CreateIndex(ctx, ddl.Table, ddl.Name, ddl.Columns, ddl.Unique)
+}
+
+// CreateIndex adds an index over the columns given to a table, and builds the index data from the table's rows.  A
+// doltdb.UniqueIndexViolationError is returned if the index is unique and the existing rows violate it.
+func (db *Database) CreateIndex(ctx context.Context, tableName, indexName string, columns []string, unique bool) error {
+	tableName, tbl, sch, err := db.getTableForDDL(ctx, tableName)
+
+	if err != nil {
+		return err
+	}
+
+	if _, ok := sch.Indexes().GetByName(indexName); ok {
+		return fmt.Errorf("index '%s' already exists on table '%s'", indexName, tableName)
+	}
+
+	idx := schema.Index{Name: indexName, Unique: unique}
+	for _, colName := range columns {
+		col, ok := sch.GetAllCols().GetByNameCaseInsensitive(colName)
+
+		if !ok {
+			return fmt.Errorf("table '%s' does not have column '%s'", tableName, colName)
+		} else if idx.HasTag(col.Tag) {
+			return fmt.Errorf("column '%s' is used more than once in index '%s'", colName, indexName)
+		}
+
+		idx.Tags = append(idx.Tags, col.Tag)
+	}
+
+	indexes, err := sch.Indexes().Append(idx)
+
+	if err != nil {
+		return err
+	}
+
+	newSch, err := schema.SchemaWithIndexes(sch, indexes)
+
+	if err != nil {
+		return err
+	}
+
+	tbl, err = tbl.UpdateSchema(ctx, newSch)
+
+	if err != nil {
+		return err
+	}
+
+	tbl, err = tbl.RebuildIndexRowData(ctx, idx)
+
+	if err != nil {
+		return err
+	}
+
+	return db.putTableForDDL(ctx, tableName, tbl)
+}
+
+// DropIndex removes an index and its data from a table.
+func (db *Database) DropIndex(ctx context.Context, tableName, indexName string) error {
+	tableName, tbl, sch, err := db.getTableForDDL(ctx, tableName)
+
+	if err != nil {
+		return err
+	}
+
+	idx, ok := sch.Indexes().GetByName(indexName)
+
+	if !ok {
+		return fmt.Errorf("index '%s' does not exist on table '%s'", indexName, tableName)
+	}
+
+	indexes, err := sch.Indexes().Remove(idx.Name)
+
+	if err != nil {
+		return err
+	}
+
+	newSch, err := schema.SchemaWithIndexes(sch, indexes)
+
+	if err != nil {
+		return err
+	}
+
+	tbl, err = tbl.UpdateSchema(ctx, newSch)
+
+	if err != nil {
+		return err
+	}
+
+	tbl, err = tbl.DeleteIndexRowData(ctx, idx.Name)
+
+	if err != nil {
+		return err
+	}
+
+	return db.putTableForDDL(ctx, tableName, tbl)
+}
+
+// getTableForDDL flushes any pending edits and returns the exact name of the table being altered along with the table
+// and its schema.
+func (db *Database) getTableForDDL(ctx context.Context, tableName string) (string, *doltdb.Table, schema.Schema, error) {
+	if err := db.checkWritable(); err != nil {
+		return "", nil, nil, err
+	}
+
+	if err := db.Flush(ctx); err != nil {
+		return "", nil, nil, err
+	}
+
+	tableNames, err := db.root.GetTableNames(ctx)
+
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	exactName, ok := sql.GetTableNameInsensitive(tableName, tableNames)
+
+	if !ok {
+		return "", nil, nil, sql.ErrTableNotFound.New(tableName)
+	}
+
+	tbl, _, err := db.root.GetTable(ctx, exactName)
+
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	sch, err := tbl.GetSchema(ctx)
+
+	if err != nil {
+		return "", nil, nil, err
+	}
+
+	return exactName, tbl, sch, nil
+}
+
+func (db *Database) putTableForDDL(ctx context.Context, tableName string, tbl *doltdb.Table) error {
+	newRoot, err := db.root.PutTable(ctx, tableName, tbl)
+
+	if err != nil {
+		return err
+	}
+
+	delete(db.tables, tableName)
+	db.SetRoot(newRoot)
+
+	return nil
+}
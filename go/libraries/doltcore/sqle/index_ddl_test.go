@@ -0,0 +1,207 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	. "github.com/liquidata-inc/dolt/go/libraries/doltcore/sql/sqltestutil"
+)
+
+func TestParseIndexDDL(t *testing.T) {
+	tests := []struct {
+		query     string
+		expected  *IndexDDL
+		expectErr bool
+	}{
+		{"select * from people", nil, false},
+		{"create table idx (id int primary key)", nil, false},
+		{"drop table people", nil, false},
+		{
+			"create index idx_age on people (age)",
+			&IndexDDL{Name: "idx_age", Table: "people", Columns: []string{"age"}},
+			false,
+		},
+		{
+			"CREATE UNIQUE INDEX `full name` ON `people` (first ASC, last DESC);",
+			&IndexDDL{Name: "full name", Table: "people", Columns: []string{"first", "last"}, Unique: true},
+			false,
+		},
+		{
+			"drop index idx_age on people",
+			&IndexDDL{Drop: true, Name: "idx_age", Table: "people"},
+			false,
+		},
+		{"create index idx_age on people", nil, true},
+		{"create index idx_age on people (age", nil, true},
+		{"create index idx_age on people (age) extra", nil, true},
+		{"drop index idx_age", nil, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			ddl, ok, err := ParseIndexDDL(test.query)
+
+			if test.expectErr {
+				assert.True(t, ok)
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected != nil, ok)
+				assert.Equal(t, test.expected, ddl)
+			}
+		})
+	}
+}
+
+func getPeopleSchema(t *testing.T, dEnv *env.DoltEnv) schema.Schema {
+	root, err := dEnv.WorkingRoot(context.Background())
+	require.NoError(t, err)
+	tbl, ok, err := root.GetTable(context.Background(), PeopleTableName)
+	require.NoError(t, err)
+	require.True(t, ok)
+	sch, err := tbl.GetSchema(context.Background())
+	require.NoError(t, err)
+
+	return sch
+}
+
+func TestCreateAndDropIndex(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+
+	_, err := executeQuery(ctx, dEnv, "create index idx_age on people (age)")
+	require.NoError(t, err)
+	_, err = executeQuery(ctx, dEnv, "create unique index idx_name on PEOPLE (First, last)")
+	require.NoError(t, err)
+
+	assert.Equal(t, []schema.Index{
+		{Name: "idx_age", Tags: []uint64{AgeTag}},
+		{Name: "idx_name", Tags: []uint64{FirstTag, LastTag}, Unique: true},
+	}, getPeopleSchema(t, dEnv).Indexes().GetIndexes())
+
+	for _, query := range []string{
+		"create index IDX_AGE on people (rating)",
+		"create index idx_missing on people (missing)",
+		"create index idx_twice on people (age, age)",
+		"create index idx_age on missing (age)",
+		"create unique index idx_last on people (last)",
+		"drop index idx_missing on people",
+	} {
+		_, err = executeQuery(ctx, dEnv, query)
+		assert.Error(t, err, query)
+	}
+
+	_, err = executeQuery(ctx, dEnv, "drop index idx_age on people")
+	require.NoError(t, err)
+
+	assert.Equal(t, []schema.Index{
+		{Name: "idx_name", Tags: []uint64{FirstTag, LastTag}, Unique: true},
+	}, getPeopleSchema(t, dEnv).Indexes().GetIndexes())
+
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+	tbl, _, err := root.GetTable(ctx, PeopleTableName)
+	require.NoError(t, err)
+	_, err = tbl.GetIndexRowData(ctx, "idx_age")
+	assert.Equal(t, schema.ErrIndexNotFound, err)
+	indexData, err := tbl.GetIndexRowData(ctx, "idx_name")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(AllPeopleRows)), indexData.Len())
+}
+
+func TestUniqueIndexEnforcement(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+
+	_, err := executeQuery(ctx, dEnv, "create unique index idx_name on people (first, last)")
+	require.NoError(t, err)
+
+	_, err = executeQuery(ctx, dEnv, "insert into people (id, first, last) values (10, 'Homer', 'Simpson')")
+	require.Error(t, err)
+	assert.True(t, doltdb.IsUniqueIndexViolation(err))
+
+	_, err = executeQuery(ctx, dEnv, "update people set first = 'Homer' where id = 1")
+	assert.True(t, doltdb.IsUniqueIndexViolation(err))
+
+	// rows which only match in some of the indexed columns, or have null values, don't violate the index
+	for _, query := range []string{
+		"insert into people (id, first, last) values (10, 'Homer', 'Szyslak')",
+		"insert into people (id, first, last, age) values (11, 'Maggie', 'Simpson', 1)",
+		"update people set first = 'Bartholomew' where id = 2",
+		"update people set first = 'Bart' where id = 2",
+	} {
+		_, err = executeQuery(ctx, dEnv, query)
+		assert.NoError(t, err, query)
+	}
+
+	rows, err := executeQuery(ctx, dEnv, "select id from people where first = 'Homer' order by id")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(0)}, {int64(10)}}, rows)
+}
+
+func TestIndexesRebuiltOnMerge(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	commitAll(t, dEnv, "add tables")
+
+	for _, query := range []string{
+		"create index idx_age on people (age)",
+		"select dolt_add('.')",
+		"select dolt_commit('-m', 'add index')",
+		"select dolt_checkout('-b', 'other')",
+		"insert into people (id, first, last, age) values (10, 'Maggie', 'Simpson', 1)",
+		"create index idx_rating on people (rating)",
+		"select dolt_add('.')",
+		"select dolt_commit('-m', 'on other')",
+		"select dolt_checkout('master')",
+		"insert into people (id, first, last, age) values (11, 'Abe', 'Simpson', 83)",
+		"select dolt_add('.')",
+		"select dolt_commit('-m', 'on master')",
+		"select dolt_merge('other')",
+	} {
+		_, err := executeQuery(ctx, dEnv, query)
+		require.NoError(t, err, query)
+	}
+
+	assert.Equal(t, []schema.Index{
+		{Name: "idx_age", Tags: []uint64{AgeTag}},
+		{Name: "idx_rating", Tags: []uint64{RatingTag}},
+	}, getPeopleSchema(t, dEnv).Indexes().GetIndexes())
+
+	rows, err := executeQuery(ctx, dEnv, "select id from people where age < 5 or age > 80 order by id")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(10)}, {int64(11)}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select id from people where age > 80")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(11)}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select id from people where rating >= 9 order by id")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(2)}, {int64(3)}}, rows)
+}
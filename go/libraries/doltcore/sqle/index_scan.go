@@ -0,0 +1,374 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/src-d/go-mysql-server/sql/expression"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
+	sqlTypes "github.com/liquidata-inc/dolt/go/libraries/doltcore/sqle/types"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+var _ sql.FilteredTable = (*DoltTable)(nil)
+var _ sql.FilteredTable = (*filteredDoltTable)(nil)
+
+// HandledFilters implements sql.FilteredTable.  Comparisons between the first column of one of the table's indexes and
+// a literal are handled by scanning the index instead of the whole table.
+func (t *DoltTable) HandledFilters(filters []sql.Expression) []sql.Expression {
+	var handled []sql.Expression
+	for _, f := range filters {
+		if _, ok := t.indexRangeForFilter(f); ok {
+			handled = append(handled, f)
+		}
+	}
+
+	return handled
+}
+
+// WithFilters implements sql.FilteredTable.  The filters given must be ones returned by HandledFilters.
+func (t *DoltTable) WithFilters(filters []sql.Expression) sql.Table {
+	if len(filters) == 0 {
+		return t
+	}
+
+	return &filteredDoltTable{t, filters}
+}
+
+// Filters implements sql.FilteredTable.  A DoltTable has no filters, see WithFilters.
+func (t *DoltTable) Filters() []sql.Expression {
+	return nil
+}
+
+// filteredDoltTable is a DoltTable whose rows are restricted by filters over indexed columns.  Rows are read by
+// scanning the index named by the filters, and the filters are evaluated against every row read, as the engine removes
+// them from the query plan.
+type filteredDoltTable struct {
+	*DoltTable
+	filters []sql.Expression
+}
+
+// Filters implements sql.FilteredTable
+func (t *filteredDoltTable) Filters() []sql.Expression {
+	return t.filters
+}
+
+// WithFilters implements sql.FilteredTable
+func (t *filteredDoltTable) WithFilters(filters []sql.Expression) sql.Table {
+	return t.DoltTable.WithFilters(filters)
+}
+
+func (t *filteredDoltTable) String() string {
+	filterStrs := make([]string, len(t.filters))
+	for i, f := range t.filters {
+		filterStrs[i] = f.String()
+	}
+
+	return fmt.Sprintf("%s(%s)", t.name, strings.Join(filterStrs, " AND "))
+}
+
+// PartitionRows returns the rows of the table matching all of the filters.
+func (t *filteredDoltTable) PartitionRows(ctx *sql.Context, _ sql.Partition) (sql.RowIter, error) {
+	// every filter is on the first column of some index, so the range scanned is the intersection of the ranges of
+	// all the filters on the column of the first one
+	rng, _ := t.indexRangeForFilter(t.filters[0])
+	for _, f := range t.filters[1:] {
+		if other, ok := t.indexRangeForFilter(f); ok && other.idx.Name == rng.idx.Name {
+			rng = rng.intersect(t.table.Format(), other)
+		}
+	}
+
+	rowData, err := t.table.GetRowData(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	indexData, err := t.table.GetIndexRowData(ctx, rng.idx.Name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var itr types.MapIterator
+	if rng.lo != nil {
+		var prefix types.Tuple
+		prefix, err = doltdb.IndexKeyPrefix(t.table.Format(), rng.idx.Tags[:1], []types.Value{rng.lo})
+
+		if err != nil {
+			return nil, err
+		}
+
+		itr, err = indexData.IteratorFrom(ctx, prefix)
+	} else {
+		itr, err = indexData.Iterator(ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &indexScanRowIter{t, ctx, rng, rowData, itr}, nil
+}
+
+// indexRange is a range of values of the first column of an index.  Nil bounds are unbounded.
+type indexRange struct {
+	idx                      schema.Index
+	lo, hi                   types.Value
+	loInclusive, hiInclusive bool
+}
+
+// contains returns whether the value is within the range, and whether it's beyond the upper bound of the range.
+func (rng indexRange) contains(nbf *types.NomsBinFormat, val types.Value) (contains bool, beyond bool, err error) {
+	if rng.hi != nil {
+		if less, err := rng.hi.Less(nbf, val); err != nil {
+			return false, false, err
+		} else if less || (!rng.hiInclusive && rng.hi.Equals(val)) {
+			return false, true, nil
+		}
+	}
+
+	if rng.lo != nil {
+		if less, err := val.Less(nbf, rng.lo); err != nil {
+			return false, false, err
+		} else if less || (!rng.loInclusive && rng.lo.Equals(val)) {
+			return false, false, nil
+		}
+	}
+
+	return true, false, nil
+}
+
+// intersect returns the range of values within both ranges, which must be over the same index.
+func (rng indexRange) intersect(nbf *types.NomsBinFormat, other indexRange) indexRange {
+	if other.lo != nil {
+		if rng.lo == nil {
+			rng.lo, rng.loInclusive = other.lo, other.loInclusive
+		} else if less, err := rng.lo.Less(nbf, other.lo); err == nil && less {
+			rng.lo, rng.loInclusive = other.lo, other.loInclusive
+		} else if rng.lo.Equals(other.lo) {
+			rng.loInclusive = rng.loInclusive && other.loInclusive
+		}
+	}
+
+	if other.hi != nil {
+		if rng.hi == nil {
+			rng.hi, rng.hiInclusive = other.hi, other.hiInclusive
+		} else if less, err := other.hi.Less(nbf, rng.hi); err == nil && less {
+			rng.hi, rng.hiInclusive = other.hi, other.hiInclusive
+		} else if rng.hi.Equals(other.hi) {
+			rng.hiInclusive = rng.hiInclusive && other.hiInclusive
+		}
+	}
+
+	return rng
+}
+
+// indexRangeForFilter returns the range of the first column of an index that contains all rows matching the filter,
+// if the filter is a comparison between such a column and a literal.
+func (t *DoltTable) indexRangeForFilter(filter sql.Expression) (indexRange, bool) {
+	cmp, ok := filter.(expression.Comparer)
+
+	if !ok {
+		return indexRange{}, false
+	}
+
+	field, fieldOk := cmp.Left().(*expression.GetField)
+	lit, litOk := cmp.Right().(*expression.Literal)
+	reversed := false
+
+	if !fieldOk || !litOk {
+		field, fieldOk = cmp.Right().(*expression.GetField)
+		lit, litOk = cmp.Left().(*expression.Literal)
+		reversed = true
+	}
+
+	if !fieldOk || !litOk {
+		return indexRange{}, false
+	}
+
+	col, ok := t.sch.GetAllCols().GetByNameCaseInsensitive(field.Name())
+
+	if !ok {
+		return indexRange{}, false
+	}
+
+	var idx schema.Index
+	found := false
+	for _, currIdx := range t.sch.Indexes().GetIndexes() {
+		if currIdx.Tags[0] == col.Tag {
+			idx, found = currIdx, true
+			break
+		}
+	}
+
+	if !found {
+		return indexRange{}, false
+	}
+
+	litVal, err := lit.Eval(nil, nil)
+
+	if err != nil {
+		return indexRange{}, false
+	}
+
+	val, ok := literalToIndexValue(litVal, col.Kind)
+
+	if !ok {
+		return indexRange{}, false
+	}
+
+	rng := indexRange{idx: idx}
+	switch filter.(type) {
+	case *expression.Equals:
+		rng.lo, rng.hi, rng.loInclusive, rng.hiInclusive = val, val, true, true
+	case *expression.GreaterThan, *expression.GreaterThanOrEqual:
+		_, inclusive := filter.(*expression.GreaterThanOrEqual)
+		if reversed {
+			rng.hi, rng.hiInclusive = val, inclusive
+		} else {
+			rng.lo, rng.loInclusive = val, inclusive
+		}
+	case *expression.LessThan, *expression.LessThanOrEqual:
+		_, inclusive := filter.(*expression.LessThanOrEqual)
+		if reversed {
+			rng.lo, rng.loInclusive = val, inclusive
+		} else {
+			rng.hi, rng.hiInclusive = val, inclusive
+		}
+	default:
+		return indexRange{}, false
+	}
+
+	return rng, true
+}
+
+// literalToIndexValue converts the value of a literal to a value of the kind given, if values of that kind are ordered
+// in the same way by noms and by the engine.  Literals of a different category than the column, such as a string
+// literal compared with a numeric column, are left for the engine to compare.
+func literalToIndexValue(litVal interface{}, kind types.NomsKind) (types.Value, bool) {
+	switch litVal.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		if kind != types.IntKind && kind != types.UintKind && kind != types.FloatKind {
+			return nil, false
+		}
+	case string:
+		if kind != types.StringKind {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	val, err := sqlTypes.SqlValToNomsVal(litVal, kind)
+
+	if err != nil {
+		return nil, false
+	}
+
+	return val, true
+}
+
+// indexScanRowIter returns the rows referenced by the entries of an index within a range of values, which match all
+// the filters of a filteredDoltTable.
+type indexScanRowIter struct {
+	table   *filteredDoltTable
+	ctx     *sql.Context
+	rng     indexRange
+	rowData types.Map
+	itr     types.MapIterator
+}
+
+// Next returns the next row in this row iterator, or an io.EOF error if there aren't any more.
+func (itr *indexScanRowIter) Next() (sql.Row, error) {
+	nbf := itr.table.table.Format()
+	sch := itr.table.sch
+	for {
+		k, _, err := itr.itr.Next(itr.ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if k == nil {
+			return nil, io.EOF
+		}
+
+		idxVals, err := doltdb.IndexKeyValues(itr.rng.idx, k.(types.Tuple))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if contains, beyond, err := itr.rng.contains(nbf, idxVals[0]); err != nil {
+			return nil, err
+		} else if beyond {
+			return nil, io.EOF
+		} else if !contains {
+			continue
+		}
+
+		pk, err := doltdb.IndexKeyToPK(nbf, itr.rng.idx, k.(types.Tuple))
+
+		if err != nil {
+			return nil, err
+		}
+
+		val, ok, err := itr.rowData.MaybeGet(itr.ctx, pk)
+
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("index '%s' of table '%s' refers to a missing row", itr.rng.idx.Name, itr.table.name)
+		}
+
+		doltRow, err := row.FromNoms(sch, pk, val.(types.Tuple))
+
+		if err != nil {
+			return nil, err
+		}
+
+		sqlRow, err := doltRowToSqlRow(doltRow, sch)
+
+		if err != nil {
+			return nil, err
+		}
+
+		matches := true
+		for _, f := range itr.table.filters {
+			if matches, err = sql.EvaluateCondition(itr.ctx, f, sqlRow); err != nil {
+				return nil, err
+			} else if !matches {
+				break
+			}
+		}
+
+		if matches {
+			return sqlRow, nil
+		}
+	}
+}
+
+// Close required by sql.RowIter interface
+func (itr *indexScanRowIter) Close() error {
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/src-d/go-mysql-server/sql/expression"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	. "github.com/liquidata-inc/dolt/go/libraries/doltcore/sql/sqltestutil"
+)
+
+func TestIndexScans(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+
+	_, err := executeQuery(ctx, dEnv, "create index idx_age on people (age)")
+	require.NoError(t, err)
+	_, err = executeQuery(ctx, dEnv, "create index idx_name on people (last, first)")
+	require.NoError(t, err)
+
+	// Each indexed query is compared against the same query written so that no index can be used
+	tests := []struct {
+		indexed  string
+		baseline string
+	}{
+		{"age = 40", "age + 0 = 40"},
+		{"age > 35", "age + 0 > 35"},
+		{"age >= 38", "age + 0 >= 38"},
+		{"age < 38", "age + 0 < 38"},
+		{"age <= 10", "age + 0 <= 10"},
+		{"40 >= age", "40 >= age + 0"},
+		{"age > 9 and age < 40", "age + 0 > 9 and age + 0 < 40"},
+		{"age > 9 and age < 40 and first = 'Bart'", "age + 0 > 9 and age + 0 < 40 and first = 'Bart'"},
+		{"age > 50", "age + 0 > 50"},
+		{"age > 40 and age < 10", "age + 0 > 40 and age + 0 < 10"},
+		{"last = 'Simpson'", "concat(last, '') = 'Simpson'"},
+		{"last = 'Simpson' and first = 'Lisa'", "concat(last, '') = 'Simpson' and first = 'Lisa'"},
+		{"last >= 'S'", "concat(last, '') >= 'S'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.indexed, func(t *testing.T) {
+			expected, err := executeQuery(ctx, dEnv, "select id, first, age from people where "+test.baseline+" order by id")
+			require.NoError(t, err)
+			actual, err := executeQuery(ctx, dEnv, "select id, first, age from people where "+test.indexed+" order by id")
+			require.NoError(t, err)
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	// index data must follow writes to the table
+	for _, query := range []string{
+		"insert into people (id, first, last, age) values (10, 'Maggie', 'Simpson', 1)",
+		"update people set age = 41 where id = 0",
+		"delete from people where id = 4",
+	} {
+		_, err = executeQuery(ctx, dEnv, query)
+		require.NoError(t, err, query)
+	}
+
+	rows, err := executeQuery(ctx, dEnv, "select id from people where age < 5 or age > 40 order by id")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(0)}, {int64(10)}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select id from people where age > 40")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(0)}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select id from people where age = 40")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(5)}}, rows)
+}
+
+func TestHandledFilters(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+
+	_, err := executeQuery(ctx, dEnv, "create index idx_name on people (last, first)")
+	require.NoError(t, err)
+
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+	db := NewDatabase("dolt", root, dEnv)
+	tbl, ok, err := db.GetTableInsensitive(sql.NewContext(ctx), PeopleTableName)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	last := expression.NewGetField(int(LastTag), sql.Text, "last", false)
+	first := expression.NewGetField(int(FirstTag), sql.Text, "first", false)
+	age := expression.NewGetField(int(AgeTag), sql.Int64, "age", false)
+
+	onLast := expression.NewEquals(last, expression.NewLiteral("Simpson", sql.Text))
+	lastReversed := expression.NewGreaterThan(expression.NewLiteral("S", sql.Text), last)
+	onFirst := expression.NewEquals(first, expression.NewLiteral("Lisa", sql.Text))
+	onAge := expression.NewEquals(age, expression.NewLiteral(int64(40), sql.Int64))
+	wrongType := expression.NewEquals(last, expression.NewLiteral(int64(40), sql.Int64))
+
+	filtered := tbl.(sql.FilteredTable)
+	handled := filtered.HandledFilters([]sql.Expression{onLast, lastReversed, onFirst, onAge, wrongType})
+	assert.Equal(t, []sql.Expression{onLast, lastReversed}, handled)
+}
@@ -27,7 +27,8 @@ import (
 	"github.com/liquidata-inc/dolt/go/store/types"
 )
 
-// IndexDriver implementation. Not ready for prime time.
+// IndexDriver implementation for primary key lookups. Not ready for prime time. Secondary indexes are used through
+// DoltTable's implementation of sql.FilteredTable instead.
 
 type DoltIndexDriver struct {
 	db *Database
@@ -41,16 +42,20 @@ func (*DoltIndexDriver) ID() string {
 	return "doltDbIndexDriver"
 }
 
+// errIndexDriverDDL is returned by the driver methods that manage indexes.  Dolt indexes are stored with the table
+// rather than by the driver, and are managed with CREATE INDEX and DROP INDEX statements, see ParseIndexDDL.
+var errIndexDriverDDL = errors.New("indexes are not managed by the index driver, use CREATE INDEX and DROP INDEX")
+
 func (*DoltIndexDriver) Create(db, table, id string, expressions []sql.Expression, config map[string]string) (sql.Index, error) {
-	panic("creating indexes not supported")
+	return nil, errIndexDriverDDL
 }
 
 func (i *DoltIndexDriver) Save(*sql.Context, sql.Index, sql.PartitionIndexKeyValueIter) error {
-	panic("saving indexes not supported")
+	return errIndexDriverDDL
 }
 
 func (i *DoltIndexDriver) Delete(sql.Index, sql.PartitionIter) error {
-	panic("deleting indexes not supported")
+	return errIndexDriverDDL
 }
 
 func (i *DoltIndexDriver) LoadAll(db, table string) ([]sql.Index, error) {
@@ -0,0 +1,35 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import "vitess.io/vitess/go/vt/sqlparser"
+
+// IsReadOnlyQuery returns true if the query is a statement which only reads from databases, so that it can be run at
+// the same time as other read-only statements.  Queries which can't be parsed, and statements calling version control
+// functions, which change the working set of the repository, aren't read-only.
+func IsReadOnlyQuery(query string) bool {
+	stmt, err := sqlparser.Parse(query)
+
+	if err != nil {
+		return false
+	}
+
+	switch stmt.(type) {
+	case *sqlparser.Select, *sqlparser.Union, *sqlparser.Show, *sqlparser.OtherRead:
+		return !callsVCFunc(stmt)
+	}
+
+	return false
+}
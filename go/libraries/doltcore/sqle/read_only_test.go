@@ -0,0 +1,51 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		readOnly bool
+	}{
+		{"select * from people", true},
+		{"SELECT count(*) FROM people WHERE age > 30", true},
+		{"select 1 union select 2", true},
+		{"show tables", true},
+		{"describe people", true},
+		{"explain select * from people", true},
+		{"insert into people (id) values (1)", false},
+		{"update people set age = 1", false},
+		{"delete from people", false},
+		{"create table t (pk bigint primary key)", false},
+		{"use db1", false},
+		{"set autocommit = 0", false},
+		{"begin", false},
+		{"select dolt_commit('-m', 'message')", false},
+		{"select * from people where name = DOLT_ADD('.')", false},
+		{"not a statement", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			assert.Equal(t, test.readOnly, IsReadOnlyQuery(test.query))
+		})
+	}
+}
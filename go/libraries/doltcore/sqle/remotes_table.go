@@ -0,0 +1,106 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/src-d/go-mysql-server/sql"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+)
+
+const (
+	// RemotesTableName is the system table name
+	RemotesTableName = "dolt_remotes"
+)
+
+var _ sql.Table = (*RemotesTable)(nil)
+
+// RemotesTable is a sql.Table implementation that implements a system table which shows the remotes configured for
+// the repository
+type RemotesTable struct {
+	dEnv *env.DoltEnv
+}
+
+// NewRemotesTable creates a RemotesTable
+func NewRemotesTable(dEnv *env.DoltEnv) *RemotesTable {
+	return &RemotesTable{dEnv: dEnv}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// RemotesTableName
+func (rt *RemotesTable) Name() string {
+	return RemotesTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// RemotesTableName
+func (rt *RemotesTable) String() string {
+	return RemotesTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the remotes system table.  The fetch specs and
+// params of each remote are JSON encoded.
+func (rt *RemotesTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "name", Type: sql.Text, Source: RemotesTableName, PrimaryKey: true},
+		{Name: "url", Type: sql.Text, Source: RemotesTableName, PrimaryKey: false},
+		{Name: "fetch_specs", Type: sql.Text, Source: RemotesTableName, PrimaryKey: false},
+		{Name: "params", Type: sql.Text, Source: RemotesTableName, PrimaryKey: false},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (rt *RemotesTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &doltTablePartitionIter{}, nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (rt *RemotesTable) PartitionRows(sqlCtx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	remotes, err := rt.dEnv.GetRemotes()
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(remotes))
+	for name := range remotes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	rows := make([]sql.Row, 0, len(remotes))
+	for _, name := range names {
+		remote := remotes[name]
+		fetchSpecs, err := json.Marshal(remote.FetchSpecs)
+
+		if err != nil {
+			return nil, err
+		}
+
+		params, err := json.Marshal(remote.Params)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, sql.NewRow(remote.Name, remote.Url, string(fetchSpecs), string(params)))
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
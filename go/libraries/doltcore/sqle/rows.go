@@ -101,7 +101,8 @@ func doltRowToSqlRow(doltRow row.Row, sch schema.Schema) (sql.Row, error) {
 	return sql.NewRow(colVals...), nil
 }
 
-// Returns a Dolt row representation for SQL row given
+// Returns a Dolt row representation for SQL row given. Auto increment columns may be nil, as their values are generated
+// when the row is inserted.
 func SqlRowToDoltRow(nbf *types.NomsBinFormat, r sql.Row, doltSchema schema.Schema) (row.Row, error) {
 	taggedVals := make(row.TaggedValues)
 	allCols := doltSchema.GetAllCols()
@@ -114,7 +115,7 @@ func SqlRowToDoltRow(nbf *types.NomsBinFormat, r sql.Row, doltSchema schema.Sche
 			if err != nil {
 				return nil, err
 			}
-		} else if !schCol.IsNullable() {
+		} else if !schCol.IsNullable() && !schCol.AutoIncrement {
 			return nil, fmt.Errorf("column <%v> received nil but is non-nullable", schCol.Name)
 		}
 	}
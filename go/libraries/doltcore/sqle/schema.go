@@ -90,11 +90,24 @@ func doltColToSqlCol(tableName string, col schema.Column) (*sql.Column, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	defaultVal, err := col.DefaultValue()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDefault, err := types.NomsValToSqlVal(defaultVal)
+	if err != nil {
+		return nil, err
+	}
+
+	// Auto increment columns are reported as nullable so that inserts can leave them out. The table editor generates
+	// their values.
 	return &sql.Column{
 		Name:     col.Name,
 		Type:     colType,
-		Default:  nil,
-		Nullable: col.IsNullable(),
+		Default:  sqlDefault,
+		Nullable: col.IsNullable() || col.AutoIncrement,
 		Source:   tableName,
 	}, nil
 }
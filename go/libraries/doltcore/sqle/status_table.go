@@ -0,0 +1,128 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"github.com/src-d/go-mysql-server/sql"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/set"
+)
+
+const (
+	// StatusTableName is the system table name
+	StatusTableName = "dolt_status"
+
+	// conflictStatus is the status of tables in the working set which have conflicts
+	conflictStatus = "conflict"
+)
+
+var tableDiffTypeToStatus = map[actions.TableDiffType]string{
+	actions.AddedTable:    "new table",
+	actions.ModifiedTable: "modified",
+	actions.RemovedTable:  "deleted",
+}
+
+var _ sql.Table = (*StatusTable)(nil)
+
+// StatusTable is a sql.Table implementation that implements a system table which shows the tables with changes that
+// are staged to be committed, changes in the working set that aren't staged, and conflicts, as dolt status does.
+type StatusTable struct {
+	db *Database
+}
+
+// NewStatusTable creates a StatusTable for the working root of the database given
+func NewStatusTable(db *Database) *StatusTable {
+	return &StatusTable{db: db}
+}
+
+// Name is a sql.Table interface function which returns the name of the table which is defined by the constant
+// StatusTableName
+func (st *StatusTable) Name() string {
+	return StatusTableName
+}
+
+// String is a sql.Table interface function which returns the name of the table which is defined by the constant
+// StatusTableName
+func (st *StatusTable) String() string {
+	return StatusTableName
+}
+
+// Schema is a sql.Table interface function that gets the sql.Schema of the status system table.
+func (st *StatusTable) Schema() sql.Schema {
+	return []*sql.Column{
+		{Name: "table_name", Type: sql.Text, Source: StatusTableName, PrimaryKey: true},
+		{Name: "staged", Type: sql.Boolean, Source: StatusTableName, PrimaryKey: true},
+		{Name: "status", Type: sql.Text, Source: StatusTableName, PrimaryKey: false},
+	}
+}
+
+// Partitions is a sql.Table interface function that returns a partition of the data.  Currently the data is unpartitioned.
+func (st *StatusTable) Partitions(*sql.Context) (sql.PartitionIter, error) {
+	return &doltTablePartitionIter{}, nil
+}
+
+// PartitionRows is a sql.Table interface function that gets a row iterator for a partition
+func (st *StatusTable) PartitionRows(sqlCtx *sql.Context, part sql.Partition) (sql.RowIter, error) {
+	dEnv := st.db.dEnv
+	headRoot, err := dEnv.HeadRoot(sqlCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stagedRoot, err := dEnv.StagedRoot(sqlCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	workingRoot := st.db.root
+	staged, err := actions.NewTableDiffs(sqlCtx, stagedRoot, headRoot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	notStaged, err := actions.NewTableDiffs(sqlCtx, workingRoot, stagedRoot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	inConflict, err := workingRoot.TablesInConflict(sqlCtx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []sql.Row
+	for _, tblName := range staged.Tables {
+		rows = append(rows, sql.NewRow(tblName, true, tableDiffTypeToStatus[staged.TableToType[tblName]]))
+	}
+
+	conflictSet := set.NewStrSet(inConflict)
+	for _, tblName := range notStaged.Tables {
+		if !conflictSet.Contains(tblName) {
+			rows = append(rows, sql.NewRow(tblName, false, tableDiffTypeToStatus[notStaged.TableToType[tblName]]))
+		}
+	}
+
+	for _, tblName := range inConflict {
+		rows = append(rows, sql.NewRow(tblName, false, conflictStatus))
+	}
+
+	return sql.RowsToRowIter(rows...), nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	. "github.com/liquidata-inc/dolt/go/libraries/doltcore/sql/sqltestutil"
+)
+
+func TestBranchesAndRemotesTables(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	commitAll(t, dEnv, "add tables")
+
+	_, err := executeQuery(ctx, dEnv, "select dolt_branch('other')")
+	require.NoError(t, err)
+
+	dEnv.RepoState.AddRemote(env.NewRemote("origin", "localhost/org/repo", map[string]string{}))
+	require.NoError(t, dEnv.RepoState.Save())
+
+	rows, err := executeQuery(ctx, dEnv, "select name, latest_committer, latest_commit_message from dolt_branches order by name")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{
+		{"master", "billy bob", "add tables"},
+		{"other", "billy bob", "add tables"},
+	}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select count(*) from dolt_branches b join dolt_log l on b.hash = l.commit_hash")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(2)}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select * from dolt_remotes")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{
+		{"origin", "localhost/org/repo", `["refs/heads/*:refs/remotes/origin/*","refs/tags/*:refs/tags/*"]`, "{}"},
+	}, rows)
+}
+
+func TestStatusTable(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	commitAll(t, dEnv, "add tables")
+
+	for _, query := range []string{
+		"update people set age = 41 where id = 0",
+		"select dolt_add('people')",
+		"delete from episodes where id = 1",
+		"create table new_table (id bigint primary key comment 'tag:100')",
+	} {
+		_, err := executeQuery(ctx, dEnv, query)
+		require.NoError(t, err, query)
+	}
+
+	rows, err := executeQuery(ctx, dEnv, "select * from dolt_status order by table_name")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{
+		{"episodes", false, "modified"},
+		{"new_table", false, "new table"},
+		{"people", true, "modified"},
+	}, rows)
+}
+
+func TestConflictsTables(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	commitAll(t, dEnv, "add tables")
+
+	for _, query := range []string{
+		"select dolt_checkout('-b', 'other')",
+		"update people set age = 41 where id in (0, 1)",
+		"select dolt_add('people')",
+		"select dolt_commit('-m', 'on other')",
+		"select dolt_checkout('master')",
+		"update people set age = 42 where id in (0, 1)",
+		"select dolt_add('people')",
+		"select dolt_commit('-m', 'on master')",
+		"select dolt_merge('other')",
+	} {
+		_, err := executeQuery(ctx, dEnv, query)
+		require.NoError(t, err, query)
+	}
+
+	rows, err := executeQuery(ctx, dEnv, "select * from dolt_conflicts")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{"people", uint64(2)}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select base_id, base_age, our_age, their_age from dolt_conflicts_people order by base_id")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{
+		{int64(0), int64(40), int64(42), int64(41)},
+		{int64(1), int64(38), int64(42), int64(41)},
+	}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select table_name, status from dolt_status where staged = false")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{"people", "conflict"}}, rows)
+
+	_, err = executeQuery(ctx, dEnv, "delete from dolt_conflicts_people where our_id = 0")
+	require.NoError(t, err)
+
+	rows, err = executeQuery(ctx, dEnv, "select * from dolt_conflicts")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{"people", uint64(1)}}, rows)
+
+	_, err = executeQuery(ctx, dEnv, "delete from dolt_conflicts_people")
+	require.NoError(t, err)
+
+	rows, err = executeQuery(ctx, dEnv, "select count(*) from dolt_conflicts")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(0)}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select count(*) from dolt_conflicts_people")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(0)}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select age from people where id = 0")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(42)}}, rows)
+
+	_, err = executeQuery(ctx, dEnv, "select * from dolt_conflicts_unknown")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "table not found: dolt_conflicts_unknown")
+}
@@ -21,6 +21,8 @@ import (
 	"github.com/src-d/go-mysql-server/sql"
 
 	"github.com/liquidata-inc/dolt/go/cmd/dolt/errhand"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/row"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/schema"
 	"github.com/liquidata-inc/dolt/go/store/hash"
 	"github.com/liquidata-inc/dolt/go/store/types"
 )
@@ -38,6 +40,8 @@ type tableEditor struct {
 	insertedKeys map[hash.Hash]types.Value
 	addedKeys    map[hash.Hash]types.Value
 	removedKeys  map[hash.Hash]types.Value
+	// nextAutoInc is the next value to generate for the table's auto increment column, or 0 if it hasn't been loaded
+	nextAutoInc uint64
 }
 
 var _ sql.RowReplacer = (*tableEditor)(nil)
@@ -55,11 +59,20 @@ func newTableEditor(t *DoltTable) *tableEditor {
 }
 
 func (te *tableEditor) Insert(ctx *sql.Context, sqlRow sql.Row) error {
+	if err := te.t.db.checkWritable(); err != nil {
+		return err
+	}
+
 	dRow, err := SqlRowToDoltRow(te.t.table.Format(), sqlRow, te.t.sch)
 	if err != nil {
 		return err
 	}
 
+	dRow, err = te.setAutoIncrementValue(ctx, dRow)
+	if err != nil {
+		return err
+	}
+
 	key, err := dRow.NomsMapKey(te.t.sch).Value(ctx)
 	if err != nil {
 		return errhand.BuildDError("failed to get row key").AddCause(err).Build()
@@ -93,7 +106,50 @@ func (te *tableEditor) Insert(ctx *sql.Context, sqlRow sql.Row) error {
 	return nil
 }
 
+// setAutoIncrementValue gives the row inserted the next value of the table's auto increment column if it doesn't have
+// one.  Values given explicitly move the counter past them.
+func (te *tableEditor) setAutoIncrementValue(ctx context.Context, dRow row.Row) (row.Row, error) {
+	col, ok := schema.AutoIncrementCol(te.t.sch)
+	if !ok {
+		return dRow, nil
+	}
+
+	if te.nextAutoInc == 0 {
+		next, err := te.t.table.NextAutoIncrementValue(ctx, te.t.sch)
+		if err != nil {
+			return nil, err
+		}
+		te.nextAutoInc = next
+	}
+
+	val, _ := dRow.GetColVal(col.Tag)
+	switch val := val.(type) {
+	case types.Int:
+		if val > 0 && uint64(val) >= te.nextAutoInc {
+			te.nextAutoInc = uint64(val) + 1
+		}
+		return dRow, nil
+	case types.Uint:
+		if uint64(val) >= te.nextAutoInc {
+			te.nextAutoInc = uint64(val) + 1
+		}
+		return dRow, nil
+	}
+
+	var generated types.Value = types.Uint(te.nextAutoInc)
+	if col.Kind == types.IntKind {
+		generated = types.Int(te.nextAutoInc)
+	}
+	te.nextAutoInc++
+
+	return dRow.SetColVal(col.Tag, generated, te.t.sch)
+}
+
 func (te *tableEditor) Delete(ctx *sql.Context, sqlRow sql.Row) error {
+	if err := te.t.db.checkWritable(); err != nil {
+		return err
+	}
+
 	dRow, err := SqlRowToDoltRow(te.t.table.Format(), sqlRow, te.t.sch)
 	if err != nil {
 		return err
@@ -132,6 +188,10 @@ func (t *DoltTable) newMapEditor(ctx context.Context) (*types.MapEditor, error)
 }
 
 func (te *tableEditor) Update(ctx *sql.Context, oldRow sql.Row, newRow sql.Row) error {
+	if err := te.t.db.checkWritable(); err != nil {
+		return err
+	}
+
 	dOldRow, err := SqlRowToDoltRow(te.t.table.Format(), oldRow, te.t.sch)
 	if err != nil {
 		return err
@@ -141,6 +201,12 @@ func (te *tableEditor) Update(ctx *sql.Context, oldRow sql.Row, newRow sql.Row)
 		return err
 	}
 
+	if col, ok := schema.AutoIncrementCol(te.t.sch); ok {
+		if _, ok := dNewRow.GetColVal(col.Tag); !ok {
+			return fmt.Errorf("column <%v> received nil but is non-nullable", col.Name)
+		}
+	}
+
 	// If the PK is changed then we need to delete the old value and insert the new one
 	dOldKey := dOldRow.NomsMapKey(te.t.sch)
 	dOldKeyVal, err := dOldKey.Value(ctx)
@@ -218,6 +284,14 @@ func (te *tableEditor) flush(ctx context.Context) error {
 	}
 
 	if te.ed != nil {
+		if te.nextAutoInc > 0 {
+			tbl, err := te.t.table.SetAutoIncrementValue(te.nextAutoInc)
+			if err != nil {
+				return err
+			}
+			te.t.table = tbl
+		}
+
 		return te.t.updateTable(ctx, te.ed)
 	}
 	return nil
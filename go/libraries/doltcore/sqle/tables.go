@@ -189,7 +189,9 @@ func (t *DoltTable) updateTable(ctx context.Context, mapEditor *types.MapEditor)
 	}
 
 	newTable, err := t.table.UpdateRows(ctx, updated)
-	if err != nil {
+	if doltdb.IsUniqueIndexViolation(err) {
+		return err
+	} else if err != nil {
 		return errhand.BuildDError("failed to update rows").AddCause(err).Build()
 	}
 
@@ -198,6 +200,13 @@ func (t *DoltTable) updateTable(ctx context.Context, mapEditor *types.MapEditor)
 		return errhand.BuildDError("failed to write table back to database").AddCause(err).Build()
 	}
 
+	err = newRoot.CheckForeignKeys(ctx, t.name, t.table)
+	if doltdb.IsForeignKeyViolation(err) {
+		return err
+	} else if err != nil {
+		return errhand.BuildDError("failed to check foreign keys").AddCause(err).Build()
+	}
+
 	t.table = newTable
 	t.db.root = newRoot
 	return nil
@@ -0,0 +1,476 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+)
+
+// TransactionStatementType is the type of a statement which controls the transactions of a session.
+type TransactionStatementType int
+
+const (
+	// BeginTransaction is a BEGIN or START TRANSACTION statement
+	BeginTransaction TransactionStatementType = iota
+	// CommitTransaction is a COMMIT statement
+	CommitTransaction
+	// RollbackTransaction is a ROLLBACK statement
+	RollbackTransaction
+	// SetAutocommit is a SET statement which only sets the autocommit variable
+	SetAutocommit
+)
+
+// TransactionStatement is a parsed statement which controls the transactions of a session.
+type TransactionStatement struct {
+	Type TransactionStatementType
+	// Autocommit is the value autocommit is set to by a SetAutocommit statement
+	Autocommit bool
+}
+
+// ParseTransactionStatement parses BEGIN, START TRANSACTION, COMMIT and ROLLBACK statements, and SET statements which
+// set autocommit, such as SET autocommit = 0 or SET @@session.autocommit = ON.  ok is false if the query isn't one of
+// these statements.  SET statements which set other variables along with autocommit are left to the engine.
+func ParseTransactionStatement(query string) (stmt TransactionStatement, ok bool, err error) {
+	parsed, err := sqlparser.Parse(query)
+
+	if err != nil {
+		return TransactionStatement{}, false, nil
+	}
+
+	switch parsed := parsed.(type) {
+	case *sqlparser.Begin:
+		return TransactionStatement{Type: BeginTransaction}, true, nil
+	case *sqlparser.Commit:
+		return TransactionStatement{Type: CommitTransaction}, true, nil
+	case *sqlparser.Rollback:
+		return TransactionStatement{Type: RollbackTransaction}, true, nil
+	case *sqlparser.Set:
+		if len(parsed.Exprs) != 1 || parsed.Scope == sqlparser.GlobalStr || !isAutocommitVar(parsed.Exprs[0].Name.String()) {
+			return TransactionStatement{}, false, nil
+		}
+
+		autocommit, err := parseAutocommitValue(parsed.Exprs[0].Expr)
+
+		if err != nil {
+			return TransactionStatement{}, true, err
+		}
+
+		return TransactionStatement{Type: SetAutocommit, Autocommit: autocommit}, true, nil
+	}
+
+	return TransactionStatement{}, false, nil
+}
+
+func isAutocommitVar(name string) bool {
+	name = strings.ToLower(name)
+	return name == "autocommit" || name == "@@autocommit" || name == "@@session.autocommit"
+}
+
+func parseAutocommitValue(expr sqlparser.Expr) (bool, error) {
+	switch expr := expr.(type) {
+	case sqlparser.BoolVal:
+		return bool(expr), nil
+	case *sqlparser.SQLVal:
+		switch strings.ToLower(string(expr.Val)) {
+		case "1", "on":
+			return true, nil
+		case "0", "off":
+			return false, nil
+		}
+	}
+
+	return false, fmt.Errorf("variable 'autocommit' can't be set to the value of '%s'", sqlparser.String(expr))
+}
+
+// ImplicitlyCommits returns true if the query is a statement which, as in MySQL, commits the current transaction
+// before it runs and is never part of a transaction.  These are data definition statements, and statements which call
+// version control functions, which operate on the working set of the repository directly.
+func ImplicitlyCommits(query string) bool {
+	if _, ok, _ := ParseIndexDDL(query); ok {
+		return true
+	}
+
+	if _, ok, _ := ParseForeignKeyDDL(query); ok {
+		return true
+	}
+
+	stmt, err := sqlparser.Parse(query)
+
+	if err != nil {
+		return false
+	}
+
+	if _, ok := stmt.(*sqlparser.DDL); ok {
+		return true
+	}
+
+	return callsVCFunc(stmt)
+}
+
+// TransactionConflictError is returned when the changes made by a transaction can't be merged with the changes
+// committed by other sessions since the transaction began.
+type TransactionConflictError struct {
+	// Tables are the names of the tables with conflicting changes
+	Tables []string
+}
+
+func (e TransactionConflictError) Error() string {
+	return fmt.Sprintf("transaction conflicts with changes made by another session to table(s) %s and was rolled back", strings.Join(e.Tables, ", "))
+}
+
+// IsTransactionConflict returns true if the error is a TransactionConflictError
+func IsTransactionConflict(err error) bool {
+	_, ok := err.(TransactionConflictError)
+	return ok
+}
+
+// MergeTransaction merges the changes a transaction made between startRoot and root into working, the root the
+// changes are being committed to.  If working is still startRoot, root is returned as is, and if root is unchanged or
+// already matches working, working is returned.  Otherwise the changes are
+// merged the same way branches are, and a TransactionConflictError is returned if any table has conflicts.
+func MergeTransaction(ctx context.Context, ddb *doltdb.DoltDB, working, startRoot, root *doltdb.RootValue) (*doltdb.RootValue, error) {
+	if eq, err := rootsEqual(working, startRoot); err != nil {
+		return nil, err
+	} else if eq {
+		return root, nil
+	}
+
+	for _, unchanged := range []*doltdb.RootValue{startRoot, working} {
+		if eq, err := rootsEqual(root, unchanged); err != nil {
+			return nil, err
+		} else if eq {
+			return working, nil
+		}
+	}
+
+	merged, tblToStats, err := actions.MergeRoots(ctx, ddb, working, root, startRoot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicted []string
+	for tblName, stats := range tblToStats {
+		if stats.Conflicts > 0 {
+			conflicted = append(conflicted, tblName)
+		}
+	}
+
+	if len(conflicted) > 0 {
+		sort.Strings(conflicted)
+		return nil, TransactionConflictError{conflicted}
+	}
+
+	return merged, nil
+}
+
+func rootsEqual(root, other *doltdb.RootValue) (bool, error) {
+	if root == other {
+		return true, nil
+	}
+
+	h, err := root.HashOf()
+
+	if err != nil {
+		return false, err
+	}
+
+	otherH, err := other.HashOf()
+
+	if err != nil {
+		return false, err
+	}
+
+	return h == otherH, nil
+}
+
+// Session tracks the transaction state of a client of a set of databases.  When autocommit is on, which is the
+// default, each statement run outside of an explicit transaction is applied to the databases as soon as it succeeds.
+// BEGIN starts a transaction, which snapshots the roots of the databases.  The statements of the transaction are run
+// against the snapshot, and COMMIT merges the changes they made into the current roots of the databases, which may
+// have been changed by other sessions in the meantime.  ROLLBACK discards them.  When autocommit is off, a transaction
+// is started by the first statement run outside of one.
+//
+// If the databases are shared with other sessions, the roots of a transaction are only installed in the databases
+// while the session runs a statement.  Otherwise they stay installed for the duration of the transaction.  A session
+// sharing its databases also has its own head and working set for any database in which it checks out a branch other
+// than the repository's, which are installed the same way.
+type Session struct {
+	autocommit  bool
+	shared      bool
+	tx          *transaction
+	workingSets map[*Database]*sessionWorkingSet
+}
+
+// transaction is a transaction which hasn't been committed or rolled back.
+type transaction struct {
+	dbs []*Database
+	// startRoots are the roots of the databases when the transaction began, the base of the merge when the transaction
+	// is committed.
+	startRoots []*doltdb.RootValue
+	// roots are the roots holding the changes made by the transaction while its roots aren't installed
+	roots []*doltdb.RootValue
+	// outerRoots are the roots the databases had outside of the transaction while its roots are installed
+	outerRoots []*doltdb.RootValue
+	installed  bool
+}
+
+// NewSession returns a new session with autocommit on.  shared should be true if the databases the session runs
+// statements against are used by other sessions as well.
+func NewSession(shared bool) *Session {
+	return &Session{autocommit: true, shared: shared, workingSets: make(map[*Database]*sessionWorkingSet)}
+}
+
+// Autocommit returns true if autocommit is on.
+func (s *Session) Autocommit() bool {
+	return s.autocommit
+}
+
+// UsesSharedRoots returns true if a statement the session runs sees the same roots as other sessions, so that a
+// read-only statement can be run without Exec.  This is the case when the session has no transaction and won't start
+// one, and hasn't checked out a branch of its own in any database.
+func (s *Session) UsesSharedRoots() bool {
+	if s.tx != nil || !s.autocommit {
+		return false
+	}
+
+	for _, ws := range s.workingSets {
+		if ws.dEnv != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// InTransaction returns true if the session has a transaction which hasn't been committed or rolled back.
+func (s *Session) InTransaction() bool {
+	return s.tx != nil
+}
+
+// Exec runs a statement in the session.  Transaction statements are run by the session itself, and any other
+// statement is run by calling exec with the roots of the session's transaction, if it has one, installed in the
+// databases.  If exec fails, the changes made by the statement are discarded.  Statements which implicitly commit
+// commit the current transaction first, and are run outside of any transaction.
+func (s *Session) Exec(ctx context.Context, dbs []*Database, query string, exec func() error) error {
+	s.installWorkingSets(dbs)
+	defer s.uninstallWorkingSets(dbs)
+
+	if stmt, ok, err := ParseTransactionStatement(query); ok {
+		if err != nil {
+			return err
+		}
+
+		return s.execTransactionStatement(ctx, dbs, stmt)
+	}
+
+	if ImplicitlyCommits(query) {
+		if err := s.Commit(ctx); err != nil {
+			return err
+		}
+	} else {
+		s.BeginImplicitly(dbs)
+	}
+
+	s.install()
+	defer s.uninstall()
+
+	roots := make([]*doltdb.RootValue, len(dbs))
+	envs := make([]*env.DoltEnv, len(dbs))
+	for i, db := range dbs {
+		roots[i] = db.Root()
+		envs[i] = db.dEnv
+	}
+
+	if err := exec(); err != nil {
+		for i, db := range dbs {
+			if db.sessWS != nil {
+				db.sessWS.setEnv(db, envs[i])
+			}
+
+			db.SetRoot(roots[i])
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// installWorkingSets installs the session's own working sets in the databases given if they're shared with other
+// sessions.
+func (s *Session) installWorkingSets(dbs []*Database) {
+	if !s.shared {
+		return
+	}
+
+	for _, db := range dbs {
+		ws, ok := s.workingSets[db]
+
+		if !ok {
+			ws = &sessionWorkingSet{}
+			s.workingSets[db] = ws
+		}
+
+		ws.install(db)
+	}
+}
+
+func (s *Session) uninstallWorkingSets(dbs []*Database) {
+	if !s.shared {
+		return
+	}
+
+	for _, db := range dbs {
+		s.workingSets[db].uninstall(db)
+	}
+}
+
+func (s *Session) execTransactionStatement(ctx context.Context, dbs []*Database, stmt TransactionStatement) error {
+	switch stmt.Type {
+	case BeginTransaction:
+		if err := s.Commit(ctx); err != nil {
+			return err
+		}
+
+		s.Begin(dbs)
+	case CommitTransaction:
+		return s.Commit(ctx)
+	case RollbackTransaction:
+		s.Rollback()
+	case SetAutocommit:
+		// turning autocommit on commits the current transaction
+		if stmt.Autocommit && !s.autocommit {
+			if err := s.Commit(ctx); err != nil {
+				return err
+			}
+		}
+
+		s.autocommit = stmt.Autocommit
+	}
+
+	return nil
+}
+
+// Begin starts a transaction on the given databases.  Any transaction the session already has must be committed or
+// rolled back first.
+func (s *Session) Begin(dbs []*Database) {
+	startRoots := make([]*doltdb.RootValue, len(dbs))
+	for i, db := range dbs {
+		startRoots[i] = db.Root()
+	}
+
+	s.tx = &transaction{dbs: dbs, startRoots: startRoots, roots: startRoots}
+
+	if !s.shared {
+		s.install()
+	}
+}
+
+// BeginImplicitly starts a transaction on the given databases if autocommit is off and the session doesn't have one.
+// It's called before running a statement without Exec.
+func (s *Session) BeginImplicitly(dbs []*Database) {
+	if s.tx == nil && !s.autocommit {
+		s.Begin(dbs)
+	}
+}
+
+// Commit commits the session's transaction, merging the changes it made into the current roots of its databases.  If
+// they conflict with changes made since the transaction began, the transaction is rolled back and a
+// TransactionConflictError is returned.  Committing when the session has no transaction does nothing.
+func (s *Session) Commit(ctx context.Context) error {
+	if s.tx == nil {
+		return nil
+	}
+
+	tx := s.tx
+	s.install()
+
+	merged := make([]*doltdb.RootValue, len(tx.dbs))
+	for i, db := range tx.dbs {
+		var err error
+		merged[i], err = MergeTransaction(ctx, db.dEnv.DoltDB, tx.outerRoots[i], tx.startRoots[i], db.Root())
+
+		if err != nil {
+			s.Rollback()
+			return err
+		}
+	}
+
+	for i, db := range tx.dbs {
+		db.SetRoot(merged[i])
+	}
+
+	s.tx = nil
+	return nil
+}
+
+// Rollback discards the changes made by the session's transaction.  Rolling back when the session has no transaction
+// does nothing.
+func (s *Session) Rollback() {
+	if s.tx == nil {
+		return
+	}
+
+	s.install()
+
+	for i, db := range s.tx.dbs {
+		db.SetRoot(s.tx.outerRoots[i])
+	}
+
+	s.tx = nil
+}
+
+// install installs the roots of the session's transaction in its databases.
+func (s *Session) install() {
+	tx := s.tx
+	if tx == nil || tx.installed {
+		return
+	}
+
+	tx.outerRoots = make([]*doltdb.RootValue, len(tx.dbs))
+	for i, db := range tx.dbs {
+		tx.outerRoots[i] = db.Root()
+		db.SetRoot(tx.roots[i])
+	}
+
+	tx.installed = true
+}
+
+// uninstall restores the roots the databases had outside of the session's transaction if they're shared with other
+// sessions, saving the roots of the transaction.
+func (s *Session) uninstall() {
+	tx := s.tx
+	if tx == nil || !tx.installed || !s.shared {
+		return
+	}
+
+	tx.roots = make([]*doltdb.RootValue, len(tx.dbs))
+	for i, db := range tx.dbs {
+		tx.roots[i] = db.Root()
+		db.SetRoot(tx.outerRoots[i])
+	}
+
+	tx.installed = false
+}
@@ -0,0 +1,169 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	sqle "github.com/src-d/go-mysql-server"
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	. "github.com/liquidata-inc/dolt/go/libraries/doltcore/sql/sqltestutil"
+)
+
+func TestParseTransactionStatement(t *testing.T) {
+	tests := []struct {
+		query     string
+		expected  TransactionStatement
+		ok        bool
+		expectErr bool
+	}{
+		{"select * from people", TransactionStatement{}, false, false},
+		{"begin", TransactionStatement{Type: BeginTransaction}, true, false},
+		{"START TRANSACTION", TransactionStatement{Type: BeginTransaction}, true, false},
+		{"commit", TransactionStatement{Type: CommitTransaction}, true, false},
+		{"rollback", TransactionStatement{Type: RollbackTransaction}, true, false},
+		{"set autocommit = 0", TransactionStatement{Type: SetAutocommit}, true, false},
+		{"SET @@autocommit = 1", TransactionStatement{Type: SetAutocommit, Autocommit: true}, true, false},
+		{"set session autocommit = off", TransactionStatement{Type: SetAutocommit}, true, false},
+		{"set @@session.autocommit = ON", TransactionStatement{Type: SetAutocommit, Autocommit: true}, true, false},
+		{"set autocommit = true", TransactionStatement{Type: SetAutocommit, Autocommit: true}, true, false},
+		{"set autocommit = 2", TransactionStatement{}, true, true},
+		{"set autocommit = 1, sql_mode = ''", TransactionStatement{}, false, false},
+		{"set @autocommit = 0", TransactionStatement{}, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, ok, err := ParseTransactionStatement(test.query)
+			assert.Equal(t, test.ok, ok)
+
+			if test.expectErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestImplicitlyCommits(t *testing.T) {
+	for _, query := range []string{
+		"create table t (id int primary key)",
+		"drop table people",
+		"alter table people add column c int",
+		"create index idx_age on people (age)",
+		"alter table people add foreign key (age) references other (id)",
+		"select dolt_commit('-m', 'message')",
+		"SELECT DOLT_CHECKOUT('-b', 'branch')",
+	} {
+		assert.True(t, ImplicitlyCommits(query), query)
+	}
+
+	for _, query := range []string{
+		"select * from people",
+		"insert into people (id) values (10)",
+		"update people set age = 10",
+		"select concat('a', 'b')",
+	} {
+		assert.False(t, ImplicitlyCommits(query), query)
+	}
+}
+
+func TestSessionTransactions(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	root, err := dEnv.WorkingRoot(ctx)
+	require.NoError(t, err)
+
+	db := NewDatabase("dolt", root, dEnv)
+	engine := sqle.NewDefault()
+	engine.AddDatabase(db)
+
+	dbs := []*Database{db}
+	s1, s2 := NewSession(true), NewSession(true)
+	exec := func(sess *Session, query string) ([]sql.Row, error) {
+		var rows []sql.Row
+		err := sess.Exec(ctx, dbs, query, func() error {
+			_, iter, err := engine.Query(sql.NewContext(ctx), query)
+
+			if err != nil {
+				return err
+			}
+
+			rows, err = sql.RowIterToRows(iter)
+			return err
+		})
+
+		return rows, err
+	}
+
+	mustExec := func(sess *Session, query string) {
+		_, err := exec(sess, query)
+		require.NoError(t, err, query)
+	}
+
+	age := func(sess *Session, id int) interface{} {
+		rows, err := exec(sess, "select age from people where id = "+strconv.Itoa(id))
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		return rows[0][0]
+	}
+
+	mustExec(s1, "begin")
+	assert.True(t, s1.InTransaction())
+	mustExec(s1, "update people set age = 50 where id = 0")
+	assert.Equal(t, int64(50), age(s1, 0))
+	assert.Equal(t, int64(40), age(s2, 0))
+
+	// a failed statement doesn't affect the rest of the transaction
+	_, err = exec(s1, "insert into people (id, first, last) values (0, 'Homer', 'Simpson')")
+	assert.Error(t, err)
+	assert.True(t, s1.InTransaction())
+
+	mustExec(s2, "update people set age = 11 where id = 1")
+	mustExec(s1, "commit")
+	assert.False(t, s1.InTransaction())
+	assert.Equal(t, int64(50), age(s2, 0))
+	assert.Equal(t, int64(11), age(s2, 1))
+
+	mustExec(s1, "begin")
+	mustExec(s1, "update people set age = 51 where id = 0")
+	mustExec(s2, "update people set age = 52 where id = 0")
+	_, err = exec(s1, "commit")
+	assert.True(t, IsTransactionConflict(err))
+	assert.False(t, s1.InTransaction())
+	assert.Equal(t, int64(52), age(s1, 0))
+
+	mustExec(s1, "set autocommit = 0")
+	assert.False(t, s1.Autocommit())
+	mustExec(s1, "update people set age = 53 where id = 0")
+	assert.True(t, s1.InTransaction())
+	mustExec(s1, "rollback")
+	assert.Equal(t, int64(52), age(s2, 0))
+
+	// data definition statements commit the transaction before they run
+	mustExec(s1, "update people set age = 54 where id = 0")
+	mustExec(s1, "create table other (id bigint primary key)")
+	assert.False(t, s1.InTransaction())
+	assert.Equal(t, int64(54), age(s2, 0))
+}
@@ -0,0 +1,582 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env/actions"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/ref"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/argparser"
+)
+
+const (
+	DoltAddFuncName      = "dolt_add"
+	DoltCommitFuncName   = "dolt_commit"
+	DoltCheckoutFuncName = "dolt_checkout"
+	DoltBranchFuncName   = "dolt_branch"
+	DoltMergeFuncName    = "dolt_merge"
+	DoltResetFuncName    = "dolt_reset"
+)
+
+// vcFunc runs a version control operation against a repository, using arguments parsed the same way as the arguments
+// of the equivalent dolt command.  It returns the single value the function evaluates to.
+type vcFunc func(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (interface{}, error)
+
+// vcFuncDef defines a version control function.
+type vcFuncDef struct {
+	name   string
+	typ    sql.Type
+	parser func() *argparser.ArgParser
+	run    vcFunc
+
+	// switchesBranch returns whether running the function with the arguments given checks out another branch.  It's
+	// nil for functions which never do.
+	switchesBranch func(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (bool, error)
+}
+
+var vcFuncDefs = []vcFuncDef{
+	{DoltAddFuncName, sql.Int64, addArgParser, doltAdd, nil},
+	{DoltCommitFuncName, sql.Text, commitArgParser, doltCommit, nil},
+	{DoltCheckoutFuncName, sql.Int64, checkoutArgParser, doltCheckout, checkoutSwitchesBranch},
+	{DoltBranchFuncName, sql.Int64, branchArgParser, doltBranch, nil},
+	{DoltMergeFuncName, sql.Int64, mergeArgParser, doltMerge, nil},
+	{DoltResetFuncName, sql.Int64, resetArgParser, doltReset, nil},
+}
+
+// callsVCFunc returns true if the statement calls a version control function.
+func callsVCFunc(stmt sqlparser.Statement) bool {
+	calls := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if fn, ok := node.(*sqlparser.FuncExpr); ok {
+			for _, def := range vcFuncDefs {
+				if fn.Name.EqualString(def.name) {
+					calls = true
+				}
+			}
+		}
+
+		return !calls, nil
+	}, stmt)
+
+	return calls
+}
+
+// VersionControlFunctions returns the SQL functions which run version control operations against the current database
+// of the catalog given, such as SELECT DOLT_COMMIT('-m', 'message').  Each function takes the same arguments as the
+// equivalent dolt command, and operates on the working set of the database the same way the command does.  In a
+// session which shares its databases with other sessions, DOLT_CHECKOUT of another branch gives the session its own
+// head and working set, so the branch is only checked out for that session, and the other functions operate on the
+// session's working set.  DOLT_COMMIT returns the hash of the new commit, DOLT_MERGE returns the number of conflicts
+// the merge produced, and the other functions return 0.
+func VersionControlFunctions(catalog *sql.Catalog) []sql.Function {
+	fns := make([]sql.Function, len(vcFuncDefs))
+	for i, def := range vcFuncDefs {
+		def := def
+		fns[i] = sql.FunctionN{
+			Name: def.name,
+			Fn: func(args ...sql.Expression) (sql.Expression, error) {
+				return &VersionControlFunction{def, catalog, args}, nil
+			},
+		}
+	}
+
+	return fns
+}
+
+// VersionControlFunction is the expression for a call to a version control function.
+type VersionControlFunction struct {
+	def     vcFuncDef
+	catalog *sql.Catalog
+	args    []sql.Expression
+}
+
+var _ sql.Expression = (*VersionControlFunction)(nil)
+
+// Resolved implements the sql.Expression interface.
+func (f *VersionControlFunction) Resolved() bool {
+	for _, arg := range f.args {
+		if !arg.Resolved() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String implements the sql.Expression interface.
+func (f *VersionControlFunction) String() string {
+	args := make([]string, len(f.args))
+	for i, arg := range f.args {
+		args[i] = arg.String()
+	}
+
+	return fmt.Sprintf("%s(%s)", strings.ToUpper(f.def.name), strings.Join(args, ", "))
+}
+
+// Type implements the sql.Expression interface.
+func (f *VersionControlFunction) Type() sql.Type {
+	return f.def.typ
+}
+
+// IsNullable implements the sql.Expression interface.
+func (f *VersionControlFunction) IsNullable() bool {
+	return false
+}
+
+// Children implements the sql.Expression interface.
+func (f *VersionControlFunction) Children() []sql.Expression {
+	return f.args
+}
+
+// WithChildren implements the sql.Expression interface.
+func (f *VersionControlFunction) WithChildren(children ...sql.Expression) (sql.Expression, error) {
+	return &VersionControlFunction{f.def, f.catalog, children}, nil
+}
+
+// Eval implements the sql.Expression interface.  The working root of the database is written to the working set before
+// the operation runs, and the database is updated with the working root the operation leaves behind.
+func (f *VersionControlFunction) Eval(ctx *sql.Context, row sql.Row) (interface{}, error) {
+	args := make([]string, len(f.args))
+	for i, arg := range f.args {
+		val, err := arg.Eval(ctx, row)
+
+		if err != nil {
+			return nil, err
+		} else if val == nil {
+			return nil, fmt.Errorf("%s: arguments can't be NULL", strings.ToUpper(f.def.name))
+		}
+
+		str, err := sql.Text.Convert(val)
+
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = str.(string)
+	}
+
+	apr, err := f.def.parser().Parse(args)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", strings.ToUpper(f.def.name), err)
+	}
+
+	db, err := f.currentDatabase()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Flush(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := db.dEnv.UpdateWorkingRoot(ctx, db.root); err != nil {
+		return nil, err
+	}
+
+	dEnv, err := f.sessionEnv(ctx, db, apr)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", strings.ToUpper(f.def.name), err)
+	}
+
+	result, err := f.def.run(ctx, dEnv, apr)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", strings.ToUpper(f.def.name), err)
+	}
+
+	if db.sessWS != nil && f.def.switchesBranch != nil {
+		if err := switchSessionWorkingSet(ctx, db, dEnv); err != nil {
+			return nil, err
+		}
+	}
+
+	root, err := db.dEnv.WorkingRoot(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetRoot(root)
+	return result, nil
+}
+
+// sessionEnv returns the environment the function should be run against.  A session which shares the database with
+// other sessions gets a copy of the repository's head and working set when it checks out another branch.
+func (f *VersionControlFunction) sessionEnv(ctx context.Context, db *Database, apr *argparser.ArgParseResults) (*env.DoltEnv, error) {
+	if db.sessWS == nil || db.sessWS.dEnv != nil || f.def.switchesBranch == nil {
+		return db.dEnv, nil
+	}
+
+	switches, err := f.def.switchesBranch(ctx, db.dEnv, apr)
+
+	if err != nil {
+		return nil, err
+	} else if !switches {
+		return db.dEnv, nil
+	}
+
+	return db.dEnv.Detached(), nil
+}
+
+// switchSessionWorkingSet installs the environment a branch was checked out in as the session's working set.  A session
+// which checks out the repository's branch with no changes in its own working set goes back to the repository's.
+func switchSessionWorkingSet(ctx context.Context, db *Database, dEnv *env.DoltEnv) error {
+	ws := db.sessWS
+
+	if dEnv != ws.repoEnv && ref.Equals(dEnv.RepoState.Head.Ref, ws.repoEnv.RepoState.Head.Ref) {
+		if unchanged, err := dEnv.IsUnchangedFromHead(ctx); err != nil {
+			return err
+		} else if unchanged && !dEnv.IsMergeActive() {
+			dEnv = ws.repoEnv
+		}
+	}
+
+	ws.setEnv(db, dEnv)
+	return nil
+}
+
+// currentDatabase returns the current database of the catalog, which must be a writable dolt database backed by a
+// repository.
+func (f *VersionControlFunction) currentDatabase() (*Database, error) {
+	sqlDB, err := f.catalog.Database(f.catalog.CurrentDatabase())
+
+	if err != nil {
+		return nil, err
+	}
+
+	db, ok := sqlDB.(*Database)
+
+	if !ok || db.dEnv == nil {
+		return nil, fmt.Errorf("%s is not supported by database '%s'", strings.ToUpper(f.def.name), sqlDB.Name())
+	}
+
+	if err := db.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func addArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.SupportsFlag("all", "a", "Stages any and all changes (adds, deletes, and modifications).")
+	return ap
+}
+
+func doltAdd(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (interface{}, error) {
+	var err error
+	if apr.Contains("all") || apr.NArg() == 1 && apr.Arg(0) == "." {
+		err = actions.StageAllTables(ctx, dEnv, false)
+	} else if apr.NArg() == 0 {
+		return nil, errors.New("nothing specified, nothing added")
+	} else {
+		err = actions.StageTables(ctx, dEnv, apr.Args(), false)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return int64(0), nil
+}
+
+func commitArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.SupportsString("message", "m", "msg", "Use the given <msg> as the commit message.")
+	ap.SupportsFlag("allow-empty", "", "Allow recording a commit that has the exact same data as its sole parent.")
+	return ap
+}
+
+func doltCommit(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (interface{}, error) {
+	msg, _ := apr.GetValue("message")
+	err := actions.CommitStaged(ctx, dEnv, msg, apr.Contains("allow-empty"))
+
+	if actions.IsNothingStaged(err) {
+		return nil, errors.New(`no changes added to commit (use DOLT_ADD)`)
+	} else if err != nil {
+		return nil, err
+	}
+
+	cm, err := dEnv.DoltDB.Resolve(ctx, dEnv.RepoState.CWBHeadSpec())
+
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := cm.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return h.String(), nil
+}
+
+func checkoutArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.SupportsString("b", "", "branch", "Create a new branch named <new_branch> and start it at <start_point>.")
+	return ap
+}
+
+// checkoutSwitchesBranch returns whether DOLT_CHECKOUT checks out a branch rather than tables.
+func checkoutSwitchesBranch(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (bool, error) {
+	if apr.Contains("b") {
+		return true, nil
+	} else if apr.NArg() != 1 {
+		return false, nil
+	}
+
+	isBranch, rootsWithTable, err := actions.BranchOrTable(ctx, dEnv, apr.Arg(0))
+
+	if err != nil {
+		return false, err
+	}
+
+	return isBranch && rootsWithTable.IsEmpty(), nil
+}
+
+func doltCheckout(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (interface{}, error) {
+	if newBranch, ok := apr.GetValue("b"); ok {
+		if apr.NArg() > 1 {
+			return nil, errors.New("-b takes a branch name and an optional start point")
+		}
+
+		startPt := "head"
+		if apr.NArg() == 1 {
+			startPt = apr.Arg(0)
+		}
+
+		if err := actions.CreateBranch(ctx, dEnv, newBranch, startPt, false); err != nil {
+			return nil, err
+		}
+
+		return int64(0), actions.CheckoutBranch(ctx, dEnv, newBranch)
+	}
+
+	if apr.NArg() == 0 {
+		return nil, errors.New("a branch or tables must be given")
+	}
+
+	name := apr.Arg(0)
+	isBranch, rootsWithTable, err := actions.BranchOrTable(ctx, dEnv, name)
+
+	if err != nil {
+		return nil, err
+	} else if !rootsWithTable.IsEmpty() || apr.NArg() > 1 {
+		err = actions.CheckoutTables(ctx, dEnv, apr.Args())
+	} else if isBranch {
+		err = actions.CheckoutBranch(ctx, dEnv, name)
+	} else {
+		err = fmt.Errorf("could not find %s", name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return int64(0), nil
+}
+
+func branchArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.SupportsFlag("force", "f", "Reset <branchname> to <startpoint>, even if <branchname> exists already.")
+	ap.SupportsFlag("copy", "c", "Create a copy of a branch.")
+	ap.SupportsFlag("move", "m", "Move/rename a branch")
+	ap.SupportsFlag("delete", "d", "Delete a branch. The branch must be fully merged in its upstream branch.")
+	ap.SupportsFlag("D", "", "Shortcut for --delete --force.")
+	return ap
+}
+
+func doltBranch(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (interface{}, error) {
+	force := apr.Contains("force")
+
+	var err error
+	switch {
+	case apr.Contains("move"), apr.Contains("copy"):
+		if apr.NArg() != 2 {
+			return nil, errors.New("a source and destination branch must be given")
+		}
+
+		if apr.Contains("move") {
+			err = actions.MoveBranch(ctx, dEnv, apr.Arg(0), apr.Arg(1), force)
+		} else {
+			err = actions.CopyBranch(ctx, dEnv, apr.Arg(0), apr.Arg(1), force)
+		}
+	case apr.Contains("delete"), apr.Contains("D"):
+		if apr.NArg() == 0 {
+			return nil, errors.New("branch name required")
+		}
+
+		for _, brName := range apr.Args() {
+			if err = actions.DeleteBranch(ctx, dEnv, brName, force || apr.Contains("D")); err != nil {
+				break
+			}
+		}
+	default:
+		if apr.NArg() == 0 || apr.NArg() > 2 {
+			return nil, errors.New("a branch name and an optional start point must be given")
+		}
+
+		startPt := "head"
+		if apr.NArg() == 2 {
+			startPt = apr.Arg(1)
+		}
+
+		err = actions.CreateBranch(ctx, dEnv, apr.Arg(0), startPt, force)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return int64(0), nil
+}
+
+func mergeArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.SupportsFlag("abort", "", "Abort the current conflict resolution process, and try to reconstruct the pre-merge state.")
+	return ap
+}
+
+// doltMerge merges the branch given into the current branch.  Merges which can be resolved as a fast-forward move the
+// current branch, otherwise the merged root is written to the working set and the merge must be committed with
+// DOLT_COMMIT once any conflicts are resolved.
+func doltMerge(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (interface{}, error) {
+	if apr.Contains("abort") {
+		if !dEnv.IsMergeActive() {
+			return nil, errors.New("there is no merge to abort")
+		}
+
+		if err := actions.CheckoutAllTables(ctx, dEnv); err != nil {
+			return nil, err
+		}
+
+		return int64(0), dEnv.RepoState.ClearMerge()
+	}
+
+	if apr.NArg() != 1 {
+		return nil, errors.New("a branch to merge must be given")
+	}
+
+	if isUnchanged, err := dEnv.IsUnchangedFromHead(ctx); err != nil {
+		return nil, err
+	} else if !isUnchanged {
+		return nil, errors.New("local changes would be overwritten, commit them before merging")
+	} else if dEnv.IsMergeActive() {
+		return nil, errors.New("merging is not possible because you have not committed an active merge")
+	}
+
+	headSpec := dEnv.RepoState.CWBHeadSpec()
+	cm1, err := dEnv.DoltDB.Resolve(ctx, headSpec)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dref, err := dEnv.FindRef(ctx, apr.Arg(0))
+
+	if err != nil {
+		return nil, fmt.Errorf("unknown branch: %s", apr.Arg(0))
+	}
+
+	cs, err := doltdb.NewCommitSpec(dref.String(), dEnv.RepoState.Head.Ref.String())
+
+	if err != nil {
+		return nil, err
+	}
+
+	cm2, err := dEnv.DoltDB.Resolve(ctx, cs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	canFF, err := cm1.CanFastForwardTo(ctx, cm2)
+
+	if err == doltdb.ErrUpToDate || err == doltdb.ErrIsAhead {
+		return int64(0), nil
+	} else if err != nil {
+		return nil, err
+	} else if canFF {
+		return int64(0), actions.FastForward(ctx, dEnv, cm2)
+	}
+
+	mergedRoot, tblToStats, err := actions.MergeCommits(ctx, dEnv.DoltDB, cm1, cm2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	h2, err := cm2.HashOf()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dEnv.RepoState.StartMerge(dref, h2.String()); err != nil {
+		return nil, err
+	}
+
+	if err := dEnv.UpdateWorkingRoot(ctx, mergedRoot); err != nil {
+		return nil, err
+	}
+
+	var conflicts int64
+	for _, stats := range tblToStats {
+		conflicts += int64(stats.Conflicts)
+	}
+
+	return conflicts, nil
+}
+
+func resetArgParser() *argparser.ArgParser {
+	ap := argparser.NewArgParser()
+	ap.SupportsFlag("hard", "", "Resets the working tables and staged tables.")
+	ap.SupportsFlag("soft", "", "Does not touch the working tables, but removes all tables staged to be committed.")
+	return ap
+}
+
+func doltReset(ctx context.Context, dEnv *env.DoltEnv, apr *argparser.ArgParseResults) (interface{}, error) {
+	var err error
+	if apr.ContainsAll("hard", "soft") {
+		return nil, errors.New("--hard and --soft are mutually exclusive options")
+	} else if apr.Contains("hard") {
+		if apr.NArg() != 0 {
+			return nil, errors.New("--hard does not support additional params")
+		}
+
+		err = actions.ResetHard(ctx, dEnv)
+	} else {
+		_, err = actions.ResetSoft(ctx, dEnv, apr.Args())
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return int64(0), nil
+}
@@ -0,0 +1,188 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/src-d/go-mysql-server/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/dtestutils"
+	. "github.com/liquidata-inc/dolt/go/libraries/doltcore/sql/sqltestutil"
+)
+
+func TestVersionControlFunctions(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	commitAll(t, dEnv, "add tables")
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedRows []sql.Row
+		expectedErr  string
+	}{
+		{
+			name:        "commit with nothing staged",
+			query:       "select dolt_commit('-m', 'nothing')",
+			expectedErr: "no changes added to commit",
+		},
+		{
+			name:         "add",
+			query:        "select dolt_add('.')",
+			expectedRows: []sql.Row{{int64(0)}},
+		},
+		{
+			name:         "branch",
+			query:        "select dolt_branch('other')",
+			expectedRows: []sql.Row{{int64(0)}},
+		},
+		{
+			name:        "branch that exists",
+			query:       "select dolt_branch('other')",
+			expectedErr: "already exists",
+		},
+		{
+			name:         "checkout a new branch",
+			query:        "select dolt_checkout('-b', 'feature')",
+			expectedRows: []sql.Row{{int64(0)}},
+		},
+		{
+			name:         "update the working set",
+			query:        "update people set age = 41 where id = 0",
+			expectedRows: []sql.Row{{int64(1), int64(1)}},
+		},
+		{
+			name:         "reset hard",
+			query:        "select dolt_reset('--hard')",
+			expectedRows: []sql.Row{{int64(0)}},
+		},
+		{
+			name:         "reset discards working changes",
+			query:        "select age from people where id = 0",
+			expectedRows: []sql.Row{{int64(40)}},
+		},
+		{
+			name:        "unknown flag",
+			query:       "select dolt_reset('--medium')",
+			expectedErr: "unknown option `medium'",
+		},
+		{
+			name:        "checkout a missing branch",
+			query:       "select dolt_checkout('nope')",
+			expectedErr: "could not find nope",
+		},
+		{
+			name:         "checkout an existing branch",
+			query:        "select dolt_checkout('master')",
+			expectedRows: []sql.Row{{int64(0)}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := executeQuery(ctx, dEnv, test.query)
+
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedRows, rows)
+			}
+		})
+	}
+}
+
+func TestDoltCommitFunction(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+
+	_, err := executeQuery(ctx, dEnv, "select dolt_add('-a')")
+	require.NoError(t, err)
+
+	rows, err := executeQuery(ctx, dEnv, "select dolt_commit('-m', 'add tables')")
+	require.NoError(t, err)
+
+	head, err := headCommit(ctx, dEnv)
+	require.NoError(t, err)
+	h, err := head.HashOf()
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{h.String()}}, rows)
+
+	meta, err := head.GetCommitMeta()
+	require.NoError(t, err)
+	assert.Equal(t, "add tables", meta.Description)
+}
+
+func TestDoltMergeFunction(t *testing.T) {
+	ctx := context.Background()
+	dEnv := dtestutils.CreateTestEnv()
+	CreateTestDatabase(dEnv, t)
+	commitAll(t, dEnv, "add tables")
+
+	for _, query := range []string{
+		"select dolt_checkout('-b', 'feature')",
+		"update people set age = 41 where id = 0",
+		"select dolt_add('people')",
+		"select dolt_commit('-m', 'update homer on feature')",
+		"select dolt_checkout('master')",
+	} {
+		_, err := executeQuery(ctx, dEnv, query)
+		require.NoError(t, err, query)
+	}
+
+	// fast-forward
+	rows, err := executeQuery(ctx, dEnv, "select dolt_merge('feature')")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(0)}}, rows)
+
+	rows, err = executeQuery(ctx, dEnv, "select age from people where id = 0")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(41)}}, rows)
+
+	for _, query := range []string{
+		"update people set age = 42 where id = 0",
+		"select dolt_add('people')",
+		"select dolt_commit('-m', 'update homer on master')",
+		"select dolt_checkout('feature')",
+		"update people set age = 43 where id = 0",
+		"select dolt_add('people')",
+		"select dolt_commit('-m', 'update homer on feature again')",
+		"select dolt_checkout('master')",
+	} {
+		_, err := executeQuery(ctx, dEnv, query)
+		require.NoError(t, err, query)
+	}
+
+	rows, err = executeQuery(ctx, dEnv, "select dolt_merge('feature')")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(1)}}, rows)
+	assert.True(t, dEnv.IsMergeActive())
+
+	rows, err = executeQuery(ctx, dEnv, "select dolt_merge('--abort')")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(0)}}, rows)
+	assert.False(t, dEnv.IsMergeActive())
+
+	rows, err = executeQuery(ctx, dEnv, "select age from people where id = 0")
+	require.NoError(t, err)
+	assert.Equal(t, []sql.Row{{int64(42)}}, rows)
+}
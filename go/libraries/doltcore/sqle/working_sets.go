@@ -0,0 +1,79 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqle
+
+import (
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+)
+
+// sessionWorkingSet is the head and working set used by a session for a database it shares with other sessions.  The
+// session uses the head and working set of the repository until it checks out another branch, after which it has its
+// own, starting from a copy of the repository's.  Checking out the repository's branch again with no changes in the
+// session's working set returns the session to the repository's working set.
+//
+// The session's working set is only installed in the database while the session runs a statement, so other sessions
+// keep using the repository's.  It's discarded when the session ends, though any commits made to branches are kept.
+type sessionWorkingSet struct {
+	// dEnv is the environment of the session's own head and working set, whose repo state is detached from the
+	// repository's.  It's nil while the session uses the repository's head and working set.
+	dEnv *env.DoltEnv
+	// root is the session's working root while it's not installed in the database.
+	root *doltdb.RootValue
+
+	// repoEnv and repoRoot are the environment and root the database has outside of the session.
+	repoEnv  *env.DoltEnv
+	repoRoot *doltdb.RootValue
+}
+
+// install installs the session's working set in the database, if it has its own.
+func (ws *sessionWorkingSet) install(db *Database) {
+	ws.repoEnv, ws.repoRoot = db.dEnv, db.Root()
+	db.sessWS = ws
+
+	if ws.dEnv != nil {
+		db.dEnv = ws.dEnv
+		db.SetRoot(ws.root)
+	}
+}
+
+// uninstall restores the environment and root the database has outside of the session, saving the session's working
+// root if it has its own working set.
+func (ws *sessionWorkingSet) uninstall(db *Database) {
+	if ws.dEnv != nil {
+		ws.root = db.Root()
+		db.SetRoot(ws.repoRoot)
+	}
+
+	db.dEnv = ws.repoEnv
+	db.sessWS = nil
+}
+
+// setEnv switches the installed database to the environment given, which is either the repository's environment or
+// one with a detached repo state.  The caller is responsible for setting the root of the database.
+func (ws *sessionWorkingSet) setEnv(db *Database, dEnv *env.DoltEnv) {
+	if dEnv == ws.repoEnv {
+		ws.dEnv = nil
+	} else {
+		if ws.dEnv == nil {
+			// changes the statement made to the repository's working set so far are kept
+			ws.repoRoot = db.Root()
+		}
+
+		ws.dEnv = dEnv
+	}
+
+	db.dEnv = dEnv
+}
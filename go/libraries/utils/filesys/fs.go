@@ -50,6 +50,11 @@ type WritableFS interface {
 	// it will be overwritten.
 	OpenForWrite(fp string) (io.WriteCloser, error)
 
+	// OpenForAppend opens a file for writing at the end of the file.  The file will be created if it does not exist.
+	// Each write is added to the file atomically, so writes made by other processes appending to the same file are
+	// never lost or interleaved.
+	OpenForAppend(fp string) (io.WriteCloser, error)
+
 	// WriteFile writes the entire data buffer to a given file.  The file will be created if it does not exist,
 	// and if it does exist it will be overwritten.
 	WriteFile(fp string, data []byte) error
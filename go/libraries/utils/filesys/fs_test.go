@@ -85,6 +85,20 @@ func TestFilesystems(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, dataRead, data)
 
+			// Test appending to the file
+			appended := test.RandomData(1024)
+			wr, err := fs.OpenForAppend(fp)
+			require.NoError(t, err)
+			_, err = wr.Write(appended)
+			require.NoError(t, err)
+			err = wr.Close()
+			require.NoError(t, err)
+
+			data = append(data, appended...)
+			dataRead, err = fs.ReadFile(fp)
+			require.NoError(t, err)
+			require.Equal(t, dataRead, data)
+
 			// Test moving the file
 			err = fs.MoveFile(fp, movedFilePath)
 			require.NoError(t, err)
@@ -164,6 +178,34 @@ func TestRecursiveFSIteration(t *testing.T) {
 	}
 }
 
+func TestLocalFilesysWithWorkingDir(t *testing.T) {
+	dir := test.TestDir("TestLocalFilesysWithWorkingDir")
+	err := LocalFS.MkDirs(dir)
+	require.NoError(t, err)
+
+	fs, err := LocalFilesysWithWorkingDir(dir)
+	require.NoError(t, err)
+
+	err = fs.MkDirs("child")
+	require.NoError(t, err)
+	err = fs.WriteFile(filepath.Join("child", testFilename), []byte(testString))
+	require.NoError(t, err)
+
+	// relative paths are resolved against the working directory
+	data, err := LocalFS.ReadFile(filepath.Join(dir, "child", testFilename))
+	require.NoError(t, err)
+	require.Equal(t, testString, string(data))
+
+	abs, err := fs.Abs("child")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "child"), abs)
+
+	// iteration reports paths relative to the directory given
+	actualDirs, actualFiles, err := iterate(fs, "child", true, t)
+	require.NoError(t, err)
+	validate([]string{}, []string{filepath.Join("child", testFilename)}, actualDirs, actualFiles, "local", t)
+}
+
 func TestFSIteration(t *testing.T) {
 	dir := test.TestDir("TestFSIteration")
 
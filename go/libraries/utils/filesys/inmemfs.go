@@ -278,6 +278,7 @@ type inMemFSWriteCloser struct {
 	fs        *InMemFS
 	buf       *bytes.Buffer
 	rwLock    *sync.RWMutex
+	appending bool
 }
 
 func (fsw *inMemFSWriteCloser) Write(p []byte) (int, error) {
@@ -290,6 +291,13 @@ func (fsw *inMemFSWriteCloser) Close() error {
 
 	now := InMemNowFunc()
 	data := fsw.buf.Bytes()
+
+	if fsw.appending {
+		if existing, ok := fsw.fs.objs[fsw.path].(*memFile); ok {
+			data = append(append([]byte{}, existing.data...), data...)
+		}
+	}
+
 	newFile := &memFile{fsw.path, data, fsw.parentDir, now}
 	fsw.parentDir.time = now
 	fsw.parentDir.objs[fsw.path] = newFile
@@ -301,6 +309,16 @@ func (fsw *inMemFSWriteCloser) Close() error {
 // OpenForWrite opens a file for writing.  The file will be created if it does not exist, and if it does exist
 // it will be overwritten.
 func (fs *InMemFS) OpenForWrite(fp string) (io.WriteCloser, error) {
+	return fs.openForWrite(fp, false)
+}
+
+// OpenForAppend opens a file for writing at the end of the file.  The file will be created if it does not exist.  The
+// data written is added to the file when the writer is closed.
+func (fs *InMemFS) OpenForAppend(fp string) (io.WriteCloser, error) {
+	return fs.openForWrite(fp, true)
+}
+
+func (fs *InMemFS) openForWrite(fp string, appending bool) (io.WriteCloser, error) {
 	fs.rwLock.Lock()
 	defer fs.rwLock.Unlock()
 
@@ -317,7 +335,7 @@ func (fs *InMemFS) OpenForWrite(fp string) (io.WriteCloser, error) {
 		return nil, err
 	}
 
-	return &inMemFSWriteCloser{fp, parentDir, fs, bytes.NewBuffer(make([]byte, 0, 512)), fs.rwLock}, nil
+	return &inMemFSWriteCloser{fp, parentDir, fs, bytes.NewBuffer(make([]byte, 0, 512)), fs.rwLock, appending}, nil
 }
 
 // WriteFile writes the entire data buffer to a given file.  The file will be created if it does not exist,
@@ -26,11 +26,35 @@ import (
 // LocalFS is the machines local filesystem
 var LocalFS = &localFS{}
 
-type localFS struct{}
+type localFS struct {
+	// cwd is the directory relative paths are resolved against.  If empty, the process's working directory is used.
+	cwd string
+}
+
+// LocalFilesysWithWorkingDir returns a Filesys backed by the machines local filesystem which resolves relative paths
+// against the directory cwd rather than the process's working directory.
+func LocalFilesysWithWorkingDir(cwd string) (Filesys, error) {
+	absCWD, err := filepath.Abs(cwd)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &localFS{absCWD}, nil
+}
+
+// pathOf returns the path used to access path on the local filesystem.
+func (fs *localFS) pathOf(path string) string {
+	if fs.cwd == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(fs.cwd, path)
+}
 
 // Exists will tell you if a file or directory with a given path already exists, and if it does is it a directory
 func (fs *localFS) Exists(path string) (exists bool, isDir bool) {
-	stat, err := os.Stat(path)
+	stat, err := os.Stat(fs.pathOf(path))
 
 	if err != nil {
 		return false, false
@@ -44,7 +68,7 @@ var errStopMarker = errors.New("stop")
 // Iter iterates over the files and subdirectories within a given directory (Optionally recursively.
 func (fs *localFS) Iter(path string, recursive bool, cb FSIterCB) error {
 	if !recursive {
-		info, err := ioutil.ReadDir(path)
+		info, err := ioutil.ReadDir(fs.pathOf(path))
 
 		if err != nil {
 			return err
@@ -65,8 +89,11 @@ func (fs *localFS) Iter(path string, recursive bool, cb FSIterCB) error {
 }
 
 func (fs *localFS) iter(dir string, cb FSIterCB) error {
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if dir != path {
+	walkDir := fs.pathOf(dir)
+	err := filepath.Walk(walkDir, func(path string, info os.FileInfo, err error) error {
+		if walkDir != path {
+			// report paths relative to the directory given rather than the one walked
+			path = filepath.Join(dir, path[len(walkDir):])
 			stop := cb(path, info.Size(), info.IsDir())
 
 			if stop {
@@ -91,32 +118,37 @@ func (fs *localFS) OpenForRead(fp string) (io.ReadCloser, error) {
 		return nil, ErrIsDir
 	}
 
-	return os.Open(fp)
+	return os.Open(fs.pathOf(fp))
 }
 
 // ReadFile reads the entire contents of a file
 func (fs *localFS) ReadFile(fp string) ([]byte, error) {
-	return ioutil.ReadFile(fp)
+	return ioutil.ReadFile(fs.pathOf(fp))
 }
 
 // OpenForWrite opens a file for writing.  The file will be created if it does not exist, and if it does exist
 // it will be overwritten.
 func (fs *localFS) OpenForWrite(fp string) (io.WriteCloser, error) {
-	return os.OpenFile(fp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.ModePerm)
+	return os.OpenFile(fs.pathOf(fp), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.ModePerm)
+}
+
+// OpenForAppend opens a file for writing at the end of the file.  The file will be created if it does not exist.
+func (fs *localFS) OpenForAppend(fp string) (io.WriteCloser, error) {
+	return os.OpenFile(fs.pathOf(fp), os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.ModePerm)
 }
 
 // WriteFile writes the entire data buffer to a given file.  The file will be created if it does not exist,
 // and if it does exist it will be overwritten.
 func (fs *localFS) WriteFile(fp string, data []byte) error {
-	return ioutil.WriteFile(fp, data, os.ModePerm)
+	return ioutil.WriteFile(fs.pathOf(fp), data, os.ModePerm)
 }
 
 // MkDirs creates a folder and all the parent folders that are necessary to create it.
 func (fs *localFS) MkDirs(path string) error {
-	_, err := os.Stat(path)
+	_, err := os.Stat(fs.pathOf(path))
 
 	if err != nil {
-		return os.MkdirAll(path, os.ModePerm)
+		return os.MkdirAll(fs.pathOf(path), os.ModePerm)
 	}
 
 	return nil
@@ -129,7 +161,7 @@ func (fs *localFS) DeleteFile(path string) error {
 			return ErrIsDir
 		}
 
-		return os.Remove(path)
+		return os.Remove(fs.pathOf(path))
 	}
 
 	return os.ErrNotExist
@@ -139,9 +171,9 @@ func (fs *localFS) DeleteFile(path string) error {
 // true in order to delete the dir and all of it's contents
 func (fs *localFS) Delete(path string, force bool) error {
 	if !force {
-		return os.Remove(path)
+		return os.Remove(fs.pathOf(path))
 	} else {
-		return os.RemoveAll(path)
+		return os.RemoveAll(fs.pathOf(path))
 	}
 }
 
@@ -165,12 +197,12 @@ func (fs *localFS) MoveFile(srcPath, destPath string) error {
 
 // converts a path to an absolute path.  If it's already an absolute path the input path will be returned unaltered
 func (fs *localFS) Abs(path string) (string, error) {
-	return filepath.Abs(path)
+	return filepath.Abs(fs.pathOf(path))
 }
 
 // LastModified gets the last modified timestamp for a file or directory at a given path
 func (fs *localFS) LastModified(path string) (t time.Time, exists bool) {
-	stat, err := os.Stat(path)
+	stat, err := os.Stat(fs.pathOf(path))
 
 	if err != nil {
 		return time.Time{}, false
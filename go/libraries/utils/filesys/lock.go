@@ -34,12 +34,12 @@ type FilesysLock interface {
 }
 
 // CreateFilesysLock creates a new FilesysLock
-func CreateFilesysLock(fs Filesys, filename string) FilesysLock {
-	switch fs.(type) {
+func CreateFilesysLock(fs ReadWriteFS, filename string) FilesysLock {
+	switch fs := fs.(type) {
 	case *InMemFS:
 		return NewInMemFileLock(fs)
 	case *localFS:
-		return NewLocalFileLock(fs, filename)
+		return NewLocalFileLock(fs, fs.pathOf(filename))
 	default:
 		panic("Unsupported file system")
 	}
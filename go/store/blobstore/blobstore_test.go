@@ -27,6 +27,8 @@ import (
 
 	"cloud.google.com/go/storage"
 	"github.com/google/uuid"
+
+	"github.com/liquidata-inc/dolt/go/store/util/s3test"
 )
 
 const (
@@ -78,10 +80,16 @@ func appendLocalTest(tests []BlobstoreTest) []BlobstoreTest {
 	return append(tests, BlobstoreTest{NewLocalBlobstore(dir), 10, 20})
 }
 
+func appendS3Test(tests []BlobstoreTest) []BlobstoreTest {
+	srv := s3test.NewServer()
+	return append(tests, BlobstoreTest{NewS3Blobstore(srv.NewClient(), "test-bucket", uuid.New().String()), 4, 4})
+}
+
 func newBlobStoreTests() []BlobstoreTest {
 	var tests []BlobstoreTest
 	tests = append(tests, BlobstoreTest{NewInMemoryBlobstore(), 10, 20})
 	tests = appendLocalTest(tests)
+	tests = appendS3Test(tests)
 	tests = appendGCSTest(tests)
 
 	return tests
@@ -172,6 +180,51 @@ func TestCheckAndPutError(t *testing.T) {
 	}
 }
 
+func TestS3CheckAndPutError(t *testing.T) {
+	srv := s3test.NewServer()
+	defer srv.Close()
+	bs := NewS3Blobstore(srv.NewClient(), "test-bucket", uuid.New().String())
+
+	ver, err := PutBytes(context.Background(), bs, key, randBytes(32))
+
+	if err != nil {
+		t.Fatalf("Put failed %v.", err)
+	}
+
+	// the error for a failed condition reports the version the blob has
+	for _, expectedVersion := range []string{"", ver + "-stale"} {
+		_, err = CheckAndPutBytes(context.Background(), bs, expectedVersion, key, randBytes(32))
+
+		if cpe, ok := err.(CheckAndPutError); !ok {
+			t.Errorf("Should have failed due to version mismatch.")
+		} else if cpe.ExpectedVersion != expectedVersion || cpe.ActualVersion != ver {
+			t.Errorf("CheckAndPutError does not have expected values - " + cpe.Error())
+		}
+	}
+}
+
+func TestS3CheckConditionalWrites(t *testing.T) {
+	srv := s3test.NewServer()
+	defer srv.Close()
+	bs := NewS3Blobstore(srv.NewClient(), "test-bucket", uuid.New().String())
+
+	// the check is repeated each time a store is opened
+	for i := 0; i < 2; i++ {
+		if err := bs.CheckConditionalWrites(context.Background()); err != nil {
+			t.Errorf("Conditional writes should be supported: %v.", err)
+		}
+	}
+
+	noCondSrv := s3test.NewServer()
+	defer noCondSrv.Close()
+	noCondSrv.IgnoreConditions = true
+	bs = NewS3Blobstore(noCondSrv.NewClient(), "test-bucket", uuid.New().String())
+
+	if err := bs.CheckConditionalWrites(context.Background()); err != ErrConditionalWritesNotSupported {
+		t.Errorf("Conditional writes should not be supported: %v.", err)
+	}
+}
+
 func testCheckAndPut(t *testing.T, bs Blobstore) {
 	ver, err := CheckAndPutBytes(context.Background(), bs, "", key, randBytes(32))
 
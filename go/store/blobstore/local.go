@@ -149,9 +149,10 @@ func (bs *LocalBlobstore) Put(ctx context.Context, key string, reader io.Reader)
 	ver := uuid.New()
 
 	// written as temp file and renamed so the file corresponding to this key
-	// never exists in a partially written state
+	// never exists in a partially written state.  The temp file is created in
+	// the root dir, as files can't be renamed across filesystems.
 	tempFile, err := func() (string, error) {
-		temp, err := ioutil.TempFile("", ver.String())
+		temp, err := ioutil.TempFile(bs.RootDir, ver.String())
 
 		if err != nil {
 			return "", err
@@ -0,0 +1,218 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package blobstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// ErrConditionalWritesNotSupported is returned by CheckConditionalWrites for object stores which ignore the If-Match and
+// If-None-Match headers of PUT requests.
+var ErrConditionalWritesNotSupported = errors.New("the object store does not support conditional writes")
+
+// conditionalWriteProbeKey is the key of the blob written by CheckConditionalWrites.
+const conditionalWriteProbeKey = "conditional_write_probe"
+
+// S3Blobstore provides an implementation of the Blobstore interface for S3 and S3 compatible object stores.  Versions
+// are object ETags, and CheckAndPut is implemented with conditional writes, so object stores without support for
+// If-Match and If-None-Match headers on PUT requests can't be used.  CheckConditionalWrites should be used to check
+// that an object store supports them before it's used.
+type S3Blobstore struct {
+	s3Svc  s3iface.S3API
+	bucket string
+	prefix string
+}
+
+// NewS3Blobstore creates a new instance of a S3Blobstore
+func NewS3Blobstore(s3Svc s3iface.S3API, bucket, prefix string) *S3Blobstore {
+	return &S3Blobstore{s3Svc, bucket, prefix}
+}
+
+func isS3NotFound(err error) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		switch aerr.Code() {
+		case s3.ErrCodeNoSuchKey, "NotFound":
+			return true
+		}
+	}
+
+	return false
+}
+
+func isS3PreconditionFailed(err error) bool {
+	if reqErr, ok := err.(awserr.RequestFailure); ok {
+		// a conflicting conditional write in progress is reported with a 409
+		return reqErr.StatusCode() == http.StatusPreconditionFailed || reqErr.StatusCode() == http.StatusConflict
+	}
+
+	return false
+}
+
+// Exists returns true if a blob exists for the given key, and false if it does not.
+// error may be returned if there are errors accessing the object store.
+func (bs *S3Blobstore) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := bs.s3Svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(bs.prefix + key),
+	})
+
+	if isS3NotFound(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// Get retrieves an io.reader for the portion of a blob specified by br along with
+// its version
+func (bs *S3Blobstore) Get(ctx context.Context, key string, br BlobRange) (io.ReadCloser, string, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(bs.prefix + key),
+	}
+
+	// ranges with negative offsets are read as a suffix of the object, limited to the range's length
+	if !br.isAllRange() {
+		if br.offset < 0 {
+			input.Range = aws.String("bytes=" + strconv.FormatInt(br.offset, 10))
+		} else if br.length == 0 {
+			input.Range = aws.String("bytes=" + strconv.FormatInt(br.offset, 10) + "-")
+		} else {
+			input.Range = aws.String("bytes=" + strconv.FormatInt(br.offset, 10) + "-" + strconv.FormatInt(br.offset+br.length-1, 10))
+		}
+	}
+
+	result, err := bs.s3Svc.GetObjectWithContext(ctx, input)
+
+	if isS3NotFound(err) {
+		return nil, "", NotFound{key}
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	var rc io.ReadCloser = result.Body
+	if br.offset < 0 && br.length != 0 {
+		rc = limitedReadCloser{io.LimitReader(result.Body, br.length), result.Body}
+	}
+
+	return rc, aws.StringValue(result.ETag), nil
+}
+
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (bs *S3Blobstore) put(ctx context.Context, key string, reader io.Reader, opts ...request.Option) (string, error) {
+	data, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return "", err
+	}
+
+	result, err := bs.s3Svc.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(bs.prefix + key),
+		Body:   bytes.NewReader(data),
+	}, opts...)
+
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(result.ETag), nil
+}
+
+// Put sets the blob and the version for a key
+func (bs *S3Blobstore) Put(ctx context.Context, key string, reader io.Reader) (string, error) {
+	return bs.put(ctx, key, reader)
+}
+
+// CheckAndPut will check the current version of a blob against an expectedVersion, and if the
+// versions match it will update the data and version associated with the key
+func (bs *S3Blobstore) CheckAndPut(ctx context.Context, expectedVersion, key string, reader io.Reader) (string, error) {
+	ver, err := bs.put(ctx, key, reader, ifVersion(expectedVersion))
+
+	if isS3PreconditionFailed(err) {
+		return "", bs.checkAndPutError(ctx, expectedVersion, key)
+	}
+
+	return ver, err
+}
+
+// ifVersion returns a request option which makes a PUT request conditional on the object having the version given, or
+// on it not existing if the version is empty.
+func ifVersion(version string) request.Option {
+	return func(r *request.Request) {
+		if version != "" {
+			r.HTTPRequest.Header.Set("If-Match", version)
+		} else {
+			r.HTTPRequest.Header.Set("If-None-Match", "*")
+		}
+	}
+}
+
+// checkAndPutError returns the error for a CheckAndPut whose condition failed, with the version the object has now.
+// The version is empty if the object doesn't exist.
+func (bs *S3Blobstore) checkAndPutError(ctx context.Context, expectedVersion, key string) error {
+	result, err := bs.s3Svc.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bs.bucket),
+		Key:    aws.String(bs.prefix + key),
+	})
+
+	if isS3NotFound(err) {
+		return CheckAndPutError{key, expectedVersion, ""}
+	} else if err != nil {
+		return err
+	}
+
+	return CheckAndPutError{key, expectedVersion, aws.StringValue(result.ETag)}
+}
+
+// CheckConditionalWrites checks that the object store supports the conditional writes CheckAndPut relies on, returning
+// ErrConditionalWritesNotSupported if it doesn't.  Object stores which ignore the conditions would let concurrent
+// updates of a blob silently overwrite each other.  A small blob is written to the store to do the check.
+func (bs *S3Blobstore) CheckConditionalWrites(ctx context.Context) error {
+	ver, err := bs.Put(ctx, conditionalWriteProbeKey, bytes.NewReader([]byte(conditionalWriteProbeKey)))
+
+	if err != nil {
+		return err
+	}
+
+	// both writes must fail, as the blob exists and has a different version than the one given
+	for _, cond := range []string{"", ver + "-stale"} {
+		_, err = bs.put(ctx, conditionalWriteProbeKey, bytes.NewReader([]byte(conditionalWriteProbeKey)), ifVersion(cond))
+
+		if err == nil {
+			return ErrConditionalWritesNotSupported
+		} else if !isS3PreconditionFailed(err) {
+			return err
+		}
+	}
+
+	return nil
+}
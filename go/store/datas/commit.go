@@ -107,6 +107,8 @@ func FindCommonAncestor(ctx context.Context, c1, c2 types.Ref, vr types.ValueRea
 	return a, ok, nil
 }
 
+// parentsToQueue adds the parents of the commits in |refs| to |q|.  Commits which aren't in the database, such as
+// those beyond the boundary of a shallow clone, are skipped.
 func parentsToQueue(ctx context.Context, refs types.RefSlice, q *types.RefByHeight, vr types.ValueReader) error {
 	for _, r := range refs {
 		v, err := r.TargetValue(ctx, vr)
@@ -115,6 +117,10 @@ func parentsToQueue(ctx context.Context, refs types.RefSlice, q *types.RefByHeig
 			return err
 		}
 
+		if v == nil {
+			continue
+		}
+
 		c := v.(types.Struct)
 		ps, ok, err := c.MaybeGet(ParentsField)
 
@@ -24,14 +24,19 @@ package datas
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"github.com/liquidata-inc/dolt/go/store/nbs"
 
 	"github.com/liquidata-inc/dolt/go/store/chunks"
+	"github.com/liquidata-inc/dolt/go/store/hash"
 	"github.com/liquidata-inc/dolt/go/store/types"
 )
 
+// ErrGCUnsupported is returned by CollectGarbage when the ChunkStore backing a Database is not able to remove chunks.
+var ErrGCUnsupported = errors.New("garbage collection is not supported by this database")
+
 // Database provides versioned storage for noms values. While Values can be
 // directly read and written from a Database, it is generally more appropriate
 // to read data by inspecting the Head of a Dataset and write new data by
@@ -131,6 +136,12 @@ type Database interface {
 
 	Flush(ctx context.Context) error
 
+	// SetShallowCommits sets the hashes of the commits whose parents are
+	// missing from the database because it was pulled with a limited depth.
+	// These commits can be made the head of a dataset even though their
+	// parents are missing.  By default a database has no shallow commits.
+	SetShallowCommits(hashes hash.HashSet)
+
 	// chunkStore returns the ChunkStore used to read and write
 	// groups of values to the database efficiently. This interface is a low-
 	// level detail of the database that should infrequently be needed by
@@ -150,3 +161,21 @@ func CanUsePuller(db Database) bool {
 
 	return ok
 }
+
+// CollectGarbage removes every chunk which is not reachable from the datasets of |db| or from one of the extra roots
+// given.  Not all Databases support this, and ErrGCUnsupported is returned for those that do not.
+func CollectGarbage(ctx context.Context, db Database, extraRoots hash.HashSet) error {
+	gc, ok := db.chunkStore().(nbs.GarbageCollector)
+
+	if !ok {
+		return ErrGCUnsupported
+	}
+
+	err := gc.CollectGarbage(ctx, extraRoots)
+
+	if err != nil {
+		return err
+	}
+
+	return db.Rebase(ctx)
+}
@@ -25,6 +25,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/liquidata-inc/dolt/go/store/chunks"
 	"github.com/liquidata-inc/dolt/go/store/d"
@@ -37,6 +38,9 @@ import (
 type database struct {
 	*types.ValueStore
 	rt rootTracker
+
+	shallowMu sync.RWMutex
+	shallow   hash.HashSet
 }
 
 var (
@@ -184,7 +188,7 @@ func (db *database) doSetHead(ctx context.Context, ds Dataset, newHeadRef types.
 		return err
 	}
 
-	commitRef, err := db.WriteValue(ctx, commit) // will be orphaned if the tryCommitChunks() below fails
+	commitRef, err := db.writeCommit(ctx, commit)
 
 	if err != nil {
 		return err
@@ -276,7 +280,7 @@ func (db *database) doCommit(ctx context.Context, datasetID string, commit types
 			return err
 		}
 
-		commitRef, err := db.WriteValue(ctx, commit) // will be orphaned if the tryCommitChunks() below fails
+		commitRef, err := db.writeCommit(ctx, commit) // will be orphaned if the tryCommitChunks() below fails
 
 		if err != nil {
 			return err
@@ -464,6 +468,46 @@ func (db *database) tryCommitChunks(ctx context.Context, currentDatasets types.M
 	return nil
 }
 
+func (db *database) SetShallowCommits(hashes hash.HashSet) {
+	db.shallowMu.Lock()
+	defer db.shallowMu.Unlock()
+	db.shallow = hashes
+}
+
+// writeCommit writes a commit which is becoming the head of a dataset.  Like any other value, the values it refers to
+// must be in the database, other than the parents of a shallow commit.
+func (db *database) writeCommit(ctx context.Context, commit types.Struct) (types.Ref, error) {
+	h, err := commit.Hash(db.Format())
+
+	if err != nil {
+		return types.Ref{}, err
+	}
+
+	db.shallowMu.RLock()
+	isShallow := db.shallow.Has(h)
+	db.shallowMu.RUnlock()
+
+	if !isShallow {
+		return db.WriteValue(ctx, commit)
+	}
+
+	parents := hash.NewHashSet()
+	if ps, ok, err := commit.MaybeGet(ParentsField); err != nil {
+		return types.Ref{}, err
+	} else if ok {
+		err = ps.(types.Set).IterAll(ctx, func(v types.Value) error {
+			parents.Insert(v.(types.Ref).TargetHash())
+			return nil
+		})
+
+		if err != nil {
+			return types.Ref{}, err
+		}
+	}
+
+	return db.WriteValueWithAbsentRefs(ctx, commit, parents)
+}
+
 func (db *database) validateRefAsCommit(ctx context.Context, r types.Ref) (types.Struct, error) {
 	v, err := db.ReadValue(ctx, r.TargetHash())
 
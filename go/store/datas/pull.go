@@ -228,10 +228,17 @@ func clone(ctx context.Context, srcTS, sinkTS nbs.TableFileStore, eventCh chan<-
 
 // Pull objects that descend from sourceRef from srcDB to sinkDB.
 func Pull(ctx context.Context, srcDB, sinkDB Database, sourceRef types.Ref, progressCh chan PullProgress) error {
-	return pull(ctx, srcDB, sinkDB, sourceRef, progressCh, defaultBatchSize)
+	return pull(ctx, srcDB, sinkDB, sourceRef, nil, progressCh, defaultBatchSize)
 }
 
-func pull(ctx context.Context, srcDB, sinkDB Database, sourceRef types.Ref, progressCh chan PullProgress, batchSize int) error {
+// PullShallow pulls the objects that descend from sourceRef from srcDB to sinkDB, except for the chunks in skip and the
+// chunks which are only reachable through them.  This is used to pull a limited amount of history, where skip holds
+// the parents of the commits at the boundary of that history.
+func PullShallow(ctx context.Context, srcDB, sinkDB Database, sourceRef types.Ref, skip hash.HashSet, progressCh chan PullProgress) error {
+	return pull(ctx, srcDB, sinkDB, sourceRef, skip, progressCh, defaultBatchSize)
+}
+
+func pull(ctx context.Context, srcDB, sinkDB Database, sourceRef types.Ref, skip hash.HashSet, progressCh chan PullProgress, batchSize int) error {
 	// Sanity Check
 	exists, err := srcDB.chunkStore().Has(ctx, sourceRef.TargetHash())
 
@@ -282,7 +289,7 @@ func pull(ctx context.Context, srcDB, sinkDB Database, sourceRef types.Ref, prog
 				return err
 			}
 
-			uniqueOrdered, err = putChunks(ctx, sinkDB, batch, neededChunks, nextLevel, uniqueOrdered)
+			uniqueOrdered, err = putChunks(ctx, sinkDB, batch, neededChunks, skip, nextLevel, uniqueOrdered)
 
 			if err != nil {
 				return err
@@ -329,7 +336,7 @@ func persistChunks(ctx context.Context, cs chunks.ChunkStore) error {
 // optimization problem down to the chunk store which can make smarter decisions.
 func PullWithoutBatching(ctx context.Context, srcDB, sinkDB Database, sourceRef types.Ref, progressCh chan PullProgress) error {
 	// by increasing the batch size to MaxInt32 we effectively remove batching here.
-	return pull(ctx, srcDB, sinkDB, sourceRef, progressCh, math.MaxInt32)
+	return pull(ctx, srcDB, sinkDB, sourceRef, nil, progressCh, math.MaxInt32)
 }
 
 // concurrently pull all chunks from this batch that the sink is missing out of the source
@@ -367,10 +374,15 @@ func getChunks(ctx context.Context, srcDB Database, batch hash.HashSlice, sample
 }
 
 // put the chunks that were downloaded into the sink IN ORDER and at the same time gather up an ordered, uniquified list
-// of all the children of the chunks and add them to the list of the next level tree chunks.
-func putChunks(ctx context.Context, sinkDB Database, hashes hash.HashSlice, neededChunks map[hash.Hash]*chunks.Chunk, nextLevel hash.HashSet, uniqueOrdered hash.HashSlice) (hash.HashSlice, error) {
+// of all the children of the chunks, other than those in skip, and add them to the list of the next level tree chunks.
+func putChunks(ctx context.Context, sinkDB Database, hashes hash.HashSlice, neededChunks map[hash.Hash]*chunks.Chunk, skip, nextLevel hash.HashSet, uniqueOrdered hash.HashSlice) (hash.HashSlice, error) {
 	for _, h := range hashes {
-		c := neededChunks[h]
+		c, ok := neededChunks[h]
+
+		if !ok {
+			return hash.HashSlice{}, fmt.Errorf("source database is missing chunk %s", h.String())
+		}
+
 		err := sinkDB.chunkStore().Put(ctx, *c)
 
 		if err != nil {
@@ -378,7 +390,7 @@ func putChunks(ctx context.Context, sinkDB Database, hashes hash.HashSlice, need
 		}
 
 		err = types.WalkRefs(*c, sinkDB.Format(), func(r types.Ref) error {
-			if !nextLevel.Has(r.TargetHash()) {
+			if !nextLevel.Has(r.TargetHash()) && !skip.Has(r.TargetHash()) {
 				uniqueOrdered = append(uniqueOrdered, r.TargetHash())
 				nextLevel.Insert(r.TargetHash())
 			}
@@ -235,6 +235,25 @@ func (suite *PullSuite) TestPullMultiGeneration() {
 	suite.True(srcL.Equals(mustGetValue(v.(types.Struct).MaybeGet(ValueField))))
 }
 
+func (suite *PullSuite) TestPullShallow() {
+	srcL := buildListOfHeight(2, suite.source)
+	parentRef := suite.commitToSource(srcL, mustSet(types.NewSet(context.Background(), suite.source)))
+	srcL = buildListOfHeight(4, suite.source)
+	sourceRef := suite.commitToSource(srcL, mustSet(types.NewSet(context.Background(), suite.source, parentRef)))
+
+	err := PullShallow(context.Background(), suite.source, suite.sink, sourceRef, hash.NewHashSet(parentRef.TargetHash()), nil)
+	suite.NoError(err)
+
+	v, err := suite.sink.ReadValue(context.Background(), sourceRef.TargetHash())
+	suite.NoError(err)
+	suite.NotNil(v)
+	suite.True(srcL.Equals(mustGetValue(v.(types.Struct).MaybeGet(ValueField))))
+
+	v, err = suite.sink.ReadValue(context.Background(), parentRef.TargetHash())
+	suite.NoError(err)
+	suite.Nil(v)
+}
+
 // Source: -6-> C2(L5) -1-> N
 //               .  \  -5-> L4 -1-> N
 //                .          \ -4-> L3 -1-> N
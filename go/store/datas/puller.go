@@ -74,6 +74,7 @@ type Puller struct {
 	srcChunkStore NBSCompressedChunkStore
 	sinkDB        Database
 	rootChunkHash hash.Hash
+	skip          hash.HashSet
 	downloaded    hash.HashSet
 
 	wr          *nbs.CmpChunkTableWriter
@@ -126,6 +127,13 @@ func NewTFPullerEvent(et PullerEventType, details *TableFileEventDetails) Puller
 // NewPuller creates a new Puller instance to do the syncing.  If a nil puller is returned without error that means
 // that there is nothing to pull and the sinkDB is already up to date.
 func NewPuller(ctx context.Context, tempDir string, chunksPerTF int, srcDB, sinkDB Database, rootChunkHash hash.Hash, eventCh chan PullerEvent) (*Puller, error) {
+	return NewShallowPuller(ctx, tempDir, chunksPerTF, srcDB, sinkDB, rootChunkHash, nil, eventCh)
+}
+
+// NewShallowPuller creates a Puller which doesn't pull the chunks in |skip|, or the chunks which are only reachable
+// through them.  This is used to pull a limited amount of history, where |skip| holds the parents of the commits at the
+// boundary of that history.
+func NewShallowPuller(ctx context.Context, tempDir string, chunksPerTF int, srcDB, sinkDB Database, rootChunkHash hash.Hash, skip hash.HashSet, eventCh chan PullerEvent) (*Puller, error) {
 	if eventCh == nil {
 		panic("eventCh is required")
 	}
@@ -172,6 +180,7 @@ func NewPuller(ctx context.Context, tempDir string, chunksPerTF int, srcDB, sink
 		srcChunkStore: srcChunkStore,
 		sinkDB:        sinkDB,
 		rootChunkHash: rootChunkHash,
+		skip:          skip,
 		downloaded:    hash.HashSet{},
 		tempDir:       tempDir,
 		wr:            wr,
@@ -282,6 +291,7 @@ func (p *Puller) Pull(ctx context.Context) error {
 
 	for len(absent) > 0 {
 		limitToNewChunks(absent, p.downloaded)
+		limitToNewChunks(absent, p.skip)
 
 		chunksInLevel := len(absent)
 		twDetails.ChunksInLevel = chunksInLevel
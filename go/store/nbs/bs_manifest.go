@@ -80,7 +80,8 @@ func (bsm blobstoreManifest) Update(ctx context.Context, lastLock addr, newConte
 
 	ver, contents, err := manifestVersionAndContents(ctx, bsm.bs)
 
-	if err != nil {
+	// the first update of a new store is made when there is no manifest, and no version to check against
+	if err != nil && !blobstore.IsNotFoundError(err) {
 		return manifestContents{}, err
 	}
 
@@ -0,0 +1,136 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/store/blobstore"
+	"github.com/liquidata-inc/dolt/go/store/chunks"
+	"github.com/liquidata-inc/dolt/go/store/constants"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+)
+
+func putAndCommit(t *testing.T, store *NomsBlockStore, data []byte) (chunks.Chunk, bool) {
+	ctx := context.Background()
+	c := chunks.NewChunk(data)
+	err := store.Put(ctx, c)
+	require.NoError(t, err)
+
+	root, err := store.Root(ctx)
+	require.NoError(t, err)
+	success, err := store.Commit(ctx, c.Hash(), root)
+	require.NoError(t, err)
+
+	return c, success
+}
+
+func testBSStore(t *testing.T, bs blobstore.Blobstore) {
+	ctx := context.Background()
+	store, err := NewBSStore(ctx, constants.FormatDefaultString, bs, testMemTableSize)
+	require.NoError(t, err)
+	defer store.Close()
+
+	c1, success := putAndCommit(t, store, []byte("abc"))
+	require.True(t, success)
+
+	// a store opened later sees the committed root and chunk
+	reopened, err := NewBSStore(ctx, constants.FormatDefaultString, bs, testMemTableSize)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	root, err := reopened.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, c1.Hash(), root)
+	assertInputInStore([]byte("abc"), c1.Hash(), reopened, assert.New(t))
+
+	// the commit of a store which hasn't seen the latest root fails, without losing the other store's update
+	c2, success := putAndCommit(t, reopened, []byte("def"))
+	require.True(t, success)
+	c3, success := putAndCommit(t, store, []byte("ghi"))
+	require.False(t, success)
+
+	err = store.Rebase(ctx)
+	require.NoError(t, err)
+	root, err = store.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, c2.Hash(), root)
+
+	success, err = store.Commit(ctx, c3.Hash(), root)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	err = reopened.Rebase(ctx)
+	require.NoError(t, err)
+	root, err = reopened.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, c3.Hash(), root)
+
+	for _, c := range []chunks.Chunk{c1, c2, c3} {
+		assertInputInStore(c.Data(), c.Hash(), reopened, assert.New(t))
+	}
+}
+
+func TestBSStoreInMemoryBlobstore(t *testing.T) {
+	testBSStore(t, blobstore.NewInMemoryBlobstore())
+}
+
+func TestBSStoreLocalBlobstore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	testBSStore(t, blobstore.NewLocalBlobstore(dir))
+}
+
+func TestBSStoreTableFiles(t *testing.T) {
+	ctx := context.Background()
+	src, err := NewBSStore(ctx, constants.FormatDefaultString, blobstore.NewInMemoryBlobstore(), testMemTableSize)
+	require.NoError(t, err)
+	defer src.Close()
+
+	c, success := putAndCommit(t, src, []byte("abc"))
+	require.True(t, success)
+
+	dest, err := NewBSStore(ctx, constants.FormatDefaultString, blobstore.NewInMemoryBlobstore(), testMemTableSize)
+	require.NoError(t, err)
+	defer dest.Close()
+
+	root, tableFiles, err := src.Sources(ctx)
+	require.NoError(t, err)
+	require.Len(t, tableFiles, 1)
+
+	for _, tf := range tableFiles {
+		rd, err := tf.Open()
+		require.NoError(t, err)
+		err = dest.WriteTableFile(ctx, tf.FileID(), tf.NumChunks(), rd, 0, nil)
+		rd.Close()
+		require.NoError(t, err)
+	}
+
+	err = dest.SetRootChunk(ctx, root, hash.Hash{})
+	require.NoError(t, err)
+
+	destRoot, err := dest.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, c.Hash(), destRoot)
+	assertInputInStore([]byte("abc"), c.Hash(), dest, assert.New(t))
+}
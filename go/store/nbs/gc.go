@@ -0,0 +1,353 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/liquidata-inc/dolt/go/store/chunks"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+var (
+	// ErrGCPendingWrites is returned when garbage collection is attempted on a store which has chunks that have not
+	// been committed.
+	ErrGCPendingWrites = errors.New("cannot collect garbage while there are uncommitted writes")
+
+	// ErrGCRootMoved is returned when the root of the store is updated by another writer while garbage is being
+	// collected.  No chunks are removed when this happens, and the collection can be retried.
+	ErrGCRootMoved = errors.New("root moved during garbage collection")
+)
+
+// GarbageCollector is implemented by ChunkStores which are able to remove chunks that are no longer reachable.
+type GarbageCollector interface {
+	// CollectGarbage removes every chunk which cannot be reached from the root of the store or from one of the extra
+	// roots given.
+	CollectGarbage(ctx context.Context, extraRoots hash.HashSet) error
+}
+
+// tableFilePruner is implemented by tablePersisters which are able to delete the table files which are no longer
+// referenced by the manifest.
+type tableFilePruner interface {
+	// PruneTableFiles deletes the table files in |prune|.
+	PruneTableFiles(ctx context.Context, prune map[addr]bool) error
+}
+
+// CollectGarbage does a mark and sweep of the chunks in the store.  Every chunk reachable from the root of the store,
+// or from one of the extra roots given, is copied into new table files, and the manifest is then updated to replace
+// the existing tables with the new ones.  If the persister supports it, the table files which were replaced are deleted
+// afterwards.  Only files which were in the manifest are deleted, so table files which have been written by another
+// process but not yet added to the manifest are left alone.
+func (nbs *NomsBlockStore) CollectGarbage(ctx context.Context, extraRoots hash.HashSet) error {
+	nbf, err := types.GetFormatForVersionString(nbs.Version())
+
+	if err != nil {
+		return err
+	}
+
+	err = nbs.Rebase(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	root, err := nbs.gcRoot()
+
+	if err != nil {
+		return err
+	}
+
+	roots := hash.HashSet{}
+	for h := range extraRoots {
+		if !h.IsEmpty() {
+			roots.Insert(h)
+		}
+	}
+
+	if !root.IsEmpty() {
+		roots.Insert(root)
+	}
+
+	specs, err := nbs.copyReachableChunks(ctx, nbf, roots)
+
+	var replaced []tableSpec
+	if err == nil {
+		replaced, err = nbs.swapTables(ctx, root, specs)
+	}
+
+	if err != nil {
+		// the error from the collection is more useful than a failure to clean up after it, which only leaves behind
+		// table files that are not referenced by the manifest
+		_ = nbs.removeUnusedTables(ctx, specs)
+		return err
+	}
+
+	if pruner, ok := nbs.p.(tableFilePruner); ok {
+		prune := make(map[addr]bool, len(replaced))
+		for _, spec := range replaced {
+			prune[spec.name] = true
+		}
+
+		for _, spec := range specs {
+			delete(prune, spec.name)
+		}
+
+		return pruner.PruneTableFiles(ctx, prune)
+	}
+
+	return nil
+}
+
+func (nbs *NomsBlockStore) gcRoot() (hash.Hash, error) {
+	nbs.mu.RLock()
+	defer nbs.mu.RUnlock()
+
+	if nbs.mt != nil || nbs.tables.Novel() > 0 {
+		return hash.Hash{}, ErrGCPendingWrites
+	}
+
+	return nbs.upstream.root, nil
+}
+
+// copyReachableChunks walks the chunk graph breadth first starting at |roots|, writing every chunk visited to new
+// tables.  It returns the specs of the tables written, including when an error is returned after some of the tables
+// have been written.
+func (nbs *NomsBlockStore) copyReachableChunks(ctx context.Context, nbf *types.NomsBinFormat, roots hash.HashSet) ([]tableSpec, error) {
+	var specs []tableSpec
+	mt := newMemTable(nbs.mtSize)
+
+	flush := func() error {
+		cnt, err := mt.count()
+
+		if err != nil {
+			return err
+		}
+
+		if cnt == 0 {
+			return nil
+		}
+
+		cs, err := nbs.p.Persist(ctx, mt, nil, nbs.stats)
+
+		if err != nil {
+			return err
+		}
+
+		name, err := cs.hash()
+
+		if err != nil {
+			return err
+		}
+
+		cnt, err = cs.count()
+
+		if err != nil {
+			return err
+		}
+
+		specs = append(specs, tableSpec{name, cnt})
+		mt = newMemTable(nbs.mtSize)
+
+		return nil
+	}
+
+	addChunk := func(c *chunks.Chunk) error {
+		a := addr(c.Hash())
+
+		if mt.addChunk(a, c.Data()) {
+			return nil
+		}
+
+		err := flush()
+
+		if err != nil {
+			return err
+		}
+
+		if uint64(len(c.Data())) > nbs.mtSize {
+			mt = newMemTable(uint64(len(c.Data())))
+		}
+
+		mt.addChunk(a, c.Data())
+
+		return nil
+	}
+
+	visited := hash.HashSet{}
+	next := roots
+	for len(next) > 0 {
+		curr := next
+		next = hash.HashSet{}
+
+		for h := range curr {
+			visited.Insert(h)
+		}
+
+		found := hash.HashSet{}
+		foundChunks := make(chan *chunks.Chunk, 1024)
+
+		var getErr error
+		go func() {
+			defer close(foundChunks)
+			getErr = nbs.GetMany(ctx, curr, foundChunks)
+		}()
+
+		var err error
+		for c := range foundChunks {
+			if err != nil {
+				// drain the channel so GetMany can finish
+				continue
+			}
+
+			found.Insert(c.Hash())
+			err = addChunk(c)
+
+			if err == nil {
+				err = types.WalkRefs(*c, nbf, func(r types.Ref) error {
+					if h := r.TargetHash(); !visited.Has(h) {
+						next.Insert(h)
+					}
+
+					return nil
+				})
+			}
+		}
+
+		if getErr != nil {
+			return specs, getErr
+		} else if err != nil {
+			return specs, err
+		}
+
+		if len(found) != len(curr) {
+			for h := range curr {
+				if !found.Has(h) {
+					return specs, fmt.Errorf("chunk %s is reachable but is missing from the store", h.String())
+				}
+			}
+		}
+	}
+
+	err := flush()
+
+	return specs, err
+}
+
+// swapTables replaces the tables in the manifest with |specs| if the root of the store is still |root|.  It returns the
+// specs of the tables which were in the manifest before they were replaced.
+func (nbs *NomsBlockStore) swapTables(ctx context.Context, root hash.Hash, specs []tableSpec) (replaced []tableSpec, err error) {
+	nbs.mm.LockForUpdate()
+	defer func() {
+		unlockErr := nbs.mm.UnlockForUpdate()
+
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	nbs.mu.Lock()
+	defer nbs.mu.Unlock()
+
+	if nbs.mt != nil || nbs.tables.Novel() > 0 {
+		return nil, ErrGCPendingWrites
+	}
+
+	exists, contents, err := nbs.mm.Fetch(ctx, nbs.stats)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists || contents.root != root {
+		return nil, ErrGCRootMoved
+	}
+
+	newContents := manifestContents{
+		vers:  contents.vers,
+		root:  root,
+		lock:  generateLockHash(root, specs),
+		specs: specs,
+	}
+
+	upstream, err := nbs.mm.Update(ctx, contents.lock, newContents, nbs.stats, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if upstream.lock != newContents.lock {
+		return nil, ErrGCRootMoved
+	}
+
+	newTables, err := nbs.tables.Rebase(ctx, specs, nbs.stats)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nbs.upstream = newContents
+	nbs.tables = newTables
+
+	return contents.specs, nil
+}
+
+// removeUnusedTables deletes the table files in |specs|, which were written by a garbage collection that failed, if
+// the persister supports it.  Tables in the current manifest are kept, as another writer may have added a table
+// containing exactly the same chunks.
+func (nbs *NomsBlockStore) removeUnusedTables(ctx context.Context, specs []tableSpec) error {
+	pruner, ok := nbs.p.(tableFilePruner)
+
+	if !ok || len(specs) == 0 {
+		return nil
+	}
+
+	exists, contents, err := nbs.mm.Fetch(ctx, nbs.stats)
+
+	if err != nil {
+		return err
+	}
+
+	prune := make(map[addr]bool, len(specs))
+	for _, spec := range specs {
+		prune[spec.name] = true
+	}
+
+	if exists {
+		for _, spec := range contents.specs {
+			delete(prune, spec.name)
+		}
+	}
+
+	return pruner.PruneTableFiles(ctx, prune)
+}
+
+// PruneTableFiles deletes the table files in |prune| from the store's directory.  Files which have already been deleted
+// are ignored.
+func (ftp *fsTablePersister) PruneTableFiles(ctx context.Context, prune map[addr]bool) error {
+	for name := range prune {
+		err := os.Remove(filepath.Join(ftp.dir, name.String()))
+
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nbs
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/liquidata-inc/dolt/go/store/chunks"
+	"github.com/liquidata-inc/dolt/go/store/hash"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	testDir := filepath.Join(os.TempDir(), uuid.New().String())
+
+	err := os.MkdirAll(testDir, os.ModePerm)
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	st, err := NewLocalStore(ctx, types.Format_Default.VersionString(), testDir, defaultMemTableSize)
+	require.NoError(t, err)
+
+	vs := types.NewValueStore(st)
+	leafRef, err := vs.WriteValue(ctx, types.String("reachable"))
+	require.NoError(t, err)
+	list, err := types.NewList(ctx, vs, leafRef)
+	require.NoError(t, err)
+	rootRef, err := vs.WriteValue(ctx, list)
+	require.NoError(t, err)
+	extraRef, err := vs.WriteValue(ctx, types.String("extra root"))
+	require.NoError(t, err)
+	garbageRef, err := vs.WriteValue(ctx, types.String("garbage"))
+	require.NoError(t, err)
+
+	last, err := vs.Root(ctx)
+	require.NoError(t, err)
+	ok, err := vs.Commit(ctx, rootRef.TargetHash(), last)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	err = st.CollectGarbage(ctx, hash.NewHashSet(extraRef.TargetHash()))
+	require.NoError(t, err)
+
+	checkStore := func(st *NomsBlockStore) {
+		root, err := st.Root(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, rootRef.TargetHash(), root)
+
+		for _, r := range []types.Ref{leafRef, rootRef, extraRef} {
+			has, err := st.Has(ctx, r.TargetHash())
+			require.NoError(t, err)
+			assert.True(t, has)
+		}
+
+		has, err := st.Has(ctx, garbageRef.TargetHash())
+		require.NoError(t, err)
+		assert.False(t, has)
+	}
+
+	checkStore(st)
+
+	reopened, err := NewLocalStore(ctx, types.Format_Default.VersionString(), testDir, defaultMemTableSize)
+	require.NoError(t, err)
+	checkStore(reopened)
+
+	_, sources, err := reopened.Sources(ctx)
+	require.NoError(t, err)
+
+	fileInfos, err := ioutil.ReadDir(testDir)
+	require.NoError(t, err)
+
+	var tableFiles []string
+	for _, info := range fileInfos {
+		if _, ok := hash.MaybeParse(info.Name()); ok {
+			tableFiles = append(tableFiles, info.Name())
+		}
+	}
+
+	require.Len(t, sources, 1)
+	assert.Equal(t, []string{sources[0].FileID()}, tableFiles)
+}
+
+func TestCollectGarbageWithPendingWrites(t *testing.T) {
+	ctx := context.Background()
+	testDir := filepath.Join(os.TempDir(), uuid.New().String())
+
+	err := os.MkdirAll(testDir, os.ModePerm)
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	st, err := NewLocalStore(ctx, types.Format_Default.VersionString(), testDir, defaultMemTableSize)
+	require.NoError(t, err)
+
+	err = st.Put(ctx, chunks.NewChunk([]byte("pending")))
+	require.NoError(t, err)
+
+	err = st.CollectGarbage(ctx, nil)
+	assert.Equal(t, ErrGCPendingWrites, err)
+}
+
+func TestCollectGarbageKeepsUnreferencedTableFiles(t *testing.T) {
+	ctx := context.Background()
+	testDir := filepath.Join(os.TempDir(), uuid.New().String())
+
+	err := os.MkdirAll(testDir, os.ModePerm)
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	st, err := NewLocalStore(ctx, types.Format_Default.VersionString(), testDir, defaultMemTableSize)
+	require.NoError(t, err)
+
+	vs := types.NewValueStore(st)
+	rootRef, err := vs.WriteValue(ctx, types.String("root"))
+	require.NoError(t, err)
+	_, err = vs.WriteValue(ctx, types.String("garbage"))
+	require.NoError(t, err)
+	last, err := vs.Root(ctx)
+	require.NoError(t, err)
+	ok, err := vs.Commit(ctx, rootRef.TargetHash(), last)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, sources, err := st.Sources(ctx)
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	replaced := sources[0].FileID()
+
+	// a table file written by another process which has not yet been added to the manifest
+	pending := filepath.Join(testDir, hash.Of([]byte("pending")).String())
+	err = ioutil.WriteFile(pending, []byte("pending"), os.ModePerm)
+	require.NoError(t, err)
+
+	err = st.CollectGarbage(ctx, nil)
+	require.NoError(t, err)
+
+	_, err = os.Stat(pending)
+	assert.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(testDir, replaced))
+	assert.True(t, os.IsNotExist(err))
+}
+
+// rootMovingPersister runs |move| after the first table is persisted, standing in for another writer which updates
+// the root of the store while garbage is being collected.
+type rootMovingPersister struct {
+	*fsTablePersister
+	move func() error
+}
+
+func (p *rootMovingPersister) Persist(ctx context.Context, mt *memTable, haver chunkReader, stats *Stats) (chunkSource, error) {
+	cs, err := p.fsTablePersister.Persist(ctx, mt, haver, stats)
+
+	if err == nil && p.move != nil {
+		move := p.move
+		p.move = nil
+		err = move()
+	}
+
+	return cs, err
+}
+
+func TestCollectGarbageRootMoved(t *testing.T) {
+	ctx := context.Background()
+	testDir := filepath.Join(os.TempDir(), uuid.New().String())
+
+	err := os.MkdirAll(testDir, os.ModePerm)
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+
+	st, err := NewLocalStore(ctx, types.Format_Default.VersionString(), testDir, defaultMemTableSize)
+	require.NoError(t, err)
+
+	vs := types.NewValueStore(st)
+	rootRef, err := vs.WriteValue(ctx, types.String("root"))
+	require.NoError(t, err)
+	_, err = vs.WriteValue(ctx, types.String("garbage"))
+	require.NoError(t, err)
+	last, err := vs.Root(ctx)
+	require.NoError(t, err)
+	ok, err := vs.Commit(ctx, rootRef.TargetHash(), last)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	var movedRoot hash.Hash
+	st.p = &rootMovingPersister{
+		fsTablePersister: st.p.(*fsTablePersister),
+		move: func() error {
+			other, err := NewLocalStore(ctx, types.Format_Default.VersionString(), testDir, defaultMemTableSize)
+
+			if err != nil {
+				return err
+			}
+
+			otherVS := types.NewValueStore(other)
+			ref, err := otherVS.WriteValue(ctx, types.String("moved root"))
+
+			if err != nil {
+				return err
+			}
+
+			movedRoot = ref.TargetHash()
+			_, err = otherVS.Commit(ctx, movedRoot, rootRef.TargetHash())
+
+			return err
+		},
+	}
+
+	err = st.CollectGarbage(ctx, nil)
+	assert.Equal(t, ErrGCRootMoved, err)
+
+	reopened, err := NewLocalStore(ctx, types.Format_Default.VersionString(), testDir, defaultMemTableSize)
+	require.NoError(t, err)
+
+	root, err := reopened.Root(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, movedRoot, root)
+
+	_, sources, err := reopened.Sources(ctx)
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+
+	var manifestFiles []string
+	for _, src := range sources {
+		manifestFiles = append(manifestFiles, src.FileID())
+	}
+
+	fileInfos, err := ioutil.ReadDir(testDir)
+	require.NoError(t, err)
+
+	var tableFiles []string
+	for _, info := range fileInfos {
+		if _, ok := hash.MaybeParse(info.Name()); ok {
+			tableFiles = append(tableFiles, info.Name())
+		}
+	}
+
+	// the table written by the collection is deleted, leaving only the tables in the manifest
+	assert.ElementsMatch(t, manifestFiles, tableFiles)
+}
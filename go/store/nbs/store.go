@@ -266,6 +266,14 @@ func NewGCSStore(ctx context.Context, nbfVerStr string, bucketName, path string,
 
 	bucket := gcs.Bucket(bucketName)
 	bs := blobstore.NewGCSBlobstore(bucket, path)
+	return NewBSStore(ctx, nbfVerStr, bs, memTableSize)
+}
+
+// NewBSStore returns a NomsBlockStore whose table files and manifest are stored in the Blobstore given.  Manifest
+// updates are made with CheckAndPut, so concurrent writers to the same Blobstore never lose each other's updates.
+func NewBSStore(ctx context.Context, nbfVerStr string, bs blobstore.Blobstore, memTableSize uint64) (*NomsBlockStore, error) {
+	cacheOnce.Do(makeGlobalCaches)
+
 	mm := makeManifestManager(blobstoreManifest{"manifest", bs})
 
 	p := &blobstorePersister{bs, s3BlockSize, globalIndexCache}
@@ -906,6 +914,19 @@ func (tf NomsBlockStoreTableFile) Open() (io.ReadCloser, error) {
 	return f, nil
 }
 
+// bsTableFile is an implementation of TableFile that is stored in a Blobstore.
+type bsTableFile struct {
+	NomsBlockStoreTableFileInfo
+	ctx context.Context
+	bs  blobstore.Blobstore
+}
+
+// Open returns an io.ReadCloser which can be used to read the bytes of a table file.
+func (tf bsTableFile) Open() (io.ReadCloser, error) {
+	rc, _, err := tf.bs.Get(tf.ctx, tf.FileID(), blobstore.AllRange)
+	return rc, err
+}
+
 // Sources retrieves the current root hash, and a list of all the table files
 func (nbs *NomsBlockStore) Sources(ctx context.Context) (hash.Hash, []TableFile, error) {
 	nbs.mu.Lock()
@@ -922,21 +943,19 @@ func (nbs *NomsBlockStore) Sources(ctx context.Context) (hash.Hash, []TableFile,
 		return hash.Hash{}, nil, nil
 	}
 
-	fsPersister, ok := nbs.p.(*fsTablePersister)
 	numSpecs := contents.NumTableSpecs()
 
 	var tableFiles []TableFile
 	for i := 0; i < numSpecs; i++ {
-		info := contents.GetTableSpecInfo(i)
-
-		if ok {
-			tf := NomsBlockStoreTableFile{
-				NomsBlockStoreTableFileInfo: NomsBlockStoreTableFileInfo{info: info},
-				dir:                         fsPersister.dir,
-			}
-			tableFiles = append(tableFiles, tf)
-		} else {
-			tableFiles = append(tableFiles, NomsBlockStoreTableFileInfo{info: info})
+		info := NomsBlockStoreTableFileInfo{info: contents.GetTableSpecInfo(i)}
+
+		switch p := nbs.p.(type) {
+		case *fsTablePersister:
+			tableFiles = append(tableFiles, NomsBlockStoreTableFile{NomsBlockStoreTableFileInfo: info, dir: p.dir})
+		case *blobstorePersister:
+			tableFiles = append(tableFiles, bsTableFile{NomsBlockStoreTableFileInfo: info, ctx: ctx, bs: p.bs})
+		default:
+			tableFiles = append(tableFiles, info)
 		}
 	}
 
@@ -945,46 +964,48 @@ func (nbs *NomsBlockStore) Sources(ctx context.Context) (hash.Hash, []TableFile,
 
 // WriteTableFile will read a table file from the provided reader and write it to the TableFileStore
 func (nbs *NomsBlockStore) WriteTableFile(ctx context.Context, fileId string, numChunks int, rd io.Reader, contentLength uint64, contentHash []byte) error {
-	fsPersister, ok := nbs.p.(*fsTablePersister)
-
-	if !ok {
+	var err error
+	switch p := nbs.p.(type) {
+	case *fsTablePersister:
+		err = writeTableFileToDir(p.dir, fileId, rd)
+	case *blobstorePersister:
+		_, err = p.bs.Put(ctx, fileId, rd)
+	default:
 		return errors.New("Not implemented")
 	}
 
-	path := filepath.Join(fsPersister.dir, fileId)
-
-	err := func() (err error) {
-		var f *os.File
-		f, err = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.ModePerm)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return err
-		}
+	fileIdHash, ok := hash.MaybeParse(fileId)
 
-		defer func() {
-			closeErr := f.Close()
+	if !ok {
+		return errors.New("invalid base32 encoded hash: " + fileId)
+	}
 
-			if err == nil {
-				err = closeErr
-			}
-		}()
+	_, err = nbs.UpdateManifest(ctx, map[hash.Hash]uint32{fileIdHash: uint32(numChunks)})
 
-		_, err = io.Copy(f, rd)
+	return err
+}
 
-		return err
-	}()
+func writeTableFileToDir(dir, fileId string, rd io.Reader) (err error) {
+	path := filepath.Join(dir, fileId)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.ModePerm)
 
 	if err != nil {
 		return err
 	}
 
-	fileIdHash, ok := hash.MaybeParse(fileId)
+	defer func() {
+		closeErr := f.Close()
 
-	if !ok {
-		return errors.New("invalid base32 encoded hash: " + fileId)
-	}
+		if err == nil {
+			err = closeErr
+		}
+	}()
 
-	_, err = nbs.UpdateManifest(ctx, map[hash.Hash]uint32{fileIdHash: uint32(numChunks)})
+	_, err = io.Copy(f, rd)
 
 	return err
 }
@@ -290,6 +290,13 @@ func (lvs *ValueStore) ReadManyValues(ctx context.Context, hashes hash.HashSlice
 // an appropriately-typed types.Ref. v is not guaranteed to be actually
 // written until after Flush().
 func (lvs *ValueStore) WriteValue(ctx context.Context, v Value) (Ref, error) {
+	return lvs.WriteValueWithAbsentRefs(ctx, v, nil)
+}
+
+// WriteValueWithAbsentRefs is like WriteValue, except that the values v
+// refers to whose hashes are in |absent| aren't required to be in the
+// ChunkStore when lvs is committed.  All of the other values v refers to are.
+func (lvs *ValueStore) WriteValueWithAbsentRefs(ctx context.Context, v Value, absent hash.HashSet) (Ref, error) {
 	lvs.versOnce.Do(lvs.expectVersion)
 	d.PanicIfFalse(v != nil)
 
@@ -323,7 +330,7 @@ func (lvs *ValueStore) WriteValue(ctx context.Context, v Value) (Ref, error) {
 		return Ref{}, err
 	}
 
-	lvs.bufferChunk(ctx, v, c, height)
+	lvs.bufferChunk(ctx, v, c, height, absent)
 	return r, nil
 }
 
@@ -337,7 +344,7 @@ func (lvs *ValueStore) WriteValue(ctx context.Context, v Value) (Ref, error) {
 //    flushed).
 // 2. The total data occupied by buffered chunks does not exceed
 //    lvs.bufferedChunksMax
-func (lvs *ValueStore) bufferChunk(ctx context.Context, v Value, c chunks.Chunk, height uint64) {
+func (lvs *ValueStore) bufferChunk(ctx context.Context, v Value, c chunks.Chunk, height uint64, absent hash.HashSet) {
 	lvs.bufferMu.Lock()
 	defer lvs.bufferMu.Unlock()
 
@@ -391,7 +398,7 @@ func (lvs *ValueStore) bufferChunk(ctx context.Context, v Value, c chunks.Chunk,
 			childHash := childRef.TargetHash()
 			if _, isBuffered := lvs.bufferedChunks[childHash]; isBuffered {
 				lvs.withBufferedChildren[h] = height
-			} else if lvs.enforceCompleteness {
+			} else if lvs.enforceCompleteness && !absent.Has(childHash) {
 				// If the childRef isn't presently buffered, we must consider it an
 				// unresolved ref.
 				lvs.unresolvedRefs.Insert(childHash)
@@ -0,0 +1,198 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package s3test provides an in-process fake of an S3 compatible object store for tests.
+package s3test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// Server is an HTTP server implementing the subset of the S3 REST API used by blobstore.S3Blobstore: GET, HEAD and
+// PUT requests for objects addressed path-style, with Range, If-Match and If-None-Match headers.  Buckets don't need
+// to be created, and requests aren't authenticated.
+type Server struct {
+	*httptest.Server
+
+	// IgnoreConditions makes the server ignore the If-Match and If-None-Match headers of PUT requests, as some S3
+	// compatible object stores do.  It should be set before the server is used.
+	IgnoreConditions bool
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	etags   map[string]string
+}
+
+// NewServer starts and returns a new Server, which should be closed once it's no longer needed.
+func NewServer() *Server {
+	s := &Server{objects: make(map[string][]byte), etags: make(map[string]string)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// NewClient returns an S3 client for the server.
+func (s *Server) NewClient() *s3.S3 {
+	sess := session.Must(session.NewSession(aws.NewConfig().
+		WithEndpoint(s.URL).
+		WithRegion("us-east-1").
+		WithS3ForcePathStyle(true).
+		WithCredentials(credentials.NewStaticCredentials("id", "secret", ""))))
+
+	return s3.New(sess)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+
+	if strings.Index(path, "/") == -1 {
+		writeError(w, http.StatusNotImplemented, "NotImplemented", "bucket operations are not supported")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.getObject(w, r, path)
+	case http.MethodPut:
+		s.putObject(w, r, path)
+	default:
+		writeError(w, http.StatusNotImplemented, "NotImplemented", r.Method+" is not supported")
+	}
+}
+
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, path string) {
+	data, ok := s.objects[path]
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	status := http.StatusOK
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		start, end, ok := parseRange(rangeHeader, int64(len(data)))
+
+		if !ok {
+			writeError(w, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable")
+			return
+		}
+
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, len(data)))
+		data = data[start:end]
+		status = http.StatusPartialContent
+	}
+
+	w.Header().Set("ETag", s.etags[path])
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+// parseRange returns the start and exclusive end of a single range header of the forms bytes=a-b, bytes=a- and
+// bytes=-n.
+func parseRange(rangeHeader string, size int64) (start, end int64, ok bool) {
+	parts := strings.SplitN(strings.TrimPrefix(rangeHeader, "bytes="), "-", 2)
+
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	var err error
+	if parts[0] == "" {
+		suffix, err := strconv.ParseInt(parts[1], 10, 64)
+
+		if err != nil {
+			return 0, 0, false
+		}
+
+		if suffix > size {
+			suffix = size
+		}
+
+		return size - suffix, size, true
+	}
+
+	if start, err = strconv.ParseInt(parts[0], 10, 64); err != nil || start >= size {
+		return 0, 0, false
+	}
+
+	end = size
+	if parts[1] != "" {
+		last, err := strconv.ParseInt(parts[1], 10, 64)
+
+		if err != nil || last < start {
+			return 0, 0, false
+		}
+
+		if last+1 < size {
+			end = last + 1
+		}
+	}
+
+	return start, end, true
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, path string) {
+	data, err := ioutil.ReadAll(r.Body)
+
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+
+	if !s.IgnoreConditions {
+		etag, exists := s.etags[path]
+
+		if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && (!exists || ifMatch != etag) {
+			writeError(w, http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+			return
+		}
+
+		if r.Header.Get("If-None-Match") == "*" && exists {
+			writeError(w, http.StatusPreconditionFailed, "PreconditionFailed", "At least one of the pre-conditions you specified did not hold")
+			return
+		}
+	}
+
+	sum := md5.Sum(data)
+	s.objects[path] = data
+	s.etags[path] = `"` + hex.EncodeToString(sum[:]) + `"`
+
+	w.Header().Set("ETag", s.etags[path])
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, message)
+}
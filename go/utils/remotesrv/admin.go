@@ -0,0 +1,98 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	remotesapi "github.com/liquidata-inc/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+// RepoAdmin implements the RepoAdminService, which creates, deletes and lists the repositories of the server.
+type RepoAdmin struct {
+	csCache *DBCache
+}
+
+func NewRepoAdmin(csCache *DBCache) *RepoAdmin {
+	return &RepoAdmin{csCache}
+}
+
+func (ra *RepoAdmin) CreateRepo(ctx context.Context, req *remotesapi.CreateRepoRequest) (*remotesapi.CreateRepoResponse, error) {
+	org, repoName := req.GetRepoId().GetOrg(), req.GetRepoId().GetRepoName()
+	setRepoInfo(ctx, org, repoName)
+
+	nbfVerStr := req.GetRepoFormat().GetNbfVersion()
+	if nbfVerStr == "" {
+		nbfVerStr = types.Format_Default.VersionString()
+	}
+
+	err := ra.csCache.Create(org, repoName, nbfVerStr)
+
+	if err != nil {
+		return nil, repoAdminError("create", org, repoName, err)
+	}
+
+	logrus.WithFields(logrus.Fields{"org": org, "repo": repoName}).Info("repository created")
+	return &remotesapi.CreateRepoResponse{}, nil
+}
+
+func (ra *RepoAdmin) DeleteRepo(ctx context.Context, req *remotesapi.DeleteRepoRequest) (*remotesapi.DeleteRepoResponse, error) {
+	org, repoName := req.GetRepoId().GetOrg(), req.GetRepoId().GetRepoName()
+	setRepoInfo(ctx, org, repoName)
+
+	err := ra.csCache.Delete(org, repoName)
+
+	if err != nil {
+		return nil, repoAdminError("delete", org, repoName, err)
+	}
+
+	logrus.WithFields(logrus.Fields{"org": org, "repo": repoName}).Info("repository deleted")
+	return &remotesapi.DeleteRepoResponse{}, nil
+}
+
+func (ra *RepoAdmin) ListRepos(ctx context.Context, req *remotesapi.ListReposRequest) (*remotesapi.ListReposResponse, error) {
+	repoIds, err := ra.csCache.List(req.Org)
+
+	if err != nil {
+		logrus.Errorf("failed to list repositories: %v", err)
+		return nil, status.Error(codes.Internal, "failed to list repositories")
+	}
+
+	return &remotesapi.ListReposResponse{RepoIds: repoIds}, nil
+}
+
+func setRepoInfo(ctx context.Context, org, repoName string) {
+	reqInfo := getRequestInfo(ctx)
+	reqInfo.org, reqInfo.repo = org, repoName
+}
+
+func repoAdminError(op, org, repoName string, err error) error {
+	switch err {
+	case ErrRepoExists:
+		return status.Errorf(codes.AlreadyExists, "repository %s/%s already exists", org, repoName)
+	case ErrRepoNotFound:
+		return status.Errorf(codes.NotFound, "repository %s/%s not found", org, repoName)
+	case ErrInvalidRepoName:
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		logrus.Errorf("failed to %s repository %s/%s: %v", op, org, repoName, err)
+		return status.Errorf(codes.Internal, "failed to %s repository", op)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	remotesapi "github.com/liquidata-inc/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+)
+
+func TestRepoAdmin(t *testing.T) {
+	ctx := context.Background()
+	cache, dir := newTestCSCache(t)
+	defer os.RemoveAll(dir)
+	defer cache.Close()
+	admin := NewRepoAdmin(cache)
+
+	repoId := func(org, repo string) *remotesapi.RepoId {
+		return &remotesapi.RepoId{Org: org, RepoName: repo}
+	}
+
+	_, err := admin.CreateRepo(ctx, &remotesapi.CreateRepoRequest{RepoId: repoId("org", "repo")})
+	require.NoError(t, err)
+	_, err = admin.CreateRepo(ctx, &remotesapi.CreateRepoRequest{RepoId: repoId("org", "repo")})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	_, err = admin.CreateRepo(ctx, &remotesapi.CreateRepoRequest{RepoId: repoId("..", "repo")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = admin.CreateRepo(ctx, &remotesapi.CreateRepoRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	resp, err := admin.ListRepos(ctx, &remotesapi.ListReposRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []*remotesapi.RepoId{repoId("org", "repo")}, resp.RepoIds)
+
+	_, err = admin.DeleteRepo(ctx, &remotesapi.DeleteRepoRequest{RepoId: repoId("org", "missing")})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = admin.DeleteRepo(ctx, &remotesapi.DeleteRepoRequest{RepoId: repoId("org", "repo")})
+	require.NoError(t, err)
+
+	resp, err = admin.ListRepos(ctx, &remotesapi.ListReposRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.RepoIds)
+}
+
+func TestReadOfUnknownRepo(t *testing.T) {
+	ctx := context.Background()
+	cache, dir := newTestCSCache(t)
+	defer os.RemoveAll(dir)
+	defer cache.Close()
+
+	signer, err := newURLSigner(time.Minute)
+	require.NoError(t, err)
+	rs := NewHttpFSBackedChunkStore("http", "localhost", cache, signer)
+	unknown := &remotesapi.RepoId{Org: "org", RepoName: "unknown"}
+
+	_, err = rs.GetRepoMetadata(ctx, &remotesapi.GetRepoMetadataRequest{RepoId: unknown, ClientRepoFormat: &remotesapi.ClientRepoFormat{}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = rs.Root(ctx, &remotesapi.RootRequest{RepoId: unknown})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = rs.HasChunks(ctx, &remotesapi.HasChunksRequest{RepoId: unknown})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = rs.Root(ctx, &remotesapi.RootRequest{RepoId: &remotesapi.RepoId{Org: "..", RepoName: "unknown"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the reads didn't create the repository
+	exists, _ := cache.fs.Exists(filepath.Join(dir, "org"))
+	assert.False(t, exists)
+	repoIds, err := cache.List("")
+	require.NoError(t, err)
+	assert.Empty(t, repoIds)
+}
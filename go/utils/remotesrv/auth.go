@@ -0,0 +1,300 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	remotesapi "github.com/liquidata-inc/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/creds"
+)
+
+// Permission is the level of access a request needs to a repository
+type Permission int
+
+const (
+	// ReadPerm is needed to fetch and clone a repository
+	ReadPerm Permission = iota
+
+	// WritePerm is needed to push to a repository.  It implies ReadPerm.
+	WritePerm
+)
+
+// String returns the name of the permission as used in auth config files
+func (p Permission) String() string {
+	if p == WritePerm {
+		return "write"
+	}
+
+	return "read"
+}
+
+// anyUser can be used in the user lists of an auth config file to grant a permission to every user, including
+// anonymous users who don't send credentials.
+const anyUser = "*"
+
+var ErrUnauthenticated = errors.New("invalid or missing credentials")
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Authorizer decides whether the sender of a request may access a repository.
+type Authorizer interface {
+	// Authorize returns the name of the user who sent the bearer token given, or the empty string for anonymous
+	// requests which have no token, if they have the permission given for the repository org/repo.  Otherwise
+	// ErrUnauthenticated or ErrPermissionDenied is returned.
+	Authorize(token, org, repo string, perm Permission) (string, error)
+
+	// AuthorizeAdmin returns the name of the user who sent the bearer token given if they're allowed to manage the
+	// repositories of the server.  Otherwise ErrUnauthenticated or ErrPermissionDenied is returned.
+	AuthorizeAdmin(token string) (string, error)
+}
+
+// allowAllAuth is the Authorizer used when no auth config is given, which allows every request.
+type allowAllAuth struct{}
+
+func (allowAllAuth) Authorize(token, org, repo string, perm Permission) (string, error) {
+	return "", nil
+}
+
+func (allowAllAuth) AuthorizeAdmin(token string) (string, error) {
+	return "", nil
+}
+
+// repoPermissions lists the users who can read and write a repository
+type repoPermissions struct {
+	Read  []string `json:"read"`
+	Write []string `json:"write"`
+}
+
+func (rp repoPermissions) allows(user string, perm Permission) bool {
+	if perm == ReadPerm && containsUser(rp.Read, user) {
+		return true
+	}
+
+	return containsUser(rp.Write, user)
+}
+
+func containsUser(users []string, user string) bool {
+	for _, u := range users {
+		if u == anyUser || (user != "" && u == user) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authConfig is the format of auth config files.  Users maps user names to the base32 encoded public keys of their
+// dolt credentials, as printed by dolt creds ls.  Repos maps repositories to the users who can access them.
+// Repositories are named org/repo, and entries named org/* and * are used for repositories without their own entry.
+// Admins lists the users who can create, delete and list repositories.
+type authConfig struct {
+	Users  map[string]string          `json:"users"`
+	Repos  map[string]repoPermissions `json:"repos"`
+	Admins []string                   `json:"admins"`
+}
+
+// configAuth is an Authorizer which authenticates users with the bearer tokens sent by dolt clients, and checks their
+// permissions with an auth config file.
+type configAuth struct {
+	kidToUser   map[string]string
+	kidToPubKey map[string][]byte
+	repos       map[string]repoPermissions
+	admins      []string
+}
+
+// loadConfigAuth reads an auth config file and returns an Authorizer for it.
+func loadConfigAuth(path string) (*configAuth, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg authConfig
+	err = json.Unmarshal(data, &cfg)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auth config %s: %v", path, err)
+	}
+
+	return newConfigAuth(cfg)
+}
+
+func newConfigAuth(cfg authConfig) (*configAuth, error) {
+	auth := &configAuth{make(map[string]string), make(map[string][]byte), cfg.Repos, cfg.Admins}
+
+	for user, pubKeyStr := range cfg.Users {
+		if user == anyUser {
+			return nil, fmt.Errorf("'%s' can't be used as a user name", anyUser)
+		}
+
+		pubKey, err := creds.B32CredsEncoding.DecodeString(pubKeyStr)
+
+		if err != nil || len(pubKeyStr) != creds.B32EncodedPubKeyLen {
+			return nil, fmt.Errorf("invalid public key for user %s", user)
+		}
+
+		kid := creds.PubKeyToKIDStr(pubKey)
+		auth.kidToUser[kid] = user
+		auth.kidToPubKey[kid] = pubKey
+	}
+
+	return auth, nil
+}
+
+func (auth *configAuth) Authorize(token, org, repo string, perm Permission) (string, error) {
+	user, err := auth.authenticate(token)
+
+	if err != nil {
+		return "", err
+	}
+
+	if auth.repoPermissions(org, repo).allows(user, perm) {
+		return user, nil
+	} else if user == "" {
+		return "", ErrUnauthenticated
+	}
+
+	return user, ErrPermissionDenied
+}
+
+func (auth *configAuth) AuthorizeAdmin(token string) (string, error) {
+	user, err := auth.authenticate(token)
+
+	if err != nil {
+		return "", err
+	} else if user == "" {
+		return "", ErrUnauthenticated
+	}
+
+	for _, admin := range auth.admins {
+		if admin == user {
+			return user, nil
+		}
+	}
+
+	return user, ErrPermissionDenied
+}
+
+// authenticate returns the name of the user who sent a bearer token, or the empty string if there's no token.
+func (auth *configAuth) authenticate(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+
+	kid, err := creds.VerifyBearerToken(token, func(kid string) ([]byte, bool) {
+		pubKey, ok := auth.kidToPubKey[kid]
+		return pubKey, ok
+	})
+
+	if err != nil {
+		return "", ErrUnauthenticated
+	}
+
+	return auth.kidToUser[kid], nil
+}
+
+func (auth *configAuth) repoPermissions(org, repo string) repoPermissions {
+	for _, name := range []string{org + "/" + repo, org + "/*", "*"} {
+		if rp, ok := auth.repos[name]; ok {
+			return rp
+		}
+	}
+
+	return repoPermissions{}
+}
+
+// requiredPermission returns the permission needed to make a ChunkStoreService request
+func requiredPermission(req interface{}) Permission {
+	switch req.(type) {
+	case *remotesapi.GetUploadLocsRequest, *remotesapi.CommitRequest, *remotesapi.AddTableFilesRequest:
+		return WritePerm
+	default:
+		return ReadPerm
+	}
+}
+
+type repoRequest interface {
+	GetRepoId() *remotesapi.RepoId
+}
+
+// bearerToken returns the token from the authorization metadata of a grpc request, or the empty string if it has none
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+
+	if !ok {
+		return ""
+	}
+
+	for _, val := range md.Get("authorization") {
+		if strings.HasPrefix(val, "Bearer ") {
+			return strings.TrimPrefix(val, "Bearer ")
+		}
+	}
+
+	return ""
+}
+
+// authInterceptor returns a grpc interceptor which checks every request with the Authorizer given before handling it.
+func authInterceptor(auth Authorizer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		reqInfo := getRequestInfo(ctx)
+
+		var user string
+		var err error
+		var denied string
+		switch req.(type) {
+		case *remotesapi.CreateRepoRequest, *remotesapi.DeleteRepoRequest, *remotesapi.ListReposRequest:
+			user, err = auth.AuthorizeAdmin(bearerToken(ctx))
+			denied = fmt.Sprintf("user %s is not an admin", user)
+		default:
+			repoReq, ok := req.(repoRequest)
+
+			if !ok || repoReq.GetRepoId() == nil {
+				return nil, status.Error(codes.InvalidArgument, "request has no repo id")
+			}
+
+			repoId := repoReq.GetRepoId()
+			perm := requiredPermission(req)
+			reqInfo.org, reqInfo.repo = repoId.Org, repoId.RepoName
+			user, err = auth.Authorize(bearerToken(ctx), repoId.Org, repoId.RepoName, perm)
+			denied = fmt.Sprintf("user %s does not have %s permission for %s/%s", user, perm, repoId.Org, repoId.RepoName)
+		}
+
+		reqInfo.user = user
+
+		switch err {
+		case nil:
+		case ErrUnauthenticated:
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case ErrPermissionDenied:
+			return nil, status.Error(codes.PermissionDenied, denied)
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		return handler(ctx, req)
+	}
+}
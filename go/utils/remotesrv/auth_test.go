@@ -0,0 +1,245 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ed25519"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	remotesapi "github.com/liquidata-inc/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/creds"
+)
+
+// testUsers are the credentials of the users in the test auth config
+type testUsers struct {
+	reader, writer, admin, unknown creds.DoltCreds
+}
+
+func newTestAuth(t *testing.T) (*configAuth, testUsers) {
+	var users testUsers
+	for _, dc := range []*creds.DoltCreds{&users.reader, &users.writer, &users.admin, &users.unknown} {
+		var err error
+		*dc, err = creds.GenerateCredentials()
+		require.NoError(t, err)
+	}
+
+	auth, err := newConfigAuth(authConfig{
+		Users: map[string]string{
+			"reader": users.reader.PubKeyBase32Str(),
+			"writer": users.writer.PubKeyBase32Str(),
+			"admin":  users.admin.PubKeyBase32Str(),
+		},
+		Repos: map[string]repoPermissions{
+			"org/private": {Read: []string{"reader"}, Write: []string{"writer"}},
+			"org/public":  {Read: []string{anyUser}, Write: []string{"writer"}},
+			"org/*":       {Read: []string{"reader", "writer"}},
+			"*":           {Write: []string{"admin"}},
+		},
+		Admins: []string{"admin"},
+	})
+	require.NoError(t, err)
+
+	return auth, users
+}
+
+// testToken returns a bearer token for the credentials given, which is signed with the algorithm and expires at the
+// time given.
+func testToken(t *testing.T, dc creds.DoltCreds, alg jose.SignatureAlgorithm, expiry time.Time) string {
+	var key interface{} = ed25519.PrivateKey(dc.PrivKey)
+	if alg == jose.HS256 {
+		// a token signed with the public key as an hmac secret, which must not be accepted
+		key = dc.PubKey
+	}
+
+	kid := dc.KeyIDBase32Str()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, &jose.SignerOptions{
+		ExtraHeaders: map[jose.HeaderKey]interface{}{creds.JWTKIDHeader: kid},
+	})
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(jwt.Claims{
+		Audience: []string{creds.RemoteAPIAudience},
+		Issuer:   creds.ClientIssuer,
+		Subject:  "doltClientCredentials/" + kid,
+		Expiry:   jwt.NewNumericDate(expiry),
+	}).CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func validToken(t *testing.T, dc creds.DoltCreds) string {
+	md, err := dc.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	return strings.TrimPrefix(md["authorization"], "Bearer ")
+}
+
+// tamperedToken returns a valid token for the credentials given with its signature changed
+func tamperedToken(t *testing.T, dc creds.DoltCreds) string {
+	token := validToken(t, dc)
+	sigStart := strings.LastIndex(token, ".") + 1
+	sig := []byte(token[sigStart:])
+
+	if sig[0] == 'A' {
+		sig[0] = 'B'
+	} else {
+		sig[0] = 'A'
+	}
+
+	return token[:sigStart] + string(sig)
+}
+
+func TestConfigAuthAuthorize(t *testing.T) {
+	auth, users := newTestAuth(t)
+
+	tests := []struct {
+		name         string
+		token        string
+		org, repo    string
+		perm         Permission
+		expectedUser string
+		expectedErr  error
+	}{
+		{"reader can read", validToken(t, users.reader), "org", "private", ReadPerm, "reader", nil},
+		{"reader can't write", validToken(t, users.reader), "org", "private", WritePerm, "reader", ErrPermissionDenied},
+		{"writer can write", validToken(t, users.writer), "org", "private", WritePerm, "writer", nil},
+		{"write implies read", validToken(t, users.writer), "org", "private", ReadPerm, "writer", nil},
+		{"admin has no permission for repo", validToken(t, users.admin), "org", "private", ReadPerm, "admin", ErrPermissionDenied},
+		{"anonymous read of public repo", "", "org", "public", ReadPerm, "", nil},
+		{"any user can read public repo", validToken(t, users.admin), "org", "public", ReadPerm, "admin", nil},
+		{"anonymous write of public repo", "", "org", "public", WritePerm, "", ErrUnauthenticated},
+		{"anonymous read of private repo", "", "org", "private", ReadPerm, "", ErrUnauthenticated},
+		{"org entry is used for repo without one", validToken(t, users.writer), "org", "other", ReadPerm, "writer", nil},
+		{"org entry permissions", validToken(t, users.writer), "org", "other", WritePerm, "writer", ErrPermissionDenied},
+		{"default entry is used for org without one", validToken(t, users.admin), "other", "repo", WritePerm, "admin", nil},
+		{"default entry permissions", validToken(t, users.reader), "other", "repo", ReadPerm, "reader", ErrPermissionDenied},
+		{"unknown user", validToken(t, users.unknown), "org", "public", ReadPerm, "", ErrUnauthenticated},
+		{"expired token", testToken(t, users.reader, jose.EdDSA, time.Now().Add(-time.Minute)), "org", "private", ReadPerm, "", ErrUnauthenticated},
+		{"unexpired token", testToken(t, users.reader, jose.EdDSA, time.Now().Add(time.Minute)), "org", "private", ReadPerm, "reader", nil},
+		{"bad signature", tamperedToken(t, users.reader), "org", "private", ReadPerm, "", ErrUnauthenticated},
+		{"bad algorithm", testToken(t, users.reader, jose.HS256, time.Now().Add(time.Minute)), "org", "private", ReadPerm, "", ErrUnauthenticated},
+		{"not a token", "not a token", "org", "public", ReadPerm, "", ErrUnauthenticated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user, err := auth.Authorize(test.token, test.org, test.repo, test.perm)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedUser, user)
+		})
+	}
+}
+
+func TestConfigAuthAuthorizeAdmin(t *testing.T) {
+	auth, users := newTestAuth(t)
+
+	tests := []struct {
+		name         string
+		token        string
+		expectedUser string
+		expectedErr  error
+	}{
+		{"admin", validToken(t, users.admin), "admin", nil},
+		{"not an admin", validToken(t, users.writer), "writer", ErrPermissionDenied},
+		{"anonymous", "", "", ErrUnauthenticated},
+		{"unknown user", validToken(t, users.unknown), "", ErrUnauthenticated},
+		{"expired token", testToken(t, users.admin, jose.EdDSA, time.Now().Add(-time.Minute)), "", ErrUnauthenticated},
+		{"bad signature", tamperedToken(t, users.admin), "", ErrUnauthenticated},
+		{"bad algorithm", testToken(t, users.admin, jose.HS256, time.Now().Add(time.Minute)), "", ErrUnauthenticated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user, err := auth.AuthorizeAdmin(test.token)
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.expectedUser, user)
+		})
+	}
+}
+
+func TestNewConfigAuth(t *testing.T) {
+	dc, err := creds.GenerateCredentials()
+	require.NoError(t, err)
+
+	_, err = newConfigAuth(authConfig{Users: map[string]string{anyUser: dc.PubKeyBase32Str()}})
+	assert.Error(t, err)
+
+	_, err = newConfigAuth(authConfig{Users: map[string]string{"user": dc.PubKeyBase32Str()[1:]}})
+	assert.Error(t, err)
+
+	_, err = newConfigAuth(authConfig{Users: map[string]string{"user": "not a key"}})
+	assert.Error(t, err)
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	auth, users := newTestAuth(t)
+	interceptor := authInterceptor(auth)
+	private := &remotesapi.RepoId{Org: "org", RepoName: "private"}
+
+	tests := []struct {
+		name         string
+		token        string
+		req          interface{}
+		expectedCode codes.Code
+	}{
+		{"read request", validToken(t, users.reader), &remotesapi.GetRepoMetadataRequest{RepoId: private}, codes.OK},
+		{"write request", validToken(t, users.writer), &remotesapi.CommitRequest{RepoId: private}, codes.OK},
+		{"write request without write permission", validToken(t, users.reader), &remotesapi.CommitRequest{RepoId: private}, codes.PermissionDenied},
+		{"upload locations need write permission", validToken(t, users.reader), &remotesapi.GetUploadLocsRequest{RepoId: private}, codes.PermissionDenied},
+		{"adding table files needs write permission", validToken(t, users.reader), &remotesapi.AddTableFilesRequest{RepoId: private}, codes.PermissionDenied},
+		{"download locations need read permission", validToken(t, users.admin), &remotesapi.GetDownloadLocsRequest{RepoId: private}, codes.PermissionDenied},
+		{"anonymous read request", "", &remotesapi.RootRequest{RepoId: private}, codes.Unauthenticated},
+		{"anonymous read of public repo", "", &remotesapi.RootRequest{RepoId: &remotesapi.RepoId{Org: "org", RepoName: "public"}}, codes.OK},
+		{"expired token", testToken(t, users.reader, jose.EdDSA, time.Now().Add(-time.Minute)), &remotesapi.RootRequest{RepoId: private}, codes.Unauthenticated},
+		{"request without repo id", validToken(t, users.reader), &remotesapi.RootRequest{}, codes.InvalidArgument},
+		{"create repo as admin", validToken(t, users.admin), &remotesapi.CreateRepoRequest{}, codes.OK},
+		{"create repo as user", validToken(t, users.writer), &remotesapi.CreateRepoRequest{}, codes.PermissionDenied},
+		{"delete repo as user", validToken(t, users.writer), &remotesapi.DeleteRepoRequest{}, codes.PermissionDenied},
+		{"list repos as user", validToken(t, users.reader), &remotesapi.ListReposRequest{}, codes.PermissionDenied},
+		{"list repos anonymously", "", &remotesapi.ListReposRequest{}, codes.Unauthenticated},
+		{"delete repo with bad signature", tamperedToken(t, users.admin), &remotesapi.DeleteRepoRequest{}, codes.Unauthenticated},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+test.token))
+			}
+
+			handled := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				handled = true
+				return req, nil
+			}
+
+			_, err := interceptor(ctx, test.req, &grpc.UnaryServerInfo{}, handler)
+			assert.Equal(t, test.expectedCode, status.Code(err))
+			assert.Equal(t, test.expectedCode == codes.OK, handled)
+		})
+	}
+}
@@ -16,9 +16,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"sync"
 
+	remotesapi "github.com/liquidata-inc/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
 	"github.com/liquidata-inc/dolt/go/store/nbs"
 )
@@ -27,47 +31,297 @@ const (
 	defaultMemTableSize = 128 * 1024 * 1024
 )
 
+var ErrRepoNotFound = errors.New("repository not found")
+var ErrRepoExists = errors.New("repository already exists")
+var ErrInvalidRepoName = errors.New("invalid org or repository name")
+
+var validNameRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+// DBCache holds the stores of the repositories in a data directory, which are stored at <org>/<repo>.  Repositories
+// must be created with Create before they can be used.
 type DBCache struct {
 	mu  *sync.Mutex
-	dbs map[string]*nbs.NomsBlockStore
+	dbs map[string]*cachedStore
+
+	// deleting holds the ids of the repositories being deleted, which can't be used while they're waiting for the
+	// requests using their stores to finish.
+	deleting map[string]bool
 
 	fs filesys.Filesys
 }
 
+// cachedStore is an open store, and the number of requests using it.
+type cachedStore struct {
+	cs   *nbs.NomsBlockStore
+	refs int
+
+	// idle is set by Delete while the store is in use, and is closed when the last request using it releases it.
+	idle chan struct{}
+}
+
+// NewLocalCSCache returns a DBCache for the repositories in the working directory of the filesys given.
 func NewLocalCSCache(filesys filesys.Filesys) *DBCache {
 	return &DBCache{
 		&sync.Mutex{},
-		make(map[string]*nbs.NomsBlockStore),
+		make(map[string]*cachedStore),
+		make(map[string]bool),
 		filesys,
 	}
 }
 
-func (cache *DBCache) Get(org, repo, nbfVerStr string) (*nbs.NomsBlockStore, error) {
+// repoDir returns the absolute path of the directory of a repository
+func (cache *DBCache) repoDir(org, repo string) (string, error) {
+	if !validNameRegex.MatchString(org) || !validNameRegex.MatchString(repo) {
+		return "", ErrInvalidRepoName
+	}
+
+	return cache.fs.Abs(filepath.Join(org, repo))
+}
+
+// Get returns the store of an existing repository, or ErrRepoNotFound.  nbfVerStr is the format of the store if the
+// repository has never been written to, and isn't already open.  The release function returned must be called once
+// the caller is done with the store, as the store isn't closed by Delete until then.
+func (cache *DBCache) Get(org, repo, nbfVerStr string) (*nbs.NomsBlockStore, func(), error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	id := filepath.Join(org, repo)
+
+	if cache.deleting[id] {
+		return nil, nil, ErrRepoNotFound
+	}
+
+	entry, ok := cache.dbs[id]
+
+	if !ok {
+		dir, err := cache.repoDir(org, repo)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if exists, isDir := cache.fs.Exists(dir); !exists || !isDir {
+			return nil, nil, ErrRepoNotFound
+		}
+
+		newCS, err := nbs.NewLocalStore(context.TODO(), nbfVerStr, dir, defaultMemTableSize)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		entry = &cachedStore{cs: newCS}
+		cache.dbs[id] = entry
+	}
+
+	entry.refs++
+	return entry.cs, func() { cache.release(entry) }, nil
+}
+
+func (cache *DBCache) release(entry *cachedStore) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	entry.refs--
+
+	if entry.refs == 0 && entry.idle != nil {
+		close(entry.idle)
+	}
+}
+
+// Create creates an empty repository, or returns ErrRepoExists.
+func (cache *DBCache) Create(org, repo, nbfVerStr string) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	dir, err := cache.repoDir(org, repo)
+
+	if err != nil {
+		return err
+	}
+
+	if exists, _ := cache.fs.Exists(dir); exists || cache.deleting[filepath.Join(org, repo)] {
+		return ErrRepoExists
+	}
+
+	err = cache.fs.MkDirs(dir)
+
+	if err != nil {
+		return err
+	}
+
+	newCS, err := nbs.NewLocalStore(context.TODO(), nbfVerStr, dir, defaultMemTableSize)
+
+	if err != nil {
+		return err
+	}
+
+	cache.dbs[filepath.Join(org, repo)] = &cachedStore{cs: newCS}
+
+	return nil
+}
+
+// Delete closes the store of a repository and deletes all of its data, or returns ErrRepoNotFound.  New requests for
+// the repository fail with ErrRepoNotFound as soon as Delete is called, and Delete waits for the requests already
+// using its store to release it before closing it.
+func (cache *DBCache) Delete(org, repo string) error {
+	id := filepath.Join(org, repo)
+	entry, err := cache.startDelete(org, repo)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		delete(cache.deleting, id)
+	}()
+
+	if entry != nil {
+		if entry.idle != nil {
+			<-entry.idle
+		}
+
+		err = entry.cs.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	dir, err := cache.repoDir(org, repo)
+
+	if err != nil {
+		return err
+	}
+
+	return cache.fs.Delete(dir, true)
+}
+
+// startDelete marks a repository as being deleted and removes its store from the cache, returning the store if it's
+// open.
+func (cache *DBCache) startDelete(org, repo string) (*cachedStore, error) {
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
 
+	dir, err := cache.repoDir(org, repo)
+
+	if err != nil {
+		return nil, err
+	}
+
 	id := filepath.Join(org, repo)
+	if exists, isDir := cache.fs.Exists(dir); !exists || !isDir || cache.deleting[id] {
+		return nil, ErrRepoNotFound
+	}
+
+	cache.deleting[id] = true
+	entry, ok := cache.dbs[id]
+
+	if !ok {
+		return nil, nil
+	}
+
+	delete(cache.dbs, id)
+
+	if entry.refs > 0 {
+		entry.idle = make(chan struct{})
+	}
+
+	return entry, nil
+}
+
+// List returns the ids of the repositories of an org, or of every org if org is empty, sorted by org and then name.
+func (cache *DBCache) List(org string) ([]*remotesapi.RepoId, error) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	var orgs []string
+	if org != "" {
+		orgs = []string{org}
+	} else {
+		dataDir, err := cache.fs.Abs("")
+
+		if err != nil {
+			return nil, err
+		}
+
+		orgs, err = cache.subdirs(dataDir)
 
-	if cs, ok := cache.dbs[id]; ok {
-		return cs, nil
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	var newCS *nbs.NomsBlockStore
-	if cache.fs != nil {
-		err := cache.fs.MkDirs(id)
+	var repoIds []*remotesapi.RepoId
+	for _, org := range orgs {
+		if !validNameRegex.MatchString(org) {
+			continue
+		}
+
+		orgDir, err := cache.fs.Abs(org)
 
 		if err != nil {
 			return nil, err
 		}
 
-		newCS, err = nbs.NewLocalStore(context.TODO(), nbfVerStr, id, defaultMemTableSize)
+		if exists, isDir := cache.fs.Exists(orgDir); !exists || !isDir {
+			continue
+		}
+
+		repos, err := cache.subdirs(orgDir)
 
 		if err != nil {
 			return nil, err
 		}
+
+		for _, repo := range repos {
+			if validNameRegex.MatchString(repo) {
+				repoIds = append(repoIds, &remotesapi.RepoId{Org: org, RepoName: repo})
+			}
+		}
 	}
 
-	cache.dbs[id] = newCS
+	sort.Slice(repoIds, func(i, j int) bool {
+		if repoIds[i].Org != repoIds[j].Org {
+			return repoIds[i].Org < repoIds[j].Org
+		}
+
+		return repoIds[i].RepoName < repoIds[j].RepoName
+	})
+
+	return repoIds, nil
+}
+
+func (cache *DBCache) subdirs(dir string) ([]string, error) {
+	var names []string
+	err := cache.fs.Iter(dir, false, func(path string, size int64, isDir bool) (stop bool) {
+		if isDir {
+			names = append(names, filepath.Base(path))
+		}
+
+		return false
+	})
+
+	return names, err
+}
+
+// Close closes the stores of every open repository.
+func (cache *DBCache) Close() error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	var firstErr error
+	for id, entry := range cache.dbs {
+		err := entry.cs.Close()
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+
+		delete(cache.dbs, id)
+	}
 
-	return newCS, nil
+	return firstErr
 }
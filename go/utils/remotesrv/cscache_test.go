@@ -0,0 +1,134 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	remotesapi "github.com/liquidata-inc/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+func newTestCSCache(t *testing.T) (*DBCache, string) {
+	dir, err := ioutil.TempDir("", "remotesrv_test")
+	require.NoError(t, err)
+
+	fs, err := filesys.LocalFilesysWithWorkingDir(dir)
+	require.NoError(t, err)
+
+	return NewLocalCSCache(fs), dir
+}
+
+func TestDBCache(t *testing.T) {
+	cache, dir := newTestCSCache(t)
+	defer os.RemoveAll(dir)
+	defer cache.Close()
+	nbfVerStr := types.Format_Default.VersionString()
+
+	// repositories aren't created by reads
+	_, _, err := cache.Get("org", "repo", nbfVerStr)
+	assert.Equal(t, ErrRepoNotFound, err)
+	exists, _ := cache.fs.Exists(filepath.Join(dir, "org"))
+	assert.False(t, exists)
+
+	require.NoError(t, cache.Create("org", "repo", nbfVerStr))
+	require.NoError(t, cache.Create("org", "other", nbfVerStr))
+	require.NoError(t, cache.Create("another", "repo", nbfVerStr))
+	assert.Equal(t, ErrRepoExists, cache.Create("org", "repo", nbfVerStr))
+
+	for _, name := range [][2]string{{"", "repo"}, {"org", ""}, {"..", "repo"}, {"org", "re/po"}, {".org", "repo"}} {
+		assert.Equal(t, ErrInvalidRepoName, cache.Create(name[0], name[1], nbfVerStr), name)
+		_, _, err = cache.Get(name[0], name[1], nbfVerStr)
+		assert.Equal(t, ErrInvalidRepoName, err, name)
+		assert.Equal(t, ErrInvalidRepoName, cache.Delete(name[0], name[1]), name)
+	}
+
+	cs, release, err := cache.Get("org", "repo", nbfVerStr)
+	require.NoError(t, err)
+	assert.Equal(t, nbfVerStr, cs.Version())
+	release()
+
+	repoIds, err := cache.List("")
+	require.NoError(t, err)
+	assert.Equal(t, []*remotesapi.RepoId{{Org: "another", RepoName: "repo"}, {Org: "org", RepoName: "other"}, {Org: "org", RepoName: "repo"}}, repoIds)
+
+	repoIds, err = cache.List("org")
+	require.NoError(t, err)
+	assert.Equal(t, []*remotesapi.RepoId{{Org: "org", RepoName: "other"}, {Org: "org", RepoName: "repo"}}, repoIds)
+
+	repoIds, err = cache.List("missing")
+	require.NoError(t, err)
+	assert.Empty(t, repoIds)
+
+	require.NoError(t, cache.Delete("org", "repo"))
+	assert.Equal(t, ErrRepoNotFound, cache.Delete("org", "repo"))
+	_, _, err = cache.Get("org", "repo", nbfVerStr)
+	assert.Equal(t, ErrRepoNotFound, err)
+	exists, _ = cache.fs.Exists(filepath.Join(dir, "org", "repo"))
+	assert.False(t, exists)
+
+	repoIds, err = cache.List("org")
+	require.NoError(t, err)
+	assert.Equal(t, []*remotesapi.RepoId{{Org: "org", RepoName: "other"}}, repoIds)
+
+	// a deleted repository can be created again
+	require.NoError(t, cache.Create("org", "repo", nbfVerStr))
+}
+
+func TestDBCacheDeleteWaitsForRequests(t *testing.T) {
+	cache, dir := newTestCSCache(t)
+	defer os.RemoveAll(dir)
+	defer cache.Close()
+	nbfVerStr := types.Format_Default.VersionString()
+
+	require.NoError(t, cache.Create("org", "repo", nbfVerStr))
+	cs, release, err := cache.Get("org", "repo", nbfVerStr)
+	require.NoError(t, err)
+
+	deleted := make(chan error, 1)
+	go func() {
+		deleted <- cache.Delete("org", "repo")
+	}()
+
+	select {
+	case err := <-deleted:
+		t.Fatalf("Delete returned while the store was in use: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// the store can still be used by the request, but new requests can't use the repository
+	_, err = cs.Root(context.Background())
+	assert.NoError(t, err)
+	_, _, err = cache.Get("org", "repo", nbfVerStr)
+	assert.Equal(t, ErrRepoNotFound, err)
+	assert.Equal(t, ErrRepoNotFound, cache.Delete("org", "repo"))
+	assert.Equal(t, ErrRepoExists, cache.Create("org", "repo", nbfVerStr))
+
+	release()
+	require.NoError(t, <-deleted)
+
+	exists, _ := cache.fs.Exists(filepath.Join(dir, "org", "repo"))
+	assert.False(t, exists)
+	require.NoError(t, cache.Create("org", "repo", nbfVerStr))
+}
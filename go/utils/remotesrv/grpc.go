@@ -17,9 +17,9 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
-	"sync/atomic"
+	"net/url"
 
+	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -31,16 +31,20 @@ import (
 )
 
 type RemoteChunkStore struct {
-	HttpHost string
-	csCache  *DBCache
-	bucket   string
+	HttpScheme string
+	HttpHost   string
+	csCache    *DBCache
+	bucket     string
+	signer     *urlSigner
 }
 
-func NewHttpFSBackedChunkStore(httpHost string, csCache *DBCache) *RemoteChunkStore {
+func NewHttpFSBackedChunkStore(httpScheme, httpHost string, csCache *DBCache, signer *urlSigner) *RemoteChunkStore {
 	return &RemoteChunkStore{
+		httpScheme,
 		httpHost,
 		csCache,
 		"",
+		signer,
 	}
 }
 
@@ -48,12 +52,14 @@ func (rs *RemoteChunkStore) HasChunks(ctx context.Context, req *remotesapi.HasCh
 	logger := getReqLogger("GRPC", "HasChunks")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "HasChunks")
+	cs, release, err := rs.getStore(req.RepoId, "HasChunks")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
+	defer release()
+
 	logger(fmt.Sprintf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName))
 
 	hashes, hashToIndex := remotestorage.ParseByteSlices(req.Hashes)
@@ -85,12 +91,14 @@ func (rs *RemoteChunkStore) GetDownloadLocations(ctx context.Context, req *remot
 	logger := getReqLogger("GRPC", "GetDownloadLocations")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "GetDownloadLoctions")
+	cs, release, err := rs.getStore(req.RepoId, "GetDownloadLoctions")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
+	defer release()
+
 	logger(fmt.Sprintf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName))
 
 	org := req.RepoId.Org
@@ -112,11 +120,12 @@ func (rs *RemoteChunkStore) GetDownloadLocations(ctx context.Context, req *remot
 
 		url, err := rs.getDownloadUrl(logger, org, repoName, loc.String())
 		if err != nil {
-			log.Println("Failed to sign request", err)
+			logger("Failed to sign request: " + err.Error())
 		}
 
 		logger("The URL is " + url)
 
+		chunksServed.add(float64(len(ranges)), org, repoName)
 		getRange := &remotesapi.HttpGetRange{Url: url, Ranges: ranges}
 		locs = append(locs, &remotesapi.DownloadLoc{Location: &remotesapi.DownloadLoc_HttpGetRange{HttpGetRange: getRange}})
 	}
@@ -125,7 +134,13 @@ func (rs *RemoteChunkStore) GetDownloadLocations(ctx context.Context, req *remot
 }
 
 func (rs *RemoteChunkStore) getDownloadUrl(logger func(string), org, repoName, fileId string) (string, error) {
-	return fmt.Sprintf("http://%s/%s/%s/%s", rs.HttpHost, org, repoName, fileId), nil
+	return rs.getSignedUrl(org, repoName, fileId, ReadPerm), nil
+}
+
+func (rs *RemoteChunkStore) getSignedUrl(org, repoName, fileId string, perm Permission) string {
+	u := &url.URL{Scheme: rs.HttpScheme, Host: rs.HttpHost, Path: fmt.Sprintf("/%s/%s/%s", org, repoName, fileId)}
+	rs.signer.sign(u, perm)
+	return u.String()
 }
 
 func parseTableFileDetails(req *remotesapi.GetUploadLocsRequest) []*remotesapi.TableFileDetails {
@@ -151,12 +166,14 @@ func (rs *RemoteChunkStore) GetUploadLocations(ctx context.Context, req *remotes
 	logger := getReqLogger("GRPC", "GetUploadLocations")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "GetWriteChunkUrls")
+	_, release, err := rs.getStore(req.RepoId, "GetWriteChunkUrls")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
+	defer release()
+
 	logger(fmt.Sprintf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName))
 
 	org := req.RepoId.Org
@@ -183,23 +200,25 @@ func (rs *RemoteChunkStore) GetUploadLocations(ctx context.Context, req *remotes
 
 func (rs *RemoteChunkStore) getUploadUrl(logger func(string), org, repoName string, tfd *remotesapi.TableFileDetails) (string, error) {
 	fileID := hash.New(tfd.Id).String()
-	expectedFiles[fileID] = *tfd
-	return fmt.Sprintf("http://%s/%s/%s/%s", rs.HttpHost, org, repoName, fileID), nil
+	addExpectedFile(fileID, *tfd)
+	return rs.getSignedUrl(org, repoName, fileID, WritePerm), nil
 }
 
 func (rs *RemoteChunkStore) Rebase(ctx context.Context, req *remotesapi.RebaseRequest) (*remotesapi.RebaseResponse, error) {
 	logger := getReqLogger("GRPC", "Rebase")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "Rebase")
+	cs, release, err := rs.getStore(req.RepoId, "Rebase")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
+	defer release()
+
 	logger(fmt.Sprintf("found %s/%s", req.RepoId.Org, req.RepoId.RepoName))
 
-	err := cs.Rebase(ctx)
+	err = cs.Rebase(ctx)
 
 	if err != nil {
 		logger(fmt.Sprintf("error occurred during processing of Rebace rpc of %s/%s details: %v", req.RepoId.Org, req.RepoId.RepoName, err))
@@ -213,12 +232,14 @@ func (rs *RemoteChunkStore) Root(ctx context.Context, req *remotesapi.RootReques
 	logger := getReqLogger("GRPC", "Root")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "Root")
+	cs, release, err := rs.getStore(req.RepoId, "Root")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
+	defer release()
+
 	h, err := cs.Root(ctx)
 
 	if err != nil {
@@ -233,12 +254,14 @@ func (rs *RemoteChunkStore) Commit(ctx context.Context, req *remotesapi.CommitRe
 	logger := getReqLogger("GRPC", "Commit")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "Commit")
+	cs, release, err := rs.getStore(req.RepoId, "Commit")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
+	defer release()
+
 	logger(fmt.Sprintf("found %s/%s", req.RepoId.Org, req.RepoId.RepoName))
 
 	//should validate
@@ -247,13 +270,15 @@ func (rs *RemoteChunkStore) Commit(ctx context.Context, req *remotesapi.CommitRe
 		updates[hash.New(cti.Hash)] = cti.ChunkCount
 	}
 
-	_, err := cs.UpdateManifest(ctx, updates)
+	_, err = cs.UpdateManifest(ctx, updates)
 
 	if err != nil {
 		logger(fmt.Sprintf("error occurred updating the manifest: %s", err.Error()))
 		return nil, status.Error(codes.Internal, "manifest update error")
 	}
 
+	countChunksReceived(req.RepoId, req.ChunkTableInfo)
+
 	currHash := hash.New(req.Current)
 	lastHash := hash.New(req.Last)
 
@@ -273,11 +298,14 @@ func (rs *RemoteChunkStore) GetRepoMetadata(ctx context.Context, req *remotesapi
 	logger := getReqLogger("GRPC", "GetRepoMetadata")
 	defer func() { logger("finished") }()
 
-	cs := rs.getOrCreateStore(req.RepoId, "GetRepoMetadata", req.ClientRepoFormat.NbfVersion)
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	cs, release, err := rs.getStoreOfFormat(req.RepoId, "GetRepoMetadata", req.ClientRepoFormat.GetNbfVersion())
+
+	if err != nil {
+		return nil, err
 	}
 
+	defer release()
+
 	return &remotesapi.GetRepoMetadataResponse{
 		NbfVersion: cs.Version(),
 		NbsVersion: nbs.StorageVersion,
@@ -288,12 +316,14 @@ func (rs *RemoteChunkStore) ListTableFiles(ctx context.Context, req *remotesapi.
 	logger := getReqLogger("GRPC", "ListTableFiles")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "ListTableFiles")
+	cs, release, err := rs.getStore(req.RepoId, "ListTableFiles")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
+	defer release()
+
 	logger(fmt.Sprintf("found repo %s/%s", req.RepoId.Org, req.RepoId.RepoName))
 
 	root, tables, err := cs.Sources(ctx)
@@ -327,15 +357,17 @@ func (rs *RemoteChunkStore) ListTableFiles(ctx context.Context, req *remotesapi.
 
 // AddTableFiles updates the remote manifest with new table files without modifying the root hash.
 func (rs *RemoteChunkStore) AddTableFiles(ctx context.Context, req *remotesapi.AddTableFilesRequest) (*remotesapi.AddTableFilesResponse, error) {
-	logger := getReqLogger("GRPC", "Commit")
+	logger := getReqLogger("GRPC", "AddTableFiles")
 	defer func() { logger("finished") }()
 
-	cs := rs.getStore(req.RepoId, "Commit")
+	cs, release, err := rs.getStore(req.RepoId, "AddTableFiles")
 
-	if cs == nil {
-		return nil, status.Error(codes.Internal, "Could not get chunkstore")
+	if err != nil {
+		return nil, err
 	}
 
+	defer release()
+
 	logger(fmt.Sprintf("found %s/%s", req.RepoId.Org, req.RepoId.RepoName))
 
 	// should validate
@@ -344,44 +376,50 @@ func (rs *RemoteChunkStore) AddTableFiles(ctx context.Context, req *remotesapi.A
 		updates[hash.New(cti.Hash)] = cti.ChunkCount
 	}
 
-	_, err := cs.UpdateManifest(ctx, updates)
+	_, err = cs.UpdateManifest(ctx, updates)
 
 	if err != nil {
 		logger(fmt.Sprintf("error occurred updating the manifest: %s", err.Error()))
 		return nil, status.Error(codes.Internal, "manifest update error")
 	}
 
-	return &remotesapi.AddTableFilesResponse{Success: true}, nil
-}
+	countChunksReceived(req.RepoId, req.ChunkTableInfo)
 
-func (rs *RemoteChunkStore) getStore(repoId *remotesapi.RepoId, rpcName string) *nbs.NomsBlockStore {
-	return rs.getOrCreateStore(repoId, rpcName, types.Format_Default.VersionString())
+	return &remotesapi.AddTableFilesResponse{Success: true}, nil
 }
 
-func (rs *RemoteChunkStore) getOrCreateStore(repoId *remotesapi.RepoId, rpcName, nbfVerStr string) *nbs.NomsBlockStore {
-	org := repoId.Org
-	repoName := repoId.RepoName
-
-	cs, err := rs.csCache.Get(org, repoName, nbfVerStr)
-
-	if err != nil {
-		log.Printf("Failed to retrieve chunkstore for %s/%s\n", org, repoName)
+func countChunksReceived(repoId *remotesapi.RepoId, ctis []*remotesapi.ChunkTableInfo) {
+	for _, cti := range ctis {
+		chunksReceived.add(float64(cti.ChunkCount), repoId.Org, repoId.RepoName)
 	}
-
-	return cs
 }
 
-var requestId int32
-
-func incReqId() int32 {
-	return atomic.AddInt32(&requestId, 1)
+func (rs *RemoteChunkStore) getStore(repoId *remotesapi.RepoId, rpcName string) (*nbs.NomsBlockStore, func(), error) {
+	return rs.getStoreOfFormat(repoId, rpcName, types.Format_Default.VersionString())
 }
 
-func getReqLogger(method, callName string) func(string) {
-	callId := fmt.Sprintf("%s(%05d)", method, incReqId())
-	log.Println(callId, "new request for:", callName)
+// getStoreOfFormat returns the store of an existing repository, or a grpc status error.  Repositories aren't created
+// implicitly, and must be created with the RepoAdminService.  The release function returned must be called once the
+// request is done with the store.
+func (rs *RemoteChunkStore) getStoreOfFormat(repoId *remotesapi.RepoId, rpcName, nbfVerStr string) (*nbs.NomsBlockStore, func(), error) {
+	org := repoId.GetOrg()
+	repoName := repoId.GetRepoName()
+
+	if nbfVerStr == "" {
+		nbfVerStr = types.Format_Default.VersionString()
+	}
 
-	return func(msg string) {
-		log.Println(callId, "-", msg)
+	cs, release, err := rs.csCache.Get(org, repoName, nbfVerStr)
+
+	switch err {
+	case nil:
+		return cs, release, nil
+	case ErrRepoNotFound:
+		return nil, nil, status.Errorf(codes.NotFound, "repository %s/%s not found", org, repoName)
+	case ErrInvalidRepoName:
+		return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	default:
+		logrus.Errorf("%s failed to retrieve chunkstore for %s/%s: %v", rpcName, org, repoName, err)
+		return nil, nil, status.Error(codes.Internal, "Could not get chunkstore")
 	}
 }
@@ -26,44 +26,95 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	remotesapi "github.com/liquidata-inc/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
 
+	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/iohelp"
 	"github.com/liquidata-inc/dolt/go/store/hash"
 )
 
+var expectedFilesMu = &sync.Mutex{}
 var expectedFiles = make(map[string]remotesapi.TableFileDetails)
 
-func ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
+func addExpectedFile(fileId string, tfd remotesapi.TableFileDetails) {
+	expectedFilesMu.Lock()
+	defer expectedFilesMu.Unlock()
+	expectedFiles[fileId] = tfd
+}
+
+func getExpectedFile(fileId string) (remotesapi.TableFileDetails, bool) {
+	expectedFilesMu.Lock()
+	defer expectedFilesMu.Unlock()
+	tfd, ok := expectedFiles[fileId]
+	return tfd, ok
+}
+
+// fileHandler is the handler of the http file server, which reads and writes the table files of the repositories in
+// the working directory of its filesys.  It only serves requests for urls signed by its urlSigner.
+type fileHandler struct {
+	fs     filesys.Filesys
+	signer *urlSigner
+}
+
+// newHttpHandler returns the handler of the http file server.
+func newHttpHandler(fs filesys.Filesys, signer *urlSigner) http.Handler {
+	return loggingHandler(&fileHandler{fs, signer})
+}
+
+func (fh *fileHandler) ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
 	logger := getReqLogger("HTTP_"+req.Method, req.RequestURI)
 	defer func() { logger("finished") }()
 
+	if !fh.signer.verify(req) {
+		logger("rejected: url signature is invalid or expired")
+		respWr.WriteHeader(http.StatusForbidden)
+		return
+	}
+
 	path := strings.TrimLeft(req.URL.Path, "/")
 	tokens := strings.Split(path, "/")
 
 	if len(tokens) != 3 {
 		logger(fmt.Sprintf("response to: %v method: %v http response code: %v", req.RequestURI, req.Method, http.StatusNotFound))
 		respWr.WriteHeader(http.StatusNotFound)
+		return
 	}
 
 	org := tokens[0]
 	repo := tokens[1]
 	hashStr := tokens[2]
+	setRepoInfo(req.Context(), org, repo)
+
+	repoDir, err := fh.repoDir(org, repo)
+
+	if err != nil {
+		logger(err.Error())
+		respWr.WriteHeader(http.StatusNotFound)
+		return
+	}
 
 	statusCode := http.StatusMethodNotAllowed
 	switch req.Method {
 	case http.MethodGet:
 		rangeStr := req.Header.Get("Range")
+		wr := &countingWriter{respWr, 0}
 
 		if rangeStr == "" {
-			statusCode = readFile(logger, org, repo, hashStr, respWr)
+			statusCode = readFile(logger, repoDir, hashStr, wr)
 		} else {
-			statusCode = readChunk(logger, org, repo, hashStr, rangeStr, respWr)
+			statusCode = readChunk(logger, repoDir, hashStr, rangeStr, wr)
 		}
 
+		bytesServed.add(float64(wr.n), org, repo)
+
 	case http.MethodPost, http.MethodPut:
-		statusCode = writeTableFile(logger, org, repo, hashStr, req)
+		statusCode = writeTableFile(logger, repoDir, hashStr, req)
+
+		if statusCode == http.StatusOK && req.ContentLength > 0 {
+			bytesReceived.add(float64(req.ContentLength), org, repo)
+		}
 	}
 
 	if statusCode != -1 {
@@ -71,7 +122,38 @@ func ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func writeTableFile(logger func(string), org, repo, fileId string, request *http.Request) int {
+// repoDir returns the absolute path of the directory of an existing repository
+func (fh *fileHandler) repoDir(org, repo string) (string, error) {
+	if !validNameRegex.MatchString(org) || !validNameRegex.MatchString(repo) {
+		return "", ErrInvalidRepoName
+	}
+
+	dir, err := fh.fs.Abs(filepath.Join(org, repo))
+
+	if err != nil {
+		return "", err
+	}
+
+	if exists, isDir := fh.fs.Exists(dir); !exists || !isDir {
+		return "", ErrRepoNotFound
+	}
+
+	return dir, nil
+}
+
+// countingWriter counts the bytes written to a response
+type countingWriter struct {
+	wr io.Writer
+	n  int64
+}
+
+func (cw *countingWriter) Write(data []byte) (int, error) {
+	n, err := cw.wr.Write(data)
+	cw.n += int64(n)
+	return n, err
+}
+
+func writeTableFile(logger func(string), repoDir, fileId string, request *http.Request) int {
 	_, ok := hash.MaybeParse(fileId)
 
 	if !ok {
@@ -79,7 +161,7 @@ func writeTableFile(logger func(string), org, repo, fileId string, request *http
 		return http.StatusBadRequest
 	}
 
-	tfd, ok := expectedFiles[fileId]
+	tfd, ok := getExpectedFile(fileId)
 
 	if !ok {
 		return http.StatusBadRequest
@@ -104,7 +186,7 @@ func writeTableFile(logger func(string), org, repo, fileId string, request *http
 		return http.StatusInternalServerError
 	}
 
-	err = writeLocal(logger, org, repo, fileId, data)
+	err = writeLocal(logger, repoDir, fileId, data)
 
 	if err != nil {
 		return http.StatusInternalServerError
@@ -113,13 +195,32 @@ func writeTableFile(logger func(string), org, repo, fileId string, request *http
 	return http.StatusOK
 }
 
-func writeLocal(logger func(string), org, repo, fileId string, data []byte) error {
-	path := filepath.Join(org, repo, fileId)
+// writeLocal writes a table file to a temporary file in the repository directory, and then renames it, so that a
+// partially written table file can never be read.
+func writeLocal(logger func(string), repoDir, fileId string, data []byte) error {
+	path := filepath.Join(repoDir, fileId)
+	f, err := ioutil.TempFile(repoDir, fileId+".*.tmp")
+
+	if err != nil {
+		logger(fmt.Sprintf("failed to create temp file for %s: %v", path, err))
+		return err
+	}
+
+	err = iohelp.WriteAll(f, data)
 
-	err := ioutil.WriteFile(path, data, os.ModePerm)
+	if err == nil {
+		err = f.Close()
+	} else {
+		f.Close()
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
 
 	if err != nil {
-		logger(fmt.Sprintf("failed to write file %s", path))
+		os.Remove(f.Name())
+		logger(fmt.Sprintf("failed to write file %s: %v", path, err))
 		return err
 	}
 
@@ -158,8 +259,8 @@ func offsetAndLenFromRange(rngStr string) (int64, int64, error) {
 	return int64(start), int64(end-start) + 1, nil
 }
 
-func readFile(logger func(string), org, repo, fileId string, writer io.Writer) int {
-	path := filepath.Join(org, repo, fileId)
+func readFile(logger func(string), repoDir, fileId string, writer io.Writer) int {
+	path := filepath.Join(repoDir, fileId)
 
 	info, err := os.Stat(path)
 
@@ -200,7 +301,7 @@ func readFile(logger func(string), org, repo, fileId string, writer io.Writer) i
 	return -1
 }
 
-func readChunk(logger func(string), org, repo, fileId, rngStr string, writer io.Writer) int {
+func readChunk(logger func(string), repoDir, fileId, rngStr string, writer io.Writer) int {
 	offset, length, err := offsetAndLenFromRange(rngStr)
 
 	if err != nil {
@@ -208,7 +309,7 @@ func readChunk(logger func(string), org, repo, fileId, rngStr string, writer io.
 		return http.StatusBadRequest
 	}
 
-	data, retVal := readLocalRange(logger, org, repo, fileId, int64(offset), int64(length))
+	data, retVal := readLocalRange(logger, repoDir, fileId, int64(offset), int64(length))
 
 	if retVal != -1 {
 		return retVal
@@ -226,8 +327,8 @@ func readChunk(logger func(string), org, repo, fileId, rngStr string, writer io.
 	return -1
 }
 
-func readLocalRange(logger func(string), org, repo, fileId string, offset, length int64) ([]byte, int) {
-	path := filepath.Join(org, repo, fileId)
+func readLocalRange(logger func(string), repoDir, fileId string, offset, length int64) ([]byte, int) {
+	path := filepath.Join(repoDir, fileId)
 
 	logger(fmt.Sprintf("Attempting to read bytes %d to %d from %s", offset, offset+length, path))
 	info, err := os.Stat(path)
@@ -0,0 +1,183 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var requestId int32
+
+func incReqId() int32 {
+	return atomic.AddInt32(&requestId, 1)
+}
+
+// getReqLogger returns a function which logs the progress of a request at the debug level.  A summary of every request
+// is logged at the info level once it completes.
+func getReqLogger(method, callName string) func(string) {
+	entry := logrus.WithFields(logrus.Fields{
+		"request_id": fmt.Sprintf("%s(%05d)", method, incReqId()),
+		"call":       callName,
+	})
+	entry.Debug("new request")
+
+	return func(msg string) {
+		entry.Debug(msg)
+	}
+}
+
+// requestInfo holds the details of a request which are only known once it's been authorized
+type requestInfo struct {
+	user string
+	org  string
+	repo string
+}
+
+type requestInfoKey struct{}
+
+func withRequestInfo(ctx context.Context) (context.Context, *requestInfo) {
+	info := &requestInfo{}
+	return context.WithValue(ctx, requestInfoKey{}, info), info
+}
+
+// getRequestInfo returns the requestInfo of a request's context, which is discarded if the request isn't being logged
+func getRequestInfo(ctx context.Context) *requestInfo {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		return info
+	}
+
+	return &requestInfo{}
+}
+
+func (info *requestInfo) fields() logrus.Fields {
+	fields := logrus.Fields{}
+
+	if info.user != "" {
+		fields["user"] = info.user
+	}
+
+	if info.org != "" {
+		fields["org"] = info.org
+		fields["repo"] = info.repo
+	}
+
+	return fields
+}
+
+// loggingInterceptor returns a grpc interceptor which logs and counts every request.  It should run before any other
+// interceptors so that the request info they set is logged.
+func loggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx, reqInfo := withRequestInfo(ctx)
+		resp, err := handler(ctx, req)
+
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		code := status.Code(err)
+		grpcRequests.add(1, method, code.String())
+
+		entry := logrus.WithFields(reqInfo.fields()).WithFields(logrus.Fields{
+			"protocol": "grpc",
+			"method":   method,
+			"code":     code.String(),
+			"duration": time.Since(start).String(),
+		})
+
+		if err != nil {
+			entry.WithField("error", status.Convert(err).Message()).Warn("request failed")
+		} else {
+			entry.Info("request completed")
+		}
+
+		return resp, err
+	}
+}
+
+// chainInterceptors returns an interceptor which runs the interceptors given in order.
+func chainInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+
+		return chained(ctx, req)
+	}
+}
+
+// loggingResponseWriter records the status code and size of an http response
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	n      int64
+}
+
+func (wr *loggingResponseWriter) WriteHeader(status int) {
+	wr.status = status
+	wr.ResponseWriter.WriteHeader(status)
+}
+
+func (wr *loggingResponseWriter) Write(data []byte) (int, error) {
+	if wr.status == 0 {
+		wr.status = http.StatusOK
+	}
+
+	n, err := wr.ResponseWriter.Write(data)
+	wr.n += int64(n)
+	return n, err
+}
+
+// loggingHandler wraps an http handler so that every request it handles is logged and counted.
+func loggingHandler(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(respWr http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		ctx, reqInfo := withRequestInfo(req.Context())
+		wr := &loggingResponseWriter{ResponseWriter: respWr}
+		handler.ServeHTTP(wr, req.WithContext(ctx))
+
+		if wr.status == 0 {
+			wr.status = http.StatusOK
+		}
+
+		httpRequests.add(1, req.Method, fmt.Sprint(wr.status))
+
+		entry := logrus.WithFields(reqInfo.fields()).WithFields(logrus.Fields{
+			"protocol":      "http",
+			"method":        req.Method,
+			"path":          req.URL.Path,
+			"status":        wr.status,
+			"bytes_written": wr.n,
+			"duration":      time.Since(start).String(),
+		})
+
+		if wr.status >= http.StatusBadRequest {
+			entry.Warn("request failed")
+		} else {
+			entry.Info("request completed")
+		}
+	})
+}
@@ -18,127 +18,288 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"sync"
+	"syscall"
 
+	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	remotesapi "github.com/liquidata-inc/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
 	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
 )
 
 func main() {
-	dirParam := flag.String("dir", "", "root directory that this command will run in.")
-	grpcPortParam := flag.Int("grpc-port", -1, "root directory that this command will run in.")
-	httpPortParam := flag.Int("http-port", -1, "root directory that this command will run in.")
+	if len(os.Args) > 1 && os.Args[1] == repoCmdName {
+		os.Exit(runRepoCmd(os.Args[2:]))
+	}
+
+	dirParam := flag.String("dir", "", "data directory where repositories are stored.")
+	grpcPortParam := flag.Int("grpc-port", -1, "port on which the grpc remote chunkstore api is served.")
+	httpPortParam := flag.Int("http-port", -1, "port on which table files are served over http.")
+	httpHostParam := flag.String("http-host", "localhost", "hostname used in the urls of the http file server given to clients.")
+	authConfigParam := flag.String("auth-config", "", "auth config file listing the users and the repos they can access. If not provided all requests are allowed.")
+	tlsCertParam := flag.String("tls-cert", "", "certificate file used to serve both grpc and http requests over tls.")
+	tlsKeyParam := flag.String("tls-key", "", "private key file of the tls certificate.")
+	metricsPortParam := flag.Int("metrics-port", -1, "port on which metrics are served at /metrics in the Prometheus text format. If not provided metrics aren't served.")
+	logFormatParam := flag.String("log-format", "text", "format of log messages. Either text or json.")
+	logLevelParam := flag.String("log-level", "info", "lowest level of log messages written. Use debug to log the progress of every request.")
 	flag.Parse()
 
-	if dirParam != nil && len(*dirParam) > 0 {
-		err := os.Chdir(*dirParam)
+	configureLogging(*logFormatParam, *logLevelParam)
+
+	dataDir := *dirParam
+	if dataDir == "" {
+		dataDir = "."
+		logrus.Info("'dir' parameter not provided. Using the current working dir.")
+	}
+
+	fs, err := filesys.LocalFilesysWithWorkingDir(dataDir)
+
+	if err != nil {
+		logrus.Fatalf("invalid data directory %s: %v", dataDir, err)
+	}
+
+	if exists, isDir := fs.Exists(""); !exists || !isDir {
+		logrus.Fatalf("data directory %s does not exist", dataDir)
+	}
+
+	logrus.Infof("serving repositories from %s", dataDir)
+
+	var tlsFiles *tlsCertFiles
+	if *tlsCertParam != "" || *tlsKeyParam != "" {
+		if *tlsCertParam == "" || *tlsKeyParam == "" {
+			logrus.Fatal("'tls-cert' and 'tls-key' must be provided together")
+		}
+
+		tlsFiles = &tlsCertFiles{*tlsCertParam, *tlsKeyParam}
+	}
+
+	var auth Authorizer = allowAllAuth{}
+	if *authConfigParam != "" {
+		cfgAuth, err := loadConfigAuth(*authConfigParam)
 
 		if err != nil {
-			log.Fatalln("failed to chdir to:", *dirParam)
-			log.Fatalln("error:", err.Error())
-			os.Exit(1)
-		} else {
-			log.Println("cwd set to " + *dirParam)
+			logrus.Fatalf("failed to load auth config: %v", err)
 		}
+
+		auth = cfgAuth
 	} else {
-		log.Println("'dir' parameter not provided. Using the current working dir.")
+		logrus.Warn("'auth-config' parameter not provided. All requests will be allowed.")
+	}
+
+	signer, err := newURLSigner(defaultURLExpiry)
+
+	if err != nil {
+		logrus.Fatalf("failed to create url signing key: %v", err)
 	}
 
-	httpHost := "localhost"
+	httpHost := *httpHostParam
 
 	if *httpPortParam != -1 {
 		httpHost = fmt.Sprintf("%s:%d", httpHost, *httpPortParam)
+	} else if tlsFiles != nil {
+		*httpPortParam = 443
+		logrus.Info("'http-port' parameter not provided. Using default port 443")
 	} else {
 		*httpPortParam = 80
-		log.Println("'http-port' parameter not provided. Using default port 80")
+		logrus.Info("'http-port' parameter not provided. Using default port 80")
 	}
 
 	if *grpcPortParam == -1 {
 		*grpcPortParam = 50051
-		log.Println("'grpc-port' parameter not provided. Using default port 50051")
+		logrus.Info("'grpc-port' parameter not provided. Using default port 50051")
 	}
 
-	stopChan, wg := startServer(httpHost, *httpPortParam, *grpcPortParam)
+	cfg := serverConfig{
+		httpHost:    httpHost,
+		httpPort:    *httpPortParam,
+		grpcPort:    *grpcPortParam,
+		metricsPort: *metricsPortParam,
+		auth:        auth,
+		signer:      signer,
+		tlsFiles:    tlsFiles,
+		fs:          fs,
+		csCache:     NewLocalCSCache(fs),
+	}
+
+	stopChan, wg := startServer(cfg)
 	waitForSignal()
 
+	logrus.Info("shutting down")
 	close(stopChan)
 	wg.Wait()
+
+	err = cfg.csCache.Close()
+
+	if err != nil {
+		logrus.Errorf("failed to close repositories: %v", err)
+		os.Exit(1)
+	}
+}
+
+func configureLogging(format, level string) {
+	switch format {
+	case "json":
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	case "text":
+		logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	default:
+		logrus.Fatalf("invalid log format %s", format)
+	}
+
+	lvl, err := logrus.ParseLevel(level)
+
+	if err != nil {
+		logrus.Fatalf("invalid log level %s", level)
+	}
+
+	logrus.SetLevel(lvl)
+}
+
+// tlsCertFiles are the certificate and private key files used to serve requests over tls
+type tlsCertFiles struct {
+	certFile string
+	keyFile  string
+}
+
+// serverConfig holds everything the grpc, http and metrics servers share
+type serverConfig struct {
+	httpHost    string
+	httpPort    int
+	grpcPort    int
+	metricsPort int
+	auth        Authorizer
+	signer      *urlSigner
+	tlsFiles    *tlsCertFiles
+	fs          filesys.Filesys
+	csCache     *DBCache
 }
 
 func waitForSignal() {
-	c := make(chan os.Signal)
-	signal.Notify(c, os.Interrupt)
-	signal.Notify(c, os.Kill)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	<-c
 }
 
-func startServer(httpHost string, httpPort, grpcPort int) (chan interface{}, *sync.WaitGroup) {
+func startServer(cfg serverConfig) (chan interface{}, *sync.WaitGroup) {
 	wg := sync.WaitGroup{}
 	stopChan := make(chan interface{})
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		httpServer(httpPort, stopChan)
+		httpServer(cfg, stopChan)
 	}()
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		grpcServer(httpHost, grpcPort, stopChan)
+		grpcServer(cfg, stopChan)
 	}()
 
+	if cfg.metricsPort != -1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			metricsServer(cfg.metricsPort, stopChan)
+		}()
+	}
+
 	return stopChan, &wg
 }
 
-func grpcServer(httpHost string, grpcPort int, stopChan chan interface{}) {
+func grpcServer(cfg serverConfig, stopChan chan interface{}) {
 	defer func() {
-		log.Println("exiting grpc Server go routine")
+		logrus.Info("exiting grpc Server go routine")
 	}()
 
-	dbCache := NewLocalCSCache(filesys.LocalFS)
-	chnkSt := NewHttpFSBackedChunkStore(httpHost, dbCache)
+	httpScheme := "http"
+	interceptor := chainInterceptors(loggingInterceptor(), authInterceptor(cfg.auth))
+	opts := []grpc.ServerOption{grpc.MaxRecvMsgSize(128 * 1024 * 1024), grpc.UnaryInterceptor(interceptor)}
+
+	if cfg.tlsFiles != nil {
+		creds, err := credentials.NewServerTLSFromFile(cfg.tlsFiles.certFile, cfg.tlsFiles.keyFile)
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
+		if err != nil {
+			logrus.Fatalf("failed to load tls certificate: %v", err)
+		}
+
+		httpScheme = "https"
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	chnkSt := NewHttpFSBackedChunkStore(httpScheme, cfg.httpHost, cfg.csCache, cfg.signer)
+	repoAdmin := NewRepoAdmin(cfg.csCache)
+
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.grpcPort))
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		logrus.Fatalf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer(grpc.MaxRecvMsgSize(128 * 1024 * 1024))
+	grpcServer := grpc.NewServer(opts...)
 	go func() {
 		remotesapi.RegisterChunkStoreServiceServer(grpcServer, chnkSt)
+		remotesapi.RegisterRepoAdminServiceServer(grpcServer, repoAdmin)
 
-		log.Println("Starting grpc server on port", grpcPort)
+		logrus.Infof("Starting grpc server on port %d", cfg.grpcPort)
 		err := grpcServer.Serve(lis)
-		log.Println("grpc server exited. error:", err)
+		logrus.Infof("grpc server exited. error: %v", err)
 	}()
 
 	<-stopChan
 	grpcServer.GracefulStop()
 }
 
-func httpServer(httpPort int, stopChan chan interface{}) {
+func httpServer(cfg serverConfig, stopChan chan interface{}) {
 	defer func() {
-		log.Println("exiting http Server go routine")
+		logrus.Info("exiting http Server go routine")
 	}()
 
 	server := http.Server{
-		Addr:    fmt.Sprintf(":%d", httpPort),
-		Handler: http.HandlerFunc(ServeHTTP),
+		Addr:    fmt.Sprintf(":%d", cfg.httpPort),
+		Handler: newHttpHandler(cfg.fs, cfg.signer),
+	}
+
+	go func() {
+		logrus.Infof("Starting http server on port %d", cfg.httpPort)
+
+		var err error
+		if cfg.tlsFiles != nil {
+			err = server.ListenAndServeTLS(cfg.tlsFiles.certFile, cfg.tlsFiles.keyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+
+		logrus.Infof("http server exited. exit error: %v", err)
+	}()
+
+	<-stopChan
+	server.Shutdown(context.Background())
+}
+
+func metricsServer(metricsPort int, stopChan chan interface{}) {
+	defer func() {
+		logrus.Info("exiting metrics Server go routine")
+	}()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics)
+
+	server := http.Server{
+		Addr:    fmt.Sprintf(":%d", metricsPort),
+		Handler: mux,
 	}
 
 	go func() {
-		log.Println("Starting http server on port ", httpPort)
+		logrus.Infof("Starting metrics server on port %d", metricsPort)
 		err := server.ListenAndServe()
-		log.Println("http server exited. exit error:", err)
+		logrus.Infof("metrics server exited. exit error: %v", err)
 	}()
 
 	<-stopChan
@@ -0,0 +1,109 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var metrics = &metricsRegistry{}
+
+var (
+	bytesServed    = metrics.newCounterVec("remotesrv_http_bytes_served_total", "Bytes of table files downloaded.", "org", "repo")
+	bytesReceived  = metrics.newCounterVec("remotesrv_http_bytes_received_total", "Bytes of table files uploaded.", "org", "repo")
+	chunksServed   = metrics.newCounterVec("remotesrv_chunks_served_total", "Chunks whose download locations were requested.", "org", "repo")
+	chunksReceived = metrics.newCounterVec("remotesrv_chunks_received_total", "Chunks in table files added to repositories.", "org", "repo")
+	grpcRequests   = metrics.newCounterVec("remotesrv_grpc_requests_total", "Grpc requests handled.", "method", "code")
+	httpRequests   = metrics.newCounterVec("remotesrv_http_requests_total", "Http requests handled.", "method", "code")
+)
+
+// metricsRegistry holds the metrics of the server, and serves them in the Prometheus text exposition format.
+type metricsRegistry struct {
+	counters []*counterVec
+}
+
+func (r *metricsRegistry) newCounterVec(name, help string, labelNames ...string) *counterVec {
+	c := &counterVec{name: name, help: help, labelNames: labelNames, values: make(map[string]float64)}
+	r.counters = append(r.counters, c)
+	return c
+}
+
+func (r *metricsRegistry) ServeHTTP(respWr http.ResponseWriter, req *http.Request) {
+	respWr.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	wr := bufio.NewWriter(respWr)
+
+	for _, c := range r.counters {
+		c.write(wr)
+	}
+
+	_ = wr.Flush()
+}
+
+// counterVec is a counter with a value for each combination of its label values.
+type counterVec struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+const labelSep = "\xff"
+
+func (c *counterVec) add(delta float64, labelValues ...string) {
+	if len(labelValues) != len(c.labelNames) {
+		panic(fmt.Sprintf("%s has %d labels but %d values were given", c.name, len(c.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, labelSep)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[key] += delta
+}
+
+func (c *counterVec) write(wr *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(wr, "# HELP %s %s\n", c.name, c.help)
+	fmt.Fprintf(wr, "# TYPE %s counter\n", c.name)
+
+	keys := make([]string, 0, len(c.values))
+	for key := range c.values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		labelValues := strings.Split(key, labelSep)
+		labels := make([]string, len(labelValues))
+
+		for i, val := range labelValues {
+			labels[i] = fmt.Sprintf("%s=%s", c.labelNames[i], strconv.Quote(val))
+		}
+
+		fmt.Fprintf(wr, "%s{%s} %s\n", c.name, strings.Join(labels, ","), strconv.FormatFloat(c.values[key], 'f', -1, 64))
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	remotesapi "github.com/liquidata-inc/dolt/go/gen/proto/dolt/services/remotesapi/v1alpha1"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/doltdb"
+	"github.com/liquidata-inc/dolt/go/libraries/doltcore/env"
+	"github.com/liquidata-inc/dolt/go/libraries/utils/filesys"
+	"github.com/liquidata-inc/dolt/go/store/nbs"
+	"github.com/liquidata-inc/dolt/go/store/types"
+)
+
+const repoCmdName = "repo"
+
+const repoCmdUsage = `usage: remotesrv repo create <url>
+   or: remotesrv repo delete <url>
+   or: remotesrv repo list <url>
+
+Manages the repositories of a running remotesrv.  For create and delete <url> is the url of the repository, as used
+with dolt remote add: http://<HOST>:<GRPC-PORT>/<ORG>/<REPO>.  For list it's the url of the server, or of an org to
+only list its repositories.  Use https urls for servers using tls.
+
+Requests are sent with the dolt credentials in the user.creds config value, and are only allowed for the admins listed
+in the auth config of the server.`
+
+// runRepoCmd runs the repo subcommand, which is a client of the RepoAdminService, and returns the exit code.
+func runRepoCmd(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, repoCmdUsage)
+		return 1
+	}
+
+	op, urlStr := args[0], args[1]
+	u, err := url.Parse(urlStr)
+
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fmt.Fprintf(os.Stderr, "invalid url '%s'. Urls should be in the format http://<HOST>:<GRPC-PORT>/<ORG>/<REPO>\n", urlStr)
+		return 1
+	}
+
+	var pathParts []string
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		pathParts = strings.Split(path, "/")
+	}
+
+	ctx := context.Background()
+	dEnv := env.Load(ctx, env.GetCurrentUserHomeDir, filesys.LocalFS, doltdb.InMemDoltDB)
+	conn, err := dEnv.GrpcConn(u.Host, u.Scheme == "http")
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect to", u.Host, "error:", err)
+		return 1
+	}
+
+	defer conn.Close()
+	client := remotesapi.NewRepoAdminServiceClient(conn)
+
+	switch op {
+	case "create", "delete":
+		if len(pathParts) != 2 {
+			fmt.Fprintf(os.Stderr, "invalid url '%s'. Urls should be in the format http://<HOST>:<GRPC-PORT>/<ORG>/<REPO>\n", urlStr)
+			return 1
+		}
+
+		repoId := &remotesapi.RepoId{Org: pathParts[0], RepoName: pathParts[1]}
+
+		if op == "create" {
+			repoFormat := &remotesapi.ClientRepoFormat{NbfVersion: types.Format_Default.VersionString(), NbsVersion: nbs.StorageVersion}
+			_, err = client.CreateRepo(ctx, &remotesapi.CreateRepoRequest{RepoId: repoId, RepoFormat: repoFormat})
+		} else {
+			_, err = client.DeleteRepo(ctx, &remotesapi.DeleteRepoRequest{RepoId: repoId})
+		}
+
+	case "list":
+		if len(pathParts) > 1 {
+			fmt.Fprintf(os.Stderr, "invalid url '%s'. Urls should be in the format http://<HOST>:<GRPC-PORT>[/<ORG>]\n", urlStr)
+			return 1
+		}
+
+		req := &remotesapi.ListReposRequest{}
+		if len(pathParts) == 1 {
+			req.Org = pathParts[0]
+		}
+
+		var resp *remotesapi.ListReposResponse
+		resp, err = client.ListRepos(ctx, req)
+
+		if err == nil {
+			for _, repoId := range resp.RepoIds {
+				fmt.Printf("%s/%s\n", repoId.Org, repoId.RepoName)
+			}
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, repoCmdUsage)
+		return 1
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	expiresParam   = "expires"
+	signatureParam = "signature"
+
+	// defaultURLExpiry is how long signed urls can be used for.  Download urls are used for the duration of a clone or
+	// fetch, so they're given long enough to download a large repository.
+	defaultURLExpiry = time.Hour
+)
+
+// urlSigner signs the table file urls handed out by the grpc server, so that the http server only serves requests
+// which were authorized by the grpc server, and only until the urls expire.  The signing key is generated when the
+// server starts, so urls can't be used after a restart.
+type urlSigner struct {
+	key    []byte
+	expiry time.Duration
+	now    func() time.Time
+}
+
+func newURLSigner(expiry time.Duration) (*urlSigner, error) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &urlSigner{key, expiry, time.Now}, nil
+}
+
+// sign adds an expiration time and a signature to the query of a url for requests with the permission given.
+func (s *urlSigner) sign(u *url.URL, perm Permission) {
+	expires := strconv.FormatInt(s.now().Add(s.expiry).Unix(), 10)
+	query := url.Values{}
+	query.Set(expiresParam, expires)
+	query.Set(signatureParam, s.signature(u.Path, expires, perm))
+	u.RawQuery = query.Encode()
+}
+
+// verify returns true if the request url was signed with the permission its method needs, and hasn't expired.
+func (s *urlSigner) verify(req *http.Request) bool {
+	perm := ReadPerm
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		perm = WritePerm
+	}
+
+	query := req.URL.Query()
+	expires := query.Get(expiresParam)
+	expiresUnix, err := strconv.ParseInt(expires, 10, 64)
+
+	if err != nil || s.now().Unix() > expiresUnix {
+		return false
+	}
+
+	sig, err := hex.DecodeString(query.Get(signatureParam))
+
+	if err != nil {
+		return false
+	}
+
+	expected, _ := hex.DecodeString(s.signature(req.URL.Path, expires, perm))
+	return hmac.Equal(sig, expected)
+}
+
+func (s *urlSigner) signature(path, expires string, perm Permission) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(perm.String() + "\n" + path + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,89 @@
+// Copyright 2019 Liquidata, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLSigner(t *testing.T) {
+	signer, err := newURLSigner(time.Minute)
+	require.NoError(t, err)
+	other, err := newURLSigner(time.Minute)
+	require.NoError(t, err)
+
+	now := time.Now()
+	signer.now = func() time.Time { return now }
+
+	signed := func(s *urlSigner, path string, perm Permission) *url.URL {
+		u := &url.URL{Scheme: "http", Host: "localhost", Path: path}
+		s.sign(u, perm)
+		return u
+	}
+
+	// modified returns a copy of a url with a query parameter changed
+	modified := func(u *url.URL, param, val string) *url.URL {
+		query := u.Query()
+		query.Set(param, val)
+		modified := *u
+		modified.RawQuery = query.Encode()
+		return &modified
+	}
+
+	readURL := signed(signer, "/org/repo/file", ReadPerm)
+	writeURL := signed(signer, "/org/repo/file", WritePerm)
+	expires := readURL.Query().Get(expiresParam)
+	tamperedPath := *readURL
+	tamperedPath.Path = "/org/other/file"
+
+	tests := []struct {
+		name     string
+		method   string
+		u        *url.URL
+		elapsed  time.Duration
+		expected bool
+	}{
+		{"download", http.MethodGet, readURL, 0, true},
+		{"head", http.MethodHead, readURL, 0, true},
+		{"upload", http.MethodPut, writeURL, 0, true},
+		{"upload with download url", http.MethodPut, readURL, 0, false},
+		{"download with upload url", http.MethodGet, writeURL, 0, false},
+		{"before expiry", http.MethodGet, readURL, time.Minute, true},
+		{"expired", http.MethodGet, readURL, time.Minute + time.Second, false},
+		{"tampered path", http.MethodGet, &tamperedPath, 0, false},
+		{"tampered expiry", http.MethodGet, modified(readURL, expiresParam, expires+"0"), 0, false},
+		{"bad expiry", http.MethodGet, modified(readURL, expiresParam, "never"), 0, false},
+		{"missing signature", http.MethodGet, modified(readURL, signatureParam, ""), 0, false},
+		{"bad signature encoding", http.MethodGet, modified(readURL, signatureParam, "not hex"), 0, false},
+		{"signature of another path", http.MethodGet, modified(readURL, signatureParam, signed(signer, "/org/other/file", ReadPerm).Query().Get(signatureParam)), 0, false},
+		{"signed with another key", http.MethodGet, signed(other, "/org/repo/file", ReadPerm), 0, false},
+		{"unsigned", http.MethodGet, &url.URL{Scheme: "http", Host: "localhost", Path: "/org/repo/file"}, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signer.now = func() time.Time { return now.Add(test.elapsed) }
+			req, err := http.NewRequest(test.method, test.u.String(), nil)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, signer.verify(req))
+		})
+	}
+}